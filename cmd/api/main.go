@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -21,6 +22,10 @@ import (
 	"github.com/onnwee/pulse-score/internal/config"
 	"github.com/onnwee/pulse-score/internal/database"
 	"github.com/onnwee/pulse-score/internal/handler"
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/integration/hubspot"
+	"github.com/onnwee/pulse-score/internal/integration/intercom"
+	"github.com/onnwee/pulse-score/internal/integration/stripe"
 	"github.com/onnwee/pulse-score/internal/middleware"
 	"github.com/onnwee/pulse-score/internal/repository"
 	"github.com/onnwee/pulse-score/internal/service"
@@ -31,6 +36,7 @@ import (
 const (
 	corsMaxAgeSeconds                = 300
 	connectionMonitorIntervalSeconds = 60
+	tokenRefreshIntervalMinutes      = 1
 )
 
 func main() {
@@ -90,7 +96,7 @@ func newRouter(cfg *config.Config, pool *database.Pool, jwtMgr *auth.JWTManager)
 
 	r.Use(chimw.RequestID)
 	r.Use(chimw.RealIP)
-	r.Use(chimw.Logger)
+	r.Use(middleware.Logger)
 	r.Use(chimw.Recoverer)
 	r.Use(middleware.SecurityHeaders)
 	r.Use(cors.Handler(cors.Options{
@@ -107,6 +113,11 @@ func newRouter(cfg *config.Config, pool *database.Pool, jwtMgr *auth.JWTManager)
 	r.Get("/healthz", health.Liveness)
 	r.Get("/readyz", health.Readiness)
 
+	// Runtime and provider HTTP metrics; not exposed in production
+	if !cfg.IsProd() {
+		r.Handle("/debug/vars", expvar.Handler())
+	}
+
 	registerAPIRoutes(r, cfg, pool, jwtMgr)
 	return r
 }
@@ -174,11 +185,18 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				}
 			}
 
+			tokenKeys, err := service.LoadTokenKeyring(cfg.Tokens.Keys, cfg.Tokens.PrimaryKeyID)
+			if err != nil {
+				slog.Error("invalid token encryption keyring", "error", err)
+				os.Exit(1)
+			}
+
 			stripeOAuthSvc := service.NewStripeOAuthService(service.StripeOAuthConfig{
 				ClientID:         cfg.Stripe.ClientID,
 				SecretKey:        cfg.Stripe.SecretKey,
 				OAuthRedirectURL: cfg.Stripe.OAuthRedirectURL,
 				EncryptionKey:    cfg.Stripe.EncryptionKey,
+				Keyring:          tokenKeys,
 			}, connRepo)
 
 			hubspotOAuthSvc := service.NewHubSpotOAuthService(service.HubSpotOAuthConfig{
@@ -186,6 +204,7 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				ClientSecret:     cfg.HubSpot.ClientSecret,
 				OAuthRedirectURL: cfg.HubSpot.OAuthRedirectURL,
 				EncryptionKey:    cfg.HubSpot.EncryptionKey,
+				Keyring:          tokenKeys,
 			}, connRepo)
 
 			intercomOAuthSvc := service.NewIntercomOAuthService(service.IntercomOAuthConfig{
@@ -193,18 +212,43 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				ClientSecret:     cfg.Intercom.ClientSecret,
 				OAuthRedirectURL: cfg.Intercom.OAuthRedirectURL,
 				EncryptionKey:    cfg.Intercom.EncryptionKey,
+				Keyring:          tokenKeys,
 			}, connRepo)
 
-			hubspotClient := service.NewHubSpotClient()
-			intercomClient := service.NewIntercomClient()
+			// Provider API clients share rate-limited, retrying transports
+			stripeTransport := service.NewStripeTransport()
+			hubspotTransport := service.NewHubSpotTransport()
+			intercomTransport := service.NewIntercomTransport()
+			service.PublishProviderTransportStats(stripeTransport, hubspotTransport, intercomTransport)
+
+			stripeClient := service.NewStripeClient(stripeTransport)
+			hubspotClient := service.NewHubSpotClient(hubspotTransport)
+			intercomClient := service.NewIntercomClient(intercomTransport)
 
 			stripeSyncSvc := service.NewStripeSyncService(
 				customerRepo, subRepo, paymentRepo, eventRepo,
-				stripeOAuthSvc, cfg.Stripe.PaymentSyncDays,
+				stripeOAuthSvc, stripeClient, cfg.Stripe.PaymentSyncDays,
 			)
 
-			mergeSvc := service.NewCustomerMergeService(customerRepo, hubspotContactRepo)
+			customerMergeRepo := repository.NewCustomerMergeRepository(pool.P)
+			mergeSvc := service.NewCustomerMergeService(
+				pool.P,
+				customerRepo,
+				hubspotContactRepo,
+				customerMergeRepo,
+			)
+			identityResolver := service.NewIdentityResolver(
+				customerRepo,
+				repository.NewIdentityMatchRepository(pool.P),
+				customerMergeRepo,
+				mergeSvc,
+				cfg.Identity.AutoMergeScore,
+				cfg.Identity.ReviewScore,
+				cfg.Identity.MetadataKeys,
+				cfg.Identity.AccountKeys,
+			)
 
+			syncCheckpointRepo := repository.NewSyncCheckpointRepository(pool.P)
 			hubspotSyncSvc := service.NewHubSpotSyncService(
 				hubspotOAuthSvc,
 				hubspotClient,
@@ -213,6 +257,7 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				hubspotCompanyRepo,
 				customerRepo,
 				eventRepo,
+				syncCheckpointRepo,
 			)
 
 			intercomSyncSvc := service.NewIntercomSyncService(
@@ -222,20 +267,26 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				intercomConversationRepo,
 				customerRepo,
 				eventRepo,
+				syncCheckpointRepo,
 			)
 
 			mrrSvc := service.NewMRRService(customerRepo, subRepo, eventRepo)
 			paymentHealthSvc := service.NewPaymentHealthService(paymentRepo, eventRepo, customerRepo)
 			paymentRecencySvc := service.NewPaymentRecencyService(paymentRepo, subRepo)
 
-			syncOrchestrator := service.NewSyncOrchestratorService(connRepo, stripeSyncSvc, mrrSvc)
-			hubspotSyncOrchestrator := service.NewHubSpotSyncOrchestratorService(connRepo, hubspotSyncSvc, mergeSvc)
-			intercomSyncOrchestrator := service.NewIntercomSyncOrchestratorService(connRepo, intercomSyncSvc, mergeSvc)
+			syncRunRepo := repository.NewSyncRunRepository(pool.P)
+			syncOrchestrator := service.NewSyncOrchestratorService(connRepo, syncRunRepo, stripeSyncSvc, mrrSvc)
+			hubspotSyncOrchestrator := service.NewHubSpotSyncOrchestratorService(connRepo, syncRunRepo, hubspotSyncSvc, identityResolver)
+			intercomSyncOrchestrator := service.NewIntercomSyncOrchestratorService(connRepo, syncRunRepo, intercomSyncSvc, identityResolver)
+
+			webhookInboxSvc := service.NewWebhookInboxService(repository.NewWebhookInboxRepository(pool.P))
 
 			stripeWebhookSvc := service.NewStripeWebhookService(
 				cfg.Stripe.WebhookSecret,
-				connRepo, customerRepo, subRepo, paymentRepo, eventRepo,
+				connRepo, repository.NewWebhookQuarantineRepository(pool.P),
+				customerRepo, subRepo, paymentRepo, eventRepo,
 				mrrSvc, paymentHealthSvc,
+				webhookInboxSvc,
 			)
 
 			billingSubscriptionSvc := billingsvc.NewSubscriptionService(
@@ -287,6 +338,7 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				hubspotDealRepo,
 				hubspotCompanyRepo,
 				eventRepo,
+				webhookInboxSvc,
 			)
 
 			intercomWebhookSvc := service.NewIntercomWebhookService(
@@ -297,8 +349,19 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				intercomContactRepo,
 				intercomConversationRepo,
 				eventRepo,
+				webhookInboxSvc,
 			)
 
+			// Integration providers — each connector registers here once
+			providers := integration.NewRegistry()
+			providers.Register(stripe.NewProvider(stripeOAuthSvc, stripeClient, syncOrchestrator, stripeWebhookSvc))
+			providers.Register(hubspot.NewProvider(hubspotOAuthSvc, hubspotClient, hubspotSyncOrchestrator, hubspotWebhookSvc))
+			providers.Register(intercom.NewProvider(intercomOAuthSvc, intercomClient, intercomSyncOrchestrator, intercomWebhookSvc))
+
+			for _, p := range providers.All() {
+				webhookInboxSvc.RegisterProcessor(p.Name(), p.ProcessWebhook)
+			}
+
 			onboardingSvc := service.NewOnboardingService(onboardingStatusRepo, onboardingEventRepo)
 
 			// Health scoring engine
@@ -310,6 +373,12 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 			failedPaymentsFactor := scoring.NewFailedPaymentsFactor(paymentHealthSvc, paymentRepo)
 			supportTicketsFactor := scoring.NewSupportTicketsFactor(eventRepo)
 			engagementFactor := scoring.NewEngagementFactor(eventRepo)
+			billingRiskFactor := scoring.NewBillingRiskFactor(eventRepo)
+
+			// Custom fields: typed customer fields, optionally filled from synced
+			// metadata, that can feed scoring factors, alert rules and segments
+			customFieldRepo := repository.NewCustomFieldRepository(pool.P)
+			customFieldSvc := service.NewCustomFieldService(customFieldRepo, customerRepo)
 
 			scoreAggregator := scoring.NewScoreAggregator(
 				[]scoring.ScoreFactor{
@@ -318,9 +387,11 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 					failedPaymentsFactor,
 					supportTicketsFactor,
 					engagementFactor,
+					billingRiskFactor,
 				},
 				scoringConfigRepo,
 			)
+			scoreAggregator.SetFactorSource(scoring.NewCustomFieldFactors(customFieldRepo))
 
 			changeDetector := scoring.NewChangeDetector(eventRepo, cfg.Scoring.ChangeDelta)
 			riskCategorizer := scoring.NewRiskCategorizer(healthScoreRepo)
@@ -331,6 +402,23 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				cfg.Scoring.Workers,
 			)
 
+			// Accounts: customers grouped by company, with rolled-up scores
+			accountRepo := repository.NewAccountRepository(pool.P)
+			accountScoreRepo := repository.NewAccountHealthScoreRepository(pool.P)
+			accountSvc := service.NewAccountService(
+				accountRepo, accountScoreRepo, customerRepo, hubspotContactRepo, hubspotCompanyRepo,
+			)
+			accountRollup := scoring.NewAccountRollup(accountSvc, accountRepo, accountScoreRepo, scoringConfigRepo)
+			scoreScheduler.SetAccountRollup(accountRollup)
+
+			// Customer ownership: owners are auto-assigned before each org's scores are recalculated
+			customerOwnerRepo := repository.NewCustomerOwnerRepository(pool.P)
+			customerOwnerSvc := service.NewCustomerOwnerService(
+				customerOwnerRepo, repository.NewCustomerAssignmentRuleRepository(pool.P), customerRepo, orgRepo,
+			)
+			scoreScheduler.SetOwnerAssigner(customerOwnerSvc)
+			scoreScheduler.SetFieldSyncer(customFieldSvc)
+
 			scoringConfigSvc := scoring.NewConfigService(scoringConfigRepo, scoreScheduler)
 
 			// Alert engine + scheduler
@@ -342,14 +430,30 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				os.Exit(1)
 			}
 
+			// Saved segments: used by customer lists, dashboards and alert rule scoping
+			segmentRepo := repository.NewSegmentRepository(pool.P)
+			customerTagRepo := repository.NewCustomerTagRepository(pool.P)
+			segmentSvc := service.NewSegmentService(segmentRepo, cfg.Segment.CountIntervalMin)
+
+			// Renewals: each customer's next renewal from a manual override, an
+			// open HubSpot renewal deal or its Stripe subscriptions
+			renewalRepo := repository.NewRenewalRepository(pool.P)
+
 			alertEngine := service.NewAlertEngine(
 				alertRuleRepo, alertHistoryRepo, healthScoreRepo,
-				customerRepo, eventRepo, cfg.Alert.DefaultCooldownHr,
+				customerRepo, eventRepo, accountRepo, accountScoreRepo,
+				segmentRepo, customFieldRepo, renewalRepo, cfg.Alert.DefaultCooldownHr,
 			)
 
 			notifPrefRepo := repository.NewNotificationPreferenceRepository(pool.P)
 			notifPrefSvc := service.NewNotificationPreferenceService(notifPrefRepo)
 
+			alertTemplateSvc := service.NewAlertTemplateService(
+				repository.NewAlertTemplateRepository(pool.P),
+				alertRuleRepo, customerRepo, healthScoreRepo,
+				emailTemplateSvc, cfg.SendGrid.FrontendURL,
+			)
+
 			alertScheduler := service.NewAlertScheduler(
 				service.AlertSchedulerDeps{
 					Engine:       alertEngine,
@@ -359,6 +463,9 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 					AlertRules:   alertRuleRepo,
 					UserRepo:     userRepo,
 					NotifPrefSvc: notifPrefSvc,
+					Queue:        repository.NewQueuedAlertEmailRepository(pool.P),
+					Owners:       customerOwnerRepo,
+					OrgTemplates: alertTemplateSvc,
 				},
 				cfg.Alert.EvalIntervalMin,
 				cfg.SendGrid.FrontendURL,
@@ -367,8 +474,60 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 			// Wire in-app notifications into the alert scheduler
 			notifRepo := repository.NewNotificationRepository(pool.P)
 			notifSvc := service.NewNotificationService(notifRepo, userRepo, notifPrefSvc)
+			customerNoteRepo := repository.NewCustomerNoteRepository(pool.P)
 			alertScheduler.SetNotificationService(notifSvc)
 
+			// Tasks: alert rules may raise follow-up tasks; assignees are reminded when they are overdue
+			taskSvc := service.NewTaskService(
+				repository.NewTaskRepository(pool.P), customerRepo, alertHistoryRepo, orgRepo,
+				customerOwnerRepo, notifSvc, cfg.Task.ReminderIntervalMin, cfg.Task.ReminderRepeatHr,
+			)
+			alertScheduler.SetTaskService(taskSvc)
+
+			// Playbooks: triggers enroll customers in multi-step sequences, for plans that include them
+			playbookRepo := repository.NewPlaybookRepository(pool.P)
+			playbookRunRepo := repository.NewPlaybookRunRepository(pool.P)
+			playbookSvc := service.NewPlaybookService(playbookRepo, playbookRunRepo, customerRepo, orgRepo)
+			playbookEngine := service.NewPlaybookEngine(
+				service.PlaybookEngineDeps{
+					Playbooks:    playbookRepo,
+					Runs:         playbookRunRepo,
+					Customers:    customerRepo,
+					HealthScores: healthScoreRepo,
+					Owners:       customerOwnerRepo,
+					Tasks:        taskSvc,
+					EmailService: emailSvc,
+					Templates:    alertTemplateSvc,
+					FeatureAllowed: func(ctx context.Context, orgID uuid.UUID) (bool, error) {
+						decision, err := billingLimitsSvc.CanAccess(ctx, orgID, "playbooks")
+						if err != nil {
+							return false, err
+						}
+						return decision.Allowed, nil
+					},
+				},
+				cfg.Playbook.IntervalMin,
+			)
+
+			// Daily/weekly digest emails
+			digestSvc := service.NewDigestService(
+				service.DigestServiceDeps{
+					Prefs:        notifPrefRepo,
+					History:      repository.NewDigestHistoryRepository(pool.P),
+					Users:        userRepo,
+					Orgs:         orgRepo,
+					Customers:    customerRepo,
+					HealthScores: healthScoreRepo,
+					AlertHistory: alertHistoryRepo,
+					Events:       eventRepo,
+					EmailService: emailSvc,
+					Templates:    emailTemplateSvc,
+				},
+				cfg.Alert.DigestCheckInterval,
+				cfg.Alert.DigestSendHour,
+				cfg.SendGrid.FrontendURL,
+			)
+
 			// Hook real-time alert evaluation into score recalculation
 			scoreScheduler.SetAlertCallback(func(ctx context.Context, customerID, orgID uuid.UUID) {
 				matches, err := alertEngine.EvaluateForCustomer(ctx, customerID, orgID)
@@ -380,6 +539,16 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 					alertScheduler.ProcessMatch(ctx, match)
 				}
 			})
+			accountRollup.SetAlertCallback(func(ctx context.Context, accountID, orgID uuid.UUID) {
+				matches, err := alertEngine.EvaluateForAccount(ctx, accountID, orgID)
+				if err != nil {
+					slog.Error("real-time account alert eval error", "account_id", accountID, "error", err)
+					return
+				}
+				for _, match := range matches {
+					alertScheduler.ProcessMatch(ctx, match)
+				}
+			})
 
 			// Start background services
 			bgCtx, bgCancel := context.WithCancel(context.Background())
@@ -388,25 +557,48 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 			if cfg.Stripe.SyncIntervalMin > 0 {
 				syncScheduler := service.NewSyncSchedulerService(
 					connRepo,
-					syncOrchestrator,
-					hubspotSyncOrchestrator,
-					intercomSyncOrchestrator,
+					syncRunRepo,
+					syncCheckpointRepo,
+					providers,
 					cfg.Stripe.SyncIntervalMin,
 				)
 				go syncScheduler.Start(bgCtx)
 			}
 
+			reconciler := service.NewReconciliationService(
+				connRepo,
+				repository.NewReconciliationReportRepository(pool.P),
+				providers,
+				cfg.Reconcile.IntervalMin,
+				cfg.Reconcile.AutoHeal,
+			)
+			if cfg.Reconcile.IntervalMin > 0 {
+				go reconciler.Start(bgCtx)
+			}
+
 			if cfg.Scoring.RecalcIntervalMin > 0 {
 				go scoreScheduler.Start(bgCtx)
 			}
 
+			tokenLifecycle := service.NewTokenLifecycleService(
+				service.TokenLifecycleDeps{
+					Connections:   connRepo,
+					Providers:     providers,
+					Orgs:          orgRepo,
+					Notifications: notifRepo,
+					Prefs:         notifPrefSvc,
+					EmailService:  emailSvc,
+					Templates:     emailTemplateSvc,
+				},
+				tokenRefreshIntervalMinutes,
+				cfg.SendGrid.FrontendURL,
+			)
+			go tokenLifecycle.Start(bgCtx)
+
 			connMonitor := service.NewConnectionMonitorService(
 				connRepo,
-				stripeOAuthSvc,
-				hubspotOAuthSvc,
-				hubspotClient,
-				intercomOAuthSvc,
-				intercomClient,
+				providers,
+				tokenLifecycle,
 				connectionMonitorIntervalSeconds,
 			)
 			go connMonitor.Start(bgCtx)
@@ -415,6 +607,27 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				go alertScheduler.Start(bgCtx)
 			}
 
+			if cfg.Alert.DigestCheckInterval > 0 {
+				go digestSvc.Start(bgCtx)
+			}
+
+			if cfg.Task.ReminderIntervalMin > 0 {
+				go taskSvc.Start(bgCtx)
+			}
+
+			if cfg.Playbook.IntervalMin > 0 {
+				go playbookEngine.Start(bgCtx)
+			}
+
+			if cfg.Segment.CountIntervalMin > 0 {
+				go segmentSvc.Start(bgCtx)
+			}
+
+			realtimeBroker := service.NewRealtimeBroker(repository.NewRealtimeEventRepository(pool.P))
+			go realtimeBroker.Start(bgCtx)
+
+			go webhookInboxSvc.Start(bgCtx)
+
 			r.Post("/auth/register", authHandler.Register)
 			r.Post("/auth/login", authHandler.Login)
 			r.Post("/auth/refresh", authHandler.Refresh)
@@ -424,10 +637,6 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 			// Invitation acceptance (public — no auth required)
 			r.Post("/invitations/accept", invitationHandler.Accept)
 
-			// Stripe webhook (public — verified by signature)
-			webhookHandler := handler.NewWebhookStripeHandler(stripeWebhookSvc)
-			r.Post("/webhooks/stripe", webhookHandler.HandleWebhook)
-
 			// Stripe billing webhook (public — verified by signature)
 			billingWebhookHandler := handler.NewWebhookStripeBillingHandler(billingWebhookSvc)
 			r.Post("/webhooks/stripe-billing", billingWebhookHandler.HandleWebhook)
@@ -436,13 +645,16 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 			sendgridWebhookHandler := handler.NewWebhookSendGridHandler(alertHistoryRepo)
 			r.Post("/webhooks/sendgrid", sendgridWebhookHandler.HandleWebhook)
 
-			// HubSpot webhook (public — verified by signature)
-			hubspotWebhookHandler := handler.NewWebhookHubSpotHandler(hubspotWebhookSvc)
-			r.Post("/webhooks/hubspot", hubspotWebhookHandler.HandleWebhook)
+			// Integration provider webhooks (public — verified by signature)
+			integrationWebhookHandler := handler.NewWebhookIntegrationHandler(providers)
+			r.Post("/webhooks/{provider}", integrationWebhookHandler.HandleWebhook)
 
-			// Intercom webhook (public — verified by signature)
-			intercomWebhookHandler := handler.NewWebhookIntercomHandler(intercomWebhookSvc)
-			r.Post("/webhooks/intercom", intercomWebhookHandler.HandleWebhook)
+			// Realtime event stream (JWT via header or access_token query for EventSource)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.StreamJWTAuth(jwtMgr))
+				r.Use(middleware.TenantIsolation(orgRepo))
+				r.Get("/notifications/stream", handler.NewRealtimeHandler(realtimeBroker).Stream)
+			})
 
 			// Protected routes (JWT required)
 			r.Group(func(r chi.Router) {
@@ -478,27 +690,155 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				r.Patch("/users/me", userHandler.UpdateProfile)
 
 				// Customer routes
-				customerSvc := service.NewCustomerService(customerRepo, healthScoreRepo, subRepo, eventRepo)
+				customerSvc := service.NewCustomerService(customerRepo, healthScoreRepo, subRepo, eventRepo, customerTagRepo, segmentRepo, customFieldRepo)
 				customerHandler := handler.NewCustomerHandler(customerSvc)
 				r.Get("/customers", customerHandler.List)
+				r.Get("/customers/export", customerHandler.Export)
 				r.Get("/customers/{id}", customerHandler.GetDetail)
 				r.Get("/customers/{id}/events", customerHandler.ListEvents)
 
+				// Customer tag routes
+				customerTagSvc := service.NewCustomerTagService(customerTagRepo, customerRepo)
+				customerTagHandler := handler.NewCustomerTagHandler(customerTagSvc)
+				r.Get("/tags", customerTagHandler.ListOrgTags)
+				r.Get("/customers/{id}/tags", customerTagHandler.List)
+				r.Post("/customers/{id}/tags", customerTagHandler.Add)
+				r.Put("/customers/{id}/tags", customerTagHandler.Set)
+				r.Delete("/customers/{id}/tags/{tag}", customerTagHandler.Remove)
+
+				// Segment routes (saving, editing and deleting segments requires admin+)
+				segmentHandler := handler.NewSegmentHandler(segmentSvc)
+				r.Route("/segments", func(r chi.Router) {
+					r.Get("/", segmentHandler.List)
+					r.Post("/preview", segmentHandler.Preview)
+					r.Get("/{id}", segmentHandler.Get)
+					r.Get("/{id}/history", segmentHandler.History)
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequireRole("admin"))
+						r.Post("/", segmentHandler.Create)
+						r.Patch("/{id}", segmentHandler.Update)
+						r.Delete("/{id}", segmentHandler.Delete)
+					})
+				})
+
+				// Custom field routes (defining and editing fields requires admin+)
+				customFieldHandler := handler.NewCustomFieldHandler(customFieldSvc)
+				r.Get("/customers/{id}/fields", customFieldHandler.ListValues)
+				r.Patch("/customers/{id}/fields", customFieldHandler.SetValues)
+				r.Route("/custom-fields", func(r chi.Router) {
+					r.Get("/", customFieldHandler.List)
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequireRole("admin"))
+						r.Post("/", customFieldHandler.Create)
+						r.Patch("/{id}", customFieldHandler.Update)
+						r.Delete("/{id}", customFieldHandler.Delete)
+					})
+				})
+
+				// Renewal routes (changing which HubSpot deals are renewals requires admin+)
+				renewalHandler := handler.NewRenewalHandler(service.NewRenewalService(renewalRepo, customerRepo))
+				r.Get("/customers/{id}/renewal", renewalHandler.GetCustomer)
+				r.Put("/customers/{id}/renewal", renewalHandler.SetCustomer)
+				r.Delete("/customers/{id}/renewal", renewalHandler.ClearCustomer)
+				r.Route("/renewals", func(r chi.Router) {
+					r.Get("/", renewalHandler.Pipeline)
+					r.Get("/settings", renewalHandler.GetSettings)
+					r.With(middleware.RequireRole("admin")).Patch("/settings", renewalHandler.UpdateSettings)
+				})
+
+				// Customer note routes (authors edit their notes; admins+ may also delete them)
+				customerNoteSvc := service.NewCustomerNoteService(customerNoteRepo, customerRepo, orgRepo, notifSvc)
+				customerNoteHandler := handler.NewCustomerNoteHandler(customerNoteSvc)
+				r.Get("/customers/{id}/notes", customerNoteHandler.List)
+				r.Post("/customers/{id}/notes", customerNoteHandler.Create)
+				r.Patch("/customers/{id}/notes/{noteId}", customerNoteHandler.Update)
+				r.Delete("/customers/{id}/notes/{noteId}", customerNoteHandler.Delete)
+				r.Get("/customers/{id}/notes/{noteId}/revisions", customerNoteHandler.ListRevisions)
+
+				// Customer ownership routes (assigning owners and rules requires admin+)
+				customerOwnerHandler := handler.NewCustomerOwnerHandler(customerOwnerSvc)
+				r.Get("/customers/{id}/owners", customerOwnerHandler.ListOwners)
+				r.With(middleware.RequireRole("admin")).Put("/customers/{id}/owners", customerOwnerHandler.SetOwners)
+				r.Get("/customer-owners/summary", customerOwnerHandler.BookOfBusiness)
+				r.With(middleware.RequireRole("admin")).Post("/customer-owners/bulk", customerOwnerHandler.BulkAssign)
+				r.With(middleware.RequireRole("admin")).Post("/customer-owners/auto-assign", customerOwnerHandler.AutoAssign)
+				r.Route("/assignment-rules", func(r chi.Router) {
+					r.Get("/", customerOwnerHandler.ListRules)
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequireRole("admin"))
+						r.Post("/", customerOwnerHandler.CreateRule)
+						r.Patch("/{id}", customerOwnerHandler.UpdateRule)
+						r.Delete("/{id}", customerOwnerHandler.DeleteRule)
+					})
+				})
+
+				// Task routes (creators may delete their tasks; admins+ may delete any)
+				taskHandler := handler.NewTaskHandler(taskSvc)
+				r.Get("/tasks", taskHandler.List)
+				r.Post("/tasks", taskHandler.Create)
+				r.Get("/tasks/{id}", taskHandler.Get)
+				r.Patch("/tasks/{id}", taskHandler.Update)
+				r.Delete("/tasks/{id}", taskHandler.Delete)
+
+				// Playbook routes (plan-gated; managing playbooks and runs requires admin+)
+				playbookHandler := handler.NewPlaybookHandler(playbookSvc)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireFeature(billingLimitsSvc, "playbooks"))
+					r.Route("/playbooks", func(r chi.Router) {
+						r.Get("/", playbookHandler.List)
+						r.Get("/{id}", playbookHandler.Get)
+						r.Get("/{id}/runs", playbookHandler.ListRuns)
+						r.Group(func(r chi.Router) {
+							r.Use(middleware.RequireRole("admin"))
+							r.Post("/", playbookHandler.Create)
+							r.Patch("/{id}", playbookHandler.Update)
+							r.Delete("/{id}", playbookHandler.Delete)
+							r.Get("/{id}/webhook-secret", playbookHandler.GetWebhookSecret)
+							r.Post("/{id}/webhook-secret/rotate", playbookHandler.RotateWebhookSecret)
+							r.Post("/{id}/runs", playbookHandler.StartRun)
+						})
+					})
+					r.Get("/playbook-runs/{id}", playbookHandler.GetRun)
+					r.With(middleware.RequireRole("admin")).Post("/playbook-runs/{id}/cancel", playbookHandler.CancelRun)
+					r.Get("/customers/{id}/playbook-runs", playbookHandler.ListCustomerRuns)
+				})
+
+				accountHandler := handler.NewAccountHandler(accountSvc)
+				r.Get("/accounts", accountHandler.List)
+				r.Get("/accounts/{id}", accountHandler.GetDetail)
+
+				// Customer merge routes (merging and undoing require admin+)
+				customerMergeHandler := handler.NewCustomerMergeHandler(mergeSvc)
+				r.Get("/customers/{id}/merges", customerMergeHandler.ListMerges)
+				r.With(middleware.RequireRole("admin")).Post("/customers/{id}/merge", customerMergeHandler.Merge)
+				r.With(middleware.RequireRole("admin")).Post("/customer-merges/{id}/unmerge", customerMergeHandler.Unmerge)
+
+				// Identity match review routes (deciding requires admin+)
+				identityMatchHandler := handler.NewIdentityMatchHandler(identityResolver)
+				r.Get("/identity-matches", identityMatchHandler.List)
+				r.With(middleware.RequireRole("admin")).Post("/identity-matches/{id}/approve", identityMatchHandler.Approve)
+				r.With(middleware.RequireRole("admin")).Post("/identity-matches/{id}/reject", identityMatchHandler.Reject)
+
 				// Dashboard routes
-				dashboardSvc := service.NewDashboardService(customerRepo, healthScoreRepo)
+				dashboardSvc := service.NewDashboardService(customerRepo, healthScoreRepo, segmentRepo)
 				dashboardHandler := handler.NewDashboardHandler(dashboardSvc)
 				r.Get("/dashboard/summary", dashboardHandler.GetSummary)
 				r.Get("/dashboard/score-distribution", dashboardHandler.GetScoreDistribution)
 
 				// Integration management routes (admin+ required)
-				integrationSvc := service.NewIntegrationService(connRepo, stripeOAuthSvc, syncOrchestrator)
+				integrationSvc := service.NewIntegrationService(connRepo, syncRunRepo, providers, reconciler)
 				integrationHandler := handler.NewIntegrationHandler(integrationSvc)
 				r.Route("/integrations", func(r chi.Router) {
 					r.Get("/", integrationHandler.List)
 					r.Route("/{provider}", func(r chi.Router) {
 						r.Use(middleware.RequireRole("admin"))
+						r.With(middleware.RequireIntegrationLimit(billingLimitsSvc, providers)).Get("/connect", integrationHandler.Connect)
+						r.Get("/callback", integrationHandler.Callback)
 						r.Get("/status", integrationHandler.GetStatus)
 						r.Post("/sync", integrationHandler.TriggerSync)
+						r.Get("/runs", integrationHandler.ListRuns)
+						r.Post("/reconcile", integrationHandler.TriggerReconcile)
+						r.Get("/reconciliations", integrationHandler.ListReconciliations)
 						r.Delete("/", integrationHandler.Disconnect)
 					})
 				})
@@ -534,54 +874,43 @@ func registerAPIRoutes(r *chi.Mux, cfg *config.Config, pool *database.Pool, jwtM
 				r.Post("/notifications/read-all", notifHandler.MarkAllRead)
 
 				// Alert rule routes (admin+ required)
-				alertRuleSvc := service.NewAlertRuleService(alertRuleRepo)
+				alertRuleSvc := service.NewAlertRuleService(alertRuleRepo, alertEngine, orgRepo, segmentRepo, customFieldRepo)
 				alertRuleHandler := handler.NewAlertRuleHandler(alertRuleSvc)
 				alertHistoryHandler := handler.NewAlertHistoryHandler(alertHistoryRepo)
 				r.Route("/alerts/rules", func(r chi.Router) {
 					r.Use(middleware.RequireRole("admin"))
 					r.Get("/", alertRuleHandler.List)
 					r.Post("/", alertRuleHandler.Create)
+					r.Post("/backtest", alertRuleHandler.Backtest)
 					r.Get("/{id}", alertRuleHandler.Get)
 					r.Patch("/{id}", alertRuleHandler.Update)
 					r.Delete("/{id}", alertRuleHandler.Delete)
 					r.Get("/{id}/history", alertHistoryHandler.ListByRule)
 				})
 
-				// Alert history routes
-				r.Get("/alerts/history", alertHistoryHandler.List)
-				r.Get("/alerts/stats", alertHistoryHandler.Stats)
-
-				// Stripe integration routes (admin+ required)
-				stripeHandler := handler.NewIntegrationStripeHandler(stripeOAuthSvc, syncOrchestrator)
-				r.Route("/integrations/stripe", func(r chi.Router) {
+				// Alert template routes (admin+ required)
+				alertTemplateHandler := handler.NewAlertTemplateHandler(alertTemplateSvc)
+				r.Route("/alerts/templates", func(r chi.Router) {
 					r.Use(middleware.RequireRole("admin"))
-					r.With(middleware.RequireIntegrationLimit(billingLimitsSvc, "stripe")).Get("/connect", stripeHandler.Connect)
-					r.Get("/callback", stripeHandler.Callback)
-					r.Get("/status", stripeHandler.Status)
-					r.Delete("/", stripeHandler.Disconnect)
-					r.Post("/sync", stripeHandler.TriggerSync)
+					r.Get("/", alertTemplateHandler.List)
+					r.Post("/", alertTemplateHandler.Create)
+					r.Get("/variables", alertTemplateHandler.Variables)
+					r.Post("/preview", alertTemplateHandler.Preview)
+					r.Get("/{id}", alertTemplateHandler.Get)
+					r.Patch("/{id}", alertTemplateHandler.Update)
+					r.Delete("/{id}", alertTemplateHandler.Delete)
 				})
 
-				// HubSpot integration routes (admin+ required)
-				hubspotHandler := handler.NewIntegrationHubSpotHandler(hubspotOAuthSvc, hubspotSyncOrchestrator)
-				r.Route("/integrations/hubspot", func(r chi.Router) {
-					r.Use(middleware.RequireRole("admin"))
-					r.With(middleware.RequireIntegrationLimit(billingLimitsSvc, "hubspot")).Get("/connect", hubspotHandler.Connect)
-					r.Get("/callback", hubspotHandler.Callback)
-					r.Get("/status", hubspotHandler.Status)
-					r.Delete("/", hubspotHandler.Disconnect)
-					r.Post("/sync", hubspotHandler.TriggerSync)
-				})
+				// Alert history routes
+				r.Get("/alerts/history", alertHistoryHandler.List)
+				r.Get("/alerts/stats", alertHistoryHandler.Stats)
 
-				// Intercom integration routes (admin+ required)
-				intercomHandler := handler.NewIntegrationIntercomHandler(intercomOAuthSvc, intercomSyncOrchestrator)
-				r.Route("/integrations/intercom", func(r chi.Router) {
+				// Inbound webhook inbox routes (admin+ required)
+				webhookInboxHandler := handler.NewWebhookInboxHandler(webhookInboxSvc)
+				r.Route("/webhooks/inbox", func(r chi.Router) {
 					r.Use(middleware.RequireRole("admin"))
-					r.With(middleware.RequireIntegrationLimit(billingLimitsSvc, "intercom")).Get("/connect", intercomHandler.Connect)
-					r.Get("/callback", intercomHandler.Callback)
-					r.Get("/status", intercomHandler.Status)
-					r.Delete("/", intercomHandler.Disconnect)
-					r.Post("/sync", intercomHandler.TriggerSync)
+					r.Get("/", webhookInboxHandler.ListFailed)
+					r.Post("/{id}/replay", webhookInboxHandler.Replay)
 				})
 
 				// Onboarding routes
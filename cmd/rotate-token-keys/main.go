@@ -0,0 +1,83 @@
+// Command rotate-token-keys re-encrypts stored integration tokens under the
+// primary key of the token keyring (TOKEN_ENCRYPTION_KEYS and
+// TOKEN_ENCRYPTION_PRIMARY_KEY). It is safe to run against a live database:
+// rows are updated in batches and only if their tokens are unchanged.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/onnwee/pulse-score/internal/config"
+	"github.com/onnwee/pulse-score/internal/database"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 100, "connections per batch")
+	pause := flag.Duration("pause", 200*time.Millisecond, "pause between batches")
+	dryRun := flag.Bool("dry-run", false, "count rows that would be re-encrypted without writing")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	cfg := config.Load()
+	if cfg.Database.URL == "" {
+		slog.Error("DATABASE_URL is required")
+		os.Exit(1)
+	}
+
+	keys, err := service.LoadTokenKeyring(cfg.Tokens.Keys, cfg.Tokens.PrimaryKeyID)
+	if err != nil {
+		slog.Error("invalid token encryption keyring", "error", err)
+		os.Exit(1)
+	}
+	if keys == nil {
+		slog.Error("TOKEN_ENCRYPTION_KEYS is required")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := database.NewPool(ctx, database.DefaultPoolConfig(cfg.Database.URL))
+	if err != nil {
+		slog.Error("connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	rotation := service.NewTokenRotationService(
+		repository.NewIntegrationConnectionRepository(pool),
+		map[string]*service.TokenCipher{
+			"stripe":   service.NewTokenCipher(keys, cfg.Stripe.EncryptionKey),
+			"hubspot":  service.NewTokenCipher(keys, cfg.HubSpot.EncryptionKey),
+			"intercom": service.NewTokenCipher(keys, cfg.Intercom.EncryptionKey),
+		},
+	)
+
+	slog.Info("token rotation started", "primary_key", keys.Primary(), "keys", keys.KeyIDs(), "dry_run", *dryRun)
+
+	result, err := rotation.Rotate(ctx, *batchSize, *pause, *dryRun)
+	slog.Info("token rotation finished",
+		"scanned", result.Scanned,
+		"rotated", result.Rotated,
+		"current", result.Current,
+		"conflict", result.Conflict,
+		"failed", result.Failed,
+		"dry_run", *dryRun,
+	)
+	if err != nil {
+		slog.Error("token rotation aborted", "error", err)
+		os.Exit(1)
+	}
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -20,14 +20,52 @@ type Config struct {
 	BillingStripe BillingStripeConfig
 	HubSpot       HubSpotConfig
 	Intercom      IntercomConfig
+	Tokens        TokenEncryptionConfig
 	Scoring       ScoringConfig
 	Alert         AlertConfig
+	Reconcile     ReconcileConfig
+	Identity      IdentityConfig
+	Task          TaskConfig
+	Playbook      PlaybookConfig
+	Segment       SegmentConfig
+}
+
+// SegmentConfig holds saved segment settings.
+type SegmentConfig struct {
+	CountIntervalMin int // minutes between recording segment member counts; 0 disables recording
+}
+
+// PlaybookConfig holds playbook engine settings.
+type PlaybookConfig struct {
+	IntervalMin int // minutes between trigger checks and step runs; 0 disables the engine
+}
+
+// TaskConfig holds task reminder settings.
+type TaskConfig struct {
+	ReminderIntervalMin int // minutes between checks for overdue tasks; 0 disables reminders
+	ReminderRepeatHr    int // hours before an overdue task's assignee is reminded again
+}
+
+// IdentityConfig holds customer identity resolution settings.
+type IdentityConfig struct {
+	AutoMergeScore int      // pairs scoring at least this (0-100) are merged automatically
+	ReviewScore    int      // pairs scoring at least this are queued for admin review
+	MetadataKeys   []string // Stripe metadata keys that hold a person's IDs in other systems
+	AccountKeys    []string // Stripe metadata keys that hold a company or workspace ID
+}
+
+// ReconcileConfig holds data reconciliation settings.
+type ReconcileConfig struct {
+	IntervalMin int  // minutes between reconciliations of each connection; 0 disables
+	AutoHeal    bool // repair drift found by scheduled runs instead of only reporting it
 }
 
 // AlertConfig holds alert engine settings.
 type AlertConfig struct {
-	EvalIntervalMin   int
-	DefaultCooldownHr int
+	EvalIntervalMin     int
+	DefaultCooldownHr   int
+	DigestCheckInterval int // minutes between checks for due digests
+	DigestSendHour      int // local hour of day digests are sent
 }
 
 // ScoringConfig holds health score engine settings.
@@ -80,6 +118,12 @@ type IntercomConfig struct {
 	SyncIntervalMin  int
 }
 
+// TokenEncryptionConfig holds the keyring integration tokens are encrypted with.
+type TokenEncryptionConfig struct {
+	Keys         []string // "id:hex" 32-byte AES keys; every key that may still wrap a stored token
+	PrimaryKeyID string   // key new tokens are encrypted with; optional with a single key
+}
+
 // SendGridConfig holds email sending settings.
 type SendGridConfig struct {
 	APIKey           string
@@ -199,14 +243,42 @@ func Load() *Config {
 			WebhookSecret:    getEnv("INTERCOM_WEBHOOK_SECRET", ""),
 			SyncIntervalMin:  getInt("INTERCOM_SYNC_INTERVAL_MIN", 15),
 		},
+		Tokens: TokenEncryptionConfig{
+			Keys:         getEnvSlice("TOKEN_ENCRYPTION_KEYS", nil),
+			PrimaryKeyID: getEnv("TOKEN_ENCRYPTION_PRIMARY_KEY", ""),
+		},
 		Scoring: ScoringConfig{
 			RecalcIntervalMin: getInt("SCORE_RECALC_INTERVAL_MIN", 60),
 			Workers:           getInt("SCORE_RECALC_WORKERS", 5),
 			ChangeDelta:       float64(getInt("SCORE_CHANGE_DELTA", 10)),
 		},
 		Alert: AlertConfig{
-			EvalIntervalMin:   getInt("ALERT_EVAL_INTERVAL_MIN", 15),
-			DefaultCooldownHr: getInt("ALERT_DEFAULT_COOLDOWN_HR", 24),
+			EvalIntervalMin:     getInt("ALERT_EVAL_INTERVAL_MIN", 15),
+			DefaultCooldownHr:   getInt("ALERT_DEFAULT_COOLDOWN_HR", 24),
+			DigestCheckInterval: getInt("DIGEST_CHECK_INTERVAL_MIN", 15),
+			DigestSendHour:      getInt("DIGEST_SEND_HOUR", 8),
+		},
+		Reconcile: ReconcileConfig{
+			IntervalMin: getInt("RECONCILE_INTERVAL_MIN", 1440),
+			AutoHeal:    getBool("RECONCILE_AUTO_HEAL", false),
+		},
+		Identity: IdentityConfig{
+			AutoMergeScore: getInt("IDENTITY_AUTO_MERGE_SCORE", 90),
+			ReviewScore:    getInt("IDENTITY_REVIEW_SCORE", 60),
+			MetadataKeys: getEnvSlice("IDENTITY_METADATA_KEYS", []string{
+				"user_id", "external_id", "hubspot_contact_id", "intercom_contact_id", "intercom_user_id",
+			}),
+			AccountKeys: getEnvSlice("IDENTITY_ACCOUNT_KEYS", []string{"account_id", "company_id", "workspace_id"}),
+		},
+		Task: TaskConfig{
+			ReminderIntervalMin: getInt("TASK_REMINDER_INTERVAL_MIN", 15),
+			ReminderRepeatHr:    getInt("TASK_REMINDER_REPEAT_HR", 24),
+		},
+		Playbook: PlaybookConfig{
+			IntervalMin: getInt("PLAYBOOK_INTERVAL_MIN", 5),
+		},
+		Segment: SegmentConfig{
+			CountIntervalMin: getInt("SEGMENT_COUNT_INTERVAL_MIN", 60),
 		},
 	}
 }
@@ -265,6 +337,18 @@ func getInt(key string, fallback int) int {
 	return i
 }
 
+func getBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
 func getDuration(key string, fallback time.Duration) time.Duration {
 	v := os.Getenv(key)
 	if v == "" {
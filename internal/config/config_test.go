@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"slices"
 	"testing"
 	"time"
 )
@@ -17,6 +18,10 @@ func clearEnv() {
 		"STRIPE_BILLING_WEBHOOK_SECRET", "STRIPE_BILLING_PORTAL_RETURN_URL",
 		"STRIPE_BILLING_PRICE_GROWTH_MONTHLY", "STRIPE_BILLING_PRICE_GROWTH_ANNUAL",
 		"STRIPE_BILLING_PRICE_SCALE_MONTHLY", "STRIPE_BILLING_PRICE_SCALE_ANNUAL",
+		"RECONCILE_INTERVAL_MIN", "RECONCILE_AUTO_HEAL",
+		"IDENTITY_AUTO_MERGE_SCORE", "IDENTITY_REVIEW_SCORE", "IDENTITY_METADATA_KEYS", "IDENTITY_ACCOUNT_KEYS",
+		"TASK_REMINDER_INTERVAL_MIN", "TASK_REMINDER_REPEAT_HR",
+		"PLAYBOOK_INTERVAL_MIN", "SEGMENT_COUNT_INTERVAL_MIN",
 	} {
 		os.Unsetenv(key)
 	}
@@ -86,6 +91,126 @@ func TestLoadFromEnv(t *testing.T) {
 	}
 }
 
+func TestLoadReconcileConfig(t *testing.T) {
+	clearEnv()
+
+	cfg := Load()
+	if cfg.Reconcile.IntervalMin != 1440 {
+		t.Errorf("expected default reconcile interval 1440, got %d", cfg.Reconcile.IntervalMin)
+	}
+	if cfg.Reconcile.AutoHeal {
+		t.Error("expected auto-heal to be off by default")
+	}
+
+	os.Setenv("RECONCILE_INTERVAL_MIN", "60")
+	os.Setenv("RECONCILE_AUTO_HEAL", "true")
+	defer clearEnv()
+
+	cfg = Load()
+	if cfg.Reconcile.IntervalMin != 60 {
+		t.Errorf("expected reconcile interval 60, got %d", cfg.Reconcile.IntervalMin)
+	}
+	if !cfg.Reconcile.AutoHeal {
+		t.Error("expected auto-heal to be on")
+	}
+}
+
+func TestLoadIdentityConfig(t *testing.T) {
+	clearEnv()
+
+	cfg := Load()
+	if cfg.Identity.AutoMergeScore != 90 {
+		t.Errorf("expected default auto-merge score 90, got %d", cfg.Identity.AutoMergeScore)
+	}
+	if cfg.Identity.ReviewScore != 60 {
+		t.Errorf("expected default review score 60, got %d", cfg.Identity.ReviewScore)
+	}
+	if len(cfg.Identity.MetadataKeys) == 0 {
+		t.Error("expected default metadata keys")
+	}
+	if slices.Contains(cfg.Identity.MetadataKeys, "account_id") || !slices.Contains(cfg.Identity.AccountKeys, "account_id") {
+		t.Errorf("expected account_id to be an account key, got metadata keys %v and account keys %v", cfg.Identity.MetadataKeys, cfg.Identity.AccountKeys)
+	}
+
+	os.Setenv("IDENTITY_AUTO_MERGE_SCORE", "101")
+	os.Setenv("IDENTITY_REVIEW_SCORE", "50")
+	os.Setenv("IDENTITY_METADATA_KEYS", "app_user_id, tenant_id")
+	os.Setenv("IDENTITY_ACCOUNT_KEYS", "org_id")
+	defer clearEnv()
+
+	cfg = Load()
+	if cfg.Identity.AutoMergeScore != 101 {
+		t.Errorf("expected auto-merge score 101, got %d", cfg.Identity.AutoMergeScore)
+	}
+	if cfg.Identity.ReviewScore != 50 {
+		t.Errorf("expected review score 50, got %d", cfg.Identity.ReviewScore)
+	}
+	if len(cfg.Identity.MetadataKeys) != 2 || cfg.Identity.MetadataKeys[1] != "tenant_id" {
+		t.Errorf("expected metadata keys [app_user_id tenant_id], got %v", cfg.Identity.MetadataKeys)
+	}
+	if len(cfg.Identity.AccountKeys) != 1 || cfg.Identity.AccountKeys[0] != "org_id" {
+		t.Errorf("expected account keys [org_id], got %v", cfg.Identity.AccountKeys)
+	}
+}
+
+func TestLoadTaskConfig(t *testing.T) {
+	clearEnv()
+
+	cfg := Load()
+	if cfg.Task.ReminderIntervalMin != 15 {
+		t.Errorf("expected default reminder interval 15, got %d", cfg.Task.ReminderIntervalMin)
+	}
+	if cfg.Task.ReminderRepeatHr != 24 {
+		t.Errorf("expected default reminder repeat 24, got %d", cfg.Task.ReminderRepeatHr)
+	}
+
+	os.Setenv("TASK_REMINDER_INTERVAL_MIN", "0")
+	os.Setenv("TASK_REMINDER_REPEAT_HR", "4")
+	defer clearEnv()
+
+	cfg = Load()
+	if cfg.Task.ReminderIntervalMin != 0 {
+		t.Errorf("expected reminders disabled, got interval %d", cfg.Task.ReminderIntervalMin)
+	}
+	if cfg.Task.ReminderRepeatHr != 4 {
+		t.Errorf("expected reminder repeat 4, got %d", cfg.Task.ReminderRepeatHr)
+	}
+}
+
+func TestLoadPlaybookConfig(t *testing.T) {
+	clearEnv()
+
+	cfg := Load()
+	if cfg.Playbook.IntervalMin != 5 {
+		t.Errorf("expected default playbook interval 5, got %d", cfg.Playbook.IntervalMin)
+	}
+
+	os.Setenv("PLAYBOOK_INTERVAL_MIN", "1")
+	defer clearEnv()
+
+	cfg = Load()
+	if cfg.Playbook.IntervalMin != 1 {
+		t.Errorf("expected playbook interval 1, got %d", cfg.Playbook.IntervalMin)
+	}
+}
+
+func TestLoadSegmentConfig(t *testing.T) {
+	clearEnv()
+
+	cfg := Load()
+	if cfg.Segment.CountIntervalMin != 60 {
+		t.Errorf("expected default segment count interval 60, got %d", cfg.Segment.CountIntervalMin)
+	}
+
+	os.Setenv("SEGMENT_COUNT_INTERVAL_MIN", "0")
+	defer clearEnv()
+
+	cfg = Load()
+	if cfg.Segment.CountIntervalMin != 0 {
+		t.Errorf("expected segment count interval 0, got %d", cfg.Segment.CountIntervalMin)
+	}
+}
+
 func TestValidateProduction(t *testing.T) {
 	clearEnv()
 	os.Setenv("ENVIRONMENT", "production")
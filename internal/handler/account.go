@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// AccountHandler provides account HTTP endpoints.
+type AccountHandler struct {
+	accountService accountServicer
+}
+
+// NewAccountHandler creates a new AccountHandler.
+func NewAccountHandler(as accountServicer) *AccountHandler {
+	return &AccountHandler{accountService: as}
+}
+
+// List handles GET /api/v1/accounts.
+func (h *AccountHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	q := r.URL.Query()
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+
+	params := repository.AccountListParams{
+		OrgID:   orgID,
+		Page:    page,
+		PerPage: perPage,
+		Sort:    q.Get("sort"),
+		Order:   q.Get("order"),
+		Risk:    q.Get("risk"),
+		Search:  q.Get("search"),
+		Source:  q.Get("source"),
+	}
+
+	resp, err := h.accountService.List(r.Context(), params)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetDetail handles GET /api/v1/accounts/{id}.
+func (h *AccountHandler) GetDetail(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid account ID"))
+		return
+	}
+
+	detail, err := h.accountService.GetDetail(r.Context(), accountID, orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detail)
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockAccountService struct {
+	listFn      func(ctx context.Context, params repository.AccountListParams) (*service.AccountListResponse, error)
+	getDetailFn func(ctx context.Context, accountID, orgID uuid.UUID) (*service.AccountDetail, error)
+}
+
+func (m *mockAccountService) List(ctx context.Context, params repository.AccountListParams) (*service.AccountListResponse, error) {
+	return m.listFn(ctx, params)
+}
+
+func (m *mockAccountService) GetDetail(ctx context.Context, accountID, orgID uuid.UUID) (*service.AccountDetail, error) {
+	return m.getDetailFn(ctx, accountID, orgID)
+}
+
+func TestAccountList_Unauthorized(t *testing.T) {
+	h := NewAccountHandler(&mockAccountService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts", nil)
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestAccountList_QueryParams(t *testing.T) {
+	orgID := uuid.New()
+	var captured repository.AccountListParams
+	mock := &mockAccountService{
+		listFn: func(ctx context.Context, params repository.AccountListParams) (*service.AccountListResponse, error) {
+			captured = params
+			return &service.AccountListResponse{
+				Accounts: []repository.AccountWithScore{{Account: repository.Account{Name: "Acme"}}},
+			}, nil
+		},
+	}
+
+	h := NewAccountHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts?page=2&per_page=10&sort=score&order=desc&risk=red&search=acme&source=hubspot", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if captured.OrgID != orgID {
+		t.Errorf("expected orgID %s, got %s", orgID, captured.OrgID)
+	}
+	if captured.Page != 2 || captured.PerPage != 10 {
+		t.Errorf("expected page 2 per_page 10, got %d %d", captured.Page, captured.PerPage)
+	}
+	if captured.Sort != "score" || captured.Order != "desc" {
+		t.Errorf("expected sort score desc, got %s %s", captured.Sort, captured.Order)
+	}
+	if captured.Risk != "red" || captured.Search != "acme" || captured.Source != "hubspot" {
+		t.Errorf("unexpected filters: %+v", captured)
+	}
+
+	var resp service.AccountListResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Accounts) != 1 || resp.Accounts[0].Name != "Acme" {
+		t.Errorf("unexpected accounts: %+v", resp.Accounts)
+	}
+}
+
+func TestAccountList_ServiceError(t *testing.T) {
+	mock := &mockAccountService{
+		listFn: func(ctx context.Context, params repository.AccountListParams) (*service.AccountListResponse, error) {
+			return nil, &service.ValidationError{Field: "risk", Message: "invalid risk level"}
+		},
+	}
+
+	h := NewAccountHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts?risk=purple", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestAccountGetDetail_InvalidUUID(t *testing.T) {
+	h := NewAccountHandler(&mockAccountService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/not-a-uuid", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	req = withChiParam(req, "id", "not-a-uuid")
+	rr := httptest.NewRecorder()
+
+	h.GetDetail(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestAccountGetDetail_NotFound(t *testing.T) {
+	mock := &mockAccountService{
+		getDetailFn: func(ctx context.Context, accountID, orgID uuid.UUID) (*service.AccountDetail, error) {
+			return nil, &service.NotFoundError{Resource: "account", Message: "account not found"}
+		},
+	}
+
+	h := NewAccountHandler(mock)
+	accountID := uuid.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+accountID.String(), nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	req = withChiParam(req, "id", accountID.String())
+	rr := httptest.NewRecorder()
+
+	h.GetDetail(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestAccountGetDetail_Success(t *testing.T) {
+	orgID := uuid.New()
+	accountID := uuid.New()
+	score := 55
+	mock := &mockAccountService{
+		getDetailFn: func(ctx context.Context, aID, oID uuid.UUID) (*service.AccountDetail, error) {
+			if aID != accountID || oID != orgID {
+				t.Errorf("unexpected IDs: %s %s", aID, oID)
+			}
+			return &service.AccountDetail{
+				Account:     &repository.Account{ID: accountID, Name: "Acme"},
+				HealthScore: &repository.AccountHealthScore{OverallScore: 55, RiskLevel: "yellow"},
+				Contacts:    []*repository.AccountContact{{Name: "Jane", OverallScore: &score}},
+			}, nil
+		},
+	}
+
+	h := NewAccountHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/"+accountID.String(), nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "id", accountID.String())
+	rr := httptest.NewRecorder()
+
+	h.GetDetail(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var resp service.AccountDetail
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Account.Name != "Acme" || resp.HealthScore.OverallScore != 55 || len(resp.Contacts) != 1 {
+		t.Errorf("unexpected detail: %+v", resp)
+	}
+}
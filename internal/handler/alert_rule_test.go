@@ -21,6 +21,7 @@ type mockAlertRuleService struct {
 	createFn func(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAlertRuleRequest) (*repository.AlertRule, error)
 	updateFn func(ctx context.Context, id, orgID uuid.UUID, req service.UpdateAlertRuleRequest) (*repository.AlertRule, error)
 	deleteFn func(ctx context.Context, id, orgID uuid.UUID) error
+	backtestFn func(ctx context.Context, orgID uuid.UUID, req service.BacktestAlertRuleRequest) (*service.BacktestResult, error)
 }
 
 func (m *mockAlertRuleService) List(ctx context.Context, orgID uuid.UUID) ([]*repository.AlertRule, error) {
@@ -43,6 +44,10 @@ func (m *mockAlertRuleService) Delete(ctx context.Context, id, orgID uuid.UUID)
 	return m.deleteFn(ctx, id, orgID)
 }
 
+func (m *mockAlertRuleService) Backtest(ctx context.Context, orgID uuid.UUID, req service.BacktestAlertRuleRequest) (*service.BacktestResult, error) {
+	return m.backtestFn(ctx, orgID, req)
+}
+
 func TestAlertRuleList_Unauthorized(t *testing.T) {
 	h := NewAlertRuleHandler(&mockAlertRuleService{})
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/alerts/rules", nil)
@@ -408,3 +413,90 @@ func TestAlertRuleDelete_NotFound(t *testing.T) {
 		t.Fatalf("expected 404, got %d", rr.Code)
 	}
 }
+
+func TestAlertRuleBacktest_Unauthorized(t *testing.T) {
+	h := NewAlertRuleHandler(&mockAlertRuleService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/rules/backtest", nil)
+	rr := httptest.NewRecorder()
+
+	h.Backtest(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestAlertRuleBacktest_InvalidBody(t *testing.T) {
+	orgID := uuid.New()
+	h := NewAlertRuleHandler(&mockAlertRuleService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/rules/backtest", bytes.NewBufferString("not json"))
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	rr := httptest.NewRecorder()
+
+	h.Backtest(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestAlertRuleBacktest_ValidationError(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockAlertRuleService{
+		backtestFn: func(ctx context.Context, oID uuid.UUID, req service.BacktestAlertRuleRequest) (*service.BacktestResult, error) {
+			return nil, &service.ValidationError{Field: "from", Message: "from must be before to"}
+		},
+	}
+
+	h := NewAlertRuleHandler(mock)
+	body, _ := json.Marshal(map[string]any{"trigger_type": "score_below"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/rules/backtest", bytes.NewBuffer(body))
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	rr := httptest.NewRecorder()
+
+	h.Backtest(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestAlertRuleBacktest_Success(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockAlertRuleService{
+		backtestFn: func(ctx context.Context, oID uuid.UUID, req service.BacktestAlertRuleRequest) (*service.BacktestResult, error) {
+			if oID != orgID {
+				t.Errorf("expected orgID %s, got %s", orgID, oID)
+			}
+			if req.TriggerType != "score_below" {
+				t.Errorf("expected trigger_type score_below, got %s", req.TriggerType)
+			}
+			return &service.BacktestResult{TotalAlerts: 3, CustomersAffected: 2}, nil
+		},
+	}
+
+	h := NewAlertRuleHandler(mock)
+	body, _ := json.Marshal(map[string]any{
+		"trigger_type": "score_below",
+		"conditions":   map[string]any{"threshold": 40},
+		"from":         "2026-01-01T00:00:00Z",
+		"to":           "2026-02-01T00:00:00Z",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/rules/backtest", bytes.NewBuffer(body))
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	rr := httptest.NewRecorder()
+
+	h.Backtest(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var resp service.BacktestResult
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TotalAlerts != 3 {
+		t.Errorf("expected 3 alerts, got %d", resp.TotalAlerts)
+	}
+}
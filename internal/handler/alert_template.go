@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// AlertTemplateHandler provides alert template HTTP endpoints.
+type AlertTemplateHandler struct {
+	templateService alertTemplateServicer
+}
+
+// NewAlertTemplateHandler creates a new AlertTemplateHandler.
+func NewAlertTemplateHandler(templateService alertTemplateServicer) *AlertTemplateHandler {
+	return &AlertTemplateHandler{templateService: templateService}
+}
+
+// List handles GET /api/v1/alerts/templates.
+func (h *AlertTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	templates, err := h.templateService.List(r.Context(), orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"templates": templates})
+}
+
+// Variables handles GET /api/v1/alerts/templates/variables.
+func (h *AlertTemplateHandler) Variables(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"variables": h.templateService.Variables()})
+}
+
+// Get handles GET /api/v1/alerts/templates/{id}.
+func (h *AlertTemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid alert template ID"))
+		return
+	}
+
+	tpl, err := h.templateService.GetByID(r.Context(), id, orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tpl)
+}
+
+// Create handles POST /api/v1/alerts/templates.
+func (h *AlertTemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	var req service.CreateAlertTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	tpl, err := h.templateService.Create(r.Context(), orgID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tpl)
+}
+
+// Update handles PATCH /api/v1/alerts/templates/{id}.
+func (h *AlertTemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid alert template ID"))
+		return
+	}
+
+	var req service.UpdateAlertTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	tpl, err := h.templateService.Update(r.Context(), id, orgID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tpl)
+}
+
+// Delete handles DELETE /api/v1/alerts/templates/{id}.
+func (h *AlertTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid alert template ID"))
+		return
+	}
+
+	if err := h.templateService.Delete(r.Context(), id, orgID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// Preview handles POST /api/v1/alerts/templates/preview.
+func (h *AlertTemplateHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	var req service.PreviewAlertTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+	if req.CustomerID == uuid.Nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("customer_id is required"))
+		return
+	}
+
+	rendered, err := h.templateService.Preview(r.Context(), orgID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rendered)
+}
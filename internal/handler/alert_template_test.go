@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockAlertTemplateService struct {
+	listFn    func(ctx context.Context, orgID uuid.UUID) ([]*repository.AlertTemplate, error)
+	getByIDFn func(ctx context.Context, id, orgID uuid.UUID) (*repository.AlertTemplate, error)
+	createFn  func(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAlertTemplateRequest) (*repository.AlertTemplate, error)
+	updateFn  func(ctx context.Context, id, orgID uuid.UUID, req service.UpdateAlertTemplateRequest) (*repository.AlertTemplate, error)
+	deleteFn  func(ctx context.Context, id, orgID uuid.UUID) error
+	previewFn func(ctx context.Context, orgID uuid.UUID, req service.PreviewAlertTemplateRequest) (*service.RenderedAlertEmail, error)
+}
+
+func (m *mockAlertTemplateService) List(ctx context.Context, orgID uuid.UUID) ([]*repository.AlertTemplate, error) {
+	return m.listFn(ctx, orgID)
+}
+
+func (m *mockAlertTemplateService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*repository.AlertTemplate, error) {
+	return m.getByIDFn(ctx, id, orgID)
+}
+
+func (m *mockAlertTemplateService) Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAlertTemplateRequest) (*repository.AlertTemplate, error) {
+	return m.createFn(ctx, orgID, userID, req)
+}
+
+func (m *mockAlertTemplateService) Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdateAlertTemplateRequest) (*repository.AlertTemplate, error) {
+	return m.updateFn(ctx, id, orgID, req)
+}
+
+func (m *mockAlertTemplateService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	return m.deleteFn(ctx, id, orgID)
+}
+
+func (m *mockAlertTemplateService) Variables() []service.AlertTemplateVariable {
+	return service.AlertTemplateVariables
+}
+
+func (m *mockAlertTemplateService) Preview(ctx context.Context, orgID uuid.UUID, req service.PreviewAlertTemplateRequest) (*service.RenderedAlertEmail, error) {
+	return m.previewFn(ctx, orgID, req)
+}
+
+func TestAlertTemplateList_Unauthorized(t *testing.T) {
+	h := NewAlertTemplateHandler(&mockAlertTemplateService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/alerts/templates", nil)
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestAlertTemplateCreate_UnknownVariable(t *testing.T) {
+	mock := &mockAlertTemplateService{
+		createFn: func(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAlertTemplateRequest) (*repository.AlertTemplate, error) {
+			return nil, &service.ValidationError{Field: "subject_template", Message: `unknown variable ".Customer.Phone"`}
+		},
+	}
+
+	h := NewAlertTemplateHandler(mock)
+	body, _ := json.Marshal(map[string]any{
+		"trigger_type":     "score_drop",
+		"subject_template": "{{.Customer.Phone}}",
+		"body_template":    "<p>hi</p>",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/templates", bytes.NewReader(body))
+	ctx := auth.WithOrgID(req.Context(), uuid.New())
+	ctx = auth.WithUserID(ctx, uuid.New())
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+	var resp map[string]any
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp["field"] != "subject_template" {
+		t.Errorf("expected field subject_template, got %v", resp["field"])
+	}
+}
+
+func TestAlertTemplateDelete_NotFound(t *testing.T) {
+	mock := &mockAlertTemplateService{
+		deleteFn: func(ctx context.Context, id, orgID uuid.UUID) error {
+			return &service.NotFoundError{Resource: "alert_template", Message: "alert template not found"}
+		},
+	}
+
+	h := NewAlertTemplateHandler(mock)
+	id := uuid.New()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/alerts/templates/"+id.String(), nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id.String())
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = auth.WithOrgID(ctx, uuid.New())
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.Delete(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestAlertTemplatePreview_MissingCustomer(t *testing.T) {
+	h := NewAlertTemplateHandler(&mockAlertTemplateService{})
+	body, _ := json.Marshal(map[string]any{"subject_template": "x", "body_template": "y"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/templates/preview", bytes.NewReader(body))
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.Preview(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestAlertTemplatePreview_Success(t *testing.T) {
+	orgID := uuid.New()
+	customerID := uuid.New()
+	mock := &mockAlertTemplateService{
+		previewFn: func(ctx context.Context, oID uuid.UUID, req service.PreviewAlertTemplateRequest) (*service.RenderedAlertEmail, error) {
+			if oID != orgID || req.CustomerID != customerID {
+				t.Errorf("unexpected org or customer")
+			}
+			return &service.RenderedAlertEmail{Subject: "Acme dropped", HTMLBody: "<p>Acme</p>", TextBody: "Acme"}, nil
+		},
+	}
+
+	h := NewAlertTemplateHandler(mock)
+	body, _ := json.Marshal(map[string]any{
+		"customer_id":      customerID,
+		"trigger_type":     "score_drop",
+		"subject_template": "{{.Customer.Name}} dropped",
+		"body_template":    "<p>{{.Customer.Name}}</p>",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/templates/preview", bytes.NewReader(body))
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	rr := httptest.NewRecorder()
+
+	h.Preview(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var resp service.RenderedAlertEmail
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp.Subject != "Acme dropped" {
+		t.Errorf("expected subject 'Acme dropped', got %q", resp.Subject)
+	}
+}
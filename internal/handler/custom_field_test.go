@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockCustomFieldService struct {
+	listFn       func(ctx context.Context, orgID uuid.UUID) ([]*repository.CustomField, error)
+	createFn     func(ctx context.Context, orgID, userID uuid.UUID, req service.CreateCustomFieldRequest) (*repository.CustomField, error)
+	updateFn     func(ctx context.Context, id, orgID uuid.UUID, req service.UpdateCustomFieldRequest) (*repository.CustomField, error)
+	deleteFn     func(ctx context.Context, id, orgID uuid.UUID) error
+	listValuesFn func(ctx context.Context, orgID, customerID uuid.UUID) ([]service.CustomerFieldValue, error)
+	setValuesFn  func(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetCustomerFieldValuesRequest) ([]service.CustomerFieldValue, error)
+}
+
+func (m *mockCustomFieldService) List(ctx context.Context, orgID uuid.UUID) ([]*repository.CustomField, error) {
+	return m.listFn(ctx, orgID)
+}
+
+func (m *mockCustomFieldService) Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateCustomFieldRequest) (*repository.CustomField, error) {
+	return m.createFn(ctx, orgID, userID, req)
+}
+
+func (m *mockCustomFieldService) Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdateCustomFieldRequest) (*repository.CustomField, error) {
+	return m.updateFn(ctx, id, orgID, req)
+}
+
+func (m *mockCustomFieldService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	return m.deleteFn(ctx, id, orgID)
+}
+
+func (m *mockCustomFieldService) ListValues(ctx context.Context, orgID, customerID uuid.UUID) ([]service.CustomerFieldValue, error) {
+	return m.listValuesFn(ctx, orgID, customerID)
+}
+
+func (m *mockCustomFieldService) SetValues(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetCustomerFieldValuesRequest) ([]service.CustomerFieldValue, error) {
+	return m.setValuesFn(ctx, orgID, customerID, userID, req)
+}
+
+func TestCustomFieldList_Unauthorized(t *testing.T) {
+	h := NewCustomFieldHandler(&mockCustomFieldService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/custom-fields", nil)
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestCustomFieldCreate_Success(t *testing.T) {
+	orgID, userID := uuid.New(), uuid.New()
+	mock := &mockCustomFieldService{
+		createFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreateCustomFieldRequest) (*repository.CustomField, error) {
+			if oID != orgID || uID != userID {
+				t.Fatalf("unexpected org %s or caller %s", oID, uID)
+			}
+			if req.Key != "industry" || req.FieldType != "string" || req.Source == nil || *req.Source != "hubspot" {
+				t.Fatalf("unexpected request %+v", req)
+			}
+			return &repository.CustomField{ID: uuid.New(), Key: req.Key, Label: req.Label, FieldType: req.FieldType, Source: req.Source, SourceKey: req.SourceKey}, nil
+		},
+	}
+
+	h := NewCustomFieldHandler(mock)
+	body := `{"key":"industry","label":"Industry","field_type":"string","source":"hubspot","source_key":"industry"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/custom-fields", strings.NewReader(body))
+	req = withOrgAndUser(req, orgID, userID)
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp repository.CustomField
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Key != "industry" || resp.SourceKey == nil || *resp.SourceKey != "industry" {
+		t.Errorf("unexpected field %+v", resp)
+	}
+}
+
+func TestCustomFieldCreate_Conflict(t *testing.T) {
+	mock := &mockCustomFieldService{
+		createFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreateCustomFieldRequest) (*repository.CustomField, error) {
+			return nil, &service.ConflictError{Message: "a custom field with this key already exists"}
+		},
+	}
+
+	h := NewCustomFieldHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/custom-fields", strings.NewReader(`{"key":"tier","label":"Tier","field_type":"enum","options":["gold"]}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestCustomFieldUpdate_InvalidID(t *testing.T) {
+	h := NewCustomFieldHandler(&mockCustomFieldService{})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/custom-fields/x", strings.NewReader(`{}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", "not-a-uuid")
+	rr := httptest.NewRecorder()
+
+	h.Update(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestCustomFieldDelete_InUse(t *testing.T) {
+	mock := &mockCustomFieldService{
+		deleteFn: func(ctx context.Context, id, oID uuid.UUID) error {
+			return &service.ConflictError{Message: "custom field is used by 1 alert rule(s)"}
+		},
+	}
+
+	h := NewCustomFieldHandler(mock)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/custom-fields/x", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Delete(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestCustomFieldListValues_Success(t *testing.T) {
+	customerID := uuid.New()
+	mock := &mockCustomFieldService{
+		listValuesFn: func(ctx context.Context, oID, cID uuid.UUID) ([]service.CustomerFieldValue, error) {
+			if cID != customerID {
+				t.Fatalf("unexpected customer %s", cID)
+			}
+			return []service.CustomerFieldValue{
+				{Key: "seats", FieldType: "number", Value: 42.0},
+				{Key: "tier", FieldType: "enum"},
+			}, nil
+		},
+	}
+
+	h := NewCustomFieldHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/x/fields", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", customerID.String())
+	rr := httptest.NewRecorder()
+
+	h.ListValues(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Fields []service.CustomerFieldValue `json:"fields"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Fields) != 2 || resp.Fields[0].Value != 42.0 || resp.Fields[1].Value != nil {
+		t.Errorf("unexpected fields %+v", resp.Fields)
+	}
+}
+
+func TestCustomFieldSetValues_NullClears(t *testing.T) {
+	mock := &mockCustomFieldService{
+		setValuesFn: func(ctx context.Context, oID, cID, uID uuid.UUID, req service.SetCustomerFieldValuesRequest) ([]service.CustomerFieldValue, error) {
+			v, ok := req.Values["tier"]
+			if !ok || v != nil {
+				t.Fatalf("expected tier to be cleared, got %v", req.Values)
+			}
+			if req.Values["renewal_on"] != "2026-12-01" {
+				t.Fatalf("unexpected renewal_on %v", req.Values["renewal_on"])
+			}
+			return []service.CustomerFieldValue{}, nil
+		},
+	}
+
+	h := NewCustomFieldHandler(mock)
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/customers/x/fields", strings.NewReader(`{"values":{"tier":null,"renewal_on":"2026-12-01"}}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.SetValues(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestCustomFieldSetValues_ReadOnly(t *testing.T) {
+	mock := &mockCustomFieldService{
+		setValuesFn: func(ctx context.Context, oID, cID, uID uuid.UUID, req service.SetCustomerFieldValuesRequest) ([]service.CustomerFieldValue, error) {
+			return nil, &service.ValidationError{Field: "values.industry", Message: "value is synced from hubspot and cannot be edited"}
+		},
+	}
+
+	h := NewCustomFieldHandler(mock)
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/customers/x/fields", strings.NewReader(`{"values":{"industry":"Retail"}}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.SetValues(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"encoding/csv"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -24,10 +28,96 @@ func NewCustomerHandler(cs customerServicer) *CustomerHandler {
 
 // List handles GET /api/v1/customers.
 func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
+	params, ok := h.listParams(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.customerService.List(r.Context(), params)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// customerExportColumns are the columns of a customer CSV export.
+var customerExportColumns = []string{
+	"id", "name", "email", "company_name", "source", "mrr_cents",
+	"overall_score", "risk_level", "tags", "owner_ids", "last_seen_at",
+}
+
+// Export handles GET /api/v1/customers/export. It takes the same filters as
+// List and writes every matching customer as CSV.
+func (h *CustomerHandler) Export(w http.ResponseWriter, r *http.Request) {
+	params, ok := h.listParams(w, r)
+	if !ok {
+		return
+	}
+
+	// Headers are only sent with the first row so that errors found before
+	// then still get a JSON error response.
+	var cw *csv.Writer
+	start := func() {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="customers-`+time.Now().UTC().Format("2006-01-02")+`.csv"`)
+		w.WriteHeader(http.StatusOK)
+		cw = csv.NewWriter(w)
+		_ = cw.Write(customerExportColumns)
+	}
+
+	err := h.customerService.Export(r.Context(), params, func(c repository.CustomerWithScore) error {
+		if cw == nil {
+			start()
+		}
+		return cw.Write(customerExportRow(c))
+	})
+	if err != nil {
+		if cw == nil {
+			handleServiceError(w, err)
+			return
+		}
+		slog.Error("customer export failed", "org_id", params.OrgID, "error", err)
+		return
+	}
+
+	if cw == nil {
+		start()
+	}
+	cw.Flush()
+}
+
+func customerExportRow(c repository.CustomerWithScore) []string {
+	var score, risk, lastSeen string
+	if c.OverallScore != nil {
+		score = strconv.Itoa(*c.OverallScore)
+	}
+	if c.RiskLevel != nil {
+		risk = *c.RiskLevel
+	}
+	if c.LastSeenAt != nil {
+		lastSeen = c.LastSeenAt.UTC().Format(time.RFC3339)
+	}
+	ownerIDs := make([]string, len(c.OwnerIDs))
+	for i, id := range c.OwnerIDs {
+		ownerIDs[i] = id.String()
+	}
+
+	return []string{
+		c.ID.String(), c.Name, c.Email, c.CompanyName, c.Source,
+		strconv.Itoa(c.MRRCents), score, risk,
+		strings.Join(c.Tags, ","), strings.Join(ownerIDs, ","), lastSeen,
+	}
+}
+
+// listParams reads the customer list filters shared by List and Export. It
+// writes the error response and returns false when they are invalid.
+func (h *CustomerHandler) listParams(w http.ResponseWriter, r *http.Request) (repository.CustomerListParams, bool) {
 	orgID, ok := auth.GetOrgID(r.Context())
 	if !ok {
 		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
-		return
+		return repository.CustomerListParams{}, false
 	}
 
 	q := r.URL.Query()
@@ -46,13 +136,76 @@ func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
 		Source:  q.Get("source"),
 	}
 
-	resp, err := h.customerService.List(r.Context(), params)
-	if err != nil {
-		handleServiceError(w, err)
-		return
+	// owner=me lists the caller's own customers, owner=none the unowned ones
+	switch owner := q.Get("owner"); owner {
+	case "":
+	case "me":
+		userID, ok := auth.GetUserID(r.Context())
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+			return params, false
+		}
+		params.OwnerID = &userID
+	case "none":
+		params.Unowned = true
+	default:
+		ownerID, err := uuid.Parse(owner)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid owner"))
+			return params, false
+		}
+		params.OwnerID = &ownerID
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	// tag=a,b lists customers with every given tag
+	for _, v := range q["tag"] {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				params.Tags = append(params.Tags, tag)
+			}
+		}
+	}
+
+	if v := q.Get("segment_id"); v != "" {
+		segmentID, err := uuid.Parse(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid segment ID"))
+			return params, false
+		}
+		params.SegmentID = &segmentID
+	}
+
+	// field.<key>=v filters on a custom field's value; field.<key>.<op>=v
+	// uses another op, with in and not_in taking a comma-separated list
+	names := make([]string, 0, len(q))
+	for name := range q {
+		if strings.HasPrefix(name, "field.") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		key, op := strings.TrimPrefix(name, "field."), "eq"
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key, op = key[:i], key[i+1:]
+		}
+		for _, v := range q[name] {
+			var value any = v
+			switch op {
+			case "in", "not_in":
+				var list []any
+				for _, item := range strings.Split(v, ",") {
+					list = append(list, strings.TrimSpace(item))
+				}
+				value = list
+			case "exists", "not_exists":
+				value = nil
+			}
+			params.Fields = append(params.Fields, repository.SegmentFilter{Field: "field." + key, Op: op, Value: value})
+		}
+	}
+
+	return params, true
 }
 
 // GetDetail handles GET /api/v1/customers/{id}.
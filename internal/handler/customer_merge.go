@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+)
+
+// CustomerMergeHandler provides customer merge HTTP endpoints.
+type CustomerMergeHandler struct {
+	mergeService customerMergeServicer
+}
+
+// NewCustomerMergeHandler creates a new CustomerMergeHandler.
+func NewCustomerMergeHandler(mergeService customerMergeServicer) *CustomerMergeHandler {
+	return &CustomerMergeHandler{mergeService: mergeService}
+}
+
+type mergeCustomerRequest struct {
+	DuplicateID string `json:"duplicate_id"`
+}
+
+// Merge handles POST /api/v1/customers/{id}/merge.
+func (h *CustomerMergeHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	primaryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	var req mergeCustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+	duplicateID, err := uuid.Parse(req.DuplicateID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid duplicate_id"))
+		return
+	}
+
+	merge, err := h.mergeService.Merge(r.Context(), orgID, primaryID, duplicateID, &userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, merge)
+}
+
+// ListMerges handles GET /api/v1/customers/{id}/merges.
+func (h *CustomerMergeHandler) ListMerges(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	merges, err := h.mergeService.ListMerges(r.Context(), orgID, customerID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"merges": merges})
+}
+
+// Unmerge handles POST /api/v1/customer-merges/{id}/unmerge.
+func (h *CustomerMergeHandler) Unmerge(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	mergeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid merge ID"))
+		return
+	}
+
+	merge, err := h.mergeService.Unmerge(r.Context(), orgID, mergeID, &userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, merge)
+}
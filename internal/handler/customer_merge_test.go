@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockCustomerMergeService struct {
+	mergeFn      func(ctx context.Context, orgID, primaryID, duplicateID uuid.UUID, userID *uuid.UUID) (*repository.CustomerMerge, error)
+	listMergesFn func(ctx context.Context, orgID, customerID uuid.UUID) ([]*repository.CustomerMerge, error)
+	unmergeFn    func(ctx context.Context, orgID, mergeID uuid.UUID, userID *uuid.UUID) (*repository.CustomerMerge, error)
+}
+
+func (m *mockCustomerMergeService) Merge(ctx context.Context, orgID, primaryID, duplicateID uuid.UUID, userID *uuid.UUID) (*repository.CustomerMerge, error) {
+	return m.mergeFn(ctx, orgID, primaryID, duplicateID, userID)
+}
+
+func (m *mockCustomerMergeService) ListMerges(ctx context.Context, orgID, customerID uuid.UUID) ([]*repository.CustomerMerge, error) {
+	return m.listMergesFn(ctx, orgID, customerID)
+}
+
+func (m *mockCustomerMergeService) Unmerge(ctx context.Context, orgID, mergeID uuid.UUID, userID *uuid.UUID) (*repository.CustomerMerge, error) {
+	return m.unmergeFn(ctx, orgID, mergeID, userID)
+}
+
+func withOrgAndUser(req *http.Request, orgID, userID uuid.UUID) *http.Request {
+	ctx := auth.WithOrgID(req.Context(), orgID)
+	ctx = auth.WithUserID(ctx, userID)
+	return req.WithContext(ctx)
+}
+
+func TestCustomerMerge_Unauthorized(t *testing.T) {
+	h := NewCustomerMergeHandler(&mockCustomerMergeService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/x/merge", nil)
+	rr := httptest.NewRecorder()
+
+	h.Merge(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestCustomerMerge_InvalidDuplicateID(t *testing.T) {
+	h := NewCustomerMergeHandler(&mockCustomerMergeService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/x/merge", strings.NewReader(`{"duplicate_id":"nope"}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Merge(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestCustomerMerge_Success(t *testing.T) {
+	orgID, userID := uuid.New(), uuid.New()
+	primaryID, duplicateID := uuid.New(), uuid.New()
+	mock := &mockCustomerMergeService{
+		mergeFn: func(ctx context.Context, oID, pID, dID uuid.UUID, uID *uuid.UUID) (*repository.CustomerMerge, error) {
+			if pID != primaryID || dID != duplicateID {
+				t.Fatalf("unexpected merge %s <- %s", pID, dID)
+			}
+			if uID == nil || *uID != userID {
+				t.Fatal("expected the caller to be recorded")
+			}
+			return &repository.CustomerMerge{ID: uuid.New(), PrimaryID: pID, MergedID: dID, Reason: service.MergeReasonManual}, nil
+		},
+	}
+
+	h := NewCustomerMergeHandler(mock)
+	body := `{"duplicate_id":"` + duplicateID.String() + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/x/merge", strings.NewReader(body))
+	req = withOrgAndUser(req, orgID, userID)
+	req = withChiParam(req, "id", primaryID.String())
+	rr := httptest.NewRecorder()
+
+	h.Merge(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestCustomerMerge_IntoItself(t *testing.T) {
+	id := uuid.New()
+	mock := &mockCustomerMergeService{
+		mergeFn: func(ctx context.Context, oID, pID, dID uuid.UUID, uID *uuid.UUID) (*repository.CustomerMerge, error) {
+			return nil, &service.ValidationError{Field: "duplicate_id", Message: "a customer cannot be merged into itself"}
+		},
+	}
+
+	h := NewCustomerMergeHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/x/merge", strings.NewReader(`{"duplicate_id":"`+id.String()+`"}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", id.String())
+	rr := httptest.NewRecorder()
+
+	h.Merge(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestCustomerListMerges_Success(t *testing.T) {
+	customerID := uuid.New()
+	mock := &mockCustomerMergeService{
+		listMergesFn: func(ctx context.Context, oID, cID uuid.UUID) ([]*repository.CustomerMerge, error) {
+			if cID != customerID {
+				t.Fatalf("expected customer %s, got %s", customerID, cID)
+			}
+			return []*repository.CustomerMerge{{ID: uuid.New(), PrimaryID: cID, MergedID: uuid.New()}}, nil
+		},
+	}
+
+	h := NewCustomerMergeHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/x/merges", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	req = withChiParam(req, "id", customerID.String())
+	rr := httptest.NewRecorder()
+
+	h.ListMerges(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestCustomerUnmerge_AlreadyUndone(t *testing.T) {
+	mock := &mockCustomerMergeService{
+		unmergeFn: func(ctx context.Context, oID, mID uuid.UUID, uID *uuid.UUID) (*repository.CustomerMerge, error) {
+			return nil, &service.ConflictError{Resource: "merge", Message: "merge was already undone"}
+		},
+	}
+
+	h := NewCustomerMergeHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customer-merges/x/unmerge", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Unmerge(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestCustomerUnmerge_NotFound(t *testing.T) {
+	mock := &mockCustomerMergeService{
+		unmergeFn: func(ctx context.Context, oID, mID uuid.UUID, uID *uuid.UUID) (*repository.CustomerMerge, error) {
+			return nil, &service.NotFoundError{Resource: "merge", Message: "merge not found"}
+		},
+	}
+
+	h := NewCustomerMergeHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customer-merges/x/unmerge", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Unmerge(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// CustomerNoteHandler provides customer note HTTP endpoints.
+type CustomerNoteHandler struct {
+	noteService customerNoteServicer
+}
+
+// NewCustomerNoteHandler creates a new CustomerNoteHandler.
+func NewCustomerNoteHandler(noteService customerNoteServicer) *CustomerNoteHandler {
+	return &CustomerNoteHandler{noteService: noteService}
+}
+
+// List handles GET /api/v1/customers/{id}/notes.
+func (h *CustomerNoteHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+
+	resp, err := h.noteService.List(r.Context(), repository.NoteListParams{
+		OrgID:      orgID,
+		CustomerID: customerID,
+		Page:       page,
+		PerPage:    perPage,
+		PinnedOnly: q.Get("pinned") == "true",
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Create handles POST /api/v1/customers/{id}/notes.
+func (h *CustomerNoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	var req service.CreateNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	note, err := h.noteService.Create(r.Context(), orgID, customerID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, note)
+}
+
+// Update handles PATCH /api/v1/customers/{id}/notes/{noteId}.
+func (h *CustomerNoteHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, noteID, ok := parseNoteParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	note, err := h.noteService.Update(r.Context(), orgID, customerID, noteID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, note)
+}
+
+// Delete handles DELETE /api/v1/customers/{id}/notes/{noteId}.
+func (h *CustomerNoteHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, noteID, ok := parseNoteParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.noteService.Delete(r.Context(), orgID, customerID, noteID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// ListRevisions handles GET /api/v1/customers/{id}/notes/{noteId}/revisions.
+func (h *CustomerNoteHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, noteID, ok := parseNoteParams(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.noteService.ListRevisions(r.Context(), orgID, customerID, noteID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"revisions": revisions})
+}
+
+// parseNoteParams parses the customer and note IDs from the URL, writing a
+// 400 response if either is invalid.
+func parseNoteParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	noteID, err := uuid.Parse(chi.URLParam(r, "noteId"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid note ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return customerID, noteID, true
+}
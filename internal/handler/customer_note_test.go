@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockCustomerNoteService struct {
+	listFn          func(ctx context.Context, params repository.NoteListParams) (*service.NoteListResponse, error)
+	createFn        func(ctx context.Context, orgID, customerID, authorID uuid.UUID, req service.CreateNoteRequest) (*repository.CustomerNote, error)
+	updateFn        func(ctx context.Context, orgID, customerID, noteID, userID uuid.UUID, req service.UpdateNoteRequest) (*repository.CustomerNote, error)
+	deleteFn        func(ctx context.Context, orgID, customerID, noteID, userID uuid.UUID) error
+	listRevisionsFn func(ctx context.Context, orgID, customerID, noteID uuid.UUID) ([]*repository.CustomerNoteRevision, error)
+}
+
+func (m *mockCustomerNoteService) List(ctx context.Context, params repository.NoteListParams) (*service.NoteListResponse, error) {
+	return m.listFn(ctx, params)
+}
+
+func (m *mockCustomerNoteService) Create(ctx context.Context, orgID, customerID, authorID uuid.UUID, req service.CreateNoteRequest) (*repository.CustomerNote, error) {
+	return m.createFn(ctx, orgID, customerID, authorID, req)
+}
+
+func (m *mockCustomerNoteService) Update(ctx context.Context, orgID, customerID, noteID, userID uuid.UUID, req service.UpdateNoteRequest) (*repository.CustomerNote, error) {
+	return m.updateFn(ctx, orgID, customerID, noteID, userID, req)
+}
+
+func (m *mockCustomerNoteService) Delete(ctx context.Context, orgID, customerID, noteID, userID uuid.UUID) error {
+	return m.deleteFn(ctx, orgID, customerID, noteID, userID)
+}
+
+func (m *mockCustomerNoteService) ListRevisions(ctx context.Context, orgID, customerID, noteID uuid.UUID) ([]*repository.CustomerNoteRevision, error) {
+	return m.listRevisionsFn(ctx, orgID, customerID, noteID)
+}
+
+func withNoteParams(r *http.Request, customerID, noteID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", customerID)
+	rctx.URLParams.Add("noteId", noteID)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestCustomerNoteCreate_Unauthorized(t *testing.T) {
+	h := NewCustomerNoteHandler(&mockCustomerNoteService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/x/notes", strings.NewReader(`{"body":"hi"}`))
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestCustomerNoteCreate_Success(t *testing.T) {
+	orgID, userID, customerID := uuid.New(), uuid.New(), uuid.New()
+	mentioned := uuid.New()
+	mock := &mockCustomerNoteService{
+		createFn: func(ctx context.Context, oID, cID, aID uuid.UUID, req service.CreateNoteRequest) (*repository.CustomerNote, error) {
+			if cID != customerID || aID != userID {
+				t.Fatalf("unexpected customer %s or author %s", cID, aID)
+			}
+			if len(req.Mentions) != 1 || req.Mentions[0] != mentioned {
+				t.Fatalf("expected mention of %s, got %v", mentioned, req.Mentions)
+			}
+			return &repository.CustomerNote{ID: uuid.New(), CustomerID: cID, AuthorID: &aID, Body: req.Body, Mentions: req.Mentions}, nil
+		},
+	}
+
+	h := NewCustomerNoteHandler(mock)
+	body := `{"body":"QBR held, renewal at risk","mentions":["` + mentioned.String() + `"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/x/notes", strings.NewReader(body))
+	req = withOrgAndUser(req, orgID, userID)
+	req = withChiParam(req, "id", customerID.String())
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var note repository.CustomerNote
+	if err := json.NewDecoder(rr.Body).Decode(&note); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if note.Body != "QBR held, renewal at risk" {
+		t.Errorf("unexpected body %q", note.Body)
+	}
+}
+
+func TestCustomerNoteCreate_ValidationError(t *testing.T) {
+	mock := &mockCustomerNoteService{
+		createFn: func(ctx context.Context, oID, cID, aID uuid.UUID, req service.CreateNoteRequest) (*repository.CustomerNote, error) {
+			return nil, &service.ValidationError{Field: "body", Message: "body is required"}
+		},
+	}
+
+	h := NewCustomerNoteHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/x/notes", strings.NewReader(`{"body":""}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestCustomerNoteList_PinnedFilter(t *testing.T) {
+	orgID, customerID := uuid.New(), uuid.New()
+	mock := &mockCustomerNoteService{
+		listFn: func(ctx context.Context, params repository.NoteListParams) (*service.NoteListResponse, error) {
+			if params.CustomerID != customerID || !params.PinnedOnly || params.PerPage != 10 {
+				t.Fatalf("unexpected params %+v", params)
+			}
+			return &service.NoteListResponse{Notes: []*repository.CustomerNote{}}, nil
+		},
+	}
+
+	h := NewCustomerNoteHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/x/notes?pinned=true&per_page=10", nil)
+	req = withOrgAndUser(req, orgID, uuid.New())
+	req = withChiParam(req, "id", customerID.String())
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestCustomerNoteUpdate_InvalidNoteID(t *testing.T) {
+	h := NewCustomerNoteHandler(&mockCustomerNoteService{})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/customers/x/notes/y", strings.NewReader(`{"pinned":true}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withNoteParams(req, uuid.New().String(), "not-a-uuid")
+	rr := httptest.NewRecorder()
+
+	h.Update(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestCustomerNoteUpdate_NotAuthor(t *testing.T) {
+	mock := &mockCustomerNoteService{
+		updateFn: func(ctx context.Context, oID, cID, nID, uID uuid.UUID, req service.UpdateNoteRequest) (*repository.CustomerNote, error) {
+			return nil, &service.ForbiddenError{Message: "only the author can edit a note"}
+		},
+	}
+
+	h := NewCustomerNoteHandler(mock)
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/customers/x/notes/y", strings.NewReader(`{"body":"edited"}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withNoteParams(req, uuid.New().String(), uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Update(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rr.Code)
+	}
+}
+
+func TestCustomerNoteDelete_Success(t *testing.T) {
+	noteID := uuid.New()
+	mock := &mockCustomerNoteService{
+		deleteFn: func(ctx context.Context, oID, cID, nID, uID uuid.UUID) error {
+			if nID != noteID {
+				t.Fatalf("unexpected note %s", nID)
+			}
+			return nil
+		},
+	}
+
+	h := NewCustomerNoteHandler(mock)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/customers/x/notes/y", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withNoteParams(req, uuid.New().String(), noteID.String())
+	rr := httptest.NewRecorder()
+
+	h.Delete(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rr.Code)
+	}
+}
+
+func TestCustomerNoteListRevisions_NotFound(t *testing.T) {
+	mock := &mockCustomerNoteService{
+		listRevisionsFn: func(ctx context.Context, oID, cID, nID uuid.UUID) ([]*repository.CustomerNoteRevision, error) {
+			return nil, &service.NotFoundError{Resource: "note", Message: "note not found"}
+		},
+	}
+
+	h := NewCustomerNoteHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/x/notes/y/revisions", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withNoteParams(req, uuid.New().String(), uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.ListRevisions(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockCustomerOwnerService struct {
+	listOwnersFn     func(ctx context.Context, orgID, customerID uuid.UUID) ([]*repository.CustomerOwner, error)
+	setOwnersFn      func(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetOwnersRequest) ([]*repository.CustomerOwner, error)
+	bulkAssignFn     func(ctx context.Context, orgID, userID uuid.UUID, req service.BulkAssignOwnersRequest) (*service.BulkAssignOwnersResult, error)
+	assignUnownedFn  func(ctx context.Context, orgID uuid.UUID) (*service.AutoAssignResult, error)
+	bookOfBusinessFn func(ctx context.Context, orgID uuid.UUID) (*service.BookOfBusiness, error)
+	listRulesFn      func(ctx context.Context, orgID uuid.UUID) ([]*repository.CustomerAssignmentRule, error)
+	createRuleFn     func(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error)
+	updateRuleFn     func(ctx context.Context, id, orgID uuid.UUID, req service.UpdateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error)
+	deleteRuleFn     func(ctx context.Context, id, orgID uuid.UUID) error
+}
+
+func (m *mockCustomerOwnerService) ListOwners(ctx context.Context, orgID, customerID uuid.UUID) ([]*repository.CustomerOwner, error) {
+	return m.listOwnersFn(ctx, orgID, customerID)
+}
+
+func (m *mockCustomerOwnerService) SetOwners(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetOwnersRequest) ([]*repository.CustomerOwner, error) {
+	return m.setOwnersFn(ctx, orgID, customerID, userID, req)
+}
+
+func (m *mockCustomerOwnerService) BulkAssign(ctx context.Context, orgID, userID uuid.UUID, req service.BulkAssignOwnersRequest) (*service.BulkAssignOwnersResult, error) {
+	return m.bulkAssignFn(ctx, orgID, userID, req)
+}
+
+func (m *mockCustomerOwnerService) AssignUnowned(ctx context.Context, orgID uuid.UUID) (*service.AutoAssignResult, error) {
+	return m.assignUnownedFn(ctx, orgID)
+}
+
+func (m *mockCustomerOwnerService) BookOfBusiness(ctx context.Context, orgID uuid.UUID) (*service.BookOfBusiness, error) {
+	return m.bookOfBusinessFn(ctx, orgID)
+}
+
+func (m *mockCustomerOwnerService) ListRules(ctx context.Context, orgID uuid.UUID) ([]*repository.CustomerAssignmentRule, error) {
+	return m.listRulesFn(ctx, orgID)
+}
+
+func (m *mockCustomerOwnerService) CreateRule(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error) {
+	return m.createRuleFn(ctx, orgID, userID, req)
+}
+
+func (m *mockCustomerOwnerService) UpdateRule(ctx context.Context, id, orgID uuid.UUID, req service.UpdateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error) {
+	return m.updateRuleFn(ctx, id, orgID, req)
+}
+
+func (m *mockCustomerOwnerService) DeleteRule(ctx context.Context, id, orgID uuid.UUID) error {
+	return m.deleteRuleFn(ctx, id, orgID)
+}
+
+func TestCustomerOwnerSet_Unauthorized(t *testing.T) {
+	h := NewCustomerOwnerHandler(&mockCustomerOwnerService{})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/x/owners", strings.NewReader(`{"user_ids":[]}`))
+	rr := httptest.NewRecorder()
+
+	h.SetOwners(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestCustomerOwnerSet_Success(t *testing.T) {
+	orgID, userID, customerID, ownerID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	mock := &mockCustomerOwnerService{
+		setOwnersFn: func(ctx context.Context, oID, cID, uID uuid.UUID, req service.SetOwnersRequest) ([]*repository.CustomerOwner, error) {
+			if cID != customerID || uID != userID {
+				t.Fatalf("unexpected customer %s or caller %s", cID, uID)
+			}
+			if len(req.UserIDs) != 1 || req.UserIDs[0] != ownerID {
+				t.Fatalf("unexpected owners %v", req.UserIDs)
+			}
+			return []*repository.CustomerOwner{{UserID: ownerID, Email: "csm@example.com"}}, nil
+		},
+	}
+
+	h := NewCustomerOwnerHandler(mock)
+	body := `{"user_ids":["` + ownerID.String() + `"]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/x/owners", strings.NewReader(body))
+	req = withOrgAndUser(req, orgID, userID)
+	req = withChiParam(req, "id", customerID.String())
+	rr := httptest.NewRecorder()
+
+	h.SetOwners(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Owners []repository.CustomerOwner `json:"owners"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Owners) != 1 || resp.Owners[0].UserID != ownerID {
+		t.Errorf("unexpected owners %+v", resp.Owners)
+	}
+}
+
+func TestCustomerOwnerBulkAssign_Segment(t *testing.T) {
+	ownerID := uuid.New()
+	mock := &mockCustomerOwnerService{
+		bulkAssignFn: func(ctx context.Context, oID, uID uuid.UUID, req service.BulkAssignOwnersRequest) (*service.BulkAssignOwnersResult, error) {
+			if req.Segment.Risk != "red" || req.Segment.MinMRRCents == nil || *req.Segment.MinMRRCents != 50000 {
+				t.Fatalf("unexpected segment %+v", req.Segment)
+			}
+			if req.Mode != "replace" {
+				t.Fatalf("expected replace mode, got %q", req.Mode)
+			}
+			return &service.BulkAssignOwnersResult{Matched: 12}, nil
+		},
+	}
+
+	h := NewCustomerOwnerHandler(mock)
+	body := `{"user_ids":["` + ownerID.String() + `"],"segment":{"risk":"red","min_mrr_cents":50000},"mode":"replace"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customer-owners/bulk", strings.NewReader(body))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.BulkAssign(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var result service.BulkAssignOwnersResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if result.Matched != 12 {
+		t.Errorf("expected 12 matched, got %d", result.Matched)
+	}
+}
+
+func TestCustomerOwnerBulkAssign_ValidationError(t *testing.T) {
+	mock := &mockCustomerOwnerService{
+		bulkAssignFn: func(ctx context.Context, oID, uID uuid.UUID, req service.BulkAssignOwnersRequest) (*service.BulkAssignOwnersResult, error) {
+			return nil, &service.ValidationError{Field: "user_ids", Message: "at least one owner is required"}
+		},
+	}
+
+	h := NewCustomerOwnerHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customer-owners/bulk", strings.NewReader(`{"user_ids":[]}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.BulkAssign(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestCustomerOwnerCreateRule_Success(t *testing.T) {
+	mock := &mockCustomerOwnerService{
+		createRuleFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error) {
+			if req.Strategy != repository.AssignmentStrategyRoundRobin || req.Conditions.Source != "stripe" {
+				t.Fatalf("unexpected rule %+v", req)
+			}
+			return &repository.CustomerAssignmentRule{ID: uuid.New(), Name: req.Name, Strategy: req.Strategy}, nil
+		},
+	}
+
+	h := NewCustomerOwnerHandler(mock)
+	body := `{"name":"Stripe signups","strategy":"round_robin","conditions":{"source":"stripe"},"user_ids":["` + uuid.New().String() + `"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/assignment-rules", strings.NewReader(body))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.CreateRule(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rr.Code)
+	}
+}
+
+func TestCustomerOwnerDeleteRule_NotFound(t *testing.T) {
+	mock := &mockCustomerOwnerService{
+		deleteRuleFn: func(ctx context.Context, id, oID uuid.UUID) error {
+			return &service.NotFoundError{Resource: "assignment_rule", Message: "assignment rule not found"}
+		},
+	}
+
+	h := NewCustomerOwnerHandler(mock)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/assignment-rules/x", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.DeleteRule(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// CustomerTagHandler provides customer tag HTTP endpoints.
+type CustomerTagHandler struct {
+	tagService customerTagServicer
+}
+
+// NewCustomerTagHandler creates a new CustomerTagHandler.
+func NewCustomerTagHandler(tagService customerTagServicer) *CustomerTagHandler {
+	return &CustomerTagHandler{tagService: tagService}
+}
+
+// ListOrgTags handles GET /api/v1/tags.
+func (h *CustomerTagHandler) ListOrgTags(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	tags, err := h.tagService.ListOrgTags(r.Context(), orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+// List handles GET /api/v1/customers/{id}/tags.
+func (h *CustomerTagHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	tags, err := h.tagService.ListForCustomer(r.Context(), orgID, customerID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+// Add handles POST /api/v1/customers/{id}/tags.
+func (h *CustomerTagHandler) Add(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, h.tagService.AddTags)
+}
+
+// Set handles PUT /api/v1/customers/{id}/tags.
+func (h *CustomerTagHandler) Set(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, h.tagService.SetTags)
+}
+
+// write decodes a tags request for a customer and applies it with fn.
+func (h *CustomerTagHandler) write(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.CustomerTagsRequest) ([]string, error)) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	var req service.CustomerTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	tags, err := fn(r.Context(), orgID, customerID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+// Remove handles DELETE /api/v1/customers/{id}/tags/{tag}.
+func (h *CustomerTagHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	tags, err := h.tagService.RemoveTag(r.Context(), orgID, customerID, chi.URLParam(r, "tag"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockCustomerTagService struct {
+	listOrgTagsFn     func(ctx context.Context, orgID uuid.UUID) ([]repository.TagCount, error)
+	listForCustomerFn func(ctx context.Context, orgID, customerID uuid.UUID) ([]string, error)
+	addTagsFn         func(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.CustomerTagsRequest) ([]string, error)
+	setTagsFn         func(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.CustomerTagsRequest) ([]string, error)
+	removeTagFn       func(ctx context.Context, orgID, customerID uuid.UUID, tag string) ([]string, error)
+}
+
+func (m *mockCustomerTagService) ListOrgTags(ctx context.Context, orgID uuid.UUID) ([]repository.TagCount, error) {
+	return m.listOrgTagsFn(ctx, orgID)
+}
+
+func (m *mockCustomerTagService) ListForCustomer(ctx context.Context, orgID, customerID uuid.UUID) ([]string, error) {
+	return m.listForCustomerFn(ctx, orgID, customerID)
+}
+
+func (m *mockCustomerTagService) AddTags(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.CustomerTagsRequest) ([]string, error) {
+	return m.addTagsFn(ctx, orgID, customerID, userID, req)
+}
+
+func (m *mockCustomerTagService) SetTags(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.CustomerTagsRequest) ([]string, error) {
+	return m.setTagsFn(ctx, orgID, customerID, userID, req)
+}
+
+func (m *mockCustomerTagService) RemoveTag(ctx context.Context, orgID, customerID uuid.UUID, tag string) ([]string, error) {
+	return m.removeTagFn(ctx, orgID, customerID, tag)
+}
+
+func TestCustomerTagListOrgTags_Unauthorized(t *testing.T) {
+	h := NewCustomerTagHandler(&mockCustomerTagService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+	rr := httptest.NewRecorder()
+
+	h.ListOrgTags(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestCustomerTagListOrgTags_Success(t *testing.T) {
+	mock := &mockCustomerTagService{
+		listOrgTagsFn: func(ctx context.Context, orgID uuid.UUID) ([]repository.TagCount, error) {
+			return []repository.TagCount{{Tag: "vip", Count: 3}}, nil
+		},
+	}
+
+	h := NewCustomerTagHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.ListOrgTags(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var resp struct {
+		Tags []repository.TagCount `json:"tags"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Tags) != 1 || resp.Tags[0].Tag != "vip" || resp.Tags[0].Count != 3 {
+		t.Errorf("unexpected tags %+v", resp.Tags)
+	}
+}
+
+func TestCustomerTagAdd_Success(t *testing.T) {
+	orgID, userID, customerID := uuid.New(), uuid.New(), uuid.New()
+	mock := &mockCustomerTagService{
+		addTagsFn: func(ctx context.Context, oID, cID, uID uuid.UUID, req service.CustomerTagsRequest) ([]string, error) {
+			if oID != orgID || cID != customerID || uID != userID {
+				t.Fatalf("unexpected org %s, customer %s or caller %s", oID, cID, uID)
+			}
+			if len(req.Tags) != 2 {
+				t.Fatalf("unexpected tags %v", req.Tags)
+			}
+			return []string{"beta", "vip"}, nil
+		},
+	}
+
+	h := NewCustomerTagHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/x/tags", strings.NewReader(`{"tags":["VIP","beta"]}`))
+	req = withOrgAndUser(req, orgID, userID)
+	req = withChiParam(req, "id", customerID.String())
+	rr := httptest.NewRecorder()
+
+	h.Add(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestCustomerTagSet_ValidationError(t *testing.T) {
+	mock := &mockCustomerTagService{
+		setTagsFn: func(ctx context.Context, oID, cID, uID uuid.UUID, req service.CustomerTagsRequest) ([]string, error) {
+			return nil, &service.ValidationError{Field: "tags", Message: "tags must not be empty"}
+		},
+	}
+
+	h := NewCustomerTagHandler(mock)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/x/tags", strings.NewReader(`{"tags":[" "]}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Set(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestCustomerTagSet_InvalidCustomerID(t *testing.T) {
+	h := NewCustomerTagHandler(&mockCustomerTagService{})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/x/tags", strings.NewReader(`{"tags":[]}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", "not-a-uuid")
+	rr := httptest.NewRecorder()
+
+	h.Set(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestCustomerTagRemove_Success(t *testing.T) {
+	customerID := uuid.New()
+	mock := &mockCustomerTagService{
+		removeTagFn: func(ctx context.Context, oID, cID uuid.UUID, tag string) ([]string, error) {
+			if cID != customerID || tag != "vip" {
+				t.Fatalf("unexpected customer %s or tag %q", cID, tag)
+			}
+			return []string{}, nil
+		},
+	}
+
+	h := NewCustomerTagHandler(mock)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/customers/x/tags/vip", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", customerID.String())
+	rctx.URLParams.Add("tag", "vip")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	h.Remove(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
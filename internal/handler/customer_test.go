@@ -2,10 +2,12 @@ package handler
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -20,6 +22,7 @@ type mockCustomerService struct {
 	listFn       func(ctx context.Context, params repository.CustomerListParams) (*service.CustomerListResponse, error)
 	getDetailFn  func(ctx context.Context, customerID, orgID uuid.UUID) (*service.CustomerDetail, error)
 	listEventsFn func(ctx context.Context, params repository.EventListParams) (*service.EventListResponse, error)
+	exportFn     func(ctx context.Context, params repository.CustomerListParams, fn func(repository.CustomerWithScore) error) error
 }
 
 func (m *mockCustomerService) List(ctx context.Context, params repository.CustomerListParams) (*service.CustomerListResponse, error) {
@@ -34,6 +37,10 @@ func (m *mockCustomerService) ListEvents(ctx context.Context, params repository.
 	return m.listEventsFn(ctx, params)
 }
 
+func (m *mockCustomerService) Export(ctx context.Context, params repository.CustomerListParams, fn func(repository.CustomerWithScore) error) error {
+	return m.exportFn(ctx, params, fn)
+}
+
 func withChiParam(r *http.Request, key, val string) *http.Request {
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add(key, val)
@@ -131,6 +138,205 @@ func TestCustomerList_QueryParams(t *testing.T) {
 	}
 }
 
+func TestCustomerList_OwnerFilter(t *testing.T) {
+	orgID, userID, otherID := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		owner       string
+		wantOwner   *uuid.UUID
+		wantUnowned bool
+		wantStatus  int
+	}{
+		{owner: "me", wantOwner: &userID, wantStatus: http.StatusOK},
+		{owner: otherID.String(), wantOwner: &otherID, wantStatus: http.StatusOK},
+		{owner: "none", wantUnowned: true, wantStatus: http.StatusOK},
+		{owner: "someone", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			var captured repository.CustomerListParams
+			mock := &mockCustomerService{
+				listFn: func(ctx context.Context, params repository.CustomerListParams) (*service.CustomerListResponse, error) {
+					captured = params
+					return &service.CustomerListResponse{}, nil
+				},
+			}
+
+			h := NewCustomerHandler(mock)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/customers?owner="+tt.owner, nil)
+			req = withOrgAndUser(req, orgID, userID)
+			rr := httptest.NewRecorder()
+
+			h.List(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if (captured.OwnerID == nil) != (tt.wantOwner == nil) ||
+				(captured.OwnerID != nil && *captured.OwnerID != *tt.wantOwner) {
+				t.Errorf("expected owner %v, got %v", tt.wantOwner, captured.OwnerID)
+			}
+			if captured.Unowned != tt.wantUnowned {
+				t.Errorf("expected unowned %v, got %v", tt.wantUnowned, captured.Unowned)
+			}
+		})
+	}
+}
+
+func TestCustomerList_TagAndSegmentFilters(t *testing.T) {
+	segmentID := uuid.New()
+	var captured repository.CustomerListParams
+	mock := &mockCustomerService{
+		listFn: func(ctx context.Context, params repository.CustomerListParams) (*service.CustomerListResponse, error) {
+			captured = params
+			return &service.CustomerListResponse{}, nil
+		},
+	}
+
+	h := NewCustomerHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers?tag=vip,%20beta&tag=emea&segment_id="+segmentID.String(), nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if strings.Join(captured.Tags, "|") != "vip|beta|emea" {
+		t.Errorf("expected tags vip, beta and emea, got %v", captured.Tags)
+	}
+	if captured.SegmentID == nil || *captured.SegmentID != segmentID {
+		t.Errorf("expected segment %s, got %v", segmentID, captured.SegmentID)
+	}
+}
+
+func TestCustomerList_CustomFieldFilters(t *testing.T) {
+	var captured repository.CustomerListParams
+	mock := &mockCustomerService{
+		listFn: func(ctx context.Context, params repository.CustomerListParams) (*service.CustomerListResponse, error) {
+			captured = params
+			return &service.CustomerListResponse{}, nil
+		},
+	}
+
+	h := NewCustomerHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers?field.tier=gold&field.seats.gte=50&field.industry.in=retail,%20media&sort=field.seats", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if len(captured.Fields) != 3 {
+		t.Fatalf("expected 3 field filters, got %+v", captured.Fields)
+	}
+	industry, seats, tier := captured.Fields[0], captured.Fields[1], captured.Fields[2]
+	if industry.Field != "field.industry" || industry.Op != "in" {
+		t.Errorf("unexpected industry filter %+v", industry)
+	}
+	if list, ok := industry.Value.([]any); !ok || len(list) != 2 || list[1] != "media" {
+		t.Errorf("expected industry values retail and media, got %v", industry.Value)
+	}
+	if seats.Field != "field.seats" || seats.Op != "gte" || seats.Value != "50" {
+		t.Errorf("unexpected seats filter %+v", seats)
+	}
+	if tier.Field != "field.tier" || tier.Op != "eq" || tier.Value != "gold" {
+		t.Errorf("unexpected tier filter %+v", tier)
+	}
+	if captured.Sort != "field.seats" {
+		t.Errorf("expected sort field.seats, got %q", captured.Sort)
+	}
+}
+
+func TestCustomerList_InvalidSegmentID(t *testing.T) {
+	h := NewCustomerHandler(&mockCustomerService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers?segment_id=bad", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestCustomerExport_Success(t *testing.T) {
+	score, risk := 42, "red"
+	customerID, ownerID := uuid.New(), uuid.New()
+	mock := &mockCustomerService{
+		exportFn: func(ctx context.Context, params repository.CustomerListParams, fn func(repository.CustomerWithScore) error) error {
+			if params.Risk != "red" {
+				t.Errorf("expected risk filter red, got %q", params.Risk)
+			}
+			return fn(repository.CustomerWithScore{
+				Customer:     repository.Customer{ID: customerID, Name: "Acme, Inc.", Email: "ops@acme.test", Source: "stripe", MRRCents: 5000},
+				OverallScore: &score,
+				RiskLevel:    &risk,
+				OwnerIDs:     []uuid.UUID{ownerID},
+				Tags:         []string{"beta", "vip"},
+			})
+		},
+	}
+
+	h := NewCustomerHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/export?risk=red", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.Export(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected text/csv, got %q", ct)
+	}
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected header and 1 row, got %d records", len(records))
+	}
+	row := records[1]
+	if row[0] != customerID.String() || row[1] != "Acme, Inc." || row[6] != "42" || row[7] != "red" {
+		t.Errorf("unexpected row %v", row)
+	}
+	if row[8] != "beta,vip" || row[9] != ownerID.String() {
+		t.Errorf("unexpected tags or owners in row %v", row)
+	}
+}
+
+func TestCustomerExport_ServiceError(t *testing.T) {
+	mock := &mockCustomerService{
+		exportFn: func(ctx context.Context, params repository.CustomerListParams, fn func(repository.CustomerWithScore) error) error {
+			return &service.NotFoundError{Resource: "segment", Message: "segment not found"}
+		},
+	}
+
+	h := NewCustomerHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/export?segment_id="+uuid.New().String(), nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.Export(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected a JSON error, got %q", ct)
+	}
+}
+
 func TestCustomerList_ServiceError(t *testing.T) {
 	orgID := uuid.New()
 	mock := &mockCustomerService{
@@ -3,7 +3,10 @@ package handler
 import (
 	"net/http"
 
+	"github.com/google/uuid"
+
 	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/service"
 )
 
 // DashboardHandler provides dashboard HTTP endpoints.
@@ -16,7 +19,8 @@ func NewDashboardHandler(ds dashboardServicer) *DashboardHandler {
 	return &DashboardHandler{dashboardService: ds}
 }
 
-// GetSummary handles GET /api/v1/dashboard/summary.
+// GetSummary handles GET /api/v1/dashboard/summary. The optional segment_id
+// query param limits the summary to a saved segment.
 func (h *DashboardHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 	orgID, ok := auth.GetOrgID(r.Context())
 	if !ok {
@@ -24,7 +28,20 @@ func (h *DashboardHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	summary, err := h.dashboardService.GetSummary(r.Context(), orgID)
+	var (
+		summary *service.DashboardSummary
+		err     error
+	)
+	if v := r.URL.Query().Get("segment_id"); v != "" {
+		segmentID, parseErr := uuid.Parse(v)
+		if parseErr != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid segment ID"))
+			return
+		}
+		summary, err = h.dashboardService.GetSegmentSummary(r.Context(), orgID, segmentID)
+	} else {
+		summary, err = h.dashboardService.GetSummary(r.Context(), orgID)
+	}
 	if err != nil {
 		handleServiceError(w, err)
 		return
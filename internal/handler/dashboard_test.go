@@ -16,6 +16,7 @@ import (
 
 type mockDashboardService struct {
 	getSummaryFn            func(ctx context.Context, orgID uuid.UUID) (*service.DashboardSummary, error)
+	getSegmentSummaryFn    func(ctx context.Context, orgID, segmentID uuid.UUID) (*service.DashboardSummary, error)
 	getScoreDistributionFn func(ctx context.Context, orgID uuid.UUID) (*service.ScoreDistributionResponse, error)
 }
 
@@ -23,6 +24,10 @@ func (m *mockDashboardService) GetSummary(ctx context.Context, orgID uuid.UUID)
 	return m.getSummaryFn(ctx, orgID)
 }
 
+func (m *mockDashboardService) GetSegmentSummary(ctx context.Context, orgID, segmentID uuid.UUID) (*service.DashboardSummary, error) {
+	return m.getSegmentSummaryFn(ctx, orgID, segmentID)
+}
+
 func (m *mockDashboardService) GetScoreDistribution(ctx context.Context, orgID uuid.UUID) (*service.ScoreDistributionResponse, error) {
 	return m.getScoreDistributionFn(ctx, orgID)
 }
@@ -74,6 +79,50 @@ func TestDashboardGetSummary_Success(t *testing.T) {
 	}
 }
 
+func TestDashboardGetSummary_Segment(t *testing.T) {
+	orgID, segmentID := uuid.New(), uuid.New()
+	mock := &mockDashboardService{
+		getSegmentSummaryFn: func(ctx context.Context, oID, sID uuid.UUID) (*service.DashboardSummary, error) {
+			if oID != orgID || sID != segmentID {
+				t.Errorf("unexpected org %s or segment %s", oID, sID)
+			}
+			return &service.DashboardSummary{TotalCustomers: 7}, nil
+		},
+	}
+
+	h := NewDashboardHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dashboard/summary?segment_id="+segmentID.String(), nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	rr := httptest.NewRecorder()
+
+	h.GetSummary(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var resp service.DashboardSummary
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TotalCustomers != 7 {
+		t.Errorf("expected 7 customers, got %d", resp.TotalCustomers)
+	}
+}
+
+func TestDashboardGetSummary_InvalidSegmentID(t *testing.T) {
+	h := NewDashboardHandler(&mockDashboardService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dashboard/summary?segment_id=nope", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.GetSummary(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
 func TestDashboardGetSummary_ServiceError(t *testing.T) {
 	orgID := uuid.New()
 	mock := &mockDashboardService{
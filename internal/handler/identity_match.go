@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+)
+
+// IdentityMatchHandler provides the identity match review HTTP endpoints.
+type IdentityMatchHandler struct {
+	resolver identityMatchServicer
+}
+
+// NewIdentityMatchHandler creates a new IdentityMatchHandler.
+func NewIdentityMatchHandler(resolver identityMatchServicer) *IdentityMatchHandler {
+	return &IdentityMatchHandler{resolver: resolver}
+}
+
+// List handles GET /api/v1/identity-matches.
+func (h *IdentityMatchHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "pending"
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	matches, total, err := h.resolver.ListMatches(r.Context(), orgID, status, limit, offset)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"matches": matches,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// Approve handles POST /api/v1/identity-matches/{id}/approve.
+func (h *IdentityMatchHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	matchID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid match ID"))
+		return
+	}
+
+	var req struct {
+		PrimaryID string `json:"primary_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+	var primaryID *uuid.UUID
+	if req.PrimaryID != "" {
+		id, err := uuid.Parse(req.PrimaryID)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid primary_id"))
+			return
+		}
+		primaryID = &id
+	}
+
+	match, err := h.resolver.ApproveMatch(r.Context(), orgID, matchID, primaryID, &userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, match)
+}
+
+// Reject handles POST /api/v1/identity-matches/{id}/reject.
+func (h *IdentityMatchHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	matchID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid match ID"))
+		return
+	}
+
+	match, err := h.resolver.RejectMatch(r.Context(), orgID, matchID, &userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, match)
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockIdentityMatchService struct {
+	listFn    func(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.IdentityMatch, int, error)
+	approveFn func(ctx context.Context, orgID, matchID uuid.UUID, primaryID, userID *uuid.UUID) (*repository.IdentityMatch, error)
+	rejectFn  func(ctx context.Context, orgID, matchID uuid.UUID, userID *uuid.UUID) (*repository.IdentityMatch, error)
+}
+
+func (m *mockIdentityMatchService) ListMatches(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.IdentityMatch, int, error) {
+	return m.listFn(ctx, orgID, status, limit, offset)
+}
+
+func (m *mockIdentityMatchService) ApproveMatch(ctx context.Context, orgID, matchID uuid.UUID, primaryID, userID *uuid.UUID) (*repository.IdentityMatch, error) {
+	return m.approveFn(ctx, orgID, matchID, primaryID, userID)
+}
+
+func (m *mockIdentityMatchService) RejectMatch(ctx context.Context, orgID, matchID uuid.UUID, userID *uuid.UUID) (*repository.IdentityMatch, error) {
+	return m.rejectFn(ctx, orgID, matchID, userID)
+}
+
+func TestIdentityMatchList_Unauthorized(t *testing.T) {
+	h := NewIdentityMatchHandler(&mockIdentityMatchService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/identity-matches", nil)
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestIdentityMatchList_DefaultsToPending(t *testing.T) {
+	mock := &mockIdentityMatchService{
+		listFn: func(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.IdentityMatch, int, error) {
+			if status != "pending" {
+				t.Fatalf("expected status pending, got %q", status)
+			}
+			if limit != 25 || offset != 0 {
+				t.Fatalf("expected default paging, got limit=%d offset=%d", limit, offset)
+			}
+			return []*repository.IdentityMatch{{ID: uuid.New(), Score: 80, Status: "pending"}}, 1, nil
+		},
+	}
+
+	h := NewIdentityMatchHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/identity-matches", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestIdentityMatchList_InvalidStatus(t *testing.T) {
+	mock := &mockIdentityMatchService{
+		listFn: func(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.IdentityMatch, int, error) {
+			return nil, 0, &service.ValidationError{Field: "status", Message: "status must be pending, approved or rejected"}
+		},
+	}
+
+	h := NewIdentityMatchHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/identity-matches?status=maybe", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), uuid.New()))
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestIdentityMatchApprove_WithPrimary(t *testing.T) {
+	matchID, primaryID := uuid.New(), uuid.New()
+	mock := &mockIdentityMatchService{
+		approveFn: func(ctx context.Context, orgID, mID uuid.UUID, pID, userID *uuid.UUID) (*repository.IdentityMatch, error) {
+			if mID != matchID {
+				t.Fatalf("expected match %s, got %s", matchID, mID)
+			}
+			if pID == nil || *pID != primaryID {
+				t.Fatal("expected the chosen primary to be passed through")
+			}
+			mergeID := uuid.New()
+			return &repository.IdentityMatch{ID: mID, Status: "approved", MergeID: &mergeID}, nil
+		},
+	}
+
+	h := NewIdentityMatchHandler(mock)
+	body := `{"primary_id":"` + primaryID.String() + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity-matches/x/approve", strings.NewReader(body))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", matchID.String())
+	rr := httptest.NewRecorder()
+
+	h.Approve(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestIdentityMatchApprove_EmptyBody(t *testing.T) {
+	mock := &mockIdentityMatchService{
+		approveFn: func(ctx context.Context, orgID, mID uuid.UUID, pID, userID *uuid.UUID) (*repository.IdentityMatch, error) {
+			if pID != nil {
+				t.Fatal("expected no primary without a body")
+			}
+			return &repository.IdentityMatch{ID: mID, Status: "approved"}, nil
+		},
+	}
+
+	h := NewIdentityMatchHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity-matches/x/approve", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Approve(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestIdentityMatchApprove_InvalidPrimary(t *testing.T) {
+	h := NewIdentityMatchHandler(&mockIdentityMatchService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity-matches/x/approve", strings.NewReader(`{"primary_id":"nope"}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Approve(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestIdentityMatchReject_AlreadyDecided(t *testing.T) {
+	mock := &mockIdentityMatchService{
+		rejectFn: func(ctx context.Context, orgID, mID uuid.UUID, userID *uuid.UUID) (*repository.IdentityMatch, error) {
+			return nil, &service.ConflictError{Resource: "identity_match", Message: "identity match was already decided"}
+		},
+	}
+
+	h := NewIdentityMatchHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity-matches/x/reject", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Reject(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestIdentityMatchReject_InvalidID(t *testing.T) {
+	h := NewIdentityMatchHandler(&mockIdentityMatchService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity-matches/x/reject", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", "not-a-uuid")
+	rr := httptest.NewRecorder()
+
+	h.Reject(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 
@@ -35,6 +40,63 @@ func (h *IntegrationHandler) List(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"integrations": summaries})
 }
 
+// Connect handles GET /api/v1/integrations/{provider}/connect.
+// Returns the OAuth URL to redirect the user to the provider.
+func (h *IntegrationHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	provider := chi.URLParam(r, "provider")
+	if provider == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse("provider is required"))
+		return
+	}
+
+	connectURL, err := h.integrationService.ConnectURL(orgID, provider)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"url": connectURL})
+}
+
+// Callback handles GET /api/v1/integrations/{provider}/callback.
+// Exchanges the code for tokens and initiates a full sync.
+func (h *IntegrationHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	provider := chi.URLParam(r, "provider")
+	if provider == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse("provider is required"))
+		return
+	}
+	displayName := h.integrationService.DisplayName(provider)
+
+	if errMsg := r.URL.Query().Get("error"); errMsg != "" {
+		errDesc := r.URL.Query().Get("error_description")
+		slog.Warn("oauth error", "provider", provider, "error", errMsg, "description", errDesc)
+		writeJSON(w, http.StatusBadRequest, errorResponse(displayName+" connection failed: "+errDesc))
+		return
+	}
+
+	code := r.URL.Query().Get("code")
+	state := r.URL.Query().Get("state")
+	if err := h.integrationService.CompleteOAuth(r.Context(), orgID, provider, code, state); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": displayName + " connected successfully. Initial sync started."})
+}
+
 // GetStatus handles GET /api/v1/integrations/{provider}/status.
 func (h *IntegrationHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	orgID, ok := auth.GetOrgID(r.Context())
@@ -80,6 +142,111 @@ func (h *IntegrationHandler) TriggerSync(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusAccepted, map[string]string{"status": "sync_started"})
 }
 
+// ListRuns handles GET /api/v1/integrations/{provider}/runs.
+func (h *IntegrationHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	provider := chi.URLParam(r, "provider")
+	if provider == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse("provider is required"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	runs, total, err := h.integrationService.ListRuns(r.Context(), orgID, provider, limit, offset)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"runs":   runs,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// TriggerReconcile handles POST /api/v1/integrations/{provider}/reconcile.
+func (h *IntegrationHandler) TriggerReconcile(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	provider := chi.URLParam(r, "provider")
+	if provider == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse("provider is required"))
+		return
+	}
+
+	// The body is optional; without it the run only reports drift
+	var req struct {
+		Heal bool `json:"heal"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	if err := h.integrationService.TriggerReconcile(r.Context(), orgID, provider, req.Heal); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "reconciliation_started"})
+}
+
+// ListReconciliations handles GET /api/v1/integrations/{provider}/reconciliations.
+func (h *IntegrationHandler) ListReconciliations(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	provider := chi.URLParam(r, "provider")
+	if provider == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse("provider is required"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	reports, total, err := h.integrationService.ListReconciliations(r.Context(), orgID, provider, limit, offset)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"reports": reports,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
 // Disconnect handles DELETE /api/v1/integrations/{provider}.
 func (h *IntegrationHandler) Disconnect(w http.ResponseWriter, r *http.Request) {
 	orgID, ok := auth.GetOrgID(r.Context())
@@ -5,26 +5,45 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
 
 	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
 	"github.com/onnwee/pulse-score/internal/service"
 )
 
 type mockIntegrationService struct {
-	listFn       func(ctx context.Context, orgID uuid.UUID) ([]service.IntegrationSummary, error)
-	getStatusFn  func(ctx context.Context, orgID uuid.UUID, provider string) (*service.IntegrationStatus, error)
+	listFn          func(ctx context.Context, orgID uuid.UUID) ([]service.IntegrationSummary, error)
+	connectURLFn    func(orgID uuid.UUID, provider string) (string, error)
+	completeOAuthFn func(ctx context.Context, orgID uuid.UUID, provider, code, state string) error
+	getStatusFn  func(ctx context.Context, orgID uuid.UUID, provider string) (any, error)
 	triggerSyncFn func(ctx context.Context, orgID uuid.UUID, provider string) error
+	listRunsFn   func(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.SyncRun, int, error)
 	disconnectFn func(ctx context.Context, orgID uuid.UUID, provider string) error
+	triggerReconcileFn    func(ctx context.Context, orgID uuid.UUID, provider string, heal bool) error
+	listReconciliationsFn func(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.ReconciliationReport, int, error)
 }
 
 func (m *mockIntegrationService) List(ctx context.Context, orgID uuid.UUID) ([]service.IntegrationSummary, error) {
 	return m.listFn(ctx, orgID)
 }
 
-func (m *mockIntegrationService) GetStatus(ctx context.Context, orgID uuid.UUID, provider string) (*service.IntegrationStatus, error) {
+func (m *mockIntegrationService) DisplayName(provider string) string {
+	return provider
+}
+
+func (m *mockIntegrationService) ConnectURL(orgID uuid.UUID, provider string) (string, error) {
+	return m.connectURLFn(orgID, provider)
+}
+
+func (m *mockIntegrationService) CompleteOAuth(ctx context.Context, orgID uuid.UUID, provider, code, state string) error {
+	return m.completeOAuthFn(ctx, orgID, provider, code, state)
+}
+
+func (m *mockIntegrationService) GetStatus(ctx context.Context, orgID uuid.UUID, provider string) (any, error) {
 	return m.getStatusFn(ctx, orgID, provider)
 }
 
@@ -32,6 +51,18 @@ func (m *mockIntegrationService) TriggerSync(ctx context.Context, orgID uuid.UUI
 	return m.triggerSyncFn(ctx, orgID, provider)
 }
 
+func (m *mockIntegrationService) ListRuns(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.SyncRun, int, error) {
+	return m.listRunsFn(ctx, orgID, provider, limit, offset)
+}
+
+func (m *mockIntegrationService) TriggerReconcile(ctx context.Context, orgID uuid.UUID, provider string, heal bool) error {
+	return m.triggerReconcileFn(ctx, orgID, provider, heal)
+}
+
+func (m *mockIntegrationService) ListReconciliations(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.ReconciliationReport, int, error) {
+	return m.listReconciliationsFn(ctx, orgID, provider, limit, offset)
+}
+
 func (m *mockIntegrationService) Disconnect(ctx context.Context, orgID uuid.UUID, provider string) error {
 	return m.disconnectFn(ctx, orgID, provider)
 }
@@ -90,6 +121,137 @@ func TestIntegrationList_ServiceError(t *testing.T) {
 	}
 }
 
+func TestIntegrationConnect_Unauthorized(t *testing.T) {
+	h := NewIntegrationHandler(&mockIntegrationService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/intercom/connect", nil)
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.Connect(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationConnect_NotConfigured(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		connectURLFn: func(oID uuid.UUID, provider string) (string, error) {
+			return "", &service.ValidationError{Field: "intercom", Message: "Intercom integration is not configured"}
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/intercom/connect", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.Connect(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationConnect_UnknownProvider(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		connectURLFn: func(oID uuid.UUID, provider string) (string, error) {
+			return "", &service.NotFoundError{Resource: "provider", Message: "unknown integration provider"}
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/zendesk/connect", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "zendesk")
+	rr := httptest.NewRecorder()
+
+	h.Connect(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationConnect_Success(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		connectURLFn: func(oID uuid.UUID, provider string) (string, error) {
+			if provider != "intercom" {
+				t.Errorf("expected provider intercom, got %s", provider)
+			}
+			return "https://app.intercom.com/oauth?client_id=test", nil
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/intercom/connect", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.Connect(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationCallback_Unauthorized(t *testing.T) {
+	h := NewIntegrationHandler(&mockIntegrationService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/intercom/callback?code=test", nil)
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.Callback(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationCallback_OAuthError(t *testing.T) {
+	orgID := uuid.New()
+	h := NewIntegrationHandler(&mockIntegrationService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/intercom/callback?error=access_denied&error_description=User+denied+access", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.Callback(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationCallback_Success(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		completeOAuthFn: func(ctx context.Context, oID uuid.UUID, provider, code, state string) error {
+			if provider != "hubspot" || code != "abc" || state != "xyz" {
+				t.Errorf("unexpected callback provider=%q code=%q state=%q", provider, code, state)
+			}
+			return nil
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/hubspot/callback?code=abc&state=xyz", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "hubspot")
+	rr := httptest.NewRecorder()
+
+	h.Callback(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
 func TestIntegrationGetStatus_Unauthorized(t *testing.T) {
 	h := NewIntegrationHandler(&mockIntegrationService{})
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/stripe/status", nil)
@@ -121,7 +283,7 @@ func TestIntegrationGetStatus_EmptyProvider(t *testing.T) {
 func TestIntegrationGetStatus_NotFound(t *testing.T) {
 	orgID := uuid.New()
 	mock := &mockIntegrationService{
-		getStatusFn: func(ctx context.Context, oID uuid.UUID, provider string) (*service.IntegrationStatus, error) {
+		getStatusFn: func(ctx context.Context, oID uuid.UUID, provider string) (any, error) {
 			return nil, &service.NotFoundError{Resource: "integration", Message: "no hubspot integration found"}
 		},
 	}
@@ -142,13 +304,11 @@ func TestIntegrationGetStatus_NotFound(t *testing.T) {
 func TestIntegrationGetStatus_Success(t *testing.T) {
 	orgID := uuid.New()
 	mock := &mockIntegrationService{
-		getStatusFn: func(ctx context.Context, oID uuid.UUID, provider string) (*service.IntegrationStatus, error) {
+		getStatusFn: func(ctx context.Context, oID uuid.UUID, provider string) (any, error) {
 			if provider != "stripe" {
 				t.Errorf("expected provider stripe, got %s", provider)
 			}
-			return &service.IntegrationStatus{
-				IntegrationSummary: service.IntegrationSummary{Provider: "stripe", Status: "active"},
-			}, nil
+			return &service.StripeConnectionStatus{Status: "active", ExternalAccountID: "acct_123"}, nil
 		},
 	}
 
@@ -214,6 +374,161 @@ func TestIntegrationTriggerSync_Success(t *testing.T) {
 	}
 }
 
+func TestIntegrationListRuns_Unauthorized(t *testing.T) {
+	h := NewIntegrationHandler(&mockIntegrationService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/stripe/runs", nil)
+	req = withChiParam(req, "provider", "stripe")
+	rr := httptest.NewRecorder()
+
+	h.ListRuns(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationListRuns_Success(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		listRunsFn: func(ctx context.Context, oID uuid.UUID, provider string, limit, offset int) ([]*repository.SyncRun, int, error) {
+			if provider != "hubspot" || limit != 10 || offset != 20 {
+				t.Fatalf("unexpected query provider=%q limit=%d offset=%d", provider, limit, offset)
+			}
+			return []*repository.SyncRun{{
+				ID:       uuid.New(),
+				Provider: "hubspot",
+				Status:   "running",
+				Steps:    []repository.SyncRunStep{{Step: "hubspot_contacts", Current: 1240, Expected: 3000}},
+			}}, 21, nil
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/hubspot/runs?limit=10&offset=20", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "hubspot")
+	rr := httptest.NewRecorder()
+
+	h.ListRuns(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationTriggerReconcile_Unauthorized(t *testing.T) {
+	h := NewIntegrationHandler(&mockIntegrationService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/integrations/stripe/reconcile", nil)
+	req = withChiParam(req, "provider", "stripe")
+	rr := httptest.NewRecorder()
+
+	h.TriggerReconcile(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationTriggerReconcile_ReportOnly(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		triggerReconcileFn: func(ctx context.Context, oID uuid.UUID, provider string, heal bool) error {
+			if heal {
+				t.Fatal("expected heal to default to false")
+			}
+			return nil
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/integrations/stripe/reconcile", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "stripe")
+	rr := httptest.NewRecorder()
+
+	h.TriggerReconcile(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationTriggerReconcile_Heal(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		triggerReconcileFn: func(ctx context.Context, oID uuid.UUID, provider string, heal bool) error {
+			if !heal {
+				t.Fatal("expected heal to be true")
+			}
+			return nil
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/integrations/stripe/reconcile", strings.NewReader(`{"heal":true}`))
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "stripe")
+	rr := httptest.NewRecorder()
+
+	h.TriggerReconcile(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationTriggerReconcile_AlreadyRunning(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		triggerReconcileFn: func(ctx context.Context, oID uuid.UUID, provider string, heal bool) error {
+			return &service.ConflictError{Resource: "reconciliation", Message: "a reconciliation is already running for this integration"}
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/integrations/stripe/reconcile", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "stripe")
+	rr := httptest.NewRecorder()
+
+	h.TriggerReconcile(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestIntegrationListReconciliations_Success(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockIntegrationService{
+		listReconciliationsFn: func(ctx context.Context, oID uuid.UUID, provider string, limit, offset int) ([]*repository.ReconciliationReport, int, error) {
+			if provider != "intercom" || limit != 25 || offset != 0 {
+				t.Fatalf("unexpected query provider=%q limit=%d offset=%d", provider, limit, offset)
+			}
+			return []*repository.ReconciliationReport{{
+				ID:       uuid.New(),
+				Provider: "intercom",
+				Status:   "succeeded",
+				Checked:  120,
+				Orphaned: 1,
+				Items:    []repository.ReconciliationItem{{Resource: "contact", ExternalID: "c_1", Kind: "orphaned"}},
+			}}, 1, nil
+		},
+	}
+
+	h := NewIntegrationHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/integrations/intercom/reconciliations", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.ListReconciliations(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
 func TestIntegrationDisconnect_Unauthorized(t *testing.T) {
 	h := NewIntegrationHandler(&mockIntegrationService{})
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/integrations/stripe", nil)
@@ -14,19 +14,133 @@ type customerServicer interface {
 	List(ctx context.Context, params repository.CustomerListParams) (*service.CustomerListResponse, error)
 	GetDetail(ctx context.Context, customerID, orgID uuid.UUID) (*service.CustomerDetail, error)
 	ListEvents(ctx context.Context, params repository.EventListParams) (*service.EventListResponse, error)
+	Export(ctx context.Context, params repository.CustomerListParams, fn func(repository.CustomerWithScore) error) error
+}
+
+// accountServicer defines the methods the AccountHandler needs.
+type accountServicer interface {
+	List(ctx context.Context, params repository.AccountListParams) (*service.AccountListResponse, error)
+	GetDetail(ctx context.Context, accountID, orgID uuid.UUID) (*service.AccountDetail, error)
+}
+
+// customerMergeServicer defines the methods the CustomerMergeHandler needs.
+type customerMergeServicer interface {
+	Merge(ctx context.Context, orgID, primaryID, duplicateID uuid.UUID, userID *uuid.UUID) (*repository.CustomerMerge, error)
+	ListMerges(ctx context.Context, orgID, customerID uuid.UUID) ([]*repository.CustomerMerge, error)
+	Unmerge(ctx context.Context, orgID, mergeID uuid.UUID, userID *uuid.UUID) (*repository.CustomerMerge, error)
+}
+
+// customerNoteServicer defines the methods the CustomerNoteHandler needs.
+type customerNoteServicer interface {
+	List(ctx context.Context, params repository.NoteListParams) (*service.NoteListResponse, error)
+	Create(ctx context.Context, orgID, customerID, authorID uuid.UUID, req service.CreateNoteRequest) (*repository.CustomerNote, error)
+	Update(ctx context.Context, orgID, customerID, noteID, userID uuid.UUID, req service.UpdateNoteRequest) (*repository.CustomerNote, error)
+	Delete(ctx context.Context, orgID, customerID, noteID, userID uuid.UUID) error
+	ListRevisions(ctx context.Context, orgID, customerID, noteID uuid.UUID) ([]*repository.CustomerNoteRevision, error)
+}
+
+// taskServicer defines the methods the TaskHandler needs.
+type taskServicer interface {
+	List(ctx context.Context, params repository.TaskListParams) (*service.TaskListResponse, error)
+	Get(ctx context.Context, id, orgID uuid.UUID) (*repository.Task, error)
+	Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateTaskRequest) (*repository.Task, error)
+	Update(ctx context.Context, id, orgID, userID uuid.UUID, req service.UpdateTaskRequest) (*repository.Task, error)
+	Delete(ctx context.Context, id, orgID, userID uuid.UUID) error
+}
+
+// playbookServicer defines the methods the PlaybookHandler needs.
+type playbookServicer interface {
+	List(ctx context.Context, orgID uuid.UUID) ([]*repository.Playbook, error)
+	Get(ctx context.Context, id, orgID uuid.UUID) (*repository.Playbook, error)
+	Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreatePlaybookRequest) (*repository.Playbook, error)
+	Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdatePlaybookRequest) (*repository.Playbook, error)
+	Delete(ctx context.Context, id, orgID uuid.UUID) error
+	GetWebhookSecret(ctx context.Context, id, orgID uuid.UUID) (string, error)
+	RotateWebhookSecret(ctx context.Context, id, orgID uuid.UUID) (string, error)
+	ListRuns(ctx context.Context, params repository.PlaybookRunListParams) (*service.PlaybookRunListResponse, error)
+	GetRun(ctx context.Context, id, orgID uuid.UUID) (*service.PlaybookRunDetail, error)
+	StartRun(ctx context.Context, playbookID, orgID, userID uuid.UUID, req service.StartPlaybookRunRequest) (*service.PlaybookRunDetail, error)
+	CancelRun(ctx context.Context, id, orgID uuid.UUID) (*service.PlaybookRunDetail, error)
+}
+
+// customerOwnerServicer defines the methods the CustomerOwnerHandler needs.
+type customerOwnerServicer interface {
+	ListOwners(ctx context.Context, orgID, customerID uuid.UUID) ([]*repository.CustomerOwner, error)
+	SetOwners(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetOwnersRequest) ([]*repository.CustomerOwner, error)
+	BulkAssign(ctx context.Context, orgID, userID uuid.UUID, req service.BulkAssignOwnersRequest) (*service.BulkAssignOwnersResult, error)
+	AssignUnowned(ctx context.Context, orgID uuid.UUID) (*service.AutoAssignResult, error)
+	BookOfBusiness(ctx context.Context, orgID uuid.UUID) (*service.BookOfBusiness, error)
+	ListRules(ctx context.Context, orgID uuid.UUID) ([]*repository.CustomerAssignmentRule, error)
+	CreateRule(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error)
+	UpdateRule(ctx context.Context, id, orgID uuid.UUID, req service.UpdateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error)
+	DeleteRule(ctx context.Context, id, orgID uuid.UUID) error
+}
+
+// customerTagServicer defines the methods the CustomerTagHandler needs.
+type customerTagServicer interface {
+	ListOrgTags(ctx context.Context, orgID uuid.UUID) ([]repository.TagCount, error)
+	ListForCustomer(ctx context.Context, orgID, customerID uuid.UUID) ([]string, error)
+	AddTags(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.CustomerTagsRequest) ([]string, error)
+	SetTags(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.CustomerTagsRequest) ([]string, error)
+	RemoveTag(ctx context.Context, orgID, customerID uuid.UUID, tag string) ([]string, error)
+}
+
+// segmentServicer defines the methods the SegmentHandler needs.
+type segmentServicer interface {
+	List(ctx context.Context, orgID uuid.UUID) ([]*service.SegmentResponse, error)
+	Get(ctx context.Context, id, orgID uuid.UUID) (*service.SegmentResponse, error)
+	Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateSegmentRequest) (*service.SegmentResponse, error)
+	Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdateSegmentRequest) (*service.SegmentResponse, error)
+	Delete(ctx context.Context, id, orgID uuid.UUID) error
+	Preview(ctx context.Context, orgID uuid.UUID, req service.PreviewSegmentRequest) (*repository.SegmentStats, error)
+	History(ctx context.Context, id, orgID uuid.UUID, days int) (*service.SegmentHistory, error)
+}
+
+// customFieldServicer defines the methods the CustomFieldHandler needs.
+type customFieldServicer interface {
+	List(ctx context.Context, orgID uuid.UUID) ([]*repository.CustomField, error)
+	Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateCustomFieldRequest) (*repository.CustomField, error)
+	Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdateCustomFieldRequest) (*repository.CustomField, error)
+	Delete(ctx context.Context, id, orgID uuid.UUID) error
+	ListValues(ctx context.Context, orgID, customerID uuid.UUID) ([]service.CustomerFieldValue, error)
+	SetValues(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetCustomerFieldValuesRequest) ([]service.CustomerFieldValue, error)
+}
+
+// renewalServicer defines the methods the RenewalHandler needs.
+type renewalServicer interface {
+	Pipeline(ctx context.Context, orgID uuid.UUID, params service.RenewalPipelineParams) (*service.RenewalPipeline, error)
+	GetForCustomer(ctx context.Context, orgID, customerID uuid.UUID) (*service.CustomerRenewal, error)
+	SetOverride(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetRenewalOverrideRequest) (*service.CustomerRenewal, error)
+	ClearOverride(ctx context.Context, orgID, customerID uuid.UUID) (*service.CustomerRenewal, error)
+	GetSettings(ctx context.Context, orgID uuid.UUID) (*repository.RenewalSettings, error)
+	UpdateSettings(ctx context.Context, orgID, userID uuid.UUID, req service.UpdateRenewalSettingsRequest) (*repository.RenewalSettings, error)
+}
+
+// identityMatchServicer defines the methods the IdentityMatchHandler needs.
+type identityMatchServicer interface {
+	ListMatches(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.IdentityMatch, int, error)
+	ApproveMatch(ctx context.Context, orgID, matchID uuid.UUID, primaryID, userID *uuid.UUID) (*repository.IdentityMatch, error)
+	RejectMatch(ctx context.Context, orgID, matchID uuid.UUID, userID *uuid.UUID) (*repository.IdentityMatch, error)
 }
 
 // dashboardServicer defines the methods the DashboardHandler needs.
 type dashboardServicer interface {
 	GetSummary(ctx context.Context, orgID uuid.UUID) (*service.DashboardSummary, error)
+	GetSegmentSummary(ctx context.Context, orgID, segmentID uuid.UUID) (*service.DashboardSummary, error)
 	GetScoreDistribution(ctx context.Context, orgID uuid.UUID) (*service.ScoreDistributionResponse, error)
 }
 
 // integrationServicer defines the methods the IntegrationHandler needs.
 type integrationServicer interface {
 	List(ctx context.Context, orgID uuid.UUID) ([]service.IntegrationSummary, error)
-	GetStatus(ctx context.Context, orgID uuid.UUID, provider string) (*service.IntegrationStatus, error)
+	DisplayName(provider string) string
+	ConnectURL(orgID uuid.UUID, provider string) (string, error)
+	CompleteOAuth(ctx context.Context, orgID uuid.UUID, provider, code, state string) error
+	GetStatus(ctx context.Context, orgID uuid.UUID, provider string) (any, error)
 	TriggerSync(ctx context.Context, orgID uuid.UUID, provider string) error
+	ListRuns(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.SyncRun, int, error)
+	TriggerReconcile(ctx context.Context, orgID uuid.UUID, provider string, heal bool) error
+	ListReconciliations(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.ReconciliationReport, int, error)
 	Disconnect(ctx context.Context, orgID uuid.UUID, provider string) error
 }
 
@@ -44,6 +158,7 @@ type alertRuleServicer interface {
 	Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAlertRuleRequest) (*repository.AlertRule, error)
 	Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdateAlertRuleRequest) (*repository.AlertRule, error)
 	Delete(ctx context.Context, id, orgID uuid.UUID) error
+	Backtest(ctx context.Context, orgID uuid.UUID, req service.BacktestAlertRuleRequest) (*service.BacktestResult, error)
 }
 
 // organizationServicer defines the methods the OrganizationHandler needs.
@@ -52,3 +167,27 @@ type organizationServicer interface {
 	UpdateCurrent(ctx context.Context, orgID uuid.UUID, req service.UpdateOrgRequest) (*service.OrgDetailResponse, error)
 	Create(ctx context.Context, userID uuid.UUID, req service.CreateOrgRequest) (*service.OrgResponse, error)
 }
+
+// realtimeStreamer defines the methods the RealtimeHandler needs.
+type realtimeStreamer interface {
+	Subscribe(orgID, userID uuid.UUID) *service.RealtimeSubscription
+	Unsubscribe(sub *service.RealtimeSubscription)
+	Replay(ctx context.Context, orgID, userID uuid.UUID, lastEventID int64) ([]*repository.RealtimeEvent, error)
+}
+
+// alertTemplateServicer defines the methods the AlertTemplateHandler needs.
+type alertTemplateServicer interface {
+	List(ctx context.Context, orgID uuid.UUID) ([]*repository.AlertTemplate, error)
+	GetByID(ctx context.Context, id, orgID uuid.UUID) (*repository.AlertTemplate, error)
+	Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateAlertTemplateRequest) (*repository.AlertTemplate, error)
+	Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdateAlertTemplateRequest) (*repository.AlertTemplate, error)
+	Delete(ctx context.Context, id, orgID uuid.UUID) error
+	Variables() []service.AlertTemplateVariable
+	Preview(ctx context.Context, orgID uuid.UUID, req service.PreviewAlertTemplateRequest) (*service.RenderedAlertEmail, error)
+}
+
+// webhookInboxServicer defines the methods the WebhookInboxHandler needs.
+type webhookInboxServicer interface {
+	ListFailed(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.WebhookInboxEvent, int, error)
+	Replay(ctx context.Context, id, orgID uuid.UUID) (*repository.WebhookInboxEvent, error)
+}
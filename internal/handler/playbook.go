@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// PlaybookHandler provides playbook and playbook run HTTP endpoints.
+type PlaybookHandler struct {
+	playbookService playbookServicer
+}
+
+// NewPlaybookHandler creates a new PlaybookHandler.
+func NewPlaybookHandler(playbookService playbookServicer) *PlaybookHandler {
+	return &PlaybookHandler{playbookService: playbookService}
+}
+
+// List handles GET /api/v1/playbooks.
+func (h *PlaybookHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	playbooks, err := h.playbookService.List(r.Context(), orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"playbooks": playbooks})
+}
+
+// Get handles GET /api/v1/playbooks/{id}.
+func (h *PlaybookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid playbook ID"))
+		return
+	}
+
+	playbook, err := h.playbookService.Get(r.Context(), id, orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, playbook)
+}
+
+// Create handles POST /api/v1/playbooks.
+func (h *PlaybookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	var req service.CreatePlaybookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	playbook, err := h.playbookService.Create(r.Context(), orgID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, playbook)
+}
+
+// Update handles PATCH /api/v1/playbooks/{id}.
+func (h *PlaybookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid playbook ID"))
+		return
+	}
+
+	var req service.UpdatePlaybookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	playbook, err := h.playbookService.Update(r.Context(), id, orgID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, playbook)
+}
+
+// Delete handles DELETE /api/v1/playbooks/{id}.
+func (h *PlaybookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid playbook ID"))
+		return
+	}
+
+	if err := h.playbookService.Delete(r.Context(), id, orgID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+// GetWebhookSecret handles GET /api/v1/playbooks/{id}/webhook-secret.
+func (h *PlaybookHandler) GetWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	h.webhookSecret(w, r, h.playbookService.GetWebhookSecret)
+}
+
+// RotateWebhookSecret handles POST /api/v1/playbooks/{id}/webhook-secret/rotate.
+func (h *PlaybookHandler) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	h.webhookSecret(w, r, h.playbookService.RotateWebhookSecret)
+}
+
+func (h *PlaybookHandler) webhookSecret(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, id, orgID uuid.UUID) (string, error)) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid playbook ID"))
+		return
+	}
+
+	secret, err := fn(r.Context(), id, orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"webhook_secret": secret})
+}
+
+// ListRuns handles GET /api/v1/playbooks/{id}/runs.
+func (h *PlaybookHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid playbook ID"))
+		return
+	}
+	h.listRuns(w, r, &id, nil)
+}
+
+// ListCustomerRuns handles GET /api/v1/customers/{id}/playbook-runs.
+func (h *PlaybookHandler) ListCustomerRuns(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+	h.listRuns(w, r, nil, &id)
+}
+
+func (h *PlaybookHandler) listRuns(w http.ResponseWriter, r *http.Request, playbookID, customerID *uuid.UUID) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+
+	resp, err := h.playbookService.ListRuns(r.Context(), repository.PlaybookRunListParams{
+		OrgID:      orgID,
+		PlaybookID: playbookID,
+		CustomerID: customerID,
+		Status:     q.Get("status"),
+		Page:       page,
+		PerPage:    perPage,
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// StartRun handles POST /api/v1/playbooks/{id}/runs.
+func (h *PlaybookHandler) StartRun(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid playbook ID"))
+		return
+	}
+
+	var req service.StartPlaybookRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	run, err := h.playbookService.StartRun(r.Context(), id, orgID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, run)
+}
+
+// GetRun handles GET /api/v1/playbook-runs/{id}.
+func (h *PlaybookHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid playbook run ID"))
+		return
+	}
+
+	run, err := h.playbookService.GetRun(r.Context(), id, orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, run)
+}
+
+// CancelRun handles POST /api/v1/playbook-runs/{id}/cancel.
+func (h *PlaybookHandler) CancelRun(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid playbook run ID"))
+		return
+	}
+
+	run, err := h.playbookService.CancelRun(r.Context(), id, orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, run)
+}
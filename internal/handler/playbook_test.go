@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockPlaybookService struct {
+	listFn      func(ctx context.Context, orgID uuid.UUID) ([]*repository.Playbook, error)
+	getFn       func(ctx context.Context, id, orgID uuid.UUID) (*repository.Playbook, error)
+	createFn    func(ctx context.Context, orgID, userID uuid.UUID, req service.CreatePlaybookRequest) (*repository.Playbook, error)
+	updateFn    func(ctx context.Context, id, orgID uuid.UUID, req service.UpdatePlaybookRequest) (*repository.Playbook, error)
+	deleteFn    func(ctx context.Context, id, orgID uuid.UUID) error
+	secretFn    func(ctx context.Context, id, orgID uuid.UUID) (string, error)
+	rotateFn    func(ctx context.Context, id, orgID uuid.UUID) (string, error)
+	listRunsFn  func(ctx context.Context, params repository.PlaybookRunListParams) (*service.PlaybookRunListResponse, error)
+	getRunFn    func(ctx context.Context, id, orgID uuid.UUID) (*service.PlaybookRunDetail, error)
+	startRunFn  func(ctx context.Context, playbookID, orgID, userID uuid.UUID, req service.StartPlaybookRunRequest) (*service.PlaybookRunDetail, error)
+	cancelRunFn func(ctx context.Context, id, orgID uuid.UUID) (*service.PlaybookRunDetail, error)
+}
+
+func (m *mockPlaybookService) List(ctx context.Context, orgID uuid.UUID) ([]*repository.Playbook, error) {
+	return m.listFn(ctx, orgID)
+}
+
+func (m *mockPlaybookService) Get(ctx context.Context, id, orgID uuid.UUID) (*repository.Playbook, error) {
+	return m.getFn(ctx, id, orgID)
+}
+
+func (m *mockPlaybookService) Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreatePlaybookRequest) (*repository.Playbook, error) {
+	return m.createFn(ctx, orgID, userID, req)
+}
+
+func (m *mockPlaybookService) Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdatePlaybookRequest) (*repository.Playbook, error) {
+	return m.updateFn(ctx, id, orgID, req)
+}
+
+func (m *mockPlaybookService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	return m.deleteFn(ctx, id, orgID)
+}
+
+func (m *mockPlaybookService) GetWebhookSecret(ctx context.Context, id, orgID uuid.UUID) (string, error) {
+	return m.secretFn(ctx, id, orgID)
+}
+
+func (m *mockPlaybookService) RotateWebhookSecret(ctx context.Context, id, orgID uuid.UUID) (string, error) {
+	return m.rotateFn(ctx, id, orgID)
+}
+
+func (m *mockPlaybookService) ListRuns(ctx context.Context, params repository.PlaybookRunListParams) (*service.PlaybookRunListResponse, error) {
+	return m.listRunsFn(ctx, params)
+}
+
+func (m *mockPlaybookService) GetRun(ctx context.Context, id, orgID uuid.UUID) (*service.PlaybookRunDetail, error) {
+	return m.getRunFn(ctx, id, orgID)
+}
+
+func (m *mockPlaybookService) StartRun(ctx context.Context, playbookID, orgID, userID uuid.UUID, req service.StartPlaybookRunRequest) (*service.PlaybookRunDetail, error) {
+	return m.startRunFn(ctx, playbookID, orgID, userID, req)
+}
+
+func (m *mockPlaybookService) CancelRun(ctx context.Context, id, orgID uuid.UUID) (*service.PlaybookRunDetail, error) {
+	return m.cancelRunFn(ctx, id, orgID)
+}
+
+func TestPlaybookList_Unauthorized(t *testing.T) {
+	h := NewPlaybookHandler(&mockPlaybookService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/playbooks", nil)
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestPlaybookCreate_Success(t *testing.T) {
+	orgID, userID := uuid.New(), uuid.New()
+	mock := &mockPlaybookService{
+		createFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreatePlaybookRequest) (*repository.Playbook, error) {
+			if oID != orgID || uID != userID {
+				t.Fatalf("unexpected org %s or caller %s", oID, uID)
+			}
+			if req.Trigger.Type != repository.PlaybookTriggerRiskChange || req.Trigger.To != "red" {
+				t.Fatalf("unexpected trigger %+v", req.Trigger)
+			}
+			if len(req.Steps) != 2 || req.Steps[1].Days != 3 {
+				t.Fatalf("unexpected steps %+v", req.Steps)
+			}
+			return &repository.Playbook{ID: uuid.New(), Name: req.Name, Trigger: req.Trigger, Steps: req.Steps, IsActive: true}, nil
+		},
+	}
+
+	h := NewPlaybookHandler(mock)
+	body := `{"name":"Save at-risk","trigger":{"type":"risk_change","to":"red"},"steps":[{"type":"create_task","title":"Call"},{"type":"wait","days":3}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/playbooks", strings.NewReader(body))
+	req = withOrgAndUser(req, orgID, userID)
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestPlaybookCreate_ValidationError(t *testing.T) {
+	mock := &mockPlaybookService{
+		createFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreatePlaybookRequest) (*repository.Playbook, error) {
+			return nil, &service.ValidationError{Field: "steps", Message: "at least one step is required"}
+		},
+	}
+
+	h := NewPlaybookHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/playbooks", strings.NewReader(`{"name":"Empty"}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestPlaybookUpdate_InvalidID(t *testing.T) {
+	h := NewPlaybookHandler(&mockPlaybookService{})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/playbooks/x", strings.NewReader(`{}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", "not-a-uuid")
+	rr := httptest.NewRecorder()
+
+	h.Update(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestPlaybookListCustomerRuns(t *testing.T) {
+	customerID := uuid.New()
+	mock := &mockPlaybookService{
+		listRunsFn: func(ctx context.Context, params repository.PlaybookRunListParams) (*service.PlaybookRunListResponse, error) {
+			if params.CustomerID == nil || *params.CustomerID != customerID || params.PlaybookID != nil {
+				t.Fatalf("unexpected params %+v", params)
+			}
+			if params.Status != repository.PlaybookRunWaiting {
+				t.Fatalf("unexpected status %q", params.Status)
+			}
+			return &service.PlaybookRunListResponse{
+				Runs:       []*repository.PlaybookRun{{ID: uuid.New(), CustomerID: customerID, Status: params.Status}},
+				Pagination: service.PaginationMeta{Page: 1, PerPage: 25, Total: 1, TotalPages: 1},
+			}, nil
+		},
+	}
+
+	h := NewPlaybookHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/x/playbook-runs?status=waiting", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", customerID.String())
+	rr := httptest.NewRecorder()
+
+	h.ListCustomerRuns(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp service.PlaybookRunListResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Runs) != 1 || resp.Pagination.Total != 1 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestPlaybookStartRun_Conflict(t *testing.T) {
+	playbookID, customerID := uuid.New(), uuid.New()
+	mock := &mockPlaybookService{
+		startRunFn: func(ctx context.Context, pID, oID, uID uuid.UUID, req service.StartPlaybookRunRequest) (*service.PlaybookRunDetail, error) {
+			if pID != playbookID || req.CustomerID != customerID {
+				t.Fatalf("unexpected playbook %s or request %+v", pID, req)
+			}
+			return nil, &service.ConflictError{Message: "customer already has an active run of this playbook"}
+		},
+	}
+
+	h := NewPlaybookHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/playbooks/x/runs", strings.NewReader(`{"customer_id":"`+customerID.String()+`"}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", playbookID.String())
+	rr := httptest.NewRecorder()
+
+	h.StartRun(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestPlaybookGetRun_History(t *testing.T) {
+	runID := uuid.New()
+	mock := &mockPlaybookService{
+		getRunFn: func(ctx context.Context, id, oID uuid.UUID) (*service.PlaybookRunDetail, error) {
+			return &service.PlaybookRunDetail{
+				PlaybookRun: &repository.PlaybookRun{ID: id, Status: repository.PlaybookRunWaiting, CurrentStep: 1},
+				History: []*repository.PlaybookRunStep{
+					{RunID: id, StepIndex: 0, StepType: repository.PlaybookStepWait, Status: repository.PlaybookRunCompleted},
+				},
+			}, nil
+		},
+	}
+
+	h := NewPlaybookHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/playbook-runs/x", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", runID.String())
+	rr := httptest.NewRecorder()
+
+	h.GetRun(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["id"] != runID.String() || resp["status"] != "waiting" {
+		t.Errorf("expected run fields at top level, got %v", resp)
+	}
+	if history, _ := resp["history"].([]any); len(history) != 1 {
+		t.Errorf("expected 1 history entry, got %v", resp["history"])
+	}
+}
+
+func TestPlaybookCancelRun_NotFound(t *testing.T) {
+	mock := &mockPlaybookService{
+		cancelRunFn: func(ctx context.Context, id, oID uuid.UUID) (*service.PlaybookRunDetail, error) {
+			return nil, &service.NotFoundError{Resource: "playbook_run", Message: "playbook run not found"}
+		},
+	}
+
+	h := NewPlaybookHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/playbook-runs/x/cancel", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.CancelRun(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestPlaybookRotateWebhookSecret_Success(t *testing.T) {
+	orgID, playbookID := uuid.New(), uuid.New()
+	mock := &mockPlaybookService{
+		rotateFn: func(ctx context.Context, id, oID uuid.UUID) (string, error) {
+			if id != playbookID || oID != orgID {
+				t.Fatalf("unexpected playbook %s or org %s", id, oID)
+			}
+			return "new-secret", nil
+		},
+	}
+
+	h := NewPlaybookHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/playbooks/x/webhook-secret/rotate", nil)
+	req = withOrgAndUser(req, orgID, uuid.New())
+	req = withChiParam(req, "id", playbookID.String())
+	rr := httptest.NewRecorder()
+
+	h.RotateWebhookSecret(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]string
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["webhook_secret"] != "new-secret" {
+		t.Errorf("expected the new secret, got %v", resp)
+	}
+}
+
+func TestPlaybookGetWebhookSecret_NotFound(t *testing.T) {
+	mock := &mockPlaybookService{
+		secretFn: func(ctx context.Context, id, oID uuid.UUID) (string, error) {
+			return "", &service.NotFoundError{Resource: "playbook", Message: "playbook not found"}
+		},
+	}
+
+	h := NewPlaybookHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/playbooks/x/webhook-secret", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.GetWebhookSecret(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
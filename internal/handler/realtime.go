@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// realtimeHeartbeat keeps idle streams open through proxies.
+const realtimeHeartbeat = 25 * time.Second
+
+// RealtimeHandler streams realtime events over Server-Sent Events.
+type RealtimeHandler struct {
+	broker realtimeStreamer
+}
+
+// NewRealtimeHandler creates a new RealtimeHandler.
+func NewRealtimeHandler(broker realtimeStreamer) *RealtimeHandler {
+	return &RealtimeHandler{broker: broker}
+}
+
+// Stream handles GET /api/v1/notifications/stream.
+func (h *RealtimeHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	// EventSource resends Last-Event-ID on reconnect; the query parameter lets a
+	// client resume after a full page load.
+	var lastEventID int64
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	if v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid Last-Event-ID"))
+			return
+		}
+		lastEventID = id
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server's write timeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		slog.Error("realtime stream: clear write deadline", "error", err)
+	}
+
+	// Subscribe before replaying so nothing published in between is lost;
+	// live events the replay already sent are skipped below.
+	sub := h.broker.Subscribe(orgID, userID)
+	defer h.broker.Unsubscribe(sub)
+
+	var missed []*repository.RealtimeEvent
+	if lastEventID > 0 {
+		var err error
+		missed, err = h.broker.Replay(r.Context(), orgID, userID, lastEventID)
+		if err != nil {
+			handleServiceError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+	// IDs are assigned before commit, so events can arrive out of ID order.
+	// Only the overlap with the replay is a duplicate, not every lower ID.
+	replayed := make(map[int64]bool, len(missed))
+	for _, event := range missed {
+		if err := writeSSEEvent(w, event); err != nil {
+			return
+		}
+		replayed[event.ID] = true
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(realtimeHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped by the broker; the client reconnects with Last-Event-ID.
+				return
+			}
+			if replayed[event.ID] {
+				delete(replayed, event.ID)
+				continue
+			}
+			if err := writeSSEEvent(w, event); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeSSEEvent(w io.Writer, event *repository.RealtimeEvent) error {
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, data)
+	return err
+}
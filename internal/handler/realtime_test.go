@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockRealtimeStreamer struct {
+	live     []*repository.RealtimeEvent
+	replayFn func(ctx context.Context, orgID, userID uuid.UUID, lastEventID int64) ([]*repository.RealtimeEvent, error)
+}
+
+func (m *mockRealtimeStreamer) Subscribe(orgID, userID uuid.UUID) *service.RealtimeSubscription {
+	sub := &service.RealtimeSubscription{
+		OrgID:  orgID,
+		UserID: userID,
+		Events: make(chan *repository.RealtimeEvent, len(m.live)),
+	}
+	for _, e := range m.live {
+		sub.Events <- e
+	}
+	// Closing ends the stream once the queued events are written.
+	close(sub.Events)
+	return sub
+}
+
+func (m *mockRealtimeStreamer) Unsubscribe(*service.RealtimeSubscription) {}
+
+func (m *mockRealtimeStreamer) Replay(ctx context.Context, orgID, userID uuid.UUID, lastEventID int64) ([]*repository.RealtimeEvent, error) {
+	return m.replayFn(ctx, orgID, userID, lastEventID)
+}
+
+func TestRealtimeStream_Unauthorized(t *testing.T) {
+	h := NewRealtimeHandler(&mockRealtimeStreamer{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/stream", nil)
+	rr := httptest.NewRecorder()
+
+	h.Stream(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestRealtimeStream_InvalidLastEventID(t *testing.T) {
+	h := NewRealtimeHandler(&mockRealtimeStreamer{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	ctx := auth.WithUserID(req.Context(), uuid.New())
+	ctx = auth.WithOrgID(ctx, uuid.New())
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.Stream(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestRealtimeStream_ReplaysThenStreams(t *testing.T) {
+	var gotLastID int64
+	h := NewRealtimeHandler(&mockRealtimeStreamer{
+		replayFn: func(ctx context.Context, orgID, userID uuid.UUID, lastEventID int64) ([]*repository.RealtimeEvent, error) {
+			gotLastID = lastEventID
+			return []*repository.RealtimeEvent{
+				{ID: 8, EventType: "notification.created", Payload: map[string]any{"title": "missed"}},
+			}, nil
+		},
+		live: []*repository.RealtimeEvent{
+			{ID: 8, EventType: "notification.created", Payload: map[string]any{"title": "missed"}},
+			{ID: 9, EventType: "score.changed", Payload: map[string]any{"overall_score": 42}},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/stream", nil)
+	req.Header.Set("Last-Event-ID", "7")
+	ctx := auth.WithUserID(req.Context(), uuid.New())
+	ctx = auth.WithOrgID(ctx, uuid.New())
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.Stream(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if gotLastID != 7 {
+		t.Errorf("expected replay from 7, got %d", gotLastID)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+
+	body := rr.Body.String()
+	if n := strings.Count(body, "id: 8\n"); n != 1 {
+		t.Errorf("expected event 8 once, got %d times:\n%s", n, body)
+	}
+	if !strings.Contains(body, "id: 9\nevent: score.changed\ndata: {\"overall_score\":42}\n\n") {
+		t.Errorf("missing live event in body:\n%s", body)
+	}
+}
+
+func TestRealtimeStream_DeliversLateLowerIDs(t *testing.T) {
+	h := NewRealtimeHandler(&mockRealtimeStreamer{
+		replayFn: func(ctx context.Context, orgID, userID uuid.UUID, lastEventID int64) ([]*repository.RealtimeEvent, error) {
+			return []*repository.RealtimeEvent{
+				{ID: 12, EventType: "notification.created", Payload: map[string]any{}},
+			}, nil
+		},
+		// Event 11 committed after 12 and 13 were published
+		live: []*repository.RealtimeEvent{
+			{ID: 12, EventType: "notification.created", Payload: map[string]any{}},
+			{ID: 13, EventType: "score.changed", Payload: map[string]any{}},
+			{ID: 11, EventType: "score.changed", Payload: map[string]any{}},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/stream", nil)
+	req.Header.Set("Last-Event-ID", "10")
+	ctx := auth.WithUserID(req.Context(), uuid.New())
+	ctx = auth.WithOrgID(ctx, uuid.New())
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.Stream(rr, req)
+
+	body := rr.Body.String()
+	for id, want := range map[string]int{"11": 1, "12": 1, "13": 1} {
+		if n := strings.Count(body, "id: "+id+"\n"); n != want {
+			t.Errorf("expected event %s %d time(s), got %d:\n%s", id, want, n, body)
+		}
+	}
+}
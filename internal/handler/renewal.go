@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// RenewalHandler provides renewal HTTP endpoints.
+type RenewalHandler struct {
+	renewalService renewalServicer
+}
+
+// NewRenewalHandler creates a new RenewalHandler.
+func NewRenewalHandler(renewalService renewalServicer) *RenewalHandler {
+	return &RenewalHandler{renewalService: renewalService}
+}
+
+// Pipeline handles GET /api/v1/renewals.
+func (h *RenewalHandler) Pipeline(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	q := r.URL.Query()
+	params := service.RenewalPipelineParams{
+		Risk:           q.Get("risk"),
+		Sort:           q.Get("sort"),
+		Order:          q.Get("order"),
+		IncludeOverdue: q.Get("include_overdue") == "true",
+	}
+	if v := q.Get("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid days"))
+			return
+		}
+		params.Days = days
+	}
+	if v := q.Get("max_score"); v != "" {
+		maxScore, err := strconv.Atoi(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid max_score"))
+			return
+		}
+		params.MaxScore = &maxScore
+	}
+
+	// owner=me lists the caller's own customers' renewals
+	switch owner := q.Get("owner"); owner {
+	case "":
+	case "me":
+		userID, ok := auth.GetUserID(r.Context())
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+			return
+		}
+		params.OwnerID = &userID
+	default:
+		ownerID, err := uuid.Parse(owner)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid owner"))
+			return
+		}
+		params.OwnerID = &ownerID
+	}
+
+	pipeline, err := h.renewalService.Pipeline(r.Context(), orgID, params)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pipeline)
+}
+
+// GetCustomer handles GET /api/v1/customers/{id}/renewal.
+func (h *RenewalHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	renewal, err := h.renewalService.GetForCustomer(r.Context(), orgID, customerID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, renewal)
+}
+
+// SetCustomer handles PUT /api/v1/customers/{id}/renewal.
+func (h *RenewalHandler) SetCustomer(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	var req service.SetRenewalOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	renewal, err := h.renewalService.SetOverride(r.Context(), orgID, customerID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, renewal)
+}
+
+// ClearCustomer handles DELETE /api/v1/customers/{id}/renewal.
+func (h *RenewalHandler) ClearCustomer(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer ID"))
+		return
+	}
+
+	renewal, err := h.renewalService.ClearOverride(r.Context(), orgID, customerID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, renewal)
+}
+
+// GetSettings handles GET /api/v1/renewals/settings.
+func (h *RenewalHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	settings, err := h.renewalService.GetSettings(r.Context(), orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// UpdateSettings handles PATCH /api/v1/renewals/settings.
+func (h *RenewalHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	var req service.UpdateRenewalSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	settings, err := h.renewalService.UpdateSettings(r.Context(), orgID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
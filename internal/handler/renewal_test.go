@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockRenewalService struct {
+	pipelineFn       func(ctx context.Context, orgID uuid.UUID, params service.RenewalPipelineParams) (*service.RenewalPipeline, error)
+	getForCustomerFn func(ctx context.Context, orgID, customerID uuid.UUID) (*service.CustomerRenewal, error)
+	setOverrideFn    func(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetRenewalOverrideRequest) (*service.CustomerRenewal, error)
+	clearOverrideFn  func(ctx context.Context, orgID, customerID uuid.UUID) (*service.CustomerRenewal, error)
+	getSettingsFn    func(ctx context.Context, orgID uuid.UUID) (*repository.RenewalSettings, error)
+	updateSettingsFn func(ctx context.Context, orgID, userID uuid.UUID, req service.UpdateRenewalSettingsRequest) (*repository.RenewalSettings, error)
+}
+
+func (m *mockRenewalService) Pipeline(ctx context.Context, orgID uuid.UUID, params service.RenewalPipelineParams) (*service.RenewalPipeline, error) {
+	return m.pipelineFn(ctx, orgID, params)
+}
+
+func (m *mockRenewalService) GetForCustomer(ctx context.Context, orgID, customerID uuid.UUID) (*service.CustomerRenewal, error) {
+	return m.getForCustomerFn(ctx, orgID, customerID)
+}
+
+func (m *mockRenewalService) SetOverride(ctx context.Context, orgID, customerID, userID uuid.UUID, req service.SetRenewalOverrideRequest) (*service.CustomerRenewal, error) {
+	return m.setOverrideFn(ctx, orgID, customerID, userID, req)
+}
+
+func (m *mockRenewalService) ClearOverride(ctx context.Context, orgID, customerID uuid.UUID) (*service.CustomerRenewal, error) {
+	return m.clearOverrideFn(ctx, orgID, customerID)
+}
+
+func (m *mockRenewalService) GetSettings(ctx context.Context, orgID uuid.UUID) (*repository.RenewalSettings, error) {
+	return m.getSettingsFn(ctx, orgID)
+}
+
+func (m *mockRenewalService) UpdateSettings(ctx context.Context, orgID, userID uuid.UUID, req service.UpdateRenewalSettingsRequest) (*repository.RenewalSettings, error) {
+	return m.updateSettingsFn(ctx, orgID, userID, req)
+}
+
+func TestRenewalPipeline_Unauthorized(t *testing.T) {
+	h := NewRenewalHandler(&mockRenewalService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/renewals", nil)
+	rr := httptest.NewRecorder()
+
+	h.Pipeline(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestRenewalPipeline_Params(t *testing.T) {
+	userID := uuid.New()
+	score, risk := 35, "red"
+	mock := &mockRenewalService{
+		pipelineFn: func(ctx context.Context, oID uuid.UUID, params service.RenewalPipelineParams) (*service.RenewalPipeline, error) {
+			if params.Days != 60 || params.MaxScore == nil || *params.MaxScore != 50 || !params.IncludeOverdue {
+				t.Fatalf("unexpected params %+v", params)
+			}
+			if params.OwnerID == nil || *params.OwnerID != userID || params.Sort != "amount" {
+				t.Fatalf("unexpected owner or sort %+v", params)
+			}
+			return &service.RenewalPipeline{
+				To: "2026-12-18",
+				Renewals: []service.RenewalItem{{
+					Renewal: &repository.Renewal{
+						CustomerID:   uuid.New(),
+						CustomerName: "Globex",
+						RenewalDate:  time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC),
+						AmountCents:  1200000,
+						Currency:     "USD",
+						Source:       repository.RenewalSourceStripe,
+						OverallScore: &score,
+						RiskLevel:    &risk,
+					},
+					DaysUntil: 42,
+				}},
+			}, nil
+		},
+	}
+
+	h := NewRenewalHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/renewals?days=60&max_score=50&include_overdue=true&owner=me&sort=amount", nil)
+	req = withOrgAndUser(req, uuid.New(), userID)
+	rr := httptest.NewRecorder()
+
+	h.Pipeline(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Renewals []map[string]any `json:"renewals"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Renewals) != 1 || resp.Renewals[0]["customer_name"] != "Globex" || resp.Renewals[0]["days_until"] != float64(42) {
+		t.Errorf("expected renewal fields at top level, got %v", resp.Renewals)
+	}
+}
+
+func TestRenewalPipeline_InvalidMaxScore(t *testing.T) {
+	h := NewRenewalHandler(&mockRenewalService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/renewals?max_score=low", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.Pipeline(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestRenewalSetCustomer_Success(t *testing.T) {
+	orgID, userID, customerID := uuid.New(), uuid.New(), uuid.New()
+	mock := &mockRenewalService{
+		setOverrideFn: func(ctx context.Context, oID, cID, uID uuid.UUID, req service.SetRenewalOverrideRequest) (*service.CustomerRenewal, error) {
+			if oID != orgID || cID != customerID || uID != userID {
+				t.Fatalf("unexpected org %s, customer %s or caller %s", oID, cID, uID)
+			}
+			if req.RenewalDate != "2027-01-31" || req.AmountCents == nil || *req.AmountCents != 2400000 {
+				t.Fatalf("unexpected request %+v", req)
+			}
+			return &service.CustomerRenewal{}, nil
+		},
+	}
+
+	h := NewRenewalHandler(mock)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/x/renewal", strings.NewReader(`{"renewal_date":"2027-01-31","amount_cents":2400000,"notes":"Two-year deal"}`))
+	req = withOrgAndUser(req, orgID, userID)
+	req = withChiParam(req, "id", customerID.String())
+	rr := httptest.NewRecorder()
+
+	h.SetCustomer(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRenewalSetCustomer_ValidationError(t *testing.T) {
+	mock := &mockRenewalService{
+		setOverrideFn: func(ctx context.Context, oID, cID, uID uuid.UUID, req service.SetRenewalOverrideRequest) (*service.CustomerRenewal, error) {
+			return nil, &service.ValidationError{Field: "renewal_date", Message: "renewal_date must be a date (YYYY-MM-DD)"}
+		},
+	}
+
+	h := NewRenewalHandler(mock)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/x/renewal", strings.NewReader(`{"renewal_date":"next year"}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.SetCustomer(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestRenewalClearCustomer_InvalidID(t *testing.T) {
+	h := NewRenewalHandler(&mockRenewalService{})
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/customers/x/renewal", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", "not-a-uuid")
+	rr := httptest.NewRecorder()
+
+	h.ClearCustomer(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestRenewalUpdateSettings_Success(t *testing.T) {
+	mock := &mockRenewalService{
+		updateSettingsFn: func(ctx context.Context, oID, uID uuid.UUID, req service.UpdateRenewalSettingsRequest) (*repository.RenewalSettings, error) {
+			if req.HubSpotPipeline == nil || *req.HubSpotPipeline != "renewals" {
+				t.Fatalf("unexpected pipeline %v", req.HubSpotPipeline)
+			}
+			return &repository.RenewalSettings{OrgID: oID, HubSpotPipeline: req.HubSpotPipeline, ClosedStages: []string{"closedwon", "closedlost"}}, nil
+		},
+	}
+
+	h := NewRenewalHandler(mock)
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/renewals/settings", strings.NewReader(`{"hubspot_pipeline":"renewals"}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.UpdateSettings(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockSegmentService struct {
+	listFn    func(ctx context.Context, orgID uuid.UUID) ([]*service.SegmentResponse, error)
+	getFn     func(ctx context.Context, id, orgID uuid.UUID) (*service.SegmentResponse, error)
+	createFn  func(ctx context.Context, orgID, userID uuid.UUID, req service.CreateSegmentRequest) (*service.SegmentResponse, error)
+	updateFn  func(ctx context.Context, id, orgID uuid.UUID, req service.UpdateSegmentRequest) (*service.SegmentResponse, error)
+	deleteFn  func(ctx context.Context, id, orgID uuid.UUID) error
+	previewFn func(ctx context.Context, orgID uuid.UUID, req service.PreviewSegmentRequest) (*repository.SegmentStats, error)
+	historyFn func(ctx context.Context, id, orgID uuid.UUID, days int) (*service.SegmentHistory, error)
+}
+
+func (m *mockSegmentService) List(ctx context.Context, orgID uuid.UUID) ([]*service.SegmentResponse, error) {
+	return m.listFn(ctx, orgID)
+}
+
+func (m *mockSegmentService) Get(ctx context.Context, id, orgID uuid.UUID) (*service.SegmentResponse, error) {
+	return m.getFn(ctx, id, orgID)
+}
+
+func (m *mockSegmentService) Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateSegmentRequest) (*service.SegmentResponse, error) {
+	return m.createFn(ctx, orgID, userID, req)
+}
+
+func (m *mockSegmentService) Update(ctx context.Context, id, orgID uuid.UUID, req service.UpdateSegmentRequest) (*service.SegmentResponse, error) {
+	return m.updateFn(ctx, id, orgID, req)
+}
+
+func (m *mockSegmentService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	return m.deleteFn(ctx, id, orgID)
+}
+
+func (m *mockSegmentService) Preview(ctx context.Context, orgID uuid.UUID, req service.PreviewSegmentRequest) (*repository.SegmentStats, error) {
+	return m.previewFn(ctx, orgID, req)
+}
+
+func (m *mockSegmentService) History(ctx context.Context, id, orgID uuid.UUID, days int) (*service.SegmentHistory, error) {
+	return m.historyFn(ctx, id, orgID, days)
+}
+
+func TestSegmentList_Unauthorized(t *testing.T) {
+	h := NewSegmentHandler(&mockSegmentService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/segments", nil)
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestSegmentCreate_Success(t *testing.T) {
+	orgID, userID := uuid.New(), uuid.New()
+	mock := &mockSegmentService{
+		createFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreateSegmentRequest) (*service.SegmentResponse, error) {
+			if oID != orgID || uID != userID {
+				t.Fatalf("unexpected org %s or caller %s", oID, uID)
+			}
+			if len(req.Filter.All) != 2 || req.Filter.All[0].Field != "risk" || req.Filter.All[1].Op != "gte" {
+				t.Fatalf("unexpected filter %+v", req.Filter)
+			}
+			return &service.SegmentResponse{
+				Segment:     &repository.Segment{ID: uuid.New(), Name: req.Name, Filter: req.Filter},
+				MemberCount: 4,
+			}, nil
+		},
+	}
+
+	h := NewSegmentHandler(mock)
+	body := `{"name":"Enterprise at risk","filter":{"all":[{"field":"risk","op":"eq","value":"red"},{"field":"mrr_cents","op":"gte","value":100000}]}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/segments", strings.NewReader(body))
+	req = withOrgAndUser(req, orgID, userID)
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["name"] != "Enterprise at risk" || resp["member_count"] != float64(4) {
+		t.Errorf("expected segment fields at top level, got %v", resp)
+	}
+}
+
+func TestSegmentCreate_Conflict(t *testing.T) {
+	mock := &mockSegmentService{
+		createFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreateSegmentRequest) (*service.SegmentResponse, error) {
+			return nil, &service.ConflictError{Message: "a segment with this name already exists"}
+		},
+	}
+
+	h := NewSegmentHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/segments", strings.NewReader(`{"name":"VIP","filter":{"field":"tag","op":"eq","value":"vip"}}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestSegmentUpdate_InvalidID(t *testing.T) {
+	h := NewSegmentHandler(&mockSegmentService{})
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/segments/x", strings.NewReader(`{}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", "not-a-uuid")
+	rr := httptest.NewRecorder()
+
+	h.Update(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestSegmentDelete_InUse(t *testing.T) {
+	mock := &mockSegmentService{
+		deleteFn: func(ctx context.Context, id, oID uuid.UUID) error {
+			return &service.ConflictError{Message: "segment is used by 1 alert rule(s)"}
+		},
+	}
+
+	h := NewSegmentHandler(mock)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/segments/x", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Delete(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+}
+
+func TestSegmentPreview_ValidationError(t *testing.T) {
+	mock := &mockSegmentService{
+		previewFn: func(ctx context.Context, oID uuid.UUID, req service.PreviewSegmentRequest) (*repository.SegmentStats, error) {
+			if req.Filter.Field != "shoe_size" {
+				t.Fatalf("unexpected filter %+v", req.Filter)
+			}
+			return nil, &service.ValidationError{Field: "filter", Message: "shoe_size: unknown field"}
+		},
+	}
+
+	h := NewSegmentHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/segments/preview", strings.NewReader(`{"filter":{"field":"shoe_size","op":"eq","value":9}}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.Preview(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestSegmentHistory_Days(t *testing.T) {
+	segmentID := uuid.New()
+	mock := &mockSegmentService{
+		historyFn: func(ctx context.Context, id, oID uuid.UUID, days int) (*service.SegmentHistory, error) {
+			if id != segmentID || days != 90 {
+				t.Fatalf("unexpected segment %s or days %d", id, days)
+			}
+			return &service.SegmentHistory{
+				SegmentID: id,
+				Counts:    []repository.SegmentCount{{Date: "2026-10-01", MemberCount: 12, MRRCents: 50000}},
+			}, nil
+		},
+	}
+
+	h := NewSegmentHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/segments/x/history?days=90", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", segmentID.String())
+	rr := httptest.NewRecorder()
+
+	h.History(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp service.SegmentHistory
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Counts) != 1 || resp.Counts[0].MemberCount != 12 {
+		t.Errorf("unexpected history %+v", resp)
+	}
+}
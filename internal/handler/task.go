@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// TaskHandler provides task HTTP endpoints.
+type TaskHandler struct {
+	taskService taskServicer
+}
+
+// NewTaskHandler creates a new TaskHandler.
+func NewTaskHandler(taskService taskServicer) *TaskHandler {
+	return &TaskHandler{taskService: taskService}
+}
+
+// List handles GET /api/v1/tasks.
+func (h *TaskHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	q := r.URL.Query()
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+
+	params := repository.TaskListParams{
+		OrgID:   orgID,
+		Page:    page,
+		PerPage: perPage,
+		Sort:    q.Get("sort"),
+		Order:   q.Get("order"),
+		Overdue: q.Get("overdue") == "true",
+	}
+	if status := q.Get("status"); status != "" {
+		params.Statuses = strings.Split(status, ",")
+	}
+
+	// assignee=me lists the caller's own tasks, assignee=none the unassigned ones
+	switch assignee := q.Get("assignee"); assignee {
+	case "":
+	case "me":
+		userID, ok := auth.GetUserID(r.Context())
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+			return
+		}
+		params.AssigneeID = &userID
+	case "none":
+		params.Unassigned = true
+	default:
+		assigneeID, err := uuid.Parse(assignee)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid assignee"))
+			return
+		}
+		params.AssigneeID = &assigneeID
+	}
+
+	if v := q.Get("customer_id"); v != "" {
+		customerID, err := uuid.Parse(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid customer_id"))
+			return
+		}
+		params.CustomerID = &customerID
+	}
+	if v := q.Get("alert_history_id"); v != "" {
+		alertID, err := uuid.Parse(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid alert_history_id"))
+			return
+		}
+		params.AlertHistoryID = &alertID
+	}
+	if v := q.Get("due_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid due_after"))
+			return
+		}
+		params.DueAfter = &t
+	}
+	if v := q.Get("due_before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid due_before"))
+			return
+		}
+		params.DueBefore = &t
+	}
+
+	resp, err := h.taskService.List(r.Context(), params)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Get handles GET /api/v1/tasks/{id}.
+func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid task ID"))
+		return
+	}
+
+	task, err := h.taskService.Get(r.Context(), id, orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+// Create handles POST /api/v1/tasks.
+func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	var req service.CreateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	task, err := h.taskService.Create(r.Context(), orgID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, task)
+}
+
+// Update handles PATCH /api/v1/tasks/{id}.
+func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid task ID"))
+		return
+	}
+
+	var req service.UpdateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	task, err := h.taskService.Update(r.Context(), id, orgID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+// Delete handles DELETE /api/v1/tasks/{id}.
+func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid task ID"))
+		return
+	}
+
+	if err := h.taskService.Delete(r.Context(), id, orgID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusNoContent, nil)
+}
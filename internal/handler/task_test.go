@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockTaskService struct {
+	listFn   func(ctx context.Context, params repository.TaskListParams) (*service.TaskListResponse, error)
+	getFn    func(ctx context.Context, id, orgID uuid.UUID) (*repository.Task, error)
+	createFn func(ctx context.Context, orgID, userID uuid.UUID, req service.CreateTaskRequest) (*repository.Task, error)
+	updateFn func(ctx context.Context, id, orgID, userID uuid.UUID, req service.UpdateTaskRequest) (*repository.Task, error)
+	deleteFn func(ctx context.Context, id, orgID, userID uuid.UUID) error
+}
+
+func (m *mockTaskService) List(ctx context.Context, params repository.TaskListParams) (*service.TaskListResponse, error) {
+	return m.listFn(ctx, params)
+}
+
+func (m *mockTaskService) Get(ctx context.Context, id, orgID uuid.UUID) (*repository.Task, error) {
+	return m.getFn(ctx, id, orgID)
+}
+
+func (m *mockTaskService) Create(ctx context.Context, orgID, userID uuid.UUID, req service.CreateTaskRequest) (*repository.Task, error) {
+	return m.createFn(ctx, orgID, userID, req)
+}
+
+func (m *mockTaskService) Update(ctx context.Context, id, orgID, userID uuid.UUID, req service.UpdateTaskRequest) (*repository.Task, error) {
+	return m.updateFn(ctx, id, orgID, userID, req)
+}
+
+func (m *mockTaskService) Delete(ctx context.Context, id, orgID, userID uuid.UUID) error {
+	return m.deleteFn(ctx, id, orgID, userID)
+}
+
+func TestTaskList_Unauthorized(t *testing.T) {
+	h := NewTaskHandler(&mockTaskService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestTaskList_Filters(t *testing.T) {
+	orgID, userID, customerID := uuid.New(), uuid.New(), uuid.New()
+	mock := &mockTaskService{
+		listFn: func(ctx context.Context, params repository.TaskListParams) (*service.TaskListResponse, error) {
+			if params.AssigneeID == nil || *params.AssigneeID != userID {
+				t.Fatalf("expected assignee %s, got %v", userID, params.AssigneeID)
+			}
+			if len(params.Statuses) != 2 || params.Statuses[1] != "in_progress" {
+				t.Fatalf("unexpected statuses %v", params.Statuses)
+			}
+			if params.CustomerID == nil || *params.CustomerID != customerID {
+				t.Fatalf("unexpected customer %v", params.CustomerID)
+			}
+			if !params.Overdue || params.DueBefore == nil || params.Page != 2 {
+				t.Fatalf("unexpected params %+v", params)
+			}
+			return &service.TaskListResponse{
+				Tasks:      []*repository.Task{{ID: uuid.New(), Title: "Call back"}},
+				Pagination: service.PaginationMeta{Page: 2, PerPage: 25, Total: 26, TotalPages: 2},
+			}, nil
+		},
+	}
+
+	h := NewTaskHandler(mock)
+	url := "/api/v1/tasks?assignee=me&status=open,in_progress&overdue=true&due_before=2026-01-01T00:00:00Z&page=2&customer_id=" + customerID.String()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req = withOrgAndUser(req, orgID, userID)
+	rr := httptest.NewRecorder()
+
+	h.List(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp service.TaskListResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Tasks) != 1 || resp.Pagination.Total != 26 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestTaskList_InvalidFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"assignee", "assignee=someone"},
+		{"customer", "customer_id=abc"},
+		{"due date", "due_after=yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewTaskHandler(&mockTaskService{})
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?"+tt.query, nil)
+			req = withOrgAndUser(req, uuid.New(), uuid.New())
+			rr := httptest.NewRecorder()
+
+			h.List(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestTaskCreate_Success(t *testing.T) {
+	orgID, userID, assigneeID := uuid.New(), uuid.New(), uuid.New()
+	mock := &mockTaskService{
+		createFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreateTaskRequest) (*repository.Task, error) {
+			if oID != orgID || uID != userID {
+				t.Fatalf("unexpected org %s or caller %s", oID, uID)
+			}
+			if req.Title != "Schedule QBR" || req.AssigneeID == nil || *req.AssigneeID != assigneeID || req.DueAt == nil {
+				t.Fatalf("unexpected request %+v", req)
+			}
+			return &repository.Task{ID: uuid.New(), Title: req.Title, Status: repository.TaskStatusOpen, AssigneeID: req.AssigneeID}, nil
+		},
+	}
+
+	h := NewTaskHandler(mock)
+	body := `{"title":"Schedule QBR","due_at":"2026-11-01T17:00:00Z","assignee_id":"` + assigneeID.String() + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(body))
+	req = withOrgAndUser(req, orgID, userID)
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestTaskCreate_ValidationError(t *testing.T) {
+	mock := &mockTaskService{
+		createFn: func(ctx context.Context, oID, uID uuid.UUID, req service.CreateTaskRequest) (*repository.Task, error) {
+			return nil, &service.ValidationError{Field: "title", Message: "title is required"}
+		},
+	}
+
+	h := NewTaskHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{"title":""}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	rr := httptest.NewRecorder()
+
+	h.Create(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rr.Code)
+	}
+}
+
+func TestTaskUpdate_Complete(t *testing.T) {
+	taskID := uuid.New()
+	mock := &mockTaskService{
+		updateFn: func(ctx context.Context, id, oID, uID uuid.UUID, req service.UpdateTaskRequest) (*repository.Task, error) {
+			if id != taskID {
+				t.Fatalf("unexpected task %s", id)
+			}
+			if req.Status == nil || *req.Status != repository.TaskStatusDone || !req.Unassign {
+				t.Fatalf("unexpected request %+v", req)
+			}
+			return &repository.Task{ID: id, Status: *req.Status}, nil
+		},
+	}
+
+	h := NewTaskHandler(mock)
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/x", strings.NewReader(`{"status":"done","unassign":true}`))
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", taskID.String())
+	rr := httptest.NewRecorder()
+
+	h.Update(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestTaskDelete_Forbidden(t *testing.T) {
+	mock := &mockTaskService{
+		deleteFn: func(ctx context.Context, id, oID, uID uuid.UUID) error {
+			return &service.ForbiddenError{Message: "only the creator or an admin can delete a task"}
+		},
+	}
+
+	h := NewTaskHandler(mock)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/tasks/x", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Delete(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rr.Code)
+	}
+}
+
+func TestTaskGet_NotFound(t *testing.T) {
+	mock := &mockTaskService{
+		getFn: func(ctx context.Context, id, oID uuid.UUID) (*repository.Task, error) {
+			return nil, &service.NotFoundError{Resource: "task", Message: "task not found"}
+		},
+	}
+
+	h := NewTaskHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/x", nil)
+	req = withOrgAndUser(req, uuid.New(), uuid.New())
+	req = withChiParam(req, "id", uuid.New().String())
+	rr := httptest.NewRecorder()
+
+	h.Get(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+)
+
+// WebhookInboxHandler provides admin endpoints for failed inbound webhooks.
+type WebhookInboxHandler struct {
+	inboxService webhookInboxServicer
+}
+
+// NewWebhookInboxHandler creates a new WebhookInboxHandler.
+func NewWebhookInboxHandler(inboxService webhookInboxServicer) *WebhookInboxHandler {
+	return &WebhookInboxHandler{inboxService: inboxService}
+}
+
+// ListFailed handles GET /api/v1/webhooks/inbox.
+func (h *WebhookInboxHandler) ListFailed(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	events, total, err := h.inboxService.ListFailed(r.Context(), orgID, status, limit, offset)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"events": events,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Replay handles POST /api/v1/webhooks/inbox/{id}/replay.
+func (h *WebhookInboxHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.GetOrgID(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid webhook event ID"))
+		return
+	}
+
+	event, err := h.inboxService.Replay(r.Context(), id, orgID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, event)
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type mockWebhookInboxService struct {
+	listFailedFn func(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.WebhookInboxEvent, int, error)
+	replayFn     func(ctx context.Context, id, orgID uuid.UUID) (*repository.WebhookInboxEvent, error)
+}
+
+func (m *mockWebhookInboxService) ListFailed(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.WebhookInboxEvent, int, error) {
+	return m.listFailedFn(ctx, orgID, status, limit, offset)
+}
+
+func (m *mockWebhookInboxService) Replay(ctx context.Context, id, orgID uuid.UUID) (*repository.WebhookInboxEvent, error) {
+	return m.replayFn(ctx, id, orgID)
+}
+
+func TestWebhookInboxListFailed_Unauthorized(t *testing.T) {
+	h := NewWebhookInboxHandler(&mockWebhookInboxService{})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/inbox", nil)
+	rr := httptest.NewRecorder()
+
+	h.ListFailed(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestWebhookInboxListFailed_Success(t *testing.T) {
+	orgID := uuid.New()
+	mock := &mockWebhookInboxService{
+		listFailedFn: func(ctx context.Context, gotOrgID uuid.UUID, status string, limit, offset int) ([]*repository.WebhookInboxEvent, int, error) {
+			if gotOrgID != orgID {
+				t.Fatalf("unexpected org ID %s", gotOrgID)
+			}
+			if status != "dead" || limit != 25 || offset != 0 {
+				t.Fatalf("unexpected query status=%q limit=%d offset=%d", status, limit, offset)
+			}
+			return []*repository.WebhookInboxEvent{{ID: uuid.New(), Provider: "stripe", Status: "dead"}}, 1, nil
+		},
+	}
+
+	h := NewWebhookInboxHandler(mock)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/inbox?status=dead", nil)
+	req = req.WithContext(auth.WithOrgID(req.Context(), orgID))
+	rr := httptest.NewRecorder()
+
+	h.ListFailed(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var resp struct {
+		Events []map[string]any `json:"events"`
+		Total  int              `json:"total"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Total != 1 || len(resp.Events) != 1 {
+		t.Fatalf("expected 1 event, got total=%d len=%d", resp.Total, len(resp.Events))
+	}
+}
+
+func TestWebhookInboxReplay_InvalidID(t *testing.T) {
+	h := NewWebhookInboxHandler(&mockWebhookInboxService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/inbox/not-a-uuid/replay", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "not-a-uuid")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = auth.WithOrgID(ctx, uuid.New())
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.Replay(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestWebhookInboxReplay_NotFound(t *testing.T) {
+	mock := &mockWebhookInboxService{
+		replayFn: func(ctx context.Context, id, orgID uuid.UUID) (*repository.WebhookInboxEvent, error) {
+			return nil, &service.NotFoundError{Resource: "webhook_event", Message: "failed webhook event not found"}
+		},
+	}
+
+	id := uuid.New()
+	h := NewWebhookInboxHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/inbox/"+id.String()+"/replay", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id.String())
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = auth.WithOrgID(ctx, uuid.New())
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.Replay(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestWebhookInboxReplay_Success(t *testing.T) {
+	id := uuid.New()
+	mock := &mockWebhookInboxService{
+		replayFn: func(ctx context.Context, gotID, orgID uuid.UUID) (*repository.WebhookInboxEvent, error) {
+			return &repository.WebhookInboxEvent{ID: gotID, Provider: "hubspot", Status: "pending"}, nil
+		},
+	}
+
+	h := NewWebhookInboxHandler(mock)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/inbox/"+id.String()+"/replay", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id.String())
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = auth.WithOrgID(ctx, uuid.New())
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.Replay(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+)
+
+// WebhookIntegrationHandler receives webhooks for every registered integration provider.
+type WebhookIntegrationHandler struct {
+	providers *integration.Registry
+}
+
+// NewWebhookIntegrationHandler creates a new WebhookIntegrationHandler.
+func NewWebhookIntegrationHandler(providers *integration.Registry) *WebhookIntegrationHandler {
+	return &WebhookIntegrationHandler{providers: providers}
+}
+
+// HandleWebhook handles POST /api/v1/webhooks/{provider}.
+func (h *WebhookIntegrationHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers.Get(chi.URLParam(r, "provider"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse("unknown integration provider"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, webhookMaxBodyBytes)
+
+	payload, err := readBody(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+		return
+	}
+
+	err = provider.HandleWebhook(r.Context(), integration.WebhookRequest{
+		Method:     r.Method,
+		RequestURI: r.URL.RequestURI(),
+		Header:     r.Header,
+		Body:       payload,
+	})
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	case errors.Is(err, integration.ErrInvalidSignature):
+		slog.Warn("webhook signature verification failed", "provider", provider.Name(), "error", err)
+		writeJSON(w, http.StatusUnauthorized, errorResponse("invalid signature"))
+	case errors.Is(err, integration.ErrInvalidPayload):
+		writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+	default:
+		// The event was not stored; a non-2xx response makes the provider redeliver it.
+		slog.Error("webhook enqueue error", "provider", provider.Name(), "error", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse("failed to store webhook"))
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+)
+
+type fakeProvider struct {
+	name            string
+	handleWebhookFn func(ctx context.Context, req integration.WebhookRequest) error
+}
+
+func (p *fakeProvider) Name() string        { return p.name }
+func (p *fakeProvider) DisplayName() string { return p.name }
+func (p *fakeProvider) ConnectURL(orgID uuid.UUID) (string, error) {
+	return "", nil
+}
+func (p *fakeProvider) ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error {
+	return nil
+}
+func (p *fakeProvider) RefreshToken(ctx context.Context, orgID uuid.UUID) error { return nil }
+func (p *fakeProvider) Status(ctx context.Context, orgID uuid.UUID) (any, error) {
+	return nil, nil
+}
+func (p *fakeProvider) Disconnect(ctx context.Context, orgID uuid.UUID) error  { return nil }
+func (p *fakeProvider) CheckHealth(ctx context.Context, orgID uuid.UUID) error { return nil }
+func (p *fakeProvider) RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) {
+}
+func (p *fakeProvider) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) {
+}
+func (p *fakeProvider) HandleWebhook(ctx context.Context, req integration.WebhookRequest) error {
+	return p.handleWebhookFn(ctx, req)
+}
+func (p *fakeProvider) ProcessWebhook(ctx context.Context, payload []byte) error { return nil }
+func (p *fakeProvider) Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error) {
+	return &integration.DriftReport{}, nil
+}
+
+func newWebhookTestHandler(fn func(ctx context.Context, req integration.WebhookRequest) error) *WebhookIntegrationHandler {
+	providers := integration.NewRegistry()
+	providers.Register(&fakeProvider{name: "intercom", handleWebhookFn: fn})
+	return NewWebhookIntegrationHandler(providers)
+}
+
+func TestWebhookIntegration_UnknownProvider(t *testing.T) {
+	h := newWebhookTestHandler(nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/zendesk", strings.NewReader(`{}`))
+	req = withChiParam(req, "provider", "zendesk")
+	rr := httptest.NewRecorder()
+
+	h.HandleWebhook(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestWebhookIntegration_InvalidSignature(t *testing.T) {
+	h := newWebhookTestHandler(func(ctx context.Context, req integration.WebhookRequest) error {
+		return fmt.Errorf("%w: missing signature", integration.ErrInvalidSignature)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/intercom", strings.NewReader(`{}`))
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.HandleWebhook(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestWebhookIntegration_InvalidPayload(t *testing.T) {
+	h := newWebhookTestHandler(func(ctx context.Context, req integration.WebhookRequest) error {
+		return fmt.Errorf("%w: unexpected end of JSON input", integration.ErrInvalidPayload)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/intercom", strings.NewReader(`{`))
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.HandleWebhook(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestWebhookIntegration_EnqueueError(t *testing.T) {
+	h := newWebhookTestHandler(func(ctx context.Context, req integration.WebhookRequest) error {
+		return errors.New("db down")
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/intercom", strings.NewReader(`{}`))
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.HandleWebhook(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rr.Code)
+	}
+}
+
+func TestWebhookIntegration_Success(t *testing.T) {
+	h := newWebhookTestHandler(func(ctx context.Context, req integration.WebhookRequest) error {
+		if string(req.Body) != `{"type":"notification_event"}` {
+			t.Errorf("unexpected body %q", req.Body)
+		}
+		if req.Header.Get("X-Hub-Signature") != "sha1=abc" {
+			t.Errorf("expected signature header to be passed through")
+		}
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/intercom", strings.NewReader(`{"type":"notification_event"}`))
+	req.Header.Set("X-Hub-Signature", "sha1=abc")
+	req = withChiParam(req, "provider", "intercom")
+	rr := httptest.NewRecorder()
+
+	h.HandleWebhook(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,150 @@
+// Package hubspot registers the HubSpot connector with the integration registry.
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// oauthService is the part of service.HubSpotOAuthService the provider uses.
+type oauthService interface {
+	ConnectURL(orgID uuid.UUID) (string, error)
+	ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error
+	RefreshToken(ctx context.Context, orgID uuid.UUID) error
+	GetStatus(ctx context.Context, orgID uuid.UUID) (*service.HubSpotConnectionStatus, error)
+	Disconnect(ctx context.Context, orgID uuid.UUID) error
+	GetAccessToken(ctx context.Context, orgID uuid.UUID) (string, error)
+}
+
+// apiClient is the part of service.HubSpotClient the provider uses.
+type apiClient interface {
+	CheckAccess(ctx context.Context, accessToken string) error
+}
+
+// syncOrchestrator is the part of service.HubSpotSyncOrchestratorService the provider uses.
+type syncOrchestrator interface {
+	RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) *service.HubSpotSyncResult
+	RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) *service.HubSpotSyncResult
+	Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error)
+}
+
+// webhookService is the part of service.HubSpotWebhookService the provider uses.
+type webhookService interface {
+	VerifySignature(requestBody []byte, signatureHeader, timestamp, httpMethod, requestURI string) error
+	ProcessEvents(ctx context.Context, webhookEvents []service.HubSpotWebhookEvent) error
+	ProcessPayload(ctx context.Context, payload []byte) error
+}
+
+// Provider exposes the HubSpot connector through the integration.Provider interface.
+type Provider struct {
+	oauthSvc     oauthService
+	client       apiClient
+	orchestrator syncOrchestrator
+	webhookSvc   webhookService
+}
+
+// NewProvider creates a new Provider.
+func NewProvider(
+	oauthSvc *service.HubSpotOAuthService,
+	client *service.HubSpotClient,
+	orchestrator *service.HubSpotSyncOrchestratorService,
+	webhookSvc *service.HubSpotWebhookService,
+) *Provider {
+	return &Provider{
+		oauthSvc:     oauthSvc,
+		client:       client,
+		orchestrator: orchestrator,
+		webhookSvc:   webhookSvc,
+	}
+}
+
+var _ integration.Provider = (*Provider)(nil)
+
+// Name returns "hubspot".
+func (p *Provider) Name() string { return "hubspot" }
+
+// DisplayName returns "HubSpot".
+func (p *Provider) DisplayName() string { return "HubSpot" }
+
+// ConnectURL returns the HubSpot OAuth authorization URL.
+func (p *Provider) ConnectURL(orgID uuid.UUID) (string, error) {
+	return p.oauthSvc.ConnectURL(orgID)
+}
+
+// ExchangeCode completes the HubSpot OAuth flow.
+func (p *Provider) ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error {
+	return p.oauthSvc.ExchangeCode(ctx, orgID, code, state)
+}
+
+// RefreshToken renews the HubSpot access token.
+func (p *Provider) RefreshToken(ctx context.Context, orgID uuid.UUID) error {
+	return p.oauthSvc.RefreshToken(ctx, orgID)
+}
+
+// Status returns the HubSpot connection status.
+func (p *Provider) Status(ctx context.Context, orgID uuid.UUID) (any, error) {
+	return p.oauthSvc.GetStatus(ctx, orgID)
+}
+
+// Disconnect removes the HubSpot connection.
+func (p *Provider) Disconnect(ctx context.Context, orgID uuid.UUID) error {
+	return p.oauthSvc.Disconnect(ctx, orgID)
+}
+
+// CheckHealth lists one page of contacts to verify the access token works.
+func (p *Provider) CheckHealth(ctx context.Context, orgID uuid.UUID) error {
+	accessToken, err := p.oauthSvc.GetAccessToken(ctx, orgID)
+	if errors.Is(err, integration.ErrReauthRequired) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", integration.ErrCredentials, err)
+	}
+
+	return p.client.CheckAccess(ctx, accessToken)
+}
+
+// RunFullSync runs a full HubSpot sync.
+func (p *Provider) RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) {
+	p.orchestrator.RunFullSync(ctx, orgID, trigger)
+}
+
+// RunIncrementalSync runs an incremental HubSpot sync.
+func (p *Provider) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) {
+	p.orchestrator.RunIncrementalSync(ctx, orgID, since, trigger)
+}
+
+// Reconcile compares local deals with HubSpot.
+func (p *Provider) Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error) {
+	return p.orchestrator.Reconcile(ctx, orgID, heal)
+}
+
+// HandleWebhook verifies a HubSpot webhook batch and stores its events in the webhook inbox.
+func (p *Provider) HandleWebhook(ctx context.Context, req integration.WebhookRequest) error {
+	sigHeader := req.Header.Get("X-HubSpot-Signature-v3")
+	timestamp := req.Header.Get("X-HubSpot-Request-Timestamp")
+
+	if err := p.webhookSvc.VerifySignature(req.Body, sigHeader, timestamp, req.Method, req.RequestURI); err != nil {
+		return fmt.Errorf("%w: %v", integration.ErrInvalidSignature, err)
+	}
+
+	var events []service.HubSpotWebhookEvent
+	if err := json.Unmarshal(req.Body, &events); err != nil {
+		return fmt.Errorf("%w: %v", integration.ErrInvalidPayload, err)
+	}
+
+	return p.webhookSvc.ProcessEvents(ctx, events)
+}
+
+// ProcessWebhook processes a stored HubSpot webhook event.
+func (p *Provider) ProcessWebhook(ctx context.Context, payload []byte) error {
+	return p.webhookSvc.ProcessPayload(ctx, payload)
+}
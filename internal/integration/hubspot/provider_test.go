@@ -0,0 +1,55 @@
+package hubspot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type fakeWebhookService struct {
+	webhookService
+	verifyErr error
+	events    []service.HubSpotWebhookEvent
+}
+
+func (f *fakeWebhookService) VerifySignature(requestBody []byte, signatureHeader, timestamp, httpMethod, requestURI string) error {
+	return f.verifyErr
+}
+
+func (f *fakeWebhookService) ProcessEvents(ctx context.Context, webhookEvents []service.HubSpotWebhookEvent) error {
+	f.events = append(f.events, webhookEvents...)
+	return nil
+}
+
+func TestHandleWebhook(t *testing.T) {
+	req := integration.WebhookRequest{
+		Method:     http.MethodPost,
+		RequestURI: "/api/v1/webhooks/hubspot",
+		Header:     http.Header{"X-Hubspot-Signature-V3": {"sig"}},
+		Body:       []byte(`[{"eventId":1,"portalId":42,"subscriptionType":"contact.creation","objectId":7}]`),
+	}
+
+	webhooks := &fakeWebhookService{}
+	p := &Provider{webhookSvc: webhooks}
+	if err := p.HandleWebhook(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(webhooks.events) != 1 || webhooks.events[0].ObjectID != 7 {
+		t.Errorf("expected the batch's event to be queued, got %+v", webhooks.events)
+	}
+
+	p = &Provider{webhookSvc: &fakeWebhookService{verifyErr: errors.New("signature mismatch")}}
+	if err := p.HandleWebhook(context.Background(), req); !errors.Is(err, integration.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	req.Body = []byte(`{"eventId":1}`)
+	p = &Provider{webhookSvc: &fakeWebhookService{}}
+	if err := p.HandleWebhook(context.Background(), req); !errors.Is(err, integration.ErrInvalidPayload) {
+		t.Errorf("expected a non-batch body to be ErrInvalidPayload, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+// Package intercom registers the Intercom connector with the integration registry.
+package intercom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// oauthService is the part of service.IntercomOAuthService the provider uses.
+type oauthService interface {
+	ConnectURL(orgID uuid.UUID) (string, error)
+	ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error
+	GetStatus(ctx context.Context, orgID uuid.UUID) (*service.IntercomConnectionStatus, error)
+	Disconnect(ctx context.Context, orgID uuid.UUID) error
+	GetAccessToken(ctx context.Context, orgID uuid.UUID) (string, error)
+}
+
+// apiClient is the part of service.IntercomClient the provider uses.
+type apiClient interface {
+	CheckAccess(ctx context.Context, accessToken string) error
+}
+
+// syncOrchestrator is the part of service.IntercomSyncOrchestratorService the provider uses.
+type syncOrchestrator interface {
+	RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) *service.IntercomSyncResult
+	RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) *service.IntercomSyncResult
+	Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error)
+}
+
+// webhookService is the part of service.IntercomWebhookService the provider uses.
+type webhookService interface {
+	VerifySignature(requestBody []byte, signatureHeader string) error
+	ProcessEvent(ctx context.Context, event service.IntercomWebhookEvent, payload []byte) error
+	ProcessPayload(ctx context.Context, payload []byte) error
+}
+
+// Provider exposes the Intercom connector through the integration.Provider interface.
+type Provider struct {
+	oauthSvc     oauthService
+	client       apiClient
+	orchestrator syncOrchestrator
+	webhookSvc   webhookService
+}
+
+// NewProvider creates a new Provider.
+func NewProvider(
+	oauthSvc *service.IntercomOAuthService,
+	client *service.IntercomClient,
+	orchestrator *service.IntercomSyncOrchestratorService,
+	webhookSvc *service.IntercomWebhookService,
+) *Provider {
+	return &Provider{
+		oauthSvc:     oauthSvc,
+		client:       client,
+		orchestrator: orchestrator,
+		webhookSvc:   webhookSvc,
+	}
+}
+
+var _ integration.Provider = (*Provider)(nil)
+
+// Name returns "intercom".
+func (p *Provider) Name() string { return "intercom" }
+
+// DisplayName returns "Intercom".
+func (p *Provider) DisplayName() string { return "Intercom" }
+
+// ConnectURL returns the Intercom OAuth authorization URL.
+func (p *Provider) ConnectURL(orgID uuid.UUID) (string, error) {
+	return p.oauthSvc.ConnectURL(orgID)
+}
+
+// ExchangeCode completes the Intercom OAuth flow.
+func (p *Provider) ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error {
+	return p.oauthSvc.ExchangeCode(ctx, orgID, code, state)
+}
+
+// RefreshToken is a no-op: Intercom access tokens do not expire.
+func (p *Provider) RefreshToken(ctx context.Context, orgID uuid.UUID) error {
+	return nil
+}
+
+// Status returns the Intercom connection status.
+func (p *Provider) Status(ctx context.Context, orgID uuid.UUID) (any, error) {
+	return p.oauthSvc.GetStatus(ctx, orgID)
+}
+
+// Disconnect removes the Intercom connection.
+func (p *Provider) Disconnect(ctx context.Context, orgID uuid.UUID) error {
+	return p.oauthSvc.Disconnect(ctx, orgID)
+}
+
+// CheckHealth lists one page of contacts to verify the access token works.
+func (p *Provider) CheckHealth(ctx context.Context, orgID uuid.UUID) error {
+	accessToken, err := p.oauthSvc.GetAccessToken(ctx, orgID)
+	if errors.Is(err, integration.ErrReauthRequired) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", integration.ErrCredentials, err)
+	}
+
+	return p.client.CheckAccess(ctx, accessToken)
+}
+
+// RunFullSync runs a full Intercom sync.
+func (p *Provider) RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) {
+	p.orchestrator.RunFullSync(ctx, orgID, trigger)
+}
+
+// RunIncrementalSync runs an incremental Intercom sync.
+func (p *Provider) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) {
+	p.orchestrator.RunIncrementalSync(ctx, orgID, since, trigger)
+}
+
+// Reconcile compares local contacts with Intercom.
+func (p *Provider) Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error) {
+	return p.orchestrator.Reconcile(ctx, orgID, heal)
+}
+
+// HandleWebhook verifies an Intercom webhook and stores it in the webhook inbox.
+func (p *Provider) HandleWebhook(ctx context.Context, req integration.WebhookRequest) error {
+	if err := p.webhookSvc.VerifySignature(req.Body, req.Header.Get("X-Hub-Signature")); err != nil {
+		return fmt.Errorf("%w: %v", integration.ErrInvalidSignature, err)
+	}
+
+	var event service.IntercomWebhookEvent
+	if err := json.Unmarshal(req.Body, &event); err != nil {
+		return fmt.Errorf("%w: %v", integration.ErrInvalidPayload, err)
+	}
+
+	return p.webhookSvc.ProcessEvent(ctx, event, req.Body)
+}
+
+// ProcessWebhook processes a stored Intercom webhook event.
+func (p *Provider) ProcessWebhook(ctx context.Context, payload []byte) error {
+	return p.webhookSvc.ProcessPayload(ctx, payload)
+}
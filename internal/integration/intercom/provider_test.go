@@ -0,0 +1,54 @@
+package intercom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type fakeWebhookService struct {
+	webhookService
+	verifyErr error
+	events    []service.IntercomWebhookEvent
+}
+
+func (f *fakeWebhookService) VerifySignature(requestBody []byte, signatureHeader string) error {
+	return f.verifyErr
+}
+
+func (f *fakeWebhookService) ProcessEvent(ctx context.Context, event service.IntercomWebhookEvent, payload []byte) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+func TestHandleWebhook(t *testing.T) {
+	req := integration.WebhookRequest{
+		Method: http.MethodPost,
+		Header: http.Header{"X-Hub-Signature": {"sha1=sig"}},
+		Body:   []byte(`{"type":"notification_event","id":"notif_1","topic":"contact.user.created","app_id":"abc"}`),
+	}
+
+	webhooks := &fakeWebhookService{}
+	p := &Provider{webhookSvc: webhooks}
+	if err := p.HandleWebhook(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(webhooks.events) != 1 || webhooks.events[0].Topic != "contact.user.created" {
+		t.Errorf("expected the event to be queued, got %+v", webhooks.events)
+	}
+
+	p = &Provider{webhookSvc: &fakeWebhookService{verifyErr: errors.New("signature mismatch")}}
+	if err := p.HandleWebhook(context.Background(), req); !errors.Is(err, integration.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	req.Body = []byte(`not json`)
+	p = &Provider{webhookSvc: &fakeWebhookService{}}
+	if err := p.HandleWebhook(context.Background(), req); !errors.Is(err, integration.ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload, got %v", err)
+	}
+}
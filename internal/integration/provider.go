@@ -0,0 +1,121 @@
+// Package integration defines the contract every third-party connector
+// implements and the registry the rest of the app discovers connectors from.
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidSignature is returned by HandleWebhook when a request fails
+	// signature verification.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrInvalidPayload is returned by HandleWebhook when a verified request
+	// body cannot be decoded.
+	ErrInvalidPayload = errors.New("invalid webhook payload")
+	// ErrCredentials is returned by CheckHealth when the stored credentials
+	// cannot be loaded, as opposed to the provider API call failing.
+	ErrCredentials = errors.New("integration credentials unavailable")
+	// ErrReauthRequired is returned by RefreshToken and CheckHealth when the
+	// provider has revoked the grant and the user must connect again.
+	ErrReauthRequired = errors.New("integration requires reauthorization")
+)
+
+// WebhookRequest is an inbound webhook delivery as received over HTTP.
+type WebhookRequest struct {
+	Method     string
+	RequestURI string
+	Header     http.Header
+	Body       []byte
+}
+
+// Provider is a third-party connector. Name is the key used in routes and in
+// integration_connections.provider.
+type Provider interface {
+	Name() string
+	DisplayName() string
+
+	// ConnectURL returns the OAuth authorization URL for an org.
+	ConnectURL(orgID uuid.UUID) (string, error)
+	// ExchangeCode completes the OAuth flow and stores the connection.
+	ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error
+	// RefreshToken renews the stored access token. Providers whose tokens do
+	// not expire return nil.
+	RefreshToken(ctx context.Context, orgID uuid.UUID) error
+	// Status returns the provider-specific connection status.
+	Status(ctx context.Context, orgID uuid.UUID) (any, error)
+	// Disconnect removes the org's connection.
+	Disconnect(ctx context.Context, orgID uuid.UUID) error
+
+	// CheckHealth makes a lightweight API call to verify the connection works.
+	CheckHealth(ctx context.Context, orgID uuid.UUID) error
+
+	// RunFullSync and RunIncrementalSync run a sync and record it as a sync run.
+	RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string)
+	RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string)
+
+	// HandleWebhook verifies a webhook delivery and queues its events for
+	// processing.
+	HandleWebhook(ctx context.Context, req WebhookRequest) error
+	// ProcessWebhook processes one event queued by HandleWebhook.
+	ProcessWebhook(ctx context.Context, payload []byte) error
+
+	// Reconcile compares the org's local records with the provider API and
+	// reports the drift. With heal set it also repairs what it finds.
+	Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*DriftReport, error)
+}
+
+// Drift kinds.
+const (
+	// DriftMissing is a record the provider has that PulseScore does not.
+	DriftMissing = "missing"
+	// DriftStale is a record whose local copy differs from the provider's.
+	DriftStale = "stale"
+	// DriftOrphaned is a local record the provider no longer has.
+	DriftOrphaned = "orphaned"
+)
+
+// maxDriftItems caps the items kept in a report; the counts stay exact.
+const maxDriftItems = 200
+
+// DriftItem is one record that differs between PulseScore and the provider.
+type DriftItem struct {
+	Resource   string `json:"resource"` // e.g. subscription, customer_mrr, deal, contact
+	ExternalID string `json:"external_id"`
+	Kind       string `json:"kind"` // missing, stale, orphaned
+	Detail     string `json:"detail,omitempty"`
+	Healed     bool   `json:"healed"`
+}
+
+// DriftReport is the outcome of reconciling one org's data with a provider.
+type DriftReport struct {
+	Checked  int         `json:"checked"`
+	Missing  int         `json:"missing"`
+	Stale    int         `json:"stale"`
+	Orphaned int         `json:"orphaned"`
+	Healed   int         `json:"healed"`
+	Items    []DriftItem `json:"items"`
+}
+
+// Add records a drift item, updating the counts.
+func (r *DriftReport) Add(item DriftItem) {
+	switch item.Kind {
+	case DriftMissing:
+		r.Missing++
+	case DriftStale:
+		r.Stale++
+	case DriftOrphaned:
+		r.Orphaned++
+	}
+	if item.Healed {
+		r.Healed++
+	}
+	if len(r.Items) < maxDriftItems {
+		r.Items = append(r.Items, item)
+	}
+}
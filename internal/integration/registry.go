@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry holds the providers available to the app, in registration order.
+type Registry struct {
+	mu        sync.RWMutex
+	providers []Provider
+	byName    map[string]Provider
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]Provider)}
+}
+
+// Register adds a provider. It panics if a provider with the same name is
+// already registered.
+func (r *Registry) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byName[p.Name()]; ok {
+		panic(fmt.Sprintf("integration: provider %q registered twice", p.Name()))
+	}
+	r.byName[p.Name()] = p
+	r.providers = append(r.providers, p)
+}
+
+// Get returns the provider with the given name.
+func (r *Registry) Get(name string) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.byName[name]
+	return p, ok
+}
+
+// All returns every registered provider in registration order.
+func (r *Registry) All() []Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	providers := make([]Provider, len(r.providers))
+	copy(providers, r.providers)
+	return providers
+}
@@ -0,0 +1,160 @@
+// Package stripe registers the Stripe connector with the integration registry.
+package stripe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// oauthService is the part of service.StripeOAuthService the provider uses.
+type oauthService interface {
+	ConnectURL(orgID uuid.UUID) (string, error)
+	ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error
+	GetStatus(ctx context.Context, orgID uuid.UUID) (*service.StripeConnectionStatus, error)
+	Disconnect(ctx context.Context, orgID uuid.UUID) error
+	GetAccessToken(ctx context.Context, orgID uuid.UUID) (string, error)
+}
+
+// apiClient is the part of service.StripeClient the provider uses.
+type apiClient interface {
+	CheckAccess(ctx context.Context, accessToken string) error
+}
+
+// syncOrchestrator is the part of service.SyncOrchestratorService the provider uses.
+type syncOrchestrator interface {
+	RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) *service.SyncResult
+	RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) *service.SyncResult
+	Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error)
+}
+
+// webhookService is the part of service.StripeWebhookService the provider uses.
+type webhookService interface {
+	HandleEvent(ctx context.Context, payload []byte, sigHeader string) error
+	ProcessPayload(ctx context.Context, payload []byte) error
+	ReleaseQuarantined(ctx context.Context, orgID uuid.UUID) error
+	ForgetOrg(orgID uuid.UUID)
+}
+
+// Provider exposes the Stripe connector through the integration.Provider interface.
+type Provider struct {
+	oauthSvc     oauthService
+	client       apiClient
+	orchestrator syncOrchestrator
+	webhookSvc   webhookService
+}
+
+// NewProvider creates a new Provider.
+func NewProvider(
+	oauthSvc *service.StripeOAuthService,
+	client *service.StripeClient,
+	orchestrator *service.SyncOrchestratorService,
+	webhookSvc *service.StripeWebhookService,
+) *Provider {
+	return &Provider{
+		oauthSvc:     oauthSvc,
+		client:       client,
+		orchestrator: orchestrator,
+		webhookSvc:   webhookSvc,
+	}
+}
+
+var _ integration.Provider = (*Provider)(nil)
+
+// Name returns "stripe".
+func (p *Provider) Name() string { return "stripe" }
+
+// DisplayName returns "Stripe".
+func (p *Provider) DisplayName() string { return "Stripe" }
+
+// ConnectURL returns the Stripe Connect authorization URL.
+func (p *Provider) ConnectURL(orgID uuid.UUID) (string, error) {
+	return p.oauthSvc.ConnectURL(orgID)
+}
+
+// ExchangeCode completes the Stripe Connect OAuth flow.
+func (p *Provider) ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error {
+	if err := p.oauthSvc.ExchangeCode(ctx, orgID, code, state); err != nil {
+		return err
+	}
+	if err := p.webhookSvc.ReleaseQuarantined(ctx, orgID); err != nil {
+		slog.Error("stripe: failed to release quarantined webhooks", "org_id", orgID, "error", err)
+	}
+	return nil
+}
+
+// RefreshToken is a no-op: Stripe Connect access tokens do not expire.
+func (p *Provider) RefreshToken(ctx context.Context, orgID uuid.UUID) error {
+	return nil
+}
+
+// Status returns the Stripe connection status.
+func (p *Provider) Status(ctx context.Context, orgID uuid.UUID) (any, error) {
+	return p.oauthSvc.GetStatus(ctx, orgID)
+}
+
+// Disconnect removes the Stripe connection.
+func (p *Provider) Disconnect(ctx context.Context, orgID uuid.UUID) error {
+	if err := p.oauthSvc.Disconnect(ctx, orgID); err != nil {
+		return err
+	}
+	p.webhookSvc.ForgetOrg(orgID)
+	return nil
+}
+
+// CheckHealth lists one customer to verify the access token works.
+func (p *Provider) CheckHealth(ctx context.Context, orgID uuid.UUID) error {
+	accessToken, err := p.oauthSvc.GetAccessToken(ctx, orgID)
+	if errors.Is(err, integration.ErrReauthRequired) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", integration.ErrCredentials, err)
+	}
+
+	return p.client.CheckAccess(ctx, accessToken)
+}
+
+// RunFullSync runs a full Stripe sync.
+func (p *Provider) RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) {
+	p.orchestrator.RunFullSync(ctx, orgID, trigger)
+}
+
+// RunIncrementalSync runs an incremental Stripe sync.
+func (p *Provider) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) {
+	p.orchestrator.RunIncrementalSync(ctx, orgID, since, trigger)
+}
+
+// Reconcile compares local subscriptions and MRR with Stripe.
+func (p *Provider) Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error) {
+	return p.orchestrator.Reconcile(ctx, orgID, heal)
+}
+
+// HandleWebhook verifies a Stripe webhook and stores it in the webhook inbox.
+func (p *Provider) HandleWebhook(ctx context.Context, req integration.WebhookRequest) error {
+	sigHeader := req.Header.Get("Stripe-Signature")
+	if sigHeader == "" {
+		return fmt.Errorf("%w: missing Stripe-Signature header", integration.ErrInvalidSignature)
+	}
+
+	if err := p.webhookSvc.HandleEvent(ctx, req.Body, sigHeader); err != nil {
+		var valErr *service.ValidationError
+		if errors.As(err, &valErr) {
+			return fmt.Errorf("%w: %s", integration.ErrInvalidSignature, valErr.Message)
+		}
+		return err
+	}
+	return nil
+}
+
+// ProcessWebhook processes a stored Stripe webhook event.
+func (p *Provider) ProcessWebhook(ctx context.Context, payload []byte) error {
+	return p.webhookSvc.ProcessPayload(ctx, payload)
+}
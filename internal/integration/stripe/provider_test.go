@@ -0,0 +1,98 @@
+package stripe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+type fakeOAuthService struct {
+	oauthService
+	tokenErr error
+}
+
+func (f *fakeOAuthService) ExchangeCode(ctx context.Context, orgID uuid.UUID, code, state string) error {
+	return nil
+}
+
+func (f *fakeOAuthService) GetAccessToken(ctx context.Context, orgID uuid.UUID) (string, error) {
+	return "sk_test", f.tokenErr
+}
+
+type fakeAPIClient struct {
+	err error
+}
+
+func (f *fakeAPIClient) CheckAccess(ctx context.Context, accessToken string) error {
+	return f.err
+}
+
+type fakeWebhookService struct {
+	webhookService
+	handleErr error
+	released  []uuid.UUID
+}
+
+func (f *fakeWebhookService) HandleEvent(ctx context.Context, payload []byte, sigHeader string) error {
+	return f.handleErr
+}
+
+func (f *fakeWebhookService) ReleaseQuarantined(ctx context.Context, orgID uuid.UUID) error {
+	f.released = append(f.released, orgID)
+	return nil
+}
+
+func TestExchangeCode_ReleasesQuarantinedWebhooks(t *testing.T) {
+	webhooks := &fakeWebhookService{}
+	p := &Provider{oauthSvc: &fakeOAuthService{}, webhookSvc: webhooks}
+
+	orgID := uuid.New()
+	if err := p.ExchangeCode(context.Background(), orgID, "code", "state"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(webhooks.released) != 1 || webhooks.released[0] != orgID {
+		t.Errorf("expected the org's quarantined webhooks to be released, got %v", webhooks.released)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	apiErr := errors.New("stripe unavailable")
+	tests := []struct {
+		name     string
+		tokenErr error
+		apiErr   error
+		want     error
+	}{
+		{"healthy", nil, nil, nil},
+		{"token unavailable", errors.New("decrypt failed"), nil, integration.ErrCredentials},
+		{"token revoked", integration.ErrReauthRequired, nil, integration.ErrReauthRequired},
+		{"api error", nil, apiErr, apiErr},
+	}
+	for _, tt := range tests {
+		p := &Provider{oauthSvc: &fakeOAuthService{tokenErr: tt.tokenErr}, client: &fakeAPIClient{err: tt.apiErr}}
+		err := p.CheckHealth(context.Background(), uuid.New())
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestHandleWebhook_InvalidSignature(t *testing.T) {
+	p := &Provider{webhookSvc: &fakeWebhookService{handleErr: &service.ValidationError{Field: "signature", Message: "invalid webhook signature"}}}
+
+	err := p.HandleWebhook(context.Background(), integration.WebhookRequest{Header: http.Header{}})
+	if !errors.Is(err, integration.ErrInvalidSignature) {
+		t.Errorf("expected a missing header to be ErrInvalidSignature, got %v", err)
+	}
+
+	err = p.HandleWebhook(context.Background(), integration.WebhookRequest{Header: http.Header{"Stripe-Signature": {"t=1,v1=bad"}}})
+	if !errors.Is(err, integration.ErrInvalidSignature) {
+		t.Errorf("expected a rejected signature to be ErrInvalidSignature, got %v", err)
+	}
+}
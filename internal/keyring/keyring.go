@@ -0,0 +1,270 @@
+// Package keyring implements envelope encryption with versioned keys.
+//
+// Each value is sealed with its own random data key, and the data key is
+// wrapped with a key-encryption key from the keyring. The ciphertext records
+// the ID of the wrapping key, so several keys can be active at once: new values
+// are always wrapped with the primary key while older ones stay readable until
+// they are rotated.
+//
+// Envelope layout:
+//
+//	magic (4) | key ID length (1) | key ID | wrap nonce (12) | wrapped data key (48) | data nonce (12) | sealed value
+package keyring
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	keySize      = 32
+	nonceSize    = 12
+	wrappedSize  = keySize + 16 // GCM tag
+	maxKeyIDSize = 255
+)
+
+// magic prefixes every envelope. The trailing 0x01 is the format version and
+// never appears at the start of a plaintext token.
+var magic = []byte{'P', 'S', 'K', 0x01}
+
+var (
+	// ErrUnknownKey is returned when a ciphertext was wrapped with a key that
+	// is not in the keyring.
+	ErrUnknownKey = errors.New("keyring: unknown key id")
+	// ErrMalformed is returned for ciphertext that is not a valid envelope.
+	ErrMalformed = errors.New("keyring: malformed envelope")
+)
+
+// Keyring holds key-encryption keys by ID.
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	primary string
+}
+
+// Parse builds a keyring from "id:hex" specs. primary names the key new values
+// are wrapped with; it may be empty when there is exactly one key.
+func Parse(specs []string, primary string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(specs))}
+
+	for _, spec := range specs {
+		id, keyHex, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("keyring: key %q must be in id:hex form", spec)
+		}
+		if len(id) > maxKeyIDSize {
+			return nil, fmt.Errorf("keyring: key id %q is too long", id)
+		}
+		if _, dup := k.keys[id]; dup {
+			return nil, fmt.Errorf("keyring: duplicate key id %q", id)
+		}
+
+		raw, err := hex.DecodeString(keyHex)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: decode key %q: %w", id, err)
+		}
+		if len(raw) != keySize {
+			return nil, fmt.Errorf("keyring: key %q must be %d bytes, got %d", id, keySize, len(raw))
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+
+	if len(k.keys) == 0 {
+		return nil, errors.New("keyring: no keys configured")
+	}
+
+	if primary == "" {
+		if len(k.keys) > 1 {
+			return nil, errors.New("keyring: primary key id is required when several keys are configured")
+		}
+		for id := range k.keys {
+			primary = id
+		}
+	}
+	if _, ok := k.keys[primary]; !ok {
+		return nil, fmt.Errorf("keyring: primary key %q is not configured", primary)
+	}
+	k.primary = primary
+
+	return k, nil
+}
+
+// Primary returns the ID of the key new values are wrapped with.
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// KeyIDs returns the configured key IDs in sorted order.
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Encrypt seals plaintext under a fresh data key wrapped with the primary key.
+func (k *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("keyring: generate data key: %w", err)
+	}
+
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	dataNonce := make([]byte, nonceSize)
+	if _, err := rand.Read(dataNonce); err != nil {
+		return nil, fmt.Errorf("keyring: generate nonce: %w", err)
+	}
+
+	out, err := k.wrap(k.primary, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, dataNonce...)
+	return data.Seal(out, dataNonce, plaintext, nil), nil
+}
+
+// Decrypt opens an envelope produced by Encrypt with any key in the keyring.
+func (k *Keyring) Decrypt(ciphertext []byte) ([]byte, error) {
+	env, err := parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := k.unwrap(env)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := data.Open(nil, env.dataNonce, env.sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("keyring: decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap re-wraps an envelope's data key with the primary key. The sealed
+// value is carried over unchanged, so rotation never handles plaintext.
+func (k *Keyring) Rewrap(ciphertext []byte) ([]byte, error) {
+	env, err := parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if env.keyID == k.primary {
+		return ciphertext, nil
+	}
+
+	dataKey, err := k.unwrap(env)
+	if err != nil {
+		return nil, err
+	}
+	out, err := k.wrap(k.primary, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, env.dataNonce...)
+	return append(out, env.sealed...), nil
+}
+
+// KeyID returns the ID of the key an envelope was wrapped with. ok is false
+// when ciphertext is not an envelope.
+func KeyID(ciphertext []byte) (id string, ok bool) {
+	env, err := parse(ciphertext)
+	if err != nil {
+		return "", false
+	}
+	return env.keyID, true
+}
+
+// IsEnvelope reports whether ciphertext carries the envelope header.
+func IsEnvelope(ciphertext []byte) bool {
+	return bytes.HasPrefix(ciphertext, magic)
+}
+
+type envelope struct {
+	keyID      string
+	wrapNonce  []byte
+	wrappedKey []byte
+	dataNonce  []byte
+	sealed     []byte
+}
+
+func parse(ciphertext []byte) (*envelope, error) {
+	if !IsEnvelope(ciphertext) {
+		return nil, ErrMalformed
+	}
+	rest := ciphertext[len(magic):]
+	if len(rest) < 1 {
+		return nil, ErrMalformed
+	}
+	idLen := int(rest[0])
+	rest = rest[1:]
+	if idLen == 0 || len(rest) < idLen+nonceSize+wrappedSize+nonceSize {
+		return nil, ErrMalformed
+	}
+
+	env := &envelope{keyID: string(rest[:idLen])}
+	rest = rest[idLen:]
+	env.wrapNonce, rest = rest[:nonceSize], rest[nonceSize:]
+	env.wrappedKey, rest = rest[:wrappedSize], rest[wrappedSize:]
+	env.dataNonce, env.sealed = rest[:nonceSize], rest[nonceSize:]
+	return env, nil
+}
+
+// wrap returns the envelope header for dataKey wrapped with key id. The key ID
+// is bound to the wrapped key as additional data.
+func (k *Keyring) wrap(id string, dataKey []byte) ([]byte, error) {
+	wrapNonce := make([]byte, nonceSize)
+	if _, err := rand.Read(wrapNonce); err != nil {
+		return nil, fmt.Errorf("keyring: generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(magic)+1+len(id)+nonceSize+wrappedSize+nonceSize)
+	out = append(out, magic...)
+	out = append(out, byte(len(id)))
+	out = append(out, id...)
+	out = append(out, wrapNonce...)
+	return k.keys[id].Seal(out, wrapNonce, dataKey, []byte(id)), nil
+}
+
+func (k *Keyring) unwrap(env *envelope) ([]byte, error) {
+	kek, ok := k.keys[env.keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, env.keyID)
+	}
+	dataKey, err := kek.Open(nil, env.wrapNonce, env.wrappedKey, []byte(env.keyID))
+	if err != nil {
+		return nil, fmt.Errorf("keyring: unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,133 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	testKeyV1 = "v1:0000000000000000000000000000000000000000000000000000000000000001"
+	testKeyV2 = "v2:0000000000000000000000000000000000000000000000000000000000000002"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	k, err := Parse([]string{testKeyV1}, "")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if k.Primary() != "v1" {
+		t.Fatalf("expected single key to be primary, got %s", k.Primary())
+	}
+
+	ct, err := k.Encrypt([]byte("secret-token"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if id, ok := KeyID(ct); !ok || id != "v1" {
+		t.Fatalf("expected key id v1, got %q (envelope=%v)", id, ok)
+	}
+
+	pt, err := k.Decrypt(ct)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if string(pt) != "secret-token" {
+		t.Fatalf("expected secret-token, got %q", pt)
+	}
+}
+
+func TestRotationAcrossKeys(t *testing.T) {
+	old, err := Parse([]string{testKeyV1}, "v1")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ct, err := old.Encrypt([]byte("secret-token"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	// Both keys active, v2 primary: old ciphertext stays readable
+	both, err := Parse([]string{testKeyV1, testKeyV2}, "v2")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if pt, err := both.Decrypt(ct); err != nil || string(pt) != "secret-token" {
+		t.Fatalf("expected old ciphertext to decrypt, got %q, %v", pt, err)
+	}
+
+	rewrapped, err := both.Rewrap(ct)
+	if err != nil {
+		t.Fatalf("rewrap: %v", err)
+	}
+	if id, _ := KeyID(rewrapped); id != "v2" {
+		t.Fatalf("expected rewrapped key id v2, got %q", id)
+	}
+
+	// After v1 is retired only the rewrapped value can be read
+	onlyNew, err := Parse([]string{testKeyV2}, "")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if pt, err := onlyNew.Decrypt(rewrapped); err != nil || string(pt) != "secret-token" {
+		t.Fatalf("expected rewrapped ciphertext to decrypt, got %q, %v", pt, err)
+	}
+	if _, err := onlyNew.Decrypt(ct); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey for retired key, got %v", err)
+	}
+}
+
+func TestRewrapPrimaryIsNoop(t *testing.T) {
+	k, _ := Parse([]string{testKeyV1}, "")
+	ct, _ := k.Encrypt([]byte("x"))
+
+	out, err := k.Rewrap(ct)
+	if err != nil {
+		t.Fatalf("rewrap: %v", err)
+	}
+	if !bytes.Equal(out, ct) {
+		t.Fatal("expected ciphertext under the primary key to be returned unchanged")
+	}
+}
+
+func TestTamperedKeyIDRejected(t *testing.T) {
+	k, _ := Parse([]string{testKeyV1, strings.Replace(testKeyV2, "v2", "v9", 1)}, "v1")
+	ct, _ := k.Encrypt([]byte("x"))
+
+	// Relabel the envelope as wrapped by v9; the key ID is authenticated
+	tampered := bytes.Replace(ct, []byte("v1"), []byte("v9"), 1)
+	if _, err := k.Decrypt(tampered); err == nil {
+		t.Fatal("expected relabelled envelope to fail")
+	}
+}
+
+func TestLegacyCiphertextIsNotEnvelope(t *testing.T) {
+	if IsEnvelope([]byte("sk_test_plaintext")) {
+		t.Fatal("plaintext token must not look like an envelope")
+	}
+	if _, ok := KeyID([]byte{0x01, 0x02, 0x03}); ok {
+		t.Fatal("short ciphertext must not parse as an envelope")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]struct {
+		specs   []string
+		primary string
+	}{
+		"no keys":         {nil, ""},
+		"missing id":      {[]string{":00"}, ""},
+		"bad hex":         {[]string{"v1:zz"}, ""},
+		"short key":       {[]string{"v1:0011"}, ""},
+		"duplicate":       {[]string{testKeyV1, testKeyV1}, "v1"},
+		"ambiguous":       {[]string{testKeyV1, testKeyV2}, ""},
+		"unknown primary": {[]string{testKeyV1}, "v3"},
+		"no separator":    {[]string{"v1"}, ""},
+	}
+	for name, tc := range cases {
+		if _, err := Parse(tc.specs, tc.primary); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
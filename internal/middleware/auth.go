@@ -9,21 +9,37 @@ import (
 
 // JWTAuth returns middleware that validates JWT tokens from the Authorization header.
 func JWTAuth(jwtMgr *auth.JWTManager) func(http.Handler) http.Handler {
+	return jwtAuth(jwtMgr, false)
+}
+
+// StreamJWTAuth is JWTAuth for long-lived streaming endpoints. Browsers cannot set
+// headers on an EventSource, so the token may also be passed as ?access_token=,
+// which Logger masks.
+func StreamJWTAuth(jwtMgr *auth.JWTManager) func(http.Handler) http.Handler {
+	return jwtAuth(jwtMgr, true)
+}
+
+func jwtAuth(jwtMgr *auth.JWTManager, allowQueryToken bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var token string
 			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
+			switch {
+			case authHeader != "":
+				parts := strings.SplitN(authHeader, " ", 2)
+				if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+					http.Error(w, `{"error":"invalid authorization header format"}`, http.StatusUnauthorized)
+					return
+				}
+				token = parts[1]
+			case allowQueryToken && r.URL.Query().Get("access_token") != "":
+				token = r.URL.Query().Get("access_token")
+			default:
 				http.Error(w, `{"error":"missing authorization header"}`, http.StatusUnauthorized)
 				return
 			}
 
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-				http.Error(w, `{"error":"invalid authorization header format"}`, http.StatusUnauthorized)
-				return
-			}
-
-			claims, err := jwtMgr.ValidateToken(parts[1])
+			claims, err := jwtMgr.ValidateToken(token)
 			if err != nil {
 				http.Error(w, `{"error":"invalid or expired token"}`, http.StatusUnauthorized)
 				return
@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/onnwee/pulse-score/internal/auth"
+	"github.com/onnwee/pulse-score/internal/integration"
 	billing "github.com/onnwee/pulse-score/internal/service/billing"
 )
 
-// RequireIntegrationLimit enforces integration connection limits for the current org.
-func RequireIntegrationLimit(limitsSvc *billing.LimitsService, provider string) func(http.Handler) http.Handler {
+// RequireIntegrationLimit enforces integration connection limits for the current
+// org on routes with a {provider} URL parameter. Unknown providers get a 404.
+func RequireIntegrationLimit(limitsSvc *billing.LimitsService, providers *integration.Registry) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			orgID, ok := auth.GetOrgID(r.Context())
@@ -18,6 +22,12 @@ func RequireIntegrationLimit(limitsSvc *billing.LimitsService, provider string)
 				return
 			}
 
+			provider := chi.URLParam(r, "provider")
+			if _, ok := providers.Get(provider); !ok {
+				writeFeatureGateJSON(w, http.StatusNotFound, map[string]string{"error": "unknown integration provider"})
+				return
+			}
+
 			decision, err := limitsSvc.CheckIntegrationLimit(r.Context(), orgID, provider)
 			if err != nil {
 				writeFeatureGateJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
@@ -74,6 +84,38 @@ func RequireCustomerLimit(limitsSvc *billing.LimitsService) func(http.Handler) h
 	}
 }
 
+// RequireFeature blocks routes of a plan feature, such as "playbooks", for
+// orgs whose plan does not include it.
+func RequireFeature(limitsSvc *billing.LimitsService, feature string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			orgID, ok := auth.GetOrgID(r.Context())
+			if !ok {
+				writeFeatureGateJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+
+			decision, err := limitsSvc.CanAccess(r.Context(), orgID, feature)
+			if err != nil {
+				writeFeatureGateJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				return
+			}
+
+			if !decision.Allowed {
+				writeFeatureGateJSON(w, http.StatusPaymentRequired, map[string]any{
+					"error":                    "feature not available on current plan",
+					"current_plan":             decision.CurrentPlan,
+					"feature":                  decision.Feature,
+					"recommended_upgrade_tier": decision.RecommendedUpgradeTier,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func writeFeatureGateJSON(w http.ResponseWriter, status int, body any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// redactedQueryParams are query parameters that carry credentials, such as
+// the token StreamJWTAuth accepts. Their values are masked in request logs.
+var redactedQueryParams = map[string]bool{"access_token": true}
+
+var requestLogger = newRequestLogger(log.New(os.Stdout, "", log.LstdFlags), runtime.GOOS == "windows")
+
+// Logger logs each request like chi's Logger, with credentials in the query
+// string masked.
+func Logger(next http.Handler) http.Handler {
+	return requestLogger(next)
+}
+
+func newRequestLogger(logger chimw.LoggerInterface, noColor bool) func(http.Handler) http.Handler {
+	return chimw.RequestLogger(&redactingLogFormatter{
+		next: &chimw.DefaultLogFormatter{Logger: logger, NoColor: noColor},
+	})
+}
+
+// redactingLogFormatter hands the wrapped formatter a copy of the request
+// whose RequestURI is redacted. The request served is unchanged.
+type redactingLogFormatter struct {
+	next chimw.LogFormatter
+}
+
+func (f *redactingLogFormatter) NewLogEntry(r *http.Request) chimw.LogEntry {
+	if uri := redactRequestURI(r.RequestURI); uri != r.RequestURI {
+		r = r.WithContext(r.Context())
+		r.RequestURI = uri
+	}
+	return f.next.NewLogEntry(r)
+}
+
+// redactRequestURI replaces the values of redactedQueryParams in a request
+// URI with REDACTED, leaving the rest of it as sent.
+func redactRequestURI(uri string) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok {
+		return uri
+	}
+
+	params := strings.Split(query, "&")
+	for i, p := range params {
+		key, _, _ := strings.Cut(p, "=")
+		if k, err := url.QueryUnescape(key); err == nil && redactedQueryParams[k] {
+			params[i] = key + "=REDACTED"
+		}
+	}
+	return path + "?" + strings.Join(params, "&")
+}
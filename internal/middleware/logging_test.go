@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bufferLogger struct {
+	strings.Builder
+}
+
+func (b *bufferLogger) Print(v ...any) {
+	for _, s := range v {
+		b.WriteString(s.(string))
+	}
+}
+
+func TestRedactRequestURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"/api/v1/events", "/api/v1/events"},
+		{"/api/v1/events?access_token=eyJ.abc.def", "/api/v1/events?access_token=REDACTED"},
+		{"/api/v1/events?since=1&access_token=eyJ.abc.def&x=2", "/api/v1/events?since=1&access_token=REDACTED&x=2"},
+		{"/api/v1/events?access%5Ftoken=eyJ", "/api/v1/events?access%5Ftoken=REDACTED"},
+		{"/api/v1/customers?search=access_token", "/api/v1/customers?search=access_token"},
+	}
+	for _, tt := range tests {
+		if got := redactRequestURI(tt.uri); got != tt.want {
+			t.Errorf("redactRequestURI(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestLogger_RedactsAccessToken(t *testing.T) {
+	var logged bufferLogger
+	var seen string
+	handler := newRequestLogger(&logged, true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.URL.Query().Get("access_token")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events/stream?access_token=secret-jwt", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(logged.String(), "secret-jwt") {
+		t.Errorf("expected the token to be redacted, got %q", logged.String())
+	}
+	if !strings.Contains(logged.String(), "access_token=REDACTED") {
+		t.Errorf("expected the redacted parameter in the log, got %q", logged.String())
+	}
+	if seen != "secret-jwt" {
+		t.Errorf("expected the handler to still see the token, got %q", seen)
+	}
+}
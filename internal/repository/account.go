@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Account represents an accounts row: the company a group of customers belong to.
+type Account struct {
+	ID         uuid.UUID `json:"id"`
+	OrgID      uuid.UUID `json:"org_id"`
+	Source     string    `json:"source"` // hubspot, domain, stripe
+	ExternalID string    `json:"external_id"`
+	Name       string    `json:"name"`
+	Domain     string    `json:"domain"`
+	MRRCents   int       `json:"mrr_cents"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AccountWithScore is an account joined with its rolled-up health score.
+type AccountWithScore struct {
+	Account
+	ContactCount int     `json:"contact_count"`
+	OverallScore *int    `json:"overall_score"`
+	RiskLevel    *string `json:"risk_level"`
+}
+
+// AccountContact is a customer linked to an account, with its health score.
+type AccountContact struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email"`
+	Source       string     `json:"source"`
+	MRRCents     int        `json:"mrr_cents"`
+	LastSeenAt   *time.Time `json:"last_seen_at"`
+	OverallScore *int       `json:"overall_score"`
+	RiskLevel    *string    `json:"risk_level"`
+}
+
+// AccountContactScore is one contact's input to an account score rollup.
+type AccountContactScore struct {
+	CustomerID   uuid.UUID
+	MRRCents     int
+	OverallScore int
+	RiskLevel    string
+}
+
+// AccountListParams holds filter/sort/pagination parameters for listing accounts.
+type AccountListParams struct {
+	OrgID   uuid.UUID
+	Page    int
+	PerPage int
+	Sort    string // name, mrr, score, contacts
+	Order   string // asc, desc
+	Risk    string // green, yellow, red
+	Search  string
+	Source  string
+}
+
+// AccountListResult holds paginated account results.
+type AccountListResult struct {
+	Accounts   []AccountWithScore
+	Total      int
+	Page       int
+	PerPage    int
+	TotalPages int
+}
+
+// AccountRepository handles accounts database operations.
+type AccountRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAccountRepository creates a new AccountRepository.
+func NewAccountRepository(pool *pgxpool.Pool) *AccountRepository {
+	return &AccountRepository{pool: pool}
+}
+
+// Upsert creates or updates an account by (org_id, source, external_id).
+func (r *AccountRepository) Upsert(ctx context.Context, a *Account) error {
+	query := `
+		INSERT INTO accounts (org_id, source, external_id, name, domain)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (org_id, source, external_id) DO UPDATE SET
+			name = EXCLUDED.name,
+			domain = EXCLUDED.domain,
+			updated_at = NOW()
+		RETURNING id, mrr_cents, created_at, updated_at`
+
+	if err := r.pool.QueryRow(ctx, query,
+		a.OrgID, a.Source, a.ExternalID, a.Name, a.Domain,
+	).Scan(&a.ID, &a.MRRCents, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		return fmt.Errorf("upsert account: %w", err)
+	}
+	return nil
+}
+
+// AssignCustomers links each customer to the account at the same index and
+// unlinks every other customer in the org.
+func (r *AccountRepository) AssignCustomers(ctx context.Context, orgID uuid.UUID, customerIDs, accountIDs []uuid.UUID) error {
+	if len(customerIDs) != len(accountIDs) {
+		return fmt.Errorf("assign customers: %d customers for %d accounts", len(customerIDs), len(accountIDs))
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		UPDATE customers c SET account_id = m.account_id, updated_at = NOW()
+		FROM unnest($2::uuid[], $3::uuid[]) AS m (customer_id, account_id)
+		WHERE c.id = m.customer_id AND c.org_id = $1 AND c.account_id IS DISTINCT FROM m.account_id`,
+		orgID, customerIDs, accountIDs)
+	if err != nil {
+		return fmt.Errorf("link customers to accounts: %w", err)
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		UPDATE customers SET account_id = NULL, updated_at = NOW()
+		WHERE org_id = $1 AND account_id IS NOT NULL AND NOT (id = ANY($2::uuid[]))`,
+		orgID, customerIDs)
+	if err != nil {
+		return fmt.Errorf("unlink customers from accounts: %w", err)
+	}
+	return nil
+}
+
+// RefreshMRR sets each account's MRR to the sum of its customers' MRR.
+func (r *AccountRepository) RefreshMRR(ctx context.Context, orgID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE accounts a SET mrr_cents = t.mrr_cents, updated_at = NOW()
+		FROM (
+			SELECT a.id, COALESCE(SUM(c.mrr_cents), 0) AS mrr_cents
+			FROM accounts a
+			LEFT JOIN customers c ON c.account_id = a.id AND c.deleted_at IS NULL
+			WHERE a.org_id = $1
+			GROUP BY a.id
+		) t
+		WHERE a.id = t.id AND a.mrr_cents <> t.mrr_cents`, orgID)
+	if err != nil {
+		return fmt.Errorf("refresh account mrr: %w", err)
+	}
+	return nil
+}
+
+// DeleteEmpty deletes an org's accounts that no customer links to anymore.
+// HubSpot company accounts are kept even without contacts.
+func (r *AccountRepository) DeleteEmpty(ctx context.Context, orgID uuid.UUID) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM accounts a
+		WHERE a.org_id = $1 AND a.source <> 'hubspot'
+			AND NOT EXISTS (SELECT 1 FROM customers c WHERE c.account_id = a.id)`, orgID)
+	if err != nil {
+		return 0, fmt.Errorf("delete empty accounts: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// GetByIDAndOrg returns an account by ID within an org.
+func (r *AccountRepository) GetByIDAndOrg(ctx context.Context, id, orgID uuid.UUID) (*Account, error) {
+	a := &Account{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, org_id, source, external_id, name, COALESCE(domain, ''), mrr_cents, created_at, updated_at
+		FROM accounts
+		WHERE id = $1 AND org_id = $2`, id, orgID,
+	).Scan(&a.ID, &a.OrgID, &a.Source, &a.ExternalID, &a.Name, &a.Domain, &a.MRRCents, &a.CreatedAt, &a.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+	return a, nil
+}
+
+// GetIDByCustomer returns the ID of the account a customer is linked to, or
+// nil if it has none.
+func (r *AccountRepository) GetIDByCustomer(ctx context.Context, customerID, orgID uuid.UUID) (*uuid.UUID, error) {
+	var accountID *uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		SELECT account_id FROM customers WHERE id = $1 AND org_id = $2`, customerID, orgID,
+	).Scan(&accountID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get customer account: %w", err)
+	}
+	return accountID, nil
+}
+
+// ListWithScores returns a paginated, filtered, sorted list of accounts with
+// their rolled-up health scores.
+func (r *AccountRepository) ListWithScores(ctx context.Context, params AccountListParams) (*AccountListResult, error) {
+	where := "a.org_id = $1"
+	args := []any{params.OrgID}
+	argIdx := 2
+
+	if params.Risk != "" {
+		where += fmt.Sprintf(" AND hs.risk_level = $%d", argIdx)
+		args = append(args, params.Risk)
+		argIdx++
+	}
+	if params.Search != "" {
+		where += fmt.Sprintf(" AND (a.name ILIKE $%d OR a.domain ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+	if params.Source != "" {
+		where += fmt.Sprintf(" AND a.source = $%d", argIdx)
+		args = append(args, params.Source)
+		argIdx++
+	}
+
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM accounts a LEFT JOIN account_health_scores hs ON a.id = hs.account_id WHERE %s`, where)
+	var total int
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count accounts with scores: %w", err)
+	}
+
+	totalPages := 0
+	if params.PerPage > 0 {
+		totalPages = (total + params.PerPage - 1) / params.PerPage
+	}
+
+	sortColumn := "a.name"
+	sortAllowlist := map[string]string{
+		"name":     "a.name",
+		"mrr":      "a.mrr_cents",
+		"score":    "hs.overall_score",
+		"contacts": "contact_count",
+	}
+	if col, ok := sortAllowlist[params.Sort]; ok {
+		sortColumn = col
+	}
+
+	order := "ASC"
+	if params.Order == "desc" {
+		order = "DESC"
+	}
+
+	dataQuery := fmt.Sprintf(`
+		SELECT a.id, a.org_id, a.source, a.external_id, a.name, COALESCE(a.domain, ''), a.mrr_cents,
+			a.created_at, a.updated_at,
+			(SELECT COUNT(*) FROM customers c WHERE c.account_id = a.id AND c.deleted_at IS NULL) AS contact_count,
+			hs.overall_score, hs.risk_level
+		FROM accounts a
+		LEFT JOIN account_health_scores hs ON a.id = hs.account_id
+		WHERE %s
+		ORDER BY %s %s NULLS LAST
+		LIMIT $%d OFFSET $%d`,
+		where, sortColumn, order, argIdx, argIdx+1)
+
+	offset := (params.Page - 1) * params.PerPage
+	args = append(args, params.PerPage, offset)
+
+	rows, err := r.pool.Query(ctx, dataQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts with scores: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []AccountWithScore
+	for rows.Next() {
+		a := AccountWithScore{}
+		if err := rows.Scan(
+			&a.ID, &a.OrgID, &a.Source, &a.ExternalID, &a.Name, &a.Domain, &a.MRRCents,
+			&a.CreatedAt, &a.UpdatedAt,
+			&a.ContactCount, &a.OverallScore, &a.RiskLevel,
+		); err != nil {
+			return nil, fmt.Errorf("scan account with score: %w", err)
+		}
+		accounts = append(accounts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &AccountListResult{
+		Accounts:   accounts,
+		Total:      total,
+		Page:       params.Page,
+		PerPage:    params.PerPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ListContacts returns the customers linked to an account with their health
+// scores, highest MRR first.
+func (r *AccountRepository) ListContacts(ctx context.Context, accountID, orgID uuid.UUID) ([]*AccountContact, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.id, COALESCE(c.name, ''), COALESCE(c.email, ''), c.source, c.mrr_cents, c.last_seen_at,
+			hs.overall_score, hs.risk_level
+		FROM customers c
+		LEFT JOIN health_scores hs ON c.id = hs.customer_id
+		WHERE c.account_id = $1 AND c.org_id = $2 AND c.deleted_at IS NULL
+		ORDER BY c.mrr_cents DESC, c.name`, accountID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list account contacts: %w", err)
+	}
+	defer rows.Close()
+
+	var contacts []*AccountContact
+	for rows.Next() {
+		c := &AccountContact{}
+		if err := rows.Scan(
+			&c.ID, &c.Name, &c.Email, &c.Source, &c.MRRCents, &c.LastSeenAt,
+			&c.OverallScore, &c.RiskLevel,
+		); err != nil {
+			return nil, fmt.Errorf("scan account contact: %w", err)
+		}
+		contacts = append(contacts, c)
+	}
+	return contacts, rows.Err()
+}
+
+// ListContactScores returns the scored contacts of an org's accounts, keyed
+// by account. If accountID is set, only that account's contacts are returned.
+func (r *AccountRepository) ListContactScores(ctx context.Context, orgID uuid.UUID, accountID *uuid.UUID) (map[uuid.UUID][]AccountContactScore, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.account_id, c.id, c.mrr_cents, hs.overall_score, hs.risk_level
+		FROM customers c
+		JOIN health_scores hs ON c.id = hs.customer_id
+		WHERE c.org_id = $1 AND c.account_id IS NOT NULL AND c.deleted_at IS NULL
+			AND ($2::uuid IS NULL OR c.account_id = $2)`, orgID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("list account contact scores: %w", err)
+	}
+	defer rows.Close()
+
+	scores := map[uuid.UUID][]AccountContactScore{}
+	for rows.Next() {
+		var id uuid.UUID
+		s := AccountContactScore{}
+		if err := rows.Scan(&id, &s.CustomerID, &s.MRRCents, &s.OverallScore, &s.RiskLevel); err != nil {
+			return nil, fmt.Errorf("scan account contact score: %w", err)
+		}
+		scores[id] = append(scores[id], s)
+	}
+	return scores, rows.Err()
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AccountHealthScore represents an account_health_scores row.
+type AccountHealthScore struct {
+	ID                uuid.UUID          `json:"id"`
+	OrgID             uuid.UUID          `json:"org_id"`
+	AccountID         uuid.UUID          `json:"account_id"`
+	OverallScore      int                `json:"overall_score"`
+	RiskLevel         string             `json:"risk_level"`
+	Aggregation       string             `json:"aggregation"`
+	ContactCount      int                `json:"contact_count"`
+	Factors           map[string]float64 `json:"factors"`
+	PreviousRiskLevel string             `json:"previous_risk_level,omitempty"`
+	RiskChangedAt     *time.Time         `json:"risk_changed_at,omitempty"`
+	CalculatedAt      time.Time          `json:"calculated_at"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+// AccountHealthScoreRepository handles account_health_scores database operations.
+type AccountHealthScoreRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAccountHealthScoreRepository creates a new AccountHealthScoreRepository.
+func NewAccountHealthScoreRepository(pool *pgxpool.Pool) *AccountHealthScoreRepository {
+	return &AccountHealthScoreRepository{pool: pool}
+}
+
+// UpsertCurrent creates or updates the current score for an account. When the
+// risk level changes, the old level and the time of the change are recorded
+// on the row.
+func (r *AccountHealthScoreRepository) UpsertCurrent(ctx context.Context, score *AccountHealthScore) error {
+	factorsJSON, err := json.Marshal(score.Factors)
+	if err != nil {
+		return fmt.Errorf("marshal factors: %w", err)
+	}
+
+	query := `
+		INSERT INTO account_health_scores (org_id, account_id, overall_score, risk_level, aggregation,
+			contact_count, factors, calculated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (account_id) DO UPDATE SET
+			overall_score = EXCLUDED.overall_score,
+			risk_level = EXCLUDED.risk_level,
+			aggregation = EXCLUDED.aggregation,
+			contact_count = EXCLUDED.contact_count,
+			factors = EXCLUDED.factors,
+			previous_risk_level = CASE WHEN account_health_scores.risk_level <> EXCLUDED.risk_level
+				THEN account_health_scores.risk_level ELSE account_health_scores.previous_risk_level END,
+			risk_changed_at = CASE WHEN account_health_scores.risk_level <> EXCLUDED.risk_level
+				THEN EXCLUDED.calculated_at ELSE account_health_scores.risk_changed_at END,
+			calculated_at = EXCLUDED.calculated_at
+		RETURNING id, COALESCE(previous_risk_level, ''), risk_changed_at, created_at, updated_at`
+
+	return r.pool.QueryRow(ctx, query,
+		score.OrgID, score.AccountID, score.OverallScore, score.RiskLevel, score.Aggregation,
+		score.ContactCount, factorsJSON, score.CalculatedAt,
+	).Scan(&score.ID, &score.PreviousRiskLevel, &score.RiskChangedAt, &score.CreatedAt, &score.UpdatedAt)
+}
+
+const accountHealthScoreSelect = `
+	SELECT id, org_id, account_id, overall_score, risk_level, aggregation, contact_count, factors,
+		COALESCE(previous_risk_level, ''), risk_changed_at, calculated_at, created_at, updated_at
+	FROM account_health_scores`
+
+func scanAccountHealthScore(row pgx.Row) (*AccountHealthScore, error) {
+	s := &AccountHealthScore{}
+	var factorsJSON []byte
+	if err := row.Scan(
+		&s.ID, &s.OrgID, &s.AccountID, &s.OverallScore, &s.RiskLevel, &s.Aggregation, &s.ContactCount,
+		&factorsJSON, &s.PreviousRiskLevel, &s.RiskChangedAt, &s.CalculatedAt, &s.CreatedAt, &s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(factorsJSON, &s.Factors); err != nil {
+		return nil, fmt.Errorf("unmarshal factors: %w", err)
+	}
+	return s, nil
+}
+
+// GetByAccountID retrieves the current score for an account.
+func (r *AccountHealthScoreRepository) GetByAccountID(ctx context.Context, accountID, orgID uuid.UUID) (*AccountHealthScore, error) {
+	s, err := scanAccountHealthScore(r.pool.QueryRow(ctx, accountHealthScoreSelect+`
+		WHERE account_id = $1 AND org_id = $2`, accountID, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get account health score: %w", err)
+	}
+	return s, nil
+}
+
+// ListByOrg retrieves the current scores of an org's accounts, lowest first.
+func (r *AccountHealthScoreRepository) ListByOrg(ctx context.Context, orgID uuid.UUID, limit int) ([]*AccountHealthScore, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := r.pool.Query(ctx, accountHealthScoreSelect+`
+		WHERE org_id = $1
+		ORDER BY overall_score ASC
+		LIMIT $2`, orgID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list account health scores: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []*AccountHealthScore
+	for rows.Next() {
+		s, err := scanAccountHealthScore(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan account health score: %w", err)
+		}
+		scores = append(scores, s)
+	}
+	return scores, rows.Err()
+}
+
+// DeleteStale deletes the scores of an org's accounts that were not
+// recalculated at or after calculatedAt, i.e. accounts left with no scored
+// contacts.
+func (r *AccountHealthScoreRepository) DeleteStale(ctx context.Context, orgID uuid.UUID, calculatedAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM account_health_scores WHERE org_id = $1 AND calculated_at < $2`, orgID, calculatedAt)
+	if err != nil {
+		return fmt.Errorf("delete stale account health scores: %w", err)
+	}
+	return nil
+}
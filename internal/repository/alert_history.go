@@ -16,6 +16,7 @@ type AlertHistory struct {
 	OrgID            uuid.UUID      `json:"org_id"`
 	AlertRuleID      uuid.UUID      `json:"alert_rule_id"`
 	CustomerID       *uuid.UUID     `json:"customer_id,omitempty"`
+	AccountID        *uuid.UUID     `json:"account_id,omitempty"`
 	TriggerData      map[string]any `json:"trigger_data"`
 	Channel          string         `json:"channel"`
 	Status           string         `json:"status"` // sent, failed, pending
@@ -42,10 +43,10 @@ func NewAlertHistoryRepository(pool *pgxpool.Pool) *AlertHistoryRepository {
 // Create inserts a new alert history record.
 func (r *AlertHistoryRepository) Create(ctx context.Context, h *AlertHistory) error {
 	return r.pool.QueryRow(ctx, `
-		INSERT INTO alert_history (org_id, alert_rule_id, customer_id, trigger_data, channel, status, sent_at, error_message, sendgrid_message_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO alert_history (org_id, alert_rule_id, customer_id, account_id, trigger_data, channel, status, sent_at, error_message, sendgrid_message_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at
-	`, h.OrgID, h.AlertRuleID, h.CustomerID, h.TriggerData, h.Channel,
+	`, h.OrgID, h.AlertRuleID, h.CustomerID, h.AccountID, h.TriggerData, h.Channel,
 		h.Status, h.SentAt, h.ErrorMessage, h.SendGridMsgID,
 	).Scan(&h.ID, &h.CreatedAt)
 }
@@ -129,7 +130,7 @@ func (r *AlertHistoryRepository) ListByOrg(ctx context.Context, orgID uuid.UUID,
 
 	// Data
 	dataQuery := fmt.Sprintf(`
-		SELECT id, org_id, alert_rule_id, customer_id, trigger_data, channel, status, sent_at, error_message,
+		SELECT id, org_id, alert_rule_id, customer_id, account_id, trigger_data, channel, status, sent_at, error_message,
 			COALESCE(sendgrid_message_id, ''), delivered_at, opened_at, clicked_at, bounced_at, created_at
 		FROM alert_history
 		WHERE %s
@@ -148,7 +149,7 @@ func (r *AlertHistoryRepository) ListByOrg(ctx context.Context, orgID uuid.UUID,
 	for rows.Next() {
 		h := &AlertHistory{}
 		if err := rows.Scan(
-			&h.ID, &h.OrgID, &h.AlertRuleID, &h.CustomerID, &h.TriggerData,
+			&h.ID, &h.OrgID, &h.AlertRuleID, &h.CustomerID, &h.AccountID, &h.TriggerData,
 			&h.Channel, &h.Status, &h.SentAt, &h.ErrorMessage,
 			&h.SendGridMsgID, &h.DeliveredAt, &h.OpenedAt, &h.ClickedAt, &h.BouncedAt,
 			&h.CreatedAt,
@@ -163,7 +164,7 @@ func (r *AlertHistoryRepository) ListByOrg(ctx context.Context, orgID uuid.UUID,
 // ListByRule returns alert history records for a specific rule.
 func (r *AlertHistoryRepository) ListByRule(ctx context.Context, ruleID uuid.UUID, limit, offset int) ([]*AlertHistory, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, org_id, alert_rule_id, customer_id, trigger_data, channel, status, sent_at, error_message,
+		SELECT id, org_id, alert_rule_id, customer_id, account_id, trigger_data, channel, status, sent_at, error_message,
 			COALESCE(sendgrid_message_id, ''), delivered_at, opened_at, clicked_at, bounced_at, created_at
 		FROM alert_history
 		WHERE alert_rule_id = $1
@@ -179,7 +180,7 @@ func (r *AlertHistoryRepository) ListByRule(ctx context.Context, ruleID uuid.UUI
 	for rows.Next() {
 		h := &AlertHistory{}
 		if err := rows.Scan(
-			&h.ID, &h.OrgID, &h.AlertRuleID, &h.CustomerID, &h.TriggerData,
+			&h.ID, &h.OrgID, &h.AlertRuleID, &h.CustomerID, &h.AccountID, &h.TriggerData,
 			&h.Channel, &h.Status, &h.SentAt, &h.ErrorMessage,
 			&h.SendGridMsgID, &h.DeliveredAt, &h.OpenedAt, &h.ClickedAt, &h.BouncedAt,
 			&h.CreatedAt,
@@ -191,18 +192,41 @@ func (r *AlertHistoryRepository) ListByRule(ctx context.Context, ruleID uuid.UUI
 	return items, rows.Err()
 }
 
+// GetByID returns an alert history record by ID and org.
+func (r *AlertHistoryRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*AlertHistory, error) {
+	h := &AlertHistory{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, org_id, alert_rule_id, customer_id, account_id, trigger_data, channel, status, sent_at, error_message,
+			COALESCE(sendgrid_message_id, ''), delivered_at, opened_at, clicked_at, bounced_at, created_at
+		FROM alert_history
+		WHERE id = $1 AND org_id = $2
+	`, id, orgID).Scan(
+		&h.ID, &h.OrgID, &h.AlertRuleID, &h.CustomerID, &h.AccountID, &h.TriggerData,
+		&h.Channel, &h.Status, &h.SentAt, &h.ErrorMessage,
+		&h.SendGridMsgID, &h.DeliveredAt, &h.OpenedAt, &h.ClickedAt, &h.BouncedAt,
+		&h.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get alert history: %w", err)
+	}
+	return h, nil
+}
+
 // GetLastAlertForRule returns the most recent alert history for a rule+customer combo (for deduplication/cooldown).
 func (r *AlertHistoryRepository) GetLastAlertForRule(ctx context.Context, ruleID, customerID uuid.UUID) (*AlertHistory, error) {
 	h := &AlertHistory{}
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, org_id, alert_rule_id, customer_id, trigger_data, channel, status, sent_at, error_message,
+		SELECT id, org_id, alert_rule_id, customer_id, account_id, trigger_data, channel, status, sent_at, error_message,
 			COALESCE(sendgrid_message_id, ''), delivered_at, opened_at, clicked_at, bounced_at, created_at
 		FROM alert_history
 		WHERE alert_rule_id = $1 AND customer_id = $2
 		ORDER BY created_at DESC
 		LIMIT 1
 	`, ruleID, customerID).Scan(
-		&h.ID, &h.OrgID, &h.AlertRuleID, &h.CustomerID, &h.TriggerData,
+		&h.ID, &h.OrgID, &h.AlertRuleID, &h.CustomerID, &h.AccountID, &h.TriggerData,
 		&h.Channel, &h.Status, &h.SentAt, &h.ErrorMessage,
 		&h.SendGridMsgID, &h.DeliveredAt, &h.OpenedAt, &h.ClickedAt, &h.BouncedAt,
 		&h.CreatedAt,
@@ -216,6 +240,31 @@ func (r *AlertHistoryRepository) GetLastAlertForRule(ctx context.Context, ruleID
 	return h, nil
 }
 
+// GetLastAlertForRuleAndAccount returns the most recent alert history for a rule+account combo (for cooldown).
+func (r *AlertHistoryRepository) GetLastAlertForRuleAndAccount(ctx context.Context, ruleID, accountID uuid.UUID) (*AlertHistory, error) {
+	h := &AlertHistory{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, org_id, alert_rule_id, customer_id, account_id, trigger_data, channel, status, sent_at, error_message,
+			COALESCE(sendgrid_message_id, ''), delivered_at, opened_at, clicked_at, bounced_at, created_at
+		FROM alert_history
+		WHERE alert_rule_id = $1 AND account_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, ruleID, accountID).Scan(
+		&h.ID, &h.OrgID, &h.AlertRuleID, &h.CustomerID, &h.AccountID, &h.TriggerData,
+		&h.Channel, &h.Status, &h.SentAt, &h.ErrorMessage,
+		&h.SendGridMsgID, &h.DeliveredAt, &h.OpenedAt, &h.ClickedAt, &h.BouncedAt,
+		&h.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get last alert for rule and account: %w", err)
+	}
+	return h, nil
+}
+
 // CountByStatus returns counts grouped by status for an org.
 func (r *AlertHistoryRepository) CountByStatus(ctx context.Context, orgID uuid.UUID) (map[string]int, error) {
 	rows, err := r.pool.Query(ctx, `
@@ -241,10 +290,43 @@ func (r *AlertHistoryRepository) CountByStatus(ctx context.Context, orgID uuid.U
 	return counts, rows.Err()
 }
 
+// AlertRuleCount holds the number of alerts fired by a rule.
+type AlertRuleCount struct {
+	RuleID   uuid.UUID `json:"rule_id"`
+	RuleName string    `json:"rule_name"`
+	Count    int       `json:"count"`
+}
+
+// CountByRuleInRange returns alert counts per rule for an org between from and to, most frequent first.
+func (r *AlertHistoryRepository) CountByRuleInRange(ctx context.Context, orgID uuid.UUID, from, to time.Time) ([]AlertRuleCount, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT ah.alert_rule_id, ar.name, COUNT(*)
+		FROM alert_history ah
+		JOIN alert_rules ar ON ar.id = ah.alert_rule_id
+		WHERE ah.org_id = $1 AND ah.created_at >= $2 AND ah.created_at < $3
+		GROUP BY ah.alert_rule_id, ar.name
+		ORDER BY COUNT(*) DESC, ar.name
+	`, orgID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("count alert history by rule: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []AlertRuleCount
+	for rows.Next() {
+		var c AlertRuleCount
+		if err := rows.Scan(&c.RuleID, &c.RuleName, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan rule count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
 // ListActiveRulesByOrg returns all active alert rules for an org.
 func (r *AlertHistoryRepository) ListActiveRulesByOrg(ctx context.Context, orgID uuid.UUID) ([]*AlertRule, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, org_id, name, description, trigger_type, conditions, channel, recipients, is_active, created_by, created_at, updated_at
+		SELECT id, org_id, name, description, trigger_type, conditions, channel, recipients, severity, is_active, task_config, segment_id, created_by, created_at, updated_at
 		FROM alert_rules
 		WHERE org_id = $1 AND is_active = true
 		ORDER BY created_at DESC
@@ -260,7 +342,7 @@ func (r *AlertHistoryRepository) ListActiveRulesByOrg(ctx context.Context, orgID
 		if err := rows.Scan(
 			&rule.ID, &rule.OrgID, &rule.Name, &rule.Description,
 			&rule.TriggerType, &rule.Conditions, &rule.Channel,
-			&rule.Recipients, &rule.IsActive, &rule.CreatedBy,
+			&rule.Recipients, &rule.Severity, &rule.IsActive, &rule.Task, &rule.SegmentID, &rule.CreatedBy,
 			&rule.CreatedAt, &rule.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan alert rule: %w", err)
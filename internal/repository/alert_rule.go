@@ -12,18 +12,32 @@ import (
 
 // AlertRule represents an alert rule.
 type AlertRule struct {
-	ID          uuid.UUID      `json:"id"`
-	OrgID       uuid.UUID      `json:"org_id"`
-	Name        string         `json:"name"`
-	Description string         `json:"description,omitempty"`
-	TriggerType string         `json:"trigger_type"`
-	Conditions  map[string]any `json:"conditions"`
-	Channel     string         `json:"channel"`
-	Recipients  []string       `json:"recipients"`
-	IsActive    bool           `json:"is_active"`
-	CreatedBy   *uuid.UUID     `json:"created_by,omitempty"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+	ID          uuid.UUID        `json:"id"`
+	OrgID       uuid.UUID        `json:"org_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	TriggerType string           `json:"trigger_type"`
+	Conditions  map[string]any   `json:"conditions"`
+	Channel     string           `json:"channel"`
+	Recipients  []string         `json:"recipients"`
+	Severity    string           `json:"severity"` // info, warning, critical
+	IsActive    bool             `json:"is_active"`
+	Task        *AlertTaskConfig `json:"task,omitempty"`
+	SegmentID   *uuid.UUID       `json:"segment_id,omitempty"`
+	CreatedBy   *uuid.UUID       `json:"created_by,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// AlertTaskConfig describes the follow-up task created each time a rule fires.
+// When AssignToOwner is set, customer alerts go to the customer's first owner
+// and fall back to AssigneeID.
+type AlertTaskConfig struct {
+	Enabled       bool       `json:"enabled"`
+	Title         string     `json:"title,omitempty"`
+	DueInDays     int        `json:"due_in_days"`
+	AssigneeID    *uuid.UUID `json:"assignee_id,omitempty"`
+	AssignToOwner bool       `json:"assign_to_owner,omitempty"`
 }
 
 // AlertRuleRepository handles alert_rules database operations.
@@ -39,7 +53,7 @@ func NewAlertRuleRepository(pool *pgxpool.Pool) *AlertRuleRepository {
 // List returns all alert rules for an organization.
 func (r *AlertRuleRepository) List(ctx context.Context, orgID uuid.UUID) ([]*AlertRule, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, org_id, name, description, trigger_type, conditions, channel, recipients, is_active, created_by, created_at, updated_at
+		SELECT id, org_id, name, description, trigger_type, conditions, channel, recipients, severity, is_active, task_config, segment_id, created_by, created_at, updated_at
 		FROM alert_rules
 		WHERE org_id = $1
 		ORDER BY created_at DESC
@@ -55,7 +69,7 @@ func (r *AlertRuleRepository) List(ctx context.Context, orgID uuid.UUID) ([]*Ale
 		if err := rows.Scan(
 			&rule.ID, &rule.OrgID, &rule.Name, &rule.Description,
 			&rule.TriggerType, &rule.Conditions, &rule.Channel,
-			&rule.Recipients, &rule.IsActive, &rule.CreatedBy,
+			&rule.Recipients, &rule.Severity, &rule.IsActive, &rule.Task, &rule.SegmentID, &rule.CreatedBy,
 			&rule.CreatedAt, &rule.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan alert rule: %w", err)
@@ -70,13 +84,13 @@ func (r *AlertRuleRepository) List(ctx context.Context, orgID uuid.UUID) ([]*Ale
 func (r *AlertRuleRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*AlertRule, error) {
 	rule := &AlertRule{}
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, org_id, name, description, trigger_type, conditions, channel, recipients, is_active, created_by, created_at, updated_at
+		SELECT id, org_id, name, description, trigger_type, conditions, channel, recipients, severity, is_active, task_config, segment_id, created_by, created_at, updated_at
 		FROM alert_rules
 		WHERE id = $1 AND org_id = $2
 	`, id, orgID).Scan(
 		&rule.ID, &rule.OrgID, &rule.Name, &rule.Description,
 		&rule.TriggerType, &rule.Conditions, &rule.Channel,
-		&rule.Recipients, &rule.IsActive, &rule.CreatedBy,
+		&rule.Recipients, &rule.Severity, &rule.IsActive, &rule.Task, &rule.SegmentID, &rule.CreatedBy,
 		&rule.CreatedAt, &rule.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
@@ -91,12 +105,12 @@ func (r *AlertRuleRepository) GetByID(ctx context.Context, id, orgID uuid.UUID)
 // Create inserts a new alert rule.
 func (r *AlertRuleRepository) Create(ctx context.Context, rule *AlertRule) error {
 	return r.pool.QueryRow(ctx, `
-		INSERT INTO alert_rules (org_id, name, description, trigger_type, conditions, channel, recipients, is_active, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO alert_rules (org_id, name, description, trigger_type, conditions, channel, recipients, severity, is_active, task_config, segment_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at
 	`, rule.OrgID, rule.Name, rule.Description, rule.TriggerType,
-		rule.Conditions, rule.Channel, rule.Recipients,
-		rule.IsActive, rule.CreatedBy,
+		rule.Conditions, rule.Channel, rule.Recipients, rule.Severity,
+		rule.IsActive, rule.Task, rule.SegmentID, rule.CreatedBy,
 	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
 }
 
@@ -104,11 +118,11 @@ func (r *AlertRuleRepository) Create(ctx context.Context, rule *AlertRule) error
 func (r *AlertRuleRepository) Update(ctx context.Context, rule *AlertRule) error {
 	ct, err := r.pool.Exec(ctx, `
 		UPDATE alert_rules
-		SET name = $1, description = $2, trigger_type = $3, conditions = $4, channel = $5, recipients = $6, is_active = $7, updated_at = NOW()
-		WHERE id = $8 AND org_id = $9
+		SET name = $1, description = $2, trigger_type = $3, conditions = $4, channel = $5, recipients = $6, severity = $7, is_active = $8, task_config = $9, segment_id = $10, updated_at = NOW()
+		WHERE id = $11 AND org_id = $12
 	`, rule.Name, rule.Description, rule.TriggerType, rule.Conditions,
-		rule.Channel, rule.Recipients, rule.IsActive,
-		rule.ID, rule.OrgID,
+		rule.Channel, rule.Recipients, rule.Severity, rule.IsActive, rule.Task,
+		rule.SegmentID, rule.ID, rule.OrgID,
 	)
 	if err != nil {
 		return fmt.Errorf("update alert rule: %w", err)
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AlertTemplate represents an org-defined alert email template. Exactly one of
+// AlertRuleID and TriggerType is set.
+type AlertTemplate struct {
+	ID              uuid.UUID  `json:"id"`
+	OrgID           uuid.UUID  `json:"org_id"`
+	AlertRuleID     *uuid.UUID `json:"alert_rule_id,omitempty"`
+	TriggerType     *string    `json:"trigger_type,omitempty"`
+	SubjectTemplate string     `json:"subject_template"`
+	BodyTemplate    string     `json:"body_template"`
+	TextTemplate    string     `json:"text_template"`
+	CreatedBy       *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// AlertTemplateRepository handles alert_templates database operations.
+type AlertTemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAlertTemplateRepository creates a new AlertTemplateRepository.
+func NewAlertTemplateRepository(pool *pgxpool.Pool) *AlertTemplateRepository {
+	return &AlertTemplateRepository{pool: pool}
+}
+
+const alertTemplateColumns = `id, org_id, alert_rule_id, trigger_type, subject_template, body_template, text_template, created_by, created_at, updated_at`
+
+func scanAlertTemplate(row pgx.Row) (*AlertTemplate, error) {
+	t := &AlertTemplate{}
+	err := row.Scan(
+		&t.ID, &t.OrgID, &t.AlertRuleID, &t.TriggerType,
+		&t.SubjectTemplate, &t.BodyTemplate, &t.TextTemplate,
+		&t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
+	)
+	return t, err
+}
+
+// List returns all alert templates for an organization.
+func (r *AlertTemplateRepository) List(ctx context.Context, orgID uuid.UUID) ([]*AlertTemplate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+alertTemplateColumns+`
+		FROM alert_templates
+		WHERE org_id = $1
+		ORDER BY created_at DESC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("query alert templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*AlertTemplate
+	for rows.Next() {
+		t, err := scanAlertTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// GetByID returns a single alert template by ID and org.
+func (r *AlertTemplateRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*AlertTemplate, error) {
+	t, err := scanAlertTemplate(r.pool.QueryRow(ctx, `
+		SELECT `+alertTemplateColumns+`
+		FROM alert_templates
+		WHERE id = $1 AND org_id = $2
+	`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query alert template: %w", err)
+	}
+	return t, nil
+}
+
+// FindForRule returns the template that applies to a rule: its own template if
+// one exists, otherwise the org's template for the rule's trigger type.
+func (r *AlertTemplateRepository) FindForRule(ctx context.Context, orgID, ruleID uuid.UUID, triggerType string) (*AlertTemplate, error) {
+	t, err := scanAlertTemplate(r.pool.QueryRow(ctx, `
+		SELECT `+alertTemplateColumns+`
+		FROM alert_templates
+		WHERE org_id = $1 AND (alert_rule_id = $2 OR trigger_type = $3)
+		ORDER BY alert_rule_id IS NULL
+		LIMIT 1
+	`, orgID, ruleID, triggerType))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find alert template: %w", err)
+	}
+	return t, nil
+}
+
+// Create inserts a new alert template.
+func (r *AlertTemplateRepository) Create(ctx context.Context, t *AlertTemplate) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO alert_templates (org_id, alert_rule_id, trigger_type, subject_template, body_template, text_template, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`, t.OrgID, t.AlertRuleID, t.TriggerType, t.SubjectTemplate, t.BodyTemplate, t.TextTemplate, t.CreatedBy,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+// Update updates the template bodies of an existing alert template.
+func (r *AlertTemplateRepository) Update(ctx context.Context, t *AlertTemplate) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE alert_templates
+		SET subject_template = $1, body_template = $2, text_template = $3
+		WHERE id = $4 AND org_id = $5
+		RETURNING updated_at
+	`, t.SubjectTemplate, t.BodyTemplate, t.TextTemplate, t.ID, t.OrgID,
+	).Scan(&t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgx.ErrNoRows
+		}
+		return fmt.Errorf("update alert template: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes an alert template.
+func (r *AlertTemplateRepository) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	ct, err := r.pool.Exec(ctx, `
+		DELETE FROM alert_templates WHERE id = $1 AND org_id = $2
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("delete alert template: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
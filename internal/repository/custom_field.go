@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CustomFieldScoring turns a custom field's values into a 0..1 scoring
+// factor. Number fields scale linearly from Min (0) to Max (1), reversed when
+// Invert is set; enum and boolean fields look their value up in OptionScores.
+type CustomFieldScoring struct {
+	Min          *float64           `json:"min,omitempty"`
+	Max          *float64           `json:"max,omitempty"`
+	Invert       bool               `json:"invert,omitempty"`
+	OptionScores map[string]float64 `json:"option_scores,omitempty"`
+}
+
+// CustomField represents a custom_fields row.
+type CustomField struct {
+	ID        uuid.UUID           `json:"id"`
+	OrgID     uuid.UUID           `json:"org_id"`
+	Key       string              `json:"key"`
+	Label     string              `json:"label"`
+	FieldType string              `json:"field_type"`
+	Options   []string            `json:"options"`
+	Source    *string             `json:"source,omitempty"`
+	SourceKey *string             `json:"source_key,omitempty"`
+	Scoring   *CustomFieldScoring `json:"scoring,omitempty"`
+	CreatedBy *uuid.UUID          `json:"created_by,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// CustomFieldValue represents a customer_field_values row. Text holds the
+// value in canonical form; Number and Date are set for those field types.
+type CustomFieldValue struct {
+	CustomerID uuid.UUID  `json:"customer_id"`
+	FieldID    uuid.UUID  `json:"field_id"`
+	Text       string     `json:"-"`
+	Number     *float64   `json:"-"`
+	Date       *time.Time `json:"-"`
+	Source     string     `json:"source"`
+	UpdatedBy  *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CustomFieldRepository handles custom_fields and customer_field_values
+// database operations.
+type CustomFieldRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCustomFieldRepository creates a new CustomFieldRepository.
+func NewCustomFieldRepository(pool *pgxpool.Pool) *CustomFieldRepository {
+	return &CustomFieldRepository{pool: pool}
+}
+
+const customFieldSelect = `
+	SELECT id, org_id, key, label, field_type, options, source, source_key, scoring, created_by, created_at, updated_at
+	FROM custom_fields`
+
+func scanCustomField(row pgx.Row) (*CustomField, error) {
+	f := &CustomField{}
+	err := row.Scan(&f.ID, &f.OrgID, &f.Key, &f.Label, &f.FieldType, &f.Options,
+		&f.Source, &f.SourceKey, &f.Scoring, &f.CreatedBy, &f.CreatedAt, &f.UpdatedAt)
+	return f, err
+}
+
+func (r *CustomFieldRepository) get(ctx context.Context, query string, args ...any) (*CustomField, error) {
+	f, err := scanCustomField(r.pool.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get custom field: %w", err)
+	}
+	return f, nil
+}
+
+// ListByOrg returns an org's custom fields by key.
+func (r *CustomFieldRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*CustomField, error) {
+	rows, err := r.pool.Query(ctx, customFieldSelect+` WHERE org_id = $1 ORDER BY key`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list custom fields: %w", err)
+	}
+	defer rows.Close()
+
+	fields := []*CustomField{}
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan custom field: %w", err)
+		}
+		fields = append(fields, f)
+	}
+	return fields, rows.Err()
+}
+
+// GetByID returns a custom field by ID and org.
+func (r *CustomFieldRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*CustomField, error) {
+	return r.get(ctx, customFieldSelect+` WHERE id = $1 AND org_id = $2`, id, orgID)
+}
+
+// GetByKey returns an org's custom field by key.
+func (r *CustomFieldRepository) GetByKey(ctx context.Context, orgID uuid.UUID, key string) (*CustomField, error) {
+	return r.get(ctx, customFieldSelect+` WHERE org_id = $1 AND key = $2`, orgID, key)
+}
+
+// Create inserts a new custom field.
+func (r *CustomFieldRepository) Create(ctx context.Context, f *CustomField) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO custom_fields (org_id, key, label, field_type, options, source, source_key, scoring, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at`,
+		f.OrgID, f.Key, f.Label, f.FieldType, f.Options, f.Source, f.SourceKey, f.Scoring, f.CreatedBy,
+	).Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt)
+}
+
+// Update saves a custom field's editable fields. The key and type are fixed
+// once a field is created.
+func (r *CustomFieldRepository) Update(ctx context.Context, f *CustomField) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE custom_fields SET label = $3, options = $4, source = $5, source_key = $6, scoring = $7
+		WHERE id = $1 AND org_id = $2
+		RETURNING updated_at`,
+		f.ID, f.OrgID, f.Label, f.Options, f.Source, f.SourceKey, f.Scoring,
+	).Scan(&f.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update custom field: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a custom field and every customer's value for it.
+func (r *CustomFieldRepository) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM custom_fields WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("delete custom field: %w", err)
+	}
+	return nil
+}
+
+// CountAlertRules returns how many of an org's alert rules test the field
+// with the key.
+func (r *CustomFieldRepository) CountAlertRules(ctx context.Context, orgID uuid.UUID, key string) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM alert_rules
+		WHERE org_id = $1 AND trigger_type = 'custom_field' AND conditions->>'field' = $2`,
+		orgID, key).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count custom field alert rules: %w", err)
+	}
+	return n, nil
+}
+
+// CountSegments returns how many of an org's segments filter on the field
+// with the key.
+func (r *CustomFieldRepository) CountSegments(ctx context.Context, orgID uuid.UUID, key string) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM segments
+		WHERE org_id = $1 AND jsonb_path_exists(filter, '$.** ? (@.field == $field)', jsonb_build_object('field', 'field.' || $2::text))`,
+		orgID, key).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count custom field segments: %w", err)
+	}
+	return n, nil
+}
+
+const customFieldValueSelect = `
+	SELECT customer_id, field_id, value_text, value_number::float8, value_date, source, updated_by, updated_at
+	FROM customer_field_values`
+
+func scanCustomFieldValue(row pgx.Row) (*CustomFieldValue, error) {
+	v := &CustomFieldValue{}
+	err := row.Scan(&v.CustomerID, &v.FieldID, &v.Text, &v.Number, &v.Date, &v.Source, &v.UpdatedBy, &v.UpdatedAt)
+	return v, err
+}
+
+// GetValue returns a customer's value for a field, or nil if it has none.
+func (r *CustomFieldRepository) GetValue(ctx context.Context, customerID, fieldID uuid.UUID) (*CustomFieldValue, error) {
+	v, err := scanCustomFieldValue(r.pool.QueryRow(ctx, customFieldValueSelect+`
+		WHERE customer_id = $1 AND field_id = $2`, customerID, fieldID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get custom field value: %w", err)
+	}
+	return v, nil
+}
+
+// ListValuesByCustomer returns a customer's custom field values.
+func (r *CustomFieldRepository) ListValuesByCustomer(ctx context.Context, customerID, orgID uuid.UUID) ([]*CustomFieldValue, error) {
+	rows, err := r.pool.Query(ctx, customFieldValueSelect+`
+		WHERE customer_id = $1 AND org_id = $2`, customerID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list custom field values: %w", err)
+	}
+	defer rows.Close()
+
+	var values []*CustomFieldValue
+	for rows.Next() {
+		v, err := scanCustomFieldValue(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan custom field value: %w", err)
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// SetValue creates or replaces a customer's value for a field.
+func (r *CustomFieldRepository) SetValue(ctx context.Context, orgID uuid.UUID, v *CustomFieldValue) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO customer_field_values (customer_id, field_id, org_id, value_text, value_number, value_date, source, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (customer_id, field_id) DO UPDATE SET
+			value_text = EXCLUDED.value_text,
+			value_number = EXCLUDED.value_number,
+			value_date = EXCLUDED.value_date,
+			source = EXCLUDED.source,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at`,
+		v.CustomerID, v.FieldID, orgID, v.Text, v.Number, v.Date, v.Source, v.UpdatedBy,
+	).Scan(&v.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("set custom field value: %w", err)
+	}
+	return nil
+}
+
+// DeleteValue clears a customer's value for a field.
+func (r *CustomFieldRepository) DeleteValue(ctx context.Context, orgID, customerID, fieldID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM customer_field_values WHERE customer_id = $1 AND field_id = $2 AND org_id = $3`,
+		customerID, fieldID, orgID)
+	if err != nil {
+		return fmt.Errorf("delete custom field value: %w", err)
+	}
+	return nil
+}
+
+// ListSourceValues returns the text at the first of paths that is set in
+// each of an org's customers' metadata, keyed by customer. Customers with
+// none of the paths set are left out.
+func (r *CustomFieldRepository) ListSourceValues(ctx context.Context, orgID uuid.UUID, paths [][]string) (map[uuid.UUID]string, error) {
+	if len(paths) == 0 {
+		return map[uuid.UUID]string{}, nil
+	}
+
+	args := []any{orgID}
+	exprs := make([]string, len(paths))
+	for i, path := range paths {
+		args = append(args, path)
+		exprs[i] = fmt.Sprintf("NULLIF(c.metadata #>> $%d::text[], '')", len(args))
+	}
+	value := "COALESCE(" + strings.Join(exprs, ", ") + ")"
+
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(`
+		SELECT c.id, %s
+		FROM customers c
+		WHERE c.org_id = $1 AND c.deleted_at IS NULL AND %s IS NOT NULL`, value, value), args...)
+	if err != nil {
+		return nil, fmt.Errorf("list custom field source values: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var (
+			id   uuid.UUID
+			text string
+		)
+		if err := rows.Scan(&id, &text); err != nil {
+			return nil, fmt.Errorf("scan custom field source value: %w", err)
+		}
+		values[id] = text
+	}
+	return values, rows.Err()
+}
+
+// ReplaceSynced makes values the field's only values, all marked as synced.
+// Customers missing from values lose their value for the field.
+func (r *CustomFieldRepository) ReplaceSynced(ctx context.Context, orgID, fieldID uuid.UUID, values []*CustomFieldValue) error {
+	customerIDs := make([]uuid.UUID, len(values))
+	texts := make([]string, len(values))
+	numbers := make([]*float64, len(values))
+	dates := make([]*time.Time, len(values))
+	for i, v := range values {
+		customerIDs[i], texts[i], numbers[i], dates[i] = v.CustomerID, v.Text, v.Number, v.Date
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		WITH removed AS (
+			DELETE FROM customer_field_values
+			WHERE field_id = $1 AND org_id = $2 AND customer_id <> ALL($3::uuid[])
+		)
+		INSERT INTO customer_field_values (customer_id, field_id, org_id, value_text, value_number, value_date, source)
+		SELECT v.customer_id, $1, $2, v.value_text, v.value_number, v.value_date, 'sync'
+		FROM unnest($3::uuid[], $4::text[], $5::numeric[], $6::date[]) AS v(customer_id, value_text, value_number, value_date)
+		ON CONFLICT (customer_id, field_id) DO UPDATE SET
+			value_text = EXCLUDED.value_text,
+			value_number = EXCLUDED.value_number,
+			value_date = EXCLUDED.value_date,
+			source = 'sync',
+			updated_by = NULL,
+			updated_at = NOW()
+		WHERE customer_field_values.value_text IS DISTINCT FROM EXCLUDED.value_text
+			OR customer_field_values.source <> 'sync'`,
+		fieldID, orgID, customerIDs, texts, numbers, dates)
+	if err != nil {
+		return fmt.Errorf("replace synced custom field values: %w", err)
+	}
+	return nil
+}
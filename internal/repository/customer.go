@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -41,6 +42,8 @@ func NewCustomerRepository(pool *pgxpool.Pool) *CustomerRepository {
 }
 
 // UpsertByExternal creates or updates a customer by (org_id, source, external_id).
+// A customer that was merged into another stays retired, and c.ID is set to
+// the customer it was merged into.
 func (r *CustomerRepository) UpsertByExternal(ctx context.Context, c *Customer) error {
 	query := `
 		INSERT INTO customers (org_id, external_id, source, email, name, company_name, currency, first_seen_at, last_seen_at, metadata)
@@ -52,8 +55,8 @@ func (r *CustomerRepository) UpsertByExternal(ctx context.Context, c *Customer)
 			currency = EXCLUDED.currency,
 			last_seen_at = EXCLUDED.last_seen_at,
 			metadata = EXCLUDED.metadata,
-			deleted_at = NULL
-		RETURNING id, mrr_cents, created_at, updated_at`
+			deleted_at = CASE WHEN customers.merged_into_id IS NULL THEN NULL ELSE customers.deleted_at END
+		RETURNING COALESCE(merged_into_id, id), mrr_cents, created_at, updated_at`
 
 	return r.pool.QueryRow(ctx, query,
 		c.OrgID, c.ExternalID, c.Source, c.Email, c.Name, c.CompanyName,
@@ -61,14 +64,18 @@ func (r *CustomerRepository) UpsertByExternal(ctx context.Context, c *Customer)
 	).Scan(&c.ID, &c.MRRCents, &c.CreatedAt, &c.UpdatedAt)
 }
 
-// GetByExternalID retrieves a customer by (org_id, source, external_id).
+// GetByExternalID retrieves a customer by (org_id, source, external_id),
+// following a merge to the customer it was merged into.
 func (r *CustomerRepository) GetByExternalID(ctx context.Context, orgID uuid.UUID, source, externalID string) (*Customer, error) {
 	query := `
 		SELECT id, org_id, external_id, source, COALESCE(email, ''), COALESCE(name, ''),
 			COALESCE(company_name, ''), mrr_cents, currency,
 			first_seen_at, last_seen_at, COALESCE(metadata, '{}'), created_at, updated_at, deleted_at
 		FROM customers
-		WHERE org_id = $1 AND source = $2 AND external_id = $3 AND deleted_at IS NULL`
+		WHERE id = (
+			SELECT COALESCE(merged_into_id, id) FROM customers
+			WHERE org_id = $1 AND source = $2 AND external_id = $3
+		) AND deleted_at IS NULL`
 
 	c := &Customer{}
 	err := r.pool.QueryRow(ctx, query, orgID, source, externalID).Scan(
@@ -85,14 +92,14 @@ func (r *CustomerRepository) GetByExternalID(ctx context.Context, orgID uuid.UUI
 	return c, nil
 }
 
-// GetByEmail retrieves a customer by email within an organization.
+// GetByEmail retrieves a customer by email within an organization, ignoring case.
 func (r *CustomerRepository) GetByEmail(ctx context.Context, orgID uuid.UUID, email string) (*Customer, error) {
 	query := `
 		SELECT id, org_id, external_id, source, COALESCE(email, ''), COALESCE(name, ''),
 			COALESCE(company_name, ''), mrr_cents, currency,
 			first_seen_at, last_seen_at, COALESCE(metadata, '{}'), created_at, updated_at, deleted_at
 		FROM customers
-		WHERE org_id = $1 AND email = $2 AND deleted_at IS NULL
+		WHERE org_id = $1 AND LOWER(email) = LOWER($2) AND deleted_at IS NULL
 		ORDER BY updated_at DESC
 		LIMIT 1`
 
@@ -204,60 +211,6 @@ func (r *CustomerRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]
 	return customers, rows.Err()
 }
 
-// FindDuplicatesByEmail returns groups of active customers that share the same email within an org.
-func (r *CustomerRepository) FindDuplicatesByEmail(ctx context.Context, orgID uuid.UUID) ([][]*Customer, error) {
-	query := `
-		WITH duplicate_emails AS (
-			SELECT email
-			FROM customers
-			WHERE org_id = $1 AND deleted_at IS NULL AND COALESCE(email, '') <> ''
-			GROUP BY email
-			HAVING COUNT(*) > 1
-		)
-		SELECT c.id, c.org_id, c.external_id, c.source, COALESCE(c.email, ''), COALESCE(c.name, ''),
-			COALESCE(c.company_name, ''), c.mrr_cents, c.currency,
-			c.first_seen_at, c.last_seen_at, COALESCE(c.metadata, '{}'), c.created_at, c.updated_at, c.deleted_at
-		FROM customers c
-		JOIN duplicate_emails d ON d.email = c.email
-		WHERE c.org_id = $1 AND c.deleted_at IS NULL
-		ORDER BY c.email, c.first_seen_at NULLS LAST, c.created_at`
-
-	rows, err := r.pool.Query(ctx, query, orgID)
-	if err != nil {
-		return nil, fmt.Errorf("find duplicate customers by email: %w", err)
-	}
-	defer rows.Close()
-
-	groupsByEmail := map[string][]*Customer{}
-	emailOrder := make([]string, 0)
-
-	for rows.Next() {
-		c := &Customer{}
-		if err := rows.Scan(
-			&c.ID, &c.OrgID, &c.ExternalID, &c.Source, &c.Email, &c.Name,
-			&c.CompanyName, &c.MRRCents, &c.Currency,
-			&c.FirstSeenAt, &c.LastSeenAt, &c.Metadata, &c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
-		); err != nil {
-			return nil, fmt.Errorf("scan duplicate customer: %w", err)
-		}
-
-		if _, exists := groupsByEmail[c.Email]; !exists {
-			emailOrder = append(emailOrder, c.Email)
-		}
-		groupsByEmail[c.Email] = append(groupsByEmail[c.Email], c)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
-	}
-
-	groups := make([][]*Customer, 0, len(emailOrder))
-	for _, email := range emailOrder {
-		groups = append(groups, groupsByEmail[email])
-	}
-
-	return groups, nil
-}
-
 // CountByOrg returns the number of active customers for an org.
 func (r *CustomerRepository) CountByOrg(ctx context.Context, orgID uuid.UUID) (int, error) {
 	query := `SELECT COUNT(*) FROM customers WHERE org_id = $1 AND deleted_at IS NULL`
@@ -314,6 +267,19 @@ type CustomerListParams struct {
 	Risk    string
 	Search  string
 	Source  string
+	// OwnerID limits the list to customers the user owns; Unowned to
+	// customers nobody owns.
+	OwnerID *uuid.UUID
+	Unowned bool
+	// Tags limits the list to customers carrying every tag. SegmentID names
+	// a saved segment, which the service resolves into Segment.
+	Tags      []string
+	SegmentID *uuid.UUID
+	Segment   *SegmentFilter
+	// Fields are conditions on custom field values, each on a field.<key>.
+	// Sort "field" orders by the value of the custom field SortFieldID.
+	Fields      []SegmentFilter
+	SortFieldID *uuid.UUID
 }
 
 // CustomerWithScore holds a customer with its health score data.
@@ -321,6 +287,8 @@ type CustomerWithScore struct {
 	Customer
 	OverallScore *int
 	RiskLevel    *string
+	OwnerIDs     []uuid.UUID
+	Tags         []string
 }
 
 // CustomerListResult holds paginated customer list results.
@@ -354,6 +322,40 @@ func (r *CustomerRepository) ListWithScores(ctx context.Context, params Customer
 		args = append(args, params.Source)
 		argIdx++
 	}
+	if params.OwnerID != nil {
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM customer_owners o WHERE o.customer_id = c.id AND o.user_id = $%d)", argIdx)
+		args = append(args, *params.OwnerID)
+		argIdx++
+	}
+	if params.Unowned {
+		where += ` AND NOT EXISTS (
+			SELECT 1 FROM customer_owners o
+			JOIN user_organizations m ON m.user_id = o.user_id AND m.org_id = o.org_id
+			WHERE o.customer_id = c.id)`
+	}
+	if len(params.Tags) > 0 {
+		where += fmt.Sprintf(` AND (SELECT COUNT(*) FROM customer_tags t WHERE t.customer_id = c.id AND t.tag = ANY($%d)) = $%d`, argIdx, argIdx+1)
+		args = append(args, params.Tags, len(params.Tags))
+		argIdx += 2
+	}
+	if params.Segment != nil {
+		cond, segArgs, err := params.Segment.where(args)
+		if err != nil {
+			return nil, fmt.Errorf("compile segment filter: %w", err)
+		}
+		where += " AND " + cond
+		args = segArgs
+		argIdx = len(args) + 1
+	}
+	for _, field := range params.Fields {
+		cond, fieldArgs, err := field.where(args)
+		if err != nil {
+			return nil, fmt.Errorf("compile custom field filter: %w", err)
+		}
+		where += " AND " + cond
+		args = fieldArgs
+		argIdx = len(args) + 1
+	}
 
 	// Count query
 	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM customers c LEFT JOIN health_scores hs ON c.id = hs.customer_id WHERE %s`, where)
@@ -367,8 +369,13 @@ func (r *CustomerRepository) ListWithScores(ctx context.Context, params Customer
 		totalPages = (total + params.PerPage - 1) / params.PerPage
 	}
 
+	order := "ASC"
+	if params.Order == "desc" {
+		order = "DESC"
+	}
+
 	// Sort validation
-	sortColumn := "c.name"
+	sortColumns := []string{"c.name"}
 	sortAllowlist := map[string]string{
 		"name":      "c.name",
 		"mrr":       "c.mrr_cents",
@@ -376,12 +383,22 @@ func (r *CustomerRepository) ListWithScores(ctx context.Context, params Customer
 		"last_seen": "c.last_seen_at",
 	}
 	if col, ok := sortAllowlist[params.Sort]; ok {
-		sortColumn = col
+		sortColumns = []string{col}
 	}
 
-	order := "ASC"
-	if params.Order == "desc" {
-		order = "DESC"
+	// Sorting by a custom field joins its value; only the column matching
+	// the field's type is set, so the others do not affect the order.
+	sortJoin := ""
+	if params.Sort == "field" && params.SortFieldID != nil {
+		sortJoin = fmt.Sprintf("LEFT JOIN customer_field_values sv ON sv.customer_id = c.id AND sv.field_id = $%d", argIdx)
+		args = append(args, *params.SortFieldID)
+		argIdx++
+		sortColumns = []string{"sv.value_number", "sv.value_date", "LOWER(sv.value_text)"}
+	}
+
+	orderBy := make([]string, len(sortColumns))
+	for i, col := range sortColumns {
+		orderBy[i] = fmt.Sprintf("%s %s NULLS LAST", col, order)
 	}
 
 	// Data query
@@ -389,13 +406,19 @@ func (r *CustomerRepository) ListWithScores(ctx context.Context, params Customer
 		SELECT c.id, c.org_id, c.external_id, c.source, COALESCE(c.email, ''), COALESCE(c.name, ''),
 			COALESCE(c.company_name, ''), c.mrr_cents, c.currency,
 			c.first_seen_at, c.last_seen_at, COALESCE(c.metadata, '{}'), c.created_at, c.updated_at, c.deleted_at,
-			hs.overall_score, hs.risk_level
+			hs.overall_score, hs.risk_level,
+			ARRAY(
+				SELECT o.user_id FROM customer_owners o
+				JOIN user_organizations m ON m.user_id = o.user_id AND m.org_id = o.org_id
+				WHERE o.customer_id = c.id ORDER BY o.assigned_at),
+			ARRAY(SELECT t.tag FROM customer_tags t WHERE t.customer_id = c.id ORDER BY t.tag)
 		FROM customers c
 		LEFT JOIN health_scores hs ON c.id = hs.customer_id
+		%s
 		WHERE %s
-		ORDER BY %s %s NULLS LAST
+		ORDER BY %s, c.id
 		LIMIT $%d OFFSET $%d`,
-		where, sortColumn, order, argIdx, argIdx+1)
+		sortJoin, where, strings.Join(orderBy, ", "), argIdx, argIdx+1)
 
 	offset := (params.Page - 1) * params.PerPage
 	args = append(args, params.PerPage, offset)
@@ -413,7 +436,7 @@ func (r *CustomerRepository) ListWithScores(ctx context.Context, params Customer
 			&cs.ID, &cs.OrgID, &cs.ExternalID, &cs.Source, &cs.Email, &cs.Name,
 			&cs.CompanyName, &cs.MRRCents, &cs.Currency,
 			&cs.FirstSeenAt, &cs.LastSeenAt, &cs.Metadata, &cs.CreatedAt, &cs.UpdatedAt, &cs.DeletedAt,
-			&cs.OverallScore, &cs.RiskLevel,
+			&cs.OverallScore, &cs.RiskLevel, &cs.OwnerIDs, &cs.Tags,
 		); err != nil {
 			return nil, fmt.Errorf("scan customer with score: %w", err)
 		}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Assignment rule strategies.
+const (
+	AssignmentStrategyRoundRobin = "round_robin"
+	AssignmentStrategyAll        = "all"
+)
+
+// CustomerAssignmentRule assigns owners to unowned customers in a segment.
+// round_robin gives each customer the next of UserIDs in turn; all makes
+// every one of UserIDs an owner.
+type CustomerAssignmentRule struct {
+	ID         uuid.UUID       `json:"id"`
+	OrgID      uuid.UUID       `json:"org_id"`
+	Name       string          `json:"name"`
+	Priority   int             `json:"priority"`
+	Conditions CustomerSegment `json:"conditions"`
+	Strategy   string          `json:"strategy"`
+	UserIDs    []uuid.UUID     `json:"user_ids"`
+	NextIndex  int             `json:"-"`
+	IsActive   bool            `json:"is_active"`
+	CreatedBy  *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// CustomerAssignmentRuleRepository handles customer_assignment_rules database operations.
+type CustomerAssignmentRuleRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCustomerAssignmentRuleRepository creates a new CustomerAssignmentRuleRepository.
+func NewCustomerAssignmentRuleRepository(pool *pgxpool.Pool) *CustomerAssignmentRuleRepository {
+	return &CustomerAssignmentRuleRepository{pool: pool}
+}
+
+const customerAssignmentRuleSelect = `
+	SELECT id, org_id, name, priority, conditions, strategy, user_ids, next_index, is_active,
+		created_by, created_at, updated_at
+	FROM customer_assignment_rules`
+
+func scanCustomerAssignmentRule(row pgx.Row) (*CustomerAssignmentRule, error) {
+	rule := &CustomerAssignmentRule{}
+	err := row.Scan(
+		&rule.ID, &rule.OrgID, &rule.Name, &rule.Priority, &rule.Conditions, &rule.Strategy,
+		&rule.UserIDs, &rule.NextIndex, &rule.IsActive, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	return rule, err
+}
+
+// List returns an org's assignment rules in the order they are applied.
+func (r *CustomerAssignmentRuleRepository) List(ctx context.Context, orgID uuid.UUID, activeOnly bool) ([]*CustomerAssignmentRule, error) {
+	query := customerAssignmentRuleSelect + ` WHERE org_id = $1`
+	if activeOnly {
+		query += ` AND is_active`
+	}
+	query += ` ORDER BY priority, created_at`
+
+	rows, err := r.pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list assignment rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*CustomerAssignmentRule
+	for rows.Next() {
+		rule, err := scanCustomerAssignmentRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan assignment rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetByID returns an assignment rule by ID and org.
+func (r *CustomerAssignmentRuleRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*CustomerAssignmentRule, error) {
+	rule, err := scanCustomerAssignmentRule(r.pool.QueryRow(ctx,
+		customerAssignmentRuleSelect+` WHERE id = $1 AND org_id = $2`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get assignment rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Create inserts a new assignment rule.
+func (r *CustomerAssignmentRuleRepository) Create(ctx context.Context, rule *CustomerAssignmentRule) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO customer_assignment_rules (org_id, name, priority, conditions, strategy, user_ids, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at`,
+		rule.OrgID, rule.Name, rule.Priority, rule.Conditions, rule.Strategy, rule.UserIDs,
+		rule.IsActive, rule.CreatedBy,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// Update updates an assignment rule. Changing the users restarts the rotation.
+func (r *CustomerAssignmentRuleRepository) Update(ctx context.Context, rule *CustomerAssignmentRule) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE customer_assignment_rules
+		SET name = $3, priority = $4, conditions = $5, strategy = $6, is_active = $8,
+			next_index = CASE WHEN user_ids = $7 THEN next_index ELSE 0 END,
+			user_ids = $7
+		WHERE id = $1 AND org_id = $2
+		RETURNING next_index, updated_at`,
+		rule.ID, rule.OrgID, rule.Name, rule.Priority, rule.Conditions, rule.Strategy, rule.UserIDs, rule.IsActive,
+	).Scan(&rule.NextIndex, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update assignment rule: %w", err)
+	}
+	return nil
+}
+
+// SetNextIndex stores where a round-robin rule continues its rotation.
+func (r *CustomerAssignmentRuleRepository) SetNextIndex(ctx context.Context, id uuid.UUID, nextIndex int) error {
+	_, err := r.pool.Exec(ctx, `UPDATE customer_assignment_rules SET next_index = $2 WHERE id = $1`, id, nextIndex)
+	if err != nil {
+		return fmt.Errorf("set assignment rule rotation: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes an assignment rule. Owners it assigned keep their customers.
+func (r *CustomerAssignmentRuleRepository) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM customer_assignment_rules WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("delete assignment rule: %w", err)
+	}
+	return nil
+}
@@ -81,6 +81,64 @@ func (r *CustomerEventRepository) ListByCustomerAndType(ctx context.Context, cus
 	return events, rows.Err()
 }
 
+// ListByCustomerAndTypes returns events for a customer of any of the given types since a given time.
+func (r *CustomerEventRepository) ListByCustomerAndTypes(ctx context.Context, customerID uuid.UUID, eventTypes []string, since time.Time) ([]*CustomerEvent, error) {
+	query := `
+		SELECT id, org_id, customer_id, event_type, source, COALESCE(external_event_id, ''),
+			occurred_at, COALESCE(data, '{}'), created_at
+		FROM customer_events
+		WHERE customer_id = $1 AND event_type = ANY($2) AND occurred_at >= $3
+		ORDER BY occurred_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, customerID, eventTypes, since)
+	if err != nil {
+		return nil, fmt.Errorf("list customer events by types: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*CustomerEvent
+	for rows.Next() {
+		e := &CustomerEvent{}
+		if err := rows.Scan(
+			&e.ID, &e.OrgID, &e.CustomerID, &e.EventType, &e.Source, &e.ExternalEventID,
+			&e.OccurredAt, &e.Data, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan customer event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// ListByOrgTypeAndRange returns events of a specific type for an org between from and to, ordered by occurred_at ASC.
+func (r *CustomerEventRepository) ListByOrgTypeAndRange(ctx context.Context, orgID uuid.UUID, eventType string, from, to time.Time) ([]*CustomerEvent, error) {
+	query := `
+		SELECT id, org_id, customer_id, event_type, source, COALESCE(external_event_id, ''),
+			occurred_at, COALESCE(data, '{}'), created_at
+		FROM customer_events
+		WHERE org_id = $1 AND event_type = $2 AND occurred_at >= $3 AND occurred_at <= $4
+		ORDER BY occurred_at ASC`
+
+	rows, err := r.pool.Query(ctx, query, orgID, eventType, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list org events by type and range: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*CustomerEvent
+	for rows.Next() {
+		e := &CustomerEvent{}
+		if err := rows.Scan(
+			&e.ID, &e.OrgID, &e.CustomerID, &e.EventType, &e.Source, &e.ExternalEventID,
+			&e.OccurredAt, &e.Data, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan customer event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
 // CountEventsByTypeForOrg returns event counts per customer for a given event type and time window.
 func (r *CustomerEventRepository) CountEventsByTypeForOrg(ctx context.Context, orgID uuid.UUID, eventType string, since time.Time) (map[uuid.UUID]int, error) {
 	query := `
@@ -157,7 +215,28 @@ type EventListResult struct {
 	TotalPages int
 }
 
-// ListPaginated returns a paginated list of events for a customer with optional filters.
+// customerTimeline is a customer's events with their notes interleaved as
+// "note" events, so a timeline can be filtered and paged as one list.
+const customerTimeline = `(
+	SELECT id, org_id, customer_id, event_type, source, COALESCE(external_event_id, '') AS external_event_id,
+		occurred_at, COALESCE(data, '{}') AS data, created_at
+	FROM customer_events
+	UNION ALL
+	SELECT n.id, n.org_id, n.customer_id, 'note', 'note', '',
+		n.created_at, jsonb_build_object(
+			'note_id', n.id,
+			'body', n.body,
+			'author_id', n.author_id,
+			'author_name', ` + userDisplayName + `,
+			'pinned', n.pinned,
+			'edited_at', n.edited_at
+		), n.created_at
+	FROM customer_notes n
+	LEFT JOIN users u ON u.id = n.author_id
+) timeline`
+
+// ListPaginated returns a paginated list of events for a customer with
+// optional filters. The customer's notes are included as "note" events.
 func (r *CustomerEventRepository) ListPaginated(ctx context.Context, params EventListParams) (*EventListResult, error) {
 	where := "customer_id = $1 AND org_id = $2"
 	args := []any{params.CustomerID, params.OrgID}
@@ -180,7 +259,7 @@ func (r *CustomerEventRepository) ListPaginated(ctx context.Context, params Even
 	}
 
 	// Count
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM customer_events WHERE %s", where)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", customerTimeline, where)
 	var total int
 	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, fmt.Errorf("count events: %w", err)
@@ -194,12 +273,12 @@ func (r *CustomerEventRepository) ListPaginated(ctx context.Context, params Even
 	// Data
 	offset := (params.Page - 1) * params.PerPage
 	dataQuery := fmt.Sprintf(`
-		SELECT id, org_id, customer_id, event_type, source, COALESCE(external_event_id, ''),
-			occurred_at, COALESCE(data, '{}'), created_at
-		FROM customer_events
+		SELECT id, org_id, customer_id, event_type, source, external_event_id,
+			occurred_at, data, created_at
+		FROM %s
 		WHERE %s
 		ORDER BY occurred_at DESC
-		LIMIT $%d OFFSET $%d`, where, argIdx, argIdx+1)
+		LIMIT $%d OFFSET $%d`, customerTimeline, where, argIdx, argIdx+1)
 	args = append(args, params.PerPage, offset)
 
 	rows, err := r.pool.Query(ctx, dataQuery, args...)
@@ -0,0 +1,494 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// customerChildTables are the tables whose rows belong to a customer through
+// customer_id and move to the primary when customers are merged. The current
+// health score is handled separately because it is unique per customer.
+var customerChildTables = []string{
+	"customer_events",
+	"stripe_subscriptions",
+	"stripe_payments",
+	"health_score_history",
+	"alert_history",
+	"hubspot_contacts",
+	"hubspot_deals",
+	"intercom_contacts",
+	"intercom_conversations",
+	"customer_notes",
+	"tasks",
+	"playbook_runs",
+}
+
+// CustomerMergeSnapshot is the state a merge changed, kept so it can be undone.
+type CustomerMergeSnapshot struct {
+	// Primary and Merged are the customers rows as they were before the merge.
+	Primary json.RawMessage `json:"primary"`
+	Merged  json.RawMessage `json:"merged"`
+	// HealthScore is the merged customer's current health score, which the
+	// merge deletes.
+	HealthScore json.RawMessage `json:"health_score,omitempty"`
+	// Moved holds the IDs of the child rows moved to the primary, per table.
+	Moved map[string][]uuid.UUID `json:"moved"`
+	// Redirected are customers previously merged into the merged customer,
+	// which now point at the primary.
+	Redirected []uuid.UUID `json:"redirected,omitempty"`
+	// The rows copied to the primary from the merged customer, which keeps
+	// its own: owner user IDs, tags, custom field IDs and whether the manual
+	// renewal override was copied.
+	CopiedOwners  []uuid.UUID `json:"copied_owners,omitempty"`
+	CopiedTags    []string    `json:"copied_tags,omitempty"`
+	CopiedFields  []uuid.UUID `json:"copied_fields,omitempty"`
+	CopiedRenewal bool        `json:"copied_renewal,omitempty"`
+	// CancelledRuns are the merged customer's active playbook runs cancelled
+	// because the primary was in the same playbook, with the state to restore.
+	// ClearedTriggerKeys maps moved runs to the trigger keys cleared because
+	// the primary was enrolled for the same trigger.
+	CancelledRuns      []MergedPlaybookRun  `json:"cancelled_runs,omitempty"`
+	ClearedTriggerKeys map[uuid.UUID]string `json:"cleared_trigger_keys,omitempty"`
+}
+
+// MergedPlaybookRun is a playbook run's state before a merge cancelled it.
+type MergedPlaybookRun struct {
+	ID        uuid.UUID  `json:"id"`
+	Status    string     `json:"status"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+}
+
+// CustomerMerge represents a customer_merges row.
+type CustomerMerge struct {
+	ID         uuid.UUID             `json:"id"`
+	OrgID      uuid.UUID             `json:"org_id"`
+	PrimaryID  uuid.UUID             `json:"primary_id"`
+	MergedID   uuid.UUID             `json:"merged_id"`
+	Reason     string                `json:"reason"` // duplicate_email, manual
+	Snapshot   CustomerMergeSnapshot `json:"-"`
+	MergedBy   *uuid.UUID            `json:"merged_by,omitempty"`
+	MergedAt   time.Time             `json:"merged_at"`
+	UnmergedBy *uuid.UUID            `json:"unmerged_by,omitempty"`
+	UnmergedAt *time.Time            `json:"unmerged_at,omitempty"`
+}
+
+// CustomerMergeRepository handles customer_merges database operations.
+type CustomerMergeRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCustomerMergeRepository creates a new CustomerMergeRepository.
+func NewCustomerMergeRepository(pool *pgxpool.Pool) *CustomerMergeRepository {
+	return &CustomerMergeRepository{pool: pool}
+}
+
+// Merge folds m.MergedID into m.PrimaryID within tx: it snapshots both
+// customers, stores primary's consolidated fields, moves every child row to the
+// primary, retires the merged customer and records m with the snapshot.
+func (r *CustomerMergeRepository) Merge(ctx context.Context, tx pgx.Tx, m *CustomerMerge, primary *Customer) error {
+	snap := CustomerMergeSnapshot{Moved: map[string][]uuid.UUID{}}
+
+	// Lock both customers for the duration of the merge
+	rows, err := tx.Query(ctx, `
+		SELECT id, to_jsonb(c) FROM customers c
+		WHERE id = ANY($1) AND org_id = $2 AND deleted_at IS NULL
+		FOR UPDATE`, []uuid.UUID{m.PrimaryID, m.MergedID}, m.OrgID)
+	if err != nil {
+		return fmt.Errorf("lock customers: %w", err)
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		var row json.RawMessage
+		if err := rows.Scan(&id, &row); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan customer snapshot: %w", err)
+		}
+		if id == m.PrimaryID {
+			snap.Primary = row
+		} else {
+			snap.Merged = row
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("snapshot customers: %w", err)
+	}
+	if snap.Primary == nil || snap.Merged == nil {
+		return fmt.Errorf("customers %s and %s are not both active", m.PrimaryID, m.MergedID)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE customers
+		SET name = $2, company_name = $3, mrr_cents = $4, currency = $5, metadata = $6
+		WHERE id = $1`,
+		m.PrimaryID, primary.Name, primary.CompanyName, primary.MRRCents, primary.Currency, primary.Metadata,
+	); err != nil {
+		return fmt.Errorf("update primary customer: %w", err)
+	}
+
+	if err := r.prepareMergedPlaybookRuns(ctx, tx, m, &snap); err != nil {
+		return err
+	}
+
+	for _, table := range customerChildTables {
+		ids, err := collectIDs(tx.Query(ctx,
+			`UPDATE `+table+` SET customer_id = $1 WHERE customer_id = $2 RETURNING id`,
+			m.PrimaryID, m.MergedID))
+		if err != nil {
+			return fmt.Errorf("move %s: %w", table, err)
+		}
+		if len(ids) > 0 {
+			snap.Moved[table] = ids
+		}
+	}
+
+	// The primary gains the merged customer's owners. The merged customer
+	// keeps its own, and the copies are recorded so an unmerge removes them.
+	snap.CopiedOwners, err = collectIDs(tx.Query(ctx, `
+		INSERT INTO customer_owners (customer_id, user_id, org_id, assigned_by, assignment_rule_id, assigned_at)
+		SELECT $1, user_id, org_id, assigned_by, assignment_rule_id, assigned_at
+		FROM customer_owners WHERE customer_id = $2
+		ON CONFLICT (customer_id, user_id) DO NOTHING
+		RETURNING user_id`,
+		m.PrimaryID, m.MergedID))
+	if err != nil {
+		return fmt.Errorf("copy customer owners: %w", err)
+	}
+
+	// Tags are copied the same way.
+	rows, err = tx.Query(ctx, `
+		INSERT INTO customer_tags (customer_id, tag, org_id, created_by, created_at)
+		SELECT $1, tag, org_id, created_by, created_at
+		FROM customer_tags WHERE customer_id = $2
+		ON CONFLICT (customer_id, tag) DO NOTHING
+		RETURNING tag`,
+		m.PrimaryID, m.MergedID)
+	if err != nil {
+		return fmt.Errorf("copy customer tags: %w", err)
+	}
+	snap.CopiedTags, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("copy customer tags: %w", err)
+	}
+
+	// So are custom field values the primary has no value for.
+	snap.CopiedFields, err = collectIDs(tx.Query(ctx, `
+		INSERT INTO customer_field_values (customer_id, field_id, org_id, value_text, value_number, value_date, source, updated_by, updated_at)
+		SELECT $1, field_id, org_id, value_text, value_number, value_date, source, updated_by, updated_at
+		FROM customer_field_values WHERE customer_id = $2
+		ON CONFLICT (customer_id, field_id) DO NOTHING
+		RETURNING field_id`,
+		m.PrimaryID, m.MergedID))
+	if err != nil {
+		return fmt.Errorf("copy custom field values: %w", err)
+	}
+
+	// And a manual renewal override, unless the primary has its own.
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO customer_renewals (customer_id, org_id, renewal_date, amount_cents, currency, notes, updated_by, created_at)
+		SELECT $1, org_id, renewal_date, amount_cents, currency, notes, updated_by, created_at
+		FROM customer_renewals WHERE customer_id = $2
+		ON CONFLICT (customer_id) DO NOTHING`,
+		m.PrimaryID, m.MergedID,
+	)
+	if err != nil {
+		return fmt.Errorf("copy renewal override: %w", err)
+	}
+	snap.CopiedRenewal = tag.RowsAffected() > 0
+
+	err = tx.QueryRow(ctx, `
+		DELETE FROM health_scores h WHERE customer_id = $1 RETURNING to_jsonb(h)`, m.MergedID,
+	).Scan(&snap.HealthScore)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("remove merged health score: %w", err)
+	}
+
+	snap.Redirected, err = collectIDs(tx.Query(ctx, `
+		UPDATE customers SET merged_into_id = $1 WHERE merged_into_id = $2 RETURNING id`,
+		m.PrimaryID, m.MergedID))
+	if err != nil {
+		return fmt.Errorf("redirect merged customers: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE customers SET merged_into_id = $1, deleted_at = NOW() WHERE id = $2`,
+		m.PrimaryID, m.MergedID,
+	); err != nil {
+		return fmt.Errorf("retire merged customer: %w", err)
+	}
+
+	m.Snapshot = snap
+	err = tx.QueryRow(ctx, `
+		INSERT INTO customer_merges (org_id, primary_id, merged_id, reason, snapshot, merged_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, merged_at`,
+		m.OrgID, m.PrimaryID, m.MergedID, m.Reason, m.Snapshot, m.MergedBy,
+	).Scan(&m.ID, &m.MergedAt)
+	if err != nil {
+		return fmt.Errorf("insert customer merge: %w", err)
+	}
+	return nil
+}
+
+// Unmerge reverses m within tx: the merged customer and the primary's merged
+// fields are restored from the snapshot, the moved child rows return to the
+// merged customer with their playbook runs as they were, and the owners, tags,
+// custom field values and renewal override copied to the primary are removed.
+// Rows recorded against the primary after the merge stay. Returns false if the
+// merge was already undone.
+func (r *CustomerMergeRepository) Unmerge(ctx context.Context, tx pgx.Tx, m *CustomerMerge, unmergedBy *uuid.UUID) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE customer_merges SET unmerged_at = NOW(), unmerged_by = $2
+		WHERE id = $1 AND unmerged_at IS NULL`, m.ID, unmergedBy)
+	if err != nil {
+		return false, fmt.Errorf("mark customer merge undone: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE customers c
+		SET name = s.name, company_name = s.company_name, mrr_cents = s.mrr_cents,
+			currency = s.currency, metadata = s.metadata
+		FROM jsonb_populate_record(NULL::customers, $2) s
+		WHERE c.id = $1`, m.PrimaryID, m.Snapshot.Primary,
+	); err != nil {
+		return false, fmt.Errorf("restore primary customer: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE customers c
+		SET email = s.email, name = s.name, company_name = s.company_name, mrr_cents = s.mrr_cents,
+			currency = s.currency, metadata = s.metadata, deleted_at = s.deleted_at,
+			merged_into_id = s.merged_into_id
+		FROM jsonb_populate_record(NULL::customers, $2) s
+		WHERE c.id = $1`, m.MergedID, m.Snapshot.Merged,
+	); err != nil {
+		return false, fmt.Errorf("restore merged customer: %w", err)
+	}
+
+	for _, table := range customerChildTables {
+		ids := m.Snapshot.Moved[table]
+		if len(ids) == 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE `+table+` SET customer_id = $1 WHERE id = ANY($2)`, m.MergedID, ids,
+		); err != nil {
+			return false, fmt.Errorf("restore %s: %w", table, err)
+		}
+	}
+
+	for id, key := range m.Snapshot.ClearedTriggerKeys {
+		if _, err := tx.Exec(ctx, `UPDATE playbook_runs SET trigger_key = $2 WHERE id = $1`, id, key); err != nil {
+			return false, fmt.Errorf("restore playbook run trigger key: %w", err)
+		}
+	}
+	for _, run := range m.Snapshot.CancelledRuns {
+		if _, err := tx.Exec(ctx, `
+			UPDATE playbook_runs
+			SET status = $2, next_run_at = $3, finished_at = NULL, error = ''
+			WHERE id = $1 AND status = 'cancelled'`,
+			run.ID, run.Status, run.NextRunAt,
+		); err != nil {
+			return false, fmt.Errorf("restore cancelled playbook run: %w", err)
+		}
+	}
+
+	if len(m.Snapshot.CopiedOwners) > 0 {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM customer_owners WHERE customer_id = $1 AND user_id = ANY($2)`,
+			m.PrimaryID, m.Snapshot.CopiedOwners,
+		); err != nil {
+			return false, fmt.Errorf("remove copied customer owners: %w", err)
+		}
+	}
+	if len(m.Snapshot.CopiedTags) > 0 {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM customer_tags WHERE customer_id = $1 AND tag = ANY($2)`,
+			m.PrimaryID, m.Snapshot.CopiedTags,
+		); err != nil {
+			return false, fmt.Errorf("remove copied customer tags: %w", err)
+		}
+	}
+	if len(m.Snapshot.CopiedFields) > 0 {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM customer_field_values WHERE customer_id = $1 AND field_id = ANY($2)`,
+			m.PrimaryID, m.Snapshot.CopiedFields,
+		); err != nil {
+			return false, fmt.Errorf("remove copied custom field values: %w", err)
+		}
+	}
+	if m.Snapshot.CopiedRenewal {
+		if _, err := tx.Exec(ctx, `DELETE FROM customer_renewals WHERE customer_id = $1`, m.PrimaryID); err != nil {
+			return false, fmt.Errorf("remove copied renewal override: %w", err)
+		}
+	}
+
+	if m.Snapshot.HealthScore != nil {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO health_scores
+			SELECT * FROM jsonb_populate_record(NULL::health_scores, $1)
+			ON CONFLICT (customer_id) DO NOTHING`, m.Snapshot.HealthScore,
+		); err != nil {
+			return false, fmt.Errorf("restore health score: %w", err)
+		}
+	}
+
+	// Customers merged into the primary since then keep pointing at it
+	if len(m.Snapshot.Redirected) > 0 {
+		if _, err := tx.Exec(ctx, `
+			UPDATE customers SET merged_into_id = $1
+			WHERE id = ANY($2) AND merged_into_id = $3`,
+			m.MergedID, m.Snapshot.Redirected, m.PrimaryID,
+		); err != nil {
+			return false, fmt.Errorf("restore redirected customers: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// prepareMergedPlaybookRuns makes the merged customer's playbook runs movable
+// to the primary. A customer has at most one active run per playbook and one
+// run per trigger occurrence, so the merged customer's active runs of
+// playbooks the primary is also in are cancelled, and trigger keys the primary
+// already has are cleared from the runs that will move. Both are recorded in
+// snap so an unmerge can restore them.
+func (r *CustomerMergeRepository) prepareMergedPlaybookRuns(ctx context.Context, tx pgx.Tx, m *CustomerMerge, snap *CustomerMergeSnapshot) error {
+	rows, err := tx.Query(ctx, `
+		UPDATE playbook_runs pr
+		SET status = 'cancelled', next_run_at = NULL, finished_at = NOW(),
+			error = 'customer was merged into one already in this playbook'
+		FROM playbook_runs prev
+		WHERE prev.id = pr.id AND pr.customer_id = $2 AND pr.status IN ('running', 'waiting')
+		  AND EXISTS (
+			SELECT 1 FROM playbook_runs p
+			WHERE p.customer_id = $1 AND p.playbook_id = pr.playbook_id AND p.status IN ('running', 'waiting')
+		  )
+		RETURNING pr.id, prev.status, prev.next_run_at`,
+		m.PrimaryID, m.MergedID)
+	if err != nil {
+		return fmt.Errorf("cancel overlapping playbook runs: %w", err)
+	}
+	snap.CancelledRuns, err = pgx.CollectRows(rows, pgx.RowToStructByPos[MergedPlaybookRun])
+	if err != nil {
+		return fmt.Errorf("cancel overlapping playbook runs: %w", err)
+	}
+
+	rows, err = tx.Query(ctx, `
+		UPDATE playbook_runs pr
+		SET trigger_key = ''
+		FROM playbook_runs prev
+		WHERE prev.id = pr.id AND pr.customer_id = $2 AND pr.trigger_key <> ''
+		  AND EXISTS (
+			SELECT 1 FROM playbook_runs p
+			WHERE p.customer_id = $1 AND p.playbook_id = pr.playbook_id AND p.trigger_key = pr.trigger_key
+		  )
+		RETURNING pr.id, prev.trigger_key`,
+		m.PrimaryID, m.MergedID)
+	if err != nil {
+		return fmt.Errorf("clear overlapping playbook trigger keys: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		var key string
+		if err := rows.Scan(&id, &key); err != nil {
+			return fmt.Errorf("scan playbook trigger key: %w", err)
+		}
+		if snap.ClearedTriggerKeys == nil {
+			snap.ClearedTriggerKeys = map[uuid.UUID]string{}
+		}
+		snap.ClearedTriggerKeys[id] = key
+	}
+	return rows.Err()
+}
+
+const customerMergeColumns = `id, org_id, primary_id, merged_id, reason, snapshot, merged_by, merged_at,
+	unmerged_by, unmerged_at`
+
+func scanCustomerMerge(row pgx.Row) (*CustomerMerge, error) {
+	m := &CustomerMerge{}
+	err := row.Scan(
+		&m.ID, &m.OrgID, &m.PrimaryID, &m.MergedID, &m.Reason, &m.Snapshot, &m.MergedBy, &m.MergedAt,
+		&m.UnmergedBy, &m.UnmergedAt,
+	)
+	return m, err
+}
+
+// GetByID returns a merge within an org.
+func (r *CustomerMergeRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (*CustomerMerge, error) {
+	m, err := scanCustomerMerge(r.pool.QueryRow(ctx, `
+		SELECT `+customerMergeColumns+`
+		FROM customer_merges
+		WHERE id = $1 AND org_id = $2`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get customer merge: %w", err)
+	}
+	return m, nil
+}
+
+// ListByCustomer returns the merges a customer took part in, newest first.
+func (r *CustomerMergeRepository) ListByCustomer(ctx context.Context, orgID, customerID uuid.UUID) ([]*CustomerMerge, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+customerMergeColumns+`
+		FROM customer_merges
+		WHERE org_id = $1 AND (primary_id = $2 OR merged_id = $2)
+		ORDER BY merged_at DESC`, orgID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer merges: %w", err)
+	}
+	defer rows.Close()
+
+	var merges []*CustomerMerge
+	for rows.Next() {
+		m, err := scanCustomerMerge(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan customer merge: %w", err)
+		}
+		merges = append(merges, m)
+	}
+	return merges, rows.Err()
+}
+
+// ListUndonePairs returns the customer pairs of an org whose merge was undone,
+// keyed both ways, so automatic deduplication does not merge them again.
+func (r *CustomerMergeRepository) ListUndonePairs(ctx context.Context, orgID uuid.UUID) (map[[2]uuid.UUID]bool, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT primary_id, merged_id FROM customer_merges
+		WHERE org_id = $1 AND unmerged_at IS NOT NULL`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list undone customer merges: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := map[[2]uuid.UUID]bool{}
+	for rows.Next() {
+		var a, b uuid.UUID
+		if err := rows.Scan(&a, &b); err != nil {
+			return nil, fmt.Errorf("scan undone customer merge: %w", err)
+		}
+		pairs[[2]uuid.UUID{a, b}] = true
+		pairs[[2]uuid.UUID{b, a}] = true
+	}
+	return pairs, rows.Err()
+}
+
+func collectIDs(rows pgx.Rows, err error) ([]uuid.UUID, error) {
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
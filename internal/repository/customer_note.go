@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CustomerNote represents a customer_notes row with its author's name.
+type CustomerNote struct {
+	ID         uuid.UUID   `json:"id"`
+	OrgID      uuid.UUID   `json:"org_id"`
+	CustomerID uuid.UUID   `json:"customer_id"`
+	AuthorID   *uuid.UUID  `json:"author_id"`
+	AuthorName string      `json:"author_name"`
+	Body       string      `json:"body"`
+	Mentions   []uuid.UUID `json:"mentions"`
+	Pinned     bool        `json:"pinned"`
+	EditedAt   *time.Time  `json:"edited_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// CustomerNoteRevision is a previous body of an edited note.
+type CustomerNoteRevision struct {
+	ID           uuid.UUID  `json:"id"`
+	NoteID       uuid.UUID  `json:"note_id"`
+	Body         string     `json:"body"`
+	EditedBy     *uuid.UUID `json:"edited_by"`
+	EditedByName string     `json:"edited_by_name"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// NoteListParams holds parameters for listing a customer's notes.
+type NoteListParams struct {
+	OrgID      uuid.UUID
+	CustomerID uuid.UUID
+	Page       int
+	PerPage    int
+	PinnedOnly bool
+}
+
+// NoteListResult holds a page of notes with pagination info.
+type NoteListResult struct {
+	Notes      []*CustomerNote
+	Total      int
+	Page       int
+	PerPage    int
+	TotalPages int
+}
+
+// CustomerNoteRepository handles customer_notes database operations.
+type CustomerNoteRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCustomerNoteRepository creates a new CustomerNoteRepository.
+func NewCustomerNoteRepository(pool *pgxpool.Pool) *CustomerNoteRepository {
+	return &CustomerNoteRepository{pool: pool}
+}
+
+// userDisplayName is the SQL expression for the display name of the users row u.
+const userDisplayName = `COALESCE(NULLIF(TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')), ''), u.email, '')`
+
+const customerNoteSelect = `
+	SELECT n.id, n.org_id, n.customer_id, n.author_id, ` + userDisplayName + `,
+		n.body, n.mentions, n.pinned, n.edited_at, n.created_at, n.updated_at
+	FROM customer_notes n
+	LEFT JOIN users u ON u.id = n.author_id`
+
+func scanCustomerNote(row pgx.Row) (*CustomerNote, error) {
+	n := &CustomerNote{}
+	err := row.Scan(
+		&n.ID, &n.OrgID, &n.CustomerID, &n.AuthorID, &n.AuthorName,
+		&n.Body, &n.Mentions, &n.Pinned, &n.EditedAt, &n.CreatedAt, &n.UpdatedAt,
+	)
+	return n, err
+}
+
+// Create inserts a new note.
+func (r *CustomerNoteRepository) Create(ctx context.Context, n *CustomerNote) error {
+	if n.Mentions == nil {
+		n.Mentions = []uuid.UUID{}
+	}
+
+	query := `
+		INSERT INTO customer_notes (org_id, customer_id, author_id, body, mentions, pinned)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	return r.pool.QueryRow(ctx, query,
+		n.OrgID, n.CustomerID, n.AuthorID, n.Body, n.Mentions, n.Pinned,
+	).Scan(&n.ID, &n.CreatedAt, &n.UpdatedAt)
+}
+
+// GetByID retrieves a note of a customer, scoped to an org.
+func (r *CustomerNoteRepository) GetByID(ctx context.Context, noteID, customerID, orgID uuid.UUID) (*CustomerNote, error) {
+	n, err := scanCustomerNote(r.pool.QueryRow(ctx, customerNoteSelect+`
+		WHERE n.id = $1 AND n.customer_id = $2 AND n.org_id = $3`, noteID, customerID, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get customer note: %w", err)
+	}
+	return n, nil
+}
+
+// ListByCustomer returns a page of a customer's notes, pinned notes first and
+// then newest first.
+func (r *CustomerNoteRepository) ListByCustomer(ctx context.Context, params NoteListParams) (*NoteListResult, error) {
+	where := "n.customer_id = $1 AND n.org_id = $2"
+	if params.PinnedOnly {
+		where += " AND n.pinned"
+	}
+	args := []any{params.CustomerID, params.OrgID}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM customer_notes n WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count customer notes: %w", err)
+	}
+
+	totalPages := 0
+	if params.PerPage > 0 {
+		totalPages = (total + params.PerPage - 1) / params.PerPage
+	}
+
+	offset := (params.Page - 1) * params.PerPage
+	rows, err := r.pool.Query(ctx, customerNoteSelect+`
+		WHERE `+where+`
+		ORDER BY n.pinned DESC, n.created_at DESC
+		LIMIT $3 OFFSET $4`, append(args, params.PerPage, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("list customer notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*CustomerNote
+	for rows.Next() {
+		n, err := scanCustomerNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan customer note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &NoteListResult{
+		Notes:      notes,
+		Total:      total,
+		Page:       params.Page,
+		PerPage:    params.PerPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// UpdateBody replaces a note's body and mentions, keeping the previous body as
+// a revision edited by editedBy.
+func (r *CustomerNoteRepository) UpdateBody(ctx context.Context, noteID, orgID uuid.UUID, body string, mentions []uuid.UUID, editedBy uuid.UUID) error {
+	if mentions == nil {
+		mentions = []uuid.UUID{}
+	}
+
+	query := `
+		WITH revision AS (
+			INSERT INTO customer_note_revisions (note_id, body, edited_by)
+			SELECT id, body, $5 FROM customer_notes WHERE id = $1 AND org_id = $2
+		)
+		UPDATE customer_notes SET body = $3, mentions = $4, edited_at = NOW()
+		WHERE id = $1 AND org_id = $2`
+
+	if _, err := r.pool.Exec(ctx, query, noteID, orgID, body, mentions, editedBy); err != nil {
+		return fmt.Errorf("update customer note: %w", err)
+	}
+	return nil
+}
+
+// SetPinned pins or unpins a note.
+func (r *CustomerNoteRepository) SetPinned(ctx context.Context, noteID, orgID uuid.UUID, pinned bool) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE customer_notes SET pinned = $3 WHERE id = $1 AND org_id = $2`, noteID, orgID, pinned)
+	if err != nil {
+		return fmt.Errorf("set customer note pinned: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a note and its revisions.
+func (r *CustomerNoteRepository) Delete(ctx context.Context, noteID, orgID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM customer_notes WHERE id = $1 AND org_id = $2`, noteID, orgID)
+	if err != nil {
+		return fmt.Errorf("delete customer note: %w", err)
+	}
+	return nil
+}
+
+// ListRevisions returns the previous bodies of a note, newest first.
+func (r *CustomerNoteRepository) ListRevisions(ctx context.Context, noteID uuid.UUID) ([]*CustomerNoteRevision, error) {
+	query := `
+		SELECT v.id, v.note_id, v.body, v.edited_by, ` + userDisplayName + `, v.created_at
+		FROM customer_note_revisions v
+		LEFT JOIN users u ON u.id = v.edited_by
+		WHERE v.note_id = $1
+		ORDER BY v.created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer note revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*CustomerNoteRevision
+	for rows.Next() {
+		v := &CustomerNoteRevision{}
+		if err := rows.Scan(&v.ID, &v.NoteID, &v.Body, &v.EditedBy, &v.EditedByName, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan customer note revision: %w", err)
+		}
+		revisions = append(revisions, v)
+	}
+	return revisions, rows.Err()
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CustomerSegment selects customers by their attributes. It is used for bulk
+// owner assignment and as the conditions of assignment rules. Empty fields
+// match every customer.
+type CustomerSegment struct {
+	CustomerIDs []uuid.UUID `json:"customer_ids,omitempty"`
+	Risk        string      `json:"risk,omitempty"`
+	Source      string      `json:"source,omitempty"`
+	Search      string      `json:"search,omitempty"`
+	EmailDomain string      `json:"email_domain,omitempty"`
+	MinMRRCents *int        `json:"min_mrr_cents,omitempty"`
+	MaxMRRCents *int        `json:"max_mrr_cents,omitempty"`
+	Unowned     bool        `json:"unowned,omitempty"`
+}
+
+// where returns the SQL conditions selecting the segment's customers of org
+// $1, over customers c joined with health_scores hs, and their arguments.
+// Placeholders start at $2.
+func (s CustomerSegment) where(orgID uuid.UUID) (string, []any) {
+	where := "c.org_id = $1 AND c.deleted_at IS NULL"
+	args := []any{orgID}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where += fmt.Sprintf(" AND "+cond, len(args))
+	}
+
+	if len(s.CustomerIDs) > 0 {
+		add("c.id = ANY($%d)", s.CustomerIDs)
+	}
+	if s.Risk != "" {
+		add("hs.risk_level = $%d", s.Risk)
+	}
+	if s.Source != "" {
+		add("c.source = $%d", s.Source)
+	}
+	if s.Search != "" {
+		args = append(args, "%"+s.Search+"%")
+		where += fmt.Sprintf(" AND (c.name ILIKE $%d OR c.email ILIKE $%d)", len(args), len(args))
+	}
+	if s.EmailDomain != "" {
+		add("LOWER(c.email) LIKE $%d", "%@"+s.EmailDomain)
+	}
+	if s.MinMRRCents != nil {
+		add("c.mrr_cents >= $%d", *s.MinMRRCents)
+	}
+	if s.MaxMRRCents != nil {
+		add("c.mrr_cents <= $%d", *s.MaxMRRCents)
+	}
+	if s.Unowned {
+		where += ` AND NOT EXISTS (
+			SELECT 1 FROM customer_owners o
+			JOIN user_organizations m ON m.user_id = o.user_id AND m.org_id = o.org_id
+			WHERE o.customer_id = c.id)`
+	}
+	return where, args
+}
+
+// CustomerOwner is a user who owns a customer.
+type CustomerOwner struct {
+	UserID           uuid.UUID  `json:"user_id"`
+	Email            string     `json:"email"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	AssignedBy       *uuid.UUID `json:"assigned_by"`
+	AssignmentRuleID *uuid.UUID `json:"assignment_rule_id"`
+	AssignedAt       time.Time  `json:"assigned_at"`
+}
+
+// OwnerBookSummary summarizes the customers a member owns.
+type OwnerBookSummary struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Email         string    `json:"email"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	CustomerCount int       `json:"customer_count"`
+	MRRCents      int64     `json:"mrr_cents"`
+	AvgScore      *float64  `json:"avg_score"`
+	AtRiskCount   int       `json:"at_risk_count"`
+}
+
+// UnownedBookSummary summarizes the customers nobody owns.
+type UnownedBookSummary struct {
+	CustomerCount int   `json:"customer_count"`
+	MRRCents      int64 `json:"mrr_cents"`
+	AtRiskCount   int   `json:"at_risk_count"`
+}
+
+// CustomerOwnerRepository handles customer_owners database operations. Only
+// owners who are still members of the customer's org are returned.
+type CustomerOwnerRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCustomerOwnerRepository creates a new CustomerOwnerRepository.
+func NewCustomerOwnerRepository(pool *pgxpool.Pool) *CustomerOwnerRepository {
+	return &CustomerOwnerRepository{pool: pool}
+}
+
+// ListByCustomer returns the owners of a customer in the order they were assigned.
+func (r *CustomerOwnerRepository) ListByCustomer(ctx context.Context, customerID, orgID uuid.UUID) ([]*CustomerOwner, error) {
+	query := `
+		SELECT o.user_id, u.email, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+			o.assigned_by, o.assignment_rule_id, o.assigned_at
+		FROM customer_owners o
+		JOIN users u ON u.id = o.user_id
+		JOIN user_organizations m ON m.user_id = o.user_id AND m.org_id = o.org_id
+		WHERE o.customer_id = $1 AND o.org_id = $2 AND u.deleted_at IS NULL
+		ORDER BY o.assigned_at`
+
+	rows, err := r.pool.Query(ctx, query, customerID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer owners: %w", err)
+	}
+	defer rows.Close()
+
+	var owners []*CustomerOwner
+	for rows.Next() {
+		o := &CustomerOwner{}
+		if err := rows.Scan(
+			&o.UserID, &o.Email, &o.FirstName, &o.LastName,
+			&o.AssignedBy, &o.AssignmentRuleID, &o.AssignedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan customer owner: %w", err)
+		}
+		owners = append(owners, o)
+	}
+	return owners, rows.Err()
+}
+
+// SetOwners replaces the owners of a customer with userIDs. Owners already
+// assigned keep their original assignment.
+func (r *CustomerOwnerRepository) SetOwners(ctx context.Context, orgID, customerID uuid.UUID, userIDs []uuid.UUID, assignedBy uuid.UUID) error {
+	query := `
+		WITH removed AS (
+			DELETE FROM customer_owners
+			WHERE customer_id = $1 AND org_id = $2 AND user_id <> ALL($3)
+		)
+		INSERT INTO customer_owners (customer_id, user_id, org_id, assigned_by)
+		SELECT $1, user_id, $2, $4 FROM unnest($3::uuid[]) AS user_id
+		ON CONFLICT (customer_id, user_id) DO NOTHING`
+
+	if _, err := r.pool.Exec(ctx, query, customerID, orgID, userIDs, assignedBy); err != nil {
+		return fmt.Errorf("set customer owners: %w", err)
+	}
+	return nil
+}
+
+// AssignSegment makes userIDs owners of every customer in the segment and
+// returns how many customers matched. With replace, the customers' other
+// owners are removed.
+func (r *CustomerOwnerRepository) AssignSegment(ctx context.Context, orgID uuid.UUID, segment CustomerSegment, userIDs []uuid.UUID, assignedBy uuid.UUID, replace bool) (int, error) {
+	where, args := segment.where(orgID)
+	n := len(args)
+	args = append(args, userIDs, assignedBy, replace)
+
+	query := fmt.Sprintf(`
+		WITH targets AS (
+			SELECT c.id FROM customers c
+			LEFT JOIN health_scores hs ON hs.customer_id = c.id
+			WHERE %s
+		),
+		removed AS (
+			DELETE FROM customer_owners o USING targets t
+			WHERE $%[4]d AND o.customer_id = t.id AND o.user_id <> ALL($%[2]d)
+		),
+		inserted AS (
+			INSERT INTO customer_owners (customer_id, user_id, org_id, assigned_by)
+			SELECT t.id, u.user_id, $1, $%[3]d FROM targets t CROSS JOIN unnest($%[2]d::uuid[]) AS u (user_id)
+			ON CONFLICT (customer_id, user_id) DO NOTHING
+		)
+		SELECT COUNT(*) FROM targets`, where, n+1, n+2, n+3)
+
+	var matched int
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&matched); err != nil {
+		return 0, fmt.Errorf("assign segment owners: %w", err)
+	}
+	return matched, nil
+}
+
+// ListUnownedInSegment returns the IDs of the segment's customers that have
+// no owner, oldest first.
+func (r *CustomerOwnerRepository) ListUnownedInSegment(ctx context.Context, orgID uuid.UUID, segment CustomerSegment) ([]uuid.UUID, error) {
+	segment.Unowned = true
+	where, args := segment.where(orgID)
+
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(`
+		SELECT c.id FROM customers c
+		LEFT JOIN health_scores hs ON hs.customer_id = c.id
+		WHERE %s
+		ORDER BY c.created_at, c.id`, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("list unowned customers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan customer id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AssignByRule assigns userIDs[i] as an owner of customerIDs[i], recording
+// the assignment rule that chose them.
+func (r *CustomerOwnerRepository) AssignByRule(ctx context.Context, orgID, ruleID uuid.UUID, customerIDs, userIDs []uuid.UUID) error {
+	query := `
+		INSERT INTO customer_owners (customer_id, user_id, org_id, assignment_rule_id)
+		SELECT customer_id, user_id, $1, $2
+		FROM unnest($3::uuid[], $4::uuid[]) AS a (customer_id, user_id)
+		ON CONFLICT (customer_id, user_id) DO NOTHING`
+
+	if _, err := r.pool.Exec(ctx, query, orgID, ruleID, customerIDs, userIDs); err != nil {
+		return fmt.Errorf("assign customer owners by rule: %w", err)
+	}
+	return nil
+}
+
+// ListEmailsForCustomer returns the email addresses of a customer's owners.
+func (r *CustomerOwnerRepository) ListEmailsForCustomer(ctx context.Context, customerID uuid.UUID) ([]string, error) {
+	return r.listEmails(ctx, `o.customer_id = $1`, customerID)
+}
+
+// ListEmailsForAccount returns the email addresses of the owners of an
+// account's customers.
+func (r *CustomerOwnerRepository) ListEmailsForAccount(ctx context.Context, accountID uuid.UUID) ([]string, error) {
+	return r.listEmails(ctx, `o.customer_id IN (
+		SELECT id FROM customers WHERE account_id = $1 AND deleted_at IS NULL)`, accountID)
+}
+
+func (r *CustomerOwnerRepository) listEmails(ctx context.Context, where string, arg any) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT u.email
+		FROM customer_owners o
+		JOIN users u ON u.id = o.user_id
+		JOIN user_organizations m ON m.user_id = o.user_id AND m.org_id = o.org_id
+		WHERE `+where+` AND u.deleted_at IS NULL
+		ORDER BY u.email`, arg)
+	if err != nil {
+		return nil, fmt.Errorf("list owner emails: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("scan owner email: %w", err)
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// SummarizeByOwner returns the book of business of every org member, largest
+// MRR first.
+func (r *CustomerOwnerRepository) SummarizeByOwner(ctx context.Context, orgID uuid.UUID) ([]*OwnerBookSummary, error) {
+	query := `
+		SELECT u.id, u.email, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+			COUNT(c.id), COALESCE(SUM(c.mrr_cents), 0), AVG(hs.overall_score)::float8,
+			COUNT(c.id) FILTER (WHERE hs.risk_level = 'red')
+		FROM user_organizations m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN customer_owners o ON o.user_id = m.user_id AND o.org_id = m.org_id
+		LEFT JOIN customers c ON c.id = o.customer_id AND c.deleted_at IS NULL
+		LEFT JOIN health_scores hs ON hs.customer_id = c.id
+		WHERE m.org_id = $1 AND u.deleted_at IS NULL
+		GROUP BY u.id, u.email, u.first_name, u.last_name
+		ORDER BY COALESCE(SUM(c.mrr_cents), 0) DESC, u.email`
+
+	rows, err := r.pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("summarize customer owners: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*OwnerBookSummary
+	for rows.Next() {
+		s := &OwnerBookSummary{}
+		if err := rows.Scan(
+			&s.UserID, &s.Email, &s.FirstName, &s.LastName,
+			&s.CustomerCount, &s.MRRCents, &s.AvgScore, &s.AtRiskCount,
+		); err != nil {
+			return nil, fmt.Errorf("scan owner summary: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
+
+// SummarizeUnowned summarizes an org's customers that have no owner.
+func (r *CustomerOwnerRepository) SummarizeUnowned(ctx context.Context, orgID uuid.UUID) (*UnownedBookSummary, error) {
+	where, args := CustomerSegment{Unowned: true}.where(orgID)
+
+	s := &UnownedBookSummary{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(c.id), COALESCE(SUM(c.mrr_cents), 0), COUNT(c.id) FILTER (WHERE hs.risk_level = 'red')
+		FROM customers c
+		LEFT JOIN health_scores hs ON hs.customer_id = c.id
+		WHERE `+where, args...).Scan(&s.CustomerCount, &s.MRRCents, &s.AtRiskCount)
+	if err != nil {
+		return nil, fmt.Errorf("summarize unowned customers: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TagCount is a tag in use in an org and how many customers carry it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// CustomerTagRepository handles customer_tags database operations.
+type CustomerTagRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCustomerTagRepository creates a new CustomerTagRepository.
+func NewCustomerTagRepository(pool *pgxpool.Pool) *CustomerTagRepository {
+	return &CustomerTagRepository{pool: pool}
+}
+
+// ListByCustomer returns a customer's tags in alphabetical order.
+func (r *CustomerTagRepository) ListByCustomer(ctx context.Context, customerID, orgID uuid.UUID) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT tag FROM customer_tags
+		WHERE customer_id = $1 AND org_id = $2
+		ORDER BY tag`, customerID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list customer tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("scan customer tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// Add tags a customer. Tags the customer already has are left as they are.
+func (r *CustomerTagRepository) Add(ctx context.Context, orgID, customerID uuid.UUID, tags []string, createdBy uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO customer_tags (customer_id, tag, org_id, created_by)
+		SELECT $1, tag, $2, $4 FROM unnest($3::text[]) AS tag
+		ON CONFLICT (customer_id, tag) DO NOTHING`,
+		customerID, orgID, tags, createdBy)
+	if err != nil {
+		return fmt.Errorf("add customer tags: %w", err)
+	}
+	return nil
+}
+
+// Set replaces a customer's tags with tags.
+func (r *CustomerTagRepository) Set(ctx context.Context, orgID, customerID uuid.UUID, tags []string, createdBy uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		WITH removed AS (
+			DELETE FROM customer_tags
+			WHERE customer_id = $1 AND org_id = $2 AND tag <> ALL($3)
+		)
+		INSERT INTO customer_tags (customer_id, tag, org_id, created_by)
+		SELECT $1, tag, $2, $4 FROM unnest($3::text[]) AS tag
+		ON CONFLICT (customer_id, tag) DO NOTHING`,
+		customerID, orgID, tags, createdBy)
+	if err != nil {
+		return fmt.Errorf("set customer tags: %w", err)
+	}
+	return nil
+}
+
+// Remove removes a tag from a customer.
+func (r *CustomerTagRepository) Remove(ctx context.Context, orgID, customerID uuid.UUID, tag string) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM customer_tags WHERE customer_id = $1 AND org_id = $2 AND tag = $3`,
+		customerID, orgID, tag)
+	if err != nil {
+		return fmt.Errorf("remove customer tag: %w", err)
+	}
+	return nil
+}
+
+// ListByOrg returns the tags used on an org's customers, most used first.
+func (r *CustomerTagRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]TagCount, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT t.tag, COUNT(*)
+		FROM customer_tags t
+		JOIN customers c ON c.id = t.customer_id AND c.deleted_at IS NULL
+		WHERE t.org_id = $1
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list org tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []TagCount{}
+	for rows.Next() {
+		var t TagCount
+		if err := rows.Scan(&t.Tag, &t.Count); err != nil {
+			return nil, fmt.Errorf("scan org tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DigestHistory represents a digest_history row.
+type DigestHistory struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	OrgID         uuid.UUID  `json:"org_id"`
+	Frequency     string     `json:"frequency"`
+	PeriodStart   time.Time  `json:"period_start"`
+	PeriodEnd     time.Time  `json:"period_end"`
+	Status        string     `json:"status"` // sent, failed, pending
+	SendGridMsgID string     `json:"sendgrid_message_id,omitempty"`
+	ErrorMessage  string     `json:"error_message,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	ClaimedAt     time.Time  `json:"claimed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// DigestHistoryRepository handles digest_history database operations.
+type DigestHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDigestHistoryRepository creates a new DigestHistoryRepository.
+func NewDigestHistoryRepository(pool *pgxpool.Pool) *DigestHistoryRepository {
+	return &DigestHistoryRepository{pool: pool}
+}
+
+// Claim records a pending digest for a user and period. It returns false when the
+// digest for that period was already sent or is in flight. Failed digests, and
+// pending ones claimed longer than staleAfter ago (e.g. by a crashed worker),
+// are re-claimed.
+func (r *DigestHistoryRepository) Claim(ctx context.Context, h *DigestHistory, staleAfter time.Duration) (bool, error) {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO digest_history (user_id, org_id, frequency, period_start, period_end, status)
+		VALUES ($1, $2, $3, $4, $5, 'pending')
+		ON CONFLICT (user_id, org_id, frequency, period_start) DO UPDATE SET
+			status = 'pending',
+			error_message = NULL,
+			claimed_at = NOW()
+		WHERE digest_history.status = 'failed'
+		   OR (digest_history.status = 'pending' AND digest_history.claimed_at < $6)
+		RETURNING id, status, claimed_at, created_at
+	`, h.UserID, h.OrgID, h.Frequency, h.PeriodStart, h.PeriodEnd, time.Now().Add(-staleAfter),
+	).Scan(&h.ID, &h.Status, &h.ClaimedAt, &h.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim digest: %w", err)
+	}
+	return true, nil
+}
+
+// MarkSent marks a digest as delivered.
+func (r *DigestHistoryRepository) MarkSent(ctx context.Context, id uuid.UUID, msgID string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE digest_history
+		SET status = 'sent', sent_at = NOW(), sendgrid_message_id = NULLIF($1, '')
+		WHERE id = $2
+	`, msgID, id)
+	if err != nil {
+		return fmt.Errorf("mark digest sent: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed marks a digest as failed so a later run can retry it.
+func (r *DigestHistoryRepository) MarkFailed(ctx context.Context, id uuid.UUID, errorMsg string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE digest_history SET status = 'failed', error_message = $1 WHERE id = $2
+	`, errorMsg, id)
+	if err != nil {
+		return fmt.Errorf("mark digest failed: %w", err)
+	}
+	return nil
+}
@@ -203,6 +203,84 @@ func (r *HealthScoreRepository) GetHistory(ctx context.Context, customerID uuid.
 	return scores, rows.Err()
 }
 
+// ListHistoryByOrgRange retrieves score history for an org between from and to, ordered by customer and calculated_at ASC.
+func (r *HealthScoreRepository) ListHistoryByOrgRange(ctx context.Context, orgID uuid.UUID, from, to time.Time) ([]*HealthScore, error) {
+	query := `
+		SELECT id, org_id, customer_id, overall_score, risk_level, factors, calculated_at, created_at, created_at
+		FROM health_score_history
+		WHERE org_id = $1 AND calculated_at >= $2 AND calculated_at <= $3
+		ORDER BY customer_id, calculated_at ASC`
+
+	rows, err := r.pool.Query(ctx, query, orgID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list history by org range: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []*HealthScore
+	for rows.Next() {
+		hs := &HealthScore{}
+		var factorsJSON []byte
+		if err := rows.Scan(
+			&hs.ID, &hs.OrgID, &hs.CustomerID, &hs.OverallScore, &hs.RiskLevel,
+			&factorsJSON, &hs.CalculatedAt, &hs.CreatedAt, &hs.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan history: %w", err)
+		}
+		if err := json.Unmarshal(factorsJSON, &hs.Factors); err != nil {
+			return nil, fmt.Errorf("unmarshal factors: %w", err)
+		}
+		scores = append(scores, hs)
+	}
+	return scores, rows.Err()
+}
+
+// ScoreChange holds a customer's score at the start and end of a period.
+type ScoreChange struct {
+	CustomerID   uuid.UUID
+	CustomerName string
+	OldScore     int
+	NewScore     int
+}
+
+// ListScoreChanges returns customers whose latest historical score at to differs from
+// their latest historical score at from.
+func (r *HealthScoreRepository) ListScoreChanges(ctx context.Context, orgID uuid.UUID, from, to time.Time) ([]ScoreChange, error) {
+	query := `
+		WITH start_scores AS (
+			SELECT DISTINCT ON (customer_id) customer_id, overall_score
+			FROM health_score_history
+			WHERE org_id = $1 AND calculated_at <= $2
+			ORDER BY customer_id, calculated_at DESC
+		), end_scores AS (
+			SELECT DISTINCT ON (customer_id) customer_id, overall_score
+			FROM health_score_history
+			WHERE org_id = $1 AND calculated_at <= $3
+			ORDER BY customer_id, calculated_at DESC
+		)
+		SELECT e.customer_id, COALESCE(c.name, ''), s.overall_score, e.overall_score
+		FROM end_scores e
+		JOIN start_scores s ON s.customer_id = e.customer_id
+		JOIN customers c ON c.id = e.customer_id AND c.deleted_at IS NULL
+		WHERE e.overall_score <> s.overall_score`
+
+	rows, err := r.pool.Query(ctx, query, orgID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list score changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []ScoreChange
+	for rows.Next() {
+		var c ScoreChange
+		if err := rows.Scan(&c.CustomerID, &c.CustomerName, &c.OldScore, &c.NewScore); err != nil {
+			return nil, fmt.Errorf("scan score change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
 // GetScoreAtTime retrieves the closest historical score for a customer at or before the given time.
 func (r *HealthScoreRepository) GetScoreAtTime(ctx context.Context, customerID, orgID uuid.UUID, at time.Time) (*HealthScore, error) {
 	query := `
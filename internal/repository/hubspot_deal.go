@@ -138,3 +138,12 @@ func (r *HubSpotDealRepository) CountByOrgID(ctx context.Context, orgID uuid.UUI
 	}
 	return count, nil
 }
+
+// DeleteByHubSpotID removes a HubSpot deal by (org_id, hubspot_deal_id).
+func (r *HubSpotDealRepository) DeleteByHubSpotID(ctx context.Context, orgID uuid.UUID, hubspotDealID string) error {
+	query := `DELETE FROM hubspot_deals WHERE org_id = $1 AND hubspot_deal_id = $2`
+	if _, err := r.pool.Exec(ctx, query, orgID, hubspotDealID); err != nil {
+		return fmt.Errorf("delete hubspot deal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdentitySignal is one piece of evidence that two customers are the same.
+type IdentitySignal struct {
+	Signal string `json:"signal"` // external_id, email, email_domain, company_name
+	Score  int    `json:"score"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// IdentityMatchCustomer summarizes one side of a candidate pair for review.
+type IdentityMatchCustomer struct {
+	ID          uuid.UUID `json:"id"`
+	Source      string    `json:"source"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	CompanyName string    `json:"company_name"`
+}
+
+// IdentityMatch represents an identity_matches row.
+type IdentityMatch struct {
+	ID          uuid.UUID              `json:"id"`
+	OrgID       uuid.UUID              `json:"org_id"`
+	CustomerID  uuid.UUID              `json:"customer_id"`
+	CandidateID uuid.UUID              `json:"candidate_id"`
+	Score       int                    `json:"score"`
+	Signals     []IdentitySignal       `json:"signals"`
+	Status      string                 `json:"status"` // pending, approved, rejected
+	MergeID     *uuid.UUID             `json:"merge_id,omitempty"`
+	DecidedBy   *uuid.UUID             `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time             `json:"decided_at,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	Customer    *IdentityMatchCustomer `json:"customer,omitempty"`
+	Candidate   *IdentityMatchCustomer `json:"candidate,omitempty"`
+}
+
+// IdentityMatchRepository handles identity_matches database operations.
+type IdentityMatchRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewIdentityMatchRepository creates a new IdentityMatchRepository.
+func NewIdentityMatchRepository(pool *pgxpool.Pool) *IdentityMatchRepository {
+	return &IdentityMatchRepository{pool: pool}
+}
+
+// UpsertPending queues a candidate pair for review, refreshing its score if it
+// is already pending. Decided pairs are left as they are. m.CustomerID must be
+// the lower of the two IDs.
+func (r *IdentityMatchRepository) UpsertPending(ctx context.Context, m *IdentityMatch) error {
+	if m.Signals == nil {
+		m.Signals = []IdentitySignal{}
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO identity_matches (org_id, customer_id, candidate_id, score, signals)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (org_id, customer_id, candidate_id) DO UPDATE SET
+			score = EXCLUDED.score,
+			signals = EXCLUDED.signals,
+			updated_at = NOW()
+		WHERE identity_matches.status = 'pending'`,
+		m.OrgID, m.CustomerID, m.CandidateID, m.Score, m.Signals)
+	if err != nil {
+		return fmt.Errorf("upsert identity match: %w", err)
+	}
+	return nil
+}
+
+// ListRejectedPairs returns the customer pairs of an org an admin rejected,
+// keyed both ways.
+func (r *IdentityMatchRepository) ListRejectedPairs(ctx context.Context, orgID uuid.UUID) (map[[2]uuid.UUID]bool, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT customer_id, candidate_id FROM identity_matches
+		WHERE org_id = $1 AND status = 'rejected'`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list rejected identity matches: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := map[[2]uuid.UUID]bool{}
+	for rows.Next() {
+		var a, b uuid.UUID
+		if err := rows.Scan(&a, &b); err != nil {
+			return nil, fmt.Errorf("scan rejected identity match: %w", err)
+		}
+		pairs[[2]uuid.UUID{a, b}] = true
+		pairs[[2]uuid.UUID{b, a}] = true
+	}
+	return pairs, rows.Err()
+}
+
+const identityMatchSelect = `
+	SELECT m.id, m.org_id, m.customer_id, m.candidate_id, m.score, m.signals, m.status,
+		m.merge_id, m.decided_by, m.decided_at, m.created_at, m.updated_at,
+		a.source, COALESCE(a.email, ''), COALESCE(a.name, ''), COALESCE(a.company_name, ''),
+		b.source, COALESCE(b.email, ''), COALESCE(b.name, ''), COALESCE(b.company_name, '')
+	FROM identity_matches m
+	JOIN customers a ON a.id = m.customer_id
+	JOIN customers b ON b.id = m.candidate_id`
+
+func scanIdentityMatch(row pgx.Row) (*IdentityMatch, error) {
+	m := &IdentityMatch{Customer: &IdentityMatchCustomer{}, Candidate: &IdentityMatchCustomer{}}
+	err := row.Scan(
+		&m.ID, &m.OrgID, &m.CustomerID, &m.CandidateID, &m.Score, &m.Signals, &m.Status,
+		&m.MergeID, &m.DecidedBy, &m.DecidedAt, &m.CreatedAt, &m.UpdatedAt,
+		&m.Customer.Source, &m.Customer.Email, &m.Customer.Name, &m.Customer.CompanyName,
+		&m.Candidate.Source, &m.Candidate.Email, &m.Candidate.Name, &m.Candidate.CompanyName,
+	)
+	m.Customer.ID = m.CustomerID
+	m.Candidate.ID = m.CandidateID
+	return m, err
+}
+
+// GetByID returns an identity match within an org.
+func (r *IdentityMatchRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (*IdentityMatch, error) {
+	m, err := scanIdentityMatch(r.pool.QueryRow(ctx, identityMatchSelect+`
+		WHERE m.id = $1 AND m.org_id = $2`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get identity match: %w", err)
+	}
+	return m, nil
+}
+
+// ListByOrg returns an org's identity matches with a status, highest score
+// first, with the total count. Pending pairs where either customer has since
+// been merged or deleted are left out.
+func (r *IdentityMatchRepository) ListByOrg(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*IdentityMatch, int, error) {
+	where := `
+		WHERE m.org_id = $1 AND m.status = $2
+			AND ($2 <> 'pending' OR (a.deleted_at IS NULL AND b.deleted_at IS NULL))`
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM identity_matches m
+		JOIN customers a ON a.id = m.customer_id
+		JOIN customers b ON b.id = m.candidate_id`+where, orgID, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count identity matches: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, identityMatchSelect+where+`
+		ORDER BY m.score DESC, m.created_at
+		LIMIT $3 OFFSET $4`, orgID, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list identity matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*IdentityMatch
+	for rows.Next() {
+		m, err := scanIdentityMatch(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan identity match: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, total, rows.Err()
+}
+
+const decideIdentityMatchQuery = `
+	UPDATE identity_matches
+	SET status = $3, merge_id = $4, decided_by = $5, decided_at = NOW(), updated_at = NOW()
+	WHERE id = $1 AND org_id = $2 AND status = 'pending'
+	RETURNING decided_at, updated_at`
+
+// Decide records an admin's decision on a pending match. It returns false if
+// the match was no longer pending.
+func (r *IdentityMatchRepository) Decide(ctx context.Context, m *IdentityMatch) (bool, error) {
+	return scanIdentityDecision(r.pool.QueryRow(ctx, decideIdentityMatchQuery,
+		m.ID, m.OrgID, m.Status, m.MergeID, m.DecidedBy), m)
+}
+
+// DecideTx is Decide within a transaction.
+func (r *IdentityMatchRepository) DecideTx(ctx context.Context, tx pgx.Tx, m *IdentityMatch) (bool, error) {
+	return scanIdentityDecision(tx.QueryRow(ctx, decideIdentityMatchQuery,
+		m.ID, m.OrgID, m.Status, m.MergeID, m.DecidedBy), m)
+}
+
+func scanIdentityDecision(row pgx.Row, m *IdentityMatch) (bool, error) {
+	err := row.Scan(&m.DecidedAt, &m.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("decide identity match: %w", err)
+	}
+	return true, nil
+}
@@ -169,6 +169,97 @@ func (r *IntegrationConnectionRepository) ListActiveByProvider(ctx context.Conte
 	return conns, rows.Err()
 }
 
+// ListExpiringTokens returns active connections for a provider whose access
+// token expires before the given time and can be refreshed.
+func (r *IntegrationConnectionRepository) ListExpiringTokens(ctx context.Context, provider string, before time.Time) ([]*IntegrationConnection, error) {
+	query := `
+		SELECT id, org_id, provider, status, access_token_encrypted, refresh_token_encrypted,
+			token_expires_at, external_account_id, scopes, COALESCE(metadata, '{}'),
+			last_sync_at, COALESCE(last_sync_error, ''), created_at, updated_at
+		FROM integration_connections
+		WHERE provider = $1 AND status = 'active'
+			AND refresh_token_encrypted IS NOT NULL AND token_expires_at < $2
+		ORDER BY token_expires_at`
+
+	rows, err := r.pool.Query(ctx, query, provider, before)
+	if err != nil {
+		return nil, fmt.Errorf("list expiring tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var conns []*IntegrationConnection
+	for rows.Next() {
+		c := &IntegrationConnection{}
+		if err := rows.Scan(
+			&c.ID, &c.OrgID, &c.Provider, &c.Status, &c.AccessTokenEncrypted, &c.RefreshTokenEncrypted,
+			&c.TokenExpiresAt, &c.ExternalAccountID, &c.Scopes, &c.Metadata,
+			&c.LastSyncAt, &c.LastSyncError, &c.CreatedAt, &c.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan connection: %w", err)
+		}
+		conns = append(conns, c)
+	}
+	return conns, rows.Err()
+}
+
+// MarkNeedsReauth moves a connection to needs_reauth and records why. It
+// reports false if the connection was already waiting for reauthorization.
+func (r *IntegrationConnectionRepository) MarkNeedsReauth(ctx context.Context, orgID uuid.UUID, provider, reason string) (bool, error) {
+	query := `
+		UPDATE integration_connections
+		SET status = 'needs_reauth', last_sync_error = $3
+		WHERE org_id = $1 AND provider = $2 AND status <> 'needs_reauth'`
+	tag, err := r.pool.Exec(ctx, query, orgID, provider, reason)
+	if err != nil {
+		return false, fmt.Errorf("mark needs reauth: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListTokensAfter returns up to limit connections with stored tokens, ordered
+// by ID and starting after afterID, for walking the table in batches.
+func (r *IntegrationConnectionRepository) ListTokensAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]*IntegrationConnection, error) {
+	query := `
+		SELECT id, org_id, provider, access_token_encrypted, refresh_token_encrypted
+		FROM integration_connections
+		WHERE id > $1 AND (access_token_encrypted IS NOT NULL OR refresh_token_encrypted IS NOT NULL)
+		ORDER BY id
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list connection tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var conns []*IntegrationConnection
+	for rows.Next() {
+		c := &IntegrationConnection{}
+		if err := rows.Scan(&c.ID, &c.OrgID, &c.Provider, &c.AccessTokenEncrypted, &c.RefreshTokenEncrypted); err != nil {
+			return nil, fmt.Errorf("scan connection tokens: %w", err)
+		}
+		conns = append(conns, c)
+	}
+	return conns, rows.Err()
+}
+
+// SwapTokens replaces a connection's encrypted tokens only if they still hold
+// the expected values, so a token refreshed concurrently is never overwritten.
+// It reports whether the row was updated.
+func (r *IntegrationConnectionRepository) SwapTokens(ctx context.Context, id uuid.UUID, oldAccess, oldRefresh, newAccess, newRefresh []byte) (bool, error) {
+	query := `
+		UPDATE integration_connections
+		SET access_token_encrypted = $4, refresh_token_encrypted = $5
+		WHERE id = $1
+			AND access_token_encrypted IS NOT DISTINCT FROM $2
+			AND refresh_token_encrypted IS NOT DISTINCT FROM $3`
+	tag, err := r.pool.Exec(ctx, query, id, oldAccess, oldRefresh, newAccess, newRefresh)
+	if err != nil {
+		return false, fmt.Errorf("swap connection tokens: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 // Delete removes a connection.
 func (r *IntegrationConnectionRepository) Delete(ctx context.Context, orgID uuid.UUID, provider string) error {
 	query := `DELETE FROM integration_connections WHERE org_id = $1 AND provider = $2`
@@ -157,3 +157,12 @@ func (r *IntercomContactRepository) LinkCustomer(ctx context.Context, id, custom
 	}
 	return nil
 }
+
+// DeleteByIntercomID removes an Intercom contact by (org_id, intercom_contact_id).
+func (r *IntercomContactRepository) DeleteByIntercomID(ctx context.Context, orgID uuid.UUID, intercomContactID string) error {
+	query := `DELETE FROM intercom_contacts WHERE org_id = $1 AND intercom_contact_id = $2`
+	if _, err := r.pool.Exec(ctx, query, orgID, intercomContactID); err != nil {
+		return fmt.Errorf("delete intercom contact: %w", err)
+	}
+	return nil
+}
@@ -13,16 +13,28 @@ import (
 
 // NotificationPreference represents a user's notification preferences for an org.
 type NotificationPreference struct {
-	ID              uuid.UUID   `json:"id"`
-	UserID          uuid.UUID   `json:"user_id"`
-	OrgID           uuid.UUID   `json:"org_id"`
-	EmailEnabled    bool        `json:"email_enabled"`
-	InAppEnabled    bool        `json:"in_app_enabled"`
-	DigestEnabled   bool        `json:"digest_enabled"`
-	DigestFrequency string      `json:"digest_frequency"`
-	MutedRuleIDs    []uuid.UUID `json:"muted_rule_ids"`
-	CreatedAt       time.Time   `json:"created_at"`
-	UpdatedAt       time.Time   `json:"updated_at"`
+	ID                     uuid.UUID   `json:"id"`
+	UserID                 uuid.UUID   `json:"user_id"`
+	OrgID                  uuid.UUID   `json:"org_id"`
+	EmailEnabled           bool        `json:"email_enabled"`
+	InAppEnabled           bool        `json:"in_app_enabled"`
+	DigestEnabled          bool        `json:"digest_enabled"`
+	DigestFrequency        string      `json:"digest_frequency"`
+	MutedRuleIDs           []uuid.UUID `json:"muted_rule_ids"`
+	Timezone               string      `json:"timezone"`
+	QuietHours             QuietHours  `json:"quiet_hours"`
+	BusinessDaysOnly       bool        `json:"business_days_only"`
+	UrgentBypassQuietHours bool        `json:"urgent_bypass_quiet_hours"`
+	CreatedAt              time.Time   `json:"created_at"`
+	UpdatedAt              time.Time   `json:"updated_at"`
+}
+
+// QuietHours is a daily local-time window (HH:MM) during which non-urgent email is held back.
+// Windows may wrap past midnight, e.g. 22:00–08:00.
+type QuietHours struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
 }
 
 // NotificationPreferenceRepository handles notification_preferences database operations.
@@ -39,7 +51,9 @@ func NewNotificationPreferenceRepository(pool *pgxpool.Pool) *NotificationPrefer
 // Returns a default preference if none exists.
 func (r *NotificationPreferenceRepository) GetByUserAndOrg(ctx context.Context, userID, orgID uuid.UUID) (*NotificationPreference, error) {
 	query := `
-		SELECT id, user_id, org_id, email_enabled, in_app_enabled, digest_enabled, digest_frequency, muted_rule_ids, created_at, updated_at
+		SELECT id, user_id, org_id, email_enabled, in_app_enabled, digest_enabled, digest_frequency, muted_rule_ids, timezone,
+			quiet_hours_enabled, quiet_hours_start, quiet_hours_end, business_days_only, urgent_bypass_quiet_hours,
+			created_at, updated_at
 		FROM notification_preferences
 		WHERE user_id = $1 AND org_id = $2`
 
@@ -48,19 +62,24 @@ func (r *NotificationPreferenceRepository) GetByUserAndOrg(ctx context.Context,
 		&pref.ID, &pref.UserID, &pref.OrgID,
 		&pref.EmailEnabled, &pref.InAppEnabled,
 		&pref.DigestEnabled, &pref.DigestFrequency,
-		&pref.MutedRuleIDs,
+		&pref.MutedRuleIDs, &pref.Timezone,
+		&pref.QuietHours.Enabled, &pref.QuietHours.Start, &pref.QuietHours.End,
+		&pref.BusinessDaysOnly, &pref.UrgentBypassQuietHours,
 		&pref.CreatedAt, &pref.UpdatedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		// Return defaults
 		return &NotificationPreference{
-			UserID:          userID,
-			OrgID:           orgID,
-			EmailEnabled:    true,
-			InAppEnabled:    true,
-			DigestEnabled:   false,
-			DigestFrequency: "weekly",
-			MutedRuleIDs:    []uuid.UUID{},
+			UserID:                 userID,
+			OrgID:                  orgID,
+			EmailEnabled:           true,
+			InAppEnabled:           true,
+			DigestEnabled:          false,
+			DigestFrequency:        "weekly",
+			MutedRuleIDs:           []uuid.UUID{},
+			Timezone:               "UTC",
+			QuietHours:             QuietHours{Start: "22:00", End: "08:00"},
+			UrgentBypassQuietHours: true,
 		}, nil
 	}
 	if err != nil {
@@ -72,20 +91,68 @@ func (r *NotificationPreferenceRepository) GetByUserAndOrg(ctx context.Context,
 // Upsert creates or updates notification preferences.
 func (r *NotificationPreferenceRepository) Upsert(ctx context.Context, pref *NotificationPreference) error {
 	query := `
-		INSERT INTO notification_preferences (user_id, org_id, email_enabled, in_app_enabled, digest_enabled, digest_frequency, muted_rule_ids)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO notification_preferences (user_id, org_id, email_enabled, in_app_enabled, digest_enabled, digest_frequency, muted_rule_ids, timezone,
+			quiet_hours_enabled, quiet_hours_start, quiet_hours_end, business_days_only, urgent_bypass_quiet_hours)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (user_id, org_id) DO UPDATE SET
 			email_enabled = EXCLUDED.email_enabled,
 			in_app_enabled = EXCLUDED.in_app_enabled,
 			digest_enabled = EXCLUDED.digest_enabled,
 			digest_frequency = EXCLUDED.digest_frequency,
-			muted_rule_ids = EXCLUDED.muted_rule_ids
+			muted_rule_ids = EXCLUDED.muted_rule_ids,
+			timezone = EXCLUDED.timezone,
+			quiet_hours_enabled = EXCLUDED.quiet_hours_enabled,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			business_days_only = EXCLUDED.business_days_only,
+			urgent_bypass_quiet_hours = EXCLUDED.urgent_bypass_quiet_hours
 		RETURNING id, created_at, updated_at`
 
 	return r.pool.QueryRow(ctx, query,
 		pref.UserID, pref.OrgID,
 		pref.EmailEnabled, pref.InAppEnabled,
 		pref.DigestEnabled, pref.DigestFrequency,
-		pref.MutedRuleIDs,
+		pref.MutedRuleIDs, pref.Timezone,
+		pref.QuietHours.Enabled, pref.QuietHours.Start, pref.QuietHours.End,
+		pref.BusinessDaysOnly, pref.UrgentBypassQuietHours,
 	).Scan(&pref.ID, &pref.CreatedAt, &pref.UpdatedAt)
 }
+
+// ListDigestEnabled returns preferences for all users who opted into digests
+// and are still members of the org.
+func (r *NotificationPreferenceRepository) ListDigestEnabled(ctx context.Context) ([]*NotificationPreference, error) {
+	query := `
+		SELECT np.id, np.user_id, np.org_id, np.email_enabled, np.in_app_enabled, np.digest_enabled,
+			np.digest_frequency, np.muted_rule_ids, np.timezone,
+			np.quiet_hours_enabled, np.quiet_hours_start, np.quiet_hours_end, np.business_days_only, np.urgent_bypass_quiet_hours,
+			np.created_at, np.updated_at
+		FROM notification_preferences np
+		JOIN user_organizations uo ON uo.user_id = np.user_id AND uo.org_id = np.org_id
+		JOIN users u ON u.id = np.user_id AND u.deleted_at IS NULL
+		JOIN organizations o ON o.id = np.org_id AND o.deleted_at IS NULL
+		WHERE np.digest_enabled = true AND np.email_enabled = true`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list digest preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var prefs []*NotificationPreference
+	for rows.Next() {
+		pref := &NotificationPreference{}
+		if err := rows.Scan(
+			&pref.ID, &pref.UserID, &pref.OrgID,
+			&pref.EmailEnabled, &pref.InAppEnabled,
+			&pref.DigestEnabled, &pref.DigestFrequency,
+			&pref.MutedRuleIDs, &pref.Timezone,
+			&pref.QuietHours.Enabled, &pref.QuietHours.Start, &pref.QuietHours.End,
+			&pref.BusinessDaysOnly, &pref.UrgentBypassQuietHours,
+			&pref.CreatedAt, &pref.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan notification preference: %w", err)
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, rows.Err()
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Playbook trigger types.
+const (
+	PlaybookTriggerRiskChange    = "risk_change"
+	PlaybookTriggerScoreBelow    = "score_below"
+	PlaybookTriggerNewCustomer   = "new_customer"
+	PlaybookTriggerPaymentFailed = "payment_failed"
+	PlaybookTriggerRenewalDue    = "renewal_due"
+)
+
+// Playbook step types.
+const (
+	PlaybookStepCreateTask = "create_task"
+	PlaybookStepSendEmail  = "send_email"
+	PlaybookStepWait       = "wait"
+	PlaybookStepBranch     = "branch"
+	PlaybookStepWebhook    = "webhook"
+)
+
+// PlaybookTrigger decides which customers are enrolled in a playbook.
+// ReentryDays keeps a customer out of the playbook for that many days after
+// their previous run started.
+type PlaybookTrigger struct {
+	Type        string `json:"type"`
+	From        string `json:"from,omitempty"`      // risk_change: previous risk level
+	To          string `json:"to,omitempty"`        // risk_change: new risk level
+	Threshold   int    `json:"threshold,omitempty"` // score_below
+	Days        int    `json:"days,omitempty"`      // renewal_due: days before the renewal
+	ReentryDays int    `json:"reentry_days"`
+}
+
+// PlaybookStep is one step of a playbook. Only the fields of its type are set.
+type PlaybookStep struct {
+	Type string `json:"type"`
+
+	// create_task
+	Title         string     `json:"title,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	DueInDays     int        `json:"due_in_days,omitempty"`
+	AssigneeID    *uuid.UUID `json:"assignee_id,omitempty"`
+	AssignToOwner bool       `json:"assign_to_owner,omitempty"`
+
+	// send_email: To holds addresses, "customer" and "customer_owner"
+	To      []string `json:"to,omitempty"`
+	Subject string   `json:"subject,omitempty"`
+	Body    string   `json:"body,omitempty"`
+
+	// wait
+	Days int `json:"days,omitempty"`
+
+	// branch: continue at ThenStep when the current score is below
+	// ScoreBelow, otherwise at ElseStep. An omitted target is the next step.
+	ScoreBelow *int `json:"score_below,omitempty"`
+	ThenStep   *int `json:"then_step,omitempty"`
+	ElseStep   *int `json:"else_step,omitempty"`
+
+	// webhook
+	URL string `json:"url,omitempty"`
+}
+
+// Playbook represents a playbooks row. WebhookSecret signs its webhook
+// steps' requests and is only shown to admins.
+type Playbook struct {
+	ID               uuid.UUID       `json:"id"`
+	OrgID            uuid.UUID       `json:"org_id"`
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	Trigger          PlaybookTrigger `json:"trigger"`
+	Steps            []PlaybookStep  `json:"steps"`
+	IsActive         bool            `json:"is_active"`
+	TriggerCheckedAt time.Time       `json:"-"`
+	WebhookSecret    string          `json:"-"`
+	CreatedBy        *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// PlaybookCandidate is a customer a playbook trigger matched. Key identifies
+// the trigger occurrence so it enrolls the customer only once.
+type PlaybookCandidate struct {
+	CustomerID uuid.UUID
+	Key        string
+	Data       map[string]any
+}
+
+// PlaybookRepository handles playbooks database operations.
+type PlaybookRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPlaybookRepository creates a new PlaybookRepository.
+func NewPlaybookRepository(pool *pgxpool.Pool) *PlaybookRepository {
+	return &PlaybookRepository{pool: pool}
+}
+
+const playbookSelect = `
+	SELECT id, org_id, name, description, trigger, steps, is_active, trigger_checked_at,
+		webhook_secret, created_by, created_at, updated_at
+	FROM playbooks`
+
+func scanPlaybook(row pgx.Row) (*Playbook, error) {
+	p := &Playbook{}
+	err := row.Scan(
+		&p.ID, &p.OrgID, &p.Name, &p.Description, &p.Trigger, &p.Steps, &p.IsActive, &p.TriggerCheckedAt,
+		&p.WebhookSecret, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+	)
+	return p, err
+}
+
+// List returns an org's playbooks, newest first.
+func (r *PlaybookRepository) List(ctx context.Context, orgID uuid.UUID, activeOnly bool) ([]*Playbook, error) {
+	query := playbookSelect + ` WHERE org_id = $1`
+	if activeOnly {
+		query += ` AND is_active`
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list playbooks: %w", err)
+	}
+	defer rows.Close()
+
+	var playbooks []*Playbook
+	for rows.Next() {
+		p, err := scanPlaybook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan playbook: %w", err)
+		}
+		playbooks = append(playbooks, p)
+	}
+	return playbooks, rows.Err()
+}
+
+// GetByID returns a playbook by ID and org.
+func (r *PlaybookRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*Playbook, error) {
+	p, err := scanPlaybook(r.pool.QueryRow(ctx, playbookSelect+` WHERE id = $1 AND org_id = $2`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get playbook: %w", err)
+	}
+	return p, nil
+}
+
+// Create inserts a new playbook. Its trigger only matches what happens after
+// it is created.
+func (r *PlaybookRepository) Create(ctx context.Context, p *Playbook) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO playbooks (org_id, name, description, trigger, steps, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, trigger_checked_at, webhook_secret, created_at, updated_at`,
+		p.OrgID, p.Name, p.Description, p.Trigger, p.Steps, p.IsActive, p.CreatedBy,
+	).Scan(&p.ID, &p.TriggerCheckedAt, &p.WebhookSecret, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// RotateWebhookSecret replaces a playbook's webhook secret with a new random
+// one and returns it. It returns "" when the playbook does not exist.
+func (r *PlaybookRepository) RotateWebhookSecret(ctx context.Context, id, orgID uuid.UUID) (string, error) {
+	var secret string
+	err := r.pool.QueryRow(ctx, `
+		UPDATE playbooks SET webhook_secret = DEFAULT
+		WHERE id = $1 AND org_id = $2
+		RETURNING webhook_secret`,
+		id, orgID,
+	).Scan(&secret)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("rotate playbook webhook secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Update saves a playbook's editable fields. Reactivating a playbook skips
+// what its trigger would have matched while it was inactive.
+func (r *PlaybookRepository) Update(ctx context.Context, p *Playbook) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE playbooks
+		SET name = $3, description = $4, trigger = $5, steps = $6,
+			trigger_checked_at = CASE WHEN NOT is_active AND $7 THEN NOW() ELSE trigger_checked_at END,
+			is_active = $7
+		WHERE id = $1 AND org_id = $2
+		RETURNING trigger_checked_at, updated_at`,
+		p.ID, p.OrgID, p.Name, p.Description, p.Trigger, p.Steps, p.IsActive,
+	).Scan(&p.TriggerCheckedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update playbook: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a playbook and its runs.
+func (r *PlaybookRepository) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM playbooks WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("delete playbook: %w", err)
+	}
+	return nil
+}
+
+// ListOrgsWithActivePlaybooks returns the orgs that have at least one active playbook.
+func (r *PlaybookRepository) ListOrgsWithActivePlaybooks(ctx context.Context) ([]uuid.UUID, error) {
+	return collectIDs(r.pool.Query(ctx, `SELECT DISTINCT org_id FROM playbooks WHERE is_active`))
+}
+
+// SetTriggerCheckedAt records how far the playbook's trigger has been evaluated.
+func (r *PlaybookRepository) SetTriggerCheckedAt(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE playbooks SET trigger_checked_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("set playbook trigger checked at: %w", err)
+	}
+	return nil
+}
+
+// ListTriggerCandidates returns the customers of an org that a trigger
+// matches. Event triggers match what happened after since and up to until;
+// score and renewal triggers match the current state.
+func (r *PlaybookRepository) ListTriggerCandidates(ctx context.Context, orgID uuid.UUID, trigger PlaybookTrigger, since, until time.Time) ([]PlaybookCandidate, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+	switch trigger.Type {
+	case PlaybookTriggerRiskChange, PlaybookTriggerPaymentFailed:
+		eventType := "risk_level.changed"
+		if trigger.Type == PlaybookTriggerPaymentFailed {
+			eventType = "payment.failed"
+		}
+		rows, err = r.pool.Query(ctx, `
+			SELECT e.customer_id, e.id::text, e.data
+			FROM customer_events e
+			JOIN customers c ON c.id = e.customer_id AND c.deleted_at IS NULL
+			WHERE e.org_id = $1 AND e.event_type = $2 AND e.created_at > $3 AND e.created_at <= $4
+			ORDER BY e.created_at`, orgID, eventType, since, until)
+	case PlaybookTriggerNewCustomer:
+		rows, err = r.pool.Query(ctx, `
+			SELECT id, 'new_customer', jsonb_build_object('source', source)
+			FROM customers
+			WHERE org_id = $1 AND deleted_at IS NULL AND created_at > $2 AND created_at <= $3
+			ORDER BY created_at`, orgID, since, until)
+	case PlaybookTriggerScoreBelow:
+		rows, err = r.pool.Query(ctx, `
+			SELECT hs.customer_id, '', jsonb_build_object('score', hs.overall_score, 'threshold', $2::int, 'risk_level', hs.risk_level)
+			FROM health_scores hs
+			JOIN customers c ON c.id = hs.customer_id AND c.deleted_at IS NULL
+			WHERE hs.org_id = $1 AND hs.overall_score < $2`, orgID, trigger.Threshold)
+	case PlaybookTriggerRenewalDue:
+		rows, err = r.pool.Query(ctx, renewalsCTE+`
+			SELECT r.customer_id, 'renewal:' || COALESCE(NULLIF(r.source_id, ''), r.source) || ':' || to_char(r.renewal_date, 'YYYY-MM-DD'),
+				jsonb_build_object('renewal_at', r.renewal_date, 'source', r.source,
+					'amount_cents', r.amount_cents, 'currency', r.currency)
+			FROM renewals r
+			WHERE r.renewal_date > $2::date AND r.renewal_date <= ($2 + make_interval(days => $3))::date
+			ORDER BY r.renewal_date`, orgID, until, trigger.Days)
+	default:
+		return nil, fmt.Errorf("unknown playbook trigger: %s", trigger.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list playbook trigger candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []PlaybookCandidate
+	for rows.Next() {
+		var c PlaybookCandidate
+		if err := rows.Scan(&c.CustomerID, &c.Key, &c.Data); err != nil {
+			return nil, fmt.Errorf("scan playbook trigger candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Playbook run statuses. Running and waiting runs are active.
+const (
+	PlaybookRunRunning   = "running"
+	PlaybookRunWaiting   = "waiting"
+	PlaybookRunCompleted = "completed"
+	PlaybookRunFailed    = "failed"
+	PlaybookRunCancelled = "cancelled"
+)
+
+// PlaybookRun is one customer's progress through a playbook.
+type PlaybookRun struct {
+	ID           uuid.UUID      `json:"id"`
+	OrgID        uuid.UUID      `json:"org_id"`
+	PlaybookID   uuid.UUID      `json:"playbook_id"`
+	PlaybookName string         `json:"playbook_name"`
+	CustomerID   uuid.UUID      `json:"customer_id"`
+	CustomerName string         `json:"customer_name"`
+	TriggerKey   string         `json:"-"`
+	TriggerData  map[string]any `json:"trigger_data"`
+	Steps        []PlaybookStep `json:"steps"`
+	Status       string         `json:"status"`
+	CurrentStep  int            `json:"current_step"`
+	NextRunAt    *time.Time     `json:"next_run_at"`
+	Error        string         `json:"error,omitempty"`
+	StartedBy    *uuid.UUID     `json:"started_by,omitempty"`
+	StartedAt    time.Time      `json:"started_at"`
+	FinishedAt   *time.Time     `json:"finished_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// PlaybookRunStep records what one executed step of a run did.
+type PlaybookRunStep struct {
+	ID         uuid.UUID      `json:"id"`
+	RunID      uuid.UUID      `json:"run_id"`
+	StepIndex  int            `json:"step_index"`
+	StepType   string         `json:"step_type"`
+	Status     string         `json:"status"`
+	Detail     map[string]any `json:"detail"`
+	Error      string         `json:"error,omitempty"`
+	ExecutedAt time.Time      `json:"executed_at"`
+}
+
+// PlaybookRunListParams holds pagination and filter params for run listing.
+type PlaybookRunListParams struct {
+	OrgID      uuid.UUID
+	PlaybookID *uuid.UUID
+	CustomerID *uuid.UUID
+	Status     string
+	Page       int
+	PerPage    int
+}
+
+// PlaybookRunListResult holds a page of runs with pagination info.
+type PlaybookRunListResult struct {
+	Runs       []*PlaybookRun
+	Total      int
+	Page       int
+	PerPage    int
+	TotalPages int
+}
+
+// PlaybookRunRepository handles playbook_runs database operations.
+type PlaybookRunRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPlaybookRunRepository creates a new PlaybookRunRepository.
+func NewPlaybookRunRepository(pool *pgxpool.Pool) *PlaybookRunRepository {
+	return &PlaybookRunRepository{pool: pool}
+}
+
+const playbookRunSelect = `
+	SELECT r.id, r.org_id, r.playbook_id, COALESCE(p.name, ''), r.customer_id, COALESCE(c.name, c.email, ''),
+		r.trigger_key, r.trigger_data, r.steps, r.status, r.current_step, r.next_run_at, r.error,
+		r.started_by, r.started_at, r.finished_at, r.updated_at
+	FROM playbook_runs r
+	LEFT JOIN playbooks p ON p.id = r.playbook_id
+	LEFT JOIN customers c ON c.id = r.customer_id`
+
+func scanPlaybookRun(row pgx.Row) (*PlaybookRun, error) {
+	run := &PlaybookRun{}
+	err := row.Scan(
+		&run.ID, &run.OrgID, &run.PlaybookID, &run.PlaybookName, &run.CustomerID, &run.CustomerName,
+		&run.TriggerKey, &run.TriggerData, &run.Steps, &run.Status, &run.CurrentStep, &run.NextRunAt, &run.Error,
+		&run.StartedBy, &run.StartedAt, &run.FinishedAt, &run.UpdatedAt,
+	)
+	return run, err
+}
+
+// Start enrolls a customer in a playbook and reports whether a run was
+// started. No run starts while the customer has an active run of the
+// playbook, when the trigger key was already used, or when their previous run
+// started less than reentryDays ago.
+func (r *PlaybookRunRepository) Start(ctx context.Context, run *PlaybookRun, reentryDays int) (bool, error) {
+	if run.TriggerData == nil {
+		run.TriggerData = map[string]any{}
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO playbook_runs (org_id, playbook_id, customer_id, trigger_key, trigger_data, steps, status, next_run_at, started_by)
+		SELECT $1, $2, $3, $4, $5, $6, 'running', NOW(), $7
+		WHERE NOT EXISTS (
+			SELECT 1 FROM playbook_runs
+			WHERE playbook_id = $2 AND customer_id = $3
+				AND started_at > NOW() - make_interval(days => $8))
+		ON CONFLICT DO NOTHING
+		RETURNING id, status, next_run_at, started_at, updated_at`,
+		run.OrgID, run.PlaybookID, run.CustomerID, run.TriggerKey, run.TriggerData, run.Steps, run.StartedBy, reentryDays,
+	).Scan(&run.ID, &run.Status, &run.NextRunAt, &run.StartedAt, &run.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("start playbook run: %w", err)
+	}
+	return true, nil
+}
+
+// GetByID returns a run by ID and org.
+func (r *PlaybookRunRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*PlaybookRun, error) {
+	run, err := scanPlaybookRun(r.pool.QueryRow(ctx, playbookRunSelect+` WHERE r.id = $1 AND r.org_id = $2`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get playbook run: %w", err)
+	}
+	return run, nil
+}
+
+// List returns a filtered page of an org's runs, newest first.
+func (r *PlaybookRunRepository) List(ctx context.Context, params PlaybookRunListParams) (*PlaybookRunListResult, error) {
+	where := "r.org_id = $1"
+	args := []any{params.OrgID}
+	argIdx := 2
+
+	if params.PlaybookID != nil {
+		where += fmt.Sprintf(" AND r.playbook_id = $%d", argIdx)
+		args = append(args, *params.PlaybookID)
+		argIdx++
+	}
+	if params.CustomerID != nil {
+		where += fmt.Sprintf(" AND r.customer_id = $%d", argIdx)
+		args = append(args, *params.CustomerID)
+		argIdx++
+	}
+	if params.Status != "" {
+		where += fmt.Sprintf(" AND r.status = $%d", argIdx)
+		args = append(args, params.Status)
+		argIdx++
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM playbook_runs r WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count playbook runs: %w", err)
+	}
+
+	totalPages := 0
+	if params.PerPage > 0 {
+		totalPages = (total + params.PerPage - 1) / params.PerPage
+	}
+
+	offset := (params.Page - 1) * params.PerPage
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(`%s
+		WHERE %s
+		ORDER BY r.started_at DESC
+		LIMIT $%d OFFSET $%d`, playbookRunSelect, where, argIdx, argIdx+1),
+		append(args, params.PerPage, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("list playbook runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*PlaybookRun
+	for rows.Next() {
+		run, err := scanPlaybookRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan playbook run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &PlaybookRunListResult{
+		Runs:       runs,
+		Total:      total,
+		Page:       params.Page,
+		PerPage:    params.PerPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ClaimDue marks up to limit active runs whose next step is due as running
+// and returns them. Claimed runs are leased until lease has passed so that a
+// worker that dies mid-run does not hold them forever.
+func (r *PlaybookRunRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) ([]*PlaybookRun, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH claimed AS (
+			UPDATE playbook_runs SET status = 'running', next_run_at = NOW() + make_interval(secs => $1)
+			WHERE id IN (
+				SELECT id FROM playbook_runs
+				WHERE status IN ('running', 'waiting') AND next_run_at <= NOW()
+				ORDER BY next_run_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED)
+			RETURNING id
+		)`+playbookRunSelect+`
+		WHERE r.id IN (SELECT id FROM claimed)`,
+		lease.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim due playbook runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*PlaybookRun
+	for rows.Next() {
+		run, err := scanPlaybookRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan playbook run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// SaveProgress stores a run's position, status and next due time. Runs that
+// were cancelled meanwhile are left alone.
+func (r *PlaybookRunRepository) SaveProgress(ctx context.Context, run *PlaybookRun) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE playbook_runs
+		SET status = $2, current_step = $3, next_run_at = $4, error = $5, finished_at = $6
+		WHERE id = $1 AND status IN ('running', 'waiting')`,
+		run.ID, run.Status, run.CurrentStep, run.NextRunAt, run.Error, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("save playbook run progress: %w", err)
+	}
+	return nil
+}
+
+// Cancel stops an active run and reports whether it was active.
+func (r *PlaybookRunRepository) Cancel(ctx context.Context, id, orgID uuid.UUID) (bool, error) {
+	ct, err := r.pool.Exec(ctx, `
+		UPDATE playbook_runs
+		SET status = 'cancelled', next_run_at = NULL, finished_at = NOW()
+		WHERE id = $1 AND org_id = $2 AND status IN ('running', 'waiting')`, id, orgID)
+	if err != nil {
+		return false, fmt.Errorf("cancel playbook run: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+// AddStep records an executed step of a run.
+func (r *PlaybookRunRepository) AddStep(ctx context.Context, step *PlaybookRunStep) error {
+	if step.Detail == nil {
+		step.Detail = map[string]any{}
+	}
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO playbook_run_steps (run_id, step_index, step_type, status, detail, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, executed_at`,
+		step.RunID, step.StepIndex, step.StepType, step.Status, step.Detail, step.Error,
+	).Scan(&step.ID, &step.ExecutedAt)
+}
+
+// ListSteps returns the executed steps of a run in order.
+func (r *PlaybookRunRepository) ListSteps(ctx context.Context, runID uuid.UUID) ([]*PlaybookRunStep, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, run_id, step_index, step_type, status, detail, error, executed_at
+		FROM playbook_run_steps
+		WHERE run_id = $1
+		ORDER BY executed_at, step_index`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("list playbook run steps: %w", err)
+	}
+	defer rows.Close()
+
+	var steps []*PlaybookRunStep
+	for rows.Next() {
+		s := &PlaybookRunStep{}
+		if err := rows.Scan(&s.ID, &s.RunID, &s.StepIndex, &s.StepType, &s.Status, &s.Detail, &s.Error, &s.ExecutedAt); err != nil {
+			return nil, fmt.Errorf("scan playbook run step: %w", err)
+		}
+		steps = append(steps, s)
+	}
+	return steps, rows.Err()
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QueuedAlertEmail represents a queued_alert_emails row: an alert email held
+// back until the recipient's delivery window opens.
+type QueuedAlertEmail struct {
+	ID             uuid.UUID
+	OrgID          uuid.UUID
+	AlertHistoryID uuid.UUID
+	UserID         *uuid.UUID
+	Recipient      string
+	Subject        string
+	HTMLBody       string
+	TextBody       string
+	DeliverAfter   time.Time
+	Status         string // queued, sending, sent, failed
+	ErrorMessage   string
+	ClaimedAt      *time.Time
+	SentAt         *time.Time
+	CreatedAt      time.Time
+}
+
+// QueuedAlertEmailRepository handles queued_alert_emails database operations.
+type QueuedAlertEmailRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewQueuedAlertEmailRepository creates a new QueuedAlertEmailRepository.
+func NewQueuedAlertEmailRepository(pool *pgxpool.Pool) *QueuedAlertEmailRepository {
+	return &QueuedAlertEmailRepository{pool: pool}
+}
+
+// Create queues an alert email for later delivery.
+func (r *QueuedAlertEmailRepository) Create(ctx context.Context, q *QueuedAlertEmail) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO queued_alert_emails (org_id, alert_history_id, user_id, recipient, subject, html_body, text_body, deliver_after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at
+	`, q.OrgID, q.AlertHistoryID, q.UserID, q.Recipient, q.Subject, q.HTMLBody, q.TextBody, q.DeliverAfter,
+	).Scan(&q.ID, &q.Status, &q.CreatedAt)
+}
+
+// ClaimDue marks up to limit due emails as sending and returns them. Emails
+// left in sending longer than staleAfter (e.g. by a crashed worker) are
+// reclaimed. Rows locked by another instance are skipped.
+func (r *QueuedAlertEmailRepository) ClaimDue(ctx context.Context, now time.Time, staleAfter time.Duration, limit int) ([]*QueuedAlertEmail, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE queued_alert_emails
+		SET status = 'sending', claimed_at = $1
+		WHERE id IN (
+			SELECT id FROM queued_alert_emails
+			WHERE (status = 'queued' AND deliver_after <= $1)
+			   OR (status = 'sending' AND claimed_at < $2)
+			ORDER BY deliver_after
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, org_id, alert_history_id, user_id, recipient, subject, html_body, text_body,
+			deliver_after, status, COALESCE(error_message, ''), claimed_at, sent_at, created_at
+	`, now, now.Add(-staleAfter), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim due alert emails: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*QueuedAlertEmail
+	for rows.Next() {
+		q := &QueuedAlertEmail{}
+		if err := rows.Scan(
+			&q.ID, &q.OrgID, &q.AlertHistoryID, &q.UserID, &q.Recipient,
+			&q.Subject, &q.HTMLBody, &q.TextBody,
+			&q.DeliverAfter, &q.Status, &q.ErrorMessage, &q.ClaimedAt, &q.SentAt, &q.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan queued alert email: %w", err)
+		}
+		items = append(items, q)
+	}
+	return items, rows.Err()
+}
+
+// MarkSent marks a queued email as delivered.
+func (r *QueuedAlertEmailRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE queued_alert_emails SET status = 'sent', sent_at = NOW() WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("mark queued alert email sent: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed marks a queued email as failed.
+func (r *QueuedAlertEmailRepository) MarkFailed(ctx context.Context, id uuid.UUID, errorMsg string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE queued_alert_emails SET status = 'failed', error_message = $1 WHERE id = $2
+	`, errorMsg, id)
+	if err != nil {
+		return fmt.Errorf("mark queued alert email failed: %w", err)
+	}
+	return nil
+}
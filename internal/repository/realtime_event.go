@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RealtimeEventChannel is the Postgres NOTIFY channel realtime_events inserts are announced on.
+const RealtimeEventChannel = "realtime_events"
+
+// RealtimeEvent represents a realtime_events row pushed to connected clients.
+type RealtimeEvent struct {
+	ID        int64          `json:"id"`
+	OrgID     uuid.UUID      `json:"org_id"`
+	UserID    *uuid.UUID     `json:"user_id,omitempty"` // nil: every member of the org
+	EventType string         `json:"event_type"`
+	Payload   map[string]any `json:"payload"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// RealtimeEventRepository handles realtime_events database operations.
+type RealtimeEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRealtimeEventRepository creates a new RealtimeEventRepository.
+func NewRealtimeEventRepository(pool *pgxpool.Pool) *RealtimeEventRepository {
+	return &RealtimeEventRepository{pool: pool}
+}
+
+// Create inserts a realtime event. Listeners are notified by a database trigger.
+func (r *RealtimeEventRepository) Create(ctx context.Context, e *RealtimeEvent) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO realtime_events (org_id, user_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, e.OrgID, e.UserID, e.EventType, e.Payload,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+// GetByID returns a realtime event by ID.
+func (r *RealtimeEventRepository) GetByID(ctx context.Context, id int64) (*RealtimeEvent, error) {
+	e := &RealtimeEvent{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, org_id, user_id, event_type, payload, created_at
+		FROM realtime_events
+		WHERE id = $1
+	`, id).Scan(&e.ID, &e.OrgID, &e.UserID, &e.EventType, &e.Payload, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get realtime event: %w", err)
+	}
+	return e, nil
+}
+
+// ListSince returns up to limit events after afterID visible to a user in an org, oldest first.
+func (r *RealtimeEventRepository) ListSince(ctx context.Context, orgID, userID uuid.UUID, afterID int64, limit int) ([]*RealtimeEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, org_id, user_id, event_type, payload, created_at
+		FROM realtime_events
+		WHERE org_id = $1 AND (user_id IS NULL OR user_id = $2) AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`, orgID, userID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list realtime events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*RealtimeEvent
+	for rows.Next() {
+		e := &RealtimeEvent{}
+		if err := rows.Scan(&e.ID, &e.OrgID, &e.UserID, &e.EventType, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan realtime event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// DeleteOlderThan removes events created before the cutoff and returns how many were deleted.
+func (r *RealtimeEventRepository) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM realtime_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete old realtime events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Listen holds a dedicated connection subscribed to RealtimeEventChannel and calls
+// fn with each notification payload. It blocks until ctx is cancelled or the
+// connection fails.
+func (r *RealtimeEventRepository) Listen(ctx context.Context, fn func(payload string)) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire listen connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+RealtimeEventChannel); err != nil {
+		return fmt.Errorf("listen %s: %w", RealtimeEventChannel, err)
+	}
+	defer func() {
+		// The connection goes back to the pool; stop listening on it first.
+		_, _ = conn.Exec(context.Background(), "UNLISTEN "+RealtimeEventChannel)
+	}()
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		fn(n.Payload)
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReconciliationItem is one record found to differ from the provider.
+type ReconciliationItem struct {
+	Resource   string `json:"resource"`
+	ExternalID string `json:"external_id"`
+	Kind       string `json:"kind"` // missing, stale, orphaned
+	Detail     string `json:"detail,omitempty"`
+	Healed     bool   `json:"healed"`
+}
+
+// ReconciliationReport represents a reconciliation_reports row.
+type ReconciliationReport struct {
+	ID         uuid.UUID            `json:"id"`
+	OrgID      uuid.UUID            `json:"org_id"`
+	Provider   string               `json:"provider"`
+	Trigger    string               `json:"trigger"` // scheduler, manual
+	Heal       bool                 `json:"heal"`
+	Status     string               `json:"status"` // succeeded, failed
+	Checked    int                  `json:"checked"`
+	Missing    int                  `json:"missing"`
+	Stale      int                  `json:"stale"`
+	Orphaned   int                  `json:"orphaned"`
+	Healed     int                  `json:"healed"`
+	Items      []ReconciliationItem `json:"items"`
+	Error      string               `json:"error,omitempty"`
+	StartedAt  time.Time            `json:"started_at"`
+	FinishedAt time.Time            `json:"finished_at"`
+}
+
+// ReconciliationReportRepository handles reconciliation_reports database operations.
+type ReconciliationReportRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewReconciliationReportRepository creates a new ReconciliationReportRepository.
+func NewReconciliationReportRepository(pool *pgxpool.Pool) *ReconciliationReportRepository {
+	return &ReconciliationReportRepository{pool: pool}
+}
+
+// Create inserts a finished reconciliation report.
+func (r *ReconciliationReportRepository) Create(ctx context.Context, rep *ReconciliationReport) error {
+	if rep.Items == nil {
+		rep.Items = []ReconciliationItem{}
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO reconciliation_reports (org_id, provider, trigger, heal, status,
+			checked, missing, stale, orphaned, healed, items, error, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13)
+		RETURNING id, finished_at
+	`, rep.OrgID, rep.Provider, rep.Trigger, rep.Heal, rep.Status,
+		rep.Checked, rep.Missing, rep.Stale, rep.Orphaned, rep.Healed, rep.Items, rep.Error, rep.StartedAt,
+	).Scan(&rep.ID, &rep.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("create reconciliation report: %w", err)
+	}
+	return nil
+}
+
+// ListByOrgAndProvider returns an org's reconciliation reports for a provider, newest first, with the total count.
+func (r *ReconciliationReportRepository) ListByOrgAndProvider(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*ReconciliationReport, int, error) {
+	var total int
+	if err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM reconciliation_reports WHERE org_id = $1 AND provider = $2
+	`, orgID, provider).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count reconciliation reports: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, org_id, provider, trigger, heal, status, checked, missing, stale, orphaned,
+			healed, items, COALESCE(error, ''), started_at, finished_at
+		FROM reconciliation_reports
+		WHERE org_id = $1 AND provider = $2
+		ORDER BY started_at DESC
+		LIMIT $3 OFFSET $4
+	`, orgID, provider, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list reconciliation reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*ReconciliationReport
+	for rows.Next() {
+		rep := &ReconciliationReport{}
+		if err := rows.Scan(
+			&rep.ID, &rep.OrgID, &rep.Provider, &rep.Trigger, &rep.Heal, &rep.Status,
+			&rep.Checked, &rep.Missing, &rep.Stale, &rep.Orphaned, &rep.Healed, &rep.Items,
+			&rep.Error, &rep.StartedAt, &rep.FinishedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan reconciliation report: %w", err)
+		}
+		reports = append(reports, rep)
+	}
+	return reports, total, rows.Err()
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Renewal sources, in order of precedence.
+const (
+	RenewalSourceManual  = "manual"
+	RenewalSourceHubSpot = "hubspot"
+	RenewalSourceStripe  = "stripe"
+)
+
+// RenewalSettings represents a renewal_settings row. Open HubSpot deals in
+// HubSpotPipeline are treated as renewals; deals whose stage is one of
+// ClosedStages are not open.
+type RenewalSettings struct {
+	OrgID           uuid.UUID  `json:"org_id"`
+	HubSpotPipeline *string    `json:"hubspot_pipeline"`
+	ClosedStages    []string   `json:"closed_stages"`
+	UpdatedBy       *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// RenewalOverride represents a customer_renewals row: a manually set renewal
+// date and, optionally, amount.
+type RenewalOverride struct {
+	CustomerID  uuid.UUID  `json:"customer_id"`
+	OrgID       uuid.UUID  `json:"org_id"`
+	RenewalDate time.Time  `json:"renewal_date"`
+	AmountCents *int64     `json:"amount_cents"`
+	Currency    *string    `json:"currency"`
+	Notes       string     `json:"notes"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Renewal is a customer's next renewal as resolved from its manual override,
+// open HubSpot renewal deal or Stripe subscriptions, with its health score.
+// Source says where the date came from; SourceID is the Stripe subscription
+// or HubSpot deal ID.
+type Renewal struct {
+	CustomerID   uuid.UUID   `json:"customer_id"`
+	CustomerName string      `json:"customer_name"`
+	CompanyName  string      `json:"company_name"`
+	RenewalDate  time.Time   `json:"renewal_date"`
+	AmountCents  int64       `json:"amount_cents"`
+	Currency     string      `json:"currency"`
+	Source       string      `json:"source"`
+	SourceID     string      `json:"source_id,omitempty"`
+	OverallScore *int        `json:"overall_score"`
+	RiskLevel    *string     `json:"risk_level"`
+	OwnerIDs     []uuid.UUID `json:"owner_ids"`
+}
+
+// RenewalListParams filters the renewals of an org. From and To bound the
+// renewal date, inclusive; MaxScore keeps scored customers at or below it.
+type RenewalListParams struct {
+	OrgID    uuid.UUID
+	From     *time.Time
+	To       *time.Time
+	Risk     string
+	OwnerID  *uuid.UUID
+	MaxScore *int
+}
+
+// renewalsCTE resolves the next renewal of each customer of org $1. A
+// manual override wins, then the earliest open deal in the org's HubSpot
+// renewal pipeline, then the earliest period end of the customer's live
+// Stripe subscriptions, whose amounts are summed. Without a manual amount,
+// the amount comes from the deal and then from Stripe.
+const renewalsCTE = `
+	WITH stripe_renewals AS (
+		SELECT DISTINCT ON (customer_id) customer_id, renewal_date, amount_cents, currency, source_id
+		FROM (
+			SELECT s.customer_id, s.current_period_end::date AS renewal_date,
+				(SUM(s.amount_cents) OVER (PARTITION BY s.customer_id, s.current_period_end::date))::bigint AS amount_cents,
+				s.currency, s.stripe_subscription_id AS source_id
+			FROM stripe_subscriptions s
+			WHERE s.org_id = $1 AND s.status IN ('active', 'trialing') AND s.canceled_at IS NULL
+				AND s.current_period_end IS NOT NULL
+		) s
+		ORDER BY customer_id, renewal_date, source_id
+	), hubspot_renewals AS (
+		SELECT DISTINCT ON (d.customer_id) d.customer_id, d.close_date::date AS renewal_date,
+			NULLIF(d.amount_cents, 0) AS amount_cents, COALESCE(d.currency, 'USD') AS currency,
+			d.hubspot_deal_id AS source_id
+		FROM hubspot_deals d
+		JOIN renewal_settings rs ON rs.org_id = d.org_id AND rs.hubspot_pipeline = d.pipeline
+		WHERE d.org_id = $1 AND d.customer_id IS NOT NULL AND d.close_date IS NOT NULL
+			AND NOT (COALESCE(d.stage, '') = ANY (rs.closed_stages))
+		ORDER BY d.customer_id, d.close_date, d.hubspot_deal_id
+	), renewals AS (
+		SELECT c.id AS customer_id,
+			COALESCE(m.renewal_date, h.renewal_date, s.renewal_date) AS renewal_date,
+			COALESCE(m.amount_cents, h.amount_cents, s.amount_cents, 0) AS amount_cents,
+			CASE
+				WHEN m.amount_cents IS NOT NULL THEN COALESCE(m.currency, c.currency)
+				WHEN h.amount_cents IS NOT NULL THEN h.currency
+				WHEN s.amount_cents IS NOT NULL THEN s.currency
+				ELSE c.currency
+			END AS currency,
+			CASE
+				WHEN m.customer_id IS NOT NULL THEN 'manual'
+				WHEN h.customer_id IS NOT NULL THEN 'hubspot'
+				ELSE 'stripe'
+			END AS source,
+			CASE
+				WHEN m.customer_id IS NOT NULL THEN ''
+				WHEN h.customer_id IS NOT NULL THEN h.source_id
+				ELSE s.source_id
+			END AS source_id
+		FROM customers c
+		LEFT JOIN customer_renewals m ON m.customer_id = c.id
+		LEFT JOIN hubspot_renewals h ON h.customer_id = c.id
+		LEFT JOIN stripe_renewals s ON s.customer_id = c.id
+		WHERE c.org_id = $1 AND c.deleted_at IS NULL
+			AND (m.customer_id IS NOT NULL OR h.customer_id IS NOT NULL OR s.customer_id IS NOT NULL)
+	)`
+
+const renewalSelect = renewalsCTE + `
+	SELECT r.customer_id, COALESCE(c.name, ''), COALESCE(c.company_name, ''),
+		r.renewal_date, r.amount_cents, r.currency, r.source, r.source_id,
+		hs.overall_score, hs.risk_level,
+		ARRAY(
+			SELECT o.user_id FROM customer_owners o
+			JOIN user_organizations uo ON uo.user_id = o.user_id AND uo.org_id = o.org_id
+			WHERE o.customer_id = c.id ORDER BY o.assigned_at)
+	FROM renewals r
+	JOIN customers c ON c.id = r.customer_id
+	LEFT JOIN health_scores hs ON hs.customer_id = r.customer_id`
+
+// RenewalRepository handles customer_renewals and renewal_settings database
+// operations and resolves customers' renewals.
+type RenewalRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRenewalRepository creates a new RenewalRepository.
+func NewRenewalRepository(pool *pgxpool.Pool) *RenewalRepository {
+	return &RenewalRepository{pool: pool}
+}
+
+func scanRenewal(row pgx.Row) (*Renewal, error) {
+	r := &Renewal{}
+	err := row.Scan(&r.CustomerID, &r.CustomerName, &r.CompanyName,
+		&r.RenewalDate, &r.AmountCents, &r.Currency, &r.Source, &r.SourceID,
+		&r.OverallScore, &r.RiskLevel, &r.OwnerIDs)
+	return r, err
+}
+
+// List returns the org's renewals matching params, soonest first.
+func (r *RenewalRepository) List(ctx context.Context, params RenewalListParams) ([]*Renewal, error) {
+	where := "TRUE"
+	args := []any{params.OrgID}
+	if params.From != nil {
+		args = append(args, *params.From)
+		where += fmt.Sprintf(" AND r.renewal_date >= $%d::date", len(args))
+	}
+	if params.To != nil {
+		args = append(args, *params.To)
+		where += fmt.Sprintf(" AND r.renewal_date <= $%d::date", len(args))
+	}
+	if params.Risk != "" {
+		args = append(args, params.Risk)
+		where += fmt.Sprintf(" AND hs.risk_level = $%d", len(args))
+	}
+	if params.OwnerID != nil {
+		args = append(args, *params.OwnerID)
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM customer_owners o WHERE o.customer_id = c.id AND o.user_id = $%d)", len(args))
+	}
+	if params.MaxScore != nil {
+		args = append(args, *params.MaxScore)
+		where += fmt.Sprintf(" AND hs.overall_score <= $%d", len(args))
+	}
+
+	rows, err := r.pool.Query(ctx, renewalSelect+`
+		WHERE `+where+`
+		ORDER BY r.renewal_date, r.amount_cents DESC, r.customer_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list renewals: %w", err)
+	}
+	defer rows.Close()
+
+	renewals := []*Renewal{}
+	for rows.Next() {
+		renewal, err := scanRenewal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan renewal: %w", err)
+		}
+		renewals = append(renewals, renewal)
+	}
+	return renewals, rows.Err()
+}
+
+// GetByCustomer returns a customer's next renewal, or nil if it has none.
+func (r *RenewalRepository) GetByCustomer(ctx context.Context, orgID, customerID uuid.UUID) (*Renewal, error) {
+	renewal, err := scanRenewal(r.pool.QueryRow(ctx, renewalSelect+`
+		WHERE r.customer_id = $2`, orgID, customerID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get customer renewal: %w", err)
+	}
+	return renewal, nil
+}
+
+// GetOverride returns a customer's manual renewal override, or nil.
+func (r *RenewalRepository) GetOverride(ctx context.Context, orgID, customerID uuid.UUID) (*RenewalOverride, error) {
+	o := &RenewalOverride{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT customer_id, org_id, renewal_date, amount_cents, currency, notes, updated_by, created_at, updated_at
+		FROM customer_renewals
+		WHERE customer_id = $1 AND org_id = $2`, customerID, orgID,
+	).Scan(&o.CustomerID, &o.OrgID, &o.RenewalDate, &o.AmountCents, &o.Currency, &o.Notes,
+		&o.UpdatedBy, &o.CreatedAt, &o.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get renewal override: %w", err)
+	}
+	return o, nil
+}
+
+// SetOverride creates or replaces a customer's manual renewal override.
+func (r *RenewalRepository) SetOverride(ctx context.Context, o *RenewalOverride) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO customer_renewals (customer_id, org_id, renewal_date, amount_cents, currency, notes, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (customer_id) DO UPDATE SET
+			renewal_date = EXCLUDED.renewal_date,
+			amount_cents = EXCLUDED.amount_cents,
+			currency = EXCLUDED.currency,
+			notes = EXCLUDED.notes,
+			updated_by = EXCLUDED.updated_by
+		RETURNING created_at, updated_at`,
+		o.CustomerID, o.OrgID, o.RenewalDate, o.AmountCents, o.Currency, o.Notes, o.UpdatedBy,
+	).Scan(&o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("set renewal override: %w", err)
+	}
+	return nil
+}
+
+// DeleteOverride removes a customer's manual renewal override. It reports
+// whether there was one.
+func (r *RenewalRepository) DeleteOverride(ctx context.Context, orgID, customerID uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM customer_renewals WHERE customer_id = $1 AND org_id = $2`, customerID, orgID)
+	if err != nil {
+		return false, fmt.Errorf("delete renewal override: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetSettings returns an org's renewal settings, or nil if it has none.
+func (r *RenewalRepository) GetSettings(ctx context.Context, orgID uuid.UUID) (*RenewalSettings, error) {
+	s := &RenewalSettings{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT org_id, hubspot_pipeline, closed_stages, updated_by, updated_at
+		FROM renewal_settings WHERE org_id = $1`, orgID,
+	).Scan(&s.OrgID, &s.HubSpotPipeline, &s.ClosedStages, &s.UpdatedBy, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get renewal settings: %w", err)
+	}
+	return s, nil
+}
+
+// UpsertSettings creates or replaces an org's renewal settings.
+func (r *RenewalRepository) UpsertSettings(ctx context.Context, s *RenewalSettings) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO renewal_settings (org_id, hubspot_pipeline, closed_stages, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (org_id) DO UPDATE SET
+			hubspot_pipeline = EXCLUDED.hubspot_pipeline,
+			closed_stages = EXCLUDED.closed_stages,
+			updated_by = EXCLUDED.updated_by
+		RETURNING updated_at`,
+		s.OrgID, s.HubSpotPipeline, s.ClosedStages, s.UpdatedBy,
+	).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert renewal settings: %w", err)
+	}
+	return nil
+}
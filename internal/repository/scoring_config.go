@@ -15,22 +15,31 @@ import (
 
 // ScoringConfig represents a scoring_configs row.
 type ScoringConfig struct {
-	ID         uuid.UUID          `json:"id"`
-	OrgID      uuid.UUID          `json:"org_id"`
-	Weights    map[string]float64 `json:"weights"`
-	Thresholds map[string]int     `json:"thresholds"`
-	CreatedAt  time.Time          `json:"created_at"`
-	UpdatedAt  time.Time          `json:"updated_at"`
+	ID                 uuid.UUID          `json:"id"`
+	OrgID              uuid.UUID          `json:"org_id"`
+	Weights            map[string]float64 `json:"weights"`
+	Thresholds         map[string]int     `json:"thresholds"`
+	AccountAggregation string             `json:"account_aggregation"` // mrr_weighted, min, mean
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
 }
 
+// Account score aggregations.
+const (
+	AccountAggregationMRRWeighted = "mrr_weighted"
+	AccountAggregationMin         = "min"
+	AccountAggregationMean        = "mean"
+)
+
 // DefaultWeights returns the default scoring factor weights.
 func DefaultWeights() map[string]float64 {
 	return map[string]float64{
-		"payment_recency": 0.3,
+		"payment_recency": 0.25,
 		"mrr_trend":       0.2,
-		"failed_payments": 0.2,
+		"failed_payments": 0.15,
 		"support_tickets": 0.15,
 		"engagement":      0.15,
+		"billing_risk":    0.1,
 	}
 }
 
@@ -85,6 +94,16 @@ func ValidateThresholds(thresholds map[string]int) error {
 	return nil
 }
 
+// ValidateAccountAggregation checks that an account aggregation is supported.
+func ValidateAccountAggregation(aggregation string) error {
+	switch aggregation {
+	case AccountAggregationMRRWeighted, AccountAggregationMin, AccountAggregationMean:
+		return nil
+	}
+	return fmt.Errorf("account aggregation must be one of %s, %s, %s, got %q",
+		AccountAggregationMRRWeighted, AccountAggregationMin, AccountAggregationMean, aggregation)
+}
+
 // ScoringConfigRepository handles scoring_configs database operations.
 type ScoringConfigRepository struct {
 	pool *pgxpool.Pool
@@ -98,14 +117,14 @@ func NewScoringConfigRepository(pool *pgxpool.Pool) *ScoringConfigRepository {
 // GetByOrgID returns the scoring config for an org, or nil if none exists.
 func (r *ScoringConfigRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) (*ScoringConfig, error) {
 	query := `
-		SELECT id, org_id, weights, thresholds, created_at, updated_at
+		SELECT id, org_id, weights, thresholds, account_aggregation, created_at, updated_at
 		FROM scoring_configs
 		WHERE org_id = $1`
 
 	sc := &ScoringConfig{}
 	var weightsJSON, thresholdsJSON []byte
 	err := r.pool.QueryRow(ctx, query, orgID).Scan(
-		&sc.ID, &sc.OrgID, &weightsJSON, &thresholdsJSON, &sc.CreatedAt, &sc.UpdatedAt,
+		&sc.ID, &sc.OrgID, &weightsJSON, &thresholdsJSON, &sc.AccountAggregation, &sc.CreatedAt, &sc.UpdatedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
@@ -135,16 +154,21 @@ func (r *ScoringConfigRepository) Upsert(ctx context.Context, sc *ScoringConfig)
 		return fmt.Errorf("marshal thresholds: %w", err)
 	}
 
+	if sc.AccountAggregation == "" {
+		sc.AccountAggregation = AccountAggregationMRRWeighted
+	}
+
 	query := `
-		INSERT INTO scoring_configs (org_id, weights, thresholds)
-		VALUES ($1, $2, $3)
+		INSERT INTO scoring_configs (org_id, weights, thresholds, account_aggregation)
+		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (org_id) DO UPDATE SET
 			weights = EXCLUDED.weights,
 			thresholds = EXCLUDED.thresholds,
+			account_aggregation = EXCLUDED.account_aggregation,
 			updated_at = NOW()
 		RETURNING id, created_at, updated_at`
 
-	return r.pool.QueryRow(ctx, query, sc.OrgID, weightsJSON, thresholdsJSON).Scan(
+	return r.pool.QueryRow(ctx, query, sc.OrgID, weightsJSON, thresholdsJSON, sc.AccountAggregation).Scan(
 		&sc.ID, &sc.CreatedAt, &sc.UpdatedAt,
 	)
 }
@@ -152,9 +176,10 @@ func (r *ScoringConfigRepository) Upsert(ctx context.Context, sc *ScoringConfig)
 // CreateDefault creates a scoring config with default weights and thresholds for an org.
 func (r *ScoringConfigRepository) CreateDefault(ctx context.Context, orgID uuid.UUID) (*ScoringConfig, error) {
 	sc := &ScoringConfig{
-		OrgID:      orgID,
-		Weights:    DefaultWeights(),
-		Thresholds: DefaultThresholds(),
+		OrgID:              orgID,
+		Weights:            DefaultWeights(),
+		Thresholds:         DefaultThresholds(),
+		AccountAggregation: AccountAggregationMRRWeighted,
 	}
 	if err := r.Upsert(ctx, sc); err != nil {
 		return nil, fmt.Errorf("create default scoring config: %w", err)
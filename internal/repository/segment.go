@@ -0,0 +1,725 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	maxSegmentFilterDepth      = 5
+	maxSegmentFilterConditions = 50
+	maxSegmentFilterValues     = 100
+)
+
+// SegmentFilter is a filter expression over customers. A node is either a
+// condition (Field, Op and Value) or combines other nodes with All (and), Any
+// (or) or Not.
+//
+// Fields are score, risk, mrr_cents, plan, source, tag, owner, company,
+// metadata.<key> and field.<key>, the latter naming a custom field. Ops are
+// eq, neq, lt, lte, gt, gte, in, not_in, contains, exists and not_exists;
+// which of them apply depends on the field.
+type SegmentFilter struct {
+	All   []SegmentFilter `json:"all,omitempty"`
+	Any   []SegmentFilter `json:"any,omitempty"`
+	Not   *SegmentFilter  `json:"not,omitempty"`
+	Field string          `json:"field,omitempty"`
+	Op    string          `json:"op,omitempty"`
+	Value any             `json:"value,omitempty"`
+}
+
+// Validate reports whether the filter is well-formed.
+func (f SegmentFilter) Validate() error {
+	_, _, err := f.where(nil)
+	return err
+}
+
+// where compiles the filter to an SQL condition over customers c joined with
+// health_scores hs. Its arguments are appended to args and numbered after them.
+func (f SegmentFilter) where(args []any) (string, []any, error) {
+	c := &segmentCompiler{args: args}
+	cond, err := c.node(f, 1)
+	if err != nil {
+		return "", nil, err
+	}
+	return cond, c.args, nil
+}
+
+type segmentCompiler struct {
+	args       []any
+	conditions int
+}
+
+func (c *segmentCompiler) arg(v any) string {
+	c.args = append(c.args, v)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+func (c *segmentCompiler) node(f SegmentFilter, depth int) (string, error) {
+	if depth > maxSegmentFilterDepth {
+		return "", fmt.Errorf("filter is nested more than %d levels deep", maxSegmentFilterDepth)
+	}
+
+	kinds := 0
+	for _, set := range []bool{f.All != nil, f.Any != nil, f.Not != nil, f.Field != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return "", errors.New("each filter node needs exactly one of all, any, not or field")
+	}
+
+	switch {
+	case f.All != nil || f.Any != nil:
+		nodes, joiner := f.All, " AND "
+		if f.Any != nil {
+			nodes, joiner = f.Any, " OR "
+		}
+		if len(nodes) == 0 {
+			return "", errors.New("all and any need at least one filter")
+		}
+		parts := make([]string, len(nodes))
+		for i, n := range nodes {
+			part, err := c.node(n, depth+1)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "(" + strings.Join(parts, joiner) + ")", nil
+	case f.Not != nil:
+		part, err := c.node(*f.Not, depth+1)
+		if err != nil {
+			return "", err
+		}
+		// A comparison on a missing score or metadata value is NULL, and so is
+		// its NOT; count it as not matching so not selects every other customer
+		return "NOT COALESCE(" + part + ", false)", nil
+	}
+
+	c.conditions++
+	if c.conditions > maxSegmentFilterConditions {
+		return "", fmt.Errorf("filter has more than %d conditions", maxSegmentFilterConditions)
+	}
+	cond, err := c.condition(f)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", f.Field, err)
+	}
+	return "(" + cond + ")", nil
+}
+
+var segmentComparisons = map[string]string{
+	"eq": "=", "neq": "<>", "lt": "<", "lte": "<=", "gt": ">", "gte": ">=",
+}
+
+func (c *segmentCompiler) condition(f SegmentFilter) (string, error) {
+	switch {
+	case f.Field == "score" || f.Field == "mrr_cents":
+		col := "hs.overall_score"
+		if f.Field == "mrr_cents" {
+			col = "c.mrr_cents"
+		}
+		if f.Op == "exists" || f.Op == "not_exists" {
+			if f.Field == "mrr_cents" {
+				return "", unsupportedOp(f.Op)
+			}
+			return col + isNull(f.Op), nil
+		}
+		cmp, ok := segmentComparisons[f.Op]
+		if !ok {
+			return "", unsupportedOp(f.Op)
+		}
+		n, ok := f.Value.(float64)
+		if !ok {
+			return "", errors.New("value must be a number")
+		}
+		return fmt.Sprintf("%s %s %s::numeric", col, cmp, c.arg(n)), nil
+
+	case f.Field == "risk" || f.Field == "source" || f.Field == "company":
+		col := map[string]string{
+			"risk":    "COALESCE(hs.risk_level, '')",
+			"source":  "c.source",
+			"company": "LOWER(COALESCE(c.company_name, ''))",
+		}[f.Field]
+		lower := f.Field == "company"
+		if f.Field == "risk" && !validSegmentRisk(f.Value) {
+			return "", errors.New("value must be green, yellow or red")
+		}
+		return c.stringCondition(col, f, lower)
+
+	case strings.HasPrefix(f.Field, "metadata."):
+		key := strings.TrimPrefix(f.Field, "metadata.")
+		if key == "" {
+			return "", errors.New("metadata key is required")
+		}
+		k := c.arg(key) + "::text"
+		if cmp, ok := segmentComparisons[f.Op]; ok && f.Op != "eq" && f.Op != "neq" {
+			n, ok := f.Value.(float64)
+			if !ok {
+				return "", errors.New("value must be a number")
+			}
+			return fmt.Sprintf("(CASE WHEN jsonb_typeof(c.metadata->%s) = 'number' THEN (c.metadata->>%s)::numeric END) %s %s::numeric",
+				k, k, cmp, c.arg(n)), nil
+		}
+		if f.Op == "exists" || f.Op == "not_exists" {
+			return "c.metadata->" + k + isNull(f.Op), nil
+		}
+		return c.stringCondition("COALESCE(c.metadata->>"+k+", '')", f, false)
+
+	case strings.HasPrefix(f.Field, "field."):
+		key := strings.TrimPrefix(f.Field, "field.")
+		if key == "" {
+			return "", errors.New("custom field key is required")
+		}
+		return c.customFieldCondition(key, f)
+
+	case f.Field == "tag":
+		tag := `EXISTS (SELECT 1 FROM customer_tags t WHERE t.customer_id = c.id%s)`
+		return c.setCondition(tag, "t.tag", f, true, false)
+
+	case f.Field == "owner":
+		owner := `EXISTS (
+			SELECT 1 FROM customer_owners o
+			JOIN user_organizations m ON m.user_id = o.user_id AND m.org_id = o.org_id
+			WHERE o.customer_id = c.id%s)`
+		return c.setCondition(owner, "o.user_id", f, false, true)
+
+	case f.Field == "plan":
+		plan := `EXISTS (
+			SELECT 1 FROM stripe_subscriptions s
+			WHERE s.customer_id = c.id AND s.status IN ('active', 'trialing', 'past_due')%s)`
+		if f.Op == "contains" {
+			v, ok := f.Value.(string)
+			if !ok || v == "" {
+				return "", errors.New("value must be a non-empty string")
+			}
+			return fmt.Sprintf(plan, " AND s.plan_name ILIKE "+c.arg("%"+v+"%")), nil
+		}
+		return c.setCondition(plan, "LOWER(s.plan_name)", f, true, false)
+	}
+
+	return "", fmt.Errorf("unknown field %q", f.Field)
+}
+
+// customFieldCondition compiles a condition on a custom field's value. Numbers
+// compare as numbers, YYYY-MM-DD strings compare as dates for lt, lte, gt and
+// gte, and everything else compares case-insensitively as text. A customer
+// without a value matches neq, not_in and not_exists.
+func (c *segmentCompiler) customFieldCondition(key string, f SegmentFilter) (string, error) {
+	exists := `EXISTS (
+			SELECT 1 FROM customer_field_values v
+			JOIN custom_fields cf ON cf.id = v.field_id
+			WHERE v.customer_id = c.id AND cf.key = ` + c.arg(key) + `::text%s)`
+
+	var (
+		extra  string
+		negate bool
+	)
+	switch f.Op {
+	case "exists":
+	case "not_exists":
+		negate = true
+	case "eq", "neq":
+		switch v := f.Value.(type) {
+		case float64:
+			extra = " AND v.value_number = " + c.arg(v) + "::numeric"
+		case string, bool:
+			s, _ := scalarString(v)
+			extra = " AND LOWER(v.value_text) = " + c.arg(strings.ToLower(s))
+		default:
+			return "", errors.New("value must be a string, number or boolean")
+		}
+		negate = f.Op == "neq"
+	case "in", "not_in":
+		values, err := stringValues(f.Value, true)
+		if err != nil {
+			return "", err
+		}
+		extra = " AND LOWER(v.value_text) = ANY(" + c.arg(values) + ")"
+		negate = f.Op == "not_in"
+	case "lt", "lte", "gt", "gte":
+		cmp := segmentComparisons[f.Op]
+		switch v := f.Value.(type) {
+		case float64:
+			extra = fmt.Sprintf(" AND v.value_number %s %s::numeric", cmp, c.arg(v))
+		case string:
+			d, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				return "", errors.New("value must be a number or a YYYY-MM-DD date")
+			}
+			extra = fmt.Sprintf(" AND v.value_date %s %s::date", cmp, c.arg(d))
+		default:
+			return "", errors.New("value must be a number or a YYYY-MM-DD date")
+		}
+	case "contains":
+		v, ok := f.Value.(string)
+		if !ok || v == "" {
+			return "", errors.New("value must be a non-empty string")
+		}
+		extra = " AND v.value_text ILIKE " + c.arg("%"+v+"%")
+	default:
+		return "", unsupportedOp(f.Op)
+	}
+
+	cond := fmt.Sprintf(exists, extra)
+	if negate {
+		cond = "NOT " + cond
+	}
+	return cond, nil
+}
+
+// stringCondition compiles eq, neq, in, not_in, contains, exists and
+// not_exists over a text column that is never NULL.
+func (c *segmentCompiler) stringCondition(col string, f SegmentFilter, lower bool) (string, error) {
+	switch f.Op {
+	case "eq", "neq":
+		v, ok := scalarString(f.Value)
+		if !ok {
+			return "", errors.New("value must be a string or number")
+		}
+		if lower {
+			v = strings.ToLower(v)
+		}
+		return fmt.Sprintf("%s %s %s", col, segmentComparisons[f.Op], c.arg(v)), nil
+	case "in", "not_in":
+		values, err := stringValues(f.Value, lower)
+		if err != nil {
+			return "", err
+		}
+		if f.Op == "in" {
+			return fmt.Sprintf("%s = ANY(%s)", col, c.arg(values)), nil
+		}
+		return fmt.Sprintf("%s <> ALL(%s)", col, c.arg(values)), nil
+	case "contains":
+		v, ok := f.Value.(string)
+		if !ok || v == "" {
+			return "", errors.New("value must be a non-empty string")
+		}
+		return fmt.Sprintf("%s ILIKE %s", col, c.arg("%"+v+"%")), nil
+	case "exists":
+		return col + " <> ''", nil
+	case "not_exists":
+		return col + " = ''", nil
+	}
+	return "", unsupportedOp(f.Op)
+}
+
+// setCondition compiles eq, neq, in, not_in, exists and not_exists for fields
+// a customer can have several values of. exists is a subquery with a %s
+// placeholder for the extra condition on col.
+func (c *segmentCompiler) setCondition(exists, col string, f SegmentFilter, lower, ids bool) (string, error) {
+	var (
+		extra  string
+		negate bool
+	)
+	switch f.Op {
+	case "exists":
+	case "not_exists":
+		negate = true
+	case "eq", "neq":
+		v, ok := f.Value.(string)
+		if !ok || v == "" {
+			return "", errors.New("value must be a non-empty string")
+		}
+		var arg any = v
+		if lower {
+			arg = strings.ToLower(v)
+		}
+		if ids {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return "", errors.New("value must be a user ID")
+			}
+			arg = id
+		}
+		extra = fmt.Sprintf(" AND %s = %s", col, c.arg(arg))
+		negate = f.Op == "neq"
+	case "in", "not_in":
+		values, err := stringValues(f.Value, lower)
+		if err != nil {
+			return "", err
+		}
+		var arg any = values
+		if ids {
+			idList := make([]uuid.UUID, len(values))
+			for i, v := range values {
+				if idList[i], err = uuid.Parse(v); err != nil {
+					return "", errors.New("values must be user IDs")
+				}
+			}
+			arg = idList
+		}
+		extra = fmt.Sprintf(" AND %s = ANY(%s)", col, c.arg(arg))
+		negate = f.Op == "not_in"
+	default:
+		return "", unsupportedOp(f.Op)
+	}
+
+	cond := fmt.Sprintf(exists, extra)
+	if negate {
+		cond = "NOT " + cond
+	}
+	return cond, nil
+}
+
+func unsupportedOp(op string) error {
+	return fmt.Errorf("unsupported op %q", op)
+}
+
+func isNull(op string) string {
+	if op == "exists" {
+		return " IS NOT NULL"
+	}
+	return " IS NULL"
+}
+
+func scalarString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func stringValues(v any, lower bool) ([]string, error) {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return nil, errors.New("value must be a non-empty list")
+	}
+	if len(list) > maxSegmentFilterValues {
+		return nil, fmt.Errorf("value can list at most %d entries", maxSegmentFilterValues)
+	}
+	values := make([]string, len(list))
+	for i, item := range list {
+		s, ok := scalarString(item)
+		if !ok {
+			return nil, errors.New("list entries must be strings or numbers")
+		}
+		if lower {
+			s = strings.ToLower(s)
+		}
+		values[i] = s
+	}
+	return values, nil
+}
+
+func validSegmentRisk(v any) bool {
+	risks := map[string]bool{"green": true, "yellow": true, "red": true}
+	if s, ok := v.(string); ok {
+		return risks[s]
+	}
+	if list, ok := v.([]any); ok {
+		for _, item := range list {
+			if s, _ := item.(string); !risks[s] {
+				return false
+			}
+		}
+		return true
+	}
+	return v == nil
+}
+
+// Segment represents a segments row.
+type Segment struct {
+	ID          uuid.UUID     `json:"id"`
+	OrgID       uuid.UUID     `json:"org_id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Filter      SegmentFilter `json:"filter"`
+	CreatedBy   *uuid.UUID    `json:"created_by,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// SegmentStats is the current size of a segment.
+type SegmentStats struct {
+	MemberCount int   `json:"member_count"`
+	MRRCents    int64 `json:"mrr_cents"`
+}
+
+// SegmentCount is a segment's size on a day.
+type SegmentCount struct {
+	Date        string `json:"date"`
+	MemberCount int    `json:"member_count"`
+	MRRCents    int64  `json:"mrr_cents"`
+}
+
+// SegmentSummary holds dashboard figures for a segment's customers. The
+// figures at a past time are for the customers in the segment now.
+type SegmentSummary struct {
+	TotalCustomers int
+	TotalMRRCents  int64
+	Green          int
+	Yellow         int
+	Red            int
+	AvgScore       float64
+	AvgScoreAt     float64
+	AtRiskAt       int
+}
+
+// SegmentRepository handles segments database operations and evaluates
+// segment filters.
+type SegmentRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSegmentRepository creates a new SegmentRepository.
+func NewSegmentRepository(pool *pgxpool.Pool) *SegmentRepository {
+	return &SegmentRepository{pool: pool}
+}
+
+const segmentSelect = `
+	SELECT id, org_id, name, description, filter, created_by, created_at, updated_at
+	FROM segments`
+
+func scanSegment(row pgx.Row) (*Segment, error) {
+	s := &Segment{}
+	err := row.Scan(&s.ID, &s.OrgID, &s.Name, &s.Description, &s.Filter, &s.CreatedBy, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func (r *SegmentRepository) list(ctx context.Context, query string, args ...any) ([]*Segment, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list segments: %w", err)
+	}
+	defer rows.Close()
+
+	var segments []*Segment
+	for rows.Next() {
+		s, err := scanSegment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan segment: %w", err)
+		}
+		segments = append(segments, s)
+	}
+	return segments, rows.Err()
+}
+
+// List returns an org's segments by name.
+func (r *SegmentRepository) List(ctx context.Context, orgID uuid.UUID) ([]*Segment, error) {
+	return r.list(ctx, segmentSelect+` WHERE org_id = $1 ORDER BY name`, orgID)
+}
+
+// ListAll returns the segments of every org.
+func (r *SegmentRepository) ListAll(ctx context.Context) ([]*Segment, error) {
+	return r.list(ctx, segmentSelect+` ORDER BY org_id, name`)
+}
+
+// GetByID returns a segment by ID and org.
+func (r *SegmentRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*Segment, error) {
+	s, err := scanSegment(r.pool.QueryRow(ctx, segmentSelect+` WHERE id = $1 AND org_id = $2`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get segment: %w", err)
+	}
+	return s, nil
+}
+
+// NameExists reports whether another segment of the org has the name.
+func (r *SegmentRepository) NameExists(ctx context.Context, orgID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM segments WHERE org_id = $1 AND name = $2 AND ($3::uuid IS NULL OR id <> $3))`,
+		orgID, name, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check segment name: %w", err)
+	}
+	return exists, nil
+}
+
+// Create inserts a new segment.
+func (r *SegmentRepository) Create(ctx context.Context, s *Segment) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO segments (org_id, name, description, filter, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`,
+		s.OrgID, s.Name, s.Description, s.Filter, s.CreatedBy,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+}
+
+// Update saves a segment's editable fields.
+func (r *SegmentRepository) Update(ctx context.Context, s *Segment) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE segments SET name = $3, description = $4, filter = $5
+		WHERE id = $1 AND org_id = $2
+		RETURNING updated_at`,
+		s.ID, s.OrgID, s.Name, s.Description, s.Filter,
+	).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update segment: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a segment and its count history.
+func (r *SegmentRepository) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM segments WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("delete segment: %w", err)
+	}
+	return nil
+}
+
+// CountAlertRules returns how many alert rules are limited to the segment.
+func (r *SegmentRepository) CountAlertRules(ctx context.Context, id uuid.UUID) (int, error) {
+	var n int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM alert_rules WHERE segment_id = $1`, id).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count segment alert rules: %w", err)
+	}
+	return n, nil
+}
+
+// memberWhere returns the conditions selecting the org's customers that
+// match filter, with the org at $1.
+func memberWhere(orgID uuid.UUID, filter SegmentFilter) (string, []any, error) {
+	cond, args, err := filter.where([]any{orgID})
+	if err != nil {
+		return "", nil, fmt.Errorf("compile segment filter: %w", err)
+	}
+	return "c.org_id = $1 AND c.deleted_at IS NULL AND " + cond, args, nil
+}
+
+// Stats returns how many of an org's customers match filter and their MRR.
+func (r *SegmentRepository) Stats(ctx context.Context, orgID uuid.UUID, filter SegmentFilter) (*SegmentStats, error) {
+	where, args, err := memberWhere(orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &SegmentStats{}
+	err = r.pool.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(c.mrr_cents), 0)
+		FROM customers c
+		LEFT JOIN health_scores hs ON hs.customer_id = c.id
+		WHERE `+where, args...).Scan(&stats.MemberCount, &stats.MRRCents)
+	if err != nil {
+		return nil, fmt.Errorf("segment stats: %w", err)
+	}
+	return stats, nil
+}
+
+// ListMemberIDs returns the IDs of an org's customers that match filter.
+func (r *SegmentRepository) ListMemberIDs(ctx context.Context, orgID uuid.UUID, filter SegmentFilter) ([]uuid.UUID, error) {
+	where, args, err := memberWhere(orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return collectIDs(r.pool.Query(ctx, `
+		SELECT c.id
+		FROM customers c
+		LEFT JOIN health_scores hs ON hs.customer_id = c.id
+		WHERE `+where, args...))
+}
+
+// Contains reports whether a customer of the org matches filter.
+func (r *SegmentRepository) Contains(ctx context.Context, orgID uuid.UUID, filter SegmentFilter, customerID uuid.UUID) (bool, error) {
+	where, args, err := memberWhere(orgID, filter)
+	if err != nil {
+		return false, err
+	}
+
+	args = append(args, customerID)
+	var ok bool
+	err = r.pool.QueryRow(ctx, fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM customers c
+			LEFT JOIN health_scores hs ON hs.customer_id = c.id
+			WHERE %s AND c.id = $%d)`, where, len(args)), args...).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check segment membership: %w", err)
+	}
+	return ok, nil
+}
+
+// Summarize returns dashboard figures for the org's customers that match
+// filter, along with their average score and at-risk count at a past time.
+func (r *SegmentRepository) Summarize(ctx context.Context, orgID uuid.UUID, filter SegmentFilter, at time.Time) (*SegmentSummary, error) {
+	where, args, err := memberWhere(orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	args = append(args, at)
+	s := &SegmentSummary{}
+	err = r.pool.QueryRow(ctx, fmt.Sprintf(`
+		WITH members AS (
+			SELECT c.id, c.mrr_cents, hs.overall_score, hs.risk_level
+			FROM customers c
+			LEFT JOIN health_scores hs ON hs.customer_id = c.id
+			WHERE %s
+		), past AS (
+			SELECT DISTINCT ON (h.customer_id) h.overall_score, h.risk_level
+			FROM health_score_history h
+			WHERE h.customer_id IN (SELECT id FROM members) AND h.calculated_at <= $%d
+			ORDER BY h.customer_id, h.calculated_at DESC
+		)
+		SELECT COUNT(*), COALESCE(SUM(mrr_cents), 0),
+			COUNT(*) FILTER (WHERE risk_level = 'green'),
+			COUNT(*) FILTER (WHERE risk_level = 'yellow'),
+			COUNT(*) FILTER (WHERE risk_level = 'red'),
+			COALESCE(AVG(overall_score), 0),
+			(SELECT COALESCE(AVG(overall_score), 0) FROM past),
+			(SELECT COUNT(*) FROM past WHERE risk_level = 'red')
+		FROM members`, where, len(args)), args...).Scan(
+		&s.TotalCustomers, &s.TotalMRRCents, &s.Green, &s.Yellow, &s.Red,
+		&s.AvgScore, &s.AvgScoreAt, &s.AtRiskAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summarize segment: %w", err)
+	}
+	return s, nil
+}
+
+// RecordCount stores a segment's size for today, replacing an earlier count
+// from the same day.
+func (r *SegmentRepository) RecordCount(ctx context.Context, segmentID uuid.UUID, stats *SegmentStats) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO segment_counts (segment_id, recorded_on, member_count, mrr_cents)
+		VALUES ($1, CURRENT_DATE, $2, $3)
+		ON CONFLICT (segment_id, recorded_on)
+		DO UPDATE SET member_count = EXCLUDED.member_count, mrr_cents = EXCLUDED.mrr_cents, recorded_at = NOW()`,
+		segmentID, stats.MemberCount, stats.MRRCents)
+	if err != nil {
+		return fmt.Errorf("record segment count: %w", err)
+	}
+	return nil
+}
+
+// ListCounts returns a segment's daily sizes since a date, oldest first.
+func (r *SegmentRepository) ListCounts(ctx context.Context, segmentID uuid.UUID, since time.Time) ([]SegmentCount, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT to_char(recorded_on, 'YYYY-MM-DD'), member_count, mrr_cents
+		FROM segment_counts
+		WHERE segment_id = $1 AND recorded_on >= $2::date
+		ORDER BY recorded_on`, segmentID, since)
+	if err != nil {
+		return nil, fmt.Errorf("list segment counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []SegmentCount{}
+	for rows.Next() {
+		var c SegmentCount
+		if err := rows.Scan(&c.Date, &c.MemberCount, &c.MRRCents); err != nil {
+			return nil, fmt.Errorf("scan segment count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// compactSQL collapses the whitespace of multi-line subqueries so expected
+// conditions can be written on one line.
+func compactSQL(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.ReplaceAll(s, "( ", "(")
+	return strings.ReplaceAll(s, " )", ")")
+}
+
+func TestSegmentFilterWhere(t *testing.T) {
+	ownerID := uuid.MustParse("2e4f6a8b-0c1d-4e3f-9a5b-7c9d1e3f5a7b")
+	const (
+		tagExists   = "EXISTS (SELECT 1 FROM customer_tags t WHERE t.customer_id = c.id"
+		ownerExists = "EXISTS (SELECT 1 FROM customer_owners o JOIN user_organizations m ON m.user_id = o.user_id AND m.org_id = o.org_id WHERE o.customer_id = c.id"
+		planExists  = "EXISTS (SELECT 1 FROM stripe_subscriptions s WHERE s.customer_id = c.id AND s.status IN ('active', 'trialing', 'past_due')"
+		fieldExists = "EXISTS (SELECT 1 FROM customer_field_values v JOIN custom_fields cf ON cf.id = v.field_id WHERE v.customer_id = c.id AND cf.key = $2::text"
+	)
+
+	tests := []struct {
+		name     string
+		filter   SegmentFilter
+		wantCond string
+		wantArgs []any
+	}{
+		{"score lt", SegmentFilter{Field: "score", Op: "lt", Value: 50.0}, "(hs.overall_score < $2::numeric)", []any{50.0}},
+		{"score neq", SegmentFilter{Field: "score", Op: "neq", Value: 50.0}, "(hs.overall_score <> $2::numeric)", []any{50.0}},
+		{"score exists", SegmentFilter{Field: "score", Op: "exists"}, "(hs.overall_score IS NOT NULL)", nil},
+		{"score not_exists", SegmentFilter{Field: "score", Op: "not_exists"}, "(hs.overall_score IS NULL)", nil},
+		{"mrr gte", SegmentFilter{Field: "mrr_cents", Op: "gte", Value: 1000.0}, "(c.mrr_cents >= $2::numeric)", []any{1000.0}},
+		{"risk eq", SegmentFilter{Field: "risk", Op: "eq", Value: "red"}, "(COALESCE(hs.risk_level, '') = $2)", []any{"red"}},
+		{"risk in", SegmentFilter{Field: "risk", Op: "in", Value: []any{"red", "yellow"}}, "(COALESCE(hs.risk_level, '') = ANY($2))", []any{[]string{"red", "yellow"}}},
+		{"risk not_exists", SegmentFilter{Field: "risk", Op: "not_exists"}, "(COALESCE(hs.risk_level, '') = '')", nil},
+		{"source neq", SegmentFilter{Field: "source", Op: "neq", Value: "stripe"}, "(c.source <> $2)", []any{"stripe"}},
+		{"source not_in", SegmentFilter{Field: "source", Op: "not_in", Value: []any{"stripe", "hubspot"}}, "(c.source <> ALL($2))", []any{[]string{"stripe", "hubspot"}}},
+		{"source exists", SegmentFilter{Field: "source", Op: "exists"}, "(c.source <> '')", nil},
+		{"company eq is case-insensitive", SegmentFilter{Field: "company", Op: "eq", Value: "Acme"}, "(LOWER(COALESCE(c.company_name, '')) = $2)", []any{"acme"}},
+		{"company contains", SegmentFilter{Field: "company", Op: "contains", Value: "Ac"}, "(LOWER(COALESCE(c.company_name, '')) ILIKE $2)", []any{"%Ac%"}},
+		{"metadata eq", SegmentFilter{Field: "metadata.tier", Op: "eq", Value: "gold"}, "(COALESCE(c.metadata->>$2::text, '') = $3)", []any{"tier", "gold"}},
+		{"metadata eq number", SegmentFilter{Field: "metadata.seats", Op: "eq", Value: 10.0}, "(COALESCE(c.metadata->>$2::text, '') = $3)", []any{"seats", "10"}},
+		{
+			"metadata gt compares numbers",
+			SegmentFilter{Field: "metadata.seats", Op: "gt", Value: 10.0},
+			"((CASE WHEN jsonb_typeof(c.metadata->$2::text) = 'number' THEN (c.metadata->>$2::text)::numeric END) > $3::numeric)",
+			[]any{"seats", 10.0},
+		},
+		{"metadata exists", SegmentFilter{Field: "metadata.seats", Op: "exists"}, "(c.metadata->$2::text IS NOT NULL)", []any{"seats"}},
+		{"tag eq", SegmentFilter{Field: "tag", Op: "eq", Value: "VIP"}, "(" + tagExists + " AND t.tag = $2))", []any{"vip"}},
+		{"tag not_in", SegmentFilter{Field: "tag", Op: "not_in", Value: []any{"VIP", "beta"}}, "(NOT " + tagExists + " AND t.tag = ANY($2)))", []any{[]string{"vip", "beta"}}},
+		{"tag not_exists", SegmentFilter{Field: "tag", Op: "not_exists"}, "(NOT " + tagExists + "))", nil},
+		{"owner eq", SegmentFilter{Field: "owner", Op: "eq", Value: ownerID.String()}, "(" + ownerExists + " AND o.user_id = $2))", []any{ownerID}},
+		{"owner in", SegmentFilter{Field: "owner", Op: "in", Value: []any{ownerID.String()}}, "(" + ownerExists + " AND o.user_id = ANY($2)))", []any{[]uuid.UUID{ownerID}}},
+		{"plan neq", SegmentFilter{Field: "plan", Op: "neq", Value: "Pro"}, "(NOT " + planExists + " AND LOWER(s.plan_name) = $2))", []any{"pro"}},
+		{"plan contains", SegmentFilter{Field: "plan", Op: "contains", Value: "pro"}, "(" + planExists + " AND s.plan_name ILIKE $2))", []any{"%pro%"}},
+		{"plan exists", SegmentFilter{Field: "plan", Op: "exists"}, "(" + planExists + "))", nil},
+		{"field eq number", SegmentFilter{Field: "field.seats", Op: "eq", Value: 5.0}, "(" + fieldExists + " AND v.value_number = $3::numeric))", []any{"seats", 5.0}},
+		{"field eq bool", SegmentFilter{Field: "field.active", Op: "eq", Value: true}, "(" + fieldExists + " AND LOWER(v.value_text) = $3))", []any{"active", "true"}},
+		{"field neq", SegmentFilter{Field: "field.industry", Op: "neq", Value: "Retail"}, "(NOT " + fieldExists + " AND LOWER(v.value_text) = $3))", []any{"industry", "retail"}},
+		{"field in", SegmentFilter{Field: "field.industry", Op: "in", Value: []any{"Retail"}}, "(" + fieldExists + " AND LOWER(v.value_text) = ANY($3)))", []any{"industry", []string{"retail"}}},
+		{"field gte number", SegmentFilter{Field: "field.seats", Op: "gte", Value: 5.0}, "(" + fieldExists + " AND v.value_number >= $3::numeric))", []any{"seats", 5.0}},
+		{
+			"field lt date",
+			SegmentFilter{Field: "field.renewal", Op: "lt", Value: "2026-01-01"},
+			"(" + fieldExists + " AND v.value_date < $3::date))",
+			[]any{"renewal", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{"field contains", SegmentFilter{Field: "field.notes", Op: "contains", Value: "churn"}, "(" + fieldExists + " AND v.value_text ILIKE $3))", []any{"notes", "%churn%"}},
+		{"field not_exists", SegmentFilter{Field: "field.industry", Op: "not_exists"}, "(NOT " + fieldExists + "))", []any{"industry"}},
+		{
+			"all",
+			SegmentFilter{All: []SegmentFilter{{Field: "risk", Op: "eq", Value: "red"}, {Field: "mrr_cents", Op: "gte", Value: 1000.0}}},
+			"((COALESCE(hs.risk_level, '') = $2) AND (c.mrr_cents >= $3::numeric))",
+			[]any{"red", 1000.0},
+		},
+		{
+			"any",
+			SegmentFilter{Any: []SegmentFilter{{Field: "risk", Op: "eq", Value: "red"}, {Field: "score", Op: "lt", Value: 40.0}}},
+			"((COALESCE(hs.risk_level, '') = $2) OR (hs.overall_score < $3::numeric))",
+			[]any{"red", 40.0},
+		},
+		{
+			"not counts a missing score as not matching",
+			SegmentFilter{Not: &SegmentFilter{Field: "score", Op: "lt", Value: 50.0}},
+			"NOT COALESCE((hs.overall_score < $2::numeric), false)",
+			[]any{50.0},
+		},
+		{
+			"nested",
+			SegmentFilter{All: []SegmentFilter{
+				{Field: "tag", Op: "eq", Value: "vip"},
+				{Any: []SegmentFilter{{Field: "score", Op: "lt", Value: 50.0}, {Not: &SegmentFilter{Field: "risk", Op: "eq", Value: "green"}}}},
+			}},
+			"((" + tagExists + " AND t.tag = $2)) AND ((hs.overall_score < $3::numeric) OR NOT COALESCE((COALESCE(hs.risk_level, '') = $4), false)))",
+			[]any{"vip", 50.0, "green"},
+		},
+	}
+	for _, tt := range tests {
+		orgID := uuid.New()
+		cond, args, err := tt.filter.where([]any{orgID})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got := compactSQL(cond); got != tt.wantCond {
+			t.Errorf("%s: expected condition\n  %s\ngot\n  %s", tt.name, tt.wantCond, got)
+		}
+		wantArgs := append([]any{orgID}, tt.wantArgs...)
+		if !reflect.DeepEqual(args, wantArgs) {
+			t.Errorf("%s: expected args %#v, got %#v", tt.name, wantArgs, args)
+		}
+	}
+}
+
+func TestSegmentFilterWhere_Invalid(t *testing.T) {
+	nest := func(depth int) SegmentFilter {
+		f := SegmentFilter{Field: "score", Op: "exists"}
+		for i := 1; i < depth; i++ {
+			child := f
+			f = SegmentFilter{Not: &child}
+		}
+		return f
+	}
+	conditions := func(n int) SegmentFilter {
+		all := make([]SegmentFilter, n)
+		for i := range all {
+			all[i] = SegmentFilter{Field: "score", Op: "exists"}
+		}
+		return SegmentFilter{All: all}
+	}
+	values := func(n int) []any {
+		list := make([]any, n)
+		for i := range list {
+			list[i] = "v"
+		}
+		return list
+	}
+
+	if err := nest(maxSegmentFilterDepth).Validate(); err != nil {
+		t.Errorf("expected %d levels to be allowed, got %v", maxSegmentFilterDepth, err)
+	}
+	if err := conditions(maxSegmentFilterConditions).Validate(); err != nil {
+		t.Errorf("expected %d conditions to be allowed, got %v", maxSegmentFilterConditions, err)
+	}
+	if err := (SegmentFilter{Field: "tag", Op: "in", Value: values(maxSegmentFilterValues)}).Validate(); err != nil {
+		t.Errorf("expected %d values to be allowed, got %v", maxSegmentFilterValues, err)
+	}
+
+	tests := []struct {
+		name   string
+		filter SegmentFilter
+	}{
+		{"too deep", nest(maxSegmentFilterDepth + 1)},
+		{"too many conditions", conditions(maxSegmentFilterConditions + 1)},
+		{"too many values", SegmentFilter{Field: "tag", Op: "in", Value: values(maxSegmentFilterValues + 1)}},
+		{"empty node", SegmentFilter{}},
+		{"two kinds in one node", SegmentFilter{Field: "score", Op: "exists", Not: &SegmentFilter{Field: "score", Op: "exists"}}},
+		{"empty all", SegmentFilter{All: []SegmentFilter{}}},
+		{"empty any", SegmentFilter{Any: []SegmentFilter{}}},
+		{"invalid child", SegmentFilter{Any: []SegmentFilter{{Field: "score", Op: "exists"}, {Field: "nope", Op: "eq"}}}},
+		{"unknown field", SegmentFilter{Field: "nope", Op: "eq", Value: "x"}},
+		{"score needs a number", SegmentFilter{Field: "score", Op: "lt", Value: "50"}},
+		{"score contains", SegmentFilter{Field: "score", Op: "contains", Value: 50.0}},
+		{"mrr exists", SegmentFilter{Field: "mrr_cents", Op: "exists"}},
+		{"unknown risk", SegmentFilter{Field: "risk", Op: "eq", Value: "orange"}},
+		{"unknown risk in list", SegmentFilter{Field: "risk", Op: "in", Value: []any{"red", "orange"}}},
+		{"risk lt", SegmentFilter{Field: "risk", Op: "lt", Value: "red"}},
+		{"empty list", SegmentFilter{Field: "source", Op: "in", Value: []any{}}},
+		{"empty contains", SegmentFilter{Field: "company", Op: "contains", Value: ""}},
+		{"metadata without key", SegmentFilter{Field: "metadata.", Op: "exists"}},
+		{"metadata gt needs a number", SegmentFilter{Field: "metadata.seats", Op: "gt", Value: "10"}},
+		{"field without key", SegmentFilter{Field: "field.", Op: "exists"}},
+		{"field lt needs a number or date", SegmentFilter{Field: "field.renewal", Op: "lt", Value: "soon"}},
+		{"tag needs a value", SegmentFilter{Field: "tag", Op: "eq", Value: ""}},
+		{"tag contains", SegmentFilter{Field: "tag", Op: "contains", Value: "vip"}},
+		{"owner needs a user ID", SegmentFilter{Field: "owner", Op: "eq", Value: "me"}},
+		{"owner list needs user IDs", SegmentFilter{Field: "owner", Op: "in", Value: []any{"me"}}},
+	}
+	for _, tt := range tests {
+		if err := tt.filter.Validate(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -234,6 +234,28 @@ func (r *StripePaymentRepository) ListByCustomer(ctx context.Context, customerID
 	return payments, rows.Err()
 }
 
+// GetByStripeID retrieves a payment by its Stripe charge or invoice ID.
+func (r *StripePaymentRepository) GetByStripeID(ctx context.Context, stripePaymentID string) (*StripePayment, error) {
+	query := `
+		SELECT id, org_id, customer_id, stripe_payment_id, amount_cents, currency, status,
+			COALESCE(failure_code, ''), COALESCE(failure_message, ''), paid_at, created_at
+		FROM stripe_payments
+		WHERE stripe_payment_id = $1`
+
+	p := &StripePayment{}
+	err := r.pool.QueryRow(ctx, query, stripePaymentID).Scan(
+		&p.ID, &p.OrgID, &p.CustomerID, &p.StripePaymentID, &p.AmountCents, &p.Currency, &p.Status,
+		&p.FailureCode, &p.FailureMessage, &p.PaidAt, &p.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get payment by stripe id: %w", err)
+	}
+	return p, nil
+}
+
 // CountFailedByCustomerInWindow returns the count of failed payments for a customer in a time window.
 func (r *StripePaymentRepository) CountFailedByCustomerInWindow(ctx context.Context, customerID uuid.UUID, since time.Time) (int, error) {
 	query := `SELECT COUNT(*) FROM stripe_payments WHERE customer_id = $1 AND status = 'failed' AND COALESCE(paid_at, created_at) >= $2`
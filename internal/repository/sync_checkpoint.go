@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SyncCheckpoint represents a sync_checkpoints row: the cursor of the next
+// page to fetch for one object type of a provider sync.
+type SyncCheckpoint struct {
+	OrgID      uuid.UUID `json:"org_id"`
+	Provider   string    `json:"provider"`
+	ObjectType string    `json:"object_type"`
+	Cursor     string    `json:"cursor"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// SyncCheckpointRepository handles sync_checkpoints database operations.
+type SyncCheckpointRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSyncCheckpointRepository creates a new SyncCheckpointRepository.
+func NewSyncCheckpointRepository(pool *pgxpool.Pool) *SyncCheckpointRepository {
+	return &SyncCheckpointRepository{pool: pool}
+}
+
+// Get returns the checkpoint for an object type, or nil if there is none.
+func (r *SyncCheckpointRepository) Get(ctx context.Context, orgID uuid.UUID, provider, objectType string) (*SyncCheckpoint, error) {
+	cp := &SyncCheckpoint{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT org_id, provider, object_type, cursor, created_at, updated_at
+		FROM sync_checkpoints
+		WHERE org_id = $1 AND provider = $2 AND object_type = $3
+	`, orgID, provider, objectType).Scan(
+		&cp.OrgID, &cp.Provider, &cp.ObjectType, &cp.Cursor, &cp.CreatedAt, &cp.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get sync checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// Save stores the cursor for an object type.
+func (r *SyncCheckpointRepository) Save(ctx context.Context, orgID uuid.UUID, provider, objectType, cursor string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO sync_checkpoints (org_id, provider, object_type, cursor)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (org_id, provider, object_type) DO UPDATE SET cursor = EXCLUDED.cursor
+	`, orgID, provider, objectType, cursor)
+	if err != nil {
+		return fmt.Errorf("save sync checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the checkpoint for an object type.
+func (r *SyncCheckpointRepository) Delete(ctx context.Context, orgID uuid.UUID, provider, objectType string) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM sync_checkpoints WHERE org_id = $1 AND provider = $2 AND object_type = $3
+	`, orgID, provider, objectType)
+	if err != nil {
+		return fmt.Errorf("delete sync checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ExistsForProvider reports whether an org has an unfinished sync for a provider.
+func (r *SyncCheckpointRepository) ExistsForProvider(ctx context.Context, orgID uuid.UUID, provider string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sync_checkpoints WHERE org_id = $1 AND provider = $2)
+	`, orgID, provider).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check sync checkpoints: %w", err)
+	}
+	return exists, nil
+}
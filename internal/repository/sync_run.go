@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SyncRunStep holds the progress counts of one step of a sync run.
+type SyncRunStep struct {
+	Step     string `json:"step"`
+	Total    int    `json:"total"`
+	Current  int    `json:"current"`
+	Errors   int    `json:"errors"`
+	Expected int    `json:"expected,omitempty"`
+}
+
+// SyncRun represents a sync_runs row.
+type SyncRun struct {
+	ID         uuid.UUID     `json:"id"`
+	OrgID      uuid.UUID     `json:"org_id"`
+	Provider   string        `json:"provider"`
+	Mode       string        `json:"mode"`    // full, incremental
+	Trigger    string        `json:"trigger"` // scheduler, manual, webhook
+	Status     string        `json:"status"`  // running, succeeded, paused, failed
+	Steps      []SyncRunStep `json:"steps"`
+	Error      string        `json:"error,omitempty"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	DurationMs *int64        `json:"duration_ms,omitempty"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// SyncRunRepository handles sync_runs database operations.
+type SyncRunRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSyncRunRepository creates a new SyncRunRepository.
+func NewSyncRunRepository(pool *pgxpool.Pool) *SyncRunRepository {
+	return &SyncRunRepository{pool: pool}
+}
+
+const syncRunColumns = `id, org_id, provider, mode, trigger, status, steps, COALESCE(error, ''),
+	started_at, finished_at, duration_ms, updated_at`
+
+func scanSyncRun(row pgx.Row) (*SyncRun, error) {
+	run := &SyncRun{}
+	err := row.Scan(
+		&run.ID, &run.OrgID, &run.Provider, &run.Mode, &run.Trigger, &run.Status, &run.Steps,
+		&run.Error, &run.StartedAt, &run.FinishedAt, &run.DurationMs, &run.UpdatedAt,
+	)
+	return run, err
+}
+
+// Create inserts a running sync run.
+func (r *SyncRunRepository) Create(ctx context.Context, run *SyncRun) error {
+	if run.Steps == nil {
+		run.Steps = []SyncRunStep{}
+	}
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO sync_runs (org_id, provider, mode, trigger, steps)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, started_at, updated_at
+	`, run.OrgID, run.Provider, run.Mode, run.Trigger, run.Steps,
+	).Scan(&run.ID, &run.Status, &run.StartedAt, &run.UpdatedAt)
+}
+
+// UpdateSteps stores the live step progress of a running sync.
+func (r *SyncRunRepository) UpdateSteps(ctx context.Context, id uuid.UUID, steps []SyncRunStep) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE sync_runs SET steps = $2 WHERE id = $1 AND status = 'running'
+	`, id, steps)
+	if err != nil {
+		return fmt.Errorf("update sync run steps: %w", err)
+	}
+	return nil
+}
+
+// Finish records the outcome of a sync run.
+func (r *SyncRunRepository) Finish(ctx context.Context, id uuid.UUID, status string, steps []SyncRunStep, errMsg string, finishedAt time.Time, durationMs int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE sync_runs
+		SET status = $2, steps = $3, error = NULLIF($4, ''), finished_at = $5, duration_ms = $6
+		WHERE id = $1
+	`, id, status, steps, errMsg, finishedAt, durationMs)
+	if err != nil {
+		return fmt.Errorf("finish sync run: %w", err)
+	}
+	return nil
+}
+
+// ListByOrgAndProvider returns an org's sync runs for a provider, newest first, with the total count.
+func (r *SyncRunRepository) ListByOrgAndProvider(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*SyncRun, int, error) {
+	var total int
+	if err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM sync_runs WHERE org_id = $1 AND provider = $2
+	`, orgID, provider).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count sync runs: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+syncRunColumns+`
+		FROM sync_runs
+		WHERE org_id = $1 AND provider = $2
+		ORDER BY started_at DESC
+		LIMIT $3 OFFSET $4
+	`, orgID, provider, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*SyncRun
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan sync run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, total, rows.Err()
+}
+
+// FailStale marks running syncs that have not reported progress since the
+// cutoff as failed, e.g. after the process running them exited.
+func (r *SyncRunRepository) FailStale(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE sync_runs
+		SET status = 'failed', error = 'sync interrupted', finished_at = NOW(),
+		    duration_ms = (EXTRACT(EPOCH FROM NOW() - started_at) * 1000)::BIGINT
+		WHERE status = 'running' AND updated_at < $1
+	`, before)
+	if err != nil {
+		return 0, fmt.Errorf("fail stale sync runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
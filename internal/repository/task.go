@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Task statuses.
+const (
+	TaskStatusOpen       = "open"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+	TaskStatusCancelled  = "cancelled"
+)
+
+// Task represents a tasks row with the names of its customer and assignee.
+type Task struct {
+	ID             uuid.UUID  `json:"id"`
+	OrgID          uuid.UUID  `json:"org_id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status"`
+	DueAt          *time.Time `json:"due_at"`
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	AssigneeName   string     `json:"assignee_name"`
+	CustomerID     *uuid.UUID `json:"customer_id"`
+	CustomerName   string     `json:"customer_name"`
+	AlertHistoryID *uuid.UUID `json:"alert_history_id"`
+	CreatedBy      *uuid.UUID `json:"created_by"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	RemindedAt     *time.Time `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TaskListParams holds pagination and filter params for task listing.
+type TaskListParams struct {
+	OrgID   uuid.UUID
+	Page    int
+	PerPage int
+	Sort    string
+	Order   string
+	// Statuses limits the list to tasks in any of the given statuses.
+	Statuses []string
+	// AssigneeID limits the list to a user's tasks; Unassigned to tasks
+	// nobody is assigned to.
+	AssigneeID     *uuid.UUID
+	Unassigned     bool
+	CustomerID     *uuid.UUID
+	AlertHistoryID *uuid.UUID
+	// Overdue limits the list to open or in-progress tasks past their due date.
+	Overdue   bool
+	DueAfter  *time.Time
+	DueBefore *time.Time
+}
+
+// TaskListResult holds a page of tasks with pagination info.
+type TaskListResult struct {
+	Tasks      []*Task
+	Total      int
+	Page       int
+	PerPage    int
+	TotalPages int
+}
+
+// TaskRepository handles tasks database operations.
+type TaskRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTaskRepository creates a new TaskRepository.
+func NewTaskRepository(pool *pgxpool.Pool) *TaskRepository {
+	return &TaskRepository{pool: pool}
+}
+
+const taskSelect = `
+	SELECT t.id, t.org_id, t.title, t.description, t.status, t.due_at,
+		t.assignee_id, ` + userDisplayName + `, t.customer_id, COALESCE(c.name, c.email, ''),
+		t.alert_history_id, t.created_by, t.completed_at, t.reminded_at, t.created_at, t.updated_at
+	FROM tasks t
+	LEFT JOIN users u ON u.id = t.assignee_id
+	LEFT JOIN customers c ON c.id = t.customer_id`
+
+func scanTask(row pgx.Row) (*Task, error) {
+	t := &Task{}
+	err := row.Scan(
+		&t.ID, &t.OrgID, &t.Title, &t.Description, &t.Status, &t.DueAt,
+		&t.AssigneeID, &t.AssigneeName, &t.CustomerID, &t.CustomerName,
+		&t.AlertHistoryID, &t.CreatedBy, &t.CompletedAt, &t.RemindedAt, &t.CreatedAt, &t.UpdatedAt,
+	)
+	return t, err
+}
+
+// Create inserts a new task.
+func (r *TaskRepository) Create(ctx context.Context, t *Task) error {
+	if t.Status == "" {
+		t.Status = TaskStatusOpen
+	}
+
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO tasks (org_id, title, description, status, due_at, assignee_id, customer_id, alert_history_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at`,
+		t.OrgID, t.Title, t.Description, t.Status, t.DueAt, t.AssigneeID,
+		t.CustomerID, t.AlertHistoryID, t.CreatedBy,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+// GetByID returns a task by ID and org.
+func (r *TaskRepository) GetByID(ctx context.Context, id, orgID uuid.UUID) (*Task, error) {
+	t, err := scanTask(r.pool.QueryRow(ctx, taskSelect+` WHERE t.id = $1 AND t.org_id = $2`, id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get task: %w", err)
+	}
+	return t, nil
+}
+
+// List returns a filtered page of an org's tasks.
+func (r *TaskRepository) List(ctx context.Context, params TaskListParams) (*TaskListResult, error) {
+	where := "t.org_id = $1"
+	args := []any{params.OrgID}
+	argIdx := 2
+
+	if len(params.Statuses) > 0 {
+		where += fmt.Sprintf(" AND t.status = ANY($%d)", argIdx)
+		args = append(args, params.Statuses)
+		argIdx++
+	}
+	if params.AssigneeID != nil {
+		where += fmt.Sprintf(" AND t.assignee_id = $%d", argIdx)
+		args = append(args, *params.AssigneeID)
+		argIdx++
+	}
+	if params.Unassigned {
+		where += " AND t.assignee_id IS NULL"
+	}
+	if params.CustomerID != nil {
+		where += fmt.Sprintf(" AND t.customer_id = $%d", argIdx)
+		args = append(args, *params.CustomerID)
+		argIdx++
+	}
+	if params.AlertHistoryID != nil {
+		where += fmt.Sprintf(" AND t.alert_history_id = $%d", argIdx)
+		args = append(args, *params.AlertHistoryID)
+		argIdx++
+	}
+	if params.Overdue {
+		where += " AND t.status IN ('open', 'in_progress') AND t.due_at < NOW()"
+	}
+	if params.DueAfter != nil {
+		where += fmt.Sprintf(" AND t.due_at >= $%d", argIdx)
+		args = append(args, *params.DueAfter)
+		argIdx++
+	}
+	if params.DueBefore != nil {
+		where += fmt.Sprintf(" AND t.due_at < $%d", argIdx)
+		args = append(args, *params.DueBefore)
+		argIdx++
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM tasks t WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count tasks: %w", err)
+	}
+
+	totalPages := 0
+	if params.PerPage > 0 {
+		totalPages = (total + params.PerPage - 1) / params.PerPage
+	}
+
+	sortColumn := "t.due_at"
+	sortAllowlist := map[string]string{
+		"due":     "t.due_at",
+		"created": "t.created_at",
+		"updated": "t.updated_at",
+		"title":   "t.title",
+	}
+	if col, ok := sortAllowlist[params.Sort]; ok {
+		sortColumn = col
+	}
+
+	order := "ASC"
+	if params.Order == "desc" {
+		order = "DESC"
+	}
+
+	offset := (params.Page - 1) * params.PerPage
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(`%s
+		WHERE %s
+		ORDER BY %s %s NULLS LAST, t.created_at DESC
+		LIMIT $%d OFFSET $%d`,
+		taskSelect, where, sortColumn, order, argIdx, argIdx+1),
+		append(args, params.PerPage, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &TaskListResult{
+		Tasks:      tasks,
+		Total:      total,
+		Page:       params.Page,
+		PerPage:    params.PerPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Update saves a task's editable fields. Moving the due date or assignee
+// clears reminded_at so the new assignee is reminded when it is missed.
+func (r *TaskRepository) Update(ctx context.Context, t *Task) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE tasks
+		SET title = $3, description = $4, status = $5, due_at = $6, assignee_id = $7, completed_at = $8,
+			reminded_at = CASE
+				WHEN due_at IS NOT DISTINCT FROM $6 AND assignee_id IS NOT DISTINCT FROM $7 THEN reminded_at
+			END
+		WHERE id = $1 AND org_id = $2
+		RETURNING reminded_at, updated_at`,
+		t.ID, t.OrgID, t.Title, t.Description, t.Status, t.DueAt, t.AssigneeID, t.CompletedAt,
+	).Scan(&t.RemindedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update task: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a task.
+func (r *TaskRepository) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM tasks WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	return nil
+}
+
+// ClaimOverdue marks up to limit assigned, overdue tasks as reminded and
+// returns them. A task is claimed again once repeatAfter has passed since its
+// last reminder, and concurrent workers never claim the same task.
+func (r *TaskRepository) ClaimOverdue(ctx context.Context, repeatAfter time.Duration, limit int) ([]*Task, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH claimed AS (
+			UPDATE tasks SET reminded_at = NOW()
+			WHERE id IN (
+				SELECT id FROM tasks
+				WHERE status IN ('open', 'in_progress')
+					AND assignee_id IS NOT NULL
+					AND due_at < NOW()
+					AND (reminded_at IS NULL OR reminded_at < NOW() - make_interval(secs => $1))
+				ORDER BY due_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED)
+			RETURNING id
+		)`+taskSelect+`
+		WHERE t.id IN (SELECT id FROM claimed)
+		ORDER BY t.due_at`,
+		repeatAfter.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim overdue tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WebhookInboxEvent represents a webhook_inbox row.
+type WebhookInboxEvent struct {
+	ID            uuid.UUID  `json:"id"`
+	Provider      string     `json:"provider"`
+	EventID       string     `json:"event_id"`
+	EventType     string     `json:"event_type"`
+	OrgID         *uuid.UUID `json:"org_id,omitempty"`
+	Payload       []byte     `json:"-"`
+	Status        string     `json:"status"` // pending, processing, processed, failed, dead
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	ReceivedAt    time.Time  `json:"received_at"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+}
+
+// WebhookInboxRepository handles webhook_inbox database operations.
+type WebhookInboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWebhookInboxRepository creates a new WebhookInboxRepository.
+func NewWebhookInboxRepository(pool *pgxpool.Pool) *WebhookInboxRepository {
+	return &WebhookInboxRepository{pool: pool}
+}
+
+const webhookInboxColumns = `id, provider, event_id, event_type, org_id, payload, status, attempts,
+	next_attempt_at, COALESCE(last_error, ''), received_at, processed_at`
+
+func scanWebhookInboxEvent(row pgx.Row) (*WebhookInboxEvent, error) {
+	e := &WebhookInboxEvent{}
+	err := row.Scan(
+		&e.ID, &e.Provider, &e.EventID, &e.EventType, &e.OrgID, &e.Payload,
+		&e.Status, &e.Attempts, &e.NextAttemptAt, &e.LastError, &e.ReceivedAt, &e.ProcessedAt,
+	)
+	return e, err
+}
+
+// Insert stores an inbound event. It returns false when the provider already
+// delivered an event with the same ID.
+func (r *WebhookInboxRepository) Insert(ctx context.Context, e *WebhookInboxEvent) (bool, error) {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_inbox (provider, event_id, event_type, org_id, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider, event_id) DO NOTHING
+		RETURNING id, status, next_attempt_at, received_at
+	`, e.Provider, e.EventID, e.EventType, e.OrgID, e.Payload,
+	).Scan(&e.ID, &e.Status, &e.NextAttemptAt, &e.ReceivedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("insert webhook inbox event: %w", err)
+	}
+	return true, nil
+}
+
+// ClaimDue marks up to limit due events as processing and returns them. Events
+// left in processing longer than staleAfter (e.g. by a crashed worker) are
+// reclaimed. Rows locked by another instance are skipped.
+func (r *WebhookInboxRepository) ClaimDue(ctx context.Context, now time.Time, staleAfter time.Duration, limit int) ([]*WebhookInboxEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE webhook_inbox
+		SET status = 'processing', attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM webhook_inbox
+			WHERE (status IN ('pending', 'failed') AND next_attempt_at <= $1)
+			   OR (status = 'processing' AND updated_at < $2)
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookInboxColumns,
+		now, now.Add(-staleAfter), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook inbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*WebhookInboxEvent
+	for rows.Next() {
+		e, err := scanWebhookInboxEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook inbox event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// MarkProcessed marks an event as successfully processed.
+func (r *WebhookInboxRepository) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_inbox
+		SET status = 'processed', processed_at = NOW(), last_error = NULL
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("mark webhook inbox event processed: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt. status is failed (retried at nextAttempt) or dead.
+func (r *WebhookInboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, status, errorMsg string, nextAttempt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_inbox
+		SET status = $2, last_error = $3, next_attempt_at = $4
+		WHERE id = $1
+	`, id, status, errorMsg, nextAttempt)
+	if err != nil {
+		return fmt.Errorf("mark webhook inbox event failed: %w", err)
+	}
+	return nil
+}
+
+// ListByOrgAndStatus returns an org's events in the given statuses, newest first, with the total count.
+func (r *WebhookInboxRepository) ListByOrgAndStatus(ctx context.Context, orgID uuid.UUID, statuses []string, limit, offset int) ([]*WebhookInboxEvent, int, error) {
+	var total int
+	if err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM webhook_inbox WHERE org_id = $1 AND status = ANY($2)
+	`, orgID, statuses).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count webhook inbox events: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookInboxColumns+`
+		FROM webhook_inbox
+		WHERE org_id = $1 AND status = ANY($2)
+		ORDER BY received_at DESC
+		LIMIT $3 OFFSET $4
+	`, orgID, statuses, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list webhook inbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*WebhookInboxEvent
+	for rows.Next() {
+		e, err := scanWebhookInboxEvent(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan webhook inbox event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, total, rows.Err()
+}
+
+// Requeue resets a failed or dead event of an org so workers pick it up again.
+// It returns nil when no such event exists.
+func (r *WebhookInboxRepository) Requeue(ctx context.Context, id, orgID uuid.UUID) (*WebhookInboxEvent, error) {
+	e, err := scanWebhookInboxEvent(r.pool.QueryRow(ctx, `
+		UPDATE webhook_inbox
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND org_id = $2 AND status IN ('failed', 'dead')
+		RETURNING `+webhookInboxColumns,
+		id, orgID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("requeue webhook inbox event: %w", err)
+	}
+	return e, nil
+}
+
+// DeleteProcessedBefore removes processed events received before the cutoff.
+func (r *WebhookInboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM webhook_inbox WHERE status = 'processed' AND received_at < $1
+	`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete processed webhook inbox events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QuarantinedWebhook represents a webhook_quarantine row.
+type QuarantinedWebhook struct {
+	ID         uuid.UUID  `json:"id"`
+	Provider   string     `json:"provider"`
+	EventID    string     `json:"event_id"`
+	EventType  string     `json:"event_type"`
+	AccountID  string     `json:"account_id"`
+	Payload    []byte     `json:"-"`
+	Reason     string     `json:"reason"`
+	ReceivedAt time.Time  `json:"received_at"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+// WebhookQuarantineRepository handles webhook_quarantine database operations.
+type WebhookQuarantineRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWebhookQuarantineRepository creates a new WebhookQuarantineRepository.
+func NewWebhookQuarantineRepository(pool *pgxpool.Pool) *WebhookQuarantineRepository {
+	return &WebhookQuarantineRepository{pool: pool}
+}
+
+// Insert quarantines an event. Redeliveries of the same event are ignored.
+func (r *WebhookQuarantineRepository) Insert(ctx context.Context, q *QuarantinedWebhook) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_quarantine (provider, event_id, event_type, account_id, payload, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (provider, event_id) DO NOTHING
+	`, q.Provider, q.EventID, q.EventType, q.AccountID, q.Payload, q.Reason)
+	if err != nil {
+		return fmt.Errorf("insert quarantined webhook: %w", err)
+	}
+	return nil
+}
+
+// ListUnreleased returns an account's quarantined events, oldest first.
+func (r *WebhookQuarantineRepository) ListUnreleased(ctx context.Context, provider, accountID string) ([]*QuarantinedWebhook, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, provider, event_id, event_type, account_id, payload, reason, received_at, released_at
+		FROM webhook_quarantine
+		WHERE provider = $1 AND account_id = $2 AND released_at IS NULL
+		ORDER BY received_at
+	`, provider, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("list quarantined webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*QuarantinedWebhook
+	for rows.Next() {
+		q := &QuarantinedWebhook{}
+		if err := rows.Scan(
+			&q.ID, &q.Provider, &q.EventID, &q.EventType, &q.AccountID,
+			&q.Payload, &q.Reason, &q.ReceivedAt, &q.ReleasedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan quarantined webhook: %w", err)
+		}
+		events = append(events, q)
+	}
+	return events, rows.Err()
+}
+
+// MarkReleased records that a quarantined event was handed to the inbox.
+func (r *WebhookQuarantineRepository) MarkReleased(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE webhook_quarantine SET released_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("mark quarantined webhook released: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// Account sources, in the order a customer is matched to them.
+const (
+	AccountSourceHubSpot = "hubspot"
+	AccountSourceDomain  = "domain"
+	AccountSourceStripe  = "stripe"
+)
+
+// AccountService builds accounts from HubSpot companies, corporate email
+// domains and Stripe customers, links customers to them, and serves the
+// account views.
+type AccountService struct {
+	accounts         *repository.AccountRepository
+	accountScores    *repository.AccountHealthScoreRepository
+	customers        *repository.CustomerRepository
+	hubspotContacts  *repository.HubSpotContactRepository
+	hubspotCompanies *repository.HubSpotCompanyRepository
+}
+
+// NewAccountService creates a new AccountService.
+func NewAccountService(
+	accounts *repository.AccountRepository,
+	accountScores *repository.AccountHealthScoreRepository,
+	customers *repository.CustomerRepository,
+	hubspotContacts *repository.HubSpotContactRepository,
+	hubspotCompanies *repository.HubSpotCompanyRepository,
+) *AccountService {
+	return &AccountService{
+		accounts:         accounts,
+		accountScores:    accountScores,
+		customers:        customers,
+		hubspotContacts:  hubspotContacts,
+		hubspotCompanies: hubspotCompanies,
+	}
+}
+
+// AccountSyncResult summarizes an account sync.
+type AccountSyncResult struct {
+	Accounts int `json:"accounts"`
+	Linked   int `json:"linked"`
+	Removed  int `json:"removed"`
+}
+
+type accountKey struct {
+	source     string
+	externalID string
+}
+
+// Sync rebuilds an org's accounts and re-links its customers. A customer
+// belongs to its HubSpot contact's company if it has one, otherwise to the
+// account for its email domain (a HubSpot company with that domain, or a
+// domain account), otherwise, if it came from Stripe, to an account of its
+// own. Customers on free mailbox domains without a company stay unlinked.
+func (s *AccountService) Sync(ctx context.Context, orgID uuid.UUID) (*AccountSyncResult, error) {
+	customers, err := s.customers.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list customers: %w", err)
+	}
+	companies, err := s.hubspotCompanies.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list hubspot companies: %w", err)
+	}
+	contacts, err := s.hubspotContacts.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list hubspot contacts: %w", err)
+	}
+
+	accounts := map[accountKey]*repository.Account{}
+	var order []accountKey
+	addAccount := func(k accountKey, a *repository.Account) *repository.Account {
+		if existing, ok := accounts[k]; ok {
+			return existing
+		}
+		a.OrgID = orgID
+		a.Source = k.source
+		a.ExternalID = k.externalID
+		accounts[k] = a
+		order = append(order, k)
+		return a
+	}
+
+	// Every HubSpot company is an account, with or without contacts
+	companyByDomain := map[string]accountKey{}
+	for _, c := range companies {
+		k := accountKey{AccountSourceHubSpot, c.HubSpotCompanyID}
+		domain := normalizeDomain(c.Domain)
+		name := c.Name
+		if name == "" {
+			name = domain
+		}
+		if name == "" {
+			name = c.HubSpotCompanyID
+		}
+		addAccount(k, &repository.Account{Name: name, Domain: domain})
+		if _, ok := companyByDomain[domain]; domain != "" && !ok {
+			companyByDomain[domain] = k
+		}
+	}
+
+	companyByCustomer := map[uuid.UUID]accountKey{}
+	for _, ct := range contacts {
+		if ct.CustomerID == nil || ct.HubSpotCompanyID == "" {
+			continue
+		}
+		k := accountKey{AccountSourceHubSpot, ct.HubSpotCompanyID}
+		if _, ok := accounts[k]; ok {
+			companyByCustomer[*ct.CustomerID] = k
+		}
+	}
+
+	var (
+		customerIDs []uuid.UUID
+		keys        []accountKey
+	)
+	for _, c := range customers {
+		k, ok := companyByCustomer[c.ID]
+		if !ok {
+			if domain := companyEmailDomain(c.Email); domain != "" {
+				if k, ok = companyByDomain[domain]; !ok {
+					k, ok = accountKey{AccountSourceDomain, domain}, true
+					a := addAccount(k, &repository.Account{Name: domain, Domain: domain})
+					if a.Name == domain && c.CompanyName != "" {
+						a.Name = c.CompanyName
+					}
+				}
+			} else if c.Source == AccountSourceStripe && c.ExternalID != "" {
+				k, ok = accountKey{AccountSourceStripe, c.ExternalID}, true
+				addAccount(k, &repository.Account{Name: firstNonEmpty(c.CompanyName, c.Name, c.Email, c.ExternalID)})
+			}
+		}
+		if ok {
+			customerIDs = append(customerIDs, c.ID)
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range order {
+		if err := s.accounts.Upsert(ctx, accounts[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	accountIDs := make([]uuid.UUID, len(keys))
+	for i, k := range keys {
+		accountIDs[i] = accounts[k].ID
+	}
+	if err := s.accounts.AssignCustomers(ctx, orgID, customerIDs, accountIDs); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.accounts.DeleteEmpty(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.RefreshMRR(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	result := &AccountSyncResult{Accounts: len(order), Linked: len(customerIDs), Removed: removed}
+	slog.Info("accounts synced", "org_id", orgID, "accounts", result.Accounts, "linked", result.Linked, "removed", result.Removed)
+	return result, nil
+}
+
+// AccountListResponse is the JSON response for the account list.
+type AccountListResponse struct {
+	Accounts   []repository.AccountWithScore `json:"accounts"`
+	Pagination PaginationMeta                `json:"pagination"`
+}
+
+// List returns a paginated list of accounts with their rolled-up scores.
+func (s *AccountService) List(ctx context.Context, params repository.AccountListParams) (*AccountListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PerPage < 1 {
+		params.PerPage = 25
+	}
+	if params.PerPage > 100 {
+		params.PerPage = 100
+	}
+
+	validSorts := map[string]bool{"name": true, "mrr": true, "score": true, "contacts": true}
+	if !validSorts[params.Sort] {
+		params.Sort = "name"
+	}
+	if params.Order != "asc" && params.Order != "desc" {
+		params.Order = "asc"
+	}
+
+	validRisks := map[string]bool{"green": true, "yellow": true, "red": true}
+	if params.Risk != "" && !validRisks[params.Risk] {
+		return nil, &ValidationError{Field: "risk", Message: "invalid risk level"}
+	}
+	validSources := map[string]bool{AccountSourceHubSpot: true, AccountSourceDomain: true, AccountSourceStripe: true}
+	if params.Source != "" && !validSources[params.Source] {
+		return nil, &ValidationError{Field: "source", Message: "invalid account source"}
+	}
+
+	result, err := s.accounts.ListWithScores(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+
+	accounts := result.Accounts
+	if accounts == nil {
+		accounts = []repository.AccountWithScore{}
+	}
+
+	return &AccountListResponse{
+		Accounts: accounts,
+		Pagination: PaginationMeta{
+			Page:       result.Page,
+			PerPage:    result.PerPage,
+			Total:      result.Total,
+			TotalPages: result.TotalPages,
+		},
+	}, nil
+}
+
+// AccountDetail is the full detail response for an account.
+type AccountDetail struct {
+	Account     *repository.Account            `json:"account"`
+	HealthScore *repository.AccountHealthScore `json:"health_score"`
+	Contacts    []*repository.AccountContact   `json:"contacts"`
+}
+
+// GetDetail returns an account with its rolled-up score and contacts.
+func (s *AccountService) GetDetail(ctx context.Context, accountID, orgID uuid.UUID) (*AccountDetail, error) {
+	account, err := s.accounts.GetByIDAndOrg(ctx, accountID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, &NotFoundError{Resource: "account", Message: "account not found"}
+	}
+
+	detail := &AccountDetail{Account: account}
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		var err error
+		detail.HealthScore, err = s.accountScores.GetByAccountID(gctx, accountID, orgID)
+		return err
+	})
+
+	g.Go(func() error {
+		var err error
+		detail.Contacts, err = s.accounts.ListContacts(gctx, accountID, orgID)
+		return err
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("get account detail: %w", err)
+	}
+
+	if detail.Contacts == nil {
+		detail.Contacts = []*repository.AccountContact{}
+	}
+	return detail, nil
+}
+
+// companyEmailDomain returns the domain of an email address, or "" if it has
+// none or belongs to a free mailbox provider.
+func companyEmailDomain(email string) string {
+	email = normalizeEmail(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	if domain := email[at+1:]; !freeEmailDomains[domain] {
+		return domain
+	}
+	return ""
+}
+
+// normalizeDomain reduces a website or domain to its lowercase host without
+// a leading www.
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "https://")
+	domain = strings.TrimPrefix(domain, "http://")
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// maxBacktestAlerts caps the number of individual alerts returned by a backtest.
+const maxBacktestAlerts = 500
+
+// BacktestAlert is a single alert that a rule would have fired during a backtest.
+type BacktestAlert struct {
+	CustomerID    uuid.UUID      `json:"customer_id"`
+	CustomerName  string         `json:"customer_name"`
+	CustomerEmail string         `json:"customer_email"`
+	FiredAt       time.Time      `json:"fired_at"`
+	TriggerData   map[string]any `json:"trigger_data"`
+}
+
+// BacktestResult summarizes what a rule would have done over a historical range.
+type BacktestResult struct {
+	From                 time.Time       `json:"from"`
+	To                   time.Time       `json:"to"`
+	CooldownHours        int             `json:"cooldown_hours"`
+	TotalAlerts          int             `json:"total_alerts"`
+	CustomersAffected    int             `json:"customers_affected"`
+	SuppressedByCooldown int             `json:"suppressed_by_cooldown"`
+	Alerts               []BacktestAlert `json:"alerts"`
+	Truncated            bool            `json:"truncated"`
+}
+
+// backtestCandidate is a point in time where the rule condition held for a customer.
+type backtestCandidate struct {
+	customerID  uuid.UUID
+	at          time.Time
+	triggerData map[string]any
+}
+
+// Backtest replays a (possibly unsaved) rule against health score history and
+// customer events between from and to. It applies the same cooldown policy as
+// live evaluation but never records history or sends notifications.
+func (e *AlertEngine) Backtest(ctx context.Context, rule *repository.AlertRule, from, to time.Time) (*BacktestResult, error) {
+	var (
+		candidates []backtestCandidate
+		err        error
+	)
+
+	switch rule.TriggerType {
+	case "score_below":
+		candidates, err = e.backtestScoreBelow(ctx, rule, from, to)
+	case "score_drop":
+		candidates, err = e.backtestScoreDrop(ctx, rule, from, to)
+	case "risk_change":
+		candidates, err = e.backtestRiskChange(ctx, rule, from, to)
+	case "payment_failed":
+		candidates, err = e.backtestEventTrigger(ctx, rule, from, to, "payment.failed")
+	case "billing_event":
+		candidates, err = e.backtestEventTrigger(ctx, rule, from, to, getConditionString(rule.Conditions, "event_type"))
+	default:
+		return nil, fmt.Errorf("unknown trigger type: %s", rule.TriggerType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	customers, err := e.customers.ListByOrg(ctx, rule.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("list customers: %w", err)
+	}
+	customerByID := make(map[uuid.UUID]*repository.Customer, len(customers))
+	for _, c := range customers {
+		customerByID[c.ID] = c
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].at.Before(candidates[j].at)
+	})
+
+	result := &BacktestResult{
+		From:          from,
+		To:            to,
+		CooldownHours: int(e.defaultCooldown / time.Hour),
+		Alerts:        []BacktestAlert{},
+	}
+
+	lastFired := make(map[uuid.UUID]time.Time)
+	for _, c := range candidates {
+		customer, ok := customerByID[c.customerID]
+		if !ok {
+			continue
+		}
+
+		if last, ok := lastFired[c.customerID]; ok && c.at.Before(last.Add(e.defaultCooldown)) {
+			result.SuppressedByCooldown++
+			continue
+		}
+		lastFired[c.customerID] = c.at
+
+		result.TotalAlerts++
+		if len(result.Alerts) >= maxBacktestAlerts {
+			result.Truncated = true
+			continue
+		}
+		result.Alerts = append(result.Alerts, BacktestAlert{
+			CustomerID:    customer.ID,
+			CustomerName:  customer.Name,
+			CustomerEmail: customer.Email,
+			FiredAt:       c.at,
+			TriggerData:   c.triggerData,
+		})
+	}
+	result.CustomersAffected = len(lastFired)
+
+	return result, nil
+}
+
+func (e *AlertEngine) backtestScoreBelow(ctx context.Context, rule *repository.AlertRule, from, to time.Time) ([]backtestCandidate, error) {
+	threshold := getConditionInt(rule.Conditions, "threshold", 40)
+
+	history, err := e.healthScores.ListHistoryByOrgRange(ctx, rule.OrgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []backtestCandidate
+	for _, score := range history {
+		if score.OverallScore >= threshold {
+			continue
+		}
+		candidates = append(candidates, backtestCandidate{
+			customerID: score.CustomerID,
+			at:         score.CalculatedAt,
+			triggerData: map[string]any{
+				"customer_id": score.CustomerID.String(),
+				"score":       score.OverallScore,
+				"threshold":   threshold,
+				"risk_level":  score.RiskLevel,
+			},
+		})
+	}
+	return candidates, nil
+}
+
+func (e *AlertEngine) backtestScoreDrop(ctx context.Context, rule *repository.AlertRule, from, to time.Time) ([]backtestCandidate, error) {
+	points := getConditionInt(rule.Conditions, "points", 10)
+	days := getConditionInt(rule.Conditions, "days", 7)
+	lookback := time.Duration(days) * 24 * time.Hour
+
+	// Load enough history before the range to compare the first points against.
+	history, err := e.healthScores.ListHistoryByOrgRange(ctx, rule.OrgID, from.Add(-lookback), to)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []backtestCandidate
+	// History is ordered by customer then time, so walk each customer's run.
+	for start := 0; start < len(history); {
+		end := start
+		for end < len(history) && history[end].CustomerID == history[start].CustomerID {
+			end++
+		}
+		run := history[start:end]
+
+		base := -1
+		for i, current := range run {
+			for base+1 < i && !run[base+1].CalculatedAt.After(current.CalculatedAt.Add(-lookback)) {
+				base++
+			}
+			if current.CalculatedAt.Before(from) || base < 0 {
+				continue
+			}
+
+			historical := run[base]
+			delta := current.OverallScore - historical.OverallScore
+			if delta >= 0 || -delta < points {
+				continue
+			}
+
+			candidates = append(candidates, backtestCandidate{
+				customerID: current.CustomerID,
+				at:         current.CalculatedAt,
+				triggerData: map[string]any{
+					"customer_id":                 current.CustomerID.String(),
+					"old_score":                   historical.OverallScore,
+					"new_score":                   current.OverallScore,
+					"delta":                       delta,
+					"days":                        days,
+					"biggest_contributing_factor": lowestFactor(current.Factors),
+					"risk_level":                  current.RiskLevel,
+				},
+			})
+		}
+		start = end
+	}
+	return candidates, nil
+}
+
+func (e *AlertEngine) backtestRiskChange(ctx context.Context, rule *repository.AlertRule, from, to time.Time) ([]backtestCandidate, error) {
+	events, err := e.events.ListByOrgTypeAndRange(ctx, rule.OrgID, "risk_level.changed", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	condFrom, _ := rule.Conditions["from"].(string)
+	condTo, _ := rule.Conditions["to"].(string)
+
+	var candidates []backtestCandidate
+	for _, event := range events {
+		fromLevel, _ := event.Data["previous_level"].(string)
+		toLevel, _ := event.Data["new_level"].(string)
+		if condFrom != "" && fromLevel != condFrom {
+			continue
+		}
+		if condTo != "" && toLevel != condTo {
+			continue
+		}
+
+		candidates = append(candidates, backtestCandidate{
+			customerID: event.CustomerID,
+			at:         event.OccurredAt,
+			triggerData: map[string]any{
+				"customer_id":    event.CustomerID.String(),
+				"previous_level": fromLevel,
+				"new_level":      toLevel,
+				"score":          event.Data["score"],
+			},
+		})
+	}
+	return candidates, nil
+}
+
+func (e *AlertEngine) backtestEventTrigger(ctx context.Context, rule *repository.AlertRule, from, to time.Time, eventType string) ([]backtestCandidate, error) {
+	events, err := e.events.ListByOrgTypeAndRange(ctx, rule.OrgID, eventType, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]backtestCandidate, 0, len(events))
+	for _, event := range events {
+		triggerData := map[string]any{
+			"customer_id": event.CustomerID.String(),
+			"event_type":  eventType,
+			"event_id":    event.ID.String(),
+			"occurred_at": event.OccurredAt.Format(time.RFC3339),
+		}
+		for k, v := range event.Data {
+			triggerData[k] = v
+		}
+
+		candidates = append(candidates, backtestCandidate{
+			customerID:  event.CustomerID,
+			at:          event.OccurredAt,
+			triggerData: triggerData,
+		})
+	}
+	return candidates, nil
+}
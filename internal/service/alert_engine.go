@@ -13,13 +13,31 @@ import (
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
-// AlertMatch represents a rule that matched for a specific customer.
+// AlertMatch represents a rule that matched for a specific customer, or for
+// an account when the rule has an account trigger type.
 type AlertMatch struct {
 	Rule        *repository.AlertRule
 	Customer    *repository.Customer
+	Account     *repository.Account
 	TriggerData map[string]any
 }
 
+// subjectName returns the name of the customer or account the alert is about.
+func (m AlertMatch) subjectName() string {
+	if m.Account != nil {
+		return m.Account.Name
+	}
+	return m.Customer.Name
+}
+
+// subjectPath returns the app path of the customer or account the alert is about.
+func (m AlertMatch) subjectPath() string {
+	if m.Account != nil {
+		return fmt.Sprintf("/accounts/%s", m.Account.ID)
+	}
+	return fmt.Sprintf("/customers/%s", m.Customer.ID)
+}
+
 // AlertEngine evaluates alert rules against current data.
 type AlertEngine struct {
 	alertRules     *repository.AlertRuleRepository
@@ -27,6 +45,11 @@ type AlertEngine struct {
 	healthScores   *repository.HealthScoreRepository
 	customers      *repository.CustomerRepository
 	events         *repository.CustomerEventRepository
+	accounts       *repository.AccountRepository
+	accountScores  *repository.AccountHealthScoreRepository
+	segments       *repository.SegmentRepository
+	customFields   *repository.CustomFieldRepository
+	renewals       *repository.RenewalRepository
 	defaultCooldown time.Duration
 }
 
@@ -37,6 +60,11 @@ func NewAlertEngine(
 	healthScores *repository.HealthScoreRepository,
 	customers *repository.CustomerRepository,
 	events *repository.CustomerEventRepository,
+	accounts *repository.AccountRepository,
+	accountScores *repository.AccountHealthScoreRepository,
+	segments *repository.SegmentRepository,
+	customFields *repository.CustomFieldRepository,
+	renewals *repository.RenewalRepository,
 	defaultCooldownHours int,
 ) *AlertEngine {
 	return &AlertEngine{
@@ -45,6 +73,11 @@ func NewAlertEngine(
 		healthScores:    healthScores,
 		customers:       customers,
 		events:          events,
+		accounts:        accounts,
+		accountScores:   accountScores,
+		segments:        segments,
+		customFields:    customFields,
+		renewals:        renewals,
 		defaultCooldown: time.Duration(defaultCooldownHours) * time.Hour,
 	}
 }
@@ -73,8 +106,40 @@ func (e *AlertEngine) EvaluateAll(ctx context.Context, orgID uuid.UUID) ([]Alert
 	return allMatches, nil
 }
 
-// EvaluateRule evaluates a single rule against all customers in an org.
+// EvaluateRule evaluates a single rule against all customers in an org, or
+// only those in the rule's segment.
 func (e *AlertEngine) EvaluateRule(ctx context.Context, rule *repository.AlertRule, orgID uuid.UUID) ([]AlertMatch, error) {
+	matches, err := e.evaluateRule(ctx, rule, orgID)
+	if err != nil || rule.SegmentID == nil || len(matches) == 0 {
+		return matches, err
+	}
+
+	segment, err := e.segments.GetByID(ctx, *rule.SegmentID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if segment == nil {
+		return nil, fmt.Errorf("segment %s not found", *rule.SegmentID)
+	}
+	memberIDs, err := e.segments.ListMemberIDs(ctx, orgID, segment.Filter)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[uuid.UUID]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		members[id] = true
+	}
+
+	inSegment := matches[:0]
+	for _, m := range matches {
+		if m.Customer != nil && members[m.Customer.ID] {
+			inSegment = append(inSegment, m)
+		}
+	}
+	return inSegment, nil
+}
+
+func (e *AlertEngine) evaluateRule(ctx context.Context, rule *repository.AlertRule, orgID uuid.UUID) ([]AlertMatch, error) {
 	switch rule.TriggerType {
 	case "score_below":
 		return e.evaluateScoreBelow(ctx, rule, orgID)
@@ -84,6 +149,16 @@ func (e *AlertEngine) EvaluateRule(ctx context.Context, rule *repository.AlertRu
 		return e.evaluateRiskChange(ctx, rule, orgID)
 	case "payment_failed":
 		return e.evaluateEventTrigger(ctx, rule, orgID, "payment.failed")
+	case "billing_event":
+		return e.evaluateEventTrigger(ctx, rule, orgID, getConditionString(rule.Conditions, "event_type"))
+	case "custom_field":
+		return e.evaluateCustomField(ctx, rule, orgID)
+	case "renewal_upcoming":
+		return e.evaluateRenewalUpcoming(ctx, rule, orgID)
+	case "account_score_below":
+		return e.evaluateAccountScoreBelow(ctx, rule, orgID)
+	case "account_risk_change":
+		return e.evaluateAccountRiskChange(ctx, rule, orgID)
 	default:
 		return nil, fmt.Errorf("unknown trigger type: %s", rule.TriggerType)
 	}
@@ -120,7 +195,53 @@ func (e *AlertEngine) EvaluateForCustomer(ctx context.Context, customerID, orgID
 	return allMatches, nil
 }
 
+// EvaluateForAccount evaluates all active account rules for a single account (used by real-time hook).
+func (e *AlertEngine) EvaluateForAccount(ctx context.Context, accountID, orgID uuid.UUID) ([]AlertMatch, error) {
+	rules, err := e.alertHistory.ListActiveRulesByOrg(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list active rules: %w", err)
+	}
+
+	account, err := e.accounts.GetByIDAndOrg(ctx, accountID, orgID)
+	if err != nil || account == nil {
+		return nil, err
+	}
+
+	var allMatches []AlertMatch
+	for _, rule := range rules {
+		var match *AlertMatch
+		switch rule.TriggerType {
+		case "account_score_below":
+			match, err = e.evaluateAccountScoreBelowForAccount(ctx, rule, account)
+		case "account_risk_change":
+			match, err = e.evaluateAccountRiskChangeForAccount(ctx, rule, account)
+		default:
+			continue
+		}
+		if err != nil {
+			slog.Error("rule evaluation error for account",
+				"rule_id", rule.ID,
+				"account_id", accountID,
+				"error", err,
+			)
+			continue
+		}
+		if match != nil {
+			allMatches = append(allMatches, *match)
+		}
+	}
+
+	return allMatches, nil
+}
+
 func (e *AlertEngine) evaluateRuleForCustomer(ctx context.Context, rule *repository.AlertRule, customer *repository.Customer) (*AlertMatch, error) {
+	if rule.SegmentID != nil {
+		inSegment, err := e.inSegment(ctx, *rule.SegmentID, customer)
+		if err != nil || !inSegment {
+			return nil, err
+		}
+	}
+
 	switch rule.TriggerType {
 	case "score_below":
 		return e.evaluateScoreBelowForCustomer(ctx, rule, customer)
@@ -130,11 +251,29 @@ func (e *AlertEngine) evaluateRuleForCustomer(ctx context.Context, rule *reposit
 		return e.evaluateRiskChangeForCustomer(ctx, rule, customer)
 	case "payment_failed":
 		return e.evaluateEventTriggerForCustomer(ctx, rule, customer, "payment.failed")
+	case "billing_event":
+		return e.evaluateEventTriggerForCustomer(ctx, rule, customer, getConditionString(rule.Conditions, "event_type"))
+	case "custom_field":
+		return e.evaluateCustomFieldForCustomer(ctx, rule, customer)
+	case "renewal_upcoming":
+		return e.evaluateRenewalUpcomingForCustomer(ctx, rule, customer)
 	default:
 		return nil, nil
 	}
 }
 
+// inSegment reports whether a customer is in a segment of its org.
+func (e *AlertEngine) inSegment(ctx context.Context, segmentID uuid.UUID, customer *repository.Customer) (bool, error) {
+	segment, err := e.segments.GetByID(ctx, segmentID, customer.OrgID)
+	if err != nil {
+		return false, err
+	}
+	if segment == nil {
+		return false, fmt.Errorf("segment %s not found", segmentID)
+	}
+	return e.segments.Contains(ctx, customer.OrgID, segment.Filter, customer.ID)
+}
+
 // evaluateScoreBelow checks for customers with score below threshold.
 func (e *AlertEngine) evaluateScoreBelow(ctx context.Context, rule *repository.AlertRule, orgID uuid.UUID) ([]AlertMatch, error) {
 	threshold := getConditionInt(rule.Conditions, "threshold", 40)
@@ -250,18 +389,6 @@ func (e *AlertEngine) checkScoreDrop(ctx context.Context, rule *repository.Alert
 		return nil, nil
 	}
 
-	// Find biggest negative factor
-	biggestFactor := ""
-	if current.Factors != nil {
-		minVal := 100.0
-		for k, v := range current.Factors {
-			if v < minVal {
-				minVal = v
-				biggestFactor = k
-			}
-		}
-	}
-
 	return &AlertMatch{
 		Rule:     rule,
 		Customer: customer,
@@ -271,7 +398,7 @@ func (e *AlertEngine) checkScoreDrop(ctx context.Context, rule *repository.Alert
 			"new_score":                  current.OverallScore,
 			"delta":                      delta,
 			"days":                       days,
-			"biggest_contributing_factor": biggestFactor,
+			"biggest_contributing_factor": lowestFactor(current.Factors),
 			"risk_level":                 current.RiskLevel,
 		},
 	}, nil
@@ -403,6 +530,306 @@ func (e *AlertEngine) checkEventTrigger(ctx context.Context, rule *repository.Al
 	}, nil
 }
 
+// customFieldRuleFilter returns the filter a custom_field rule's conditions
+// test: {field, op, value} on the custom field with key field.
+func customFieldRuleFilter(conditions map[string]any) repository.SegmentFilter {
+	return repository.SegmentFilter{
+		Field: "field." + getConditionString(conditions, "field"),
+		Op:    getConditionString(conditions, "op"),
+		Value: conditions["value"],
+	}
+}
+
+// evaluateCustomField checks for customers whose custom field value meets
+// the rule's condition.
+func (e *AlertEngine) evaluateCustomField(ctx context.Context, rule *repository.AlertRule, orgID uuid.UUID) ([]AlertMatch, error) {
+	field, err := e.customFields.GetByKey(ctx, orgID, getConditionString(rule.Conditions, "field"))
+	if err != nil || field == nil {
+		return nil, err
+	}
+
+	customerIDs, err := e.segments.ListMemberIDs(ctx, orgID, customFieldRuleFilter(rule.Conditions))
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []AlertMatch
+	for _, customerID := range customerIDs {
+		if e.isInCooldown(ctx, rule.ID, customerID) {
+			continue
+		}
+
+		customer, err := e.customers.GetByIDAndOrg(ctx, customerID, orgID)
+		if err != nil || customer == nil {
+			continue
+		}
+		matches = append(matches, e.customFieldMatch(ctx, rule, field, customer))
+	}
+	return matches, nil
+}
+
+func (e *AlertEngine) evaluateCustomFieldForCustomer(ctx context.Context, rule *repository.AlertRule, customer *repository.Customer) (*AlertMatch, error) {
+	field, err := e.customFields.GetByKey(ctx, customer.OrgID, getConditionString(rule.Conditions, "field"))
+	if err != nil || field == nil {
+		return nil, err
+	}
+
+	ok, err := e.segments.Contains(ctx, customer.OrgID, customFieldRuleFilter(rule.Conditions), customer.ID)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	if e.isInCooldown(ctx, rule.ID, customer.ID) {
+		return nil, nil
+	}
+
+	match := e.customFieldMatch(ctx, rule, field, customer)
+	return &match, nil
+}
+
+// customFieldMatch builds a custom_field match carrying the field and the
+// customer's current value, if it has one.
+func (e *AlertEngine) customFieldMatch(ctx context.Context, rule *repository.AlertRule, field *repository.CustomField, customer *repository.Customer) AlertMatch {
+	triggerData := map[string]any{
+		"customer_id": customer.ID.String(),
+		"field":       field.Key,
+		"label":       field.Label,
+		"op":          getConditionString(rule.Conditions, "op"),
+		"value":       rule.Conditions["value"],
+	}
+	if v, err := e.customFields.GetValue(ctx, customer.ID, field.ID); err == nil && v != nil {
+		triggerData["field_value"] = v.Text
+	}
+
+	return AlertMatch{
+		Rule:        rule,
+		Customer:    customer,
+		TriggerData: triggerData,
+	}
+}
+
+// renewalRuleParams returns the renewal window a renewal_upcoming rule's
+// conditions cover, from today to days ahead, and its optional max_score.
+func renewalRuleParams(conditions map[string]any) (from, to time.Time, maxScore *int) {
+	from = renewalToday()
+	to = from.AddDate(0, 0, getConditionInt(conditions, "days", 30))
+	if _, ok := conditions["max_score"]; ok {
+		score := getConditionInt(conditions, "max_score", 100)
+		maxScore = &score
+	}
+	return from, to, maxScore
+}
+
+// evaluateRenewalUpcoming checks for customers renewing within the rule's
+// window, optionally only those scoring at or below max_score. Each renewal
+// is alerted once per rule.
+func (e *AlertEngine) evaluateRenewalUpcoming(ctx context.Context, rule *repository.AlertRule, orgID uuid.UUID) ([]AlertMatch, error) {
+	from, to, maxScore := renewalRuleParams(rule.Conditions)
+	renewals, err := e.renewals.List(ctx, repository.RenewalListParams{
+		OrgID:    orgID,
+		From:     &from,
+		To:       &to,
+		MaxScore: maxScore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []AlertMatch
+	for _, renewal := range renewals {
+		if e.renewalAlerted(ctx, rule.ID, renewal) {
+			continue
+		}
+
+		customer, err := e.customers.GetByIDAndOrg(ctx, renewal.CustomerID, orgID)
+		if err != nil || customer == nil {
+			continue
+		}
+		matches = append(matches, renewalMatch(rule, customer, renewal, from))
+	}
+	return matches, nil
+}
+
+func (e *AlertEngine) evaluateRenewalUpcomingForCustomer(ctx context.Context, rule *repository.AlertRule, customer *repository.Customer) (*AlertMatch, error) {
+	renewal, err := e.renewals.GetByCustomer(ctx, customer.OrgID, customer.ID)
+	if err != nil || renewal == nil {
+		return nil, err
+	}
+
+	from, to, maxScore := renewalRuleParams(rule.Conditions)
+	if renewal.RenewalDate.Before(from) || renewal.RenewalDate.After(to) {
+		return nil, nil
+	}
+	if maxScore != nil && (renewal.OverallScore == nil || *renewal.OverallScore > *maxScore) {
+		return nil, nil
+	}
+	if e.renewalAlerted(ctx, rule.ID, renewal) {
+		return nil, nil
+	}
+
+	match := renewalMatch(rule, customer, renewal, from)
+	return &match, nil
+}
+
+// renewalAlerted reports whether the rule's last alert for the customer was
+// about the same renewal date.
+func (e *AlertEngine) renewalAlerted(ctx context.Context, ruleID uuid.UUID, renewal *repository.Renewal) bool {
+	last, err := e.alertHistory.GetLastAlertForRule(ctx, ruleID, renewal.CustomerID)
+	if err != nil || last == nil {
+		return false
+	}
+	return last.TriggerData["renewal_date"] == renewal.RenewalDate.Format(renewalDateLayout)
+}
+
+// renewalMatch builds a renewal_upcoming match carrying the renewal and the
+// customer's health score.
+func renewalMatch(rule *repository.AlertRule, customer *repository.Customer, renewal *repository.Renewal, today time.Time) AlertMatch {
+	triggerData := map[string]any{
+		"customer_id":  customer.ID.String(),
+		"renewal_date": renewal.RenewalDate.Format(renewalDateLayout),
+		"days_until":   renewalDaysUntil(renewal.RenewalDate, today),
+		"amount_cents": int(renewal.AmountCents),
+		"currency":     renewal.Currency,
+		"source":       renewal.Source,
+		"days":         getConditionInt(rule.Conditions, "days", 30),
+	}
+	if renewal.OverallScore != nil {
+		triggerData["score"] = *renewal.OverallScore
+	}
+	if renewal.RiskLevel != nil {
+		triggerData["risk_level"] = *renewal.RiskLevel
+	}
+
+	return AlertMatch{
+		Rule:        rule,
+		Customer:    customer,
+		TriggerData: triggerData,
+	}
+}
+
+// evaluateAccountScoreBelow checks for accounts with a rolled-up score below threshold.
+func (e *AlertEngine) evaluateAccountScoreBelow(ctx context.Context, rule *repository.AlertRule, orgID uuid.UUID) ([]AlertMatch, error) {
+	scores, err := e.accountScores.ListByOrg(ctx, orgID, 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []AlertMatch
+	for _, score := range scores {
+		account, err := e.accounts.GetByIDAndOrg(ctx, score.AccountID, orgID)
+		if err != nil || account == nil {
+			continue
+		}
+		if match := e.checkAccountScoreBelow(ctx, rule, account, score); match != nil {
+			matches = append(matches, *match)
+		}
+	}
+	return matches, nil
+}
+
+func (e *AlertEngine) evaluateAccountScoreBelowForAccount(ctx context.Context, rule *repository.AlertRule, account *repository.Account) (*AlertMatch, error) {
+	score, err := e.accountScores.GetByAccountID(ctx, account.ID, account.OrgID)
+	if err != nil || score == nil {
+		return nil, err
+	}
+	return e.checkAccountScoreBelow(ctx, rule, account, score), nil
+}
+
+func (e *AlertEngine) checkAccountScoreBelow(ctx context.Context, rule *repository.AlertRule, account *repository.Account, score *repository.AccountHealthScore) *AlertMatch {
+	threshold := getConditionInt(rule.Conditions, "threshold", 40)
+	if score.OverallScore >= threshold {
+		return nil
+	}
+
+	if e.isAccountInCooldown(ctx, rule.ID, account.ID) {
+		return nil
+	}
+
+	return &AlertMatch{
+		Rule:    rule,
+		Account: account,
+		TriggerData: map[string]any{
+			"account_id":    account.ID.String(),
+			"score":         score.OverallScore,
+			"threshold":     threshold,
+			"risk_level":    score.RiskLevel,
+			"aggregation":   score.Aggregation,
+			"contact_count": score.ContactCount,
+		},
+	}
+}
+
+// evaluateAccountRiskChange checks for accounts whose rolled-up risk level changed recently.
+func (e *AlertEngine) evaluateAccountRiskChange(ctx context.Context, rule *repository.AlertRule, orgID uuid.UUID) ([]AlertMatch, error) {
+	scores, err := e.accountScores.ListByOrg(ctx, orgID, 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []AlertMatch
+	for _, score := range scores {
+		account, err := e.accounts.GetByIDAndOrg(ctx, score.AccountID, orgID)
+		if err != nil || account == nil {
+			continue
+		}
+		if match := e.checkAccountRiskChange(ctx, rule, account, score); match != nil {
+			matches = append(matches, *match)
+		}
+	}
+	return matches, nil
+}
+
+func (e *AlertEngine) evaluateAccountRiskChangeForAccount(ctx context.Context, rule *repository.AlertRule, account *repository.Account) (*AlertMatch, error) {
+	score, err := e.accountScores.GetByAccountID(ctx, account.ID, account.OrgID)
+	if err != nil || score == nil {
+		return nil, err
+	}
+	return e.checkAccountRiskChange(ctx, rule, account, score), nil
+}
+
+func (e *AlertEngine) checkAccountRiskChange(ctx context.Context, rule *repository.AlertRule, account *repository.Account, score *repository.AccountHealthScore) *AlertMatch {
+	since := time.Now().Add(-e.getCooldown(rule.Conditions))
+	if score.RiskChangedAt == nil || score.RiskChangedAt.Before(since) {
+		return nil
+	}
+
+	condFrom, _ := rule.Conditions["from"].(string)
+	condTo, _ := rule.Conditions["to"].(string)
+
+	if condFrom != "" && score.PreviousRiskLevel != condFrom {
+		return nil
+	}
+	if condTo != "" && score.RiskLevel != condTo {
+		return nil
+	}
+
+	if e.isAccountInCooldown(ctx, rule.ID, account.ID) {
+		return nil
+	}
+
+	return &AlertMatch{
+		Rule:    rule,
+		Account: account,
+		TriggerData: map[string]any{
+			"account_id":     account.ID.String(),
+			"previous_level": score.PreviousRiskLevel,
+			"new_level":      score.RiskLevel,
+			"score":          score.OverallScore,
+		},
+	}
+}
+
+// isAccountInCooldown checks if an alert was recently sent for this rule+account combo.
+func (e *AlertEngine) isAccountInCooldown(ctx context.Context, ruleID, accountID uuid.UUID) bool {
+	last, err := e.alertHistory.GetLastAlertForRuleAndAccount(ctx, ruleID, accountID)
+	if err != nil || last == nil {
+		return false
+	}
+
+	cooldownEnd := last.CreatedAt.Add(e.defaultCooldown)
+	return time.Now().Before(cooldownEnd)
+}
+
 // isInCooldown checks if an alert was recently sent for this rule+customer combo.
 func (e *AlertEngine) isInCooldown(ctx context.Context, ruleID, customerID uuid.UUID) bool {
 	last, err := e.alertHistory.GetLastAlertForRule(ctx, ruleID, customerID)
@@ -421,6 +848,19 @@ func (e *AlertEngine) getCooldown(conditions map[string]any) time.Duration {
 	return e.defaultCooldown
 }
 
+// lowestFactor returns the name of the weakest scoring factor, i.e. the biggest negative contributor.
+func lowestFactor(factors map[string]float64) string {
+	lowest := ""
+	minVal := 100.0
+	for k, v := range factors {
+		if v < minVal {
+			minVal = v
+			lowest = k
+		}
+	}
+	return lowest
+}
+
 func getConditionInt(conditions map[string]any, key string, defaultVal int) int {
 	v, ok := conditions[key]
 	if !ok {
@@ -438,3 +878,8 @@ func getConditionInt(conditions map[string]any, key string, defaultVal int) int
 	}
 	return defaultVal
 }
+
+func getConditionString(conditions map[string]any, key string) string {
+	s, _ := conditions[key].(string)
+	return s
+}
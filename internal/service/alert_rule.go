@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/mail"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -15,46 +18,96 @@ import (
 // AlertRuleService handles alert rule business logic.
 type AlertRuleService struct {
 	alertRepo *repository.AlertRuleRepository
+	engine    *AlertEngine
+	orgRepo   *repository.OrganizationRepository
+	segments  *repository.SegmentRepository
+	fields    *repository.CustomFieldRepository
 }
 
 // NewAlertRuleService creates a new AlertRuleService.
-func NewAlertRuleService(alertRepo *repository.AlertRuleRepository) *AlertRuleService {
-	return &AlertRuleService{alertRepo: alertRepo}
+func NewAlertRuleService(alertRepo *repository.AlertRuleRepository, engine *AlertEngine, orgRepo *repository.OrganizationRepository, segments *repository.SegmentRepository, fields *repository.CustomFieldRepository) *AlertRuleService {
+	return &AlertRuleService{alertRepo: alertRepo, engine: engine, orgRepo: orgRepo, segments: segments, fields: fields}
 }
 
 // CreateAlertRuleRequest holds input for creating an alert rule.
 type CreateAlertRuleRequest struct {
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	TriggerType string         `json:"trigger_type"`
-	Conditions  map[string]any `json:"conditions"`
-	Channel     string         `json:"channel"`
-	Recipients  []string       `json:"recipients"`
-	IsActive    *bool          `json:"is_active"`
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	TriggerType string                      `json:"trigger_type"`
+	Conditions  map[string]any              `json:"conditions"`
+	Channel     string                      `json:"channel"`
+	Recipients  []string                    `json:"recipients"`
+	Severity    string                      `json:"severity"`
+	IsActive    *bool                       `json:"is_active"`
+	Task        *repository.AlertTaskConfig `json:"task"`
+	SegmentID   *uuid.UUID                  `json:"segment_id"`
 }
 
 // UpdateAlertRuleRequest holds input for updating an alert rule.
 type UpdateAlertRuleRequest struct {
-	Name        *string         `json:"name"`
-	Description *string         `json:"description"`
-	TriggerType *string         `json:"trigger_type"`
-	Conditions  *map[string]any `json:"conditions"`
-	Channel     *string         `json:"channel"`
-	Recipients  *[]string       `json:"recipients"`
-	IsActive    *bool           `json:"is_active"`
+	Name         *string                     `json:"name"`
+	Description  *string                     `json:"description"`
+	TriggerType  *string                     `json:"trigger_type"`
+	Conditions   *map[string]any             `json:"conditions"`
+	Channel      *string                     `json:"channel"`
+	Recipients   *[]string                   `json:"recipients"`
+	Severity     *string                     `json:"severity"`
+	IsActive     *bool                       `json:"is_active"`
+	Task         *repository.AlertTaskConfig `json:"task"`
+	SegmentID    *uuid.UUID                  `json:"segment_id"`
+	ClearSegment bool                        `json:"clear_segment"`
 }
 
+// BacktestAlertRuleRequest holds a draft rule and the historical range to replay it over.
+type BacktestAlertRuleRequest struct {
+	TriggerType string         `json:"trigger_type"`
+	Conditions  map[string]any `json:"conditions"`
+	From        *time.Time     `json:"from"`
+	To          *time.Time     `json:"to"`
+}
+
+const (
+	defaultBacktestDays = 30
+	maxBacktestDays     = 90
+	maxAlertTaskDueDays = 365
+)
+
+// AlertRecipientCustomerOwner is a recipient that stands for the owners of
+// the alerted customer, or of an alerted account's customers. It is resolved
+// to their email addresses when the alert is sent.
+const AlertRecipientCustomerOwner = "customer_owner"
+
 var validTriggerTypes = map[string]bool{
 	"score_below":    true,
 	"score_drop":     true,
 	"risk_change":    true,
 	"payment_failed": true,
+	"billing_event":  true,
+	"custom_field":   true,
+
+	"renewal_upcoming": true,
+
+	"account_score_below": true,
+	"account_risk_change": true,
+}
+
+// accountTriggerTypes are the trigger types evaluated per account rather
+// than per customer.
+var accountTriggerTypes = map[string]bool{
+	"account_score_below": true,
+	"account_risk_change": true,
 }
 
 var validChannels = map[string]bool{
 	"email": true,
 }
 
+var validSeverities = map[string]bool{
+	"info":     true,
+	"warning":  true,
+	"critical": true,
+}
+
 // List returns all alert rules for an org.
 func (s *AlertRuleService) List(ctx context.Context, orgID uuid.UUID) ([]*repository.AlertRule, error) {
 	rules, err := s.alertRepo.List(ctx, orgID)
@@ -81,12 +134,27 @@ func (s *AlertRuleService) Create(ctx context.Context, orgID, userID uuid.UUID,
 	if err := s.validateCreate(req); err != nil {
 		return nil, err
 	}
+	task, err := s.validateTask(ctx, orgID, req.Task)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateSegment(ctx, orgID, req.TriggerType, req.SegmentID); err != nil {
+		return nil, err
+	}
+	if err := s.validateCustomField(ctx, orgID, req.TriggerType, req.Conditions); err != nil {
+		return nil, err
+	}
 
 	isActive := true
 	if req.IsActive != nil {
 		isActive = *req.IsActive
 	}
 
+	severity := req.Severity
+	if severity == "" {
+		severity = "warning"
+	}
+
 	rule := &repository.AlertRule{
 		OrgID:       orgID,
 		Name:        strings.TrimSpace(req.Name),
@@ -95,7 +163,10 @@ func (s *AlertRuleService) Create(ctx context.Context, orgID, userID uuid.UUID,
 		Conditions:  req.Conditions,
 		Channel:     req.Channel,
 		Recipients:  req.Recipients,
+		Severity:    severity,
 		IsActive:    isActive,
+		Task:        task,
+		SegmentID:   req.SegmentID,
 		CreatedBy:   &userID,
 	}
 
@@ -150,9 +221,31 @@ func (s *AlertRuleService) Update(ctx context.Context, id, orgID uuid.UUID, req
 		}
 		rule.Recipients = *req.Recipients
 	}
+	if req.Severity != nil {
+		if !validSeverities[*req.Severity] {
+			return nil, &ValidationError{Field: "severity", Message: "severity must be info, warning, or critical"}
+		}
+		rule.Severity = *req.Severity
+	}
 	if req.IsActive != nil {
 		rule.IsActive = *req.IsActive
 	}
+	if req.Task != nil {
+		if rule.Task, err = s.validateTask(ctx, orgID, req.Task); err != nil {
+			return nil, err
+		}
+	}
+	if req.ClearSegment {
+		rule.SegmentID = nil
+	} else if req.SegmentID != nil {
+		rule.SegmentID = req.SegmentID
+	}
+	if err := s.validateSegment(ctx, orgID, rule.TriggerType, rule.SegmentID); err != nil {
+		return nil, err
+	}
+	if err := s.validateCustomField(ctx, orgID, rule.TriggerType, rule.Conditions); err != nil {
+		return nil, err
+	}
 
 	if err := s.alertRepo.Update(ctx, rule); err != nil {
 		if err == pgx.ErrNoRows {
@@ -175,12 +268,61 @@ func (s *AlertRuleService) Delete(ctx context.Context, id, orgID uuid.UUID) erro
 	return nil
 }
 
+// Backtest replays a draft rule against historical data without sending anything.
+func (s *AlertRuleService) Backtest(ctx context.Context, orgID uuid.UUID, req BacktestAlertRuleRequest) (*BacktestResult, error) {
+	if !validTriggerTypes[req.TriggerType] {
+		return nil, &ValidationError{Field: "trigger_type", Message: "invalid trigger type"}
+	}
+	if accountTriggerTypes[req.TriggerType] {
+		return nil, &ValidationError{Field: "trigger_type", Message: "account trigger types cannot be backtested"}
+	}
+	if req.TriggerType == "custom_field" {
+		return nil, &ValidationError{Field: "trigger_type", Message: "custom_field triggers cannot be backtested; past field values are not kept"}
+	}
+	if req.TriggerType == "renewal_upcoming" {
+		return nil, &ValidationError{Field: "trigger_type", Message: "renewal_upcoming triggers cannot be backtested; past renewal dates are not kept"}
+	}
+	if req.Conditions == nil {
+		req.Conditions = map[string]any{}
+	}
+	if err := s.validateConditions(req.TriggerType, req.Conditions); err != nil {
+		return nil, err
+	}
+
+	to := time.Now()
+	if req.To != nil {
+		to = *req.To
+	}
+	from := to.AddDate(0, 0, -defaultBacktestDays)
+	if req.From != nil {
+		from = *req.From
+	}
+	if !from.Before(to) {
+		return nil, &ValidationError{Field: "from", Message: "from must be before to"}
+	}
+	if to.Sub(from) > maxBacktestDays*24*time.Hour {
+		return nil, &ValidationError{Field: "from", Message: fmt.Sprintf("backtest range cannot exceed %d days", maxBacktestDays)}
+	}
+
+	rule := &repository.AlertRule{
+		OrgID:       orgID,
+		TriggerType: req.TriggerType,
+		Conditions:  req.Conditions,
+	}
+
+	result, err := s.engine.Backtest(ctx, rule, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("backtest alert rule: %w", err)
+	}
+	return result, nil
+}
+
 func (s *AlertRuleService) validateCreate(req CreateAlertRuleRequest) error {
 	if strings.TrimSpace(req.Name) == "" {
 		return &ValidationError{Field: "name", Message: "name is required"}
 	}
 	if !validTriggerTypes[req.TriggerType] {
-		return &ValidationError{Field: "trigger_type", Message: "invalid trigger type; must be score_drop, risk_change, or payment_failed"}
+		return &ValidationError{Field: "trigger_type", Message: "invalid trigger type; must be score_below, score_drop, risk_change, payment_failed, billing_event, custom_field, renewal_upcoming, account_score_below, or account_risk_change"}
 	}
 	if req.Conditions == nil {
 		return &ValidationError{Field: "conditions", Message: "conditions are required"}
@@ -195,6 +337,9 @@ func (s *AlertRuleService) validateCreate(req CreateAlertRuleRequest) error {
 	if !validChannels[channel] {
 		return &ValidationError{Field: "channel", Message: "invalid channel"}
 	}
+	if req.Severity != "" && !validSeverities[req.Severity] {
+		return &ValidationError{Field: "severity", Message: "severity must be info, warning, or critical"}
+	}
 	if len(req.Recipients) == 0 {
 		return &ValidationError{Field: "recipients", Message: "at least one recipient is required"}
 	}
@@ -207,21 +352,126 @@ func (s *AlertRuleService) validateConditions(triggerType string, conditions map
 		if _, ok := conditions["threshold"]; !ok {
 			return &ValidationError{Field: "conditions.threshold", Message: "threshold is required for score_drop"}
 		}
-	case "risk_change":
+	case "risk_change", "account_risk_change":
 		if _, ok := conditions["from"]; !ok {
-			return &ValidationError{Field: "conditions.from", Message: "from is required for risk_change"}
+			return &ValidationError{Field: "conditions.from", Message: "from is required for " + triggerType}
 		}
 		if _, ok := conditions["to"]; !ok {
-			return &ValidationError{Field: "conditions.to", Message: "to is required for risk_change"}
+			return &ValidationError{Field: "conditions.to", Message: "to is required for " + triggerType}
 		}
 	case "payment_failed":
 		// No required conditions for payment_failed
+	case "billing_event":
+		eventType, _ := conditions["event_type"].(string)
+		if !IsBillingEventType(eventType) {
+			return &ValidationError{Field: "conditions.event_type", Message: "event_type must be one of: " + strings.Join(BillingEventTypes, ", ")}
+		}
+	case "custom_field":
+		if field, _ := conditions["field"].(string); field == "" {
+			return &ValidationError{Field: "conditions.field", Message: "field is required for custom_field"}
+		}
+		if op, _ := conditions["op"].(string); op == "" {
+			return &ValidationError{Field: "conditions.op", Message: "op is required for custom_field"}
+		}
+	case "renewal_upcoming":
+		if v, ok := conditions["days"]; ok {
+			if days, isNum := v.(float64); !isNum || days != math.Trunc(days) || days < 1 || days > maxRenewalPipelineDays {
+				return &ValidationError{Field: "conditions.days", Message: fmt.Sprintf("days must be a whole number between 1 and %d", maxRenewalPipelineDays)}
+			}
+		}
+		if v, ok := conditions["max_score"]; ok {
+			if score, isNum := v.(float64); !isNum || score < 0 || score > 100 {
+				return &ValidationError{Field: "conditions.max_score", Message: "max_score must be a number between 0 and 100"}
+			}
+		}
+	}
+	return nil
+}
+
+// validateCustomField checks a custom_field rule's condition against the
+// field's type, storing its value typed so the rule compares it as one.
+func (s *AlertRuleService) validateCustomField(ctx context.Context, orgID uuid.UUID, triggerType string, conditions map[string]any) error {
+	if triggerType != "custom_field" {
+		return nil
+	}
+
+	key, _ := conditions["field"].(string)
+	field, err := s.fields.GetByKey(ctx, orgID, key)
+	if err != nil {
+		return err
+	}
+	if field == nil {
+		return &ValidationError{Field: "conditions.field", Message: "custom field not found"}
+	}
+
+	op, _ := conditions["op"].(string)
+	filter, err := customFieldFilter(field, op, conditions["value"])
+	if err != nil {
+		return &ValidationError{Field: "conditions", Message: err.Error()}
+	}
+	if filter.Value == nil {
+		delete(conditions, "value")
+	} else {
+		conditions["value"] = filter.Value
 	}
 	return nil
 }
 
+// validateSegment checks that a rule's segment belongs to the org. Segments
+// select customers, so account triggers cannot be limited to one.
+func (s *AlertRuleService) validateSegment(ctx context.Context, orgID uuid.UUID, triggerType string, segmentID *uuid.UUID) error {
+	if segmentID == nil {
+		return nil
+	}
+	if accountTriggerTypes[triggerType] {
+		return &ValidationError{Field: "segment_id", Message: "account triggers cannot be limited to a segment"}
+	}
+
+	segment, err := s.segments.GetByID(ctx, *segmentID, orgID)
+	if err != nil {
+		return err
+	}
+	if segment == nil {
+		return &ValidationError{Field: "segment_id", Message: "segment not found"}
+	}
+	return nil
+}
+
+// validateTask checks a rule's task config and fills in its defaults. A
+// disabled config is stored as none.
+func (s *AlertRuleService) validateTask(ctx context.Context, orgID uuid.UUID, cfg *repository.AlertTaskConfig) (*repository.AlertTaskConfig, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	task := *cfg
+	task.Title = strings.TrimSpace(task.Title)
+	if utf8.RuneCountInString(task.Title) > maxTaskTitleLength {
+		return nil, &ValidationError{Field: "task.title", Message: fmt.Sprintf("title must be at most %d characters", maxTaskTitleLength)}
+	}
+	if task.DueInDays < 0 || task.DueInDays > maxAlertTaskDueDays {
+		return nil, &ValidationError{Field: "task.due_in_days", Message: fmt.Sprintf("due_in_days must be between 0 and %d", maxAlertTaskDueDays)}
+	}
+	if task.DueInDays == 0 {
+		task.DueInDays = defaultAlertTaskDueDays
+	}
+	if task.AssigneeID != nil && s.orgRepo != nil {
+		ok, err := s.orgRepo.IsMember(ctx, *task.AssigneeID, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("check membership: %w", err)
+		}
+		if !ok {
+			return nil, &ValidationError{Field: "task.assignee_id", Message: "assignee is not a member of this organization"}
+		}
+	}
+	return &task, nil
+}
+
 func (s *AlertRuleService) validateRecipients(recipients []string) error {
 	for _, r := range recipients {
+		if r == AlertRecipientCustomerOwner {
+			continue
+		}
 		if _, err := mail.ParseAddress(r); err != nil {
 			return &ValidationError{Field: "recipients", Message: fmt.Sprintf("invalid email: %s", r)}
 		}
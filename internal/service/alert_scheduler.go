@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +22,10 @@ type AlertScheduler struct {
 	userRepo     *repository.UserRepository
 	notifPrefSvc *NotificationPreferenceService
 	notifService *NotificationService
+	taskService  *TaskService
+	queue        *repository.QueuedAlertEmailRepository
+	orgTemplates *AlertTemplateService
+	owners       *repository.CustomerOwnerRepository
 	interval     time.Duration
 	frontendURL  string
 }
@@ -34,8 +39,19 @@ type AlertSchedulerDeps struct {
 	AlertRules   *repository.AlertRuleRepository
 	UserRepo     *repository.UserRepository
 	NotifPrefSvc *NotificationPreferenceService
+	Queue        *repository.QueuedAlertEmailRepository
+	OrgTemplates *AlertTemplateService
+	Owners       *repository.CustomerOwnerRepository
 }
 
+const (
+	// queuedDeliveryBatch caps how many held-back alert emails are sent per run.
+	queuedDeliveryBatch = 200
+	// queuedDeliveryStaleAfter is how long an email may stay in sending before
+	// it is claimed again; it covers a worker crashing mid-batch.
+	queuedDeliveryStaleAfter = 10 * time.Minute
+)
+
 // NewAlertScheduler creates a new AlertScheduler.
 func NewAlertScheduler(
 	deps AlertSchedulerDeps,
@@ -50,6 +66,9 @@ func NewAlertScheduler(
 		alertRules:   deps.AlertRules,
 		userRepo:     deps.UserRepo,
 		notifPrefSvc: deps.NotifPrefSvc,
+		queue:        deps.Queue,
+		orgTemplates: deps.OrgTemplates,
+		owners:       deps.Owners,
 		interval:     time.Duration(intervalMinutes) * time.Minute,
 		frontendURL:  frontendURL,
 	}
@@ -60,6 +79,11 @@ func (s *AlertScheduler) SetNotificationService(notifSvc *NotificationService) {
 	s.notifService = notifSvc
 }
 
+// SetTaskService sets the task service for creating the follow-up tasks configured on rules.
+func (s *AlertScheduler) SetTaskService(taskSvc *TaskService) {
+	s.taskService = taskSvc
+}
+
 // Start begins the periodic alert evaluation loop. Cancel the context to stop.
 func (s *AlertScheduler) Start(ctx context.Context) {
 	slog.Info("alert scheduler started", "interval", s.interval)
@@ -80,6 +104,8 @@ func (s *AlertScheduler) Start(ctx context.Context) {
 
 // RunOnce performs a single evaluation pass across all orgs with active rules.
 func (s *AlertScheduler) RunOnce(ctx context.Context) {
+	s.deliverQueued(ctx, time.Now())
+
 	orgIDs, err := s.alertHistory.ListOrgsWithActiveRules(ctx)
 	if err != nil {
 		slog.Error("alert scheduler: list orgs", "error", err)
@@ -114,23 +140,35 @@ func (s *AlertScheduler) evaluateOrg(ctx context.Context, orgID uuid.UUID) {
 }
 
 func (s *AlertScheduler) ProcessMatch(ctx context.Context, match AlertMatch) {
+	match = s.resolveRecipients(ctx, match)
+
 	// Create pending history record
-	customerID := &match.Customer.ID
 	history := &repository.AlertHistory{
 		OrgID:       match.Rule.OrgID,
 		AlertRuleID: match.Rule.ID,
-		CustomerID:  customerID,
 		TriggerData: match.TriggerData,
 		Channel:     match.Rule.Channel,
 		Status:      "pending",
 	}
+	if match.Customer != nil {
+		history.CustomerID = &match.Customer.ID
+	}
+	if match.Account != nil {
+		history.AccountID = &match.Account.ID
+	}
 	if err := s.alertHistory.Create(ctx, history); err != nil {
 		slog.Error("alert scheduler: create history", "error", err)
 		return
 	}
 
+	if s.taskService != nil {
+		if _, err := s.taskService.CreateForAlert(ctx, match, history.ID); err != nil {
+			slog.Error("alert scheduler: create task", "rule_id", match.Rule.ID, "error", err)
+		}
+	}
+
 	// Render email
-	subject, htmlBody, textBody, err := s.renderEmail(match)
+	subject, htmlBody, textBody, err := s.renderEmail(ctx, match)
 	if err != nil {
 		slog.Error("alert scheduler: render email",
 			"rule_id", match.Rule.ID,
@@ -141,14 +179,44 @@ func (s *AlertScheduler) ProcessMatch(ctx context.Context, match AlertMatch) {
 		return
 	}
 
-	// Send to each recipient (respecting notification preferences)
+	// Send to each recipient (respecting notification preferences and delivery windows)
+	now := time.Now()
+	var sent, queued int
 	for _, recipient := range match.Rule.Recipients {
+		deliverAt := now
+		var userID *uuid.UUID
 		if s.userRepo != nil && s.notifPrefSvc != nil {
 			user, err := s.userRepo.GetByEmail(ctx, recipient)
-			if err == nil && !s.notifPrefSvc.ShouldNotifyEmail(ctx, user.ID, match.Rule.OrgID, match.Rule.ID, time.Now()) {
-				slog.Debug("alert scheduler: skipping muted/disabled recipient", "recipient", recipient, "rule_id", match.Rule.ID)
-				continue
+			if err == nil && user != nil {
+				at, ok := s.notifPrefSvc.EmailDeliveryTime(ctx, user.ID, match.Rule.OrgID, match.Rule.ID, match.Rule.Severity, now)
+				if !ok {
+					slog.Debug("alert scheduler: skipping muted/disabled recipient", "recipient", recipient, "rule_id", match.Rule.ID)
+					continue
+				}
+				deliverAt = at
+				userID = &user.ID
+			}
+		}
+
+		if deliverAt.After(now) && s.queue != nil {
+			err := s.queue.Create(ctx, &repository.QueuedAlertEmail{
+				OrgID:          match.Rule.OrgID,
+				AlertHistoryID: history.ID,
+				UserID:         userID,
+				Recipient:      recipient,
+				Subject:        subject,
+				HTMLBody:       htmlBody,
+				TextBody:       textBody,
+				DeliverAfter:   deliverAt,
+			})
+			if err != nil {
+				slog.Error("alert scheduler: queue email", "recipient", recipient, "rule_id", match.Rule.ID, "error", err)
+				_ = s.alertHistory.UpdateStatus(ctx, history.ID, "failed", err.Error())
+				return
 			}
+			queued++
+			slog.Debug("alert scheduler: email held for delivery window", "recipient", recipient, "deliver_after", deliverAt)
+			continue
 		}
 
 		msgID, err := s.emailService.SendEmail(ctx, SendEmailParams{
@@ -166,13 +234,17 @@ func (s *AlertScheduler) ProcessMatch(ctx context.Context, match AlertMatch) {
 			_ = s.alertHistory.UpdateStatus(ctx, history.ID, "failed", err.Error())
 			return
 		}
+		sent++
 
 		if msgID != "" {
 			_ = s.alertHistory.UpdateSendGridMessageID(ctx, history.ID, msgID)
 		}
 	}
 
-	_ = s.alertHistory.UpdateStatus(ctx, history.ID, "sent", "")
+	// History stays pending while every email is waiting on a delivery window.
+	if sent > 0 || queued == 0 {
+		_ = s.alertHistory.UpdateStatus(ctx, history.ID, "sent", "")
+	}
 
 	// Create in-app notifications for recipients
 	if s.notifService != nil {
@@ -180,24 +252,111 @@ func (s *AlertScheduler) ProcessMatch(ctx context.Context, match AlertMatch) {
 	}
 }
 
-func (s *AlertScheduler) renderEmail(match AlertMatch) (subject, html, text string, err error) {
-	customerURL := fmt.Sprintf("%s/customers/%s", s.frontendURL, match.Customer.ID)
+// resolveRecipients replaces the customer_owner recipient of match's rule
+// with the current owners' email addresses. The rule itself is not modified.
+func (s *AlertScheduler) resolveRecipients(ctx context.Context, match AlertMatch) AlertMatch {
+	if !slices.Contains(match.Rule.Recipients, AlertRecipientCustomerOwner) {
+		return match
+	}
+
+	var owners []string
+	if s.owners != nil {
+		var err error
+		switch {
+		case match.Account != nil:
+			owners, err = s.owners.ListEmailsForAccount(ctx, match.Account.ID)
+		case match.Customer != nil:
+			owners, err = s.owners.ListEmailsForCustomer(ctx, match.Customer.ID)
+		}
+		if err != nil {
+			slog.Error("alert scheduler: resolve customer owners", "rule_id", match.Rule.ID, "error", err)
+		}
+	}
+
+	var recipients []string
+	for _, r := range match.Rule.Recipients {
+		expanded := []string{r}
+		if r == AlertRecipientCustomerOwner {
+			expanded = owners
+		}
+		for _, email := range expanded {
+			if !slices.Contains(recipients, email) {
+				recipients = append(recipients, email)
+			}
+		}
+	}
+
+	rule := *match.Rule
+	rule.Recipients = recipients
+	match.Rule = &rule
+	return match
+}
+
+// deliverQueued sends alert emails whose delivery window has opened.
+func (s *AlertScheduler) deliverQueued(ctx context.Context, now time.Time) {
+	if s.queue == nil {
+		return
+	}
+
+	due, err := s.queue.ClaimDue(ctx, now, queuedDeliveryStaleAfter, queuedDeliveryBatch)
+	if err != nil {
+		slog.Error("alert scheduler: claim queued emails", "error", err)
+		return
+	}
+
+	for _, q := range due {
+		msgID, err := s.emailService.SendEmail(ctx, SendEmailParams{
+			To:       q.Recipient,
+			Subject:  q.Subject,
+			HTMLBody: q.HTMLBody,
+			TextBody: q.TextBody,
+		})
+		if err != nil {
+			slog.Error("alert scheduler: send queued email", "recipient", q.Recipient, "error", err)
+			_ = s.queue.MarkFailed(ctx, q.ID, err.Error())
+			_ = s.alertHistory.UpdateStatus(ctx, q.AlertHistoryID, "failed", err.Error())
+			continue
+		}
+
+		_ = s.queue.MarkSent(ctx, q.ID)
+		if msgID != "" {
+			_ = s.alertHistory.UpdateSendGridMessageID(ctx, q.AlertHistoryID, msgID)
+		}
+		_ = s.alertHistory.UpdateStatus(ctx, q.AlertHistoryID, "sent", "")
+	}
+
+	if len(due) > 0 {
+		slog.Info("alert scheduler: delivered queued emails", "count", len(due))
+	}
+}
+
+func (s *AlertScheduler) renderEmail(ctx context.Context, match AlertMatch) (subject, html, text string, err error) {
+	// Org-defined templates take precedence; fall back to the built-in ones if they fail.
+	if s.orgTemplates != nil {
+		rendered, err := s.orgTemplates.RenderForMatch(ctx, match)
+		if err != nil {
+			slog.Error("alert scheduler: render org template", "rule_id", match.Rule.ID, "error", err)
+		} else if rendered != nil {
+			return rendered.Subject, rendered.HTMLBody, rendered.TextBody, nil
+		}
+	}
+
+	customerURL := s.frontendURL + match.subjectPath()
 	unsubURL := fmt.Sprintf("%s/settings?tab=notifications", s.frontendURL)
 
 	switch match.Rule.TriggerType {
 	case "score_below":
-		score, _ := match.TriggerData["score"].(int)
-		threshold, _ := match.TriggerData["threshold"].(int)
+		score := extractInt(match.TriggerData, "score")
+		threshold := extractInt(match.TriggerData, "threshold")
 		riskLevel, _ := match.TriggerData["risk_level"].(string)
 
 		subject = fmt.Sprintf("Alert: %s health score below %d", match.Customer.Name, threshold)
-		html, text, err = s.templates.RenderScoreDrop(ScoreDropEmailData{
+		html, text, err = s.templates.RenderScoreBelow(ScoreBelowEmailData{
 			CustomerName:      match.Customer.Name,
 			CompanyName:       match.Customer.CompanyName,
-			OldScore:          threshold,
-			NewScore:          score,
-			Delta:             threshold - score,
-			TopNegativeFactor: riskLevel,
+			Score:             score,
+			Threshold:         threshold,
+			RiskLevel:         riskLevel,
 			CustomerDetailURL: customerURL,
 			UnsubscribeURL:    unsubURL,
 		})
@@ -237,8 +396,7 @@ func (s *AlertScheduler) renderEmail(match AlertMatch) (subject, html, text stri
 		})
 
 	case "payment_failed":
-		amount, _ := match.TriggerData["amount"].(string)
-		reason, _ := match.TriggerData["reason"].(string)
+		amount, reason := paymentFailedDetails(match.TriggerData)
 		if amount == "" {
 			amount = "N/A"
 		}
@@ -256,6 +414,90 @@ func (s *AlertScheduler) renderEmail(match AlertMatch) (subject, html, text stri
 			UnsubscribeURL:    unsubURL,
 		})
 
+	case "billing_event":
+		eventType, _ := match.TriggerData["event_type"].(string)
+		label := BillingEventLabel(eventType)
+		amount, detail := billingEventDetails(match.TriggerData)
+
+		subject = fmt.Sprintf("Alert: %s for %s", label, match.Customer.Name)
+		html, text, err = s.templates.RenderBillingEvent(BillingEventEmailData{
+			CustomerName:      match.Customer.Name,
+			CompanyName:       match.Customer.CompanyName,
+			EventLabel:        label,
+			Amount:            amount,
+			Detail:            detail,
+			CustomerDetailURL: customerURL,
+			UnsubscribeURL:    unsubURL,
+		})
+
+	case "custom_field":
+		label, _ := match.TriggerData["label"].(string)
+		op, _ := match.TriggerData["op"].(string)
+		value, _ := match.TriggerData["field_value"].(string)
+		condition := customFieldConditionText(op, match.TriggerData["value"])
+
+		subject = fmt.Sprintf("Alert: %s %s for %s", label, condition, match.Customer.Name)
+		html, text, err = s.templates.RenderCustomField(CustomFieldEmailData{
+			CustomerName:      match.Customer.Name,
+			CompanyName:       match.Customer.CompanyName,
+			FieldLabel:        label,
+			Condition:         condition,
+			Value:             value,
+			CustomerDetailURL: customerURL,
+			UnsubscribeURL:    unsubURL,
+		})
+
+	case "renewal_upcoming":
+		date, _ := match.TriggerData["renewal_date"].(string)
+		_, hasScore := match.TriggerData["score"]
+		riskLevel, _ := match.TriggerData["risk_level"].(string)
+
+		subject = fmt.Sprintf("Alert: %s renews on %s", match.Customer.Name, date)
+		html, text, err = s.templates.RenderRenewal(RenewalEmailData{
+			CustomerName:      match.Customer.Name,
+			CompanyName:       match.Customer.CompanyName,
+			RenewalDate:       date,
+			DaysUntil:         extractInt(match.TriggerData, "days_until"),
+			Amount:            formatCents(extractInt(match.TriggerData, "amount_cents")),
+			HasScore:          hasScore,
+			Score:             extractInt(match.TriggerData, "score"),
+			RiskLevel:         riskLevel,
+			CustomerDetailURL: customerURL,
+			UnsubscribeURL:    unsubURL,
+		})
+
+	case "account_score_below":
+		score := extractInt(match.TriggerData, "score")
+		threshold := extractInt(match.TriggerData, "threshold")
+		riskLevel, _ := match.TriggerData["risk_level"].(string)
+
+		subject = fmt.Sprintf("Alert: account %s health score below %d", match.Account.Name, threshold)
+		html, text, err = s.templates.RenderScoreBelow(ScoreBelowEmailData{
+			CustomerName:      match.Account.Name,
+			CompanyName:       match.Account.Domain,
+			Score:             score,
+			Threshold:         threshold,
+			RiskLevel:         riskLevel,
+			CustomerDetailURL: customerURL,
+			UnsubscribeURL:    unsubURL,
+		})
+
+	case "account_risk_change":
+		prevLevel, _ := match.TriggerData["previous_level"].(string)
+		newLevel, _ := match.TriggerData["new_level"].(string)
+		score := extractInt(match.TriggerData, "score")
+
+		subject = fmt.Sprintf("Alert: account %s risk level changed to %s", match.Account.Name, newLevel)
+		html, text, err = s.templates.RenderRiskChange(RiskChangeEmailData{
+			CustomerName:      match.Account.Name,
+			CompanyName:       match.Account.Domain,
+			PreviousLevel:     prevLevel,
+			NewLevel:          newLevel,
+			Score:             score,
+			CustomerDetailURL: customerURL,
+			UnsubscribeURL:    unsubURL,
+		})
+
 	default:
 		err = fmt.Errorf("unsupported trigger type: %s", match.Rule.TriggerType)
 	}
@@ -263,6 +505,57 @@ func (s *AlertScheduler) renderEmail(match AlertMatch) (subject, html, text stri
 	return subject, html, text, err
 }
 
+// paymentFailedDetails formats the amount and failure reason of a
+// payment.failed event from its trigger data. Failures reported by invoice
+// webhooks carry no reason.
+func paymentFailedDetails(data map[string]any) (amount, reason string) {
+	if _, ok := data["amount_cents"]; ok {
+		currency, _ := data["currency"].(string)
+		amount = formatAmount(extractInt(data, "amount_cents"), currency)
+	}
+	reason, _ = data["failure_message"].(string)
+	if reason == "" {
+		reason, _ = data["failure_code"].(string)
+	}
+	return amount, reason
+}
+
+// billingEventDetails formats the amount and a one-line description of a
+// billing event from its trigger data.
+func billingEventDetails(data map[string]any) (amount, detail string) {
+	if _, ok := data["amount_cents"]; ok {
+		currency, _ := data["currency"].(string)
+		amount = formatAmount(extractInt(data, "amount_cents"), currency)
+	}
+
+	eventType, _ := data["event_type"].(string)
+	switch eventType {
+	case "plan.upgraded", "plan.downgraded":
+		prevPlan, _ := data["previous_plan"].(string)
+		plan, _ := data["plan"].(string)
+		detail = fmt.Sprintf("%s (%s) → %s (%s)",
+			prevPlan, formatCents(extractInt(data, "previous_amount")), plan, amount)
+	case "dispute.opened", "dispute.won", "dispute.lost":
+		if reason, _ := data["reason"].(string); reason != "" {
+			detail = "Reason: " + reason
+		}
+	case "trial.ending":
+		if end, _ := data["trial_end"].(string); end != "" {
+			detail = "Trial ends " + end
+		}
+	case "invoice.upcoming":
+		if due, _ := data["due_at"].(string); due != "" {
+			detail = "Due " + due
+		}
+	case "subscription.past_due", "subscription.paused", "subscription.resumed":
+		if prev, _ := data["previous_status"].(string); prev != "" {
+			status, _ := data["status"].(string)
+			detail = fmt.Sprintf("Status changed from %s to %s", prev, status)
+		}
+	}
+	return amount, detail
+}
+
 func extractInt(data map[string]any, key string) int {
 	v, ok := data[key]
 	if !ok {
@@ -0,0 +1,42 @@
+package service
+
+import "testing"
+
+func TestPaymentFailedDetails(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       map[string]any
+		wantAmount string
+		wantReason string
+	}{
+		{
+			name:       "invoice webhook",
+			data:       map[string]any{"invoice_id": "in_1", "amount_cents": float64(4900), "currency": "usd"},
+			wantAmount: "$49.00",
+		},
+		{
+			name:       "charge sync with message",
+			data:       map[string]any{"amount_cents": float64(12050), "currency": "eur", "failure_code": "card_declined", "failure_message": "Your card was declined."},
+			wantAmount: "120.50 EUR",
+			wantReason: "Your card was declined.",
+		},
+		{
+			name:       "charge sync with code only",
+			data:       map[string]any{"amount_cents": 99, "currency": "gbp", "failure_code": "expired_card"},
+			wantAmount: "0.99 GBP",
+			wantReason: "expired_card",
+		},
+		{
+			name: "no amount",
+			data: map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, reason := paymentFailedDetails(tt.data)
+			if amount != tt.wantAmount || reason != tt.wantReason {
+				t.Errorf("expected %q, %q, got %q, %q", tt.wantAmount, tt.wantReason, amount, reason)
+			}
+		})
+	}
+}
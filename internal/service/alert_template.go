@@ -0,0 +1,824 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	stdhtml "html"
+	htmltemplate "html/template"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	maxAlertTemplateSize   = 20 * 1024
+	maxAlertTemplateOutput = 256 * 1024
+)
+
+// AlertTemplateData is the variable set available to org-defined alert templates.
+type AlertTemplateData struct {
+	Customer AlertTemplateCustomer
+	Account  AlertTemplateAccount
+	Score    AlertTemplateScore
+	Factors  map[string]float64
+	Rule     AlertTemplateRule
+	Payment  AlertTemplatePayment
+	Billing  AlertTemplateBilling
+	Field    AlertTemplateField
+	Renewal  AlertTemplateRenewal
+	Links    AlertTemplateLinks
+}
+
+// AlertTemplateCustomer describes the customer an alert fired for.
+type AlertTemplateCustomer struct {
+	Name        string
+	CompanyName string
+	Email       string
+	MRR         string
+	Source      string
+}
+
+// AlertTemplateAccount describes the account an account alert fired for.
+type AlertTemplateAccount struct {
+	Name   string
+	Domain string
+	MRR    string
+	Source string
+}
+
+// AlertTemplateScore describes the health score change behind an alert.
+type AlertTemplateScore struct {
+	Current           int
+	Previous          int
+	Delta             int
+	Threshold         int
+	RiskLevel         string
+	PreviousRiskLevel string
+	TopNegativeFactor string
+}
+
+// AlertTemplateRule describes the rule that fired.
+type AlertTemplateRule struct {
+	Name        string
+	TriggerType string
+	Severity    string
+}
+
+// AlertTemplatePayment describes a failed payment for payment_failed alerts.
+type AlertTemplatePayment struct {
+	Amount        string
+	FailureReason string
+}
+
+// AlertTemplateBilling describes the Stripe event behind a billing_event alert.
+type AlertTemplateBilling struct {
+	Event  string
+	Label  string
+	Amount string
+	Detail string
+}
+
+// AlertTemplateField describes the custom field behind a custom_field alert.
+type AlertTemplateField struct {
+	Key   string
+	Label string
+	Value string
+}
+
+// AlertTemplateRenewal describes the renewal behind a renewal_upcoming alert.
+type AlertTemplateRenewal struct {
+	Date      string
+	DaysUntil int
+	Amount    string
+	Source    string
+}
+
+// AlertTemplateLinks holds links into the app.
+type AlertTemplateLinks struct {
+	Customer    string
+	Account     string
+	Dashboard   string
+	Unsubscribe string
+}
+
+// AlertTemplateVariable documents one template variable.
+type AlertTemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AlertTemplateVariables is the documented variable set for alert templates.
+var AlertTemplateVariables = []AlertTemplateVariable{
+	{".Customer.Name", "Customer name"},
+	{".Customer.CompanyName", "Customer company name"},
+	{".Customer.Email", "Customer email address"},
+	{".Customer.MRR", "Customer MRR, formatted (e.g. $1200.00)"},
+	{".Customer.Source", "Integration the customer came from (stripe, hubspot, intercom)"},
+	{".Account.Name", "Account name, for account alerts"},
+	{".Account.Domain", "Account domain, for account alerts"},
+	{".Account.MRR", "Account MRR across its contacts, formatted, for account alerts"},
+	{".Account.Source", "Where the account came from (hubspot, domain, stripe), for account alerts"},
+	{".Score.Current", "Current health score (0-100)"},
+	{".Score.Previous", "Previous health score, for score_drop alerts"},
+	{".Score.Delta", "Score change; negative for drops"},
+	{".Score.Threshold", "Rule threshold, for score_below alerts"},
+	{".Score.RiskLevel", "Current risk level (green, yellow, red)"},
+	{".Score.PreviousRiskLevel", "Previous risk level, for risk_change alerts"},
+	{".Score.TopNegativeFactor", "Lowest scoring health factor"},
+	{".Factors", "Map of factor name to factor score (0-1), e.g. {{index .Factors \"payment_recency\"}}"},
+	{".Rule.Name", "Alert rule name"},
+	{".Rule.TriggerType", "Alert rule trigger type"},
+	{".Rule.Severity", "Alert rule severity (info, warning, critical)"},
+	{".Payment.Amount", "Failed payment amount, for payment_failed alerts"},
+	{".Payment.FailureReason", "Failed payment reason reported by Stripe, when there is one, for payment_failed alerts"},
+	{".Billing.Event", "Billing event type (e.g. dispute.opened), for billing_event alerts"},
+	{".Billing.Label", "Billing event label (e.g. Dispute opened), for billing_event alerts"},
+	{".Billing.Amount", "Billing event amount, formatted, for billing_event alerts"},
+	{".Billing.Detail", "One-line billing event description, for billing_event alerts"},
+	{".Field.Key", "Custom field key, for custom_field alerts"},
+	{".Field.Label", "Custom field label, for custom_field alerts"},
+	{".Field.Value", "Customer's current value of the custom field, for custom_field alerts"},
+	{".Renewal.Date", "Renewal date (YYYY-MM-DD), for renewal_upcoming alerts"},
+	{".Renewal.DaysUntil", "Days until the renewal, for renewal_upcoming alerts"},
+	{".Renewal.Amount", "Renewal amount, formatted, for renewal_upcoming alerts"},
+	{".Renewal.Source", "Where the renewal date came from (manual, hubspot, stripe), for renewal_upcoming alerts"},
+	{".Links.Customer", "Link to the customer in PulseScore"},
+	{".Links.Account", "Link to the account in PulseScore, for account alerts"},
+	{".Links.Dashboard", "Link to the dashboard"},
+	{".Links.Unsubscribe", "Link to notification preferences"},
+}
+
+// alertTemplateFuncs are the only functions templates may call besides the
+// text/template builtins (minus call).
+var alertTemplateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"default": func(fallback, value any) any {
+		if value == nil || reflect.ValueOf(value).IsZero() {
+			return fallback
+		}
+		return value
+	},
+}
+
+var alertTemplateDataType = reflect.TypeOf(AlertTemplateData{})
+
+// AlertTemplateService manages org-defined alert templates and renders them.
+type AlertTemplateService struct {
+	templates    *repository.AlertTemplateRepository
+	rules        *repository.AlertRuleRepository
+	customers    *repository.CustomerRepository
+	healthScores *repository.HealthScoreRepository
+	emails       *EmailTemplateService
+	frontendURL  string
+}
+
+// NewAlertTemplateService creates a new AlertTemplateService.
+func NewAlertTemplateService(
+	templates *repository.AlertTemplateRepository,
+	rules *repository.AlertRuleRepository,
+	customers *repository.CustomerRepository,
+	healthScores *repository.HealthScoreRepository,
+	emails *EmailTemplateService,
+	frontendURL string,
+) *AlertTemplateService {
+	return &AlertTemplateService{
+		templates:    templates,
+		rules:        rules,
+		customers:    customers,
+		healthScores: healthScores,
+		emails:       emails,
+		frontendURL:  frontendURL,
+	}
+}
+
+// CreateAlertTemplateRequest holds input for creating an alert template.
+type CreateAlertTemplateRequest struct {
+	AlertRuleID     *uuid.UUID `json:"alert_rule_id"`
+	TriggerType     *string    `json:"trigger_type"`
+	SubjectTemplate string     `json:"subject_template"`
+	BodyTemplate    string     `json:"body_template"`
+	TextTemplate    string     `json:"text_template"`
+}
+
+// UpdateAlertTemplateRequest holds input for updating an alert template.
+type UpdateAlertTemplateRequest struct {
+	SubjectTemplate *string `json:"subject_template"`
+	BodyTemplate    *string `json:"body_template"`
+	TextTemplate    *string `json:"text_template"`
+}
+
+// PreviewAlertTemplateRequest holds a draft template and the customer to render it for.
+type PreviewAlertTemplateRequest struct {
+	CustomerID      uuid.UUID  `json:"customer_id"`
+	AlertRuleID     *uuid.UUID `json:"alert_rule_id"`
+	TriggerType     string     `json:"trigger_type"`
+	SubjectTemplate string     `json:"subject_template"`
+	BodyTemplate    string     `json:"body_template"`
+	TextTemplate    string     `json:"text_template"`
+}
+
+// RenderedAlertEmail is a rendered alert email.
+type RenderedAlertEmail struct {
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"html_body"`
+	TextBody string `json:"text_body"`
+}
+
+// List returns all alert templates for an org.
+func (s *AlertTemplateService) List(ctx context.Context, orgID uuid.UUID) ([]*repository.AlertTemplate, error) {
+	templates, err := s.templates.List(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list alert templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetByID returns a single alert template.
+func (s *AlertTemplateService) GetByID(ctx context.Context, id, orgID uuid.UUID) (*repository.AlertTemplate, error) {
+	tpl, err := s.templates.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("get alert template: %w", err)
+	}
+	if tpl == nil {
+		return nil, &NotFoundError{Resource: "alert_template", Message: "alert template not found"}
+	}
+	return tpl, nil
+}
+
+// Create validates and stores a new alert template.
+func (s *AlertTemplateService) Create(ctx context.Context, orgID, userID uuid.UUID, req CreateAlertTemplateRequest) (*repository.AlertTemplate, error) {
+	if (req.AlertRuleID == nil) == (req.TriggerType == nil) {
+		return nil, &ValidationError{Field: "alert_rule_id", Message: "exactly one of alert_rule_id or trigger_type is required"}
+	}
+	if req.TriggerType != nil && !validTriggerTypes[*req.TriggerType] {
+		return nil, &ValidationError{Field: "trigger_type", Message: "invalid trigger type"}
+	}
+	if req.AlertRuleID != nil {
+		rule, err := s.rules.GetByID(ctx, *req.AlertRuleID, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("get alert rule: %w", err)
+		}
+		if rule == nil {
+			return nil, &ValidationError{Field: "alert_rule_id", Message: "alert rule not found"}
+		}
+	}
+	if err := validateAlertTemplates(req.SubjectTemplate, req.BodyTemplate, req.TextTemplate); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.templates.List(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list alert templates: %w", err)
+	}
+	for _, t := range existing {
+		if req.AlertRuleID != nil && t.AlertRuleID != nil && *t.AlertRuleID == *req.AlertRuleID {
+			return nil, &ConflictError{Resource: "alert_template", Message: "a template already exists for this alert rule"}
+		}
+		if req.TriggerType != nil && t.TriggerType != nil && *t.TriggerType == *req.TriggerType {
+			return nil, &ConflictError{Resource: "alert_template", Message: "a template already exists for this trigger type"}
+		}
+	}
+
+	tpl := &repository.AlertTemplate{
+		OrgID:           orgID,
+		AlertRuleID:     req.AlertRuleID,
+		TriggerType:     req.TriggerType,
+		SubjectTemplate: req.SubjectTemplate,
+		BodyTemplate:    req.BodyTemplate,
+		TextTemplate:    req.TextTemplate,
+		CreatedBy:       &userID,
+	}
+	if err := s.templates.Create(ctx, tpl); err != nil {
+		return nil, fmt.Errorf("create alert template: %w", err)
+	}
+	return tpl, nil
+}
+
+// Update validates and saves changes to an alert template.
+func (s *AlertTemplateService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdateAlertTemplateRequest) (*repository.AlertTemplate, error) {
+	tpl, err := s.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SubjectTemplate != nil {
+		tpl.SubjectTemplate = *req.SubjectTemplate
+	}
+	if req.BodyTemplate != nil {
+		tpl.BodyTemplate = *req.BodyTemplate
+	}
+	if req.TextTemplate != nil {
+		tpl.TextTemplate = *req.TextTemplate
+	}
+	if err := validateAlertTemplates(tpl.SubjectTemplate, tpl.BodyTemplate, tpl.TextTemplate); err != nil {
+		return nil, err
+	}
+
+	if err := s.templates.Update(ctx, tpl); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &NotFoundError{Resource: "alert_template", Message: "alert template not found"}
+		}
+		return nil, fmt.Errorf("update alert template: %w", err)
+	}
+	return tpl, nil
+}
+
+// Delete deletes an alert template.
+func (s *AlertTemplateService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	if err := s.templates.Delete(ctx, id, orgID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &NotFoundError{Resource: "alert_template", Message: "alert template not found"}
+		}
+		return fmt.Errorf("delete alert template: %w", err)
+	}
+	return nil
+}
+
+// Variables returns the documented template variable set.
+func (s *AlertTemplateService) Variables() []AlertTemplateVariable {
+	return AlertTemplateVariables
+}
+
+// Preview renders a draft template against a real customer's current data.
+func (s *AlertTemplateService) Preview(ctx context.Context, orgID uuid.UUID, req PreviewAlertTemplateRequest) (*RenderedAlertEmail, error) {
+	if err := validateAlertTemplates(req.SubjectTemplate, req.BodyTemplate, req.TextTemplate); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.customers.GetByIDAndOrg(ctx, req.CustomerID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("get customer: %w", err)
+	}
+	if customer == nil {
+		return nil, &NotFoundError{Resource: "customer", Message: "customer not found"}
+	}
+
+	rule := &repository.AlertRule{OrgID: orgID, Name: "Preview", TriggerType: req.TriggerType, Severity: "warning"}
+	if req.AlertRuleID != nil {
+		rule, err = s.rules.GetByID(ctx, *req.AlertRuleID, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("get alert rule: %w", err)
+		}
+		if rule == nil {
+			return nil, &NotFoundError{Resource: "alert_rule", Message: "alert rule not found"}
+		}
+	} else if req.TriggerType != "" && !validTriggerTypes[req.TriggerType] {
+		return nil, &ValidationError{Field: "trigger_type", Message: "invalid trigger type"}
+	}
+
+	data := s.buildData(ctx, AlertMatch{Rule: rule, Customer: customer, TriggerData: map[string]any{}})
+	data.Score.Threshold = getConditionInt(rule.Conditions, "threshold", 0)
+	if history, err := s.healthScores.GetHistory(ctx, customer.ID, 2); err == nil && len(history) == 2 {
+		data.Score.Previous = history[1].OverallScore
+		data.Score.PreviousRiskLevel = history[1].RiskLevel
+		data.Score.Delta = data.Score.Current - data.Score.Previous
+	}
+
+	tpl := &repository.AlertTemplate{
+		SubjectTemplate: req.SubjectTemplate,
+		BodyTemplate:    req.BodyTemplate,
+		TextTemplate:    req.TextTemplate,
+	}
+	rendered, err := s.render(tpl, data)
+	if err != nil {
+		return nil, &ValidationError{Field: "template", Message: err.Error()}
+	}
+	return rendered, nil
+}
+
+// RenderForMatch renders the org's template for an alert match. It returns nil
+// when the org has no template for the rule or its trigger type.
+func (s *AlertTemplateService) RenderForMatch(ctx context.Context, match AlertMatch) (*RenderedAlertEmail, error) {
+	tpl, err := s.templates.FindForRule(ctx, match.Rule.OrgID, match.Rule.ID, match.Rule.TriggerType)
+	if err != nil {
+		return nil, err
+	}
+	if tpl == nil {
+		return nil, nil
+	}
+	return s.render(tpl, s.buildData(ctx, match))
+}
+
+// RenderInline renders a subject and body template that are not stored as an
+// org template, such as a playbook email, against an alert match.
+func (s *AlertTemplateService) RenderInline(ctx context.Context, match AlertMatch, subject, body string) (*RenderedAlertEmail, error) {
+	return s.render(&repository.AlertTemplate{SubjectTemplate: subject, BodyTemplate: body}, s.buildData(ctx, match))
+}
+
+// buildData maps an alert match onto the template variable set.
+func (s *AlertTemplateService) buildData(ctx context.Context, match AlertMatch) AlertTemplateData {
+	data := AlertTemplateData{
+		Factors: map[string]float64{},
+		Rule: AlertTemplateRule{
+			Name:        match.Rule.Name,
+			TriggerType: match.Rule.TriggerType,
+			Severity:    match.Rule.Severity,
+		},
+		Links: AlertTemplateLinks{
+			Dashboard:   fmt.Sprintf("%s/dashboard", s.frontendURL),
+			Unsubscribe: fmt.Sprintf("%s/settings?tab=notifications", s.frontendURL),
+		},
+	}
+
+	if match.Account != nil {
+		data.Account = AlertTemplateAccount{
+			Name:   match.Account.Name,
+			Domain: match.Account.Domain,
+			MRR:    formatCents(match.Account.MRRCents),
+			Source: match.Account.Source,
+		}
+		data.Links.Account = fmt.Sprintf("%s/accounts/%s", s.frontendURL, match.Account.ID)
+	}
+
+	if match.Customer == nil {
+		data.Score.Current = extractInt(match.TriggerData, "score")
+		applyAlertTriggerData(&data, match)
+		return data
+	}
+
+	data.Customer = AlertTemplateCustomer{
+		Name:        match.Customer.Name,
+		CompanyName: match.Customer.CompanyName,
+		Email:       match.Customer.Email,
+		MRR:         formatCents(match.Customer.MRRCents),
+		Source:      match.Customer.Source,
+	}
+	data.Links.Customer = fmt.Sprintf("%s/customers/%s", s.frontendURL, match.Customer.ID)
+
+	if score, err := s.healthScores.GetByCustomerID(ctx, match.Customer.ID, match.Rule.OrgID); err != nil {
+		slog.Error("alert template: get health score", "customer_id", match.Customer.ID, "error", err)
+	} else if score != nil {
+		data.Score.Current = score.OverallScore
+		data.Score.RiskLevel = score.RiskLevel
+		data.Score.TopNegativeFactor = lowestFactor(score.Factors)
+		if score.Factors != nil {
+			data.Factors = score.Factors
+		}
+	}
+
+	applyAlertTriggerData(&data, match)
+	return data
+}
+
+// applyAlertTriggerData fills the score, payment, billing, field and renewal variables
+// from a match's trigger data.
+func applyAlertTriggerData(data *AlertTemplateData, match AlertMatch) {
+	td := match.TriggerData
+	switch match.Rule.TriggerType {
+	case "score_below", "account_score_below":
+		data.Score.Current = extractInt(td, "score")
+		data.Score.Threshold = extractInt(td, "threshold")
+	case "score_drop":
+		data.Score.Previous = extractInt(td, "old_score")
+		data.Score.Current = extractInt(td, "new_score")
+		data.Score.Delta = extractInt(td, "delta")
+		if factor, _ := td["biggest_contributing_factor"].(string); factor != "" {
+			data.Score.TopNegativeFactor = factor
+		}
+	case "risk_change", "account_risk_change":
+		data.Score.PreviousRiskLevel, _ = td["previous_level"].(string)
+		if level, _ := td["new_level"].(string); level != "" {
+			data.Score.RiskLevel = level
+		}
+	case "payment_failed":
+		data.Payment.Amount, data.Payment.FailureReason = paymentFailedDetails(td)
+	case "billing_event":
+		data.Billing.Event, _ = td["event_type"].(string)
+		data.Billing.Label = BillingEventLabel(data.Billing.Event)
+		data.Billing.Amount, data.Billing.Detail = billingEventDetails(td)
+	case "custom_field":
+		data.Field.Key, _ = td["field"].(string)
+		data.Field.Label, _ = td["label"].(string)
+		data.Field.Value, _ = td["field_value"].(string)
+	case "renewal_upcoming":
+		data.Renewal.Date, _ = td["renewal_date"].(string)
+		data.Renewal.DaysUntil = extractInt(td, "days_until")
+		data.Renewal.Amount = formatCents(extractInt(td, "amount_cents"))
+		data.Renewal.Source, _ = td["source"].(string)
+		if _, ok := td["score"]; ok {
+			data.Score.Current = extractInt(td, "score")
+		}
+	}
+	if level, _ := td["risk_level"].(string); level != "" {
+		data.Score.RiskLevel = level
+	}
+}
+
+func (s *AlertTemplateService) render(tpl *repository.AlertTemplate, data AlertTemplateData) (*RenderedAlertEmail, error) {
+	subject, err := executeAlertTemplate("subject", tpl.SubjectTemplate, data, false)
+	if err != nil {
+		return nil, fmt.Errorf("render subject: %w", err)
+	}
+	content, err := executeAlertTemplate("body", tpl.BodyTemplate, data, true)
+	if err != nil {
+		return nil, fmt.Errorf("render body: %w", err)
+	}
+	html, err := s.emails.RenderCustom(CustomEmailData{
+		Content:        htmltemplate.HTML(content),
+		UnsubscribeURL: data.Links.Unsubscribe,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	text := htmlToText(content)
+	if tpl.TextTemplate != "" {
+		text, err = executeAlertTemplate("text", tpl.TextTemplate, data, false)
+		if err != nil {
+			return nil, fmt.Errorf("render text: %w", err)
+		}
+	}
+
+	return &RenderedAlertEmail{
+		Subject:  strings.Join(strings.Fields(subject), " "),
+		HTMLBody: html,
+		TextBody: text,
+	}, nil
+}
+
+// validateAlertTemplates checks the subject, body and optional text templates.
+func validateAlertTemplates(subject, body, text string) error {
+	if strings.TrimSpace(subject) == "" {
+		return &ValidationError{Field: "subject_template", Message: "subject_template is required"}
+	}
+	if strings.TrimSpace(body) == "" {
+		return &ValidationError{Field: "body_template", Message: "body_template is required"}
+	}
+	for _, f := range []struct{ field, src string }{
+		{"subject_template", subject},
+		{"body_template", body},
+		{"text_template", text},
+	} {
+		if err := checkAlertTemplate(f.src); err != nil {
+			return &ValidationError{Field: f.field, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// checkAlertTemplate parses a template and rejects anything outside the sandbox:
+// unknown variables, nested template definitions and functions other than the
+// builtins and alertTemplateFuncs.
+func checkAlertTemplate(src string) error {
+	if len(src) > maxAlertTemplateSize {
+		return fmt.Errorf("template exceeds %d bytes", maxAlertTemplateSize)
+	}
+	t, err := template.New("check").Funcs(alertTemplateFuncs).Parse(src)
+	if err != nil {
+		return fmt.Errorf("invalid template: %s", strings.TrimPrefix(err.Error(), "template: check:"))
+	}
+	if len(t.Templates()) > 1 {
+		return errors.New("defining templates is not allowed")
+	}
+	if t.Tree == nil {
+		return nil
+	}
+	c := &alertTemplateChecker{}
+	return c.walk(t.Tree.Root, alertTemplateDataType, map[string]reflect.Type{"$": alertTemplateDataType})
+}
+
+// executeAlertTemplate renders a validated template with output capped at maxAlertTemplateOutput.
+func executeAlertTemplate(name, src string, data AlertTemplateData, escapeHTML bool) (string, error) {
+	if err := checkAlertTemplate(src); err != nil {
+		return "", err
+	}
+
+	out := &limitedBuffer{limit: maxAlertTemplateOutput}
+	if escapeHTML {
+		t, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(alertTemplateFuncs)).Parse(src)
+		if err != nil {
+			return "", err
+		}
+		err = t.Execute(out, data)
+		return out.String(), err
+	}
+
+	t, err := template.New(name).Funcs(alertTemplateFuncs).Parse(src)
+	if err != nil {
+		return "", err
+	}
+	err = t.Execute(out, data)
+	return out.String(), err
+}
+
+// limitedBuffer is a bytes.Buffer that fails writes past its limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("rendered template exceeds %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
+
+var (
+	htmlTagPattern    = regexp.MustCompile(`<[^>]*>`)
+	blankLinesPattern = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// htmlToText derives a plain-text body from rendered HTML.
+func htmlToText(s string) string {
+	s = htmlTagPattern.ReplaceAllString(s, "\n")
+	s = stdhtml.UnescapeString(s)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// alertTemplateChecker type-checks a template parse tree against AlertTemplateData.
+type alertTemplateChecker struct{}
+
+func (c *alertTemplateChecker) walk(node parse.Node, dot reflect.Type, vars map[string]reflect.Type) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		scope := copyVars(vars)
+		for _, child := range n.Nodes {
+			if err := c.walk(child, dot, scope); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.TextNode, *parse.CommentNode, *parse.BreakNode, *parse.ContinueNode:
+		return nil
+	case *parse.ActionNode:
+		_, err := c.pipe(n.Pipe, dot, vars)
+		return err
+	case *parse.IfNode:
+		scope := copyVars(vars)
+		if _, err := c.pipe(n.Pipe, dot, scope); err != nil {
+			return err
+		}
+		if err := c.walk(n.List, dot, scope); err != nil {
+			return err
+		}
+		return c.walk(n.ElseList, dot, scope)
+	case *parse.WithNode:
+		scope := copyVars(vars)
+		t, err := c.pipe(n.Pipe, dot, scope)
+		if err != nil {
+			return err
+		}
+		if err := c.walk(n.List, t, scope); err != nil {
+			return err
+		}
+		return c.walk(n.ElseList, dot, scope)
+	case *parse.RangeNode:
+		scope := copyVars(vars)
+		decl := n.Pipe.Decl
+		n.Pipe.Decl = nil
+		t, err := c.pipe(n.Pipe, dot, scope)
+		n.Pipe.Decl = decl
+		if err != nil {
+			return err
+		}
+		// Only maps, slices and arrays from the data can be ranged over. Ranging
+		// over an integer, or a value whose type is unknown such as a literal or
+		// a function result, could loop for as long as the number allows.
+		if t == nil {
+			return fmt.Errorf("cannot range over %s", n.Pipe)
+		}
+		var key, elem reflect.Type
+		switch t.Kind() {
+		case reflect.Map:
+			key, elem = t.Key(), t.Elem()
+		case reflect.Slice, reflect.Array:
+			key, elem = reflect.TypeOf(0), t.Elem()
+		default:
+			return fmt.Errorf("cannot range over %s", n.Pipe)
+		}
+		switch len(decl) {
+		case 1:
+			scope[decl[0].Ident[0]] = elem
+		case 2:
+			scope[decl[0].Ident[0]] = key
+			scope[decl[1].Ident[0]] = elem
+		}
+		if err := c.walk(n.List, elem, scope); err != nil {
+			return err
+		}
+		return c.walk(n.ElseList, dot, scope)
+	case *parse.TemplateNode:
+		return errors.New("including templates is not allowed")
+	default:
+		return fmt.Errorf("unsupported template construct %s", node)
+	}
+}
+
+// pipe checks a pipeline and returns its result type, or nil when it cannot be known statically.
+func (c *alertTemplateChecker) pipe(p *parse.PipeNode, dot reflect.Type, vars map[string]reflect.Type) (reflect.Type, error) {
+	if p == nil {
+		return nil, nil
+	}
+	var t reflect.Type
+	for _, cmd := range p.Cmds {
+		var err error
+		if t, err = c.command(cmd, dot, vars); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range p.Decl {
+		vars[v.Ident[0]] = t
+	}
+	return t, nil
+}
+
+func (c *alertTemplateChecker) command(cmd *parse.CommandNode, dot reflect.Type, vars map[string]reflect.Type) (reflect.Type, error) {
+	for _, arg := range cmd.Args[1:] {
+		if _, err := c.operand(arg, dot, vars); err != nil {
+			return nil, err
+		}
+	}
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		if ident.Ident == "call" {
+			return nil, errors.New("function \"call\" is not allowed")
+		}
+		return nil, nil
+	}
+	if len(cmd.Args) > 1 {
+		return nil, fmt.Errorf("%s is not a function", cmd.Args[0])
+	}
+	return c.operand(cmd.Args[0], dot, vars)
+}
+
+func (c *alertTemplateChecker) operand(node parse.Node, dot reflect.Type, vars map[string]reflect.Type) (reflect.Type, error) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return resolveTemplateField(dot, n.Ident)
+	case *parse.VariableNode:
+		base, ok := vars[n.Ident[0]]
+		if !ok {
+			return nil, fmt.Errorf("undefined variable %s", n.Ident[0])
+		}
+		return resolveTemplateField(base, n.Ident[1:])
+	case *parse.ChainNode:
+		base, err := c.operand(n.Node, dot, vars)
+		if err != nil {
+			return nil, err
+		}
+		return resolveTemplateField(base, n.Field)
+	case *parse.PipeNode:
+		return c.pipe(n, dot, copyVars(vars))
+	case *parse.IdentifierNode:
+		if n.Ident == "call" {
+			return nil, errors.New("function \"call\" is not allowed")
+		}
+		return nil, nil
+	case *parse.BoolNode, *parse.NumberNode, *parse.StringNode, *parse.NilNode:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported template construct %s", node)
+	}
+}
+
+// resolveTemplateField follows a field path from t, rejecting unknown names.
+func resolveTemplateField(t reflect.Type, path []string) (reflect.Type, error) {
+	for i, name := range path {
+		if t == nil {
+			return nil, fmt.Errorf("cannot access .%s here", strings.Join(path[i:], "."))
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := t.FieldByName(name)
+			if !ok || !f.IsExported() {
+				return nil, fmt.Errorf("unknown variable %q", "."+strings.Join(path[:i+1], "."))
+			}
+			t = f.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("unknown variable %q", "."+strings.Join(path[:i+1], "."))
+		}
+	}
+	return t, nil
+}
+
+func copyVars(vars map[string]reflect.Type) map[string]reflect.Type {
+	scope := make(map[string]reflect.Type, len(vars))
+	for k, v := range vars {
+		scope[k] = v
+	}
+	return scope
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckAlertTemplate_Range(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr bool
+	}{
+		{`{{range $name, $weight := .Factors}}{{$name}}={{$weight}} {{end}}`, false},
+		{`{{range .Factors}}{{.}}{{else}}none{{end}}`, false},
+		{`{{range 9999999999}}{{end}}`, true},
+		{`{{range $i := 200000000}}{{$i}}{{end}}`, true},
+		{`{{$n := 200000000}}{{range $n}}{{end}}`, true},
+		{`{{range .Score.Current}}{{end}}`, true},
+		{`{{range default 200000000 .Score.Current}}{{end}}`, true},
+		{`{{range .Customer.Name}}{{end}}`, true},
+	}
+	for _, tt := range tests {
+		err := checkAlertTemplate(tt.src)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkAlertTemplate(%q) error = %v, wantErr %v", tt.src, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExecuteAlertTemplate_RangeOverLargeLiteralRejected(t *testing.T) {
+	start := time.Now()
+	if _, err := executeAlertTemplate("body", `{{range 200000000}}{{end}}`, AlertTemplateData{}, true); err == nil {
+		t.Fatal("expected ranging over a number to be rejected")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the template to be rejected before running, took %s", elapsed)
+	}
+}
@@ -2,44 +2,38 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
-	"github.com/stripe/stripe-go/v81"
-	stripecustomer "github.com/stripe/stripe-go/v81/customer"
-
+	"github.com/onnwee/pulse-score/internal/integration"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
+// connectionMaxErrors is the number of consecutive failed health checks after
+// which a connection is disabled.
+const connectionMaxErrors = 5
+
 // ConnectionMonitorService periodically checks the health of integration connections.
 type ConnectionMonitorService struct {
-	connRepo       *repository.IntegrationConnectionRepository
-	oauthSvc       *StripeOAuthService
-	hubspotOAuth   *HubSpotOAuthService
-	hubspotClient  *HubSpotClient
-	intercomOAuth  *IntercomOAuthService
-	intercomClient *IntercomClient
-	interval       time.Duration
+	connRepo  *repository.IntegrationConnectionRepository
+	providers *integration.Registry
+	tokens    *TokenLifecycleService
+	interval  time.Duration
 }
 
 // NewConnectionMonitorService creates a new ConnectionMonitorService.
 func NewConnectionMonitorService(
 	connRepo *repository.IntegrationConnectionRepository,
-	oauthSvc *StripeOAuthService,
-	hubspotOAuth *HubSpotOAuthService,
-	hubspotClient *HubSpotClient,
-	intercomOAuth *IntercomOAuthService,
-	intercomClient *IntercomClient,
+	providers *integration.Registry,
+	tokens *TokenLifecycleService,
 	intervalMinutes int,
 ) *ConnectionMonitorService {
 	return &ConnectionMonitorService{
-		connRepo:       connRepo,
-		oauthSvc:       oauthSvc,
-		hubspotOAuth:   hubspotOAuth,
-		hubspotClient:  hubspotClient,
-		intercomOAuth:  intercomOAuth,
-		intercomClient: intercomClient,
-		interval:       time.Duration(intervalMinutes) * time.Minute,
+		connRepo:  connRepo,
+		providers: providers,
+		tokens:    tokens,
+		interval:  time.Duration(intervalMinutes) * time.Minute,
 	}
 }
 
@@ -62,205 +56,80 @@ func (s *ConnectionMonitorService) Start(ctx context.Context) {
 }
 
 func (s *ConnectionMonitorService) checkAll(ctx context.Context) {
-	// Check Stripe connections
-	conns, err := s.connRepo.ListActiveByProvider(ctx, "stripe")
-	if err != nil {
-		slog.Error("monitor: failed to list stripe connections", "error", err)
-	} else {
+	for _, p := range s.providers.All() {
+		conns, err := s.connRepo.ListActiveByProvider(ctx, p.Name())
+		if err != nil {
+			slog.Error("monitor: failed to list connections", "provider", p.Name(), "error", err)
+			continue
+		}
+
 		for _, conn := range conns {
-			if err := s.checkConnection(ctx, conn); err != nil {
-				slog.Error("monitor: stripe connection check failed",
+			if err := s.checkConnection(ctx, p, conn); err != nil {
+				slog.Error("monitor: connection check failed",
+					"provider", p.Name(),
 					"org_id", conn.OrgID,
 					"error", err,
 				)
 			}
 		}
 	}
-
-	// Check HubSpot connections
-	if s.hubspotOAuth != nil && s.hubspotClient != nil {
-		hsConns, err := s.connRepo.ListActiveByProvider(ctx, "hubspot")
-		if err != nil {
-			slog.Error("monitor: failed to list hubspot connections", "error", err)
-		} else {
-			for _, conn := range hsConns {
-				if err := s.checkHubSpotConnection(ctx, conn); err != nil {
-					slog.Error("monitor: hubspot connection check failed",
-						"org_id", conn.OrgID,
-						"error", err,
-					)
-				}
-			}
-		}
-	}
-
-	// Check Intercom connections
-	if s.intercomOAuth != nil && s.intercomClient != nil {
-		icConns, err := s.connRepo.ListActiveByProvider(ctx, "intercom")
-		if err != nil {
-			slog.Error("monitor: failed to list intercom connections", "error", err)
-		} else {
-			for _, conn := range icConns {
-				if err := s.checkIntercomConnection(ctx, conn); err != nil {
-					slog.Error("monitor: intercom connection check failed",
-						"org_id", conn.OrgID,
-						"error", err,
-					)
-				}
-			}
-		}
-	}
 }
 
-func (s *ConnectionMonitorService) checkConnection(ctx context.Context, conn *repository.IntegrationConnection) error {
-	accessToken, err := s.oauthSvc.GetAccessToken(ctx, conn.OrgID)
-	if err != nil {
-		// Token decrypt/retrieval failed — mark as error
-		if err := s.connRepo.UpdateSyncStatus(ctx, conn.OrgID, "stripe", "error", nil); err != nil {
-			slog.Error("monitor: failed to update status", "error", err)
-		}
-		return err
-	}
-
-	// Do a lightweight API call to verify the token works
-	client := stripecustomer.Client{B: stripe.GetBackend(stripe.APIBackend), Key: accessToken}
-	params := &stripe.CustomerListParams{}
-	params.Limit = stripe.Int64(1)
-	iter := client.List(params)
+func (s *ConnectionMonitorService) checkConnection(ctx context.Context, p integration.Provider, conn *repository.IntegrationConnection) error {
+	provider := p.Name()
 
-	// Try to iterate once
-	if iter.Next() {
-		// Connection is healthy
+	err := p.CheckHealth(ctx, conn.OrgID)
+	if err == nil {
 		return nil
 	}
 
-	if iterErr := iter.Err(); iterErr != nil {
-		slog.Warn("monitor: Stripe API call failed",
-			"org_id", conn.OrgID,
-			"error", iterErr,
-		)
-
-		if err := s.connRepo.UpdateErrorCount(ctx, conn.OrgID, "stripe", iterErr.Error()); err != nil {
-			slog.Error("monitor: failed to update error count", "error", err)
-		}
-
-		// Check if we've hit too many consecutive errors
-		updatedConn, err := s.connRepo.GetByOrgAndProvider(ctx, conn.OrgID, "stripe")
-		if err == nil && updatedConn != nil {
-			errorCount := 0
-			if v, ok := updatedConn.Metadata["error_count"]; ok {
-				if n, ok := v.(float64); ok {
-					errorCount = int(n)
-				}
-			}
-
-			// Disable after 5 consecutive failures
-			if errorCount >= 5 {
-				slog.Error("monitor: disabling connection after too many failures",
-					"org_id", conn.OrgID,
-					"error_count", errorCount,
-				)
-				if err := s.connRepo.UpdateSyncStatus(ctx, conn.OrgID, "stripe", "disconnected", nil); err != nil {
-					slog.Error("monitor: failed to disable connection", "error", err)
-				}
-			}
-		}
-
-		return iterErr
-	}
-
-	// No error, just no customers — that's fine
-	return nil
-}
-
-func (s *ConnectionMonitorService) checkHubSpotConnection(ctx context.Context, conn *repository.IntegrationConnection) error {
-	accessToken, err := s.hubspotOAuth.GetAccessToken(ctx, conn.OrgID)
-	if err != nil {
-		if err := s.connRepo.UpdateSyncStatus(ctx, conn.OrgID, "hubspot", "error", nil); err != nil {
-			slog.Error("monitor: failed to update hubspot status", "error", err)
+	if errors.Is(err, integration.ErrReauthRequired) {
+		// The provider revoked the grant — retrying won't help, ask for a reconnect
+		if err := s.tokens.RequireReauth(ctx, p, conn.OrgID, err); err != nil {
+			slog.Error("monitor: failed to flag connection for reauth", "provider", provider, "error", err)
 		}
 		return err
 	}
 
-	// Lightweight API call: list 1 contact to verify token
-	_, err = s.hubspotClient.ListContacts(ctx, accessToken, "")
-	if err != nil {
-		slog.Warn("monitor: HubSpot API call failed",
-			"org_id", conn.OrgID,
-			"error", err,
-		)
-
-		if err := s.connRepo.UpdateErrorCount(ctx, conn.OrgID, "hubspot", err.Error()); err != nil {
-			slog.Error("monitor: failed to update hubspot error count", "error", err)
-		}
-
-		updatedConn, lookupErr := s.connRepo.GetByOrgAndProvider(ctx, conn.OrgID, "hubspot")
-		if lookupErr == nil && updatedConn != nil {
-			errorCount := 0
-			if v, ok := updatedConn.Metadata["error_count"]; ok {
-				if n, ok := v.(float64); ok {
-					errorCount = int(n)
-				}
-			}
-			if errorCount >= 5 {
-				slog.Error("monitor: disabling hubspot connection after too many failures",
-					"org_id", conn.OrgID,
-					"error_count", errorCount,
-				)
-				if err := s.connRepo.UpdateSyncStatus(ctx, conn.OrgID, "hubspot", "disconnected", nil); err != nil {
-					slog.Error("monitor: failed to disable hubspot connection", "error", err)
-				}
-			}
+	if errors.Is(err, integration.ErrCredentials) {
+		// Token decrypt/retrieval failed — mark as error
+		if err := s.connRepo.UpdateSyncStatus(ctx, conn.OrgID, provider, "error", nil); err != nil {
+			slog.Error("monitor: failed to update status", "provider", provider, "error", err)
 		}
-
 		return err
 	}
 
-	return nil
-}
+	slog.Warn("monitor: provider API call failed",
+		"provider", provider,
+		"org_id", conn.OrgID,
+		"error", err,
+	)
 
-func (s *ConnectionMonitorService) checkIntercomConnection(ctx context.Context, conn *repository.IntegrationConnection) error {
-	accessToken, err := s.intercomOAuth.GetAccessToken(ctx, conn.OrgID)
-	if err != nil {
-		if err := s.connRepo.UpdateSyncStatus(ctx, conn.OrgID, "intercom", "error", nil); err != nil {
-			slog.Error("monitor: failed to update intercom status", "error", err)
-		}
-		return err
+	if err := s.connRepo.UpdateErrorCount(ctx, conn.OrgID, provider, err.Error()); err != nil {
+		slog.Error("monitor: failed to update error count", "provider", provider, "error", err)
 	}
 
-	// Lightweight API call: list 1 contact to verify token
-	_, err = s.intercomClient.ListContacts(ctx, accessToken, "")
-	if err != nil {
-		slog.Warn("monitor: Intercom API call failed",
-			"org_id", conn.OrgID,
-			"error", err,
-		)
-
-		if err := s.connRepo.UpdateErrorCount(ctx, conn.OrgID, "intercom", err.Error()); err != nil {
-			slog.Error("monitor: failed to update intercom error count", "error", err)
+	// Check if we've hit too many consecutive errors
+	updatedConn, lookupErr := s.connRepo.GetByOrgAndProvider(ctx, conn.OrgID, provider)
+	if lookupErr == nil && updatedConn != nil {
+		errorCount := 0
+		if v, ok := updatedConn.Metadata["error_count"]; ok {
+			if n, ok := v.(float64); ok {
+				errorCount = int(n)
+			}
 		}
 
-		updatedConn, lookupErr := s.connRepo.GetByOrgAndProvider(ctx, conn.OrgID, "intercom")
-		if lookupErr == nil && updatedConn != nil {
-			errorCount := 0
-			if v, ok := updatedConn.Metadata["error_count"]; ok {
-				if n, ok := v.(float64); ok {
-					errorCount = int(n)
-				}
-			}
-			if errorCount >= 5 {
-				slog.Error("monitor: disabling intercom connection after too many failures",
-					"org_id", conn.OrgID,
-					"error_count", errorCount,
-				)
-				if err := s.connRepo.UpdateSyncStatus(ctx, conn.OrgID, "intercom", "disconnected", nil); err != nil {
-					slog.Error("monitor: failed to disable intercom connection", "error", err)
-				}
+		if errorCount >= connectionMaxErrors {
+			slog.Error("monitor: disabling connection after too many failures",
+				"provider", provider,
+				"org_id", conn.OrgID,
+				"error_count", errorCount,
+			)
+			if err := s.connRepo.UpdateSyncStatus(ctx, conn.OrgID, provider, "disconnected", nil); err != nil {
+				slog.Error("monitor: failed to disable connection", "provider", provider, "error", err)
 			}
 		}
-
-		return err
 	}
 
-	return nil
+	return err
 }
@@ -0,0 +1,736 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	maxCustomFieldLabelLength  = 255
+	maxCustomFieldOptions      = 100
+	maxCustomFieldTextLength   = 1000
+	maxCustomFieldSourceLength = 255
+	customFieldDateLayout      = "2006-01-02"
+)
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// customFieldOps are the filter and alert condition ops each custom field
+// type supports.
+var customFieldOps = map[string][]string{
+	"string":  {"eq", "neq", "in", "not_in", "contains", "exists", "not_exists"},
+	"number":  {"eq", "neq", "lt", "lte", "gt", "gte", "exists", "not_exists"},
+	"date":    {"eq", "neq", "lt", "lte", "gt", "gte", "exists", "not_exists"},
+	"enum":    {"eq", "neq", "in", "not_in", "exists", "not_exists"},
+	"boolean": {"eq", "neq", "exists", "not_exists"},
+}
+
+// customFieldSources are the integrations a custom field can be filled from.
+var customFieldSources = map[string]bool{
+	"stripe":   true,
+	"hubspot":  true,
+	"intercom": true,
+}
+
+// CustomFieldService manages an org's typed custom fields and customers'
+// values for them. Fields mapped to a source are filled from the metadata
+// synced onto customers and cannot be edited by hand.
+type CustomFieldService struct {
+	fields    *repository.CustomFieldRepository
+	customers *repository.CustomerRepository
+}
+
+// NewCustomFieldService creates a new CustomFieldService.
+func NewCustomFieldService(fields *repository.CustomFieldRepository, customers *repository.CustomerRepository) *CustomFieldService {
+	return &CustomFieldService{fields: fields, customers: customers}
+}
+
+// CreateCustomFieldRequest holds input for creating a custom field.
+type CreateCustomFieldRequest struct {
+	Key       string                         `json:"key"`
+	Label     string                         `json:"label"`
+	FieldType string                         `json:"field_type"`
+	Options   []string                       `json:"options"`
+	Source    *string                        `json:"source"`
+	SourceKey *string                        `json:"source_key"`
+	Scoring   *repository.CustomFieldScoring `json:"scoring"`
+}
+
+// UpdateCustomFieldRequest holds input for updating a custom field. The key
+// and type cannot be changed.
+type UpdateCustomFieldRequest struct {
+	Label        *string                        `json:"label"`
+	Options      *[]string                      `json:"options"`
+	Source       *string                        `json:"source"`
+	SourceKey    *string                        `json:"source_key"`
+	ClearSource  bool                           `json:"clear_source"`
+	Scoring      *repository.CustomFieldScoring `json:"scoring"`
+	ClearScoring bool                           `json:"clear_scoring"`
+}
+
+// CustomerFieldValue is a customer's value for one of the org's custom
+// fields. Value is nil when the customer has none.
+type CustomerFieldValue struct {
+	FieldID   uuid.UUID  `json:"field_id"`
+	Key       string     `json:"key"`
+	Label     string     `json:"label"`
+	FieldType string     `json:"field_type"`
+	Value     any        `json:"value"`
+	Source    string     `json:"source,omitempty"`
+	ReadOnly  bool       `json:"read_only"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// SetCustomerFieldValuesRequest holds custom field values to set on a
+// customer, by field key. A null value clears the field.
+type SetCustomerFieldValuesRequest struct {
+	Values map[string]any `json:"values"`
+}
+
+// List returns an org's custom fields.
+func (s *CustomFieldService) List(ctx context.Context, orgID uuid.UUID) ([]*repository.CustomField, error) {
+	return s.fields.ListByOrg(ctx, orgID)
+}
+
+// Create defines a custom field. A field mapped to a source is filled from
+// it straight away.
+func (s *CustomFieldService) Create(ctx context.Context, orgID, userID uuid.UUID, req CreateCustomFieldRequest) (*repository.CustomField, error) {
+	field := &repository.CustomField{
+		OrgID:     orgID,
+		Key:       strings.TrimSpace(req.Key),
+		Label:     strings.TrimSpace(req.Label),
+		FieldType: req.FieldType,
+		Options:   req.Options,
+		Source:    req.Source,
+		SourceKey: req.SourceKey,
+		Scoring:   req.Scoring,
+		CreatedBy: &userID,
+	}
+
+	if !customFieldKeyPattern.MatchString(field.Key) {
+		return nil, &ValidationError{Field: "key", Message: "key must start with a lowercase letter and contain only lowercase letters, digits and underscores (at most 63 characters)"}
+	}
+	if _, ok := customFieldOps[field.FieldType]; !ok {
+		return nil, &ValidationError{Field: "field_type", Message: "field_type must be string, number, date, enum or boolean"}
+	}
+	if err := validateCustomField(field); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.fields.GetByKey(ctx, orgID, field.Key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, &ConflictError{Message: "a custom field with this key already exists"}
+	}
+
+	if err := s.fields.Create(ctx, field); err != nil {
+		return nil, fmt.Errorf("create custom field: %w", err)
+	}
+	s.syncAfterChange(ctx, field)
+	return field, nil
+}
+
+// Update applies partial updates to a custom field. Values no longer among
+// an enum field's options are kept until they are changed.
+func (s *CustomFieldService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdateCustomFieldRequest) (*repository.CustomField, error) {
+	field, err := s.get(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Label != nil {
+		field.Label = strings.TrimSpace(*req.Label)
+	}
+	if req.Options != nil {
+		field.Options = *req.Options
+	}
+	if req.ClearSource {
+		field.Source, field.SourceKey = nil, nil
+	} else if req.Source != nil || req.SourceKey != nil {
+		if req.Source != nil {
+			field.Source = req.Source
+		}
+		if req.SourceKey != nil {
+			field.SourceKey = req.SourceKey
+		}
+	}
+	if req.ClearScoring {
+		field.Scoring = nil
+	} else if req.Scoring != nil {
+		field.Scoring = req.Scoring
+	}
+
+	if err := validateCustomField(field); err != nil {
+		return nil, err
+	}
+	if err := s.fields.Update(ctx, field); err != nil {
+		return nil, err
+	}
+	s.syncAfterChange(ctx, field)
+	return field, nil
+}
+
+// Delete deletes a custom field and its values. Fields that alert rules or
+// segments test cannot be deleted, since they would silently stop matching.
+func (s *CustomFieldService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	field, err := s.get(ctx, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	rules, err := s.fields.CountAlertRules(ctx, orgID, field.Key)
+	if err != nil {
+		return err
+	}
+	if rules > 0 {
+		return &ConflictError{Message: fmt.Sprintf("custom field is used by %d alert rule(s)", rules)}
+	}
+	segments, err := s.fields.CountSegments(ctx, orgID, field.Key)
+	if err != nil {
+		return err
+	}
+	if segments > 0 {
+		return &ConflictError{Message: fmt.Sprintf("custom field is used by %d segment(s)", segments)}
+	}
+	return s.fields.Delete(ctx, id, orgID)
+}
+
+// ListValues returns a customer's value for each of the org's custom fields.
+func (s *CustomFieldService) ListValues(ctx context.Context, orgID, customerID uuid.UUID) ([]CustomerFieldValue, error) {
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+
+	fields, err := s.fields.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.fields.ListValuesByCustomer(ctx, customerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	byField := make(map[uuid.UUID]*repository.CustomFieldValue, len(values))
+	for _, v := range values {
+		byField[v.FieldID] = v
+	}
+
+	result := make([]CustomerFieldValue, len(fields))
+	for i, f := range fields {
+		result[i] = CustomerFieldValue{
+			FieldID:   f.ID,
+			Key:       f.Key,
+			Label:     f.Label,
+			FieldType: f.FieldType,
+			ReadOnly:  f.Source != nil,
+		}
+		if v := byField[f.ID]; v != nil {
+			result[i].Value = customFieldValueJSON(f, v)
+			result[i].Source = v.Source
+			result[i].UpdatedBy = v.UpdatedBy
+			result[i].UpdatedAt = &v.UpdatedAt
+		}
+	}
+	return result, nil
+}
+
+// SetValues sets or clears a customer's values for the given fields. Every
+// value is validated before any is saved.
+func (s *CustomFieldService) SetValues(ctx context.Context, orgID, customerID, userID uuid.UUID, req SetCustomerFieldValuesRequest) ([]CustomerFieldValue, error) {
+	if len(req.Values) == 0 {
+		return nil, &ValidationError{Field: "values", Message: "at least one value is required"}
+	}
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+
+	fields, err := s.fields.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*repository.CustomField, len(fields))
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+
+	keys := make([]string, 0, len(req.Values))
+	for key := range req.Values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	set := make([]*repository.CustomFieldValue, 0, len(keys))
+	var clear []uuid.UUID
+	for _, key := range keys {
+		field := byKey[key]
+		if field == nil {
+			return nil, &ValidationError{Field: "values." + key, Message: "unknown custom field"}
+		}
+		if field.Source != nil {
+			return nil, &ValidationError{Field: "values." + key, Message: fmt.Sprintf("value is synced from %s and cannot be edited", *field.Source)}
+		}
+		if req.Values[key] == nil {
+			clear = append(clear, field.ID)
+			continue
+		}
+		v, err := parseCustomFieldValue(field, req.Values[key])
+		if err != nil {
+			return nil, &ValidationError{Field: "values." + key, Message: err.Error()}
+		}
+		v.CustomerID, v.FieldID, v.Source, v.UpdatedBy = customerID, field.ID, "manual", &userID
+		set = append(set, v)
+	}
+
+	for _, v := range set {
+		if err := s.fields.SetValue(ctx, orgID, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, fieldID := range clear {
+		if err := s.fields.DeleteValue(ctx, orgID, customerID, fieldID); err != nil {
+			return nil, err
+		}
+	}
+	return s.ListValues(ctx, orgID, customerID)
+}
+
+// SyncOrg refills an org's source-mapped custom fields from the metadata
+// synced onto its customers.
+func (s *CustomFieldService) SyncOrg(ctx context.Context, orgID uuid.UUID) error {
+	fields, err := s.fields.ListByOrg(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.Source == nil || ctx.Err() != nil {
+			continue
+		}
+		if err := s.syncField(ctx, f); err != nil {
+			return fmt.Errorf("sync custom field %s: %w", f.Key, err)
+		}
+	}
+	return nil
+}
+
+// syncAfterChange fills a newly created or updated source-mapped field. A
+// failure is only logged; the next sync retries it.
+func (s *CustomFieldService) syncAfterChange(ctx context.Context, field *repository.CustomField) {
+	if field.Source == nil {
+		return
+	}
+	if err := s.syncField(ctx, field); err != nil {
+		slog.Error("custom field sync error", "field_id", field.ID, "error", err)
+	}
+}
+
+// syncField replaces a field's values with those found at its source. Source
+// values that are not valid for the field's type are skipped.
+func (s *CustomFieldService) syncField(ctx context.Context, field *repository.CustomField) error {
+	raw, err := s.fields.ListSourceValues(ctx, field.OrgID, customFieldSourcePaths(field))
+	if err != nil {
+		return err
+	}
+
+	values := make([]*repository.CustomFieldValue, 0, len(raw))
+	skipped := 0
+	for customerID, text := range raw {
+		v, err := parseCustomFieldValue(field, text)
+		if err != nil {
+			skipped++
+			continue
+		}
+		v.CustomerID = customerID
+		values = append(values, v)
+	}
+	if skipped > 0 {
+		slog.Warn("custom field sync skipped invalid values", "field_id", field.ID, "skipped", skipped)
+	}
+	return s.fields.ReplaceSynced(ctx, field.OrgID, field.ID, values)
+}
+
+// customFieldSourcePaths returns where in customer metadata a field's source
+// key is found. HubSpot contact properties take precedence over company ones.
+func customFieldSourcePaths(field *repository.CustomField) [][]string {
+	key := *field.SourceKey
+	switch *field.Source {
+	case "hubspot":
+		return [][]string{{"hubspot", key}, {"hubspot_company", key}}
+	case "intercom":
+		return [][]string{{"intercom", key}}
+	default:
+		return [][]string{{key}}
+	}
+}
+
+func (s *CustomFieldService) get(ctx context.Context, id, orgID uuid.UUID) (*repository.CustomField, error) {
+	field, err := s.fields.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if field == nil {
+		return nil, &NotFoundError{Resource: "custom_field", Message: "custom field not found"}
+	}
+	return field, nil
+}
+
+func (s *CustomFieldService) checkCustomer(ctx context.Context, customerID, orgID uuid.UUID) error {
+	customer, err := s.customers.GetByIDAndOrg(ctx, customerID, orgID)
+	if err != nil {
+		return fmt.Errorf("get customer: %w", err)
+	}
+	if customer == nil {
+		return &NotFoundError{Resource: "customer", Message: "customer not found"}
+	}
+	return nil
+}
+
+// validateCustomField checks a field's label, options, source and scoring,
+// normalizing its options and source.
+func validateCustomField(field *repository.CustomField) error {
+	if field.Label == "" {
+		return &ValidationError{Field: "label", Message: "label is required"}
+	}
+	if utf8.RuneCountInString(field.Label) > maxCustomFieldLabelLength {
+		return &ValidationError{Field: "label", Message: fmt.Sprintf("label must be at most %d characters", maxCustomFieldLabelLength)}
+	}
+
+	if field.FieldType == "enum" {
+		options, err := normalizeCustomFieldOptions(field.Options)
+		if err != nil {
+			return err
+		}
+		field.Options = options
+	} else if len(field.Options) > 0 {
+		return &ValidationError{Field: "options", Message: "only enum fields have options"}
+	} else {
+		field.Options = []string{}
+	}
+
+	if field.Source != nil || field.SourceKey != nil {
+		if field.Source == nil || !customFieldSources[*field.Source] {
+			return &ValidationError{Field: "source", Message: "source must be stripe, hubspot or intercom"}
+		}
+		if field.SourceKey == nil || strings.TrimSpace(*field.SourceKey) == "" {
+			return &ValidationError{Field: "source_key", Message: "source_key is required with a source"}
+		}
+		sourceKey := strings.TrimSpace(*field.SourceKey)
+		if utf8.RuneCountInString(sourceKey) > maxCustomFieldSourceLength {
+			return &ValidationError{Field: "source_key", Message: fmt.Sprintf("source_key must be at most %d characters", maxCustomFieldSourceLength)}
+		}
+		field.SourceKey = &sourceKey
+	}
+
+	return validateCustomFieldScoring(field)
+}
+
+func normalizeCustomFieldOptions(options []string) ([]string, error) {
+	if len(options) == 0 {
+		return nil, &ValidationError{Field: "options", Message: "enum fields need at least one option"}
+	}
+	if len(options) > maxCustomFieldOptions {
+		return nil, &ValidationError{Field: "options", Message: fmt.Sprintf("enum fields can have at most %d options", maxCustomFieldOptions)}
+	}
+
+	seen := make(map[string]bool, len(options))
+	result := make([]string, 0, len(options))
+	for _, o := range options {
+		o = strings.TrimSpace(o)
+		if o == "" {
+			return nil, &ValidationError{Field: "options", Message: "options must not be empty"}
+		}
+		if utf8.RuneCountInString(o) > maxCustomFieldLabelLength {
+			return nil, &ValidationError{Field: "options", Message: fmt.Sprintf("options must be at most %d characters", maxCustomFieldLabelLength)}
+		}
+		if seen[strings.ToLower(o)] {
+			return nil, &ValidationError{Field: "options", Message: fmt.Sprintf("option %q is listed twice", o)}
+		}
+		seen[strings.ToLower(o)] = true
+		result = append(result, o)
+	}
+	return result, nil
+}
+
+// validateCustomFieldScoring checks that a field's scoring fits its type.
+// Number fields scale between min and max; enum and boolean fields score
+// each value.
+func validateCustomFieldScoring(field *repository.CustomField) error {
+	sc := field.Scoring
+	if sc == nil {
+		return nil
+	}
+
+	switch field.FieldType {
+	case "number":
+		if sc.Min == nil || sc.Max == nil {
+			return &ValidationError{Field: "scoring", Message: "min and max are required to score a number field"}
+		}
+		if *sc.Min >= *sc.Max {
+			return &ValidationError{Field: "scoring", Message: "min must be less than max"}
+		}
+		if len(sc.OptionScores) > 0 {
+			return &ValidationError{Field: "scoring.option_scores", Message: "only enum and boolean fields have option scores"}
+		}
+	case "enum", "boolean":
+		if len(sc.OptionScores) == 0 {
+			return &ValidationError{Field: "scoring.option_scores", Message: "option_scores are required to score an enum or boolean field"}
+		}
+		if sc.Min != nil || sc.Max != nil || sc.Invert {
+			return &ValidationError{Field: "scoring", Message: "min, max and invert only apply to number fields"}
+		}
+		valid := field.Options
+		if field.FieldType == "boolean" {
+			valid = []string{"true", "false"}
+		}
+		for option, score := range sc.OptionScores {
+			if !slices.Contains(valid, option) {
+				return &ValidationError{Field: "scoring.option_scores", Message: fmt.Sprintf("%q is not a value of this field", option)}
+			}
+			if score < 0 || score > 1 {
+				return &ValidationError{Field: "scoring.option_scores", Message: "scores must be between 0 and 1"}
+			}
+		}
+	default:
+		return &ValidationError{Field: "scoring", Message: field.FieldType + " fields cannot be scored"}
+	}
+	return nil
+}
+
+// parseCustomFieldValue converts a JSON value, or text from a source, to a
+// field value of the field's type.
+func parseCustomFieldValue(field *repository.CustomField, raw any) (*repository.CustomFieldValue, error) {
+	switch field.FieldType {
+	case "number":
+		n, err := customFieldNumber(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &repository.CustomFieldValue{Text: strconv.FormatFloat(n, 'f', -1, 64), Number: &n}, nil
+
+	case "boolean":
+		b, err := customFieldBool(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &repository.CustomFieldValue{Text: strconv.FormatBool(b)}, nil
+
+	case "date":
+		str, _ := raw.(string)
+		d, err := customFieldDate(str)
+		if err != nil {
+			return nil, err
+		}
+		return &repository.CustomFieldValue{Text: d.Format(customFieldDateLayout), Date: &d}, nil
+
+	case "enum":
+		str, _ := raw.(string)
+		option, ok := customFieldOption(field, str)
+		if !ok {
+			return nil, fmt.Errorf("value must be one of: %s", strings.Join(field.Options, ", "))
+		}
+		return &repository.CustomFieldValue{Text: option}, nil
+
+	default:
+		str, ok := raw.(string)
+		str = strings.TrimSpace(str)
+		if !ok || str == "" {
+			return nil, errors.New("value must be a non-empty string")
+		}
+		if utf8.RuneCountInString(str) > maxCustomFieldTextLength {
+			return nil, fmt.Errorf("value must be at most %d characters", maxCustomFieldTextLength)
+		}
+		return &repository.CustomFieldValue{Text: str}, nil
+	}
+}
+
+func customFieldNumber(raw any) (float64, error) {
+	switch v := raw.(type) {
+	case float64:
+		return v, nil
+	case string:
+		if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+			return n, nil
+		}
+	}
+	return 0, errors.New("value must be a number")
+}
+
+func customFieldBool(raw any) (bool, error) {
+	switch v := raw.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b, nil
+		}
+	}
+	return false, errors.New("value must be true or false")
+}
+
+// customFieldDate parses a YYYY-MM-DD date, or the date part of an RFC 3339
+// timestamp as sources often send.
+func customFieldDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.Parse(customFieldDateLayout, s); err == nil {
+		return d, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, errors.New("value must be a YYYY-MM-DD date")
+}
+
+// customFieldOption returns the enum option matching s case-insensitively.
+func customFieldOption(field *repository.CustomField, s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	for _, o := range field.Options {
+		if strings.EqualFold(o, s) {
+			return o, true
+		}
+	}
+	return "", false
+}
+
+// customFieldValueJSON returns a stored value as its field type's JSON value.
+func customFieldValueJSON(field *repository.CustomField, v *repository.CustomFieldValue) any {
+	switch field.FieldType {
+	case "number":
+		if v.Number != nil {
+			return *v.Number
+		}
+	case "boolean":
+		return v.Text == "true"
+	}
+	return v.Text
+}
+
+// customFieldFilter builds the filter testing a field's value with op,
+// converting value to the field's type so it compares as one.
+func customFieldFilter(field *repository.CustomField, op string, value any) (repository.SegmentFilter, error) {
+	filter := repository.SegmentFilter{Field: "field." + field.Key, Op: op}
+	if !slices.Contains(customFieldOps[field.FieldType], op) {
+		return filter, fmt.Errorf("op must be one of: %s", strings.Join(customFieldOps[field.FieldType], ", "))
+	}
+
+	convert := func(raw any) (any, error) {
+		v, err := parseCustomFieldValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		switch field.FieldType {
+		case "number":
+			return *v.Number, nil
+		case "boolean":
+			return v.Text == "true", nil
+		}
+		return v.Text, nil
+	}
+
+	switch op {
+	case "exists", "not_exists":
+	case "in", "not_in":
+		list, ok := value.([]any)
+		if !ok || len(list) == 0 {
+			return filter, errors.New("value must be a non-empty list")
+		}
+		values := make([]any, len(list))
+		for i, item := range list {
+			v, err := parseCustomFieldValue(field, item)
+			if err != nil {
+				return filter, err
+			}
+			values[i] = v.Text
+		}
+		filter.Value = values
+	case "contains":
+		s, ok := value.(string)
+		if !ok || s == "" {
+			return filter, errors.New("value must be a non-empty string")
+		}
+		filter.Value = s
+	default:
+		v, err := convert(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Value = v
+	}
+
+	if err := filter.Validate(); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// customFieldOpLabels describe each op in alert messages.
+var customFieldOpLabels = map[string]string{
+	"eq":         "is",
+	"neq":        "is not",
+	"lt":         "is below",
+	"lte":        "is at most",
+	"gt":         "is above",
+	"gte":        "is at least",
+	"in":         "is one of",
+	"not_in":     "is not one of",
+	"contains":   "contains",
+	"exists":     "is set",
+	"not_exists": "is not set",
+}
+
+// customFieldConditionText describes a custom field condition, e.g. "is
+// below 10" or "is one of Retail, Media".
+func customFieldConditionText(op string, value any) string {
+	label, ok := customFieldOpLabels[op]
+	if !ok {
+		label = op
+	}
+	switch v := value.(type) {
+	case nil:
+		return label
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return label + " " + strings.Join(items, ", ")
+	default:
+		return fmt.Sprintf("%s %v", label, v)
+	}
+}
+
+// resolveCustomFieldFilters types the values of field.<key> filters by their
+// fields' types.
+func resolveCustomFieldFilters(fields []*repository.CustomField, filters []repository.SegmentFilter) ([]repository.SegmentFilter, error) {
+	byKey := make(map[string]*repository.CustomField, len(fields))
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+
+	result := make([]repository.SegmentFilter, len(filters))
+	for i, f := range filters {
+		field := byKey[strings.TrimPrefix(f.Field, "field.")]
+		if field == nil {
+			return nil, &ValidationError{Field: f.Field, Message: "unknown custom field"}
+		}
+		filter, err := customFieldFilter(field, f.Op, f.Value)
+		if err != nil {
+			return nil, &ValidationError{Field: f.Field, Message: err.Error()}
+		}
+		result[i] = filter
+	}
+	return result, nil
+}
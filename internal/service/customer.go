@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +19,9 @@ type CustomerService struct {
 	healthRepo   *repository.HealthScoreRepository
 	subRepo      *repository.StripeSubscriptionRepository
 	eventRepo    *repository.CustomerEventRepository
+	tagRepo      *repository.CustomerTagRepository
+	segmentRepo  *repository.SegmentRepository
+	fieldRepo    *repository.CustomFieldRepository
 }
 
 // NewCustomerService creates a new CustomerService.
@@ -25,12 +30,18 @@ func NewCustomerService(
 	hr *repository.HealthScoreRepository,
 	sr *repository.StripeSubscriptionRepository,
 	er *repository.CustomerEventRepository,
+	tr *repository.CustomerTagRepository,
+	segr *repository.SegmentRepository,
+	cfr *repository.CustomFieldRepository,
 ) *CustomerService {
 	return &CustomerService{
 		customerRepo: cr,
 		healthRepo:   hr,
 		subRepo:      sr,
 		eventRepo:    er,
+		tagRepo:      tr,
+		segmentRepo:  segr,
+		fieldRepo:    cfr,
 	}
 }
 
@@ -42,15 +53,17 @@ type CustomerListResponse struct {
 
 // CustomerListItem is a single customer in the list response.
 type CustomerListItem struct {
-	ID           uuid.UUID  `json:"id"`
-	Name         string     `json:"name"`
-	Email        string     `json:"email"`
-	CompanyName  string     `json:"company_name"`
-	MRRCents     int        `json:"mrr_cents"`
-	Source       string     `json:"source"`
-	LastSeenAt   *time.Time `json:"last_seen_at"`
-	OverallScore *int       `json:"overall_score"`
-	RiskLevel    *string    `json:"risk_level"`
+	ID           uuid.UUID   `json:"id"`
+	Name         string      `json:"name"`
+	Email        string      `json:"email"`
+	CompanyName  string      `json:"company_name"`
+	MRRCents     int         `json:"mrr_cents"`
+	Source       string      `json:"source"`
+	LastSeenAt   *time.Time  `json:"last_seen_at"`
+	OverallScore *int        `json:"overall_score"`
+	RiskLevel    *string     `json:"risk_level"`
+	OwnerIDs     []uuid.UUID `json:"owner_ids"`
+	Tags         []string    `json:"tags"`
 }
 
 // PaginationMeta holds pagination metadata for list responses.
@@ -86,6 +99,9 @@ func (s *CustomerService) List(ctx context.Context, params repository.CustomerLi
 	if params.Risk != "" && !validRisks[params.Risk] {
 		return nil, &ValidationError{Field: "risk", Message: "invalid risk level"}
 	}
+	if err := s.resolveListFilters(ctx, &params); err != nil {
+		return nil, err
+	}
 
 	result, err := s.customerRepo.ListWithScores(ctx, params)
 	if err != nil {
@@ -104,6 +120,8 @@ func (s *CustomerService) List(ctx context.Context, params repository.CustomerLi
 			LastSeenAt:   c.LastSeenAt,
 			OverallScore: c.OverallScore,
 			RiskLevel:    c.RiskLevel,
+			OwnerIDs:     c.OwnerIDs,
+			Tags:         c.Tags,
 		}
 	}
 
@@ -118,6 +136,84 @@ func (s *CustomerService) List(ctx context.Context, params repository.CustomerLi
 	}, nil
 }
 
+// customerExportBatch is how many customers an export reads at a time.
+const customerExportBatch = 500
+
+// Export calls fn for every customer matching the list filters, in list
+// order. Pagination params are ignored.
+func (s *CustomerService) Export(ctx context.Context, params repository.CustomerListParams, fn func(repository.CustomerWithScore) error) error {
+	validSorts := map[string]bool{"name": true, "mrr": true, "score": true, "last_seen": true}
+	if !validSorts[params.Sort] {
+		params.Sort = "name"
+	}
+	if params.Order != "asc" && params.Order != "desc" {
+		params.Order = "asc"
+	}
+	if err := s.resolveListFilters(ctx, &params); err != nil {
+		return err
+	}
+
+	params.PerPage = customerExportBatch
+	for params.Page = 1; ; params.Page++ {
+		result, err := s.customerRepo.ListWithScores(ctx, params)
+		if err != nil {
+			return fmt.Errorf("export customers: %w", err)
+		}
+		for _, c := range result.Customers {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		if params.Page >= result.TotalPages {
+			return nil
+		}
+	}
+}
+
+// resolveListFilters normalizes the tag filter and loads the saved segment
+// named by the list params.
+func (s *CustomerService) resolveListFilters(ctx context.Context, params *repository.CustomerListParams) error {
+	if len(params.Tags) > 0 {
+		tags, err := normalizeTags(params.Tags)
+		if err != nil {
+			return err
+		}
+		params.Tags = tags
+	}
+
+	if params.SegmentID != nil {
+		segment, err := s.segmentRepo.GetByID(ctx, *params.SegmentID, params.OrgID)
+		if err != nil {
+			return err
+		}
+		if segment == nil {
+			return &NotFoundError{Resource: "segment", Message: "segment not found"}
+		}
+		params.Segment = &segment.Filter
+	}
+
+	// Custom field filters and sorting are typed by the org's fields
+	sortKey, sortByField := strings.CutPrefix(params.Sort, "field.")
+	if len(params.Fields) == 0 && !sortByField {
+		return nil
+	}
+	fields, err := s.fieldRepo.ListByOrg(ctx, params.OrgID)
+	if err != nil {
+		return err
+	}
+	if params.Fields, err = resolveCustomFieldFilters(fields, params.Fields); err != nil {
+		return err
+	}
+	if sortByField {
+		i := slices.IndexFunc(fields, func(f *repository.CustomField) bool { return f.Key == sortKey })
+		if i < 0 {
+			return &ValidationError{Field: "sort", Message: "unknown custom field"}
+		}
+		params.Sort, params.SortFieldID = "field", &fields[i].ID
+	}
+	return nil
+}
+
 // CustomerDetail is the full detail response for a customer.
 type CustomerDetail struct {
 	Customer      CustomerInfo             `json:"customer"`
@@ -139,6 +235,7 @@ type CustomerInfo struct {
 	FirstSeenAt *time.Time     `json:"first_seen_at"`
 	LastSeenAt  *time.Time     `json:"last_seen_at"`
 	Metadata    map[string]any `json:"metadata"`
+	Tags        []string       `json:"tags"`
 	CreatedAt   time.Time      `json:"created_at"`
 }
 
@@ -185,6 +282,7 @@ func (s *CustomerService) GetDetail(ctx context.Context, customerID, orgID uuid.
 		healthScore   *repository.HealthScore
 		subscriptions []*repository.StripeSubscription
 		events        []*repository.CustomerEvent
+		tags          []string
 	)
 
 	g, gctx := errgroup.WithContext(ctx)
@@ -207,6 +305,12 @@ func (s *CustomerService) GetDetail(ctx context.Context, customerID, orgID uuid.
 		return err
 	})
 
+	g.Go(func() error {
+		var err error
+		tags, err = s.tagRepo.ListByCustomer(gctx, customerID, orgID)
+		return err
+	})
+
 	if err := g.Wait(); err != nil {
 		return nil, fmt.Errorf("load customer detail: %w", err)
 	}
@@ -224,6 +328,7 @@ func (s *CustomerService) GetDetail(ctx context.Context, customerID, orgID uuid.
 			FirstSeenAt: customer.FirstSeenAt,
 			LastSeenAt:  customer.LastSeenAt,
 			Metadata:    customer.Metadata,
+			Tags:        tags,
 			CreatedAt:   customer.CreatedAt,
 		},
 	}
@@ -4,33 +4,45 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
-// DeduplicationResult contains stats from a deduplication run.
-type DeduplicationResult struct {
-	Merged  int `json:"merged"`
-	Skipped int `json:"skipped"`
-	Errors  int `json:"errors"`
-}
+// Customer merge reasons.
+const (
+	MergeReasonDuplicateEmail = "duplicate_email"
+	MergeReasonIdentityMatch  = "identity_match"
+	MergeReasonManual         = "manual"
+)
 
-// CustomerMergeService handles merging HubSpot contacts with existing customers.
+// CustomerMergeService matches HubSpot contacts to existing customers and
+// merges duplicate customers, moving their records to a single primary.
+// Finding the duplicates is IdentityResolver's job.
 type CustomerMergeService struct {
+	pool      *pgxpool.Pool
 	customers *repository.CustomerRepository
 	contacts  *repository.HubSpotContactRepository
+	merges    *repository.CustomerMergeRepository
 }
 
 // NewCustomerMergeService creates a new CustomerMergeService.
 func NewCustomerMergeService(
+	pool *pgxpool.Pool,
 	customers *repository.CustomerRepository,
 	contacts *repository.HubSpotContactRepository,
+	merges *repository.CustomerMergeRepository,
 ) *CustomerMergeService {
 	return &CustomerMergeService{
+		pool:      pool,
 		customers: customers,
 		contacts:  contacts,
+		merges:    merges,
 	}
 }
 
@@ -150,76 +162,187 @@ func (s *CustomerMergeService) createFromHubSpot(ctx context.Context, orgID uuid
 	return customer, nil
 }
 
-// DeduplicateCustomers finds and merges duplicate customers across sources.
-func (s *CustomerMergeService) DeduplicateCustomers(ctx context.Context, orgID uuid.UUID) (*DeduplicationResult, error) {
-	duplicates, err := s.customers.FindDuplicatesByEmail(ctx, orgID)
+// Merge merges the duplicate customer into the primary on a user's request.
+func (s *CustomerMergeService) Merge(ctx context.Context, orgID, primaryID, duplicateID uuid.UUID, userID *uuid.UUID) (*repository.CustomerMerge, error) {
+	if primaryID == duplicateID {
+		return nil, &ValidationError{Field: "duplicate_id", Message: "a customer cannot be merged into itself"}
+	}
+
+	primary, err := s.customers.GetByIDAndOrg(ctx, primaryID, orgID)
 	if err != nil {
-		return nil, fmt.Errorf("find duplicates: %w", err)
+		return nil, fmt.Errorf("get primary customer: %w", err)
+	}
+	if primary == nil {
+		return nil, &NotFoundError{Resource: "customer", Message: "customer not found"}
 	}
 
-	result := &DeduplicationResult{}
+	duplicate, err := s.customers.GetByIDAndOrg(ctx, duplicateID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("get duplicate customer: %w", err)
+	}
+	if duplicate == nil {
+		return nil, &NotFoundError{Resource: "customer", Message: "duplicate customer not found"}
+	}
 
-	for _, group := range duplicates {
-		if len(group) < 2 {
-			continue
-		}
+	return s.merge(ctx, primary, duplicate, MergeReasonManual, userID)
+}
 
-		// Pick primary: oldest first_seen_at
-		primary := group[0]
-		for _, c := range group[1:] {
-			if c.FirstSeenAt != nil && (primary.FirstSeenAt == nil || c.FirstSeenAt.Before(*primary.FirstSeenAt)) {
-				primary = c
-			}
-		}
+// merge consolidates duplicate's fields into primary and moves all of
+// duplicate's records to it in one transaction.
+func (s *CustomerMergeService) merge(ctx context.Context, primary, duplicate *repository.Customer, reason string, userID *uuid.UUID) (*repository.CustomerMerge, error) {
+	return s.mergeWith(ctx, primary, duplicate, reason, userID, nil)
+}
 
-		// Merge metadata from others into primary
-		if primary.Metadata == nil {
-			primary.Metadata = map[string]any{}
+// mergeWith is merge with a hook that runs in the merge's transaction before
+// it commits. If the hook fails, the merge is rolled back.
+func (s *CustomerMergeService) mergeWith(
+	ctx context.Context,
+	primary, duplicate *repository.Customer,
+	reason string,
+	userID *uuid.UUID,
+	hook func(tx pgx.Tx, m *repository.CustomerMerge) error,
+) (*repository.CustomerMerge, error) {
+	// Consolidate into a copy so primary is only changed if the merge commits
+	merged := *primary
+	merged.Metadata = maps.Clone(primary.Metadata)
+	consolidateCustomer(&merged, duplicate)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	m := &repository.CustomerMerge{
+		OrgID:     primary.OrgID,
+		PrimaryID: primary.ID,
+		MergedID:  duplicate.ID,
+		Reason:    reason,
+		MergedBy:  userID,
+	}
+	if err := s.merges.Merge(ctx, tx, m, &merged); err != nil {
+		return nil, err
+	}
+	if hook != nil {
+		if err := hook(tx, m); err != nil {
+			return nil, err
 		}
+	}
 
-		for _, c := range group {
-			if c.ID == primary.ID {
-				continue
-			}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	*primary = merged
 
-			// Merge source-specific metadata
-			if c.Metadata != nil {
-				for k, v := range c.Metadata {
-					if k == "sources" {
-						continue
-					}
-					if _, exists := primary.Metadata[k]; !exists {
-						primary.Metadata[k] = v
-					}
-				}
-			}
+	slog.Info("customers merged", "org_id", m.OrgID, "primary_id", m.PrimaryID, "merged_id", m.MergedID, "reason", reason)
+	return m, nil
+}
 
-			// Name: use most recently updated if primary is empty
-			if primary.Name == "" && c.Name != "" {
-				primary.Name = c.Name
-			}
+// Unmerge undoes a merge, restoring the merged customer and its records.
+func (s *CustomerMergeService) Unmerge(ctx context.Context, orgID, mergeID uuid.UUID, userID *uuid.UUID) (*repository.CustomerMerge, error) {
+	m, err := s.merges.GetByID(ctx, orgID, mergeID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, &NotFoundError{Resource: "merge", Message: "merge not found"}
+	}
+	if m.UnmergedAt != nil {
+		return nil, &ConflictError{Resource: "merge", Message: "merge was already undone"}
+	}
 
-			// CompanyName: prefer HubSpot source
-			if c.Source == "hubspot" && c.CompanyName != "" {
-				primary.CompanyName = c.CompanyName
-			}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-			// MRR: prefer Stripe
-			if c.Source == "stripe" && c.MRRCents > 0 {
-				primary.MRRCents = c.MRRCents
-				primary.Currency = c.Currency
-			}
-		}
+	ok, err := s.merges.Unmerge(ctx, tx, m, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &ConflictError{Resource: "merge", Message: "merge was already undone"}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	slog.Info("customer merge undone", "org_id", orgID, "primary_id", m.PrimaryID, "merged_id", m.MergedID)
+	return s.merges.GetByID(ctx, orgID, mergeID)
+}
 
-		if err := s.customers.UpdateCompanyAndMetadata(ctx, primary.ID, primary.CompanyName, primary.Metadata); err != nil {
-			slog.Error("failed to update merged primary customer", "id", primary.ID, "error", err)
-			result.Errors++
+// ListMerges returns the merges a customer took part in, newest first.
+func (s *CustomerMergeService) ListMerges(ctx context.Context, orgID, customerID uuid.UUID) ([]*repository.CustomerMerge, error) {
+	merges, err := s.merges.ListByCustomer(ctx, orgID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if merges == nil {
+		merges = []*repository.CustomerMerge{}
+	}
+	return merges, nil
+}
+
+// consolidateCustomer folds duplicate's fields into primary.
+func consolidateCustomer(primary, duplicate *repository.Customer) {
+	if primary.Metadata == nil {
+		primary.Metadata = map[string]any{}
+	}
+
+	// Merge source-specific metadata
+	for k, v := range duplicate.Metadata {
+		if k == "sources" {
 			continue
 		}
+		if _, exists := primary.Metadata[k]; !exists {
+			primary.Metadata[k] = v
+		}
+	}
+	sources := customerSources(primary)
+	for _, src := range customerSources(duplicate) {
+		if !slices.Contains(sources, src) {
+			sources = append(sources, src)
+		}
+	}
+	primary.Metadata["sources"] = sources
+
+	// Name: use the duplicate's if primary is empty
+	if primary.Name == "" && duplicate.Name != "" {
+		primary.Name = duplicate.Name
+	}
 
-		result.Merged++
+	// CompanyName: prefer HubSpot source
+	if duplicate.Source == "hubspot" && duplicate.CompanyName != "" {
+		primary.CompanyName = duplicate.CompanyName
 	}
 
-	slog.Info("deduplication complete", "org_id", orgID, "merged", result.Merged, "skipped", result.Skipped, "errors", result.Errors)
-	return result, nil
+	// MRR: the duplicate's subscriptions move to the primary, so their MRR adds up
+	if duplicate.MRRCents > 0 {
+		if primary.MRRCents == 0 {
+			primary.Currency = duplicate.Currency
+		}
+		primary.MRRCents += duplicate.MRRCents
+	}
+}
+
+// customerSources returns the sources recorded in a customer's metadata,
+// falling back to the customer's own source.
+func customerSources(c *repository.Customer) []string {
+	var sources []string
+	switch v := c.Metadata["sources"].(type) {
+	case []any:
+		for _, src := range v {
+			if s, ok := src.(string); ok {
+				sources = append(sources, s)
+			}
+		}
+	case []string:
+		sources = append(sources, v...)
+	}
+	if c.Source != "" && !slices.Contains(sources, c.Source) {
+		sources = append(sources, c.Source)
+	}
+	return sources
 }
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const maxNoteBodyLength = 10000
+
+// CustomerNoteService handles notes that org members leave on customers.
+type CustomerNoteService struct {
+	notes     *repository.CustomerNoteRepository
+	customers *repository.CustomerRepository
+	orgRepo   *repository.OrganizationRepository
+	notifSvc  *NotificationService
+}
+
+// NewCustomerNoteService creates a new CustomerNoteService.
+func NewCustomerNoteService(
+	notes *repository.CustomerNoteRepository,
+	customers *repository.CustomerRepository,
+	orgRepo *repository.OrganizationRepository,
+	notifSvc *NotificationService,
+) *CustomerNoteService {
+	return &CustomerNoteService{
+		notes:     notes,
+		customers: customers,
+		orgRepo:   orgRepo,
+		notifSvc:  notifSvc,
+	}
+}
+
+// CreateNoteRequest holds input for creating a note.
+type CreateNoteRequest struct {
+	Body     string      `json:"body"`
+	Mentions []uuid.UUID `json:"mentions"`
+	Pinned   bool        `json:"pinned"`
+}
+
+// UpdateNoteRequest holds input for updating a note. Only the author may
+// change the body or mentions; any member may pin or unpin.
+type UpdateNoteRequest struct {
+	Body     *string      `json:"body"`
+	Mentions *[]uuid.UUID `json:"mentions"`
+	Pinned   *bool        `json:"pinned"`
+}
+
+// NoteListResponse is the JSON response for the note list.
+type NoteListResponse struct {
+	Notes      []*repository.CustomerNote `json:"notes"`
+	Pagination PaginationMeta             `json:"pagination"`
+}
+
+// List returns a customer's notes, pinned first.
+func (s *CustomerNoteService) List(ctx context.Context, params repository.NoteListParams) (*NoteListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PerPage < 1 {
+		params.PerPage = 25
+	}
+	if params.PerPage > 100 {
+		params.PerPage = 100
+	}
+
+	if _, err := s.getCustomer(ctx, params.CustomerID, params.OrgID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.notes.ListByCustomer(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list notes: %w", err)
+	}
+
+	notes := result.Notes
+	if notes == nil {
+		notes = []*repository.CustomerNote{}
+	}
+
+	return &NoteListResponse{
+		Notes: notes,
+		Pagination: PaginationMeta{
+			Page:       result.Page,
+			PerPage:    result.PerPage,
+			Total:      result.Total,
+			TotalPages: result.TotalPages,
+		},
+	}, nil
+}
+
+// Create adds a note to a customer and notifies the members it mentions.
+func (s *CustomerNoteService) Create(ctx context.Context, orgID, customerID, authorID uuid.UUID, req CreateNoteRequest) (*repository.CustomerNote, error) {
+	body, err := validateNoteBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := s.getCustomer(ctx, customerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.validateMentions(ctx, orgID, req.Mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &repository.CustomerNote{
+		OrgID:      orgID,
+		CustomerID: customerID,
+		AuthorID:   &authorID,
+		Body:       body,
+		Mentions:   mentions,
+		Pinned:     req.Pinned,
+	}
+	if err := s.notes.Create(ctx, note); err != nil {
+		return nil, fmt.Errorf("create note: %w", err)
+	}
+
+	created, err := s.notes.GetByID(ctx, note.ID, customerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, fmt.Errorf("created note not found")
+	}
+
+	s.notifyMentions(ctx, created, customer.Name, mentions, nil)
+	return created, nil
+}
+
+// Update edits, pins or unpins a note. Members newly mentioned by an edit are
+// notified; the replaced body is kept in the note's history.
+func (s *CustomerNoteService) Update(ctx context.Context, orgID, customerID, noteID, userID uuid.UUID, req UpdateNoteRequest) (*repository.CustomerNote, error) {
+	customer, err := s.getCustomer(ctx, customerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := s.notes.GetByID(ctx, noteID, customerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, &NotFoundError{Resource: "note", Message: "note not found"}
+	}
+
+	var newMentions []uuid.UUID
+	if req.Body != nil || req.Mentions != nil {
+		if note.AuthorID == nil || *note.AuthorID != userID {
+			return nil, &ForbiddenError{Message: "only the author can edit a note"}
+		}
+
+		body := note.Body
+		if req.Body != nil {
+			if body, err = validateNoteBody(*req.Body); err != nil {
+				return nil, err
+			}
+		}
+		mentions := note.Mentions
+		if req.Mentions != nil {
+			if mentions, err = s.validateMentions(ctx, orgID, *req.Mentions); err != nil {
+				return nil, err
+			}
+		}
+
+		if body != note.Body || !slices.Equal(mentions, note.Mentions) {
+			if err := s.notes.UpdateBody(ctx, noteID, orgID, body, mentions, userID); err != nil {
+				return nil, err
+			}
+			newMentions = mentions
+		}
+	}
+
+	if req.Pinned != nil && *req.Pinned != note.Pinned {
+		if err := s.notes.SetPinned(ctx, noteID, orgID, *req.Pinned); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.notes.GetByID(ctx, noteID, customerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, &NotFoundError{Resource: "note", Message: "note not found"}
+	}
+
+	s.notifyMentions(ctx, updated, customer.Name, newMentions, note.Mentions)
+	return updated, nil
+}
+
+// Delete removes a note. Authors may delete their own notes; admins and
+// owners may delete any note.
+func (s *CustomerNoteService) Delete(ctx context.Context, orgID, customerID, noteID, userID uuid.UUID) error {
+	note, err := s.notes.GetByID(ctx, noteID, customerID, orgID)
+	if err != nil {
+		return err
+	}
+	if note == nil {
+		return &NotFoundError{Resource: "note", Message: "note not found"}
+	}
+
+	if note.AuthorID == nil || *note.AuthorID != userID {
+		role, err := s.orgRepo.GetMemberRole(ctx, orgID, userID)
+		if err != nil {
+			return fmt.Errorf("get member role: %w", err)
+		}
+		if role != "admin" && role != "owner" {
+			return &ForbiddenError{Message: "only the author or an admin can delete a note"}
+		}
+	}
+
+	return s.notes.Delete(ctx, noteID, orgID)
+}
+
+// ListRevisions returns the edit history of a note, newest first.
+func (s *CustomerNoteService) ListRevisions(ctx context.Context, orgID, customerID, noteID uuid.UUID) ([]*repository.CustomerNoteRevision, error) {
+	note, err := s.notes.GetByID(ctx, noteID, customerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, &NotFoundError{Resource: "note", Message: "note not found"}
+	}
+
+	revisions, err := s.notes.ListRevisions(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []*repository.CustomerNoteRevision{}
+	}
+	return revisions, nil
+}
+
+func (s *CustomerNoteService) getCustomer(ctx context.Context, customerID, orgID uuid.UUID) (*repository.Customer, error) {
+	customer, err := s.customers.GetByIDAndOrg(ctx, customerID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("get customer: %w", err)
+	}
+	if customer == nil {
+		return nil, &NotFoundError{Resource: "customer", Message: "customer not found"}
+	}
+	return customer, nil
+}
+
+// validateMentions de-duplicates mentioned user IDs and checks that each is
+// a member of the org.
+func (s *CustomerNoteService) validateMentions(ctx context.Context, orgID uuid.UUID, mentions []uuid.UUID) ([]uuid.UUID, error) {
+	if len(mentions) == 0 {
+		return []uuid.UUID{}, nil
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	isMember := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+
+	var result []uuid.UUID
+	for _, id := range mentions {
+		if !isMember[id] {
+			return nil, &ValidationError{Field: "mentions", Message: "mentioned user is not a member of this organization"}
+		}
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// notifyMentions notifies the mentioned members who were not already
+// mentioned, other than the note's author.
+func (s *CustomerNoteService) notifyMentions(ctx context.Context, note *repository.CustomerNote, customerName string, mentions, previous []uuid.UUID) {
+	if s.notifSvc == nil {
+		return
+	}
+
+	var recipients []uuid.UUID
+	for _, id := range mentions {
+		if slices.Contains(previous, id) || (note.AuthorID != nil && *note.AuthorID == id) {
+			continue
+		}
+		recipients = append(recipients, id)
+	}
+	if len(recipients) > 0 {
+		s.notifSvc.CreateForMention(ctx, note, customerName, recipients)
+	}
+}
+
+func validateNoteBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", &ValidationError{Field: "body", Message: "body is required"}
+	}
+	if utf8.RuneCountInString(body) > maxNoteBodyLength {
+		return "", &ValidationError{Field: "body", Message: fmt.Sprintf("body must be at most %d characters", maxNoteBodyLength)}
+	}
+	return body, nil
+}
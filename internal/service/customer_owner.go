@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// CustomerOwnerService assigns the org members who own customers, by hand,
+// in bulk by segment, or automatically through assignment rules.
+type CustomerOwnerService struct {
+	owners    *repository.CustomerOwnerRepository
+	rules     *repository.CustomerAssignmentRuleRepository
+	customers *repository.CustomerRepository
+	orgRepo   *repository.OrganizationRepository
+}
+
+// NewCustomerOwnerService creates a new CustomerOwnerService.
+func NewCustomerOwnerService(
+	owners *repository.CustomerOwnerRepository,
+	rules *repository.CustomerAssignmentRuleRepository,
+	customers *repository.CustomerRepository,
+	orgRepo *repository.OrganizationRepository,
+) *CustomerOwnerService {
+	return &CustomerOwnerService{
+		owners:    owners,
+		rules:     rules,
+		customers: customers,
+		orgRepo:   orgRepo,
+	}
+}
+
+// SetOwnersRequest holds the complete set of owners for a customer.
+type SetOwnersRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+// BulkAssignOwnersRequest assigns owners to every customer in a segment.
+// Mode "add" (the default) keeps existing owners; "replace" removes them.
+type BulkAssignOwnersRequest struct {
+	UserIDs []uuid.UUID                `json:"user_ids"`
+	Segment repository.CustomerSegment `json:"segment"`
+	Mode    string                     `json:"mode"`
+}
+
+// BulkAssignOwnersResult reports how many customers a bulk assignment matched.
+type BulkAssignOwnersResult struct {
+	Matched int `json:"matched"`
+}
+
+// AutoAssignResult reports how many customers the assignment rules assigned.
+type AutoAssignResult struct {
+	Assigned int `json:"assigned"`
+}
+
+// BookOfBusiness summarizes the customers each member owns.
+type BookOfBusiness struct {
+	Owners  []*repository.OwnerBookSummary `json:"owners"`
+	Unowned *repository.UnownedBookSummary `json:"unowned"`
+}
+
+// CreateAssignmentRuleRequest holds input for creating an assignment rule.
+type CreateAssignmentRuleRequest struct {
+	Name       string                     `json:"name"`
+	Priority   int                        `json:"priority"`
+	Conditions repository.CustomerSegment `json:"conditions"`
+	Strategy   string                     `json:"strategy"`
+	UserIDs    []uuid.UUID                `json:"user_ids"`
+	IsActive   *bool                      `json:"is_active"`
+}
+
+// UpdateAssignmentRuleRequest holds input for updating an assignment rule.
+type UpdateAssignmentRuleRequest struct {
+	Name       *string                     `json:"name"`
+	Priority   *int                        `json:"priority"`
+	Conditions *repository.CustomerSegment `json:"conditions"`
+	Strategy   *string                     `json:"strategy"`
+	UserIDs    *[]uuid.UUID                `json:"user_ids"`
+	IsActive   *bool                       `json:"is_active"`
+}
+
+// ListOwners returns the owners of a customer.
+func (s *CustomerOwnerService) ListOwners(ctx context.Context, orgID, customerID uuid.UUID) ([]*repository.CustomerOwner, error) {
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+
+	owners, err := s.owners.ListByCustomer(ctx, customerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if owners == nil {
+		owners = []*repository.CustomerOwner{}
+	}
+	return owners, nil
+}
+
+// SetOwners replaces the owners of a customer.
+func (s *CustomerOwnerService) SetOwners(ctx context.Context, orgID, customerID, userID uuid.UUID, req SetOwnersRequest) ([]*repository.CustomerOwner, error) {
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+
+	userIDs, err := s.validateMembers(ctx, orgID, req.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.owners.SetOwners(ctx, orgID, customerID, userIDs, userID); err != nil {
+		return nil, err
+	}
+	return s.ListOwners(ctx, orgID, customerID)
+}
+
+// BulkAssign assigns owners to every customer in a segment.
+func (s *CustomerOwnerService) BulkAssign(ctx context.Context, orgID, userID uuid.UUID, req BulkAssignOwnersRequest) (*BulkAssignOwnersResult, error) {
+	if len(req.UserIDs) == 0 {
+		return nil, &ValidationError{Field: "user_ids", Message: "at least one owner is required"}
+	}
+	if req.Mode == "" {
+		req.Mode = "add"
+	}
+	if req.Mode != "add" && req.Mode != "replace" {
+		return nil, &ValidationError{Field: "mode", Message: "mode must be add or replace"}
+	}
+
+	segment, err := validateSegment(req.Segment, "segment")
+	if err != nil {
+		return nil, err
+	}
+	userIDs, err := s.validateMembers(ctx, orgID, req.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := s.owners.AssignSegment(ctx, orgID, segment, userIDs, userID, req.Mode == "replace")
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("customer owners bulk assigned", "org_id", orgID, "matched", matched, "mode", req.Mode)
+	return &BulkAssignOwnersResult{Matched: matched}, nil
+}
+
+// BookOfBusiness summarizes each member's owned customers and the unowned ones.
+func (s *CustomerOwnerService) BookOfBusiness(ctx context.Context, orgID uuid.UUID) (*BookOfBusiness, error) {
+	owners, err := s.owners.SummarizeByOwner(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	unowned, err := s.owners.SummarizeUnowned(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if owners == nil {
+		owners = []*repository.OwnerBookSummary{}
+	}
+	return &BookOfBusiness{Owners: owners, Unowned: unowned}, nil
+}
+
+// AssignUnowned runs an org's active assignment rules, in priority order,
+// over its customers without an owner. A customer is assigned by the first
+// rule that matches it. Rule users who have left the org are skipped.
+func (s *CustomerOwnerService) AssignUnowned(ctx context.Context, orgID uuid.UUID) (*AutoAssignResult, error) {
+	rules, err := s.rules.List(ctx, orgID, true)
+	if err != nil {
+		return nil, err
+	}
+	result := &AutoAssignResult{}
+	if len(rules) == 0 {
+		return result, nil
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	isMember := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+
+	for _, rule := range rules {
+		var users []uuid.UUID
+		for _, id := range rule.UserIDs {
+			if isMember[id] {
+				users = append(users, id)
+			}
+		}
+		if len(users) == 0 {
+			continue
+		}
+
+		customerIDs, err := s.owners.ListUnownedInSegment(ctx, orgID, rule.Conditions)
+		if err != nil {
+			return nil, err
+		}
+		if len(customerIDs) == 0 {
+			continue
+		}
+
+		var assignCustomers, assignUsers []uuid.UUID
+		next := rule.NextIndex
+		for _, customerID := range customerIDs {
+			if rule.Strategy == repository.AssignmentStrategyAll {
+				for _, u := range users {
+					assignCustomers = append(assignCustomers, customerID)
+					assignUsers = append(assignUsers, u)
+				}
+				continue
+			}
+			assignCustomers = append(assignCustomers, customerID)
+			assignUsers = append(assignUsers, users[next%len(users)])
+			next++
+		}
+
+		if err := s.owners.AssignByRule(ctx, orgID, rule.ID, assignCustomers, assignUsers); err != nil {
+			return nil, err
+		}
+		if rule.Strategy == repository.AssignmentStrategyRoundRobin {
+			if err := s.rules.SetNextIndex(ctx, rule.ID, next%len(users)); err != nil {
+				return nil, err
+			}
+		}
+		result.Assigned += len(customerIDs)
+	}
+
+	if result.Assigned > 0 {
+		slog.Info("customer owners auto-assigned", "org_id", orgID, "assigned", result.Assigned)
+	}
+	return result, nil
+}
+
+// ListRules returns an org's assignment rules in the order they are applied.
+func (s *CustomerOwnerService) ListRules(ctx context.Context, orgID uuid.UUID) ([]*repository.CustomerAssignmentRule, error) {
+	rules, err := s.rules.List(ctx, orgID, false)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []*repository.CustomerAssignmentRule{}
+	}
+	return rules, nil
+}
+
+// CreateRule creates an assignment rule.
+func (s *CustomerOwnerService) CreateRule(ctx context.Context, orgID, userID uuid.UUID, req CreateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error) {
+	rule := &repository.CustomerAssignmentRule{
+		OrgID:      orgID,
+		Name:       strings.TrimSpace(req.Name),
+		Priority:   req.Priority,
+		Conditions: req.Conditions,
+		Strategy:   req.Strategy,
+		UserIDs:    req.UserIDs,
+		IsActive:   true,
+		CreatedBy:  &userID,
+	}
+	if rule.Strategy == "" {
+		rule.Strategy = repository.AssignmentStrategyRoundRobin
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	if err := s.rules.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("create assignment rule: %w", err)
+	}
+	return rule, nil
+}
+
+// UpdateRule applies partial updates to an assignment rule.
+func (s *CustomerOwnerService) UpdateRule(ctx context.Context, id, orgID uuid.UUID, req UpdateAssignmentRuleRequest) (*repository.CustomerAssignmentRule, error) {
+	rule, err := s.rules.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, &NotFoundError{Resource: "assignment_rule", Message: "assignment rule not found"}
+	}
+
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.Conditions != nil {
+		rule.Conditions = *req.Conditions
+	}
+	if req.Strategy != nil {
+		rule.Strategy = *req.Strategy
+	}
+	if req.UserIDs != nil {
+		rule.UserIDs = *req.UserIDs
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	if err := s.rules.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule deletes an assignment rule.
+func (s *CustomerOwnerService) DeleteRule(ctx context.Context, id, orgID uuid.UUID) error {
+	rule, err := s.rules.GetByID(ctx, id, orgID)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return &NotFoundError{Resource: "assignment_rule", Message: "assignment rule not found"}
+	}
+	return s.rules.Delete(ctx, id, orgID)
+}
+
+func (s *CustomerOwnerService) validateRule(ctx context.Context, rule *repository.CustomerAssignmentRule) error {
+	if rule.Name == "" {
+		return &ValidationError{Field: "name", Message: "name is required"}
+	}
+	if len(rule.Name) > 255 {
+		return &ValidationError{Field: "name", Message: "name must be 255 characters or less"}
+	}
+	if rule.Strategy != repository.AssignmentStrategyRoundRobin && rule.Strategy != repository.AssignmentStrategyAll {
+		return &ValidationError{Field: "strategy", Message: "strategy must be round_robin or all"}
+	}
+	if len(rule.Conditions.CustomerIDs) > 0 {
+		return &ValidationError{Field: "conditions", Message: "rules cannot target specific customers"}
+	}
+
+	conditions, err := validateSegment(rule.Conditions, "conditions")
+	if err != nil {
+		return err
+	}
+	rule.Conditions = conditions
+
+	if len(rule.UserIDs) == 0 {
+		return &ValidationError{Field: "user_ids", Message: "at least one user is required"}
+	}
+	rule.UserIDs, err = s.validateMembers(ctx, rule.OrgID, rule.UserIDs)
+	return err
+}
+
+func (s *CustomerOwnerService) checkCustomer(ctx context.Context, customerID, orgID uuid.UUID) error {
+	customer, err := s.customers.GetByIDAndOrg(ctx, customerID, orgID)
+	if err != nil {
+		return fmt.Errorf("get customer: %w", err)
+	}
+	if customer == nil {
+		return &NotFoundError{Resource: "customer", Message: "customer not found"}
+	}
+	return nil
+}
+
+// validateMembers de-duplicates user IDs and checks that each is a member of
+// the org.
+func (s *CustomerOwnerService) validateMembers(ctx context.Context, orgID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	result := []uuid.UUID{}
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	isMember := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+
+	for _, id := range userIDs {
+		if !isMember[id] {
+			return nil, &ValidationError{Field: "user_ids", Message: "user is not a member of this organization"}
+		}
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// validateSegment normalizes a segment and checks its filters.
+func validateSegment(segment repository.CustomerSegment, field string) (repository.CustomerSegment, error) {
+	validRisks := map[string]bool{"green": true, "yellow": true, "red": true}
+	if segment.Risk != "" && !validRisks[segment.Risk] {
+		return segment, &ValidationError{Field: field, Message: "invalid risk level"}
+	}
+	if segment.MinMRRCents != nil && segment.MaxMRRCents != nil && *segment.MinMRRCents > *segment.MaxMRRCents {
+		return segment, &ValidationError{Field: field, Message: "min_mrr_cents must not exceed max_mrr_cents"}
+	}
+	segment.Search = strings.TrimSpace(segment.Search)
+	segment.EmailDomain = normalizeDomain(strings.TrimPrefix(strings.TrimSpace(segment.EmailDomain), "@"))
+	return segment, nil
+}
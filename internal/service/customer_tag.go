@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	maxTagLength      = 50
+	maxTagsPerRequest = 50
+)
+
+// CustomerTagService manages the free-form tags on customers.
+type CustomerTagService struct {
+	tags      *repository.CustomerTagRepository
+	customers *repository.CustomerRepository
+}
+
+// NewCustomerTagService creates a new CustomerTagService.
+func NewCustomerTagService(tags *repository.CustomerTagRepository, customers *repository.CustomerRepository) *CustomerTagService {
+	return &CustomerTagService{tags: tags, customers: customers}
+}
+
+// CustomerTagsRequest holds tags to add to or set on a customer.
+type CustomerTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// ListOrgTags returns the tags in use in an org with their customer counts.
+func (s *CustomerTagService) ListOrgTags(ctx context.Context, orgID uuid.UUID) ([]repository.TagCount, error) {
+	return s.tags.ListByOrg(ctx, orgID)
+}
+
+// ListForCustomer returns a customer's tags.
+func (s *CustomerTagService) ListForCustomer(ctx context.Context, orgID, customerID uuid.UUID) ([]string, error) {
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+	return s.tags.ListByCustomer(ctx, customerID, orgID)
+}
+
+// AddTags adds tags to a customer, keeping the ones it already has.
+func (s *CustomerTagService) AddTags(ctx context.Context, orgID, customerID, userID uuid.UUID, req CustomerTagsRequest) ([]string, error) {
+	if len(req.Tags) == 0 {
+		return nil, &ValidationError{Field: "tags", Message: "at least one tag is required"}
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+
+	if err := s.tags.Add(ctx, orgID, customerID, tags, userID); err != nil {
+		return nil, err
+	}
+	return s.tags.ListByCustomer(ctx, customerID, orgID)
+}
+
+// SetTags replaces a customer's tags. An empty list removes them all.
+func (s *CustomerTagService) SetTags(ctx context.Context, orgID, customerID, userID uuid.UUID, req CustomerTagsRequest) ([]string, error) {
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+
+	if err := s.tags.Set(ctx, orgID, customerID, tags, userID); err != nil {
+		return nil, err
+	}
+	return s.tags.ListByCustomer(ctx, customerID, orgID)
+}
+
+// RemoveTag removes a tag from a customer. Removing a tag the customer does
+// not have is not an error.
+func (s *CustomerTagService) RemoveTag(ctx context.Context, orgID, customerID uuid.UUID, tag string) ([]string, error) {
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+
+	if err := s.tags.Remove(ctx, orgID, customerID, strings.ToLower(strings.TrimSpace(tag))); err != nil {
+		return nil, err
+	}
+	return s.tags.ListByCustomer(ctx, customerID, orgID)
+}
+
+func (s *CustomerTagService) checkCustomer(ctx context.Context, customerID, orgID uuid.UUID) error {
+	customer, err := s.customers.GetByIDAndOrg(ctx, customerID, orgID)
+	if err != nil {
+		return fmt.Errorf("get customer: %w", err)
+	}
+	if customer == nil {
+		return &NotFoundError{Resource: "customer", Message: "customer not found"}
+	}
+	return nil
+}
+
+// normalizeTags trims and lowercases tags and drops duplicates, so "VIP" and
+// " vip" are the same tag.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxTagsPerRequest {
+		return nil, &ValidationError{Field: "tags", Message: fmt.Sprintf("at most %d tags are allowed", maxTagsPerRequest)}
+	}
+
+	result := []string{}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, &ValidationError{Field: "tags", Message: "tags must not be empty"}
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, &ValidationError{Field: "tags", Message: fmt.Sprintf("tags must be at most %d characters", maxTagLength)}
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
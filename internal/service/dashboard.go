@@ -16,16 +16,19 @@ import (
 type DashboardService struct {
 	customerRepo    *repository.CustomerRepository
 	healthScoreRepo *repository.HealthScoreRepository
+	segmentRepo     *repository.SegmentRepository
 }
 
 // NewDashboardService creates a new DashboardService.
 func NewDashboardService(
 	cr *repository.CustomerRepository,
 	hsr *repository.HealthScoreRepository,
+	segr *repository.SegmentRepository,
 ) *DashboardService {
 	return &DashboardService{
 		customerRepo:    cr,
 		healthScoreRepo: hsr,
+		segmentRepo:     segr,
 	}
 }
 
@@ -132,6 +135,35 @@ func (s *DashboardService) GetSummary(ctx context.Context, orgID uuid.UUID) (*Da
 	}, nil
 }
 
+// GetSegmentSummary returns dashboard summary stats for the customers in a
+// saved segment. The 7-day changes compare the segment's current members with
+// their own scores a week ago.
+func (s *DashboardService) GetSegmentSummary(ctx context.Context, orgID, segmentID uuid.UUID) (*DashboardSummary, error) {
+	segment, err := s.segmentRepo.GetByID(ctx, segmentID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if segment == nil {
+		return nil, &NotFoundError{Resource: "segment", Message: "segment not found"}
+	}
+
+	sum, err := s.segmentRepo.Summarize(ctx, orgID, segment.Filter, time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		return nil, fmt.Errorf("dashboard segment summary: %w", err)
+	}
+
+	return &DashboardSummary{
+		TotalCustomers:    sum.TotalCustomers,
+		RiskDistribution:  RiskDist{Green: sum.Green, Yellow: sum.Yellow, Red: sum.Red},
+		TotalMRRCents:     sum.TotalMRRCents,
+		MRRChange30DCents: 0, // MRR historical tracking not yet available
+		AtRiskCount:       sum.Red,
+		AtRiskChange7D:    sum.Red - sum.AtRiskAt,
+		AvgHealthScore:    math.Round(sum.AvgScore*100) / 100,
+		ScoreChange7D:     math.Round((sum.AvgScore-sum.AvgScoreAt)*100) / 100,
+	}, nil
+}
+
 // ScoreDistributionResponse is the response for the score distribution endpoint.
 type ScoreDistributionResponse struct {
 	Buckets       []repository.ScoreBucket `json:"buckets"`
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	digestTopDecliners    = 5
+	digestRiskTransitions = 10
+	// digestClaimStaleAfter is how long a digest may stay pending before another
+	// run claims it again; it covers a worker crashing between claim and send.
+	digestClaimStaleAfter = 30 * time.Minute
+)
+
+type digestPreferenceLister interface {
+	ListDigestEnabled(ctx context.Context) ([]*repository.NotificationPreference, error)
+}
+
+type digestHistoryStore interface {
+	Claim(ctx context.Context, h *repository.DigestHistory, staleAfter time.Duration) (bool, error)
+	MarkSent(ctx context.Context, id uuid.UUID, msgID string) error
+	MarkFailed(ctx context.Context, id uuid.UUID, errorMsg string) error
+}
+
+type userReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*repository.User, error)
+}
+
+type orgReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*repository.Organization, error)
+}
+
+type digestCustomerReader interface {
+	CountByOrg(ctx context.Context, orgID uuid.UUID) (int, error)
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*repository.Customer, error)
+}
+
+type digestScoreReader interface {
+	CountByRiskLevel(ctx context.Context, orgID uuid.UUID) (map[string]int, error)
+	ListScoreChanges(ctx context.Context, orgID uuid.UUID, from, to time.Time) ([]repository.ScoreChange, error)
+}
+
+type alertRuleCounter interface {
+	CountByRuleInRange(ctx context.Context, orgID uuid.UUID, from, to time.Time) ([]repository.AlertRuleCount, error)
+}
+
+type eventRangeLister interface {
+	ListByOrgTypeAndRange(ctx context.Context, orgID uuid.UUID, eventType string, from, to time.Time) ([]*repository.CustomerEvent, error)
+}
+
+// DigestService sends daily and weekly digest emails to users who opted in.
+type DigestService struct {
+	prefs        digestPreferenceLister
+	history      digestHistoryStore
+	users        userReader
+	orgs         orgReader
+	customers    digestCustomerReader
+	healthScores digestScoreReader
+	alertHistory alertRuleCounter
+	events       eventRangeLister
+	emailService EmailService
+	templates    *EmailTemplateService
+	interval     time.Duration
+	sendHour     int
+	frontendURL  string
+}
+
+// DigestServiceDeps holds constructor dependencies for DigestService.
+type DigestServiceDeps struct {
+	Prefs        *repository.NotificationPreferenceRepository
+	History      *repository.DigestHistoryRepository
+	Users        *repository.UserRepository
+	Orgs         *repository.OrganizationRepository
+	Customers    *repository.CustomerRepository
+	HealthScores *repository.HealthScoreRepository
+	AlertHistory *repository.AlertHistoryRepository
+	Events       *repository.CustomerEventRepository
+	EmailService EmailService
+	Templates    *EmailTemplateService
+}
+
+// NewDigestService creates a new DigestService. Digests go out at sendHour in each
+// user's local timezone; intervalMinutes controls how often due digests are checked.
+func NewDigestService(deps DigestServiceDeps, intervalMinutes, sendHour int, frontendURL string) *DigestService {
+	if sendHour < 0 || sendHour > 23 {
+		sendHour = 8
+	}
+	return &DigestService{
+		prefs:        deps.Prefs,
+		history:      deps.History,
+		users:        deps.Users,
+		orgs:         deps.Orgs,
+		customers:    deps.Customers,
+		healthScores: deps.HealthScores,
+		alertHistory: deps.AlertHistory,
+		events:       deps.Events,
+		emailService: deps.EmailService,
+		templates:    deps.Templates,
+		interval:     time.Duration(intervalMinutes) * time.Minute,
+		sendHour:     sendHour,
+		frontendURL:  frontendURL,
+	}
+}
+
+// Start begins the periodic digest loop. Cancel the context to stop.
+func (s *DigestService) Start(ctx context.Context) {
+	slog.Info("digest scheduler started", "interval", s.interval, "send_hour", s.sendHour)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("digest scheduler stopped")
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx, time.Now())
+		}
+	}
+}
+
+// digestKey identifies a shared org digest so it is built once per run.
+type digestKey struct {
+	orgID     uuid.UUID
+	frequency string
+	start     time.Time
+	end       time.Time
+}
+
+// RunOnce sends every digest that is due at now and has not been sent yet.
+func (s *DigestService) RunOnce(ctx context.Context, now time.Time) {
+	prefs, err := s.prefs.ListDigestEnabled(ctx)
+	if err != nil {
+		slog.Error("digest scheduler: list preferences", "error", err)
+		return
+	}
+
+	built := make(map[digestKey]*DigestEmailData)
+	var sent int
+	for _, pref := range prefs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		start, end := digestPeriod(now, pref.DigestFrequency, loadLocation(pref.Timezone), s.sendHour)
+		entry := &repository.DigestHistory{
+			UserID:      pref.UserID,
+			OrgID:       pref.OrgID,
+			Frequency:   pref.DigestFrequency,
+			PeriodStart: start,
+			PeriodEnd:   end,
+		}
+		claimed, err := s.history.Claim(ctx, entry, digestClaimStaleAfter)
+		if err != nil {
+			slog.Error("digest scheduler: claim digest", "user_id", pref.UserID, "org_id", pref.OrgID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		key := digestKey{orgID: pref.OrgID, frequency: pref.DigestFrequency, start: start, end: end}
+		data, ok := built[key]
+		if !ok {
+			data, err = s.buildDigest(ctx, pref.OrgID, pref.DigestFrequency, start, end)
+			if err != nil {
+				slog.Error("digest scheduler: build digest", "org_id", pref.OrgID, "error", err)
+				_ = s.history.MarkFailed(ctx, entry.ID, err.Error())
+				continue
+			}
+			built[key] = data
+		}
+
+		if err := s.send(ctx, entry, data); err != nil {
+			slog.Error("digest scheduler: send digest", "user_id", pref.UserID, "org_id", pref.OrgID, "error", err)
+			_ = s.history.MarkFailed(ctx, entry.ID, err.Error())
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		slog.Info("digest scheduler: run complete", "sent", sent)
+	}
+}
+
+func (s *DigestService) send(ctx context.Context, entry *repository.DigestHistory, data *DigestEmailData) error {
+	user, err := s.users.GetByID(ctx, entry.UserID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user %s not found", entry.UserID)
+	}
+
+	html, text, err := s.templates.RenderDigest(*data)
+	if err != nil {
+		return fmt.Errorf("render digest: %w", err)
+	}
+
+	msgID, err := s.emailService.SendEmail(ctx, SendEmailParams{
+		To:       user.Email,
+		Subject:  fmt.Sprintf("Your %s PulseScore digest for %s", data.Frequency, data.OrgName),
+		HTMLBody: html,
+		TextBody: text,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.history.MarkSent(ctx, entry.ID, msgID)
+}
+
+// buildDigest aggregates an org's activity between start and end.
+func (s *DigestService) buildDigest(ctx context.Context, orgID uuid.UUID, frequency string, start, end time.Time) (*DigestEmailData, error) {
+	org, err := s.orgs.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("get org: %w", err)
+	}
+	if org == nil {
+		return nil, fmt.Errorf("org %s not found", orgID)
+	}
+
+	totalCustomers, err := s.customers.CountByOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	riskCounts, err := s.healthScores.CountByRiskLevel(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &DigestEmailData{
+		OrgName:        org.Name,
+		Frequency:      frequency,
+		PeriodLabel:    "Weekly",
+		PeriodStart:    start.Format("Jan 2"),
+		PeriodEnd:      end.Add(-time.Second).Format("Jan 2"),
+		TotalCustomers: totalCustomers,
+		AtRiskCount:    riskCounts["red"],
+		DashboardURL:   fmt.Sprintf("%s/dashboard", s.frontendURL),
+		UnsubscribeURL: fmt.Sprintf("%s/settings?tab=notifications", s.frontendURL),
+	}
+	if frequency == "daily" {
+		data.PeriodLabel = "Daily"
+	}
+
+	// Alert matches
+	data.AlertRules, err = s.alertHistory.CountByRuleInRange(ctx, orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range data.AlertRules {
+		data.AlertsFired += r.Count
+	}
+
+	// Score movements and top decliners
+	changes, err := s.healthScores.ListScoreChanges(ctx, orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	var decliners []CustomerScoreChange
+	for _, c := range changes {
+		delta := c.NewScore - c.OldScore
+		if delta > 0 {
+			data.ImprovedCount++
+			continue
+		}
+		data.DeclinedCount++
+		decliners = append(decliners, CustomerScoreChange{
+			Name:     c.CustomerName,
+			OldScore: c.OldScore,
+			NewScore: c.NewScore,
+			Delta:    delta,
+		})
+	}
+	sort.Slice(decliners, func(i, j int) bool { return decliners[i].Delta < decliners[j].Delta })
+	if len(decliners) > digestTopDecliners {
+		decliners = decliners[:digestTopDecliners]
+	}
+	data.TopDecliners = decliners
+
+	customers, err := s.customers.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uuid.UUID]string, len(customers))
+	for _, c := range customers {
+		names[c.ID] = c.Name
+	}
+
+	// Risk transitions (most recent first)
+	riskEvents, err := s.events.ListByOrgTypeAndRange(ctx, orgID, "risk_level.changed", start, end)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(riskEvents) - 1; i >= 0 && len(data.RiskTransitions) < digestRiskTransitions; i-- {
+		event := riskEvents[i]
+		name, ok := names[event.CustomerID]
+		if !ok {
+			continue
+		}
+		prevLevel, _ := event.Data["previous_level"].(string)
+		newLevel, _ := event.Data["new_level"].(string)
+		data.RiskTransitions = append(data.RiskTransitions, DigestRiskTransition{
+			CustomerName:  name,
+			PreviousLevel: prevLevel,
+			NewLevel:      newLevel,
+		})
+	}
+
+	// MRR movements
+	mrrEvents, err := s.events.ListByOrgTypeAndRange(ctx, orgID, "mrr.changed", start, end)
+	if err != nil {
+		return nil, err
+	}
+	var expansion, contraction int
+	moved := make(map[uuid.UUID]bool)
+	for _, event := range mrrEvents {
+		delta := extractInt(event.Data, "new_mrr_cents") - extractInt(event.Data, "old_mrr_cents")
+		if delta > 0 {
+			expansion += delta
+		} else {
+			contraction -= delta
+		}
+		moved[event.CustomerID] = true
+	}
+	data.MRRChangeCount = len(moved)
+	data.MRRExpansion = formatCents(expansion)
+	data.MRRContraction = formatCents(contraction)
+	data.MRRNet = formatCents(expansion - contraction)
+	if expansion > contraction {
+		data.MRRNet = "+" + data.MRRNet
+	}
+
+	return data, nil
+}
+
+// digestPeriod returns the most recent completed digest period whose send time
+// (sendHour local on the day after the period ends) has passed. Weekly periods run Monday to Monday.
+func digestPeriod(now time.Time, frequency string, loc *time.Location, sendHour int) (start, end time.Time) {
+	local := now.In(loc)
+	end = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	days := 1
+	if frequency == "weekly" {
+		days = 7
+		offset := (int(end.Weekday()) + 6) % 7 // days since Monday
+		end = end.AddDate(0, 0, -offset)
+	}
+
+	sendAt := time.Date(end.Year(), end.Month(), end.Day(), sendHour, 0, 0, 0, loc)
+	if local.Before(sendAt) {
+		end = end.AddDate(0, 0, -days)
+	}
+	return end.AddDate(0, 0, -days), end
+}
+
+// loadLocation resolves an IANA timezone name, falling back to UTC.
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// formatCents formats an amount in cents as a dollar string.
+func formatCents(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, cents/100, cents%100)
+}
+
+// formatAmount formats cents in a currency. Dollar amounts keep the $ sign;
+// other currencies are followed by their upper-case code, as in "12.50 EUR".
+func formatAmount(cents int, currency string) string {
+	if currency == "" || strings.EqualFold(currency, "usd") {
+		return formatCents(cents)
+	}
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d %s", sign, cents/100, cents%100, strings.ToUpper(currency))
+}
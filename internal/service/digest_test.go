@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+func TestDigestPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       string
+		frequency string
+		tz        string
+		wantStart string
+		wantEnd   string
+	}{
+		{"daily after send hour", "2026-01-07T09:00:00Z", "daily", "", "2026-01-06T00:00:00Z", "2026-01-07T00:00:00Z"},
+		{"daily before send hour", "2026-01-07T07:59:00Z", "daily", "", "2026-01-05T00:00:00Z", "2026-01-06T00:00:00Z"},
+		{"daily at send hour", "2026-01-07T08:00:00Z", "daily", "", "2026-01-06T00:00:00Z", "2026-01-07T00:00:00Z"},
+		{"weekly on Monday after send hour", "2026-01-12T09:00:00Z", "weekly", "", "2026-01-05T00:00:00Z", "2026-01-12T00:00:00Z"},
+		{"weekly on Monday before send hour", "2026-01-12T07:00:00Z", "weekly", "", "2025-12-29T00:00:00Z", "2026-01-05T00:00:00Z"},
+		{"weekly on Sunday", "2026-01-11T20:00:00Z", "weekly", "", "2025-12-29T00:00:00Z", "2026-01-05T00:00:00Z"},
+		{"behind UTC", "2026-01-07T15:00:00Z", "daily", "America/Los_Angeles", "2026-01-05T08:00:00Z", "2026-01-06T08:00:00Z"},
+		{"ahead of UTC", "2026-01-06T23:30:00Z", "daily", "Asia/Tokyo", "2026-01-05T15:00:00Z", "2026-01-06T15:00:00Z"},
+		{"weekly across a date line", "2026-01-11T19:30:00Z", "weekly", "Pacific/Auckland", "2026-01-04T11:00:00Z", "2026-01-11T11:00:00Z"},
+		{"send hour on a DST change", "2026-03-08T12:30:00Z", "daily", "America/New_York", "2026-03-07T05:00:00Z", "2026-03-08T05:00:00Z"},
+		{"unknown timezone", "2026-01-07T09:00:00Z", "daily", "Mars/Olympus", "2026-01-06T00:00:00Z", "2026-01-07T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := digestPeriod(mustParseTime(t, tt.now), tt.frequency, loadLocation(tt.tz), 8)
+			if !start.Equal(mustParseTime(t, tt.wantStart)) || !end.Equal(mustParseTime(t, tt.wantEnd)) {
+				t.Errorf("expected %s to %s, got %s to %s", tt.wantStart, tt.wantEnd, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestFormatCents(t *testing.T) {
+	tests := []struct {
+		cents int
+		want  string
+	}{
+		{0, "$0.00"},
+		{5, "$0.05"},
+		{4900, "$49.00"},
+		{123456, "$1234.56"},
+		{-12345, "-$123.45"},
+	}
+	for _, tt := range tests {
+		if got := formatCents(tt.cents); got != tt.want {
+			t.Errorf("formatCents(%d) = %q, want %q", tt.cents, got, tt.want)
+		}
+	}
+}
+
+type fakeDigestPreferenceLister struct {
+	prefs []*repository.NotificationPreference
+}
+
+func (f *fakeDigestPreferenceLister) ListDigestEnabled(ctx context.Context) ([]*repository.NotificationPreference, error) {
+	return f.prefs, nil
+}
+
+type fakeDigestHistoryStore struct {
+	taken  map[uuid.UUID]bool // users whose digest is already claimed
+	sent   []uuid.UUID
+	failed []uuid.UUID
+}
+
+func (f *fakeDigestHistoryStore) Claim(ctx context.Context, h *repository.DigestHistory, staleAfter time.Duration) (bool, error) {
+	if f.taken[h.UserID] {
+		return false, nil
+	}
+	h.ID = h.UserID
+	return true, nil
+}
+
+func (f *fakeDigestHistoryStore) MarkSent(ctx context.Context, id uuid.UUID, msgID string) error {
+	f.sent = append(f.sent, id)
+	return nil
+}
+
+func (f *fakeDigestHistoryStore) MarkFailed(ctx context.Context, id uuid.UUID, errorMsg string) error {
+	f.failed = append(f.failed, id)
+	return nil
+}
+
+type fakeUserReader struct{}
+
+func (fakeUserReader) GetByID(ctx context.Context, id uuid.UUID) (*repository.User, error) {
+	return &repository.User{ID: id, Email: id.String() + "@acme.com"}, nil
+}
+
+type fakeOrgReader struct {
+	calls int
+	err   error
+}
+
+func (f *fakeOrgReader) GetByID(ctx context.Context, id uuid.UUID) (*repository.Organization, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &repository.Organization{ID: id, Name: "Acme"}, nil
+}
+
+type fakeDigestCustomerReader struct {
+	customers []*repository.Customer
+}
+
+func (f *fakeDigestCustomerReader) CountByOrg(ctx context.Context, orgID uuid.UUID) (int, error) {
+	return len(f.customers), nil
+}
+
+func (f *fakeDigestCustomerReader) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*repository.Customer, error) {
+	return f.customers, nil
+}
+
+type fakeDigestScoreReader struct {
+	changes []repository.ScoreChange
+}
+
+func (f *fakeDigestScoreReader) CountByRiskLevel(ctx context.Context, orgID uuid.UUID) (map[string]int, error) {
+	return map[string]int{"red": 2, "green": 5}, nil
+}
+
+func (f *fakeDigestScoreReader) ListScoreChanges(ctx context.Context, orgID uuid.UUID, from, to time.Time) ([]repository.ScoreChange, error) {
+	return f.changes, nil
+}
+
+type fakeAlertRuleCounter struct{}
+
+func (fakeAlertRuleCounter) CountByRuleInRange(ctx context.Context, orgID uuid.UUID, from, to time.Time) ([]repository.AlertRuleCount, error) {
+	return []repository.AlertRuleCount{{RuleName: "Score below 40", Count: 3}, {RuleName: "Payment failed", Count: 1}}, nil
+}
+
+type fakeEventRangeLister struct {
+	events map[string][]*repository.CustomerEvent
+}
+
+func (f *fakeEventRangeLister) ListByOrgTypeAndRange(ctx context.Context, orgID uuid.UUID, eventType string, from, to time.Time) ([]*repository.CustomerEvent, error) {
+	return f.events[eventType], nil
+}
+
+func newTestDigestService(t *testing.T, prefs []*repository.NotificationPreference) (*DigestService, *fakeDigestHistoryStore, *fakeOrgReader, *fakeEmailService) {
+	t.Helper()
+	templates, err := NewEmailTemplateService()
+	if err != nil {
+		t.Fatalf("load email templates: %v", err)
+	}
+	history := &fakeDigestHistoryStore{taken: map[uuid.UUID]bool{}}
+	orgs := &fakeOrgReader{}
+	emails := &fakeEmailService{}
+	return &DigestService{
+		prefs:        &fakeDigestPreferenceLister{prefs: prefs},
+		history:      history,
+		users:        fakeUserReader{},
+		orgs:         orgs,
+		customers:    &fakeDigestCustomerReader{},
+		healthScores: &fakeDigestScoreReader{},
+		alertHistory: fakeAlertRuleCounter{},
+		events:       &fakeEventRangeLister{},
+		emailService: emails,
+		templates:    templates,
+		sendHour:     8,
+		frontendURL:  "https://app.pulsescore.test",
+	}, history, orgs, emails
+}
+
+func TestDigestRunOnce(t *testing.T) {
+	orgID := uuid.New()
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	prefs := []*repository.NotificationPreference{
+		{UserID: alice, OrgID: orgID, DigestFrequency: "weekly"},
+		{UserID: bob, OrgID: orgID, DigestFrequency: "weekly", Timezone: "UTC"},
+		{UserID: carol, OrgID: orgID, DigestFrequency: "weekly"},
+	}
+	svc, history, orgs, emails := newTestDigestService(t, prefs)
+	history.taken[carol] = true
+
+	svc.RunOnce(context.Background(), mustParseTime(t, "2026-01-12T09:00:00Z"))
+
+	if len(emails.sent) != 2 || len(history.sent) != 2 {
+		t.Fatalf("expected 2 digests sent, got %d emails and %d marked sent", len(emails.sent), len(history.sent))
+	}
+	if orgs.calls != 1 {
+		t.Errorf("expected the org's digest to be built once, got %d builds", orgs.calls)
+	}
+	for _, e := range emails.sent {
+		if e.To == carol.String()+"@acme.com" {
+			t.Error("expected an already claimed digest not to be sent again")
+		}
+		if e.Subject != "Your weekly PulseScore digest for Acme" {
+			t.Errorf("unexpected subject %q", e.Subject)
+		}
+	}
+}
+
+func TestDigestRunOnce_MarksFailures(t *testing.T) {
+	prefs := []*repository.NotificationPreference{{UserID: uuid.New(), OrgID: uuid.New(), DigestFrequency: "daily"}}
+
+	svc, history, _, emails := newTestDigestService(t, prefs)
+	emails.err = errors.New("sendgrid unavailable")
+	svc.RunOnce(context.Background(), mustParseTime(t, "2026-01-12T09:00:00Z"))
+	if len(history.failed) != 1 || len(history.sent) != 0 {
+		t.Errorf("expected a failed send to be marked failed, got %d failed and %d sent", len(history.failed), len(history.sent))
+	}
+
+	svc, history, orgs, emails := newTestDigestService(t, prefs)
+	orgs.err = errors.New("db down")
+	svc.RunOnce(context.Background(), mustParseTime(t, "2026-01-12T09:00:00Z"))
+	if len(history.failed) != 1 || len(emails.sent) != 0 {
+		t.Errorf("expected a failed build to be marked failed, got %d failed and %d sent", len(history.failed), len(emails.sent))
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	svc, _, _, _ := newTestDigestService(t, nil)
+	acme, globex := uuid.New(), uuid.New()
+	svc.customers = &fakeDigestCustomerReader{customers: []*repository.Customer{{ID: acme, Name: "Acme"}, {ID: globex, Name: "Globex"}}}
+	svc.healthScores = &fakeDigestScoreReader{changes: []repository.ScoreChange{
+		{CustomerName: "A", OldScore: 80, NewScore: 70},
+		{CustomerName: "B", OldScore: 60, NewScore: 30},
+		{CustomerName: "C", OldScore: 50, NewScore: 55},
+		{CustomerName: "D", OldScore: 90, NewScore: 85},
+		{CustomerName: "E", OldScore: 70, NewScore: 50},
+		{CustomerName: "F", OldScore: 40, NewScore: 39},
+		{CustomerName: "G", OldScore: 75, NewScore: 60},
+	}}
+	svc.events = &fakeEventRangeLister{events: map[string][]*repository.CustomerEvent{
+		"risk_level.changed": {
+			{CustomerID: acme, Data: map[string]any{"previous_level": "green", "new_level": "yellow"}},
+			{CustomerID: uuid.New(), Data: map[string]any{"previous_level": "green", "new_level": "red"}},
+			{CustomerID: globex, Data: map[string]any{"previous_level": "yellow", "new_level": "red"}},
+		},
+		"mrr.changed": {
+			{CustomerID: acme, Data: map[string]any{"old_mrr_cents": float64(10000), "new_mrr_cents": float64(15000)}},
+			{CustomerID: acme, Data: map[string]any{"old_mrr_cents": float64(15000), "new_mrr_cents": float64(14000)}},
+			{CustomerID: globex, Data: map[string]any{"old_mrr_cents": float64(9900), "new_mrr_cents": float64(0)}},
+		},
+	}}
+
+	start := mustParseTime(t, "2026-01-05T00:00:00Z")
+	data, err := svc.buildDigest(context.Background(), uuid.New(), "weekly", start, start.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if data.PeriodStart != "Jan 5" || data.PeriodEnd != "Jan 11" {
+		t.Errorf("expected Jan 5 to Jan 11, got %s to %s", data.PeriodStart, data.PeriodEnd)
+	}
+	if data.TotalCustomers != 2 || data.AtRiskCount != 2 || data.AlertsFired != 4 {
+		t.Errorf("unexpected totals: %d customers, %d at risk, %d alerts", data.TotalCustomers, data.AtRiskCount, data.AlertsFired)
+	}
+	if data.ImprovedCount != 1 || data.DeclinedCount != 6 {
+		t.Errorf("expected 1 improved and 6 declined, got %d and %d", data.ImprovedCount, data.DeclinedCount)
+	}
+	var names []string
+	for _, d := range data.TopDecliners {
+		names = append(names, d.Name)
+	}
+	if got := strings.Join(names, ","); got != "B,E,G,A,D" {
+		t.Errorf("expected the 5 biggest decliners first, got %s", got)
+	}
+	if len(data.RiskTransitions) != 2 || data.RiskTransitions[0].CustomerName != "Globex" {
+		t.Errorf("expected known customers' transitions, most recent first, got %+v", data.RiskTransitions)
+	}
+	if data.MRRChangeCount != 2 || data.MRRExpansion != "$50.00" || data.MRRContraction != "$109.00" || data.MRRNet != "-$59.00" {
+		t.Errorf("unexpected MRR movement: %d customers, +%s, -%s, net %s", data.MRRChangeCount, data.MRRExpansion, data.MRRContraction, data.MRRNet)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"html/template"
 	"strings"
+
+	"github.com/onnwee/pulse-score/internal/repository"
 )
 
 //go:embed templates/emails/*.html
@@ -13,10 +15,16 @@ var emailTemplatesFS embed.FS
 
 // EmailTemplateService renders email templates.
 type EmailTemplateService struct {
+	scoreBelow    *template.Template
 	scoreDrop     *template.Template
 	riskChange    *template.Template
 	paymentFailed *template.Template
-	weeklyDigest  *template.Template
+	digest        *template.Template
+	custom        *template.Template
+	reauth        *template.Template
+	billingEvent  *template.Template
+	customField   *template.Template
+	renewal       *template.Template
 }
 
 // NewEmailTemplateService creates a new EmailTemplateService loading embedded templates.
@@ -42,6 +50,10 @@ func NewEmailTemplateService() (*EmailTemplateService, error) {
 		return t, nil
 	}
 
+	scoreBelow, err := parse("score_below.html")
+	if err != nil {
+		return nil, err
+	}
 	scoreDrop, err := parse("score_drop.html")
 	if err != nil {
 		return nil, err
@@ -54,19 +66,56 @@ func NewEmailTemplateService() (*EmailTemplateService, error) {
 	if err != nil {
 		return nil, err
 	}
-	weeklyDigest, err := parse("weekly_digest.html")
+	digest, err := parse("weekly_digest.html")
+	if err != nil {
+		return nil, err
+	}
+	custom, err := parse("custom.html")
+	if err != nil {
+		return nil, err
+	}
+	reauth, err := parse("integration_reauth.html")
+	if err != nil {
+		return nil, err
+	}
+	billingEvent, err := parse("billing_event.html")
+	if err != nil {
+		return nil, err
+	}
+	customField, err := parse("custom_field.html")
+	if err != nil {
+		return nil, err
+	}
+	renewal, err := parse("renewal_upcoming.html")
 	if err != nil {
 		return nil, err
 	}
 
 	return &EmailTemplateService{
+		scoreBelow:    scoreBelow,
 		scoreDrop:     scoreDrop,
 		riskChange:    riskChange,
 		paymentFailed: paymentFailed,
-		weeklyDigest:  weeklyDigest,
+		digest:        digest,
+		custom:        custom,
+		reauth:        reauth,
+		billingEvent:  billingEvent,
+		customField:   customField,
+		renewal:       renewal,
 	}, nil
 }
 
+// ScoreBelowEmailData holds data for score below threshold email template.
+type ScoreBelowEmailData struct {
+	CustomerName      string
+	CompanyName       string
+	Score             int
+	Threshold         int
+	RiskLevel         string
+	CustomerDetailURL string
+	UnsubscribeURL    string
+}
+
 // ScoreDropEmailData holds data for score drop email template.
 type ScoreDropEmailData struct {
 	CustomerName      string
@@ -100,7 +149,58 @@ type PaymentFailedEmailData struct {
 	UnsubscribeURL    string
 }
 
-// CustomerScoreChange represents a score change for the weekly digest.
+// BillingEventEmailData holds data for the billing event email template.
+type BillingEventEmailData struct {
+	CustomerName      string
+	CompanyName       string
+	EventLabel        string
+	Amount            string
+	Detail            string
+	CustomerDetailURL string
+	UnsubscribeURL    string
+}
+
+// CustomFieldEmailData holds data for the custom field alert email template.
+type CustomFieldEmailData struct {
+	CustomerName      string
+	CompanyName       string
+	FieldLabel        string
+	Condition         string
+	Value             string
+	CustomerDetailURL string
+	UnsubscribeURL    string
+}
+
+// RenewalEmailData holds data for the upcoming renewal alert email template.
+type RenewalEmailData struct {
+	CustomerName      string
+	CompanyName       string
+	RenewalDate       string
+	DaysUntil         int
+	Amount            string
+	HasScore          bool
+	Score             int
+	RiskLevel         string
+	CustomerDetailURL string
+	UnsubscribeURL    string
+}
+
+// CustomEmailData holds an org-defined alert body rendered into the standard layout.
+type CustomEmailData struct {
+	Content        template.HTML
+	UnsubscribeURL string
+}
+
+// IntegrationReauthEmailData holds data for the integration reconnect email template.
+type IntegrationReauthEmailData struct {
+	OrgName        string
+	ProviderName   string
+	Reason         string
+	ReconnectURL   string
+	UnsubscribeURL string
+}
+
+// CustomerScoreChange represents a score change for the digest.
 type CustomerScoreChange struct {
 	Name     string
 	OldScore int
@@ -108,16 +208,47 @@ type CustomerScoreChange struct {
 	Delta    int
 }
 
-// WeeklyDigestEmailData holds data for weekly digest email template.
-type WeeklyDigestEmailData struct {
-	OrgName        string
-	TotalCustomers int
-	AtRiskCount    int
-	ImprovedCount  int
-	DeclinedCount  int
-	TopMovers      []CustomerScoreChange
-	DashboardURL   string
-	UnsubscribeURL string
+// DigestRiskTransition represents a customer's risk level change for the digest.
+type DigestRiskTransition struct {
+	CustomerName  string
+	PreviousLevel string
+	NewLevel      string
+}
+
+// DigestEmailData holds data for the daily/weekly digest email template.
+type DigestEmailData struct {
+	OrgName         string
+	Frequency       string // daily, weekly
+	PeriodLabel     string // Daily, Weekly
+	PeriodStart     string
+	PeriodEnd       string
+	TotalCustomers  int
+	AtRiskCount     int
+	ImprovedCount   int
+	DeclinedCount   int
+	AlertsFired     int
+	AlertRules      []repository.AlertRuleCount
+	RiskTransitions []DigestRiskTransition
+	TopDecliners    []CustomerScoreChange
+	MRRChangeCount  int
+	MRRExpansion    string
+	MRRContraction  string
+	MRRNet          string
+	DashboardURL    string
+	UnsubscribeURL  string
+}
+
+// RenderScoreBelow renders the score below threshold email template.
+func (s *EmailTemplateService) RenderScoreBelow(data ScoreBelowEmailData) (html string, text string, err error) {
+	html, err = renderTemplate(s.scoreBelow, data)
+	if err != nil {
+		return "", "", err
+	}
+	text = fmt.Sprintf(
+		"Health Score Below Threshold\n\n%s's health score is %d, below the alert threshold of %d.\nRisk level: %s\n\nView details: %s",
+		data.CustomerName, data.Score, data.Threshold, data.RiskLevel, data.CustomerDetailURL,
+	)
+	return html, text, nil
 }
 
 // RenderScoreDrop renders the score drop email template.
@@ -159,30 +290,119 @@ func (s *EmailTemplateService) RenderPaymentFailed(data PaymentFailedEmailData)
 	return html, text, nil
 }
 
-// RenderWeeklyDigest renders the weekly digest email template.
-func (s *EmailTemplateService) RenderWeeklyDigest(data WeeklyDigestEmailData) (html string, text string, err error) {
-	html, err = renderTemplate(s.weeklyDigest, data)
+// RenderBillingEvent renders the billing event email template.
+func (s *EmailTemplateService) RenderBillingEvent(data BillingEventEmailData) (html string, text string, err error) {
+	html, err = renderTemplate(s.billingEvent, data)
 	if err != nil {
 		return "", "", err
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Weekly Health Digest — %s\n\n", data.OrgName))
+	sb.WriteString(fmt.Sprintf("%s\n\n%s for %s.\n", data.EventLabel, data.EventLabel, data.CustomerName))
+	if data.Amount != "" {
+		sb.WriteString(fmt.Sprintf("Amount: %s\n", data.Amount))
+	}
+	if data.Detail != "" {
+		sb.WriteString(data.Detail + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nView details: %s", data.CustomerDetailURL))
+	return html, sb.String(), nil
+}
+
+// RenderCustomField renders the custom field alert email template.
+func (s *EmailTemplateService) RenderCustomField(data CustomFieldEmailData) (html string, text string, err error) {
+	html, err = renderTemplate(s.customField, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s Alert\n\n%s for %s %s.\n", data.FieldLabel, data.FieldLabel, data.CustomerName, data.Condition))
+	if data.Value != "" {
+		sb.WriteString(fmt.Sprintf("Current value: %s\n", data.Value))
+	}
+	sb.WriteString(fmt.Sprintf("\nView details: %s", data.CustomerDetailURL))
+	return html, sb.String(), nil
+}
+
+// RenderRenewal renders the upcoming renewal alert email template.
+func (s *EmailTemplateService) RenderRenewal(data RenewalEmailData) (html string, text string, err error) {
+	html, err = renderTemplate(s.renewal, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Renewal Coming Up\n\n%s renews on %s, in %d days.\n", data.CustomerName, data.RenewalDate, data.DaysUntil))
+	sb.WriteString(fmt.Sprintf("Renewal amount: %s\n", data.Amount))
+	if data.HasScore {
+		sb.WriteString(fmt.Sprintf("Health score: %d\n", data.Score))
+	}
+	sb.WriteString(fmt.Sprintf("\nView details: %s", data.CustomerDetailURL))
+	return html, sb.String(), nil
+}
+
+// RenderDigest renders the daily/weekly digest email template.
+func (s *EmailTemplateService) RenderDigest(data DigestEmailData) (html string, text string, err error) {
+	html, err = renderTemplate(s.digest, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s Health Digest — %s (%s – %s)\n\n", data.PeriodLabel, data.OrgName, data.PeriodStart, data.PeriodEnd))
 	sb.WriteString(fmt.Sprintf("Total Customers: %d\n", data.TotalCustomers))
 	sb.WriteString(fmt.Sprintf("At Risk: %d\n", data.AtRiskCount))
 	sb.WriteString(fmt.Sprintf("Improved: %d\n", data.ImprovedCount))
 	sb.WriteString(fmt.Sprintf("Declined: %d\n\n", data.DeclinedCount))
-	if len(data.TopMovers) > 0 {
-		sb.WriteString("Top Movers:\n")
-		for _, m := range data.TopMovers {
+	if data.AlertsFired > 0 {
+		sb.WriteString(fmt.Sprintf("Alerts Fired: %d\n", data.AlertsFired))
+		for _, r := range data.AlertRules {
+			sb.WriteString(fmt.Sprintf("  %s: %d\n", r.RuleName, r.Count))
+		}
+		sb.WriteString("\n")
+	}
+	if len(data.RiskTransitions) > 0 {
+		sb.WriteString("Risk Transitions:\n")
+		for _, t := range data.RiskTransitions {
+			sb.WriteString(fmt.Sprintf("  %s: %s → %s\n", t.CustomerName, t.PreviousLevel, t.NewLevel))
+		}
+		sb.WriteString("\n")
+	}
+	if len(data.TopDecliners) > 0 {
+		sb.WriteString("Top Decliners:\n")
+		for _, m := range data.TopDecliners {
 			sb.WriteString(fmt.Sprintf("  %s: %d → %d (%+d)\n", m.Name, m.OldScore, m.NewScore, m.Delta))
 		}
+		sb.WriteString("\n")
 	}
-	sb.WriteString(fmt.Sprintf("\nView dashboard: %s", data.DashboardURL))
+	if data.MRRChangeCount > 0 {
+		sb.WriteString(fmt.Sprintf("MRR Movements: +%s expansion, -%s contraction, %s net across %d customers\n\n",
+			data.MRRExpansion, data.MRRContraction, data.MRRNet, data.MRRChangeCount))
+	}
+	sb.WriteString(fmt.Sprintf("View dashboard: %s", data.DashboardURL))
 	text = sb.String()
 	return html, text, nil
 }
 
+// RenderCustom wraps an already rendered org-defined alert body in the standard layout.
+func (s *EmailTemplateService) RenderCustom(data CustomEmailData) (string, error) {
+	return renderTemplate(s.custom, data)
+}
+
+// RenderIntegrationReauth renders the email asking admins to reconnect a revoked integration.
+func (s *EmailTemplateService) RenderIntegrationReauth(data IntegrationReauthEmailData) (html string, text string, err error) {
+	html, err = renderTemplate(s.reauth, data)
+	if err != nil {
+		return "", "", err
+	}
+	text = fmt.Sprintf(
+		"Reconnect %s\n\n%s revoked PulseScore's access. Syncing is paused until an admin reconnects the integration.\nReason: %s\n\nReconnect: %s",
+		data.ProviderName, data.ProviderName, data.Reason, data.ReconnectURL,
+	)
+	return html, text, nil
+}
+
 func renderTemplate(t *template.Template, data any) (string, error) {
 	var buf bytes.Buffer
 	if err := t.ExecuteTemplate(&buf, "base", data); err != nil {
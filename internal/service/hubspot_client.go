@@ -6,25 +6,44 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
-
-	"golang.org/x/time/rate"
+	"time"
 )
 
 const hubspotBaseURL = "https://api.hubapi.com"
 
 // HubSpotClient provides rate-limited access to the HubSpot API.
 type HubSpotClient struct {
-	client  *http.Client
-	limiter *rate.Limiter
+	client *http.Client
+}
+
+// NewHubSpotClient creates a new HubSpotClient that sends requests through transport.
+func NewHubSpotClient(transport *ProviderTransport) *HubSpotClient {
+	return &HubSpotClient{client: transport.Client()}
 }
 
-// NewHubSpotClient creates a new HubSpotClient with rate limiting.
-func NewHubSpotClient() *HubSpotClient {
-	return &HubSpotClient{
-		client:  &http.Client{},
-		limiter: rate.NewLimiter(rate.Limit(10), 10), // 10 requests/second
+// NewHubSpotTransport creates the provider transport for the HubSpot API.
+func NewHubSpotTransport() *ProviderTransport {
+	return NewProviderTransport("hubspot", ProviderTransportConfig{
+		RequestsPerSecond: 10, // 100 requests per 10 seconds per account
+		Burst:             10,
+		MaxRetries:        4,
+		QuotaExhausted:    hubspotQuotaExhausted,
+	})
+}
+
+// hubspotQuotaExhausted pauses a connection for the rest of its rate-limit
+// window once HubSpot reports no requests remaining.
+func hubspotQuotaExhausted(h http.Header) (time.Duration, bool) {
+	if h.Get("X-HubSpot-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+	ms, err := strconv.Atoi(h.Get("X-HubSpot-RateLimit-Interval-Milliseconds"))
+	if err != nil || ms <= 0 {
+		return time.Second, true
 	}
+	return time.Duration(ms) * time.Millisecond, true
 }
 
 // HubSpotContactListResponse represents the HubSpot contacts list API response.
@@ -193,10 +212,6 @@ func (c *HubSpotClient) SearchDeals(ctx context.Context, accessToken string, fil
 }
 
 func doGet[T any](ctx context.Context, c *HubSpotClient, url, accessToken string) (*T, error) {
-	if err := c.limiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -215,7 +230,7 @@ func doGet[T any](ctx context.Context, c *HubSpotClient, url, accessToken string
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("hubspot api error: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, &ProviderAPIError{Provider: "hubspot", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var result T
@@ -226,9 +241,8 @@ func doGet[T any](ctx context.Context, c *HubSpotClient, url, accessToken string
 }
 
 func doPost[T any](ctx context.Context, c *HubSpotClient, url, accessToken string, payload any) (*T, error) {
-	if err := c.limiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
+	// Only the read-only search endpoints are POSTed to, so they can be retried
+	ctx = withIdempotentRequest(ctx)
 
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -254,7 +268,7 @@ func doPost[T any](ctx context.Context, c *HubSpotClient, url, accessToken strin
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("hubspot api error: status %d, body: %s", resp.StatusCode, string(respBody))
+		return nil, &ProviderAPIError{Provider: "hubspot", StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var result T
@@ -263,3 +277,10 @@ func doPost[T any](ctx context.Context, c *HubSpotClient, url, accessToken strin
 	}
 	return &result, nil
 }
+
+// CheckAccess lists one page of contacts to verify accessToken works. A
+// rejected token is reported as integration.ErrReauthRequired.
+func (c *HubSpotClient) CheckAccess(ctx context.Context, accessToken string) error {
+	_, err := c.ListContacts(ctx, accessToken, "")
+	return reauthOnUnauthorized(err)
+}
@@ -3,16 +3,20 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/keyring"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
@@ -21,18 +25,41 @@ type HubSpotOAuthConfig struct {
 	ClientID         string
 	ClientSecret     string
 	OAuthRedirectURL string
-	EncryptionKey    string // 32-byte hex-encoded AES key
+	EncryptionKey    string // 32-byte hex-encoded AES key; only reads legacy tokens once Keyring is set
+	Keyring          *keyring.Keyring
+}
+
+// hubspotTokenURL is HubSpot's OAuth token endpoint.
+const hubspotTokenURL = "https://api.hubapi.com/oauth/v1/token"
+
+type oauthConnectionStore interface {
+	GetByOrgAndProvider(ctx context.Context, orgID uuid.UUID, provider string) (*repository.IntegrationConnection, error)
+	Upsert(ctx context.Context, conn *repository.IntegrationConnection) error
+	Delete(ctx context.Context, orgID uuid.UUID, provider string) error
 }
 
 // HubSpotOAuthService handles HubSpot OAuth connect flow.
 type HubSpotOAuthService struct {
 	cfg      HubSpotOAuthConfig
-	connRepo *repository.IntegrationConnectionRepository
+	connRepo oauthConnectionStore
+	tokens   *TokenCipher
+	tokenURL string
+
+	// Per-org refresh lock. HubSpot rotates the refresh token on every use,
+	// so two concurrent refreshes would leave one holding a dead token.
+	mu           sync.Mutex
+	refreshLocks map[uuid.UUID]*sync.Mutex
 }
 
 // NewHubSpotOAuthService creates a new HubSpotOAuthService.
 func NewHubSpotOAuthService(cfg HubSpotOAuthConfig, connRepo *repository.IntegrationConnectionRepository) *HubSpotOAuthService {
-	return &HubSpotOAuthService{cfg: cfg, connRepo: connRepo}
+	return &HubSpotOAuthService{
+		cfg:          cfg,
+		connRepo:     connRepo,
+		tokens:       NewTokenCipher(cfg.Keyring, cfg.EncryptionKey),
+		tokenURL:     hubspotTokenURL,
+		refreshLocks: make(map[uuid.UUID]*sync.Mutex),
+	}
 }
 
 // ConnectURL generates the HubSpot OAuth authorization URL.
@@ -74,12 +101,12 @@ func (s *HubSpotOAuthService) ExchangeCode(ctx context.Context, orgID uuid.UUID,
 		return fmt.Errorf("exchange code with hubspot: %w", err)
 	}
 
-	encrypted, err := encryptToken(tokenResp.AccessToken, s.cfg.EncryptionKey)
+	encrypted, err := s.tokens.Encrypt(tokenResp.AccessToken)
 	if err != nil {
 		return fmt.Errorf("encrypt access token: %w", err)
 	}
 
-	refreshEncrypted, err := encryptToken(tokenResp.RefreshToken, s.cfg.EncryptionKey)
+	refreshEncrypted, err := s.tokens.Encrypt(tokenResp.RefreshToken)
 	if err != nil {
 		return fmt.Errorf("encrypt refresh token: %w", err)
 	}
@@ -105,8 +132,21 @@ func (s *HubSpotOAuthService) ExchangeCode(ctx context.Context, orgID uuid.UUID,
 	return nil
 }
 
-// RefreshToken refreshes the access token using the refresh token.
+// RefreshToken refreshes the access token using the refresh token. A refresh
+// token HubSpot rejects as invalid or revoked is reported as
+// integration.ErrReauthRequired.
 func (s *HubSpotOAuthService) RefreshToken(ctx context.Context, orgID uuid.UUID) error {
+	return s.refreshIfExpiring(ctx, orgID, 0)
+}
+
+// refreshIfExpiring refreshes the access token under the org's refresh lock
+// unless it is valid for longer than within. A zero within always refreshes.
+func (s *HubSpotOAuthService) refreshIfExpiring(ctx context.Context, orgID uuid.UUID, within time.Duration) error {
+	lock := s.refreshLock(orgID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Re-read under the lock: another caller may have refreshed already.
 	conn, err := s.connRepo.GetByOrgAndProvider(ctx, orgID, "hubspot")
 	if err != nil {
 		return fmt.Errorf("get connection: %w", err)
@@ -114,23 +154,29 @@ func (s *HubSpotOAuthService) RefreshToken(ctx context.Context, orgID uuid.UUID)
 	if conn == nil {
 		return &NotFoundError{Resource: "hubspot_connection", Message: "no HubSpot connection found"}
 	}
+	if within > 0 && conn.TokenExpiresAt != nil && time.Now().Add(within).Before(*conn.TokenExpiresAt) {
+		return nil
+	}
 
-	refreshToken, err := decryptToken(conn.RefreshTokenEncrypted, s.cfg.EncryptionKey)
+	refreshToken, err := s.tokens.Decrypt(conn.RefreshTokenEncrypted)
 	if err != nil {
 		return fmt.Errorf("decrypt refresh token: %w", err)
 	}
 
 	tokenResp, err := s.refreshTokenWithHubSpot(refreshToken)
 	if err != nil {
+		if hubspotGrantRevoked(err) {
+			return fmt.Errorf("%w: %v", integration.ErrReauthRequired, err)
+		}
 		return fmt.Errorf("refresh token with hubspot: %w", err)
 	}
 
-	encrypted, err := encryptToken(tokenResp.AccessToken, s.cfg.EncryptionKey)
+	encrypted, err := s.tokens.Encrypt(tokenResp.AccessToken)
 	if err != nil {
 		return fmt.Errorf("encrypt access token: %w", err)
 	}
 
-	refreshEncrypted, err := encryptToken(tokenResp.RefreshToken, s.cfg.EncryptionKey)
+	refreshEncrypted, err := s.tokens.Encrypt(tokenResp.RefreshToken)
 	if err != nil {
 		return fmt.Errorf("encrypt refresh token: %w", err)
 	}
@@ -149,6 +195,33 @@ func (s *HubSpotOAuthService) RefreshToken(ctx context.Context, orgID uuid.UUID)
 	return nil
 }
 
+// hubspotGrantRevoked reports whether a token endpoint error says the refresh
+// token is no longer valid. Other failures, such as wrong app credentials,
+// are not fixed by reconnecting.
+func hubspotGrantRevoked(err error) bool {
+	var apiErr *ProviderAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	var body struct {
+		Error  string `json:"error"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(apiErr.Body), &body); err != nil {
+		return false
+	}
+	return body.Error == "invalid_grant" || body.Status == "BAD_REFRESH_TOKEN"
+}
+
+func (s *HubSpotOAuthService) refreshLock(orgID uuid.UUID) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.refreshLocks[orgID]; !ok {
+		s.refreshLocks[orgID] = &sync.Mutex{}
+	}
+	return s.refreshLocks[orgID]
+}
+
 // GetAccessToken retrieves and decrypts the access token, auto-refreshing if expired.
 func (s *HubSpotOAuthService) GetAccessToken(ctx context.Context, orgID uuid.UUID) (string, error) {
 	conn, err := s.connRepo.GetByOrgAndProvider(ctx, orgID, "hubspot")
@@ -164,7 +237,7 @@ func (s *HubSpotOAuthService) GetAccessToken(ctx context.Context, orgID uuid.UUI
 
 	// Auto-refresh if token is expired or about to expire (within 5 minutes)
 	if conn.TokenExpiresAt != nil && time.Now().Add(5*time.Minute).After(*conn.TokenExpiresAt) {
-		if err := s.RefreshToken(ctx, orgID); err != nil {
+		if err := s.refreshIfExpiring(ctx, orgID, 5*time.Minute); err != nil {
 			return "", fmt.Errorf("auto-refresh token: %w", err)
 		}
 		// Re-fetch the connection with the new token
@@ -174,7 +247,7 @@ func (s *HubSpotOAuthService) GetAccessToken(ctx context.Context, orgID uuid.UUI
 		}
 	}
 
-	token, err := decryptToken(conn.AccessTokenEncrypted, s.cfg.EncryptionKey)
+	token, err := s.tokens.Decrypt(conn.AccessTokenEncrypted)
 	if err != nil {
 		return "", fmt.Errorf("decrypt access token: %w", err)
 	}
@@ -192,13 +265,17 @@ func (s *HubSpotOAuthService) GetStatus(ctx context.Context, orgID uuid.UUID) (*
 		return &HubSpotConnectionStatus{Status: "disconnected"}, nil
 	}
 
-	return &HubSpotConnectionStatus{
+	status := &HubSpotConnectionStatus{
 		Status:            conn.Status,
 		ExternalAccountID: conn.ExternalAccountID,
 		LastSyncAt:        conn.LastSyncAt,
 		LastSyncError:     conn.LastSyncError,
 		ConnectedAt:       conn.CreatedAt,
-	}, nil
+	}
+	if conn.Status == "needs_reauth" {
+		status.ReconnectURL, _ = s.ConnectURL(orgID)
+	}
+	return status, nil
 }
 
 // Disconnect removes a HubSpot connection.
@@ -213,6 +290,7 @@ type HubSpotConnectionStatus struct {
 	LastSyncAt        *time.Time `json:"last_sync_at,omitempty"`
 	LastSyncError     string     `json:"last_sync_error,omitempty"`
 	ConnectedAt       time.Time  `json:"connected_at,omitempty"`
+	ReconnectURL      string     `json:"reconnect_url,omitempty"`
 	ContactCount      int        `json:"contact_count,omitempty"`
 	DealCount         int        `json:"deal_count,omitempty"`
 	CompanyCount      int        `json:"company_count,omitempty"`
@@ -250,7 +328,7 @@ func (s *HubSpotOAuthService) refreshTokenWithHubSpot(refreshToken string) (*hub
 }
 
 func (s *HubSpotOAuthService) postTokenRequest(data url.Values) (*hubspotTokenResponse, error) {
-	req, err := http.NewRequest("POST", "https://api.hubapi.com/oauth/v1/token", strings.NewReader(data.Encode()))
+	req, err := http.NewRequest("POST", s.tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -272,7 +350,7 @@ func (s *HubSpotOAuthService) postTokenRequest(data url.Values) (*hubspotTokenRe
 			"status", resp.StatusCode,
 			"body", string(body),
 		)
-		return nil, fmt.Errorf("hubspot token exchange failed with status %d", resp.StatusCode)
+		return nil, &ProviderAPIError{Provider: "hubspot", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var tokenResp hubspotTokenResponse
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+type fakeOAuthConnectionStore struct {
+	mu   sync.Mutex
+	conn *repository.IntegrationConnection
+}
+
+func (f *fakeOAuthConnectionStore) GetByOrgAndProvider(ctx context.Context, orgID uuid.UUID, provider string) (*repository.IntegrationConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn == nil {
+		return nil, nil
+	}
+	c := *f.conn
+	return &c, nil
+}
+
+func (f *fakeOAuthConnectionStore) Upsert(ctx context.Context, conn *repository.IntegrationConnection) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := *conn
+	f.conn = &c
+	return nil
+}
+
+func (f *fakeOAuthConnectionStore) Delete(ctx context.Context, orgID uuid.UUID, provider string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.conn = nil
+	return nil
+}
+
+// newTestHubSpotOAuth returns a HubSpotOAuthService whose token endpoint is
+// handler, with a connection whose access token expires in expiresIn.
+func newTestHubSpotOAuth(t *testing.T, handler http.HandlerFunc, expiresIn time.Duration) (*HubSpotOAuthService, *fakeOAuthConnectionStore) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	tokens := NewTokenCipher(nil, strings.Repeat("ab", 32))
+	refresh, err := tokens.Encrypt("refresh-0")
+	if err != nil {
+		t.Fatalf("encrypt refresh token: %v", err)
+	}
+	expiresAt := time.Now().Add(expiresIn)
+	store := &fakeOAuthConnectionStore{conn: &repository.IntegrationConnection{
+		Provider:              "hubspot",
+		Status:                "active",
+		RefreshTokenEncrypted: refresh,
+		TokenExpiresAt:        &expiresAt,
+	}}
+
+	return &HubSpotOAuthService{
+		connRepo:     store,
+		tokens:       tokens,
+		tokenURL:     srv.URL,
+		refreshLocks: make(map[uuid.UUID]*sync.Mutex),
+	}, store
+}
+
+func TestHubSpotGrantRevoked(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"invalid_grant", &ProviderAPIError{StatusCode: 400, Body: `{"error":"invalid_grant","error_description":"refresh token revoked"}`}, true},
+		{"bad refresh token", &ProviderAPIError{StatusCode: 400, Body: `{"status":"BAD_REFRESH_TOKEN","message":"missing or unknown refresh token"}`}, true},
+		{"wrapped", fmt.Errorf("http: %w", &ProviderAPIError{StatusCode: 400, Body: `{"error":"invalid_grant"}`}), true},
+		{"other bad request", &ProviderAPIError{StatusCode: 400, Body: `{"status":"BAD_CLIENT_ID","message":"missing or invalid client id"}`}, false},
+		{"not json", &ProviderAPIError{StatusCode: 400, Body: `Bad Request`}, false},
+		{"bad client credentials", &ProviderAPIError{StatusCode: 401, Body: `{"error":"invalid_client"}`}, false},
+		{"server error", &ProviderAPIError{StatusCode: 502, Body: `{"error":"invalid_grant"}`}, false},
+		{"network error", errors.New("connection reset"), false},
+	}
+	for _, tt := range tests {
+		if got := hubspotGrantRevoked(tt.err); got != tt.want {
+			t.Errorf("%s: hubspotGrantRevoked = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHubSpotRefreshToken_ReauthOnlyForRevokedGrant(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		reauth bool
+	}{
+		{http.StatusBadRequest, `{"status":"BAD_REFRESH_TOKEN","message":"missing or unknown refresh token"}`, true},
+		{http.StatusBadRequest, `{"status":"BAD_CLIENT_ID","message":"missing or invalid client id"}`, false},
+		{http.StatusUnauthorized, `{"error":"invalid_client"}`, false},
+	}
+	for _, tt := range tests {
+		svc, _ := newTestHubSpotOAuth(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			_, _ = w.Write([]byte(tt.body))
+		}, time.Minute)
+
+		err := svc.RefreshToken(context.Background(), uuid.New())
+		if err == nil {
+			t.Fatalf("%d %s: expected an error", tt.status, tt.body)
+		}
+		if got := errors.Is(err, integration.ErrReauthRequired); got != tt.reauth {
+			t.Errorf("%d %s: reauth = %v, want %v (%v)", tt.status, tt.body, got, tt.reauth, err)
+		}
+	}
+}
+
+func TestHubSpotRefreshIfExpiring_OneRefreshPerOrg(t *testing.T) {
+	var calls atomic.Int32
+	svc, store := newTestHubSpotOAuth(t, func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		// HubSpot rotates the refresh token, so each refresh must use the latest one
+		if got := r.FormValue("refresh_token"); got != fmt.Sprintf("refresh-%d", n-1) {
+			t.Errorf("refresh %d used stale refresh token %q", n, got)
+		}
+		time.Sleep(20 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(hubspotTokenResponse{
+			AccessToken:  fmt.Sprintf("access-%d", n),
+			RefreshToken: fmt.Sprintf("refresh-%d", n),
+			ExpiresIn:    1800,
+		})
+	}, time.Minute)
+
+	orgID := uuid.New()
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := svc.refreshIfExpiring(context.Background(), orgID, 5*time.Minute); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected concurrent callers to share one refresh, got %d", calls.Load())
+	}
+	if access, _ := svc.tokens.Decrypt(store.conn.AccessTokenEncrypted); access != "access-1" {
+		t.Errorf("expected the refreshed access token to be stored, got %q", access)
+	}
+
+	// A forced refresh uses the rotated refresh token
+	if err := svc.RefreshToken(context.Background(), orgID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected a forced refresh, got %d calls", calls.Load())
+	}
+}
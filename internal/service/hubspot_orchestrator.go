@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/integration"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
@@ -18,6 +21,7 @@ type HubSpotSyncResult struct {
 	Companies    *SyncProgress        `json:"companies"`
 	Enriched     bool                 `json:"enriched"`
 	Deduplicated *DeduplicationResult `json:"deduplicated,omitempty"`
+	Paused       bool                 `json:"paused,omitempty"`
 	Duration     string               `json:"duration"`
 	Errors       []string             `json:"errors,omitempty"`
 }
@@ -25,51 +29,69 @@ type HubSpotSyncResult struct {
 // HubSpotSyncOrchestratorService orchestrates the full HubSpot sync pipeline.
 type HubSpotSyncOrchestratorService struct {
 	connRepo *repository.IntegrationConnectionRepository
+	runs     *repository.SyncRunRepository
 	syncSvc  *HubSpotSyncService
-	mergeSvc *CustomerMergeService
+	resolver *IdentityResolver
 }
 
 // NewHubSpotSyncOrchestratorService creates a new HubSpotSyncOrchestratorService.
 func NewHubSpotSyncOrchestratorService(
 	connRepo *repository.IntegrationConnectionRepository,
+	runs *repository.SyncRunRepository,
 	syncSvc *HubSpotSyncService,
-	mergeSvc *CustomerMergeService,
+	resolver *IdentityResolver,
 ) *HubSpotSyncOrchestratorService {
 	return &HubSpotSyncOrchestratorService{
 		connRepo: connRepo,
+		runs:     runs,
 		syncSvc:  syncSvc,
-		mergeSvc: mergeSvc,
+		resolver: resolver,
 	}
 }
 
-// RunFullSync runs the complete HubSpot sync pipeline for an org.
-func (s *HubSpotSyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UUID) *HubSpotSyncResult {
+// RunFullSync runs the complete HubSpot sync pipeline for an org and records it as a sync run.
+func (s *HubSpotSyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) *HubSpotSyncResult {
 	start := time.Now()
 	result := &HubSpotSyncResult{}
 
+	ctx, run := startSyncRun(ctx, s.runs, orgID, "hubspot", "full", trigger)
+	defer func() { run.finish(ctx, strings.Join(result.Errors, "; ")) }()
+
 	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "hubspot", "syncing", nil); err != nil {
 		slog.Error("failed to update hubspot sync status", "error", err)
 	}
 
 	// Step 1: Sync contacts
-	contactProgress, err := s.syncSvc.SyncContacts(ctx, orgID)
+	contactProgress, err := s.syncSvc.RunFullSyncStep(ctx, orgID, hubspotStepContacts)
 	result.Contacts = contactProgress
+	run.record(contactProgress)
+	if errors.Is(err, errSyncPaused) {
+		return s.pauseFullSync(ctx, orgID, run, result, start)
+	}
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("contact sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
 	}
 
 	// Step 2: Sync deals
-	dealProgress, err := s.syncSvc.SyncDeals(ctx, orgID)
+	dealProgress, err := s.syncSvc.RunFullSyncStep(ctx, orgID, hubspotStepDeals)
 	result.Deals = dealProgress
+	run.record(dealProgress)
+	if errors.Is(err, errSyncPaused) {
+		return s.pauseFullSync(ctx, orgID, run, result, start)
+	}
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("deal sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
 	}
 
 	// Step 3: Sync companies
-	companyProgress, err := s.syncSvc.SyncCompanies(ctx, orgID)
+	companyProgress, err := s.syncSvc.RunFullSyncStep(ctx, orgID, hubspotStepCompanies)
 	result.Companies = companyProgress
+	run.record(companyProgress)
+	if errors.Is(err, errSyncPaused) {
+		return s.pauseFullSync(ctx, orgID, run, result, start)
+	}
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("company sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
@@ -82,14 +104,16 @@ func (s *HubSpotSyncOrchestratorService) RunFullSync(ctx context.Context, orgID
 		result.Enriched = true
 	}
 
-	// Step 5: Deduplication
-	dedupResult, err := s.mergeSvc.DeduplicateCustomers(ctx, orgID)
+	// Step 5: Identity resolution (merge or queue duplicates)
+	dedupResult, err := s.resolver.Resolve(ctx, orgID)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("dedup: %v", err))
 	} else {
 		result.Deduplicated = dedupResult
 	}
 
+	s.syncSvc.CompleteFullSync(ctx, orgID)
+
 	// Mark sync complete
 	now := time.Now()
 	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "hubspot", "active", &now); err != nil {
@@ -107,11 +131,15 @@ func (s *HubSpotSyncOrchestratorService) RunFullSync(ctx context.Context, orgID
 	return result
 }
 
-// RunIncrementalSync runs an incremental HubSpot sync for records modified since the given time.
-func (s *HubSpotSyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time) *HubSpotSyncResult {
+// RunIncrementalSync runs an incremental HubSpot sync for records modified since the given time
+// and records it as a sync run.
+func (s *HubSpotSyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) *HubSpotSyncResult {
 	start := time.Now()
 	result := &HubSpotSyncResult{}
 
+	ctx, run := startSyncRun(ctx, s.runs, orgID, "hubspot", "incremental", trigger)
+	defer func() { run.finish(ctx, strings.Join(result.Errors, "; ")) }()
+
 	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "hubspot", "syncing", nil); err != nil {
 		slog.Error("failed to update hubspot sync status", "error", err)
 	}
@@ -119,6 +147,7 @@ func (s *HubSpotSyncOrchestratorService) RunIncrementalSync(ctx context.Context,
 	// Step 1: Sync contacts modified since
 	contactProgress, err := s.syncSvc.SyncContactsSince(ctx, orgID, since)
 	result.Contacts = contactProgress
+	run.record(contactProgress)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("incremental contact sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
@@ -127,6 +156,7 @@ func (s *HubSpotSyncOrchestratorService) RunIncrementalSync(ctx context.Context,
 	// Step 2: Sync deals modified since
 	dealProgress, err := s.syncSvc.SyncDealsSince(ctx, orgID, since)
 	result.Deals = dealProgress
+	run.record(dealProgress)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("incremental deal sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
@@ -139,8 +169,8 @@ func (s *HubSpotSyncOrchestratorService) RunIncrementalSync(ctx context.Context,
 		result.Enriched = true
 	}
 
-	// Step 4: Deduplication for any new records
-	dedupResult, err := s.mergeSvc.DeduplicateCustomers(ctx, orgID)
+	// Step 4: Identity resolution for any new records
+	dedupResult, err := s.resolver.Resolve(ctx, orgID)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("dedup: %v", err))
 	} else {
@@ -164,6 +194,36 @@ func (s *HubSpotSyncOrchestratorService) RunIncrementalSync(ctx context.Context,
 	return result
 }
 
+// Reconcile compares local HubSpot deals with HubSpot.
+func (s *HubSpotSyncOrchestratorService) Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error) {
+	report := &integration.DriftReport{}
+	if err := s.syncSvc.ReconcileDeals(ctx, orgID, heal, report); err != nil {
+		return report, fmt.Errorf("reconcile deals: %w", err)
+	}
+	return report, nil
+}
+
+// pauseFullSync ends a full sync that stopped at a checkpoint to stay within
+// its time budget. Later steps are skipped; the next run resumes the sync,
+// skipping the steps this one finished.
+func (s *HubSpotSyncOrchestratorService) pauseFullSync(ctx context.Context, orgID uuid.UUID, run *syncRunTracker, result *HubSpotSyncResult, start time.Time) *HubSpotSyncResult {
+	run.pause()
+	result.Paused = true
+
+	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "hubspot", "active", nil); err != nil {
+		slog.Error("failed to update hubspot sync status", "error", err)
+	}
+
+	result.Duration = time.Since(start).String()
+
+	slog.Info("hubspot full sync paused at checkpoint",
+		"org_id", orgID,
+		"duration", result.Duration,
+	)
+
+	return result
+}
+
 func (s *HubSpotSyncOrchestratorService) markSyncError(ctx context.Context, orgID uuid.UUID, errMsg string) {
 	if err := s.connRepo.UpdateErrorCount(ctx, orgID, "hubspot", errMsg); err != nil {
 		slog.Error("failed to update hubspot error count", "error", err)
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// ReconcileDeals compares the org's local HubSpot deals with HubSpot and adds
+// what differs to report. With heal set, missing and stale deals are upserted
+// and orphaned ones are deleted.
+func (s *HubSpotSyncService) ReconcileDeals(ctx context.Context, orgID uuid.UUID, heal bool, report *integration.DriftReport) error {
+	accessToken, err := s.oauthSvc.GetAccessToken(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("get access token: %w", err)
+	}
+
+	localDeals, err := s.deals.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("list local deals: %w", err)
+	}
+	local := make(map[string]repository.HubSpotDeal, len(localDeals))
+	for _, d := range localDeals {
+		local[d.HubSpotDealID] = d
+	}
+
+	seen := make(map[string]bool, len(localDeals))
+	after := ""
+	for {
+		resp, err := s.client.ListDeals(ctx, accessToken, after)
+		if err != nil {
+			return fmt.Errorf("list deals: %w", err)
+		}
+
+		for _, d := range resp.Results {
+			seen[d.ID] = true
+			report.Checked++
+
+			var item integration.DriftItem
+			existing, ok := local[d.ID]
+			if !ok {
+				item = integration.DriftItem{Resource: "deal", ExternalID: d.ID, Kind: integration.DriftMissing}
+			} else {
+				diff := dealDiff(existing, d)
+				if diff == "" {
+					continue
+				}
+				item = integration.DriftItem{Resource: "deal", ExternalID: d.ID, Kind: integration.DriftStale, Detail: diff}
+			}
+
+			if heal {
+				if err := s.upsertDeal(ctx, orgID, d, true); err == nil {
+					item.Healed = true
+				}
+			}
+			report.Add(item)
+		}
+
+		if resp.Paging == nil || resp.Paging.Next == nil || resp.Paging.Next.After == "" {
+			break
+		}
+		after = resp.Paging.Next.After
+	}
+
+	for _, d := range localDeals {
+		if seen[d.HubSpotDealID] {
+			continue
+		}
+		item := integration.DriftItem{
+			Resource:   "deal",
+			ExternalID: d.HubSpotDealID,
+			Kind:       integration.DriftOrphaned,
+			Detail:     "not found in HubSpot",
+		}
+		if heal {
+			if err := s.deals.DeleteByHubSpotID(ctx, orgID, d.HubSpotDealID); err != nil {
+				slog.Error("reconcile: failed to delete orphaned hubspot deal", "hubspot_id", d.HubSpotDealID, "error", err)
+			} else {
+				item.Healed = true
+			}
+		}
+		report.Add(item)
+	}
+
+	return nil
+}
+
+// dealDiff describes the fields that differ between a stored deal and the
+// one HubSpot returned, or "" when they match.
+func dealDiff(local repository.HubSpotDeal, remote HubSpotAPIDeal) string {
+	var diffs []string
+	if local.Stage != remote.Properties.DealStage {
+		diffs = append(diffs, fmt.Sprintf("stage %s → %s", local.Stage, remote.Properties.DealStage))
+	}
+	if amount := parseAmountToCents(remote.Properties.Amount); local.AmountCents != amount {
+		diffs = append(diffs, fmt.Sprintf("amount %d → %d", local.AmountCents, amount))
+	}
+	if local.DealName != remote.Properties.DealName {
+		diffs = append(diffs, fmt.Sprintf("name %q → %q", local.DealName, remote.Properties.DealName))
+	}
+	if local.Pipeline != remote.Properties.Pipeline {
+		diffs = append(diffs, fmt.Sprintf("pipeline %s → %s", local.Pipeline, remote.Properties.Pipeline))
+	}
+	return strings.Join(diffs, ", ")
+}
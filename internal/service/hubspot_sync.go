@@ -14,13 +14,14 @@ import (
 
 // HubSpotSyncService handles syncing data from HubSpot to local database.
 type HubSpotSyncService struct {
-	oauthSvc  *HubSpotOAuthService
-	client    *HubSpotClient
-	contacts  *repository.HubSpotContactRepository
-	deals     *repository.HubSpotDealRepository
-	companies *repository.HubSpotCompanyRepository
-	customers *repository.CustomerRepository
-	events    *repository.CustomerEventRepository
+	oauthSvc    *HubSpotOAuthService
+	client      *HubSpotClient
+	contacts    *repository.HubSpotContactRepository
+	deals       *repository.HubSpotDealRepository
+	companies   *repository.HubSpotCompanyRepository
+	customers   *repository.CustomerRepository
+	events      *repository.CustomerEventRepository
+	checkpoints syncCheckpointer
 }
 
 type hubspotContactPageFetcher func(ctx context.Context, accessToken, after string) (*HubSpotContactListResponse, error)
@@ -35,26 +36,59 @@ func NewHubSpotSyncService(
 	companies *repository.HubSpotCompanyRepository,
 	customers *repository.CustomerRepository,
 	events *repository.CustomerEventRepository,
+	checkpoints *repository.SyncCheckpointRepository,
 ) *HubSpotSyncService {
 	return &HubSpotSyncService{
-		oauthSvc:  oauthSvc,
-		client:    client,
-		contacts:  contacts,
-		deals:     deals,
-		companies: companies,
-		customers: customers,
-		events:    events,
+		oauthSvc:    oauthSvc,
+		client:      client,
+		contacts:    contacts,
+		deals:       deals,
+		companies:   companies,
+		customers:   customers,
+		events:      events,
+		checkpoints: newSyncCheckpointer(checkpoints, "hubspot"),
 	}
 }
 
+// HubSpot full sync steps, in the order a full sync runs them.
+const (
+	hubspotStepContacts  = "contacts"
+	hubspotStepDeals     = "deals"
+	hubspotStepCompanies = "companies"
+)
+
+// RunFullSyncStep runs one step of a full sync. A step that a paused full sync
+// already finished is skipped and returns nil progress; a step that finishes
+// is recorded so a resumed full sync skips it.
+func (s *HubSpotSyncService) RunFullSyncStep(ctx context.Context, orgID uuid.UUID, step string) (*SyncProgress, error) {
+	var sync func(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error)
+	switch step {
+	case hubspotStepContacts:
+		sync = s.SyncContacts
+	case hubspotStepDeals:
+		sync = s.SyncDeals
+	case hubspotStepCompanies:
+		sync = s.SyncCompanies
+	default:
+		return nil, fmt.Errorf("unknown hubspot sync step %q", step)
+	}
+	return s.checkpoints.runStep(ctx, orgID, step, sync)
+}
+
+// CompleteFullSync forgets the steps a paused full sync finished, once the
+// full sync has run them all.
+func (s *HubSpotSyncService) CompleteFullSync(ctx context.Context, orgID uuid.UUID) {
+	s.checkpoints.clearSteps(ctx, orgID, hubspotStepContacts, hubspotStepDeals, hubspotStepCompanies)
+}
+
 // SyncContacts fetches all contacts from HubSpot and upserts them locally.
 func (s *HubSpotSyncService) SyncContacts(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error) {
-	return s.syncContacts(ctx, orgID, "hubspot_contacts", "list contacts", true, true, s.client.ListContacts)
+	return s.syncContacts(ctx, orgID, "hubspot_contacts", "contacts", "list contacts", true, true, s.client.ListContacts)
 }
 
 // SyncDeals fetches all deals from HubSpot and upserts them locally.
 func (s *HubSpotSyncService) SyncDeals(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error) {
-	return s.syncDeals(ctx, orgID, "hubspot_deals", "list deals", true, true, s.client.ListDeals)
+	return s.syncDeals(ctx, orgID, "hubspot_deals", "deals", "list deals", true, true, s.client.ListDeals)
 }
 
 // SyncCompanies fetches all companies from HubSpot and upserts them locally.
@@ -65,7 +99,8 @@ func (s *HubSpotSyncService) SyncCompanies(ctx context.Context, orgID uuid.UUID)
 	}
 
 	progress := &SyncProgress{Step: "hubspot_companies"}
-	after := ""
+	const checkpoint = "companies"
+	after := s.checkpoints.load(ctx, orgID, checkpoint)
 
 	for {
 		resp, err := s.client.ListCompanies(ctx, accessToken, after)
@@ -75,6 +110,7 @@ func (s *HubSpotSyncService) SyncCompanies(ctx context.Context, orgID uuid.UUID)
 
 		for _, c := range resp.Results {
 			progress.Total++
+			reportSyncProgress(ctx, progress)
 
 			numEmployees := 0
 			if c.Properties.NumberOfEmployees != "" {
@@ -109,7 +145,13 @@ func (s *HubSpotSyncService) SyncCompanies(ctx context.Context, orgID uuid.UUID)
 			break
 		}
 		after = resp.Paging.Next.After
+		s.checkpoints.save(ctx, orgID, checkpoint, after)
+
+		if syncBudgetExhausted(ctx) {
+			return progress, errSyncPaused
+		}
 	}
+	s.checkpoints.clear(ctx, orgID, checkpoint)
 
 	slog.Info("hubspot company sync complete",
 		"org_id", orgID,
@@ -180,6 +222,7 @@ func (s *HubSpotSyncService) SyncContactsSince(ctx context.Context, orgID uuid.U
 		ctx,
 		orgID,
 		"hubspot_contacts_incremental",
+		"",
 		"search contacts",
 		false,
 		false,
@@ -191,6 +234,7 @@ func (s *HubSpotSyncService) syncContacts(
 	ctx context.Context,
 	orgID uuid.UUID,
 	step string,
+	checkpoint string,
 	listErrorPrefix string,
 	logUpsertErrors bool,
 	logCompletion bool,
@@ -202,7 +246,7 @@ func (s *HubSpotSyncService) syncContacts(
 	}
 
 	progress := &SyncProgress{Step: step}
-	after := ""
+	after := s.checkpoints.load(ctx, orgID, checkpoint)
 
 	for {
 		resp, err := fetchPage(ctx, accessToken, after)
@@ -212,6 +256,7 @@ func (s *HubSpotSyncService) syncContacts(
 
 		for _, c := range resp.Results {
 			progress.Total++
+			reportSyncProgress(ctx, progress)
 
 			if err := s.upsertContactAndCustomer(ctx, orgID, c, logUpsertErrors); err != nil {
 				progress.Errors++
@@ -225,7 +270,13 @@ func (s *HubSpotSyncService) syncContacts(
 			break
 		}
 		after = resp.Paging.Next.After
+		s.checkpoints.save(ctx, orgID, checkpoint, after)
+
+		if s.checkpoints.shouldPause(ctx, checkpoint) {
+			return progress, errSyncPaused
+		}
 	}
+	s.checkpoints.clear(ctx, orgID, checkpoint)
 
 	if logCompletion {
 		slog.Info("hubspot contact sync complete",
@@ -256,6 +307,7 @@ func (s *HubSpotSyncService) SyncDealsSince(ctx context.Context, orgID uuid.UUID
 		ctx,
 		orgID,
 		"hubspot_deals_incremental",
+		"",
 		"search deals",
 		false,
 		false,
@@ -267,6 +319,7 @@ func (s *HubSpotSyncService) syncDeals(
 	ctx context.Context,
 	orgID uuid.UUID,
 	step string,
+	checkpoint string,
 	listErrorPrefix string,
 	logUpsertErrors bool,
 	logCompletion bool,
@@ -278,7 +331,7 @@ func (s *HubSpotSyncService) syncDeals(
 	}
 
 	progress := &SyncProgress{Step: step}
-	after := ""
+	after := s.checkpoints.load(ctx, orgID, checkpoint)
 
 	for {
 		resp, err := fetchPage(ctx, accessToken, after)
@@ -288,6 +341,7 @@ func (s *HubSpotSyncService) syncDeals(
 
 		for _, d := range resp.Results {
 			progress.Total++
+			reportSyncProgress(ctx, progress)
 
 			if err := s.upsertDeal(ctx, orgID, d, logUpsertErrors); err != nil {
 				progress.Errors++
@@ -301,7 +355,13 @@ func (s *HubSpotSyncService) syncDeals(
 			break
 		}
 		after = resp.Paging.Next.After
+		s.checkpoints.save(ctx, orgID, checkpoint, after)
+
+		if s.checkpoints.shouldPause(ctx, checkpoint) {
+			return progress, errSyncPaused
+		}
 	}
+	s.checkpoints.clear(ctx, orgID, checkpoint)
 
 	if logCompletion {
 		slog.Info("hubspot deal sync complete",
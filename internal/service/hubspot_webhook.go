@@ -5,10 +5,11 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"math"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -40,9 +41,7 @@ type HubSpotWebhookService struct {
 	deals        *repository.HubSpotDealRepository
 	companies    *repository.HubSpotCompanyRepository
 	events       *repository.CustomerEventRepository
-
-	processedEvents map[int64]time.Time
-	mu              sync.Mutex
+	inbox        *WebhookInboxService
 }
 
 // NewHubSpotWebhookService creates a new HubSpotWebhookService.
@@ -55,17 +54,18 @@ func NewHubSpotWebhookService(
 	deals *repository.HubSpotDealRepository,
 	companies *repository.HubSpotCompanyRepository,
 	events *repository.CustomerEventRepository,
+	inbox *WebhookInboxService,
 ) *HubSpotWebhookService {
 	return &HubSpotWebhookService{
-		clientSecret:    clientSecret,
-		syncSvc:         syncSvc,
-		mergeSvc:        mergeSvc,
-		connRepo:        connRepo,
-		contacts:        contacts,
-		deals:           deals,
-		companies:       companies,
-		events:          events,
-		processedEvents: make(map[int64]time.Time),
+		clientSecret: clientSecret,
+		syncSvc:      syncSvc,
+		mergeSvc:     mergeSvc,
+		connRepo:     connRepo,
+		contacts:     contacts,
+		deals:        deals,
+		companies:    companies,
+		events:       events,
+		inbox:        inbox,
 	}
 }
 
@@ -103,39 +103,54 @@ func (s *HubSpotWebhookService) VerifySignature(requestBody []byte, signatureHea
 	return nil
 }
 
-// ProcessEvents processes a batch of HubSpot webhook events.
+// ProcessEvents stores each event of a verified HubSpot webhook batch in the
+// webhook inbox. Events are processed asynchronously by ProcessPayload.
 func (s *HubSpotWebhookService) ProcessEvents(ctx context.Context, webhookEvents []HubSpotWebhookEvent) error {
 	for _, event := range webhookEvents {
-		if s.isProcessed(event.EventID) {
-			slog.Debug("duplicate hubspot webhook event skipped", "event_id", event.EventID)
-			continue
-		}
-
 		portalIDStr := strconv.FormatInt(event.PortalID, 10)
 		conn, err := s.connRepo.GetByProviderAndExternalID(ctx, "hubspot", portalIDStr)
 		if err != nil {
-			slog.Error("failed to lookup hubspot connection", "portal_id", event.PortalID, "error", err)
-			continue
+			return fmt.Errorf("lookup hubspot connection: %w", err)
 		}
 		if conn == nil {
 			slog.Warn("no hubspot connection for portal", "portal_id", event.PortalID)
 			continue
 		}
 
-		if err := s.processEvent(ctx, conn.OrgID, event); err != nil {
-			slog.Error("failed to process hubspot event",
-				"event_id", event.EventID,
-				"type", event.SubscriptionType,
-				"error", err,
-			)
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("encode hubspot event: %w", err)
 		}
 
-		s.markProcessed(event.EventID)
+		eventID := strconv.FormatInt(event.EventID, 10)
+		if err := s.inbox.Enqueue(ctx, "hubspot", eventID, event.SubscriptionType, &conn.OrgID, payload); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// ProcessPayload processes a stored HubSpot webhook event.
+func (s *HubSpotWebhookService) ProcessPayload(ctx context.Context, payload []byte) error {
+	var event HubSpotWebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("decode hubspot event: %w", err)
+	}
+
+	portalIDStr := strconv.FormatInt(event.PortalID, 10)
+	conn, err := s.connRepo.GetByProviderAndExternalID(ctx, "hubspot", portalIDStr)
+	if err != nil {
+		return fmt.Errorf("lookup hubspot connection: %w", err)
+	}
+	if conn == nil {
+		slog.Warn("no hubspot connection for portal", "portal_id", event.PortalID)
+		return nil
+	}
+
+	return s.processEvent(ctx, conn.OrgID, event)
+}
+
 func (s *HubSpotWebhookService) processEvent(ctx context.Context, orgID uuid.UUID, event HubSpotWebhookEvent) error {
 	switch event.SubscriptionType {
 	case "contact.creation", "contact.propertyChange":
@@ -331,24 +346,3 @@ func (s *HubSpotWebhookService) handleCompanyPropertyChange(ctx context.Context,
 	slog.Info("hubspot company updated via webhook", "object_id", event.ObjectID, "property", event.PropertyName)
 	return nil
 }
-
-func (s *HubSpotWebhookService) isProcessed(eventID int64) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	cutoff := time.Now().Add(-24 * time.Hour)
-	for id, t := range s.processedEvents {
-		if t.Before(cutoff) {
-			delete(s.processedEvents, id)
-		}
-	}
-
-	_, exists := s.processedEvents[eventID]
-	return exists
-}
-
-func (s *HubSpotWebhookService) markProcessed(eventID int64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.processedEvents[eventID] = time.Now()
-}
@@ -0,0 +1,573 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// Identity match signals.
+const (
+	IdentitySignalExternalID  = "external_id"
+	IdentitySignalAccountID   = "account_id"
+	IdentitySignalEmail       = "email"
+	IdentitySignalEmailDomain = "email_domain"
+	IdentitySignalCompanyName = "company_name"
+)
+
+// Identity match statuses.
+const (
+	IdentityMatchPending  = "pending"
+	IdentityMatchApproved = "approved"
+	IdentityMatchRejected = "rejected"
+)
+
+// Signal weights. A shared person ID or email is enough on its own to merge
+// at the default threshold; a shared account ID, domain or similar company
+// name only says the customers work at the same company, so together they
+// only reach the review queue.
+const (
+	identityScoreExternalID  = 100
+	identityScoreAccountID   = 40
+	identityScoreEmail       = 95
+	identityScoreEmailDomain = 40
+	identityScoreCompanyName = 40 // scaled by the names' similarity
+
+	// identityMaxCompanyScore caps the account, domain and company signals
+	// together, so colleagues never look like one person without a shared
+	// person ID or email.
+	identityMaxCompanyScore = 80
+
+	minCompanySimilarity = 0.6
+
+	// Customers sharing a key are only compared when there are at most this
+	// many of them, so a key like a large customer's domain stays cheap.
+	maxIdentityBlock = 50
+)
+
+// subaddressDomains are mailbox providers that deliver user+tag@ to user@.
+// Elsewhere a +tag may be a different mailbox, so it is kept.
+var subaddressDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "outlook.com": true, "hotmail.com": true,
+	"live.com": true, "icloud.com": true, "me.com": true, "proton.me": true,
+	"protonmail.com": true, "fastmail.com": true,
+}
+
+// freeEmailDomains are mailbox providers whose domain says nothing about the
+// customer's company.
+var freeEmailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "yahoo.com": true, "hotmail.com": true,
+	"outlook.com": true, "live.com": true, "msn.com": true, "icloud.com": true,
+	"me.com": true, "aol.com": true, "proton.me": true, "protonmail.com": true,
+	"gmx.com": true, "yandex.com": true, "mail.com": true, "zoho.com": true,
+}
+
+// companySuffixes are legal-form and filler words ignored when comparing
+// company names.
+var companySuffixes = map[string]bool{
+	"the": true, "inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true,
+	"corp": true, "corporation": true, "co": true, "company": true, "gmbh": true, "plc": true,
+	"ag": true, "sa": true, "bv": true, "pty": true, "srl": true,
+}
+
+// DeduplicationResult contains stats from an identity resolution run.
+type DeduplicationResult struct {
+	Merged  int `json:"merged"`
+	Queued  int `json:"queued"`
+	Skipped int `json:"skipped"`
+	Errors  int `json:"errors"`
+}
+
+// IdentityResolver finds customers that are likely the same account across
+// sources, merges confident matches and queues the rest for admin review.
+type IdentityResolver struct {
+	customers      *repository.CustomerRepository
+	matches        *repository.IdentityMatchRepository
+	merges         *repository.CustomerMergeRepository
+	mergeSvc       *CustomerMergeService
+	autoMergeScore int
+	reviewScore    int
+	metadataKeys   []string
+	accountKeys    []string
+}
+
+// NewIdentityResolver creates a new IdentityResolver. Pairs scoring at least
+// autoMergeScore are merged; pairs scoring at least reviewScore are queued.
+// metadataKeys name the customer metadata fields that hold a person's IDs
+// from other systems, such as an app user ID set on Stripe customers;
+// accountKeys name those holding the ID of their company or workspace,
+// which many customers share.
+func NewIdentityResolver(
+	customers *repository.CustomerRepository,
+	matches *repository.IdentityMatchRepository,
+	merges *repository.CustomerMergeRepository,
+	mergeSvc *CustomerMergeService,
+	autoMergeScore, reviewScore int,
+	metadataKeys, accountKeys []string,
+) *IdentityResolver {
+	return &IdentityResolver{
+		customers:      customers,
+		matches:        matches,
+		merges:         merges,
+		mergeSvc:       mergeSvc,
+		autoMergeScore: autoMergeScore,
+		reviewScore:    reviewScore,
+		metadataKeys:   metadataKeys,
+		accountKeys:    accountKeys,
+	}
+}
+
+// identityCandidate is a scored pair of customers.
+type identityCandidate struct {
+	a, b    *repository.Customer
+	score   int
+	signals []repository.IdentitySignal
+}
+
+// Resolve scores the org's customers against each other, merges pairs at or
+// above the auto-merge score into the older customer and queues pairs at or
+// above the review score. Pairs an admin rejected or unmerged are skipped.
+func (r *IdentityResolver) Resolve(ctx context.Context, orgID uuid.UUID) (*DeduplicationResult, error) {
+	customers, err := r.customers.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list customers: %w", err)
+	}
+
+	undone, err := r.merges.ListUndonePairs(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := r.matches.ListRejectedPairs(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DeduplicationResult{}
+
+	// Customers merged during this run, so later pairs act on the survivor
+	mergedInto := map[uuid.UUID]*repository.Customer{}
+	survivor := func(c *repository.Customer) *repository.Customer {
+		for {
+			next, ok := mergedInto[c.ID]
+			if !ok {
+				return c
+			}
+			c = next
+		}
+	}
+
+	for _, cand := range r.findCandidates(customers) {
+		a, b := survivor(cand.a), survivor(cand.b)
+		if a.ID == b.ID {
+			continue
+		}
+		pair := [2]uuid.UUID{a.ID, b.ID}
+		if undone[pair] || rejected[pair] {
+			result.Skipped++
+			continue
+		}
+
+		if cand.score >= r.autoMergeScore {
+			primary, duplicate := pickPrimary(a, b)
+			reason := MergeReasonIdentityMatch
+			if hasIdentitySignal(cand.signals, IdentitySignalEmail) {
+				reason = MergeReasonDuplicateEmail
+			}
+			if _, err := r.mergeSvc.merge(ctx, primary, duplicate, reason, nil); err != nil {
+				slog.Error("failed to merge matched customers", "primary_id", primary.ID, "merged_id", duplicate.ID, "error", err)
+				result.Errors++
+				continue
+			}
+			mergedInto[duplicate.ID] = primary
+			result.Merged++
+			continue
+		}
+
+		lo, hi := a.ID, b.ID
+		if bytes.Compare(lo[:], hi[:]) > 0 {
+			lo, hi = hi, lo
+		}
+		match := &repository.IdentityMatch{
+			OrgID:       orgID,
+			CustomerID:  lo,
+			CandidateID: hi,
+			Score:       cand.score,
+			Signals:     cand.signals,
+		}
+		if err := r.matches.UpsertPending(ctx, match); err != nil {
+			slog.Error("failed to queue identity match", "customer_id", lo, "candidate_id", hi, "error", err)
+			result.Errors++
+			continue
+		}
+		result.Queued++
+	}
+
+	slog.Info("identity resolution complete",
+		"org_id", orgID,
+		"merged", result.Merged,
+		"queued", result.Queued,
+		"skipped", result.Skipped,
+		"errors", result.Errors,
+	)
+	return result, nil
+}
+
+// ListMatches returns an org's identity matches with a status, highest score first.
+func (r *IdentityResolver) ListMatches(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.IdentityMatch, int, error) {
+	switch status {
+	case IdentityMatchPending, IdentityMatchApproved, IdentityMatchRejected:
+	default:
+		return nil, 0, &ValidationError{Field: "status", Message: "status must be pending, approved or rejected"}
+	}
+
+	matches, total, err := r.matches.ListByOrg(ctx, orgID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if matches == nil {
+		matches = []*repository.IdentityMatch{}
+	}
+	return matches, total, nil
+}
+
+// ApproveMatch merges a pending match's customers and marks it approved. The
+// older customer is kept unless primaryID names the other one.
+func (r *IdentityResolver) ApproveMatch(ctx context.Context, orgID, matchID uuid.UUID, primaryID, userID *uuid.UUID) (*repository.IdentityMatch, error) {
+	match, err := r.pendingMatch(ctx, orgID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := r.customers.GetByIDAndOrg(ctx, match.CustomerID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("get customer: %w", err)
+	}
+	b, err := r.customers.GetByIDAndOrg(ctx, match.CandidateID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("get customer: %w", err)
+	}
+	if a == nil || b == nil {
+		return nil, &ConflictError{Resource: "identity_match", Message: "a customer in this match was merged or deleted since it was found"}
+	}
+
+	primary, duplicate := pickPrimary(a, b)
+	if primaryID != nil {
+		switch *primaryID {
+		case a.ID:
+			primary, duplicate = a, b
+		case b.ID:
+			primary, duplicate = b, a
+		default:
+			return nil, &ValidationError{Field: "primary_id", Message: "primary_id must be one of the matched customers"}
+		}
+	}
+
+	_, err = r.mergeSvc.mergeWith(ctx, primary, duplicate, MergeReasonIdentityMatch, userID, func(tx pgx.Tx, m *repository.CustomerMerge) error {
+		match.Status = IdentityMatchApproved
+		match.MergeID = &m.ID
+		match.DecidedBy = userID
+		ok, err := r.matches.DecideTx(ctx, tx, match)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &ConflictError{Resource: "identity_match", Message: "identity match was already decided"}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.matches.GetByID(ctx, orgID, matchID)
+}
+
+// RejectMatch marks a pending match rejected so the pair is neither merged
+// nor queued again.
+func (r *IdentityResolver) RejectMatch(ctx context.Context, orgID, matchID uuid.UUID, userID *uuid.UUID) (*repository.IdentityMatch, error) {
+	match, err := r.pendingMatch(ctx, orgID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	match.Status = IdentityMatchRejected
+	match.DecidedBy = userID
+	ok, err := r.matches.Decide(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &ConflictError{Resource: "identity_match", Message: "identity match was already decided"}
+	}
+	return match, nil
+}
+
+func (r *IdentityResolver) pendingMatch(ctx context.Context, orgID, matchID uuid.UUID) (*repository.IdentityMatch, error) {
+	match, err := r.matches.GetByID(ctx, orgID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, &NotFoundError{Resource: "identity_match", Message: "identity match not found"}
+	}
+	if match.Status != IdentityMatchPending {
+		return nil, &ConflictError{Resource: "identity_match", Message: "identity match was already decided"}
+	}
+	return match, nil
+}
+
+// identityProfile holds the normalized keys a customer is matched on.
+type identityProfile struct {
+	customer   *repository.Customer
+	email      string
+	domain     string // empty for free mailbox providers
+	company    string
+	ownIDs     []string // the customer's own external ID
+	refIDs     []string // IDs of the customer in other systems, from its metadata
+	accountIDs []string // IDs of the customer's company or workspace, from its metadata
+}
+
+// findCandidates returns the pairs of customers scoring at least the review
+// score, highest first. Only customers sharing an email, domain, company name
+// or ID are compared.
+func (r *IdentityResolver) findCandidates(customers []*repository.Customer) []identityCandidate {
+	profiles := make([]*identityProfile, len(customers))
+	blocks := map[string][]int{}
+	for i, c := range customers {
+		p := r.profile(c)
+		profiles[i] = p
+
+		keys := map[string]bool{}
+		if p.email != "" {
+			keys["email:"+p.email] = true
+		}
+		if p.domain != "" {
+			keys["domain:"+p.domain] = true
+		}
+		if p.company != "" {
+			keys["company:"+p.company] = true
+		}
+		for _, id := range p.ownIDs {
+			keys["id:"+id] = true
+		}
+		for _, id := range p.refIDs {
+			keys["id:"+id] = true
+		}
+		for _, id := range p.accountIDs {
+			keys["account:"+id] = true
+		}
+		for k := range keys {
+			blocks[k] = append(blocks[k], i)
+		}
+	}
+
+	seen := map[[2]int]bool{}
+	var candidates []identityCandidate
+	for _, members := range blocks {
+		if len(members) < 2 || len(members) > maxIdentityBlock {
+			continue
+		}
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				i, j := members[x], members[y]
+				if seen[[2]int{i, j}] {
+					continue
+				}
+				seen[[2]int{i, j}] = true
+
+				score, signals := scoreIdentity(profiles[i], profiles[j])
+				if score < r.reviewScore {
+					continue
+				}
+				candidates = append(candidates, identityCandidate{
+					a: customers[i], b: customers[j], score: score, signals: signals,
+				})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].a.ID.String()+candidates[i].b.ID.String() < candidates[j].a.ID.String()+candidates[j].b.ID.String()
+	})
+	return candidates
+}
+
+func (r *IdentityResolver) profile(c *repository.Customer) *identityProfile {
+	p := &identityProfile{
+		customer: c,
+		email:    normalizeEmail(c.Email),
+		company:  normalizeCompanyName(c.CompanyName),
+	}
+	p.domain = companyEmailDomain(p.email)
+
+	if c.ExternalID != "" {
+		p.ownIDs = append(p.ownIDs, c.ExternalID)
+	}
+	for _, key := range r.metadataKeys {
+		if v, ok := c.Metadata[key].(string); ok && strings.TrimSpace(v) != "" {
+			p.refIDs = append(p.refIDs, strings.TrimSpace(v))
+		}
+	}
+	for _, key := range r.accountKeys {
+		if v, ok := c.Metadata[key].(string); ok && strings.TrimSpace(v) != "" {
+			p.accountIDs = append(p.accountIDs, strings.TrimSpace(v))
+		}
+	}
+	// A customer merged from another source keeps that source's ID in its
+	// namespaced metadata
+	if hs, ok := c.Metadata["hubspot"].(map[string]any); ok {
+		if v, ok := hs["contact_id"].(string); ok && v != "" && v != c.ExternalID {
+			p.refIDs = append(p.refIDs, v)
+		}
+	}
+	if ic, ok := c.Metadata["intercom"].(map[string]any); ok {
+		if v, ok := ic["external_id"].(string); ok && v != "" {
+			p.refIDs = append(p.refIDs, v)
+		}
+	}
+	return p
+}
+
+// scoreIdentity scores how likely two customers are the same, from 0 to 100,
+// with the signals that contributed.
+func scoreIdentity(a, b *identityProfile) (int, []repository.IdentitySignal) {
+	var signals []repository.IdentitySignal
+
+	if id := sharedIdentityID(a, b); id != "" {
+		signals = append(signals, repository.IdentitySignal{Signal: IdentitySignalExternalID, Score: identityScoreExternalID, Detail: id})
+	} else if i := slices.IndexFunc(a.accountIDs, func(id string) bool { return slices.Contains(b.accountIDs, id) }); i >= 0 {
+		signals = append(signals, repository.IdentitySignal{Signal: IdentitySignalAccountID, Score: identityScoreAccountID, Detail: a.accountIDs[i]})
+	}
+
+	if a.email != "" && a.email == b.email {
+		signals = append(signals, repository.IdentitySignal{Signal: IdentitySignalEmail, Score: identityScoreEmail, Detail: a.email})
+	} else if a.domain != "" && a.domain == b.domain {
+		signals = append(signals, repository.IdentitySignal{Signal: IdentitySignalEmailDomain, Score: identityScoreEmailDomain, Detail: a.domain})
+	}
+
+	if a.company != "" && b.company != "" {
+		if sim := companySimilarity(a.company, b.company); sim >= minCompanySimilarity {
+			signals = append(signals, repository.IdentitySignal{
+				Signal: IdentitySignalCompanyName,
+				Score:  int(math.Round(identityScoreCompanyName * sim)),
+				Detail: fmt.Sprintf("%q ~ %q", a.customer.CompanyName, b.customer.CompanyName),
+			})
+		}
+	}
+
+	person, company := 0, 0
+	for _, s := range signals {
+		switch s.Signal {
+		case IdentitySignalExternalID, IdentitySignalEmail:
+			person += s.Score
+		default:
+			company += s.Score
+		}
+	}
+	return min(person+min(company, identityMaxCompanyScore), 100), signals
+}
+
+// sharedIdentityID returns an ID one customer records for the other, or that
+// both record for the same third system. Two customers' own external IDs
+// matching is a coincidence across sources, not a match.
+func sharedIdentityID(a, b *identityProfile) string {
+	for _, ref := range a.refIDs {
+		if slices.Contains(b.ownIDs, ref) || slices.Contains(b.refIDs, ref) {
+			return ref
+		}
+	}
+	for _, ref := range b.refIDs {
+		if slices.Contains(a.ownIDs, ref) {
+			return ref
+		}
+	}
+	return ""
+}
+
+// normalizeEmail lowercases an email and, for providers that ignore them,
+// drops its +tag and, for Gmail, the dots.
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus > 0 && subaddressDomains[domain] {
+		local = local[:plus]
+	}
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
+
+// normalizeCompanyName lowercases a company name, drops punctuation and
+// legal-form words, and collapses whitespace.
+func normalizeCompanyName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, f := range fields {
+		if !companySuffixes[f] {
+			words = append(words, f)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// companySimilarity is the Dice coefficient of two normalized names'
+// character bigrams, from 0 to 1.
+func companySimilarity(a, b string) float64 {
+	a, b = strings.ReplaceAll(a, " ", ""), strings.ReplaceAll(b, " ", "")
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < 2 || len(rb) < 2 {
+		return 0
+	}
+
+	bigrams := map[string]int{}
+	for i := 0; i < len(ra)-1; i++ {
+		bigrams[string(ra[i:i+2])]++
+	}
+	shared := 0
+	for i := 0; i < len(rb)-1; i++ {
+		bg := string(rb[i : i+2])
+		if bigrams[bg] > 0 {
+			bigrams[bg]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ra)-1+len(rb)-1)
+}
+
+// pickPrimary returns the customer to keep, the one seen first, and the one
+// to merge into it.
+func pickPrimary(a, b *repository.Customer) (primary, duplicate *repository.Customer) {
+	if b.FirstSeenAt != nil && (a.FirstSeenAt == nil || b.FirstSeenAt.Before(*a.FirstSeenAt)) {
+		return b, a
+	}
+	return a, b
+}
+
+func hasIdentitySignal(signals []repository.IdentitySignal, signal string) bool {
+	return slices.ContainsFunc(signals, func(s repository.IdentitySignal) bool { return s.Signal == signal })
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"  Jane.Doe@Example.com ", "jane.doe@example.com"},
+		{"billing+acme@agency.com", "billing+acme@agency.com"},
+		{"jane+newsletter@gmail.com", "jane@gmail.com"},
+		{"Jane.Doe+x@googlemail.com", "janedoe@gmail.com"},
+		{"jane.doe+work@outlook.com", "jane.doe@outlook.com"},
+		{"+tag@gmail.com", "+tag@gmail.com"},
+		{"not-an-email", "not-an-email"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeEmail(tt.email); got != tt.want {
+			t.Errorf("normalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeCompanyName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Acme, Inc.", "acme"},
+		{"The Globex Corporation", "globex"},
+		{"Initech  GmbH", "initech"},
+		{"Stark-Industries LLC", "stark industries"},
+		{"3M Co", "3m"},
+		{"Inc.", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeCompanyName(tt.name); got != tt.want {
+			t.Errorf("normalizeCompanyName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompanySimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"acme", "acme", 1},
+		{"stark industries", "starkindustries", 1},
+		{"night", "nacht", 0.25},
+		{"acme", "globex", 0},
+		{"a", "ab", 0},
+		{"", "", 1},
+	}
+	for _, tt := range tests {
+		if got := companySimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("companySimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScoreIdentity(t *testing.T) {
+	r := &IdentityResolver{
+		metadataKeys: []string{"user_id"},
+		accountKeys:  []string{"account_id"},
+	}
+
+	tests := []struct {
+		name    string
+		a, b    repository.Customer
+		want    int
+		signals []string
+	}{
+		{
+			name:    "same email",
+			a:       repository.Customer{Email: "jane@acme.com"},
+			b:       repository.Customer{Email: "Jane@Acme.com"},
+			want:    95,
+			signals: []string{IdentitySignalEmail},
+		},
+		{
+			name:    "plus tags at a company domain stay apart",
+			a:       repository.Customer{Email: "billing+acme@agency.com"},
+			b:       repository.Customer{Email: "billing+globex@agency.com"},
+			want:    40,
+			signals: []string{IdentitySignalEmailDomain},
+		},
+		{
+			name:    "plus tag at gmail",
+			a:       repository.Customer{Email: "jane+stripe@gmail.com"},
+			b:       repository.Customer{Email: "j.ane@gmail.com"},
+			want:    95,
+			signals: []string{IdentitySignalEmail},
+		},
+		{
+			name:    "metadata holds the other's external ID",
+			a:       repository.Customer{ExternalID: "cus_1", Metadata: map[string]any{"user_id": "u_42"}},
+			b:       repository.Customer{ExternalID: "u_42"},
+			want:    100,
+			signals: []string{IdentitySignalExternalID},
+		},
+		{
+			name:    "own external IDs matching is a coincidence",
+			a:       repository.Customer{ExternalID: "42"},
+			b:       repository.Customer{ExternalID: "42"},
+			want:    0,
+			signals: nil,
+		},
+		{
+			name:    "shared account ID alone",
+			a:       repository.Customer{Metadata: map[string]any{"account_id": "acct_1"}},
+			b:       repository.Customer{Metadata: map[string]any{"account_id": "acct_1"}},
+			want:    40,
+			signals: []string{IdentitySignalAccountID},
+		},
+		{
+			name:    "colleagues stay below auto-merge",
+			a:       repository.Customer{Email: "jane@acme.com", CompanyName: "Acme Inc", Metadata: map[string]any{"account_id": "acct_1"}},
+			b:       repository.Customer{Email: "john@acme.com", CompanyName: "Acme", Metadata: map[string]any{"account_id": "acct_1"}},
+			want:    identityMaxCompanyScore,
+			signals: []string{IdentitySignalAccountID, IdentitySignalEmailDomain, IdentitySignalCompanyName},
+		},
+		{
+			name:    "free mailbox domains are not a signal",
+			a:       repository.Customer{Email: "jane@gmail.com"},
+			b:       repository.Customer{Email: "john@gmail.com"},
+			want:    0,
+			signals: nil,
+		},
+		{
+			name:    "email and company cap at 100",
+			a:       repository.Customer{Email: "jane@acme.com", CompanyName: "Acme"},
+			b:       repository.Customer{Email: "jane@acme.com", CompanyName: "Acme Corp"},
+			want:    100,
+			signals: []string{IdentitySignalEmail, IdentitySignalCompanyName},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, signals := scoreIdentity(r.profile(&tt.a), r.profile(&tt.b))
+			if score != tt.want {
+				t.Errorf("expected score %d, got %d (%+v)", tt.want, score, signals)
+			}
+			if len(signals) != len(tt.signals) {
+				t.Fatalf("expected signals %v, got %+v", tt.signals, signals)
+			}
+			for i, s := range signals {
+				if s.Signal != tt.signals[i] {
+					t.Errorf("expected signal %d to be %s, got %s", i, tt.signals[i], s.Signal)
+				}
+			}
+		})
+	}
+}
@@ -7,26 +7,30 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/integration"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
 // IntegrationService handles integration management business logic.
 type IntegrationService struct {
-	connRepo     *repository.IntegrationConnectionRepository
-	oauthSvc     *StripeOAuthService
-	orchestrator *SyncOrchestratorService
+	connRepo   *repository.IntegrationConnectionRepository
+	runs       *repository.SyncRunRepository
+	providers  *integration.Registry
+	reconciler *ReconciliationService
 }
 
 // NewIntegrationService creates a new IntegrationService.
 func NewIntegrationService(
 	connRepo *repository.IntegrationConnectionRepository,
-	oauthSvc *StripeOAuthService,
-	orchestrator *SyncOrchestratorService,
+	runs *repository.SyncRunRepository,
+	providers *integration.Registry,
+	reconciler *ReconciliationService,
 ) *IntegrationService {
 	return &IntegrationService{
-		connRepo:     connRepo,
-		oauthSvc:     oauthSvc,
-		orchestrator: orchestrator,
+		connRepo:   connRepo,
+		runs:       runs,
+		providers:  providers,
+		reconciler: reconciler,
 	}
 }
 
@@ -40,13 +44,6 @@ type IntegrationSummary struct {
 	ConnectedAt   time.Time  `json:"connected_at"`
 }
 
-// IntegrationStatus holds detailed status for an integration.
-type IntegrationStatus struct {
-	IntegrationSummary
-	ExternalAccountID string   `json:"external_account_id"`
-	Scopes            []string `json:"scopes"`
-}
-
 // List returns all integration connections for an org.
 func (s *IntegrationService) List(ctx context.Context, orgID uuid.UUID) ([]IntegrationSummary, error) {
 	conns, err := s.connRepo.ListByOrg(ctx, orgID)
@@ -73,37 +70,65 @@ func (s *IntegrationService) List(ctx context.Context, orgID uuid.UUID) ([]Integ
 	return summaries, nil
 }
 
-// GetStatus returns detailed status for a specific integration provider.
-func (s *IntegrationService) GetStatus(ctx context.Context, orgID uuid.UUID, provider string) (*IntegrationStatus, error) {
-	conn, err := s.connRepo.GetByOrgAndProvider(ctx, orgID, provider)
-	if err != nil {
-		return nil, fmt.Errorf("get integration status: %w", err)
+// provider returns the registered provider with the given name.
+func (s *IntegrationService) provider(name string) (integration.Provider, error) {
+	p, ok := s.providers.Get(name)
+	if !ok {
+		return nil, &NotFoundError{Resource: "provider", Message: fmt.Sprintf("unknown integration provider %q", name)}
 	}
-	if conn == nil {
-		return nil, &NotFoundError{Resource: "integration", Message: fmt.Sprintf("no %s integration found", provider)}
+	return p, nil
+}
+
+// DisplayName returns the human-readable name of a provider, or the name
+// itself if no such provider is registered.
+func (s *IntegrationService) DisplayName(provider string) string {
+	if p, ok := s.providers.Get(provider); ok {
+		return p.DisplayName()
 	}
+	return provider
+}
 
-	count, err := s.connRepo.GetCustomerCountBySource(ctx, orgID, provider)
+// ConnectURL returns the OAuth authorization URL for a provider.
+func (s *IntegrationService) ConnectURL(orgID uuid.UUID, provider string) (string, error) {
+	p, err := s.provider(provider)
 	if err != nil {
-		return nil, fmt.Errorf("get customer count: %w", err)
+		return "", err
 	}
+	return p.ConnectURL(orgID)
+}
 
-	return &IntegrationStatus{
-		IntegrationSummary: IntegrationSummary{
-			Provider:      conn.Provider,
-			Status:        conn.Status,
-			LastSyncAt:    conn.LastSyncAt,
-			LastSyncError: conn.LastSyncError,
-			CustomerCount: count,
-			ConnectedAt:   conn.CreatedAt,
-		},
-		ExternalAccountID: conn.ExternalAccountID,
-		Scopes:            conn.Scopes,
-	}, nil
+// CompleteOAuth exchanges an OAuth code for tokens and starts the initial full sync.
+func (s *IntegrationService) CompleteOAuth(ctx context.Context, orgID uuid.UUID, provider, code, state string) error {
+	p, err := s.provider(provider)
+	if err != nil {
+		return err
+	}
+	if err := p.ExchangeCode(ctx, orgID, code, state); err != nil {
+		return err
+	}
+
+	// Fire async sync
+	go p.RunFullSync(context.Background(), orgID, SyncTriggerManual)
+
+	return nil
+}
+
+// GetStatus returns the provider-specific status of an integration.
+func (s *IntegrationService) GetStatus(ctx context.Context, orgID uuid.UUID, provider string) (any, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	return p.Status(ctx, orgID)
 }
 
 // TriggerSync triggers a sync for a specific integration provider.
 func (s *IntegrationService) TriggerSync(ctx context.Context, orgID uuid.UUID, provider string) error {
+	p, err := s.provider(provider)
+	if err != nil {
+		return err
+	}
+
 	conn, err := s.connRepo.GetByOrgAndProvider(ctx, orgID, provider)
 	if err != nil {
 		return fmt.Errorf("get integration: %w", err)
@@ -116,13 +141,62 @@ func (s *IntegrationService) TriggerSync(ctx context.Context, orgID uuid.UUID, p
 	}
 
 	// Fire async sync
-	go s.orchestrator.RunFullSync(context.Background(), orgID)
+	go p.RunFullSync(context.Background(), orgID, SyncTriggerManual)
 
 	return nil
 }
 
+// ListRuns returns the sync run history of a provider, newest first. A run in
+// progress reports its live per-step progress.
+func (s *IntegrationService) ListRuns(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.SyncRun, int, error) {
+	if _, err := s.provider(provider); err != nil {
+		return nil, 0, err
+	}
+
+	runs, total, err := s.runs.ListByOrgAndProvider(ctx, orgID, provider, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list sync runs: %w", err)
+	}
+	return runs, total, nil
+}
+
+// TriggerReconcile starts a reconciliation of an active integration against
+// its provider. With heal set, the drift it finds is repaired.
+func (s *IntegrationService) TriggerReconcile(ctx context.Context, orgID uuid.UUID, provider string, heal bool) error {
+	p, err := s.provider(provider)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.connRepo.GetByOrgAndProvider(ctx, orgID, provider)
+	if err != nil {
+		return fmt.Errorf("get integration: %w", err)
+	}
+	if conn == nil {
+		return &NotFoundError{Resource: "integration", Message: fmt.Sprintf("no %s integration found", provider)}
+	}
+	if conn.Status != "active" {
+		return &ValidationError{Field: "status", Message: "integration is not active"}
+	}
+
+	return s.reconciler.RunAsync(orgID, p, heal, SyncTriggerManual)
+}
+
+// ListReconciliations returns the reconciliation reports of a provider, newest first.
+func (s *IntegrationService) ListReconciliations(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.ReconciliationReport, int, error) {
+	if _, err := s.provider(provider); err != nil {
+		return nil, 0, err
+	}
+	return s.reconciler.List(ctx, orgID, provider, limit, offset)
+}
+
 // Disconnect removes an integration connection.
 func (s *IntegrationService) Disconnect(ctx context.Context, orgID uuid.UUID, provider string) error {
+	p, err := s.provider(provider)
+	if err != nil {
+		return err
+	}
+
 	conn, err := s.connRepo.GetByOrgAndProvider(ctx, orgID, provider)
 	if err != nil {
 		return fmt.Errorf("get integration: %w", err)
@@ -131,7 +205,7 @@ func (s *IntegrationService) Disconnect(ctx context.Context, orgID uuid.UUID, pr
 		return &NotFoundError{Resource: "integration", Message: fmt.Sprintf("no %s integration found", provider)}
 	}
 
-	if err := s.connRepo.Delete(ctx, orgID, provider); err != nil {
+	if err := p.Disconnect(ctx, orgID); err != nil {
 		return fmt.Errorf("delete integration: %w", err)
 	}
 
@@ -6,24 +6,43 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-
-	"golang.org/x/time/rate"
+	"strconv"
+	"time"
 )
 
 const intercomBaseURL = "https://api.intercom.io"
 
 // IntercomClient provides rate-limited access to the Intercom API.
 type IntercomClient struct {
-	client  *http.Client
-	limiter *rate.Limiter
+	client *http.Client
+}
+
+// NewIntercomClient creates a new IntercomClient that sends requests through transport.
+func NewIntercomClient(transport *ProviderTransport) *IntercomClient {
+	return &IntercomClient{client: transport.Client()}
 }
 
-// NewIntercomClient creates a new IntercomClient with rate limiting.
-func NewIntercomClient() *IntercomClient {
-	return &IntercomClient{
-		client:  &http.Client{},
-		limiter: rate.NewLimiter(rate.Limit(15), 15), // ~1000 req/min
+// NewIntercomTransport creates the provider transport for the Intercom API.
+func NewIntercomTransport() *ProviderTransport {
+	return NewProviderTransport("intercom", ProviderTransportConfig{
+		RequestsPerSecond: 15, // ~1000 req/min
+		Burst:             15,
+		MaxRetries:        4,
+		QuotaExhausted:    intercomQuotaExhausted,
+	})
+}
+
+// intercomQuotaExhausted pauses a connection until its rate-limit window
+// resets once Intercom reports no requests remaining.
+func intercomQuotaExhausted(h http.Header) (time.Duration, bool) {
+	if h.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
 	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Second, true
+	}
+	return max(time.Until(time.Unix(reset, 0)), 0), true
 }
 
 // IntercomContactListResponse represents the Intercom contacts list API response.
@@ -167,10 +186,6 @@ func (c *IntercomClient) ListConversationsUpdatedSince(ctx context.Context, acce
 }
 
 func intercomGet[T any](ctx context.Context, c *IntercomClient, url, accessToken string) (*T, error) {
-	if err := c.limiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -191,7 +206,7 @@ func intercomGet[T any](ctx context.Context, c *IntercomClient, url, accessToken
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("intercom api error: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, &ProviderAPIError{Provider: "intercom", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var result T
@@ -200,3 +215,10 @@ func intercomGet[T any](ctx context.Context, c *IntercomClient, url, accessToken
 	}
 	return &result, nil
 }
+
+// CheckAccess lists one page of contacts to verify accessToken works. A
+// rejected token is reported as integration.ErrReauthRequired.
+func (c *IntercomClient) CheckAccess(ctx context.Context, accessToken string) error {
+	_, err := c.ListContacts(ctx, accessToken, "")
+	return reauthOnUnauthorized(err)
+}
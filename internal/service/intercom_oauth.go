@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/keyring"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
@@ -21,18 +22,20 @@ type IntercomOAuthConfig struct {
 	ClientID         string
 	ClientSecret     string
 	OAuthRedirectURL string
-	EncryptionKey    string // 32-byte hex-encoded AES key
+	EncryptionKey    string // 32-byte hex-encoded AES key; only reads legacy tokens once Keyring is set
+	Keyring          *keyring.Keyring
 }
 
 // IntercomOAuthService handles Intercom OAuth connect flow.
 type IntercomOAuthService struct {
 	cfg      IntercomOAuthConfig
 	connRepo *repository.IntegrationConnectionRepository
+	tokens   *TokenCipher
 }
 
 // NewIntercomOAuthService creates a new IntercomOAuthService.
 func NewIntercomOAuthService(cfg IntercomOAuthConfig, connRepo *repository.IntegrationConnectionRepository) *IntercomOAuthService {
-	return &IntercomOAuthService{cfg: cfg, connRepo: connRepo}
+	return &IntercomOAuthService{cfg: cfg, connRepo: connRepo, tokens: NewTokenCipher(cfg.Keyring, cfg.EncryptionKey)}
 }
 
 // ConnectURL generates the Intercom OAuth authorization URL.
@@ -74,7 +77,7 @@ func (s *IntercomOAuthService) ExchangeCode(ctx context.Context, orgID uuid.UUID
 		return fmt.Errorf("exchange code with intercom: %w", err)
 	}
 
-	encrypted, err := encryptToken(tokenResp.AccessToken, s.cfg.EncryptionKey)
+	encrypted, err := s.tokens.Encrypt(tokenResp.AccessToken)
 	if err != nil {
 		return fmt.Errorf("encrypt access token: %w", err)
 	}
@@ -112,7 +115,7 @@ func (s *IntercomOAuthService) GetAccessToken(ctx context.Context, orgID uuid.UU
 		return "", &ValidationError{Field: "intercom", Message: "Intercom connection is not active"}
 	}
 
-	token, err := decryptToken(conn.AccessTokenEncrypted, s.cfg.EncryptionKey)
+	token, err := s.tokens.Decrypt(conn.AccessTokenEncrypted)
 	if err != nil {
 		return "", fmt.Errorf("decrypt access token: %w", err)
 	}
@@ -130,13 +133,17 @@ func (s *IntercomOAuthService) GetStatus(ctx context.Context, orgID uuid.UUID) (
 		return &IntercomConnectionStatus{Status: "disconnected"}, nil
 	}
 
-	return &IntercomConnectionStatus{
+	status := &IntercomConnectionStatus{
 		Status:            conn.Status,
 		ExternalAccountID: conn.ExternalAccountID,
 		LastSyncAt:        conn.LastSyncAt,
 		LastSyncError:     conn.LastSyncError,
 		ConnectedAt:       conn.CreatedAt,
-	}, nil
+	}
+	if conn.Status == "needs_reauth" {
+		status.ReconnectURL, _ = s.ConnectURL(orgID)
+	}
+	return status, nil
 }
 
 // Disconnect removes an Intercom connection.
@@ -151,6 +158,7 @@ type IntercomConnectionStatus struct {
 	LastSyncAt        *time.Time `json:"last_sync_at,omitempty"`
 	LastSyncError     string     `json:"last_sync_error,omitempty"`
 	ConnectedAt       time.Time  `json:"connected_at,omitempty"`
+	ReconnectURL      string     `json:"reconnect_url,omitempty"`
 	ConversationCount int        `json:"conversation_count,omitempty"`
 	ContactCount      int        `json:"contact_count,omitempty"`
 }
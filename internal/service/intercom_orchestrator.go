@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/integration"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
@@ -16,6 +19,7 @@ type IntercomSyncResult struct {
 	Contacts      *SyncProgress        `json:"contacts"`
 	Conversations *SyncProgress        `json:"conversations"`
 	Deduplicated  *DeduplicationResult `json:"deduplicated,omitempty"`
+	Paused        bool                 `json:"paused,omitempty"`
 	Duration      string               `json:"duration"`
 	Errors        []string             `json:"errors,omitempty"`
 }
@@ -23,56 +27,72 @@ type IntercomSyncResult struct {
 // IntercomSyncOrchestratorService orchestrates the full Intercom sync pipeline.
 type IntercomSyncOrchestratorService struct {
 	connRepo *repository.IntegrationConnectionRepository
+	runs     *repository.SyncRunRepository
 	syncSvc  *IntercomSyncService
-	mergeSvc *CustomerMergeService
+	resolver *IdentityResolver
 }
 
 // NewIntercomSyncOrchestratorService creates a new IntercomSyncOrchestratorService.
 func NewIntercomSyncOrchestratorService(
 	connRepo *repository.IntegrationConnectionRepository,
+	runs *repository.SyncRunRepository,
 	syncSvc *IntercomSyncService,
-	mergeSvc *CustomerMergeService,
+	resolver *IdentityResolver,
 ) *IntercomSyncOrchestratorService {
 	return &IntercomSyncOrchestratorService{
 		connRepo: connRepo,
+		runs:     runs,
 		syncSvc:  syncSvc,
-		mergeSvc: mergeSvc,
+		resolver: resolver,
 	}
 }
 
-// RunFullSync runs the complete Intercom sync pipeline for an org.
-func (s *IntercomSyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UUID) *IntercomSyncResult {
+// RunFullSync runs the complete Intercom sync pipeline for an org and records it as a sync run.
+func (s *IntercomSyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) *IntercomSyncResult {
 	start := time.Now()
 	result := &IntercomSyncResult{}
 
+	ctx, run := startSyncRun(ctx, s.runs, orgID, "intercom", "full", trigger)
+	defer func() { run.finish(ctx, strings.Join(result.Errors, "; ")) }()
+
 	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "intercom", "syncing", nil); err != nil {
 		slog.Error("failed to update intercom sync status", "error", err)
 	}
 
 	// Step 1: Sync contacts
-	contactProgress, err := s.syncSvc.SyncContacts(ctx, orgID)
+	contactProgress, err := s.syncSvc.RunFullSyncStep(ctx, orgID, intercomStepContacts)
 	result.Contacts = contactProgress
+	run.record(contactProgress)
+	if errors.Is(err, errSyncPaused) {
+		return s.pauseFullSync(ctx, orgID, run, result, start)
+	}
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("contact sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
 	}
 
 	// Step 2: Sync conversations
-	convProgress, err := s.syncSvc.SyncConversations(ctx, orgID)
+	convProgress, err := s.syncSvc.RunFullSyncStep(ctx, orgID, intercomStepConversations)
 	result.Conversations = convProgress
+	run.record(convProgress)
+	if errors.Is(err, errSyncPaused) {
+		return s.pauseFullSync(ctx, orgID, run, result, start)
+	}
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("conversation sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
 	}
 
-	// Step 3: Deduplication
-	dedupResult, err := s.mergeSvc.DeduplicateCustomers(ctx, orgID)
+	// Step 3: Identity resolution (merge or queue duplicates)
+	dedupResult, err := s.resolver.Resolve(ctx, orgID)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("dedup: %v", err))
 	} else {
 		result.Deduplicated = dedupResult
 	}
 
+	s.syncSvc.CompleteFullSync(ctx, orgID)
+
 	// Mark sync complete
 	now := time.Now()
 	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "intercom", "active", &now); err != nil {
@@ -90,11 +110,15 @@ func (s *IntercomSyncOrchestratorService) RunFullSync(ctx context.Context, orgID
 	return result
 }
 
-// RunIncrementalSync runs an incremental Intercom sync for records modified since the given time.
-func (s *IntercomSyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time) *IntercomSyncResult {
+// RunIncrementalSync runs an incremental Intercom sync for records modified since the given time
+// and records it as a sync run.
+func (s *IntercomSyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) *IntercomSyncResult {
 	start := time.Now()
 	result := &IntercomSyncResult{}
 
+	ctx, run := startSyncRun(ctx, s.runs, orgID, "intercom", "incremental", trigger)
+	defer func() { run.finish(ctx, strings.Join(result.Errors, "; ")) }()
+
 	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "intercom", "syncing", nil); err != nil {
 		slog.Error("failed to update intercom sync status", "error", err)
 	}
@@ -102,6 +126,7 @@ func (s *IntercomSyncOrchestratorService) RunIncrementalSync(ctx context.Context
 	// Step 1: Sync contacts modified since
 	contactProgress, err := s.syncSvc.SyncContactsSince(ctx, orgID, since)
 	result.Contacts = contactProgress
+	run.record(contactProgress)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("incremental contact sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
@@ -110,13 +135,14 @@ func (s *IntercomSyncOrchestratorService) RunIncrementalSync(ctx context.Context
 	// Step 2: Sync conversations modified since
 	convProgress, err := s.syncSvc.SyncConversationsSince(ctx, orgID, since)
 	result.Conversations = convProgress
+	run.record(convProgress)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("incremental conversation sync: %v", err))
 		s.markSyncError(ctx, orgID, err.Error())
 	}
 
-	// Step 3: Deduplication for any new records
-	dedupResult, err := s.mergeSvc.DeduplicateCustomers(ctx, orgID)
+	// Step 3: Identity resolution for any new records
+	dedupResult, err := s.resolver.Resolve(ctx, orgID)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("dedup: %v", err))
 	} else {
@@ -140,6 +166,36 @@ func (s *IntercomSyncOrchestratorService) RunIncrementalSync(ctx context.Context
 	return result
 }
 
+// Reconcile compares local Intercom contacts with Intercom.
+func (s *IntercomSyncOrchestratorService) Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error) {
+	report := &integration.DriftReport{}
+	if err := s.syncSvc.ReconcileContacts(ctx, orgID, heal, report); err != nil {
+		return report, fmt.Errorf("reconcile contacts: %w", err)
+	}
+	return report, nil
+}
+
+// pauseFullSync ends a full sync that stopped at a checkpoint to stay within
+// its time budget. Later steps are skipped; the next run resumes the sync,
+// skipping the steps this one finished.
+func (s *IntercomSyncOrchestratorService) pauseFullSync(ctx context.Context, orgID uuid.UUID, run *syncRunTracker, result *IntercomSyncResult, start time.Time) *IntercomSyncResult {
+	run.pause()
+	result.Paused = true
+
+	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "intercom", "active", nil); err != nil {
+		slog.Error("failed to update intercom sync status", "error", err)
+	}
+
+	result.Duration = time.Since(start).String()
+
+	slog.Info("intercom full sync paused at checkpoint",
+		"org_id", orgID,
+		"duration", result.Duration,
+	)
+
+	return result
+}
+
 func (s *IntercomSyncOrchestratorService) markSyncError(ctx context.Context, orgID uuid.UUID, errMsg string) {
 	if err := s.connRepo.UpdateErrorCount(ctx, orgID, "intercom", errMsg); err != nil {
 		slog.Error("failed to update intercom error count", "error", err)
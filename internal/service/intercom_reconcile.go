@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// ReconcileContacts compares the org's local Intercom contacts with Intercom
+// and adds what differs to report. With heal set, missing and stale contacts
+// are upserted along with their customers, and orphaned contacts are deleted
+// and their customers soft-deleted.
+func (s *IntercomSyncService) ReconcileContacts(ctx context.Context, orgID uuid.UUID, heal bool, report *integration.DriftReport) error {
+	accessToken, err := s.oauthSvc.GetAccessToken(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("get access token: %w", err)
+	}
+
+	localContacts, err := s.contacts.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("list local contacts: %w", err)
+	}
+	local := make(map[string]repository.IntercomContact, len(localContacts))
+	for _, c := range localContacts {
+		local[c.IntercomContactID] = c
+	}
+
+	seen := make(map[string]bool, len(localContacts))
+	cursor := ""
+	for {
+		resp, err := s.client.ListContacts(ctx, accessToken, cursor)
+		if err != nil {
+			return fmt.Errorf("list contacts: %w", err)
+		}
+
+		for _, c := range resp.Data {
+			seen[c.ID] = true
+			report.Checked++
+
+			var item integration.DriftItem
+			existing, ok := local[c.ID]
+			if !ok {
+				item = integration.DriftItem{Resource: "contact", ExternalID: c.ID, Kind: integration.DriftMissing}
+			} else {
+				diff := contactDiff(existing, c)
+				if diff == "" {
+					continue
+				}
+				item = integration.DriftItem{Resource: "contact", ExternalID: c.ID, Kind: integration.DriftStale, Detail: diff}
+			}
+
+			if heal {
+				if err := s.upsertContactAndCustomer(ctx, orgID, c, true); err == nil {
+					item.Healed = true
+				}
+			}
+			report.Add(item)
+		}
+
+		cursor = nextIntercomCursor(resp.Pages)
+		if cursor == "" {
+			break
+		}
+	}
+
+	for _, c := range localContacts {
+		if seen[c.IntercomContactID] {
+			continue
+		}
+		item := integration.DriftItem{
+			Resource:   "contact",
+			ExternalID: c.IntercomContactID,
+			Kind:       integration.DriftOrphaned,
+			Detail:     "not found in Intercom",
+		}
+		if heal {
+			if err := s.removeContact(ctx, orgID, c.IntercomContactID); err != nil {
+				slog.Error("reconcile: failed to remove orphaned intercom contact", "intercom_id", c.IntercomContactID, "error", err)
+			} else {
+				item.Healed = true
+			}
+		}
+		report.Add(item)
+	}
+
+	return nil
+}
+
+func (s *IntercomSyncService) removeContact(ctx context.Context, orgID uuid.UUID, intercomContactID string) error {
+	if err := s.customers.SoftDelete(ctx, orgID, "intercom", intercomContactID); err != nil {
+		return err
+	}
+	return s.contacts.DeleteByIntercomID(ctx, orgID, intercomContactID)
+}
+
+// contactDiff describes the fields that differ between a stored contact and
+// the one Intercom returned, or "" when they match.
+func contactDiff(local repository.IntercomContact, remote IntercomAPIContact) string {
+	var diffs []string
+	if local.Email != remote.Email {
+		diffs = append(diffs, fmt.Sprintf("email %q → %q", local.Email, remote.Email))
+	}
+	if local.Name != remote.Name {
+		diffs = append(diffs, fmt.Sprintf("name %q → %q", local.Name, remote.Name))
+	}
+	if local.Role != remote.Role {
+		diffs = append(diffs, fmt.Sprintf("role %s → %s", local.Role, remote.Role))
+	}
+	if local.IntercomCompanyID != remote.CompanyID {
+		diffs = append(diffs, fmt.Sprintf("company %s → %s", local.IntercomCompanyID, remote.CompanyID))
+	}
+	return strings.Join(diffs, ", ")
+}
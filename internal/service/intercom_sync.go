@@ -19,6 +19,7 @@ type IntercomSyncService struct {
 	conversations *repository.IntercomConversationRepository
 	customers     *repository.CustomerRepository
 	events        *repository.CustomerEventRepository
+	checkpoints   syncCheckpointer
 }
 
 type intercomContactPageFetcher func(ctx context.Context, accessToken, cursor string) (*IntercomContactListResponse, error)
@@ -32,6 +33,7 @@ func NewIntercomSyncService(
 	conversations *repository.IntercomConversationRepository,
 	customers *repository.CustomerRepository,
 	events *repository.CustomerEventRepository,
+	checkpoints *repository.SyncCheckpointRepository,
 ) *IntercomSyncService {
 	return &IntercomSyncService{
 		oauthSvc:      oauthSvc,
@@ -40,12 +42,41 @@ func NewIntercomSyncService(
 		conversations: conversations,
 		customers:     customers,
 		events:        events,
+		checkpoints:   newSyncCheckpointer(checkpoints, "intercom"),
 	}
 }
 
+// Intercom full sync steps, in the order a full sync runs them.
+const (
+	intercomStepContacts      = "contacts"
+	intercomStepConversations = "conversations"
+)
+
+// RunFullSyncStep runs one step of a full sync. A step that a paused full sync
+// already finished is skipped and returns nil progress; a step that finishes
+// is recorded so a resumed full sync skips it.
+func (s *IntercomSyncService) RunFullSyncStep(ctx context.Context, orgID uuid.UUID, step string) (*SyncProgress, error) {
+	var sync func(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error)
+	switch step {
+	case intercomStepContacts:
+		sync = s.SyncContacts
+	case intercomStepConversations:
+		sync = s.SyncConversations
+	default:
+		return nil, fmt.Errorf("unknown intercom sync step %q", step)
+	}
+	return s.checkpoints.runStep(ctx, orgID, step, sync)
+}
+
+// CompleteFullSync forgets the steps a paused full sync finished, once the
+// full sync has run them all.
+func (s *IntercomSyncService) CompleteFullSync(ctx context.Context, orgID uuid.UUID) {
+	s.checkpoints.clearSteps(ctx, orgID, intercomStepContacts, intercomStepConversations)
+}
+
 // SyncContacts fetches all contacts from Intercom and upserts them locally.
 func (s *IntercomSyncService) SyncContacts(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error) {
-	return s.syncContacts(ctx, orgID, "intercom_contacts", "list contacts", true, true, s.client.ListContacts)
+	return s.syncContacts(ctx, orgID, "intercom_contacts", "contacts", "list contacts", true, true, s.client.ListContacts)
 }
 
 // SyncConversations fetches all conversations from Intercom and upserts them locally.
@@ -54,6 +85,7 @@ func (s *IntercomSyncService) SyncConversations(ctx context.Context, orgID uuid.
 		ctx,
 		orgID,
 		"intercom_conversations",
+		"conversations",
 		"list conversations",
 		true,
 		true,
@@ -72,6 +104,7 @@ func (s *IntercomSyncService) SyncContactsSince(ctx context.Context, orgID uuid.
 		ctx,
 		orgID,
 		"intercom_contacts_incremental",
+		"",
 		"list contacts since",
 		false,
 		false,
@@ -83,6 +116,7 @@ func (s *IntercomSyncService) syncContacts(
 	ctx context.Context,
 	orgID uuid.UUID,
 	step string,
+	checkpoint string,
 	listErrorPrefix string,
 	logUpsertErrors bool,
 	logCompletion bool,
@@ -94,16 +128,20 @@ func (s *IntercomSyncService) syncContacts(
 	}
 
 	progress := &SyncProgress{Step: step}
-	cursor := ""
+	cursor := s.checkpoints.load(ctx, orgID, checkpoint)
 
 	for {
 		resp, err := fetchPage(ctx, accessToken, cursor)
 		if err != nil {
 			return progress, fmt.Errorf("%s: %w", listErrorPrefix, err)
 		}
+		if resp.TotalCount > progress.Expected {
+			progress.Expected = resp.TotalCount
+		}
 
 		for _, c := range resp.Data {
 			progress.Total++
+			reportSyncProgress(ctx, progress)
 
 			if err := s.upsertContactAndCustomer(ctx, orgID, c, logUpsertErrors); err != nil {
 				progress.Errors++
@@ -118,7 +156,13 @@ func (s *IntercomSyncService) syncContacts(
 			break
 		}
 		cursor = nextCursor
+		s.checkpoints.save(ctx, orgID, checkpoint, cursor)
+
+		if s.checkpoints.shouldPause(ctx, checkpoint) {
+			return progress, errSyncPaused
+		}
 	}
+	s.checkpoints.clear(ctx, orgID, checkpoint)
 
 	if logCompletion {
 		slog.Info("intercom contact sync complete",
@@ -161,8 +205,9 @@ func (s *IntercomSyncService) upsertContactAndCustomer(ctx context.Context, orgI
 		LastSeenAt:  &now,
 		Metadata: map[string]any{
 			"intercom": map[string]any{
-				"role":       c.Role,
-				"company_id": c.CompanyID,
+				"role":        c.Role,
+				"company_id":  c.CompanyID,
+				"external_id": c.ExternalID,
 			},
 		},
 	}
@@ -191,6 +236,7 @@ func (s *IntercomSyncService) SyncConversationsSince(ctx context.Context, orgID
 		ctx,
 		orgID,
 		"intercom_conversations_incremental",
+		"",
 		"list conversations since",
 		false,
 		false,
@@ -203,6 +249,7 @@ func (s *IntercomSyncService) syncConversations(
 	ctx context.Context,
 	orgID uuid.UUID,
 	step string,
+	checkpoint string,
 	listErrorPrefix string,
 	emitEvents bool,
 	logUpsertErrors bool,
@@ -215,16 +262,20 @@ func (s *IntercomSyncService) syncConversations(
 	}
 
 	progress := &SyncProgress{Step: step}
-	cursor := ""
+	cursor := s.checkpoints.load(ctx, orgID, checkpoint)
 
 	for {
 		resp, err := fetchPage(ctx, accessToken, cursor)
 		if err != nil {
 			return progress, fmt.Errorf("%s: %w", listErrorPrefix, err)
 		}
+		if resp.TotalCount > progress.Expected {
+			progress.Expected = resp.TotalCount
+		}
 
 		for _, conv := range resp.Conversations {
 			progress.Total++
+			reportSyncProgress(ctx, progress)
 
 			if err := s.upsertConversation(ctx, orgID, conv, emitEvents); err != nil {
 				if logUpsertErrors {
@@ -242,7 +293,13 @@ func (s *IntercomSyncService) syncConversations(
 			break
 		}
 		cursor = nextCursor
+		s.checkpoints.save(ctx, orgID, checkpoint, cursor)
+
+		if s.checkpoints.shouldPause(ctx, checkpoint) {
+			return progress, errSyncPaused
+		}
 	}
+	s.checkpoints.clear(ctx, orgID, checkpoint)
 
 	if logCompletion {
 		slog.Info("intercom conversation sync complete",
@@ -5,8 +5,9 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"log/slog"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -40,9 +41,7 @@ type IntercomWebhookService struct {
 	contacts      *repository.IntercomContactRepository
 	conversations *repository.IntercomConversationRepository
 	events        *repository.CustomerEventRepository
-
-	processedEvents map[string]time.Time
-	mu              sync.Mutex
+	inbox         *WebhookInboxService
 }
 
 // NewIntercomWebhookService creates a new IntercomWebhookService.
@@ -54,16 +53,17 @@ func NewIntercomWebhookService(
 	contacts *repository.IntercomContactRepository,
 	conversations *repository.IntercomConversationRepository,
 	events *repository.CustomerEventRepository,
+	inbox *WebhookInboxService,
 ) *IntercomWebhookService {
 	return &IntercomWebhookService{
-		webhookSecret:   webhookSecret,
-		syncSvc:         syncSvc,
-		mergeSvc:        mergeSvc,
-		connRepo:        connRepo,
-		contacts:        contacts,
-		conversations:   conversations,
-		events:          events,
-		processedEvents: make(map[string]time.Time),
+		webhookSecret: webhookSecret,
+		syncSvc:       syncSvc,
+		mergeSvc:      mergeSvc,
+		connRepo:      connRepo,
+		contacts:      contacts,
+		conversations: conversations,
+		events:        events,
+		inbox:         inbox,
 	}
 }
 
@@ -85,35 +85,38 @@ func (s *IntercomWebhookService) VerifySignature(requestBody []byte, signatureHe
 	return nil
 }
 
-// ProcessEvent processes a single Intercom webhook event.
-func (s *IntercomWebhookService) ProcessEvent(ctx context.Context, event IntercomWebhookEvent) error {
-	if s.isProcessed(event.ID) {
-		slog.Debug("duplicate intercom webhook event skipped", "event_id", event.ID)
+// ProcessEvent stores a verified Intercom webhook event in the webhook inbox.
+// The event is processed asynchronously by ProcessPayload.
+func (s *IntercomWebhookService) ProcessEvent(ctx context.Context, event IntercomWebhookEvent, payload []byte) error {
+	conn, err := s.connRepo.GetByProviderAndExternalID(ctx, "intercom", event.AppID)
+	if err != nil {
+		return fmt.Errorf("lookup intercom connection: %w", err)
+	}
+	if conn == nil {
+		slog.Warn("no intercom connection for app", "app_id", event.AppID)
 		return nil
 	}
 
-	// Find the org for this app
+	return s.inbox.Enqueue(ctx, "intercom", event.ID, event.Topic, &conn.OrgID, payload)
+}
+
+// ProcessPayload processes a stored Intercom webhook event.
+func (s *IntercomWebhookService) ProcessPayload(ctx context.Context, payload []byte) error {
+	var event IntercomWebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("decode intercom event: %w", err)
+	}
+
 	conn, err := s.connRepo.GetByProviderAndExternalID(ctx, "intercom", event.AppID)
 	if err != nil {
-		slog.Error("failed to lookup intercom connection", "app_id", event.AppID, "error", err)
-		return err
+		return fmt.Errorf("lookup intercom connection: %w", err)
 	}
 	if conn == nil {
 		slog.Warn("no intercom connection for app", "app_id", event.AppID)
 		return nil
 	}
 
-	if err := s.processEventForOrg(ctx, conn.OrgID, event); err != nil {
-		slog.Error("failed to process intercom event",
-			"event_id", event.ID,
-			"topic", event.Topic,
-			"error", err,
-		)
-		return err
-	}
-
-	s.markProcessed(event.ID)
-	return nil
+	return s.processEventForOrg(ctx, conn.OrgID, event)
 }
 
 func (s *IntercomWebhookService) processEventForOrg(ctx context.Context, orgID uuid.UUID, event IntercomWebhookEvent) error {
@@ -331,28 +334,3 @@ func extractIntercomContactID(item map[string]any) string {
 	id, _ := first["id"].(string)
 	return id
 }
-
-func (s *IntercomWebhookService) isProcessed(eventID string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, exists := s.processedEvents[eventID]; exists {
-		return true
-	}
-
-	// Clean up old entries (older than 1 hour)
-	cutoff := time.Now().Add(-1 * time.Hour)
-	for id, t := range s.processedEvents {
-		if t.Before(cutoff) {
-			delete(s.processedEvents, id)
-		}
-	}
-
-	return false
-}
-
-func (s *IntercomWebhookService) markProcessed(eventID string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.processedEvents[eventID] = time.Now()
-}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/integration"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
@@ -41,15 +42,9 @@ func (s *MRRService) CalculateForCustomer(ctx context.Context, customerID uuid.U
 		return nil
 	}
 
-	activeSubs, err := s.subs.ListActiveByCustomer(ctx, customerID)
+	newMRR, err := s.ExpectedForCustomer(ctx, customerID)
 	if err != nil {
-		return fmt.Errorf("list active subscriptions: %w", err)
-	}
-
-	newMRR := 0
-	for _, sub := range activeSubs {
-		monthlyAmount := normalizeToMonthly(sub.AmountCents, sub.Interval, sub.Status)
-		newMRR += monthlyAmount
+		return err
 	}
 
 	oldMRR := customer.MRRCents
@@ -84,6 +79,57 @@ func (s *MRRService) CalculateForCustomer(ctx context.Context, customerID uuid.U
 	return nil
 }
 
+// ExpectedForCustomer returns the MRR a customer's active subscriptions add up to.
+func (s *MRRService) ExpectedForCustomer(ctx context.Context, customerID uuid.UUID) (int, error) {
+	activeSubs, err := s.subs.ListActiveByCustomer(ctx, customerID)
+	if err != nil {
+		return 0, fmt.Errorf("list active subscriptions: %w", err)
+	}
+
+	mrr := 0
+	for _, sub := range activeSubs {
+		mrr += normalizeToMonthly(sub.AmountCents, sub.Interval, sub.Status)
+	}
+	return mrr, nil
+}
+
+// Reconcile compares each customer's stored MRR with what their active
+// subscriptions add up to and adds mismatches to report. With heal set the
+// stored MRR is recalculated.
+func (s *MRRService) Reconcile(ctx context.Context, orgID uuid.UUID, heal bool, report *integration.DriftReport) error {
+	customers, err := s.customers.ListByOrg(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("list customers: %w", err)
+	}
+
+	for _, c := range customers {
+		expected, err := s.ExpectedForCustomer(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+		report.Checked++
+		if expected == c.MRRCents {
+			continue
+		}
+
+		item := integration.DriftItem{
+			Resource:   "customer_mrr",
+			ExternalID: c.ExternalID,
+			Kind:       integration.DriftStale,
+			Detail:     fmt.Sprintf("mrr_cents %d, subscriptions add up to %d", c.MRRCents, expected),
+		}
+		if heal {
+			if err := s.CalculateForCustomer(ctx, c.ID); err != nil {
+				slog.Error("reconcile: failed to recalculate MRR", "customer_id", c.ID, "error", err)
+			} else {
+				item.Healed = true
+			}
+		}
+		report.Add(item)
+	}
+	return nil
+}
+
 // CalculateForOrg recalculates MRR for all customers in an org.
 func (s *MRRService) CalculateForOrg(ctx context.Context, orgID uuid.UUID) error {
 	customers, err := s.customers.ListByOrg(ctx, orgID)
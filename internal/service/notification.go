@@ -67,11 +67,16 @@ func (s *NotificationService) CreateForAlert(ctx context.Context, match AlertMat
 			Message: s.buildMessage(match),
 			Data: map[string]any{
 				"alert_rule_id": match.Rule.ID,
-				"customer_id":   match.Customer.ID,
-				"customer_name": match.Customer.Name,
 				"trigger_data":  match.TriggerData,
 			},
 		}
+		if match.Account != nil {
+			notif.Data["account_id"] = match.Account.ID
+			notif.Data["account_name"] = match.Account.Name
+		} else {
+			notif.Data["customer_id"] = match.Customer.ID
+			notif.Data["customer_name"] = match.Customer.Name
+		}
 
 		if err := s.notifRepo.Create(ctx, notif); err != nil {
 			slog.Error("notification: create failed", "user_id", user.ID, "error", err)
@@ -91,8 +96,107 @@ func (s *NotificationService) buildMessage(match AlertMatch) string {
 	case "risk_change":
 		newLevel, _ := match.TriggerData["new_risk_level"].(string)
 		return fmt.Sprintf("%s risk level changed to %s", match.Customer.Name, newLevel)
+	case "custom_field":
+		label, _ := match.TriggerData["label"].(string)
+		op, _ := match.TriggerData["op"].(string)
+		return fmt.Sprintf("%s for %s %s", label, match.Customer.Name, customFieldConditionText(op, match.TriggerData["value"]))
+	case "renewal_upcoming":
+		date, _ := match.TriggerData["renewal_date"].(string)
+		days, _ := match.TriggerData["days_until"].(int)
+		return fmt.Sprintf("%s renews on %s (in %d days)", match.Customer.Name, date, days)
+	case "account_score_below":
+		score, _ := match.TriggerData["score"].(int)
+		threshold, _ := match.TriggerData["threshold"].(int)
+		return fmt.Sprintf("Account %s health score (%d) dropped below threshold (%d)", match.Account.Name, score, threshold)
+	case "account_risk_change":
+		newLevel, _ := match.TriggerData["new_level"].(string)
+		return fmt.Sprintf("Account %s risk level changed to %s", match.Account.Name, newLevel)
 	default:
-		return fmt.Sprintf("Alert triggered for %s", match.Customer.Name)
+		return fmt.Sprintf("Alert triggered for %s", match.subjectName())
+	}
+}
+
+// CreateForMention notifies the members mentioned in a customer note. Members
+// with in-app notifications turned off are skipped.
+func (s *NotificationService) CreateForMention(ctx context.Context, note *repository.CustomerNote, customerName string, userIDs []uuid.UUID) {
+	author := note.AuthorName
+	if author == "" {
+		author = "Someone"
+	}
+
+	for _, userID := range userIDs {
+		pref, err := s.prefSvc.Get(ctx, userID, note.OrgID)
+		if err == nil && !pref.InAppEnabled {
+			continue
+		}
+
+		notif := &repository.Notification{
+			UserID:  userID,
+			OrgID:   note.OrgID,
+			Type:    "note_mention",
+			Title:   fmt.Sprintf("%s mentioned you", author),
+			Message: fmt.Sprintf("%s mentioned you in a note on %s", author, customerName),
+			Data: map[string]any{
+				"note_id":       note.ID,
+				"customer_id":   note.CustomerID,
+				"customer_name": customerName,
+			},
+		}
+		if err := s.notifRepo.Create(ctx, notif); err != nil {
+			slog.Error("notification: create failed", "user_id", userID, "error", err)
+		}
+	}
+}
+
+// Task notification types.
+const (
+	NotificationTaskAssigned = "task_assigned"
+	NotificationTaskOverdue  = "task_overdue"
+)
+
+// CreateForTask notifies a task's assignee that the task was assigned to them
+// or is overdue. Assignees with in-app notifications turned off are skipped.
+func (s *NotificationService) CreateForTask(ctx context.Context, task *repository.Task, notifType string) {
+	if task.AssigneeID == nil {
+		return
+	}
+
+	pref, err := s.prefSvc.Get(ctx, *task.AssigneeID, task.OrgID)
+	if err == nil && !pref.InAppEnabled {
+		return
+	}
+
+	title := fmt.Sprintf("Task assigned: %s", task.Title)
+	message := fmt.Sprintf("You were assigned %q", task.Title)
+	if notifType == NotificationTaskOverdue {
+		title = fmt.Sprintf("Task overdue: %s", task.Title)
+		message = fmt.Sprintf("%q is past its due date", task.Title)
+	}
+	if task.CustomerName != "" {
+		message += fmt.Sprintf(" for %s", task.CustomerName)
+	}
+
+	notif := &repository.Notification{
+		UserID:  *task.AssigneeID,
+		OrgID:   task.OrgID,
+		Type:    notifType,
+		Title:   title,
+		Message: message,
+		Data: map[string]any{
+			"task_id": task.ID,
+			"due_at":  task.DueAt,
+		},
+	}
+	if task.CustomerID != nil {
+		notif.Data["customer_id"] = *task.CustomerID
+		notif.Data["customer_name"] = task.CustomerName
+	}
+	if task.AlertHistoryID != nil {
+		notif.Data["alert_history_id"] = *task.AlertHistoryID
+	}
+
+	if err := s.notifRepo.Create(ctx, notif); err != nil {
+		slog.Error("notification: create failed", "user_id", *task.AssigneeID, "error", err)
 	}
 }
 
@@ -10,9 +10,16 @@ import (
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
+// notificationPreferenceStore is the part of NotificationPreferenceRepository
+// the service uses.
+type notificationPreferenceStore interface {
+	GetByUserAndOrg(ctx context.Context, userID, orgID uuid.UUID) (*repository.NotificationPreference, error)
+	Upsert(ctx context.Context, pref *repository.NotificationPreference) error
+}
+
 // NotificationPreferenceService handles notification preference business logic.
 type NotificationPreferenceService struct {
-	prefRepo *repository.NotificationPreferenceRepository
+	prefRepo notificationPreferenceStore
 }
 
 // NewNotificationPreferenceService creates a new NotificationPreferenceService.
@@ -27,6 +34,11 @@ type UpdatePreferencesRequest struct {
 	DigestEnabled   *bool        `json:"digest_enabled"`
 	DigestFrequency *string      `json:"digest_frequency"`
 	MutedRuleIDs    *[]uuid.UUID `json:"muted_rule_ids"`
+	Timezone        *string      `json:"timezone"`
+
+	QuietHours             *repository.QuietHours `json:"quiet_hours"`
+	BusinessDaysOnly       *bool                  `json:"business_days_only"`
+	UrgentBypassQuietHours *bool                  `json:"urgent_bypass_quiet_hours"`
 }
 
 var validDigestFrequencies = map[string]bool{
@@ -64,6 +76,27 @@ func (s *NotificationPreferenceService) Update(ctx context.Context, userID, orgI
 	if req.MutedRuleIDs != nil {
 		pref.MutedRuleIDs = *req.MutedRuleIDs
 	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return nil, &ValidationError{Field: "timezone", Message: "timezone must be a valid IANA timezone name"}
+		}
+		pref.Timezone = *req.Timezone
+	}
+	if req.QuietHours != nil {
+		if _, err := parseClock(req.QuietHours.Start); err != nil {
+			return nil, &ValidationError{Field: "quiet_hours.start", Message: "start must be in HH:MM format"}
+		}
+		if _, err := parseClock(req.QuietHours.End); err != nil {
+			return nil, &ValidationError{Field: "quiet_hours.end", Message: "end must be in HH:MM format"}
+		}
+		pref.QuietHours = *req.QuietHours
+	}
+	if req.BusinessDaysOnly != nil {
+		pref.BusinessDaysOnly = *req.BusinessDaysOnly
+	}
+	if req.UrgentBypassQuietHours != nil {
+		pref.UrgentBypassQuietHours = *req.UrgentBypassQuietHours
+	}
 
 	pref.UserID = userID
 	pref.OrgID = orgID
@@ -75,23 +108,89 @@ func (s *NotificationPreferenceService) Update(ctx context.Context, userID, orgI
 	return pref, nil
 }
 
-// ShouldNotify checks whether a notification should be sent to recipients
-// based on notification preferences and muted rules.
-func (s *NotificationPreferenceService) ShouldNotifyEmail(ctx context.Context, userID, orgID uuid.UUID, ruleID uuid.UUID, _ time.Time) bool {
+// EmailDeliveryTime decides whether and when an alert email should reach a user.
+// It returns false when the user disabled email or muted the rule. Otherwise it
+// returns at when the email can go out immediately, or the time the user's
+// delivery window next opens when at falls in quiet hours or outside business days.
+// Critical alerts skip the delivery window unless the user turned that off.
+func (s *NotificationPreferenceService) EmailDeliveryTime(ctx context.Context, userID, orgID, ruleID uuid.UUID, severity string, at time.Time) (time.Time, bool) {
 	pref, err := s.prefRepo.GetByUserAndOrg(ctx, userID, orgID)
 	if err != nil {
-		return true // default to notify on error
+		return at, true // default to notify on error
 	}
 
 	if !pref.EmailEnabled {
-		return false
+		return time.Time{}, false
 	}
 
 	for _, mutedID := range pref.MutedRuleIDs {
 		if mutedID == ruleID {
-			return false
+			return time.Time{}, false
+		}
+	}
+
+	if severity == "critical" && pref.UrgentBypassQuietHours {
+		return at, true
+	}
+	return nextDeliveryWindow(pref, at), true
+}
+
+// nextDeliveryWindow returns the earliest time at or after at that is outside the
+// user's quiet hours and, if requested, on a business day in the user's timezone.
+func nextDeliveryWindow(pref *repository.NotificationPreference, at time.Time) time.Time {
+	loc := loadLocation(pref.Timezone)
+	quietStart, startErr := parseClock(pref.QuietHours.Start)
+	quietEnd, endErr := parseClock(pref.QuietHours.End)
+	quiet := pref.QuietHours.Enabled && startErr == nil && endErr == nil && quietStart != quietEnd
+
+	t := at.In(loc)
+	// A week is enough to step past any combination of weekends and quiet hours.
+	for i := 0; i < 14; i++ {
+		if pref.BusinessDaysOnly && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+			t = localClock(t.Year(), t.Month(), t.Day()+1, 0, loc)
+			continue
+		}
+		if quiet {
+			minute := t.Hour()*60 + t.Minute()
+			inQuiet := minute >= quietStart && minute < quietEnd
+			if quietStart > quietEnd {
+				inQuiet = minute >= quietStart || minute < quietEnd
+			}
+			if inQuiet {
+				end := localClock(t.Year(), t.Month(), t.Day(), quietEnd, loc)
+				if !end.After(t) {
+					end = localClock(t.Year(), t.Month(), t.Day()+1, quietEnd, loc)
+				}
+				t = end
+				continue
+			}
 		}
+		break
+	}
+	if t.Equal(at) {
+		return at
 	}
+	return t
+}
+
+// localClock returns the time minute minutes past midnight on the given date in
+// loc. A wall-clock time skipped by a DST change resolves to the instant it
+// would have been without the change, i.e. just after the clocks moved.
+func localClock(year int, month time.Month, day, minute int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, minute/60, minute%60, 0, 0, loc)
+	want := time.Date(year, month, day, minute/60, minute%60, 0, 0, time.UTC)
+	got := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	if got.Before(want) {
+		t = t.Add(want.Sub(got))
+	}
+	return t
+}
 
-	return true
+// parseClock parses an HH:MM string into minutes past midnight.
+func parseClock(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
 }
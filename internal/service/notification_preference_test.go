@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+type fakeNotificationPreferenceStore struct {
+	pref *repository.NotificationPreference
+	err  error
+}
+
+func (f *fakeNotificationPreferenceStore) GetByUserAndOrg(ctx context.Context, userID, orgID uuid.UUID) (*repository.NotificationPreference, error) {
+	return f.pref, f.err
+}
+
+func (f *fakeNotificationPreferenceStore) Upsert(ctx context.Context, pref *repository.NotificationPreference) error {
+	f.pref = pref
+	return nil
+}
+
+func mustParseTime(t *testing.T, v string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t.Fatalf("parse %q: %v", v, err)
+	}
+	return ts
+}
+
+func TestNextDeliveryWindow(t *testing.T) {
+	quiet := func(start, end string) repository.QuietHours {
+		return repository.QuietHours{Enabled: true, Start: start, End: end}
+	}
+
+	tests := []struct {
+		name string
+		pref repository.NotificationPreference
+		at   string
+		want string
+	}{
+		{
+			name: "no restrictions",
+			pref: repository.NotificationPreference{QuietHours: repository.QuietHours{Start: "22:00", End: "08:00"}},
+			at:   "2026-01-07T23:00:00Z",
+			want: "2026-01-07T23:00:00Z",
+		},
+		{
+			name: "inside a same-day window",
+			pref: repository.NotificationPreference{QuietHours: quiet("12:00", "13:00")},
+			at:   "2026-01-07T12:30:00Z",
+			want: "2026-01-07T13:00:00Z",
+		},
+		{
+			name: "before a window wrapping past midnight",
+			pref: repository.NotificationPreference{QuietHours: quiet("22:00", "08:00")},
+			at:   "2026-01-07T21:59:00Z",
+			want: "2026-01-07T21:59:00Z",
+		},
+		{
+			name: "start of a wrapping window",
+			pref: repository.NotificationPreference{QuietHours: quiet("22:00", "08:00")},
+			at:   "2026-01-07T22:00:00Z",
+			want: "2026-01-08T08:00:00Z",
+		},
+		{
+			name: "after midnight in a wrapping window",
+			pref: repository.NotificationPreference{QuietHours: quiet("22:00", "08:00")},
+			at:   "2026-01-08T03:00:00Z",
+			want: "2026-01-08T08:00:00Z",
+		},
+		{
+			name: "end of a window is outside it",
+			pref: repository.NotificationPreference{QuietHours: quiet("22:00", "08:00")},
+			at:   "2026-01-08T08:00:00Z",
+			want: "2026-01-08T08:00:00Z",
+		},
+		{
+			name: "equal start and end is no window",
+			pref: repository.NotificationPreference{QuietHours: quiet("08:00", "08:00")},
+			at:   "2026-01-08T08:00:00Z",
+			want: "2026-01-08T08:00:00Z",
+		},
+		{
+			name: "invalid clock is no window",
+			pref: repository.NotificationPreference{QuietHours: quiet("25:00", "08:00")},
+			at:   "2026-01-08T03:00:00Z",
+			want: "2026-01-08T03:00:00Z",
+		},
+		{
+			name: "weekend moves to Monday",
+			pref: repository.NotificationPreference{BusinessDaysOnly: true},
+			at:   "2026-01-03T10:00:00Z", // Saturday
+			want: "2026-01-05T00:00:00Z",
+		},
+		{
+			name: "Friday night quiet hours roll past the weekend",
+			pref: repository.NotificationPreference{BusinessDaysOnly: true, QuietHours: quiet("22:00", "08:00")},
+			at:   "2026-01-02T23:00:00Z", // Friday
+			want: "2026-01-05T08:00:00Z",
+		},
+		{
+			name: "user's timezone",
+			pref: repository.NotificationPreference{Timezone: "Europe/Berlin", QuietHours: quiet("22:00", "08:00")},
+			at:   "2026-01-15T22:30:00Z", // 23:30 in Berlin
+			want: "2026-01-16T07:00:00Z", // 08:00 in Berlin
+		},
+		{
+			name: "weekend in the user's timezone",
+			pref: repository.NotificationPreference{Timezone: "Pacific/Auckland", BusinessDaysOnly: true},
+			at:   "2026-01-09T12:00:00Z", // Saturday 01:00 in Auckland
+			want: "2026-01-11T11:00:00Z", // Monday 00:00 in Auckland
+		},
+		{
+			name: "window ending in a skipped DST hour",
+			pref: repository.NotificationPreference{Timezone: "America/New_York", QuietHours: quiet("22:00", "02:30")},
+			at:   "2026-03-08T06:00:00Z", // 01:00 EST; clocks jump from 02:00 to 03:00
+			want: "2026-03-08T07:30:00Z", // 03:30 EDT
+		},
+		{
+			name: "window across the fall-back night",
+			pref: repository.NotificationPreference{Timezone: "America/New_York", QuietHours: quiet("22:00", "08:00")},
+			at:   "2026-11-01T03:00:00Z", // 23:00 EDT
+			want: "2026-11-01T13:00:00Z", // 08:00 EST
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := mustParseTime(t, tt.at)
+			got := nextDeliveryWindow(&tt.pref, at)
+			if want := mustParseTime(t, tt.want); !got.Equal(want) {
+				t.Errorf("expected %s, got %s", want.UTC().Format(time.RFC3339), got.UTC().Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestEmailDeliveryTime(t *testing.T) {
+	ruleID := uuid.New()
+	at := mustParseTime(t, "2026-01-07T23:00:00Z")
+	held := mustParseTime(t, "2026-01-08T08:00:00Z")
+	quiet := repository.QuietHours{Enabled: true, Start: "22:00", End: "08:00"}
+
+	tests := []struct {
+		name     string
+		pref     *repository.NotificationPreference
+		err      error
+		severity string
+		want     time.Time
+		wantSend bool
+	}{
+		{"email disabled", &repository.NotificationPreference{}, nil, "warning", time.Time{}, false},
+		{"rule muted", &repository.NotificationPreference{EmailEnabled: true, MutedRuleIDs: []uuid.UUID{ruleID}}, nil, "warning", time.Time{}, false},
+		{"held for quiet hours", &repository.NotificationPreference{EmailEnabled: true, QuietHours: quiet}, nil, "warning", held, true},
+		{"critical bypasses quiet hours", &repository.NotificationPreference{EmailEnabled: true, QuietHours: quiet, UrgentBypassQuietHours: true}, nil, "critical", at, true},
+		{"critical held without bypass", &repository.NotificationPreference{EmailEnabled: true, QuietHours: quiet}, nil, "critical", held, true},
+		{"preference lookup fails", nil, errors.New("db down"), "warning", at, true},
+	}
+	for _, tt := range tests {
+		s := &NotificationPreferenceService{prefRepo: &fakeNotificationPreferenceStore{pref: tt.pref, err: tt.err}}
+		got, send := s.EmailDeliveryTime(context.Background(), uuid.New(), uuid.New(), ruleID, tt.severity, at)
+		if send != tt.wantSend || !got.Equal(tt.want) {
+			t.Errorf("%s: expected (%s, %v), got (%s, %v)", tt.name, tt.want, tt.wantSend, got, send)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	maxPlaybookNameLength = 255
+	maxPlaybookSteps      = 50
+	maxPlaybookDays       = 365
+	// defaultPlaybookReentryDays keeps a customer out of a playbook for a
+	// month after their previous run unless the trigger says otherwise.
+	defaultPlaybookReentryDays = 30
+)
+
+var validPlaybookTriggers = map[string]bool{
+	repository.PlaybookTriggerRiskChange:    true,
+	repository.PlaybookTriggerScoreBelow:    true,
+	repository.PlaybookTriggerNewCustomer:   true,
+	repository.PlaybookTriggerPaymentFailed: true,
+	repository.PlaybookTriggerRenewalDue:    true,
+}
+
+var validPlaybookRunStatuses = map[string]bool{
+	repository.PlaybookRunRunning:   true,
+	repository.PlaybookRunWaiting:   true,
+	repository.PlaybookRunCompleted: true,
+	repository.PlaybookRunFailed:    true,
+	repository.PlaybookRunCancelled: true,
+}
+
+// PlaybookRecipientCustomer is a send_email recipient that stands for the
+// customer's own email address.
+const PlaybookRecipientCustomer = "customer"
+
+// PlaybookService manages playbooks and their runs.
+type PlaybookService struct {
+	playbooks *repository.PlaybookRepository
+	runs      *repository.PlaybookRunRepository
+	customers *repository.CustomerRepository
+	orgRepo   *repository.OrganizationRepository
+}
+
+// NewPlaybookService creates a new PlaybookService.
+func NewPlaybookService(
+	playbooks *repository.PlaybookRepository,
+	runs *repository.PlaybookRunRepository,
+	customers *repository.CustomerRepository,
+	orgRepo *repository.OrganizationRepository,
+) *PlaybookService {
+	return &PlaybookService{
+		playbooks: playbooks,
+		runs:      runs,
+		customers: customers,
+		orgRepo:   orgRepo,
+	}
+}
+
+// CreatePlaybookRequest holds input for creating a playbook. Playbooks are
+// active unless IsActive is false.
+type CreatePlaybookRequest struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Trigger     repository.PlaybookTrigger `json:"trigger"`
+	Steps       []repository.PlaybookStep  `json:"steps"`
+	IsActive    *bool                      `json:"is_active"`
+}
+
+// UpdatePlaybookRequest holds partial updates to a playbook. Runs already in
+// progress keep the steps they started with.
+type UpdatePlaybookRequest struct {
+	Name        *string                     `json:"name"`
+	Description *string                     `json:"description"`
+	Trigger     *repository.PlaybookTrigger `json:"trigger"`
+	Steps       *[]repository.PlaybookStep  `json:"steps"`
+	IsActive    *bool                       `json:"is_active"`
+}
+
+// StartPlaybookRunRequest holds input for enrolling a customer by hand.
+type StartPlaybookRunRequest struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+}
+
+// PlaybookRunListResponse is a page of playbook runs.
+type PlaybookRunListResponse struct {
+	Runs       []*repository.PlaybookRun `json:"runs"`
+	Pagination PaginationMeta            `json:"pagination"`
+}
+
+// PlaybookRunDetail is a run with the history of its executed steps.
+type PlaybookRunDetail struct {
+	*repository.PlaybookRun
+	History []*repository.PlaybookRunStep `json:"history"`
+}
+
+// List returns an org's playbooks.
+func (s *PlaybookService) List(ctx context.Context, orgID uuid.UUID) ([]*repository.Playbook, error) {
+	playbooks, err := s.playbooks.List(ctx, orgID, false)
+	if err != nil {
+		return nil, err
+	}
+	if playbooks == nil {
+		playbooks = []*repository.Playbook{}
+	}
+	return playbooks, nil
+}
+
+// Get returns a playbook.
+func (s *PlaybookService) Get(ctx context.Context, id, orgID uuid.UUID) (*repository.Playbook, error) {
+	p, err := s.playbooks.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, &NotFoundError{Resource: "playbook", Message: "playbook not found"}
+	}
+	return p, nil
+}
+
+// Create creates a playbook.
+func (s *PlaybookService) Create(ctx context.Context, orgID, userID uuid.UUID, req CreatePlaybookRequest) (*repository.Playbook, error) {
+	p := &repository.Playbook{
+		OrgID:       orgID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Trigger:     req.Trigger,
+		Steps:       req.Steps,
+		IsActive:    true,
+		CreatedBy:   &userID,
+	}
+	if req.IsActive != nil {
+		p.IsActive = *req.IsActive
+	}
+
+	if err := s.validate(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := s.playbooks.Create(ctx, p); err != nil {
+		return nil, fmt.Errorf("create playbook: %w", err)
+	}
+	return p, nil
+}
+
+// Update applies partial updates to a playbook.
+func (s *PlaybookService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdatePlaybookRequest) (*repository.Playbook, error) {
+	p, err := s.Get(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		p.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		p.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Trigger != nil {
+		p.Trigger = *req.Trigger
+	}
+	if req.Steps != nil {
+		p.Steps = *req.Steps
+	}
+	if req.IsActive != nil {
+		p.IsActive = *req.IsActive
+	}
+
+	if err := s.validate(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := s.playbooks.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetWebhookSecret returns the secret that signs a playbook's webhook requests.
+func (s *PlaybookService) GetWebhookSecret(ctx context.Context, id, orgID uuid.UUID) (string, error) {
+	p, err := s.Get(ctx, id, orgID)
+	if err != nil {
+		return "", err
+	}
+	return p.WebhookSecret, nil
+}
+
+// RotateWebhookSecret replaces a playbook's webhook secret and returns the new one.
+func (s *PlaybookService) RotateWebhookSecret(ctx context.Context, id, orgID uuid.UUID) (string, error) {
+	secret, err := s.playbooks.RotateWebhookSecret(ctx, id, orgID)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", &NotFoundError{Resource: "playbook", Message: "playbook not found"}
+	}
+	return secret, nil
+}
+
+// Delete deletes a playbook along with its runs.
+func (s *PlaybookService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	if _, err := s.Get(ctx, id, orgID); err != nil {
+		return err
+	}
+	return s.playbooks.Delete(ctx, id, orgID)
+}
+
+// ListRuns returns a filtered page of an org's playbook runs.
+func (s *PlaybookService) ListRuns(ctx context.Context, params repository.PlaybookRunListParams) (*PlaybookRunListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PerPage < 1 {
+		params.PerPage = 25
+	}
+	if params.PerPage > 100 {
+		params.PerPage = 100
+	}
+	if params.Status != "" && !validPlaybookRunStatuses[params.Status] {
+		return nil, &ValidationError{Field: "status", Message: "status must be running, waiting, completed, failed, or cancelled"}
+	}
+
+	result, err := s.runs.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := result.Runs
+	if runs == nil {
+		runs = []*repository.PlaybookRun{}
+	}
+	return &PlaybookRunListResponse{
+		Runs: runs,
+		Pagination: PaginationMeta{
+			Page:       result.Page,
+			PerPage:    result.PerPage,
+			Total:      result.Total,
+			TotalPages: result.TotalPages,
+		},
+	}, nil
+}
+
+// GetRun returns a run with its step history.
+func (s *PlaybookService) GetRun(ctx context.Context, id, orgID uuid.UUID) (*PlaybookRunDetail, error) {
+	run, err := s.runs.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, &NotFoundError{Resource: "playbook_run", Message: "playbook run not found"}
+	}
+
+	history, err := s.runs.ListSteps(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		history = []*repository.PlaybookRunStep{}
+	}
+	return &PlaybookRunDetail{PlaybookRun: run, History: history}, nil
+}
+
+// StartRun enrolls a customer in a playbook by hand, regardless of its
+// trigger and re-entry window. The playbook must be active.
+func (s *PlaybookService) StartRun(ctx context.Context, playbookID, orgID, userID uuid.UUID, req StartPlaybookRunRequest) (*PlaybookRunDetail, error) {
+	p, err := s.Get(ctx, playbookID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsActive {
+		return nil, &ValidationError{Field: "playbook", Message: "playbook is not active"}
+	}
+
+	customer, err := s.customers.GetByIDAndOrg(ctx, req.CustomerID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("get customer: %w", err)
+	}
+	if customer == nil {
+		return nil, &ValidationError{Field: "customer_id", Message: "customer not found"}
+	}
+
+	run := &repository.PlaybookRun{
+		OrgID:       orgID,
+		PlaybookID:  p.ID,
+		CustomerID:  customer.ID,
+		TriggerData: map[string]any{"manual": true},
+		Steps:       p.Steps,
+		StartedBy:   &userID,
+	}
+	started, err := s.runs.Start(ctx, run, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, &ConflictError{Message: "customer already has an active run of this playbook"}
+	}
+	return s.GetRun(ctx, run.ID, orgID)
+}
+
+// CancelRun stops an active run.
+func (s *PlaybookService) CancelRun(ctx context.Context, id, orgID uuid.UUID) (*PlaybookRunDetail, error) {
+	if _, err := s.GetRun(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+
+	cancelled, err := s.runs.Cancel(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, &ConflictError{Message: "playbook run is not active"}
+	}
+	return s.GetRun(ctx, id, orgID)
+}
+
+func (s *PlaybookService) validate(ctx context.Context, p *repository.Playbook) error {
+	if p.Name == "" {
+		return &ValidationError{Field: "name", Message: "name is required"}
+	}
+	if utf8.RuneCountInString(p.Name) > maxPlaybookNameLength {
+		return &ValidationError{Field: "name", Message: fmt.Sprintf("name must be at most %d characters", maxPlaybookNameLength)}
+	}
+	if err := validatePlaybookTrigger(&p.Trigger); err != nil {
+		return err
+	}
+
+	if len(p.Steps) == 0 {
+		return &ValidationError{Field: "steps", Message: "at least one step is required"}
+	}
+	if len(p.Steps) > maxPlaybookSteps {
+		return &ValidationError{Field: "steps", Message: fmt.Sprintf("a playbook can have at most %d steps", maxPlaybookSteps)}
+	}
+	for i := range p.Steps {
+		if err := s.validateStep(ctx, p.OrgID, p.Steps, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePlaybookTrigger checks a trigger and fills in its defaults.
+func validatePlaybookTrigger(t *repository.PlaybookTrigger) error {
+	if !validPlaybookTriggers[t.Type] {
+		return &ValidationError{Field: "trigger.type", Message: "trigger type must be risk_change, score_below, new_customer, payment_failed, or renewal_due"}
+	}
+
+	validRisks := map[string]bool{"": true, "green": true, "yellow": true, "red": true}
+	switch t.Type {
+	case repository.PlaybookTriggerRiskChange:
+		if !validRisks[t.From] || !validRisks[t.To] {
+			return &ValidationError{Field: "trigger", Message: "from and to must be green, yellow, or red"}
+		}
+		if t.From != "" && t.From == t.To {
+			return &ValidationError{Field: "trigger", Message: "from and to must differ"}
+		}
+	case repository.PlaybookTriggerScoreBelow:
+		if t.Threshold < 1 || t.Threshold > 100 {
+			return &ValidationError{Field: "trigger.threshold", Message: "threshold must be between 1 and 100"}
+		}
+	case repository.PlaybookTriggerRenewalDue:
+		if t.Days < 1 || t.Days > maxPlaybookDays {
+			return &ValidationError{Field: "trigger.days", Message: fmt.Sprintf("days must be between 1 and %d", maxPlaybookDays)}
+		}
+	}
+
+	if t.ReentryDays == 0 {
+		t.ReentryDays = defaultPlaybookReentryDays
+	}
+	if t.ReentryDays < 1 || t.ReentryDays > maxPlaybookDays {
+		return &ValidationError{Field: "trigger.reentry_days", Message: fmt.Sprintf("reentry_days must be between 1 and %d", maxPlaybookDays)}
+	}
+	return nil
+}
+
+func (s *PlaybookService) validateStep(ctx context.Context, orgID uuid.UUID, steps []repository.PlaybookStep, i int) error {
+	step := &steps[i]
+	field := fmt.Sprintf("steps[%d]", i)
+
+	switch step.Type {
+	case repository.PlaybookStepCreateTask:
+		step.Title = strings.TrimSpace(step.Title)
+		if step.Title == "" {
+			return &ValidationError{Field: field + ".title", Message: "title is required"}
+		}
+		if utf8.RuneCountInString(step.Title) > maxTaskTitleLength {
+			return &ValidationError{Field: field + ".title", Message: fmt.Sprintf("title must be at most %d characters", maxTaskTitleLength)}
+		}
+		if step.DueInDays < 0 || step.DueInDays > maxPlaybookDays {
+			return &ValidationError{Field: field + ".due_in_days", Message: fmt.Sprintf("due_in_days must be between 0 and %d", maxPlaybookDays)}
+		}
+		if step.AssigneeID != nil {
+			ok, err := s.orgRepo.IsMember(ctx, *step.AssigneeID, orgID)
+			if err != nil {
+				return fmt.Errorf("check membership: %w", err)
+			}
+			if !ok {
+				return &ValidationError{Field: field + ".assignee_id", Message: "assignee is not a member of this organization"}
+			}
+		}
+
+	case repository.PlaybookStepSendEmail:
+		if len(step.To) == 0 {
+			return &ValidationError{Field: field + ".to", Message: "at least one recipient is required"}
+		}
+		for _, r := range step.To {
+			if r == PlaybookRecipientCustomer || r == AlertRecipientCustomerOwner {
+				continue
+			}
+			if _, err := mail.ParseAddress(r); err != nil {
+				return &ValidationError{Field: field + ".to", Message: fmt.Sprintf("invalid email: %s", r)}
+			}
+		}
+		if strings.TrimSpace(step.Subject) == "" || strings.TrimSpace(step.Body) == "" {
+			return &ValidationError{Field: field, Message: "subject and body are required"}
+		}
+		if err := checkAlertTemplate(step.Subject); err != nil {
+			return &ValidationError{Field: field + ".subject", Message: err.Error()}
+		}
+		if err := checkAlertTemplate(step.Body); err != nil {
+			return &ValidationError{Field: field + ".body", Message: err.Error()}
+		}
+
+	case repository.PlaybookStepWait:
+		if step.Days < 1 || step.Days > maxPlaybookDays {
+			return &ValidationError{Field: field + ".days", Message: fmt.Sprintf("days must be between 1 and %d", maxPlaybookDays)}
+		}
+
+	case repository.PlaybookStepBranch:
+		if step.ScoreBelow == nil || *step.ScoreBelow < 0 || *step.ScoreBelow > 100 {
+			return &ValidationError{Field: field + ".score_below", Message: "score_below must be between 0 and 100"}
+		}
+		// Branches may only jump forward, so every run ends. Jumping to
+		// len(steps) ends the run.
+		for name, target := range map[string]*int{"then_step": step.ThenStep, "else_step": step.ElseStep} {
+			if target != nil && (*target <= i || *target > len(steps)) {
+				return &ValidationError{Field: field + "." + name, Message: fmt.Sprintf("%s must be a later step, between %d and %d", name, i+1, len(steps))}
+			}
+		}
+
+	case repository.PlaybookStepWebhook:
+		u, err := url.Parse(step.URL)
+		if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+			return &ValidationError{Field: field + ".url", Message: "url must be an https URL"}
+		}
+		// Hostnames are checked again when the webhook is sent, since they
+		// may resolve anywhere.
+		if addr, err := netip.ParseAddr(u.Hostname()); (err == nil && !isPublicAddr(addr)) || strings.EqualFold(u.Hostname(), "localhost") {
+			return &ValidationError{Field: field + ".url", Message: "url must not point to a private or local address"}
+		}
+
+	default:
+		return &ValidationError{Field: field + ".type", Message: "step type must be create_task, send_email, wait, branch, or webhook"}
+	}
+	return nil
+}
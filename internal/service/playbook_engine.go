@@ -0,0 +1,603 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	// playbookRunBatch caps how many due runs are claimed at once.
+	playbookRunBatch = 100
+	// playbookRunLease is how long a claimed run is kept from other workers.
+	playbookRunLease = 10 * time.Minute
+	// playbookGatedRetry is when runs of orgs whose plan lost playbooks are
+	// looked at again.
+	playbookGatedRetry     = time.Hour
+	playbookWebhookTimeout = 10 * time.Second
+)
+
+// PlaybookEngine enrolls customers in playbooks when their triggers match and
+// advances runs step by step.
+type PlaybookEngine struct {
+	playbooks      *repository.PlaybookRepository
+	runs           *repository.PlaybookRunRepository
+	customers      *repository.CustomerRepository
+	healthScores   *repository.HealthScoreRepository
+	owners         *repository.CustomerOwnerRepository
+	tasks          *TaskService
+	emailService   EmailService
+	templates      *AlertTemplateService
+	featureAllowed func(ctx context.Context, orgID uuid.UUID) (bool, error)
+	httpClient     *http.Client
+	interval       time.Duration
+}
+
+// PlaybookEngineDeps holds constructor dependencies for PlaybookEngine.
+// FeatureAllowed reports whether an org's plan includes playbooks.
+type PlaybookEngineDeps struct {
+	Playbooks      *repository.PlaybookRepository
+	Runs           *repository.PlaybookRunRepository
+	Customers      *repository.CustomerRepository
+	HealthScores   *repository.HealthScoreRepository
+	Owners         *repository.CustomerOwnerRepository
+	Tasks          *TaskService
+	EmailService   EmailService
+	Templates      *AlertTemplateService
+	FeatureAllowed func(ctx context.Context, orgID uuid.UUID) (bool, error)
+}
+
+// NewPlaybookEngine creates a new PlaybookEngine.
+func NewPlaybookEngine(deps PlaybookEngineDeps, intervalMinutes int) *PlaybookEngine {
+	return &PlaybookEngine{
+		playbooks:      deps.Playbooks,
+		runs:           deps.Runs,
+		customers:      deps.Customers,
+		healthScores:   deps.HealthScores,
+		owners:         deps.Owners,
+		tasks:          deps.Tasks,
+		emailService:   deps.EmailService,
+		templates:      deps.Templates,
+		featureAllowed: deps.FeatureAllowed,
+		httpClient:     newPlaybookWebhookClient(),
+		interval:       time.Duration(intervalMinutes) * time.Minute,
+	}
+}
+
+// Start begins the periodic playbook loop. Cancel the context to stop.
+func (e *PlaybookEngine) Start(ctx context.Context) {
+	slog.Info("playbook engine started", "interval", e.interval)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("playbook engine stopped")
+			return
+		case <-ticker.C:
+			e.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce evaluates the triggers of all active playbooks and then advances
+// every run that is due.
+func (e *PlaybookEngine) RunOnce(ctx context.Context) {
+	allowed := map[uuid.UUID]bool{}
+
+	orgIDs, err := e.playbooks.ListOrgsWithActivePlaybooks(ctx)
+	if err != nil {
+		slog.Error("playbook engine: list orgs", "error", err)
+	}
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		e.evaluateOrg(ctx, orgID, e.isAllowed(ctx, orgID, allowed))
+	}
+
+	for ctx.Err() == nil {
+		runs, err := e.runs.ClaimDue(ctx, playbookRunLease, playbookRunBatch)
+		if err != nil {
+			slog.Error("playbook engine: claim due runs", "error", err)
+			return
+		}
+		for _, run := range runs {
+			if ctx.Err() != nil {
+				return
+			}
+			if !e.isAllowed(ctx, run.OrgID, allowed) {
+				e.postpone(ctx, run)
+				continue
+			}
+			e.advance(ctx, run)
+		}
+		if len(runs) < playbookRunBatch {
+			return
+		}
+	}
+}
+
+// isAllowed reports whether an org's plan includes playbooks, caching the
+// answer for the current pass. Lookup errors count as not allowed.
+func (e *PlaybookEngine) isAllowed(ctx context.Context, orgID uuid.UUID, cache map[uuid.UUID]bool) bool {
+	if ok, found := cache[orgID]; found {
+		return ok
+	}
+	ok := true
+	if e.featureAllowed != nil {
+		var err error
+		ok, err = e.featureAllowed(ctx, orgID)
+		if err != nil {
+			slog.Error("playbook engine: check plan", "org_id", orgID, "error", err)
+			ok = false
+		}
+	}
+	cache[orgID] = ok
+	return ok
+}
+
+// evaluateOrg enrolls the customers matched by an org's active playbooks.
+// Orgs whose plan does not include playbooks enroll no one, and what their
+// triggers matched meanwhile is skipped.
+func (e *PlaybookEngine) evaluateOrg(ctx context.Context, orgID uuid.UUID, allowed bool) {
+	playbooks, err := e.playbooks.List(ctx, orgID, true)
+	if err != nil {
+		slog.Error("playbook engine: list playbooks", "org_id", orgID, "error", err)
+		return
+	}
+
+	for _, p := range playbooks {
+		if ctx.Err() != nil {
+			return
+		}
+		now := time.Now()
+		if allowed {
+			if err := e.enroll(ctx, p, now); err != nil {
+				slog.Error("playbook engine: evaluate trigger", "playbook_id", p.ID, "error", err)
+				continue
+			}
+		}
+		if err := e.playbooks.SetTriggerCheckedAt(ctx, p.ID, now); err != nil {
+			slog.Error("playbook engine: save trigger progress", "playbook_id", p.ID, "error", err)
+		}
+	}
+}
+
+func (e *PlaybookEngine) enroll(ctx context.Context, p *repository.Playbook, now time.Time) error {
+	candidates, err := e.playbooks.ListTriggerCandidates(ctx, p.OrgID, p.Trigger, p.TriggerCheckedAt, now)
+	if err != nil {
+		return err
+	}
+
+	started := 0
+	for _, c := range candidates {
+		if !playbookTriggerMatches(p.Trigger, c.Data) {
+			continue
+		}
+		run := &repository.PlaybookRun{
+			OrgID:       p.OrgID,
+			PlaybookID:  p.ID,
+			CustomerID:  c.CustomerID,
+			TriggerKey:  c.Key,
+			TriggerData: c.Data,
+			Steps:       p.Steps,
+		}
+		ok, err := e.runs.Start(ctx, run, p.Trigger.ReentryDays)
+		if err != nil {
+			return err
+		}
+		if ok {
+			started++
+		}
+	}
+
+	if started > 0 {
+		slog.Info("playbook engine: runs started", "playbook_id", p.ID, "count", started)
+	}
+	return nil
+}
+
+// playbookTriggerMatches applies the parts of a trigger that are not
+// filtered in SQL.
+func playbookTriggerMatches(t repository.PlaybookTrigger, data map[string]any) bool {
+	if t.Type != repository.PlaybookTriggerRiskChange {
+		return true
+	}
+	from, _ := data["previous_level"].(string)
+	to, _ := data["new_level"].(string)
+	return (t.From == "" || t.From == from) && (t.To == "" || t.To == to)
+}
+
+// postpone puts a run of an org without playbooks on its plan back to wait.
+func (e *PlaybookEngine) postpone(ctx context.Context, run *repository.PlaybookRun) {
+	next := time.Now().Add(playbookGatedRetry)
+	run.Status = repository.PlaybookRunWaiting
+	run.NextRunAt = &next
+	if err := e.runs.SaveProgress(ctx, run); err != nil {
+		slog.Error("playbook engine: postpone run", "run_id", run.ID, "error", err)
+	}
+}
+
+// advance executes a run's steps until it waits, fails or completes.
+func (e *PlaybookEngine) advance(ctx context.Context, run *repository.PlaybookRun) {
+	customer, err := e.customers.GetByIDAndOrg(ctx, run.CustomerID, run.OrgID)
+	if err != nil {
+		slog.Error("playbook engine: get customer", "run_id", run.ID, "error", err)
+		return
+	}
+	playbook, err := e.playbooks.GetByID(ctx, run.PlaybookID, run.OrgID)
+	if err != nil {
+		slog.Error("playbook engine: get playbook", "run_id", run.ID, "error", err)
+		return
+	}
+	if customer == nil || playbook == nil {
+		e.finish(ctx, run, repository.PlaybookRunCancelled, "customer no longer exists")
+		return
+	}
+
+	for run.CurrentStep < len(run.Steps) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		index := run.CurrentStep
+		step := run.Steps[index]
+		record := &repository.PlaybookRunStep{
+			RunID:     run.ID,
+			StepIndex: index,
+			StepType:  step.Type,
+			Status:    repository.PlaybookRunCompleted,
+		}
+
+		next, detail, wait, err := e.execute(ctx, run, playbook, customer, index)
+		record.Detail = detail
+		if err != nil {
+			record.Status = repository.PlaybookRunFailed
+			record.Error = err.Error()
+		}
+		if addErr := e.runs.AddStep(ctx, record); addErr != nil {
+			slog.Error("playbook engine: record step", "run_id", run.ID, "step", index, "error", addErr)
+		}
+		if err != nil {
+			slog.Warn("playbook engine: step failed", "run_id", run.ID, "step", index, "type", step.Type, "error", err)
+			e.finish(ctx, run, repository.PlaybookRunFailed, fmt.Sprintf("step %d (%s): %s", index, step.Type, err))
+			return
+		}
+
+		run.CurrentStep = next
+		if wait > 0 {
+			resume := time.Now().Add(wait)
+			run.Status = repository.PlaybookRunWaiting
+			run.NextRunAt = &resume
+			if err := e.runs.SaveProgress(ctx, run); err != nil {
+				slog.Error("playbook engine: save run progress", "run_id", run.ID, "error", err)
+			}
+			return
+		}
+		if err := e.runs.SaveProgress(ctx, run); err != nil {
+			slog.Error("playbook engine: save run progress", "run_id", run.ID, "error", err)
+			return
+		}
+	}
+
+	e.finish(ctx, run, repository.PlaybookRunCompleted, "")
+}
+
+func (e *PlaybookEngine) finish(ctx context.Context, run *repository.PlaybookRun, status, reason string) {
+	now := time.Now()
+	run.Status = status
+	run.Error = reason
+	run.NextRunAt = nil
+	run.FinishedAt = &now
+	if err := e.runs.SaveProgress(ctx, run); err != nil {
+		slog.Error("playbook engine: finish run", "run_id", run.ID, "error", err)
+	}
+}
+
+// execute performs one step and returns the index of the step to continue
+// at, what the step did, and how long to wait before continuing.
+func (e *PlaybookEngine) execute(ctx context.Context, run *repository.PlaybookRun, playbook *repository.Playbook, customer *repository.Customer, index int) (int, map[string]any, time.Duration, error) {
+	step := run.Steps[index]
+	next := index + 1
+
+	switch step.Type {
+	case repository.PlaybookStepWait:
+		wait := time.Duration(step.Days) * 24 * time.Hour
+		return next, map[string]any{"resume_at": time.Now().Add(wait)}, wait, nil
+
+	case repository.PlaybookStepBranch:
+		score, err := e.healthScores.GetByCustomerID(ctx, customer.ID, run.OrgID)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		detail := map[string]any{"score": nil, "branch": "else"}
+		target := step.ElseStep
+		if score != nil {
+			detail["score"] = score.OverallScore
+			if step.ScoreBelow != nil && score.OverallScore < *step.ScoreBelow {
+				detail["branch"] = "then"
+				target = step.ThenStep
+			}
+		}
+		if target != nil {
+			next = *target
+		}
+		detail["next_step"] = next
+		return next, detail, 0, nil
+
+	case repository.PlaybookStepCreateTask:
+		if e.tasks == nil {
+			return 0, nil, 0, errors.New("tasks are not available")
+		}
+		name := customer.Name
+		if name == "" {
+			name = customer.Email
+		}
+		task, err := e.tasks.CreateAutomated(ctx, AutomatedTask{
+			OrgID:         run.OrgID,
+			Title:         fmt.Sprintf("%s (%s)", step.Title, name),
+			Description:   step.Description,
+			DueInDays:     step.DueInDays,
+			AssigneeID:    step.AssigneeID,
+			AssignToOwner: step.AssignToOwner,
+			CustomerID:    &customer.ID,
+			CreatedBy:     run.StartedBy,
+		})
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		return next, map[string]any{"task_id": task.ID, "assignee_id": task.AssigneeID}, 0, nil
+
+	case repository.PlaybookStepSendEmail:
+		sent, err := e.sendEmail(ctx, run, playbook, customer, step)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		return next, map[string]any{"sent_to": sent}, 0, nil
+
+	case repository.PlaybookStepWebhook:
+		status, err := e.postWebhook(ctx, run, playbook, customer, index)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		return next, map[string]any{"status_code": status}, 0, nil
+	}
+
+	return 0, nil, 0, fmt.Errorf("unknown step type: %s", step.Type)
+}
+
+// sendEmail renders a send_email step against the customer and sends it to
+// each resolved recipient. A step without any reachable recipient sends
+// nothing.
+func (e *PlaybookEngine) sendEmail(ctx context.Context, run *repository.PlaybookRun, playbook *repository.Playbook, customer *repository.Customer, step repository.PlaybookStep) ([]string, error) {
+	if e.emailService == nil || e.templates == nil {
+		return nil, errors.New("email is not configured")
+	}
+
+	var recipients []string
+	add := func(emails ...string) {
+		for _, email := range emails {
+			if email != "" && !slices.Contains(recipients, email) {
+				recipients = append(recipients, email)
+			}
+		}
+	}
+	for _, r := range step.To {
+		switch r {
+		case PlaybookRecipientCustomer:
+			add(customer.Email)
+		case AlertRecipientCustomerOwner:
+			if e.owners == nil {
+				continue
+			}
+			owners, err := e.owners.ListEmailsForCustomer(ctx, customer.ID)
+			if err != nil {
+				return nil, fmt.Errorf("resolve customer owners: %w", err)
+			}
+			add(owners...)
+		default:
+			add(r)
+		}
+	}
+	if len(recipients) == 0 {
+		return []string{}, nil
+	}
+
+	match := AlertMatch{
+		Rule: &repository.AlertRule{
+			OrgID:       run.OrgID,
+			Name:        playbook.Name,
+			TriggerType: playbook.Trigger.Type,
+		},
+		Customer:    customer,
+		TriggerData: run.TriggerData,
+	}
+	rendered, err := e.templates.RenderInline(ctx, match, step.Subject, step.Body)
+	if err != nil {
+		return nil, fmt.Errorf("render email: %w", err)
+	}
+
+	for _, to := range recipients {
+		if _, err := e.emailService.SendEmail(ctx, SendEmailParams{
+			To:       to,
+			Subject:  rendered.Subject,
+			HTMLBody: rendered.HTMLBody,
+			TextBody: rendered.TextBody,
+		}); err != nil {
+			return nil, fmt.Errorf("send email to %s: %w", to, err)
+		}
+	}
+	return recipients, nil
+}
+
+// playbookWebhookPayload is the JSON body posted by webhook steps.
+type playbookWebhookPayload struct {
+	Event       string          `json:"event"`
+	PlaybookID  uuid.UUID       `json:"playbook_id"`
+	Playbook    string          `json:"playbook"`
+	RunID       uuid.UUID       `json:"run_id"`
+	StepIndex   int             `json:"step_index"`
+	Customer    webhookCustomer `json:"customer"`
+	TriggerData map[string]any  `json:"trigger_data"`
+	SentAt      time.Time       `json:"sent_at"`
+}
+
+type webhookCustomer struct {
+	ID          uuid.UUID `json:"id"`
+	ExternalID  string    `json:"external_id"`
+	Source      string    `json:"source"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	CompanyName string    `json:"company_name"`
+	MRRCents    int       `json:"mrr_cents"`
+}
+
+// errWebhookAddressBlocked is returned when a webhook URL resolves to a
+// private or local address.
+var errWebhookAddressBlocked = errors.New("webhook address is not public")
+
+// newPlaybookWebhookClient returns the client webhook steps post with. The
+// address check runs on the dialed IP, after DNS resolution, so a hostname
+// cannot be pointed at an internal service once the URL is saved. Proxies
+// from the environment are ignored, since they would be dialed instead.
+func newPlaybookWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: playbookWebhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errWebhookAddressBlocked, address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   playbookWebhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return fmt.Errorf("webhook redirected to a non-https URL")
+			}
+			if len(via) >= 5 {
+				return fmt.Errorf("webhook redirected too many times")
+			}
+			return nil
+		},
+	}
+}
+
+// nonPublicPrefixes are ranges netip does not classify as private that still
+// reach internal hosts: carrier-grade NAT, which some clouds use for metadata
+// and internal services, and NAT64, which embeds an IPv4 address.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// isPublicAddr reports whether a webhook may be sent to addr: loopback,
+// private, link-local, multicast, unspecified, carrier-grade NAT and NAT64
+// addresses are refused.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// signPlaybookWebhook returns the X-PulseScore-Signature header for a webhook
+// body: the Unix timestamp and the hex HMAC-SHA256 of "<timestamp>.<body>"
+// under the playbook's secret.
+func signPlaybookWebhook(secret string, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postWebhook posts the run's context to a webhook step's URL, signed with
+// the playbook's secret. Responses outside 2xx fail the step.
+func (e *PlaybookEngine) postWebhook(ctx context.Context, run *repository.PlaybookRun, playbook *repository.Playbook, customer *repository.Customer, index int) (int, error) {
+	// Runs copy their steps when they start, so URLs saved before https was
+	// required are refused here.
+	target := run.Steps[index].URL
+	if u, err := url.Parse(target); err != nil || u.Scheme != "https" {
+		return 0, fmt.Errorf("webhook url must be an https URL")
+	}
+
+	now := time.Now().UTC()
+	body, err := json.Marshal(playbookWebhookPayload{
+		Event:      "playbook.step",
+		PlaybookID: playbook.ID,
+		Playbook:   playbook.Name,
+		RunID:      run.ID,
+		StepIndex:  index,
+		Customer: webhookCustomer{
+			ID:          customer.ID,
+			ExternalID:  customer.ExternalID,
+			Source:      customer.Source,
+			Name:        customer.Name,
+			Email:       customer.Email,
+			CompanyName: customer.CompanyName,
+			MRRCents:    customer.MRRCents,
+		},
+		TriggerData: run.TriggerData,
+		SentAt:      now,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PulseScore-Playbooks/1.0")
+	req.Header.Set("X-PulseScore-Signature", signPlaybookWebhook(playbook.WebhookSecret, now, body))
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+		{"100.64.0.1", false},
+		{"100.100.100.200", false},
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		{"64:ff9b::a9fe:a9fe", false},
+		{"64:ff9b:1::a00:1", false},
+		{"64:ff9c::1", true},
+	}
+	for _, tt := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestPlaybookWebhookClient_RefusesLoopback(t *testing.T) {
+	called := false
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+	_, err := newPlaybookWebhookClient().Do(req)
+	if !errors.Is(err, errWebhookAddressBlocked) {
+		t.Fatalf("expected errWebhookAddressBlocked, got %v", err)
+	}
+	if called {
+		t.Error("expected the request not to reach the server")
+	}
+}
+
+func TestSignPlaybookWebhook(t *testing.T) {
+	body := []byte(`{"event":"playbook.step"}`)
+	ts := time.Unix(1767225600, 0)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1767225600." + string(body)))
+	want := "t=1767225600,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := signPlaybookWebhook("secret", ts, body); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if signPlaybookWebhook("other", ts, body) == want {
+		t.Error("expected a different secret to give a different signature")
+	}
+}
+
+func TestValidateStep_WebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://hooks.example.com/escalate", false},
+		{"https://93.184.216.34/hook", false},
+		{"http://hooks.example.com/escalate", true},
+		{"https://localhost:8080/hook", true},
+		{"https://127.0.0.1/hook", true},
+		{"https://[::1]/hook", true},
+		{"https://169.254.169.254/latest/meta-data", true},
+		{"https://10.0.0.5/hook", true},
+		{"https://100.100.100.200/latest/meta-data", true},
+		{"https://[64:ff9b::a9fe:a9fe]/hook", true},
+		{"ftp://hooks.example.com", true},
+		{"https://", true},
+	}
+	s := &PlaybookService{}
+	for _, tt := range tests {
+		steps := []repository.PlaybookStep{{Type: repository.PlaybookStepWebhook, URL: tt.url}}
+		err := s.validateStep(context.Background(), uuid.New(), steps, 0)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateStep(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPostWebhook_Signed(t *testing.T) {
+	var sig string
+	var body []byte
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sig = r.Header.Get("X-PulseScore-Signature")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	// The test server is on loopback, so it is reached with its own client.
+	e := &PlaybookEngine{httpClient: srv.Client()}
+	run := &repository.PlaybookRun{ID: uuid.New(), Steps: []repository.PlaybookStep{{Type: repository.PlaybookStepWebhook, URL: srv.URL}}}
+	playbook := &repository.Playbook{ID: uuid.New(), Name: "Rescue", WebhookSecret: "secret"}
+
+	status, err := e.postWebhook(context.Background(), run, playbook, &repository.Customer{ID: uuid.New()}, 0)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, err)
+	}
+
+	var ts int64
+	if _, err := fmt.Sscanf(sig, "t=%d,", &ts); err != nil {
+		t.Fatalf("unexpected signature header %q", sig)
+	}
+	if want := signPlaybookWebhook("secret", time.Unix(ts, 0), body); sig != want {
+		t.Errorf("expected signature %s, got %s", want, sig)
+	}
+}
+
+func TestPostWebhook_RequiresHTTPS(t *testing.T) {
+	e := &PlaybookEngine{httpClient: newPlaybookWebhookClient()}
+	run := &repository.PlaybookRun{Steps: []repository.PlaybookStep{{Type: repository.PlaybookStepWebhook, URL: "http://hooks.example.com/escalate"}}}
+
+	if _, err := e.postWebhook(context.Background(), run, &repository.Playbook{}, &repository.Customer{}, 0); err == nil {
+		t.Fatal("expected an http URL saved before https was required to be refused")
+	}
+}
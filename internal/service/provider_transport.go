@@ -0,0 +1,484 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// providerRetryBaseDelay is the first retry delay when a response carries
+	// no Retry-After header; it doubles on every attempt.
+	providerRetryBaseDelay = 500 * time.Millisecond
+	providerRetryMaxDelay  = 30 * time.Second
+	// providerRetryAfterMax is the longest Retry-After the transport waits
+	// out. A request asked to wait longer gets the response back instead, and
+	// its connection is paused for at most this long.
+	providerRetryAfterMax = 2 * time.Minute
+
+	// providerBreakerThreshold consecutive server errors open a provider's
+	// circuit for providerBreakerCooldown, after which one probe request is let through.
+	providerBreakerThreshold = 5
+	providerBreakerCooldown  = 30 * time.Second
+
+	// providerLimiterIdleTTL is how long an unused per-connection limiter is kept.
+	providerLimiterIdleTTL = 15 * time.Minute
+)
+
+// ErrProviderUnavailable is returned without calling the provider while its
+// circuit breaker is open.
+var ErrProviderUnavailable = errors.New("provider temporarily unavailable")
+
+// ProviderAPIError is a non-2xx response from a provider API that survived
+// the transport's retries.
+type ProviderAPIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *ProviderAPIError) Error() string {
+	return fmt.Sprintf("%s api error: status %d, body: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Unauthorized reports whether the provider rejected the access token.
+func (e *ProviderAPIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// ProviderTransportConfig configures a ProviderTransport.
+type ProviderTransportConfig struct {
+	// RequestsPerSecond and Burst size the token bucket of each connection.
+	RequestsPerSecond float64
+	Burst             int
+	// MaxRetries bounds how often a rate-limited or failed idempotent request is retried.
+	MaxRetries int
+	// QuotaExhausted reports how long to pause a connection when the
+	// provider's rate-limit headers say its quota is used up. Optional.
+	QuotaExhausted func(h http.Header) (time.Duration, bool)
+	// Base is the underlying transport; nil uses http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// ProviderTransport is the http.RoundTripper shared by provider API clients.
+// It limits each connection (access token) to its own token bucket, backs off
+// on 429s honoring Retry-After up to a cap, retries idempotent requests on 5xx responses,
+// and stops calling a provider that keeps failing until it recovers.
+type ProviderTransport struct {
+	provider string
+	cfg      ProviderTransportConfig
+	base     http.RoundTripper
+
+	mu        sync.Mutex
+	conns     map[string]*providerConnLimiter
+	lastPrune time.Time
+
+	breaker providerBreaker
+	stats   providerTransportStats
+}
+
+// NewProviderTransport creates a ProviderTransport for the named provider.
+func NewProviderTransport(provider string, cfg ProviderTransportConfig) *ProviderTransport {
+	base := cfg.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &ProviderTransport{
+		provider:  provider,
+		cfg:       cfg,
+		base:      base,
+		conns:     make(map[string]*providerConnLimiter),
+		lastPrune: time.Now(),
+	}
+}
+
+// Provider returns the name of the provider this transport calls.
+func (t *ProviderTransport) Provider() string {
+	return t.provider
+}
+
+// Client returns an http.Client that sends requests through the transport.
+func (t *ProviderTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ProviderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	conn := t.connection(req)
+	idempotent := isIdempotentRequest(req)
+
+	for attempt := 0; ; attempt++ {
+		if err := conn.wait(ctx); err != nil {
+			closeUnsentBody(req, attempt)
+			return nil, err
+		}
+		if !t.breaker.allow() {
+			closeUnsentBody(req, attempt)
+			t.stats.rejected.Add(1)
+			return nil, fmt.Errorf("%s: %w", t.provider, ErrProviderUnavailable)
+		}
+
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			t.breaker.release()
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := t.base.RoundTrip(attemptReq)
+		t.stats.observe(resp, err, time.Since(start))
+		if err != nil {
+			if ctx.Err() != nil {
+				t.breaker.release()
+			} else {
+				t.breaker.failure()
+			}
+			return nil, err
+		}
+
+		if t.cfg.QuotaExhausted != nil {
+			if d, ok := t.cfg.QuotaExhausted(resp.Header); ok {
+				conn.pause(d)
+			}
+		}
+
+		var delay time.Duration
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			// The provider is up; only this connection is over its limit.
+			// A 429 means the request was not processed, so any method may be retried.
+			t.breaker.success()
+			delay = retryDelay(resp.Header, attempt)
+			conn.pause(min(delay, providerRetryAfterMax))
+		case isRetryableStatus(resp.StatusCode):
+			t.breaker.failure()
+			if !idempotent {
+				return resp, nil
+			}
+			delay = retryDelay(resp.Header, attempt)
+		default:
+			t.breaker.success()
+			return resp, nil
+		}
+
+		if attempt >= t.cfg.MaxRetries || delay > providerRetryAfterMax || !canRewind(req) || !fitsDeadline(ctx, delay) {
+			return resp, nil
+		}
+
+		// Drain so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		t.stats.retries.Add(1)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Stats returns a snapshot of the transport's request metrics.
+func (t *ProviderTransport) Stats() ProviderTransportStats {
+	return ProviderTransportStats{
+		Provider:        t.provider,
+		Requests:        t.stats.requests.Load(),
+		Retries:         t.stats.retries.Load(),
+		RateLimited:     t.stats.rateLimited.Load(),
+		ServerErrors:    t.stats.serverErrors.Load(),
+		TransportErrors: t.stats.transportErrors.Load(),
+		Rejected:        t.stats.rejected.Load(),
+		AvgLatencyMs:    t.stats.avgLatencyMs(),
+		Breaker:         t.breaker.state(),
+	}
+}
+
+// connection returns the limiter of the connection a request belongs to.
+// Connections are keyed by a hash of their credentials.
+func (t *ProviderTransport) connection(req *http.Request) *providerConnLimiter {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization") + "\x00" + req.Header.Get("Stripe-Account")))
+	key := hex.EncodeToString(sum[:])
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.lastPrune) > providerLimiterIdleTTL {
+		for k, c := range t.conns {
+			if c.idleSince(now) > providerLimiterIdleTTL {
+				delete(t.conns, k)
+			}
+		}
+		t.lastPrune = now
+	}
+
+	c, ok := t.conns[key]
+	if !ok {
+		c = &providerConnLimiter{limiter: rate.NewLimiter(rate.Limit(t.cfg.RequestsPerSecond), t.cfg.Burst)}
+		t.conns[key] = c
+	}
+	c.touch(now)
+	return c
+}
+
+// ProviderTransportStats is a snapshot of a ProviderTransport's request metrics.
+type ProviderTransportStats struct {
+	Provider        string  `json:"provider"`
+	Requests        int64   `json:"requests"`
+	Retries         int64   `json:"retries"`
+	RateLimited     int64   `json:"rate_limited"`
+	ServerErrors    int64   `json:"server_errors"`
+	TransportErrors int64   `json:"transport_errors"`
+	Rejected        int64   `json:"rejected"`
+	AvgLatencyMs    float64 `json:"avg_latency_ms"`
+	Breaker         string  `json:"breaker"`
+}
+
+type providerTransportStats struct {
+	requests        atomic.Int64
+	retries         atomic.Int64
+	rateLimited     atomic.Int64
+	serverErrors    atomic.Int64
+	transportErrors atomic.Int64
+	rejected        atomic.Int64
+	latencyMicros   atomic.Int64
+}
+
+func (s *providerTransportStats) observe(resp *http.Response, err error, elapsed time.Duration) {
+	s.requests.Add(1)
+	s.latencyMicros.Add(elapsed.Microseconds())
+	switch {
+	case err != nil:
+		s.transportErrors.Add(1)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		s.rateLimited.Add(1)
+	case resp.StatusCode >= 500:
+		s.serverErrors.Add(1)
+	}
+}
+
+func (s *providerTransportStats) avgLatencyMs() float64 {
+	n := s.requests.Load()
+	if n == 0 {
+		return 0
+	}
+	return float64(s.latencyMicros.Load()) / float64(n) / 1000
+}
+
+// providerConnLimiter rate-limits one connection.
+type providerConnLimiter struct {
+	limiter *rate.Limiter
+
+	mu           sync.Mutex
+	pausedUntil  time.Time
+	lastUsedTime time.Time
+}
+
+func (c *providerConnLimiter) touch(now time.Time) {
+	c.mu.Lock()
+	c.lastUsedTime = now
+	c.mu.Unlock()
+}
+
+func (c *providerConnLimiter) idleSince(now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return now.Sub(c.lastUsedTime)
+}
+
+// pause holds back every request of the connection for d.
+func (c *providerConnLimiter) pause(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until := time.Now().Add(d); until.After(c.pausedUntil) {
+		c.pausedUntil = until
+	}
+}
+
+func (c *providerConnLimiter) wait(ctx context.Context) error {
+	c.mu.Lock()
+	pausedFor := time.Until(c.pausedUntil)
+	c.mu.Unlock()
+
+	if pausedFor > 0 {
+		if err := sleepContext(ctx, pausedFor); err != nil {
+			return err
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limiter: %w", err)
+	}
+	return nil
+}
+
+// providerBreaker is a consecutive-failure circuit breaker.
+type providerBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a request may be sent. Once the cooldown has passed,
+// a single probe request is let through; its outcome closes or reopens the circuit.
+func (b *providerBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < providerBreakerThreshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *providerBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *providerBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.failures >= providerBreakerThreshold {
+		b.openUntil = time.Now().Add(providerBreakerCooldown)
+	}
+}
+
+// release gives up a request's slot without recording an outcome, e.g. when
+// the caller cancelled it.
+func (b *providerBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *providerBreaker) state() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.failures < providerBreakerThreshold:
+		return "closed"
+	case b.probing || !time.Now().Before(b.openUntil):
+		return "half_open"
+	default:
+		return "open"
+	}
+}
+
+type idempotentRequestKey struct{}
+
+// withIdempotentRequest marks requests made with ctx as safe to retry even
+// though their method is not idempotent, e.g. read-only search POSTs.
+func withIdempotentRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentRequestKey{}, true)
+}
+
+func isIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if req.Header.Get("Idempotency-Key") != "" {
+		return true
+	}
+	marked, _ := req.Context().Value(idempotentRequestKey{}).(bool)
+	return marked
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay honors Retry-After (seconds or an HTTP date) and otherwise backs
+// off exponentially with jitter.
+func retryDelay(h http.Header, attempt int) time.Duration {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return max(time.Until(at), 0)
+		}
+	}
+
+	delay := min(providerRetryBaseDelay<<attempt, providerRetryMaxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// fitsDeadline reports whether waiting d still leaves time before ctx expires.
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
+
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest returns the request to send for an attempt, with a fresh body for retries.
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewind request body: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// closeUnsentBody closes the request body when RoundTrip returns before the
+// base transport, which would otherwise have closed it, ever saw the request.
+func closeUnsentBody(req *http.Request, attempt int) {
+	if attempt == 0 && req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// PublishProviderTransportStats exposes the metrics of the given transports
+// as the "provider_http" expvar, keyed by provider.
+func PublishProviderTransportStats(transports ...*ProviderTransport) {
+	expvar.Publish("provider_http", expvar.Func(func() any {
+		stats := make(map[string]ProviderTransportStats, len(transports))
+		for _, t := range transports {
+			stats[t.provider] = t.Stats()
+		}
+		return stats
+	}))
+}
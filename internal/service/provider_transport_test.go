@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestProviderServer serves the given statuses in order, repeating the
+// last one, and counts the requests it receives.
+func newTestProviderServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		status := statuses[min(n, len(statuses)-1)]
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func newTestProviderTransport(maxRetries int) *ProviderTransport {
+	return NewProviderTransport("test", ProviderTransportConfig{RequestsPerSecond: 1000, Burst: 1000, MaxRetries: maxRetries})
+}
+
+func doProviderRequest(t *testing.T, client *http.Client, ctx context.Context, method, url, token string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestProviderTransport_RetriesAfterRetryAfter(t *testing.T) {
+	srv, calls := newTestProviderServer(t, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests, http.StatusOK)
+	client := newTestProviderTransport(2).Client()
+
+	start := time.Now()
+	resp, err := doProviderRequest(t, client, context.Background(), http.MethodPost, srv.URL, "token-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("expected 200 after one retry, got %d after %d calls", resp.StatusCode, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected the retry to wait for Retry-After, waited %s", elapsed)
+	}
+}
+
+func TestProviderTransport_RateLimitPausesConnection(t *testing.T) {
+	srv, _ := newTestProviderServer(t, http.Header{"Retry-After": {"30"}}, http.StatusTooManyRequests, http.StatusOK)
+	client := newTestProviderTransport(0).Client()
+
+	resp, err := doProviderRequest(t, client, context.Background(), http.MethodGet, srv.URL, "token-a")
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the 429 to be returned without retries, got %v, %v", resp, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := doProviderRequest(t, client, ctx, http.MethodGet, srv.URL, "token-a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the rate-limited connection to wait, got %v", err)
+	}
+
+	// Other connections are not held back
+	resp, err = doProviderRequest(t, client, context.Background(), http.MethodGet, srv.URL, "token-b")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("expected another connection to go through, got %v, %v", resp, err)
+	}
+}
+
+func TestProviderTransport_LongRetryAfterNotWaited(t *testing.T) {
+	retryAt := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	for _, retryAfter := range []string{"3600", retryAt} {
+		srv, calls := newTestProviderServer(t, http.Header{"Retry-After": {retryAfter}}, http.StatusTooManyRequests, http.StatusOK)
+		transport := newTestProviderTransport(3)
+
+		start := time.Now()
+		resp, err := doProviderRequest(t, transport.Client(), context.Background(), http.MethodGet, srv.URL, "token-a")
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+			t.Fatalf("Retry-After %s: expected the 429 to be returned without retries, got %v, %v after %d calls", retryAfter, resp, err, calls.Load())
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Retry-After %s: expected no wait, took %s", retryAfter, elapsed)
+		}
+
+		for _, conn := range transport.conns {
+			if pausedFor := time.Until(conn.pausedUntil); pausedFor > providerRetryAfterMax {
+				t.Errorf("Retry-After %s: expected the connection to pause at most %s, paused for %s", retryAfter, providerRetryAfterMax, pausedFor)
+			}
+		}
+	}
+}
+
+func TestProviderTransport_NonIdempotentServerErrorNotRetried(t *testing.T) {
+	srv, calls := newTestProviderServer(t, nil, http.StatusServiceUnavailable, http.StatusOK)
+	client := newTestProviderTransport(3).Client()
+
+	resp, err := doProviderRequest(t, client, context.Background(), http.MethodPost, srv.URL, "token-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("expected the 503 to be passed through after 1 call, got %d after %d calls", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestProviderTransport_IdempotentServerErrorRetried(t *testing.T) {
+	srv, calls := newTestProviderServer(t, http.Header{"Retry-After": {"0"}}, http.StatusBadGateway, http.StatusOK)
+	client := newTestProviderTransport(3).Client()
+
+	resp, err := doProviderRequest(t, client, context.Background(), http.MethodGet, srv.URL, "token-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("expected 200 after one retry, got %d after %d calls", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestProviderTransport_Breaker(t *testing.T) {
+	srv, calls := newTestProviderServer(t, nil, http.StatusInternalServerError)
+	transport := newTestProviderTransport(0)
+	client := transport.Client()
+
+	for range providerBreakerThreshold {
+		if _, err := doProviderRequest(t, client, context.Background(), http.MethodGet, srv.URL, "token-a"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if state := transport.Stats().Breaker; state != "open" {
+		t.Fatalf("expected the breaker to open, got %s", state)
+	}
+
+	// Open: requests fail without reaching the provider
+	if _, err := doProviderRequest(t, client, context.Background(), http.MethodGet, srv.URL, "token-b"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("expected ErrProviderUnavailable, got %v", err)
+	}
+	if calls.Load() != providerBreakerThreshold {
+		t.Fatalf("expected no call while open, got %d calls", calls.Load())
+	}
+
+	// Half-open: once the cooldown has passed, one probe is let through
+	transport.breaker.mu.Lock()
+	transport.breaker.openUntil = time.Now().Add(-time.Second)
+	transport.breaker.mu.Unlock()
+	if state := transport.Stats().Breaker; state != "half_open" {
+		t.Fatalf("expected the breaker to be half open, got %s", state)
+	}
+	if !transport.breaker.allow() {
+		t.Fatal("expected a probe to be allowed")
+	}
+	if transport.breaker.allow() {
+		t.Fatal("expected a single probe at a time")
+	}
+	transport.breaker.release()
+
+	// A failed probe reopens the circuit
+	if _, err := doProviderRequest(t, client, context.Background(), http.MethodGet, srv.URL, "token-a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state := transport.Stats().Breaker; state != "open" {
+		t.Fatalf("expected a failed probe to reopen the breaker, got %s", state)
+	}
+
+	// A successful probe closes it
+	ok, _ := newTestProviderServer(t, nil, http.StatusOK)
+	transport.breaker.mu.Lock()
+	transport.breaker.openUntil = time.Now().Add(-time.Second)
+	transport.breaker.mu.Unlock()
+	resp, err := doProviderRequest(t, client, context.Background(), http.MethodGet, ok.URL, "token-a")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the probe to succeed, got %v, %v", resp, err)
+	}
+	if state := transport.Stats().Breaker; state != "closed" {
+		t.Errorf("expected a successful probe to close the breaker, got %s", state)
+	}
+}
+
+func TestProviderTransport_ConnectionKeying(t *testing.T) {
+	transport := NewProviderTransport("test", ProviderTransportConfig{RequestsPerSecond: 0.001, Burst: 1})
+
+	newReq := func(token, account string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "https://api.example.com/v1/customers", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if account != "" {
+			req.Header.Set("Stripe-Account", account)
+		}
+		return req
+	}
+
+	a := transport.connection(newReq("token-a", ""))
+	if transport.connection(newReq("token-a", "")) != a {
+		t.Error("expected requests with the same token to share a limiter")
+	}
+	if transport.connection(newReq("token-b", "")) == a {
+		t.Error("expected another token to get its own limiter")
+	}
+	if transport.connection(newReq("token-a", "acct_1")) == a {
+		t.Error("expected another Stripe account to get its own limiter")
+	}
+
+	// token-a's single token is used up; token-b still has its own
+	srv, _ := newTestProviderServer(t, nil, http.StatusOK)
+	client := transport.Client()
+	if _, err := doProviderRequest(t, client, context.Background(), http.MethodGet, srv.URL, "token-a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := doProviderRequest(t, client, ctx, http.MethodGet, srv.URL, "token-a"); err == nil {
+		t.Error("expected token-a to be rate limited")
+	}
+	if _, err := doProviderRequest(t, client, context.Background(), http.MethodGet, srv.URL, "token-b"); err != nil {
+		t.Errorf("expected token-b to have its own limit, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	// realtimeBufferSize is how many undelivered events a subscriber may have
+	// queued before it is dropped and must reconnect with Last-Event-ID.
+	realtimeBufferSize = 64
+	// realtimeReplayLimit caps how many missed events are replayed on reconnect.
+	realtimeReplayLimit = 500
+	realtimeRetention   = 24 * time.Hour
+	realtimeReconnect   = 5 * time.Second
+)
+
+// RealtimeSubscription receives realtime events for one connected client.
+// Events is closed when the subscription is dropped.
+type RealtimeSubscription struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+	Events chan *repository.RealtimeEvent
+}
+
+// RealtimeBroker fans out realtime events to connected clients. Events are
+// written to realtime_events (mostly by database triggers) and announced with
+// Postgres NOTIFY, so every API instance sees every event.
+type RealtimeBroker struct {
+	events *repository.RealtimeEventRepository
+
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[*RealtimeSubscription]struct{}
+}
+
+// NewRealtimeBroker creates a new RealtimeBroker.
+func NewRealtimeBroker(events *repository.RealtimeEventRepository) *RealtimeBroker {
+	return &RealtimeBroker{
+		events: events,
+		subs:   make(map[uuid.UUID]map[*RealtimeSubscription]struct{}),
+	}
+}
+
+// Start listens for realtime event notifications until the context is cancelled,
+// reconnecting if the listen connection drops. It also prunes expired events.
+func (b *RealtimeBroker) Start(ctx context.Context) {
+	slog.Info("realtime broker started")
+
+	go b.prune(ctx)
+
+	for {
+		err := b.events.Listen(ctx, func(payload string) { b.dispatch(ctx, payload) })
+		if ctx.Err() != nil {
+			slog.Info("realtime broker stopped")
+			return
+		}
+		slog.Error("realtime broker: listen", "error", err)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("realtime broker stopped")
+			return
+		case <-time.After(realtimeReconnect):
+		}
+	}
+}
+
+// Subscribe registers a client for events in orgID addressed to the org or to userID.
+func (b *RealtimeBroker) Subscribe(orgID, userID uuid.UUID) *RealtimeSubscription {
+	sub := &RealtimeSubscription{
+		OrgID:  orgID,
+		UserID: userID,
+		Events: make(chan *repository.RealtimeEvent, realtimeBufferSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs[orgID] == nil {
+		b.subs[orgID] = make(map[*RealtimeSubscription]struct{})
+	}
+	b.subs[orgID][sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes a subscription and closes its channel. It is safe to call more than once.
+func (b *RealtimeBroker) Unsubscribe(sub *RealtimeSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(sub)
+}
+
+func (b *RealtimeBroker) removeLocked(sub *RealtimeSubscription) {
+	orgSubs, ok := b.subs[sub.OrgID]
+	if !ok {
+		return
+	}
+	if _, ok := orgSubs[sub]; !ok {
+		return
+	}
+	delete(orgSubs, sub)
+	close(sub.Events)
+	if len(orgSubs) == 0 {
+		delete(b.subs, sub.OrgID)
+	}
+}
+
+// Replay returns events the user missed after lastEventID, oldest first.
+func (b *RealtimeBroker) Replay(ctx context.Context, orgID, userID uuid.UUID, lastEventID int64) ([]*repository.RealtimeEvent, error) {
+	return b.events.ListSince(ctx, orgID, userID, lastEventID, realtimeReplayLimit)
+}
+
+// realtimeNotification is the NOTIFY payload written by notify_realtime_event().
+type realtimeNotification struct {
+	ID     int64      `json:"id"`
+	OrgID  uuid.UUID  `json:"org_id"`
+	UserID *uuid.UUID `json:"user_id"`
+}
+
+func (b *RealtimeBroker) dispatch(ctx context.Context, payload string) {
+	var n realtimeNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		slog.Error("realtime broker: decode notification", "error", err)
+		return
+	}
+
+	if !b.hasSubscribers(n.OrgID, n.UserID) {
+		return
+	}
+
+	event, err := b.events.GetByID(ctx, n.ID)
+	if err != nil {
+		slog.Error("realtime broker: load event", "id", n.ID, "error", err)
+		return
+	}
+	if event == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs[event.OrgID] {
+		if event.UserID != nil && *event.UserID != sub.UserID {
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			// A client this far behind reconnects and replays from its last event ID.
+			slog.Warn("realtime broker: dropping slow subscriber", "org_id", sub.OrgID, "user_id", sub.UserID)
+			b.removeLocked(sub)
+		}
+	}
+}
+
+func (b *RealtimeBroker) hasSubscribers(orgID uuid.UUID, userID *uuid.UUID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs[orgID] {
+		if userID == nil || *userID == sub.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *RealtimeBroker) prune(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := b.events.DeleteOlderThan(ctx, time.Now().Add(-realtimeRetention))
+			if err != nil {
+				slog.Error("realtime broker: prune events", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				slog.Info("realtime broker: pruned events", "deleted", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// reconcileTimeout bounds a single reconciliation; it lists every record the
+// provider has, so it gets longer than an incremental sync.
+const reconcileTimeout = 30 * time.Minute
+
+// ReconciliationService periodically compares local data with each provider's
+// API, stores a drift report per connection and optionally heals the drift.
+type ReconciliationService struct {
+	connRepo  *repository.IntegrationConnectionRepository
+	reports   *repository.ReconciliationReportRepository
+	providers *integration.Registry
+	interval  time.Duration
+	autoHeal  bool
+
+	// Per-org-and-provider lock to prevent overlapping reconciliations
+	locks map[string]*sync.Mutex
+	mu    sync.Mutex
+}
+
+// NewReconciliationService creates a new ReconciliationService.
+func NewReconciliationService(
+	connRepo *repository.IntegrationConnectionRepository,
+	reports *repository.ReconciliationReportRepository,
+	providers *integration.Registry,
+	intervalMinutes int,
+	autoHeal bool,
+) *ReconciliationService {
+	return &ReconciliationService{
+		connRepo:  connRepo,
+		reports:   reports,
+		providers: providers,
+		interval:  time.Duration(intervalMinutes) * time.Minute,
+		autoHeal:  autoHeal,
+		locks:     make(map[string]*sync.Mutex),
+	}
+}
+
+// Start begins the periodic reconciliation. Cancel the context to stop.
+func (s *ReconciliationService) Start(ctx context.Context) {
+	slog.Info("reconciliation scheduler started", "interval", s.interval, "auto_heal", s.autoHeal)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("reconciliation scheduler stopped")
+			return
+		case <-ticker.C:
+			s.runCycle(ctx)
+		}
+	}
+}
+
+func (s *ReconciliationService) runCycle(ctx context.Context) {
+	for _, p := range s.providers.All() {
+		conns, err := s.connRepo.ListActiveByProvider(ctx, p.Name())
+		if err != nil {
+			slog.Error("reconciliation: failed to list connections", "provider", p.Name(), "error", err)
+			continue
+		}
+
+		for _, conn := range conns {
+			lock := s.getLock(conn.OrgID, p.Name())
+			if !lock.TryLock() {
+				slog.Debug("reconciliation: skipping connection (already running)", "org_id", conn.OrgID, "provider", p.Name())
+				continue
+			}
+
+			go func(orgID uuid.UUID, p integration.Provider) {
+				defer lock.Unlock()
+				s.run(ctx, orgID, p, s.autoHeal, SyncTriggerScheduler)
+			}(conn.OrgID, p)
+		}
+	}
+}
+
+// RunAsync starts a reconciliation for one connection in the background. It
+// returns a ConflictError if one is already running for the connection.
+func (s *ReconciliationService) RunAsync(orgID uuid.UUID, p integration.Provider, heal bool, trigger string) error {
+	lock := s.getLock(orgID, p.Name())
+	if !lock.TryLock() {
+		return &ConflictError{Resource: "reconciliation", Message: "a reconciliation is already running for this integration"}
+	}
+
+	go func() {
+		defer lock.Unlock()
+		s.run(context.Background(), orgID, p, heal, trigger)
+	}()
+	return nil
+}
+
+// List returns an org's reconciliation reports for a provider, newest first.
+func (s *ReconciliationService) List(ctx context.Context, orgID uuid.UUID, provider string, limit, offset int) ([]*repository.ReconciliationReport, int, error) {
+	reports, total, err := s.reports.ListByOrgAndProvider(ctx, orgID, provider, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list reconciliation reports: %w", err)
+	}
+	return reports, total, nil
+}
+
+func (s *ReconciliationService) run(ctx context.Context, orgID uuid.UUID, p integration.Provider, heal bool, trigger string) {
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	defer cancel()
+
+	rep := &repository.ReconciliationReport{
+		OrgID:     orgID,
+		Provider:  p.Name(),
+		Trigger:   trigger,
+		Heal:      heal,
+		Status:    "succeeded",
+		StartedAt: time.Now(),
+	}
+
+	drift, err := p.Reconcile(ctx, orgID, heal)
+	if err != nil {
+		rep.Status = "failed"
+		rep.Error = err.Error()
+	}
+	if drift != nil {
+		rep.Checked = drift.Checked
+		rep.Missing = drift.Missing
+		rep.Stale = drift.Stale
+		rep.Orphaned = drift.Orphaned
+		rep.Healed = drift.Healed
+		rep.Items = make([]repository.ReconciliationItem, len(drift.Items))
+		for i, item := range drift.Items {
+			rep.Items[i] = repository.ReconciliationItem(item)
+		}
+	}
+
+	// The report is stored even if the run timed out
+	if err := s.reports.Create(context.WithoutCancel(ctx), rep); err != nil {
+		slog.Error("reconciliation: failed to store report", "org_id", orgID, "provider", p.Name(), "error", err)
+	}
+
+	slog.Info("reconciliation complete",
+		"org_id", orgID,
+		"provider", p.Name(),
+		"status", rep.Status,
+		"checked", rep.Checked,
+		"missing", rep.Missing,
+		"stale", rep.Stale,
+		"orphaned", rep.Orphaned,
+		"healed", rep.Healed,
+	)
+}
+
+func (s *ReconciliationService) getLock(orgID uuid.UUID, provider string) *sync.Mutex {
+	key := orgID.String() + ":" + provider
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.locks[key]; !ok {
+		s.locks[key] = &sync.Mutex{}
+	}
+	return s.locks[key]
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	defaultRenewalPipelineDays = 90
+	maxRenewalPipelineDays     = 365
+	maxRenewalNotesLength      = 2000
+	maxRenewalStageLength      = 255
+	renewalDateLayout          = "2006-01-02"
+)
+
+// defaultRenewalClosedStages are HubSpot's closed deal stages in its default
+// pipeline.
+var defaultRenewalClosedStages = []string{"closedwon", "closedlost"}
+
+// RenewalService derives customers' renewals and builds the renewals
+// pipeline. A renewal comes from the customer's manual override, an open
+// deal in the org's HubSpot renewal pipeline or its Stripe subscriptions'
+// current period end, in that order.
+type RenewalService struct {
+	renewals  *repository.RenewalRepository
+	customers *repository.CustomerRepository
+}
+
+// NewRenewalService creates a new RenewalService.
+func NewRenewalService(renewals *repository.RenewalRepository, customers *repository.CustomerRepository) *RenewalService {
+	return &RenewalService{renewals: renewals, customers: customers}
+}
+
+// RenewalPipelineParams filters the renewals pipeline. It covers renewals in
+// the next Days days, and past ones too when IncludeOverdue is set. Sort is
+// date, amount or score.
+type RenewalPipelineParams struct {
+	Days           int
+	IncludeOverdue bool
+	Risk           string
+	OwnerID        *uuid.UUID
+	MaxScore       *int
+	Sort           string
+	Order          string
+}
+
+// RenewalItem is a renewal with the days left until it; negative when it is
+// overdue.
+type RenewalItem struct {
+	*repository.Renewal
+	DaysUntil int `json:"days_until"`
+}
+
+// RenewalBucket totals a group of renewals.
+type RenewalBucket struct {
+	Count       int   `json:"count"`
+	AmountCents int64 `json:"amount_cents"`
+}
+
+// RenewalMonth totals the renewals due in a month, and the at-risk (red)
+// ones among them.
+type RenewalMonth struct {
+	Month             string `json:"month"`
+	Count             int    `json:"count"`
+	AmountCents       int64  `json:"amount_cents"`
+	AtRiskCount       int    `json:"at_risk_count"`
+	AtRiskAmountCents int64  `json:"at_risk_amount_cents"`
+}
+
+// RenewalPipelineSummary totals the pipeline's renewals overall, by risk
+// level (customers without a health score are "unscored") and by month.
+type RenewalPipelineSummary struct {
+	Count       int                      `json:"count"`
+	AmountCents int64                    `json:"amount_cents"`
+	ByRisk      map[string]RenewalBucket `json:"by_risk"`
+	ByMonth     []RenewalMonth           `json:"by_month"`
+}
+
+// RenewalPipeline is the response for the renewals pipeline.
+type RenewalPipeline struct {
+	From     *string                `json:"from"`
+	To       string                 `json:"to"`
+	Renewals []RenewalItem          `json:"renewals"`
+	Summary  RenewalPipelineSummary `json:"summary"`
+}
+
+// CustomerRenewal is a customer's resolved renewal, nil when none is known,
+// and its manual override, if any.
+type CustomerRenewal struct {
+	Renewal  *RenewalItem                `json:"renewal"`
+	Override *repository.RenewalOverride `json:"override"`
+}
+
+// SetRenewalOverrideRequest holds a manual renewal override. Without an
+// amount, the derived amount is kept.
+type SetRenewalOverrideRequest struct {
+	RenewalDate string  `json:"renewal_date"`
+	AmountCents *int64  `json:"amount_cents"`
+	Currency    *string `json:"currency"`
+	Notes       string  `json:"notes"`
+}
+
+// UpdateRenewalSettingsRequest holds renewal settings to change. An empty
+// hubspot_pipeline stops using HubSpot deals as renewals.
+type UpdateRenewalSettingsRequest struct {
+	HubSpotPipeline *string   `json:"hubspot_pipeline"`
+	ClosedStages    *[]string `json:"closed_stages"`
+}
+
+// Pipeline returns the org's upcoming renewals with their health scores and
+// totals.
+func (s *RenewalService) Pipeline(ctx context.Context, orgID uuid.UUID, params RenewalPipelineParams) (*RenewalPipeline, error) {
+	if params.Days == 0 {
+		params.Days = defaultRenewalPipelineDays
+	}
+	if params.Days < 1 || params.Days > maxRenewalPipelineDays {
+		return nil, &ValidationError{Field: "days", Message: fmt.Sprintf("days must be between 1 and %d", maxRenewalPipelineDays)}
+	}
+	switch params.Risk {
+	case "", "green", "yellow", "red":
+	default:
+		return nil, &ValidationError{Field: "risk", Message: "risk must be green, yellow or red"}
+	}
+	if params.MaxScore != nil && (*params.MaxScore < 0 || *params.MaxScore > 100) {
+		return nil, &ValidationError{Field: "max_score", Message: "max_score must be between 0 and 100"}
+	}
+	switch params.Sort {
+	case "", "date", "amount", "score":
+	default:
+		return nil, &ValidationError{Field: "sort", Message: "sort must be date, amount or score"}
+	}
+
+	today := renewalToday()
+	to := today.AddDate(0, 0, params.Days)
+	listParams := repository.RenewalListParams{
+		OrgID:    orgID,
+		To:       &to,
+		Risk:     params.Risk,
+		OwnerID:  params.OwnerID,
+		MaxScore: params.MaxScore,
+	}
+	pipeline := &RenewalPipeline{To: to.Format(renewalDateLayout)}
+	if !params.IncludeOverdue {
+		listParams.From = &today
+		from := today.Format(renewalDateLayout)
+		pipeline.From = &from
+	}
+
+	renewals, err := s.renewals.List(ctx, listParams)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline.Renewals = make([]RenewalItem, len(renewals))
+	for i, r := range renewals {
+		pipeline.Renewals[i] = newRenewalItem(r, today)
+	}
+	sortRenewals(pipeline.Renewals, params.Sort, params.Order)
+	pipeline.Summary = summarizeRenewals(renewals)
+	return pipeline, nil
+}
+
+// GetForCustomer returns a customer's renewal and manual override.
+func (s *RenewalService) GetForCustomer(ctx context.Context, orgID, customerID uuid.UUID) (*CustomerRenewal, error) {
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+	return s.customerRenewal(ctx, orgID, customerID)
+}
+
+// SetOverride sets a customer's renewal date and, optionally, amount by
+// hand. The override wins over HubSpot and Stripe until it is cleared.
+func (s *RenewalService) SetOverride(ctx context.Context, orgID, customerID, userID uuid.UUID, req SetRenewalOverrideRequest) (*CustomerRenewal, error) {
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse(renewalDateLayout, strings.TrimSpace(req.RenewalDate))
+	if err != nil {
+		return nil, &ValidationError{Field: "renewal_date", Message: "renewal_date must be a date (YYYY-MM-DD)"}
+	}
+	if req.AmountCents != nil && *req.AmountCents < 0 {
+		return nil, &ValidationError{Field: "amount_cents", Message: "amount_cents must not be negative"}
+	}
+	var currency *string
+	if req.Currency != nil && strings.TrimSpace(*req.Currency) != "" {
+		c := strings.ToUpper(strings.TrimSpace(*req.Currency))
+		if len(c) != 3 || strings.Trim(c, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return nil, &ValidationError{Field: "currency", Message: "currency must be a three-letter code"}
+		}
+		currency = &c
+	}
+	notes := strings.TrimSpace(req.Notes)
+	if utf8.RuneCountInString(notes) > maxRenewalNotesLength {
+		return nil, &ValidationError{Field: "notes", Message: fmt.Sprintf("notes must be at most %d characters", maxRenewalNotesLength)}
+	}
+
+	override := &repository.RenewalOverride{
+		CustomerID:  customerID,
+		OrgID:       orgID,
+		RenewalDate: date,
+		AmountCents: req.AmountCents,
+		Currency:    currency,
+		Notes:       notes,
+		UpdatedBy:   &userID,
+	}
+	if err := s.renewals.SetOverride(ctx, override); err != nil {
+		return nil, err
+	}
+	return s.customerRenewal(ctx, orgID, customerID)
+}
+
+// ClearOverride removes a customer's manual renewal override, so its
+// renewal is derived again.
+func (s *RenewalService) ClearOverride(ctx context.Context, orgID, customerID uuid.UUID) (*CustomerRenewal, error) {
+	if err := s.checkCustomer(ctx, customerID, orgID); err != nil {
+		return nil, err
+	}
+	if _, err := s.renewals.DeleteOverride(ctx, orgID, customerID); err != nil {
+		return nil, err
+	}
+	return s.customerRenewal(ctx, orgID, customerID)
+}
+
+// GetSettings returns an org's renewal settings, or the defaults.
+func (s *RenewalService) GetSettings(ctx context.Context, orgID uuid.UUID) (*repository.RenewalSettings, error) {
+	settings, err := s.renewals.GetSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &repository.RenewalSettings{OrgID: orgID, ClosedStages: slices.Clone(defaultRenewalClosedStages)}
+	}
+	return settings, nil
+}
+
+// UpdateSettings changes which HubSpot deals count as renewals.
+func (s *RenewalService) UpdateSettings(ctx context.Context, orgID, userID uuid.UUID, req UpdateRenewalSettingsRequest) (*repository.RenewalSettings, error) {
+	settings, err := s.GetSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.HubSpotPipeline != nil {
+		pipeline := strings.TrimSpace(*req.HubSpotPipeline)
+		switch {
+		case pipeline == "":
+			settings.HubSpotPipeline = nil
+		case len(pipeline) > maxRenewalStageLength:
+			return nil, &ValidationError{Field: "hubspot_pipeline", Message: fmt.Sprintf("hubspot_pipeline must be at most %d characters", maxRenewalStageLength)}
+		default:
+			settings.HubSpotPipeline = &pipeline
+		}
+	}
+	if req.ClosedStages != nil {
+		stages := []string{}
+		for _, stage := range *req.ClosedStages {
+			stage = strings.TrimSpace(stage)
+			if stage == "" || len(stage) > maxRenewalStageLength {
+				return nil, &ValidationError{Field: "closed_stages", Message: fmt.Sprintf("closed stages must be 1 to %d characters", maxRenewalStageLength)}
+			}
+			if !slices.Contains(stages, stage) {
+				stages = append(stages, stage)
+			}
+		}
+		settings.ClosedStages = stages
+	}
+
+	settings.UpdatedBy = &userID
+	if err := s.renewals.UpsertSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *RenewalService) customerRenewal(ctx context.Context, orgID, customerID uuid.UUID) (*CustomerRenewal, error) {
+	renewal, err := s.renewals.GetByCustomer(ctx, orgID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	override, err := s.renewals.GetOverride(ctx, orgID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CustomerRenewal{Override: override}
+	if renewal != nil {
+		item := newRenewalItem(renewal, renewalToday())
+		result.Renewal = &item
+	}
+	return result, nil
+}
+
+func (s *RenewalService) checkCustomer(ctx context.Context, customerID, orgID uuid.UUID) error {
+	customer, err := s.customers.GetByIDAndOrg(ctx, customerID, orgID)
+	if err != nil {
+		return fmt.Errorf("get customer: %w", err)
+	}
+	if customer == nil {
+		return &NotFoundError{Resource: "customer", Message: "customer not found"}
+	}
+	return nil
+}
+
+// renewalToday returns the start of the current UTC day; renewal dates are
+// whole days.
+func renewalToday() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// renewalDaysUntil returns the whole days from today to a renewal date.
+func renewalDaysUntil(date, today time.Time) int {
+	return int(date.Sub(today).Hours() / 24)
+}
+
+func newRenewalItem(r *repository.Renewal, today time.Time) RenewalItem {
+	if r.OwnerIDs == nil {
+		r.OwnerIDs = []uuid.UUID{}
+	}
+	return RenewalItem{Renewal: r, DaysUntil: renewalDaysUntil(r.RenewalDate, today)}
+}
+
+// sortRenewals orders renewals by sort, keeping the soonest first among
+// equal keys. Customers without a health score sort last by score.
+func sortRenewals(items []RenewalItem, sort, order string) {
+	desc := order == "desc"
+	slices.SortStableFunc(items, func(a, b RenewalItem) int {
+		var c int
+		switch sort {
+		case "amount":
+			c = cmp.Compare(a.AmountCents, b.AmountCents)
+		case "score":
+			switch {
+			case a.OverallScore == nil && b.OverallScore == nil:
+			case a.OverallScore == nil:
+				return 1
+			case b.OverallScore == nil:
+				return -1
+			default:
+				c = cmp.Compare(*a.OverallScore, *b.OverallScore)
+			}
+		default:
+			c = a.RenewalDate.Compare(b.RenewalDate)
+		}
+		if desc {
+			c = -c
+		}
+		return c
+	})
+}
+
+func summarizeRenewals(renewals []*repository.Renewal) RenewalPipelineSummary {
+	summary := RenewalPipelineSummary{
+		ByRisk: map[string]RenewalBucket{
+			"green":    {},
+			"yellow":   {},
+			"red":      {},
+			"unscored": {},
+		},
+		ByMonth: []RenewalMonth{},
+	}
+
+	months := map[string]int{}
+	for _, r := range renewals {
+		summary.Count++
+		summary.AmountCents += r.AmountCents
+
+		risk := "unscored"
+		if r.RiskLevel != nil {
+			risk = *r.RiskLevel
+		}
+		bucket := summary.ByRisk[risk]
+		bucket.Count++
+		bucket.AmountCents += r.AmountCents
+		summary.ByRisk[risk] = bucket
+
+		month := r.RenewalDate.Format("2006-01")
+		i, ok := months[month]
+		if !ok {
+			i = len(summary.ByMonth)
+			months[month] = i
+			summary.ByMonth = append(summary.ByMonth, RenewalMonth{Month: month})
+		}
+		m := &summary.ByMonth[i]
+		m.Count++
+		m.AmountCents += r.AmountCents
+		if risk == "red" {
+			m.AtRiskCount++
+			m.AtRiskAmountCents += r.AmountCents
+		}
+	}
+	return summary
+}
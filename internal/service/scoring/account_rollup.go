@@ -0,0 +1,166 @@
+package scoring
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
+)
+
+// AccountAlertCallback is called after an account score is stored, for real-time alert evaluation.
+type AccountAlertCallback func(ctx context.Context, accountID, orgID uuid.UUID)
+
+// AccountRollup rolls contact health scores up into account health scores
+// using the org's configured aggregation.
+type AccountRollup struct {
+	accountSvc    *service.AccountService
+	accounts      *repository.AccountRepository
+	accountScores *repository.AccountHealthScoreRepository
+	configRepo    *repository.ScoringConfigRepository
+	alertCallback AccountAlertCallback
+}
+
+// NewAccountRollup creates a new AccountRollup.
+func NewAccountRollup(
+	accountSvc *service.AccountService,
+	accounts *repository.AccountRepository,
+	accountScores *repository.AccountHealthScoreRepository,
+	configRepo *repository.ScoringConfigRepository,
+) *AccountRollup {
+	return &AccountRollup{
+		accountSvc:    accountSvc,
+		accounts:      accounts,
+		accountScores: accountScores,
+		configRepo:    configRepo,
+	}
+}
+
+// SetAlertCallback registers a callback for real-time alert evaluation after account score changes.
+func (r *AccountRollup) SetAlertCallback(cb AccountAlertCallback) {
+	r.alertCallback = cb
+}
+
+// RollupOrg re-links an org's customers to accounts and recalculates every
+// account score. Accounts left without scored contacts lose their score.
+func (r *AccountRollup) RollupOrg(ctx context.Context, orgID uuid.UUID) error {
+	if _, err := r.accountSvc.Sync(ctx, orgID); err != nil {
+		return fmt.Errorf("sync accounts: %w", err)
+	}
+
+	now := time.Now()
+	if err := r.rollup(ctx, orgID, nil, now); err != nil {
+		return err
+	}
+	return r.accountScores.DeleteStale(ctx, orgID, now)
+}
+
+// RollupCustomer recalculates the score of the account a customer belongs to.
+func (r *AccountRollup) RollupCustomer(ctx context.Context, customerID, orgID uuid.UUID) error {
+	accountID, err := r.accounts.GetIDByCustomer(ctx, customerID, orgID)
+	if err != nil || accountID == nil {
+		return err
+	}
+	return r.rollup(ctx, orgID, accountID, time.Now())
+}
+
+// rollup recalculates the scores of an org's accounts, or of one account if
+// accountID is set.
+func (r *AccountRollup) rollup(ctx context.Context, orgID uuid.UUID, accountID *uuid.UUID, now time.Time) error {
+	config, err := r.configRepo.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("get scoring config: %w", err)
+	}
+	if config == nil {
+		config, err = r.configRepo.CreateDefault(ctx, orgID)
+		if err != nil {
+			return fmt.Errorf("create default scoring config: %w", err)
+		}
+	}
+
+	contacts, err := r.accounts.ListContactScores(ctx, orgID, accountID)
+	if err != nil {
+		return err
+	}
+
+	for id, scores := range contacts {
+		overall, factors := aggregateAccountScore(scores, config.AccountAggregation)
+		score := &repository.AccountHealthScore{
+			OrgID:        orgID,
+			AccountID:    id,
+			OverallScore: overall,
+			RiskLevel:    assignRiskLevel(overall, config.Thresholds),
+			Aggregation:  config.AccountAggregation,
+			ContactCount: len(scores),
+			Factors:      factors,
+			CalculatedAt: now,
+		}
+		if err := r.accountScores.UpsertCurrent(ctx, score); err != nil {
+			slog.Error("account score upsert error", "account_id", id, "error", err)
+			continue
+		}
+
+		if r.alertCallback != nil {
+			r.alertCallback(ctx, id, orgID)
+		}
+	}
+	return nil
+}
+
+// aggregateAccountScore combines an account's contact scores into a 0-100
+// account score. mrr_weighted falls back to the mean when no contact pays.
+// The factors record every aggregation plus the share of contacts and of MRR
+// at red risk, all on a 0-1 scale.
+func aggregateAccountScore(contacts []repository.AccountContactScore, aggregation string) (int, map[string]float64) {
+	var (
+		sum, weightedSum         float64
+		totalMRR, atRiskMRR, red int
+	)
+	lowest := 100
+	for _, c := range contacts {
+		sum += float64(c.OverallScore)
+		lowest = min(lowest, c.OverallScore)
+		if c.MRRCents > 0 {
+			weightedSum += float64(c.OverallScore) * float64(c.MRRCents)
+			totalMRR += c.MRRCents
+		}
+		if c.RiskLevel == "red" {
+			red++
+			if c.MRRCents > 0 {
+				atRiskMRR += c.MRRCents
+			}
+		}
+	}
+
+	mean := sum / float64(len(contacts))
+	mrrWeighted := mean
+	atRiskMRRShare := 0.0
+	if totalMRR > 0 {
+		mrrWeighted = weightedSum / float64(totalMRR)
+		atRiskMRRShare = float64(atRiskMRR) / float64(totalMRR)
+	}
+
+	factors := map[string]float64{
+		repository.AccountAggregationMean:        mean / 100,
+		repository.AccountAggregationMin:         float64(lowest) / 100,
+		repository.AccountAggregationMRRWeighted: mrrWeighted / 100,
+		"at_risk_contacts":                       float64(red) / float64(len(contacts)),
+		"at_risk_mrr":                            atRiskMRRShare,
+	}
+
+	var score float64
+	switch aggregation {
+	case repository.AccountAggregationMin:
+		score = float64(lowest)
+	case repository.AccountAggregationMean:
+		score = mean
+	default:
+		score = mrrWeighted
+	}
+	return int(math.Round(score)), factors
+}
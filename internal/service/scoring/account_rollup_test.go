@@ -0,0 +1,52 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+func TestAggregateAccountScore(t *testing.T) {
+	paying := []repository.AccountContactScore{
+		{OverallScore: 80, MRRCents: 10000, RiskLevel: "green"},
+		{OverallScore: 40, MRRCents: 30000, RiskLevel: "red"},
+		{OverallScore: 90, RiskLevel: "green"},
+	}
+	unpaid := []repository.AccountContactScore{
+		{OverallScore: 80, RiskLevel: "green"},
+		{OverallScore: 41, RiskLevel: "red"},
+	}
+
+	tests := []struct {
+		name        string
+		contacts    []repository.AccountContactScore
+		aggregation string
+		want        int
+		factors     map[string]float64
+	}{
+		{
+			"mrr weighted", paying, repository.AccountAggregationMRRWeighted, 50,
+			map[string]float64{"mean": 0.7, "min": 0.4, "mrr_weighted": 0.5, "at_risk_contacts": 1.0 / 3, "at_risk_mrr": 0.75},
+		},
+		{"mean", paying, repository.AccountAggregationMean, 70, nil},
+		{"min", paying, repository.AccountAggregationMin, 40, nil},
+		{"unknown aggregation is mrr weighted", paying, "", 50, nil},
+		{
+			"mrr weighted falls back to the mean without MRR", unpaid, repository.AccountAggregationMRRWeighted, 61,
+			map[string]float64{"mean": 0.605, "min": 0.41, "mrr_weighted": 0.605, "at_risk_contacts": 0.5, "at_risk_mrr": 0},
+		},
+		{"single contact", []repository.AccountContactScore{{OverallScore: 33, MRRCents: 500}}, repository.AccountAggregationMRRWeighted, 33, nil},
+	}
+	for _, tt := range tests {
+		got, factors := aggregateAccountScore(tt.contacts, tt.aggregation)
+		if got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+		for name, want := range tt.factors {
+			if math.Abs(factors[name]-want) > 1e-9 {
+				t.Errorf("%s: expected factor %s to be %v, got %v", tt.name, name, want, factors[name])
+			}
+		}
+	}
+}
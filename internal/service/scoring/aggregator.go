@@ -25,6 +25,7 @@ type HealthScoreResult struct {
 // ScoreAggregator computes weighted overall health scores from individual factors.
 type ScoreAggregator struct {
 	factors    []ScoreFactor
+	source     FactorSource
 	configRepo *repository.ScoringConfigRepository
 }
 
@@ -39,6 +40,12 @@ func NewScoreAggregator(
 	}
 }
 
+// SetFactorSource registers a source of org-defined factors. Its factors only
+// count toward a score when the org's scoring config weights them.
+func (a *ScoreAggregator) SetFactorSource(source FactorSource) {
+	a.source = source
+}
+
 // Calculate computes the weighted health score for a customer.
 func (a *ScoreAggregator) Calculate(ctx context.Context, customerID, orgID uuid.UUID) (*HealthScoreResult, error) {
 	// Load scoring config for org
@@ -78,6 +85,25 @@ func (a *ScoreAggregator) Calculate(ctx context.Context, customerID, orgID uuid.
 		}
 	}
 
+	if a.source != nil {
+		results, err := a.source.Factors(ctx, customerID, orgID)
+		if err != nil {
+			slog.Error("factor source error",
+				"customer_id", customerID,
+				"error", err,
+			)
+		}
+		for _, result := range results {
+			weight, weighted := config.Weights[result.Name]
+			if !weighted || result.Score == nil {
+				continue
+			}
+			presentFactors = append(presentFactors, result)
+			presentWeightSum += weight
+			factorScores[result.Name] = *result.Score
+		}
+	}
+
 	// Edge case: no factors available
 	if len(presentFactors) == 0 {
 		return nil, fmt.Errorf("no scoring factors available for customer %s", customerID)
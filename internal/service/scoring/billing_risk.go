@@ -0,0 +1,91 @@
+package scoring
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// billingRiskWeights is the score impact of each billing event type. Negative
+// values are churn signals; positive values offset them.
+var billingRiskWeights = map[string]float64{
+	"dispute.lost":          -0.5,
+	"dispute.opened":        -0.35,
+	"subscription.past_due": -0.3,
+	"subscription.paused":   -0.25,
+	"plan.downgraded":       -0.2,
+	"payment.refunded":      -0.15,
+	"dispute.won":           0.15,
+	"subscription.resumed":  0.1,
+	"plan.upgraded":         0.1,
+}
+
+// billingEventLister is the part of CustomerEventRepository the billing risk
+// factor uses.
+type billingEventLister interface {
+	ListByCustomerAndTypes(ctx context.Context, customerID uuid.UUID, eventTypes []string, since time.Time) ([]*repository.CustomerEvent, error)
+}
+
+// BillingRiskFactor scores recent billing events recorded from Stripe webhooks.
+type BillingRiskFactor struct {
+	events billingEventLister
+}
+
+// NewBillingRiskFactor creates a new BillingRiskFactor.
+func NewBillingRiskFactor(events *repository.CustomerEventRepository) *BillingRiskFactor {
+	return &BillingRiskFactor{events: events}
+}
+
+// Name returns the factor name.
+func (f *BillingRiskFactor) Name() string {
+	return "billing_risk"
+}
+
+// Calculate starts from a perfect score and applies each billing event's
+// weight over the last 90 days, halving events older than 30 days.
+// Returns nil if the customer has no billing events (factor skipped in aggregation).
+func (f *BillingRiskFactor) Calculate(ctx context.Context, customerID, orgID uuid.UUID) (*FactorResult, error) {
+	now := time.Now()
+
+	eventTypes := make([]string, 0, len(billingRiskWeights))
+	for t := range billingRiskWeights {
+		eventTypes = append(eventTypes, t)
+	}
+
+	events, err := f.events.ListByCustomerAndTypes(ctx, customerID, eventTypes, now.AddDate(0, 0, -90))
+	if err != nil {
+		return nil, fmt.Errorf("list billing events: %w", err)
+	}
+	if len(events) == 0 {
+		return &FactorResult{Name: f.Name(), Score: nil}, nil
+	}
+
+	recent := now.AddDate(0, 0, -30)
+	score := 1.0
+	for _, e := range events {
+		weight := billingRiskWeights[e.EventType]
+		if e.OccurredAt.Before(recent) {
+			weight /= 2
+		}
+		// A partial refund is a weaker signal than a full one
+		if e.EventType == "payment.refunded" {
+			if full, _ := e.Data["full_refund"].(bool); !full {
+				weight /= 2
+			}
+		}
+		score += weight
+	}
+
+	if score < 0 {
+		score = 0
+	}
+	if score > 1 {
+		score = 1
+	}
+
+	return &FactorResult{Name: f.Name(), Score: &score}, nil
+}
@@ -0,0 +1,102 @@
+package scoring
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+type fakeBillingEventLister struct {
+	events []*repository.CustomerEvent
+	since  time.Time
+	types  []string
+}
+
+func (f *fakeBillingEventLister) ListByCustomerAndTypes(ctx context.Context, customerID uuid.UUID, eventTypes []string, since time.Time) ([]*repository.CustomerEvent, error) {
+	f.since = since
+	f.types = eventTypes
+	return f.events, nil
+}
+
+func TestBillingRiskFactor_Calculate(t *testing.T) {
+	recent := time.Now().AddDate(0, 0, -5)
+	old := time.Now().AddDate(0, 0, -45)
+	event := func(eventType string, at time.Time, data map[string]any) *repository.CustomerEvent {
+		return &repository.CustomerEvent{EventType: eventType, OccurredAt: at, Data: data}
+	}
+	fullRefund := map[string]any{"full_refund": true}
+
+	tests := []struct {
+		name   string
+		events []*repository.CustomerEvent
+		want   float64
+	}{
+		{"lost dispute", []*repository.CustomerEvent{event("dispute.lost", recent, nil)}, 0.5},
+		{"opened dispute", []*repository.CustomerEvent{event("dispute.opened", recent, nil)}, 0.65},
+		{"past due", []*repository.CustomerEvent{event("subscription.past_due", recent, nil)}, 0.7},
+		{"paused", []*repository.CustomerEvent{event("subscription.paused", recent, nil)}, 0.75},
+		{"downgrade", []*repository.CustomerEvent{event("plan.downgraded", recent, nil)}, 0.8},
+		{"older than 30 days is halved", []*repository.CustomerEvent{event("dispute.lost", old, nil)}, 0.75},
+		{"full refund", []*repository.CustomerEvent{event("payment.refunded", recent, fullRefund)}, 0.85},
+		{"partial refund is halved", []*repository.CustomerEvent{event("payment.refunded", recent, map[string]any{"full_refund": false})}, 0.925},
+		{"refund without data counts as partial", []*repository.CustomerEvent{event("payment.refunded", recent, nil)}, 0.925},
+		{"old partial refund is halved twice", []*repository.CustomerEvent{event("payment.refunded", old, nil)}, 0.9625},
+		{
+			"positive events offset negative ones",
+			[]*repository.CustomerEvent{
+				event("subscription.past_due", recent, nil),
+				event("subscription.resumed", recent, nil),
+				event("dispute.opened", recent, nil),
+				event("dispute.won", recent, nil),
+			},
+			0.6,
+		},
+		{
+			"clamped at zero",
+			[]*repository.CustomerEvent{
+				event("dispute.lost", recent, nil),
+				event("dispute.lost", recent, nil),
+				event("subscription.past_due", recent, nil),
+			},
+			0,
+		},
+		{"clamped at one", []*repository.CustomerEvent{event("plan.upgraded", recent, nil), event("dispute.won", recent, nil)}, 1},
+	}
+	for _, tt := range tests {
+		f := &BillingRiskFactor{events: &fakeBillingEventLister{events: tt.events}}
+		result, err := f.Calculate(context.Background(), uuid.New(), uuid.New())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if result.Score == nil {
+			t.Fatalf("%s: expected a score", tt.name)
+		}
+		if math.Abs(*result.Score-tt.want) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, *result.Score)
+		}
+	}
+}
+
+func TestBillingRiskFactor_Calculate_NoEvents(t *testing.T) {
+	lister := &fakeBillingEventLister{}
+	f := &BillingRiskFactor{events: lister}
+	result, err := f.Calculate(context.Background(), uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Name != "billing_risk" || result.Score != nil {
+		t.Errorf("expected the factor to be skipped, got %+v", result)
+	}
+
+	if days := time.Since(lister.since).Hours() / 24; math.Abs(days-90) > 1 {
+		t.Errorf("expected events from the last 90 days, got since %s", lister.since)
+	}
+	if len(lister.types) != len(billingRiskWeights) {
+		t.Errorf("expected every weighted event type to be listed, got %v", lister.types)
+	}
+}
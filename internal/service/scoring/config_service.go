@@ -41,8 +41,9 @@ func (s *ConfigService) GetConfig(ctx context.Context, orgID uuid.UUID) (*reposi
 
 // UpdateConfigRequest holds the fields for updating scoring config.
 type UpdateConfigRequest struct {
-	Weights    map[string]float64 `json:"weights"`
-	Thresholds map[string]int     `json:"thresholds"`
+	Weights            map[string]float64 `json:"weights"`
+	Thresholds         map[string]int     `json:"thresholds"`
+	AccountAggregation *string            `json:"account_aggregation"`
 }
 
 // UpdateConfig validates and updates the scoring config, then triggers recalculation.
@@ -57,6 +58,11 @@ func (s *ConfigService) UpdateConfig(ctx context.Context, orgID uuid.UUID, req U
 			return nil, &service.ValidationError{Field: "thresholds", Message: err.Error()}
 		}
 	}
+	if req.AccountAggregation != nil {
+		if err := repository.ValidateAccountAggregation(*req.AccountAggregation); err != nil {
+			return nil, &service.ValidationError{Field: "account_aggregation", Message: err.Error()}
+		}
+	}
 
 	// Get existing or create default
 	config, err := s.GetConfig(ctx, orgID)
@@ -70,6 +76,9 @@ func (s *ConfigService) UpdateConfig(ctx context.Context, orgID uuid.UUID, req U
 	if req.Thresholds != nil {
 		config.Thresholds = req.Thresholds
 	}
+	if req.AccountAggregation != nil {
+		config.AccountAggregation = *req.AccountAggregation
+	}
 
 	if err := s.configRepo.Upsert(ctx, config); err != nil {
 		return nil, fmt.Errorf("update scoring config: %w", err)
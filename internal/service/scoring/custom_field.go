@@ -0,0 +1,75 @@
+package scoring
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// CustomFieldFactors scores customers on the custom fields their org has set
+// up for scoring. Each field is a factor named field.<key>, weighted like any
+// other factor in the org's scoring config.
+type CustomFieldFactors struct {
+	fields *repository.CustomFieldRepository
+}
+
+// NewCustomFieldFactors creates a new CustomFieldFactors.
+func NewCustomFieldFactors(fields *repository.CustomFieldRepository) *CustomFieldFactors {
+	return &CustomFieldFactors{fields: fields}
+}
+
+// Factors returns a factor for each scored field the customer has a value
+// for. A value the field's scoring does not cover is left out.
+func (f *CustomFieldFactors) Factors(ctx context.Context, customerID, orgID uuid.UUID) ([]FactorResult, error) {
+	fields, err := f.fields.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list custom fields: %w", err)
+	}
+	scored := make(map[uuid.UUID]*repository.CustomField)
+	for _, field := range fields {
+		if field.Scoring != nil {
+			scored[field.ID] = field
+		}
+	}
+	if len(scored) == 0 {
+		return nil, nil
+	}
+
+	values, err := f.fields.ListValuesByCustomer(ctx, customerID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list custom field values: %w", err)
+	}
+
+	var results []FactorResult
+	for _, v := range values {
+		field := scored[v.FieldID]
+		if field == nil {
+			continue
+		}
+		if score, ok := scoreCustomField(field.Scoring, v); ok {
+			results = append(results, FactorResult{Name: "field." + field.Key, Score: &score})
+		}
+	}
+	return results, nil
+}
+
+// scoreCustomField scales a number value between the scoring's min and max,
+// or looks up an enum or boolean value's score.
+func scoreCustomField(sc *repository.CustomFieldScoring, v *repository.CustomFieldValue) (float64, bool) {
+	if sc.Min != nil && sc.Max != nil {
+		if v.Number == nil || *sc.Max <= *sc.Min {
+			return 0, false
+		}
+		score := math.Max(0, math.Min(1, (*v.Number-*sc.Min)/(*sc.Max-*sc.Min)))
+		if sc.Invert {
+			score = 1 - score
+		}
+		return score, true
+	}
+	score, ok := sc.OptionScores[v.Text]
+	return score, ok
+}
@@ -17,3 +17,9 @@ type ScoreFactor interface {
 	Name() string
 	Calculate(ctx context.Context, customerID, orgID uuid.UUID) (*FactorResult, error)
 }
+
+// FactorSource supplies factors an org defines for itself, such as scored
+// custom fields, whose names vary by org.
+type FactorSource interface {
+	Factors(ctx context.Context, customerID, orgID uuid.UUID) ([]FactorResult, error)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/onnwee/pulse-score/internal/repository"
+	"github.com/onnwee/pulse-score/internal/service"
 )
 
 // AlertCallback is called after a score is calculated, for real-time alert evaluation.
@@ -22,6 +23,9 @@ type ScoreScheduler struct {
 	connections     *repository.IntegrationConnectionRepository
 	changeDetector  *ChangeDetector
 	alertCallback   AlertCallback
+	accountRollup   *AccountRollup
+	ownerAssigner   *service.CustomerOwnerService
+	fieldSyncer     *service.CustomFieldService
 	interval        time.Duration
 	workers         int
 }
@@ -55,6 +59,26 @@ func (s *ScoreScheduler) SetAlertCallback(cb AlertCallback) {
 	s.alertCallback = cb
 }
 
+// SetAccountRollup registers the account rollup to run after org batches and
+// single-customer recalculations.
+func (s *ScoreScheduler) SetAccountRollup(r *AccountRollup) {
+	s.accountRollup = r
+}
+
+// SetOwnerAssigner registers the service whose assignment rules give unowned
+// customers an owner before each org batch, so owner-targeted alerts raised
+// by the batch reach them.
+func (s *ScoreScheduler) SetOwnerAssigner(a *service.CustomerOwnerService) {
+	s.ownerAssigner = a
+}
+
+// SetFieldSyncer registers the service that refills source-mapped custom
+// fields from synced metadata before each org batch, so custom field factors
+// score fresh values.
+func (s *ScoreScheduler) SetFieldSyncer(f *service.CustomFieldService) {
+	s.fieldSyncer = f
+}
+
 // Start begins the periodic score recalculation. Cancel the context to stop.
 func (s *ScoreScheduler) Start(ctx context.Context) {
 	slog.Info("score scheduler started", "interval", s.interval, "workers", s.workers)
@@ -90,9 +114,13 @@ func (s *ScoreScheduler) RunBatch(ctx context.Context) {
 			continue
 		}
 
+		s.syncFields(ctx, conn.OrgID)
+		s.assignOwners(ctx, conn.OrgID)
 		processed, errors := s.processCustomersBatch(ctx, customers, conn.OrgID)
 		totalCustomers += processed
 		totalErrors += errors
+
+		s.rollupAccounts(ctx, conn.OrgID)
 	}
 
 	slog.Info("score batch recalculation complete",
@@ -103,7 +131,16 @@ func (s *ScoreScheduler) RunBatch(ctx context.Context) {
 
 // RecalculateCustomer recalculates the score for a single customer (event-triggered).
 func (s *ScoreScheduler) RecalculateCustomer(ctx context.Context, customerID, orgID uuid.UUID) error {
-	return s.calculateAndStore(ctx, customerID, orgID)
+	if err := s.calculateAndStore(ctx, customerID, orgID); err != nil {
+		return err
+	}
+
+	if s.accountRollup != nil {
+		if err := s.accountRollup.RollupCustomer(ctx, customerID, orgID); err != nil {
+			slog.Error("account rollup error", "customer_id", customerID, "error", err)
+		}
+	}
+	return nil
 }
 
 // RecalculateOrg recalculates scores for all customers in an org.
@@ -113,10 +150,43 @@ func (s *ScoreScheduler) RecalculateOrg(ctx context.Context, orgID uuid.UUID) er
 		return err
 	}
 
+	s.syncFields(ctx, orgID)
+	s.assignOwners(ctx, orgID)
 	s.processCustomersBatch(ctx, customers, orgID)
+	s.rollupAccounts(ctx, orgID)
 	return nil
 }
 
+// syncFields refills an org's source-mapped custom fields.
+func (s *ScoreScheduler) syncFields(ctx context.Context, orgID uuid.UUID) {
+	if s.fieldSyncer == nil || ctx.Err() != nil {
+		return
+	}
+	if err := s.fieldSyncer.SyncOrg(ctx, orgID); err != nil {
+		slog.Error("custom field sync error", "org_id", orgID, "error", err)
+	}
+}
+
+// assignOwners runs an org's owner assignment rules over its unowned customers.
+func (s *ScoreScheduler) assignOwners(ctx context.Context, orgID uuid.UUID) {
+	if s.ownerAssigner == nil || ctx.Err() != nil {
+		return
+	}
+	if _, err := s.ownerAssigner.AssignUnowned(ctx, orgID); err != nil {
+		slog.Error("owner assignment error", "org_id", orgID, "error", err)
+	}
+}
+
+// rollupAccounts rolls an org's fresh customer scores up into account scores.
+func (s *ScoreScheduler) rollupAccounts(ctx context.Context, orgID uuid.UUID) {
+	if s.accountRollup == nil || ctx.Err() != nil {
+		return
+	}
+	if err := s.accountRollup.RollupOrg(ctx, orgID); err != nil {
+		slog.Error("account rollup error", "org_id", orgID, "error", err)
+	}
+}
+
 // processCustomersBatch processes a batch of customers with a worker pool.
 func (s *ScoreScheduler) processCustomersBatch(ctx context.Context, customers []*repository.Customer, orgID uuid.UUID) (int, int) {
 	if len(customers) == 0 {
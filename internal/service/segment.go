@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	maxSegmentNameLength      = 255
+	defaultSegmentHistoryDays = 30
+	maxSegmentHistoryDays     = 365
+)
+
+// SegmentService manages saved customer segments and records how many
+// customers each one holds over time.
+type SegmentService struct {
+	segments *repository.SegmentRepository
+	interval time.Duration
+}
+
+// NewSegmentService creates a new SegmentService that records segment counts
+// every countIntervalMin minutes once started.
+func NewSegmentService(segments *repository.SegmentRepository, countIntervalMin int) *SegmentService {
+	return &SegmentService{
+		segments: segments,
+		interval: time.Duration(countIntervalMin) * time.Minute,
+	}
+}
+
+// SegmentResponse is a segment with its current size.
+type SegmentResponse struct {
+	*repository.Segment
+	MemberCount int   `json:"member_count"`
+	MRRCents    int64 `json:"mrr_cents"`
+}
+
+// CreateSegmentRequest holds input for creating a segment.
+type CreateSegmentRequest struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Filter      repository.SegmentFilter `json:"filter"`
+}
+
+// UpdateSegmentRequest holds input for updating a segment.
+type UpdateSegmentRequest struct {
+	Name        *string                   `json:"name"`
+	Description *string                   `json:"description"`
+	Filter      *repository.SegmentFilter `json:"filter"`
+}
+
+// PreviewSegmentRequest holds a filter to size without saving it.
+type PreviewSegmentRequest struct {
+	Filter repository.SegmentFilter `json:"filter"`
+}
+
+// SegmentHistory is a segment's recorded daily sizes.
+type SegmentHistory struct {
+	SegmentID uuid.UUID                 `json:"segment_id"`
+	Counts    []repository.SegmentCount `json:"counts"`
+}
+
+// List returns an org's segments with their current sizes.
+func (s *SegmentService) List(ctx context.Context, orgID uuid.UUID) ([]*SegmentResponse, error) {
+	segments, err := s.segments.List(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*SegmentResponse, 0, len(segments))
+	for _, seg := range segments {
+		resp, err := s.withStats(ctx, seg)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, resp)
+	}
+	return result, nil
+}
+
+// Get returns a segment with its current size.
+func (s *SegmentService) Get(ctx context.Context, id, orgID uuid.UUID) (*SegmentResponse, error) {
+	seg, err := s.get(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withStats(ctx, seg)
+}
+
+// Create saves a segment.
+func (s *SegmentService) Create(ctx context.Context, orgID, userID uuid.UUID, req CreateSegmentRequest) (*SegmentResponse, error) {
+	seg := &repository.Segment{
+		OrgID:       orgID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Filter:      req.Filter,
+		CreatedBy:   &userID,
+	}
+
+	if err := s.validate(ctx, seg, nil); err != nil {
+		return nil, err
+	}
+	if err := s.segments.Create(ctx, seg); err != nil {
+		return nil, fmt.Errorf("create segment: %w", err)
+	}
+	return s.recordCount(ctx, seg)
+}
+
+// Update applies partial updates to a segment.
+func (s *SegmentService) Update(ctx context.Context, id, orgID uuid.UUID, req UpdateSegmentRequest) (*SegmentResponse, error) {
+	seg, err := s.get(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		seg.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		seg.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Filter != nil {
+		seg.Filter = *req.Filter
+	}
+
+	if err := s.validate(ctx, seg, &seg.ID); err != nil {
+		return nil, err
+	}
+	if err := s.segments.Update(ctx, seg); err != nil {
+		return nil, err
+	}
+	return s.recordCount(ctx, seg)
+}
+
+// Delete deletes a segment. Segments that alert rules are limited to cannot
+// be deleted, since the rules would otherwise widen to every customer.
+func (s *SegmentService) Delete(ctx context.Context, id, orgID uuid.UUID) error {
+	if _, err := s.get(ctx, id, orgID); err != nil {
+		return err
+	}
+
+	rules, err := s.segments.CountAlertRules(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rules > 0 {
+		return &ConflictError{Message: fmt.Sprintf("segment is used by %d alert rule(s)", rules)}
+	}
+	return s.segments.Delete(ctx, id, orgID)
+}
+
+// Preview returns how many customers a filter matches without saving it.
+func (s *SegmentService) Preview(ctx context.Context, orgID uuid.UUID, req PreviewSegmentRequest) (*repository.SegmentStats, error) {
+	if err := req.Filter.Validate(); err != nil {
+		return nil, &ValidationError{Field: "filter", Message: err.Error()}
+	}
+	return s.segments.Stats(ctx, orgID, req.Filter)
+}
+
+// History returns a segment's daily sizes over the last days days.
+func (s *SegmentService) History(ctx context.Context, id, orgID uuid.UUID, days int) (*SegmentHistory, error) {
+	if days < 1 {
+		days = defaultSegmentHistoryDays
+	}
+	if days > maxSegmentHistoryDays {
+		days = maxSegmentHistoryDays
+	}
+
+	if _, err := s.get(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.segments.ListCounts(ctx, id, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+	return &SegmentHistory{SegmentID: id, Counts: counts}, nil
+}
+
+// Start begins the periodic segment count loop. Cancel the context to stop.
+func (s *SegmentService) Start(ctx context.Context) {
+	slog.Info("segment counts started", "interval", s.interval)
+
+	s.RecordCounts(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("segment counts stopped")
+			return
+		case <-ticker.C:
+			s.RecordCounts(ctx)
+		}
+	}
+}
+
+// RecordCounts records today's size of every segment.
+func (s *SegmentService) RecordCounts(ctx context.Context) {
+	segments, err := s.segments.ListAll(ctx)
+	if err != nil {
+		slog.Error("segment counts: list segments", "error", err)
+		return
+	}
+
+	for _, seg := range segments {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.recordCount(ctx, seg); err != nil {
+			slog.Error("segment counts: record count", "segment_id", seg.ID, "error", err)
+		}
+	}
+}
+
+func (s *SegmentService) get(ctx context.Context, id, orgID uuid.UUID) (*repository.Segment, error) {
+	seg, err := s.segments.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if seg == nil {
+		return nil, &NotFoundError{Resource: "segment", Message: "segment not found"}
+	}
+	return seg, nil
+}
+
+func (s *SegmentService) withStats(ctx context.Context, seg *repository.Segment) (*SegmentResponse, error) {
+	stats, err := s.segments.Stats(ctx, seg.OrgID, seg.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return &SegmentResponse{Segment: seg, MemberCount: stats.MemberCount, MRRCents: stats.MRRCents}, nil
+}
+
+// recordCount stores a segment's current size as today's count.
+func (s *SegmentService) recordCount(ctx context.Context, seg *repository.Segment) (*SegmentResponse, error) {
+	resp, err := s.withStats(ctx, seg)
+	if err != nil {
+		return nil, err
+	}
+	stats := &repository.SegmentStats{MemberCount: resp.MemberCount, MRRCents: resp.MRRCents}
+	if err := s.segments.RecordCount(ctx, seg.ID, stats); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *SegmentService) validate(ctx context.Context, seg *repository.Segment, excludeID *uuid.UUID) error {
+	if seg.Name == "" {
+		return &ValidationError{Field: "name", Message: "name is required"}
+	}
+	if utf8.RuneCountInString(seg.Name) > maxSegmentNameLength {
+		return &ValidationError{Field: "name", Message: fmt.Sprintf("name must be at most %d characters", maxSegmentNameLength)}
+	}
+	if err := seg.Filter.Validate(); err != nil {
+		return &ValidationError{Field: "filter", Message: err.Error()}
+	}
+
+	exists, err := s.segments.NameExists(ctx, seg.OrgID, seg.Name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &ConflictError{Message: "a segment with this name already exists"}
+	}
+	return nil
+}
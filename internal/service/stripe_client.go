@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/stripe/stripe-go/v81"
+	stripecharge "github.com/stripe/stripe-go/v81/charge"
+	stripecustomer "github.com/stripe/stripe-go/v81/customer"
+	stripesub "github.com/stripe/stripe-go/v81/subscription"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+)
+
+// StripeClient provides rate-limited access to the Stripe API of connected accounts.
+type StripeClient struct {
+	backend stripe.Backend
+}
+
+// NewStripeClient creates a new StripeClient that sends requests through transport.
+func NewStripeClient(transport *ProviderTransport) *StripeClient {
+	return &StripeClient{
+		backend: stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+			HTTPClient: transport.Client(),
+			// Retries are handled by the transport
+			MaxNetworkRetries: stripe.Int64(0),
+		}),
+	}
+}
+
+// NewStripeTransport creates the provider transport for the Stripe API.
+func NewStripeTransport() *ProviderTransport {
+	return NewProviderTransport("stripe", ProviderTransportConfig{
+		RequestsPerSecond: 25, // Stripe's test-mode limit; live mode allows 100
+		Burst:             25,
+		MaxRetries:        4,
+	})
+}
+
+// customers creates a Stripe customer client with the given access token.
+func (c *StripeClient) customers(accessToken string) stripecustomer.Client {
+	return stripecustomer.Client{B: c.backend, Key: accessToken}
+}
+
+// CheckAccess lists one customer to verify accessToken works. A rejected
+// token is reported as integration.ErrReauthRequired.
+func (c *StripeClient) CheckAccess(ctx context.Context, accessToken string) error {
+	client := c.customers(accessToken)
+	params := &stripe.CustomerListParams{}
+	params.Context = ctx
+	params.Limit = stripe.Int64(1)
+	iter := client.List(params)
+
+	// No customers is fine; only an API error means the connection is unhealthy
+	iter.Next()
+	var stripeErr *stripe.Error
+	if errors.As(iter.Err(), &stripeErr) && stripeErr.HTTPStatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w: %v", integration.ErrReauthRequired, stripeErr)
+	}
+	return iter.Err()
+}
+
+// subscriptions creates a Stripe subscription client with the given access token.
+func (c *StripeClient) subscriptions(accessToken string) stripesub.Client {
+	return stripesub.Client{B: c.backend, Key: accessToken}
+}
+
+// charges creates a Stripe charge client with the given access token.
+func (c *StripeClient) charges(accessToken string) stripecharge.Client {
+	return stripecharge.Client{B: c.backend, Key: accessToken}
+}
@@ -2,10 +2,6 @@ package service
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/keyring"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
@@ -25,18 +22,20 @@ type StripeOAuthConfig struct {
 	ClientID       string
 	SecretKey      string
 	OAuthRedirectURL string
-	EncryptionKey  string // 32-byte hex-encoded AES key
+	EncryptionKey  string // 32-byte hex-encoded AES key; only reads legacy tokens once Keyring is set
+	Keyring        *keyring.Keyring
 }
 
 // StripeOAuthService handles Stripe OAuth connect flow.
 type StripeOAuthService struct {
 	cfg      StripeOAuthConfig
-	connRepo *repository.IntegrationConnectionRepository
+	connRepo oauthConnectionStore
+	tokens   *TokenCipher
 }
 
 // NewStripeOAuthService creates a new StripeOAuthService.
 func NewStripeOAuthService(cfg StripeOAuthConfig, connRepo *repository.IntegrationConnectionRepository) *StripeOAuthService {
-	return &StripeOAuthService{cfg: cfg, connRepo: connRepo}
+	return &StripeOAuthService{cfg: cfg, connRepo: connRepo, tokens: NewTokenCipher(cfg.Keyring, cfg.EncryptionKey)}
 }
 
 // ConnectURL generates the Stripe OAuth authorization URL.
@@ -82,14 +81,14 @@ func (s *StripeOAuthService) ExchangeCode(ctx context.Context, orgID uuid.UUID,
 	}
 
 	// Encrypt access token
-	encrypted, err := encryptToken(tokenResp.AccessToken, s.cfg.EncryptionKey)
+	encrypted, err := s.tokens.Encrypt(tokenResp.AccessToken)
 	if err != nil {
 		return fmt.Errorf("encrypt access token: %w", err)
 	}
 
 	var refreshEncrypted []byte
 	if tokenResp.RefreshToken != "" {
-		refreshEncrypted, err = encryptToken(tokenResp.RefreshToken, s.cfg.EncryptionKey)
+		refreshEncrypted, err = s.tokens.Encrypt(tokenResp.RefreshToken)
 		if err != nil {
 			return fmt.Errorf("encrypt refresh token: %w", err)
 		}
@@ -129,13 +128,17 @@ func (s *StripeOAuthService) GetStatus(ctx context.Context, orgID uuid.UUID) (*S
 		return &StripeConnectionStatus{Status: "disconnected"}, nil
 	}
 
-	return &StripeConnectionStatus{
+	status := &StripeConnectionStatus{
 		Status:            conn.Status,
 		ExternalAccountID: conn.ExternalAccountID,
 		LastSyncAt:        conn.LastSyncAt,
 		LastSyncError:     conn.LastSyncError,
 		ConnectedAt:       conn.CreatedAt,
-	}, nil
+	}
+	if conn.Status == "needs_reauth" {
+		status.ReconnectURL, _ = s.ConnectURL(orgID)
+	}
+	return status, nil
 }
 
 // Disconnect removes a Stripe connection.
@@ -156,7 +159,7 @@ func (s *StripeOAuthService) GetAccessToken(ctx context.Context, orgID uuid.UUID
 		return "", &ValidationError{Field: "stripe", Message: "Stripe connection is not active"}
 	}
 
-	token, err := decryptToken(conn.AccessTokenEncrypted, s.cfg.EncryptionKey)
+	token, err := s.tokens.Decrypt(conn.AccessTokenEncrypted)
 	if err != nil {
 		return "", fmt.Errorf("decrypt access token: %w", err)
 	}
@@ -170,6 +173,7 @@ type StripeConnectionStatus struct {
 	LastSyncAt        *time.Time `json:"last_sync_at,omitempty"`
 	LastSyncError     string     `json:"last_sync_error,omitempty"`
 	ConnectedAt       time.Time  `json:"connected_at,omitempty"`
+	ReconnectURL      string     `json:"reconnect_url,omitempty"`
 }
 
 // stripeTokenResponse holds the Stripe OAuth token exchange response.
@@ -219,69 +223,3 @@ func (s *StripeOAuthService) exchangeCodeWithStripe(code string) (*stripeTokenRe
 
 	return &tokenResp, nil
 }
-
-// encryptToken encrypts a token string using AES-GCM.
-func encryptToken(plaintext, keyHex string) ([]byte, error) {
-	if keyHex == "" {
-		// In dev mode, just store plaintext as bytes (not for production)
-		return []byte(plaintext), nil
-	}
-
-	key, err := hex.DecodeString(keyHex)
-	if err != nil {
-		return nil, fmt.Errorf("decode encryption key: %w", err)
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("create gcm: %w", err)
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		return nil, fmt.Errorf("generate nonce: %w", err)
-	}
-
-	return gcm.Seal(nonce, nonce, []byte(plaintext), nil), nil
-}
-
-// decryptToken decrypts a token using AES-GCM.
-func decryptToken(ciphertext []byte, keyHex string) (string, error) {
-	if keyHex == "" {
-		// In dev mode, just return plaintext
-		return string(ciphertext), nil
-	}
-
-	key, err := hex.DecodeString(keyHex)
-	if err != nil {
-		return "", fmt.Errorf("decode encryption key: %w", err)
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("create gcm: %w", err)
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(ciphertext) < nonceSize {
-		return "", fmt.Errorf("ciphertext too short")
-	}
-
-	nonce, encrypted := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, encrypted, nil)
-	if err != nil {
-		return "", fmt.Errorf("decrypt: %w", err)
-	}
-
-	return string(plaintext), nil
-}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v81"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// ReconcileSubscriptions compares the org's local subscriptions with Stripe
+// and adds what differs to report. With heal set, missing and stale
+// subscriptions are upserted and orphaned ones are marked canceled.
+func (s *StripeSyncService) ReconcileSubscriptions(ctx context.Context, orgID uuid.UUID, heal bool, report *integration.DriftReport) error {
+	accessToken, err := s.oauthSvc.GetAccessToken(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("get access token: %w", err)
+	}
+
+	localSubs, err := s.subs.ListByOrg(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("list local subscriptions: %w", err)
+	}
+	local := make(map[string]*repository.StripeSubscription, len(localSubs))
+	for _, sub := range localSubs {
+		local[sub.StripeSubscriptionID] = sub
+	}
+
+	// Canceled subscriptions are included so a local row that missed its
+	// cancellation shows up as stale rather than orphaned.
+	params := &stripe.SubscriptionListParams{Status: stripe.String("all")}
+	params.Limit = stripe.Int64(100)
+	params.AddExpand("data.customer")
+
+	iter := s.client.subscriptions(accessToken).List(params)
+	seen := make(map[string]bool, len(localSubs))
+	for iter.Next() {
+		sub := iter.Subscription()
+		seen[sub.ID] = true
+		report.Checked++
+		if sub.Customer == nil {
+			continue
+		}
+
+		existing := local[sub.ID]
+		var item integration.DriftItem
+		if existing == nil {
+			// Subscriptions that ended before PulseScore connected were never synced
+			if sub.Status == stripe.SubscriptionStatusCanceled {
+				continue
+			}
+			item = integration.DriftItem{Resource: "subscription", ExternalID: sub.ID, Kind: integration.DriftMissing}
+		} else {
+			diff := subscriptionDiff(existing, buildStripeSubscription(orgID, existing.CustomerID, sub))
+			if diff == "" {
+				continue
+			}
+			item = integration.DriftItem{Resource: "subscription", ExternalID: sub.ID, Kind: integration.DriftStale, Detail: diff}
+		}
+
+		if heal {
+			if err := s.healSubscription(ctx, orgID, sub); err != nil {
+				slog.Error("reconcile: failed to heal subscription", "stripe_sub_id", sub.ID, "error", err)
+			} else {
+				item.Healed = true
+			}
+		}
+		report.Add(item)
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("iterate subscriptions: %w", err)
+	}
+
+	for _, sub := range localSubs {
+		if seen[sub.StripeSubscriptionID] || sub.Status == "canceled" {
+			continue
+		}
+		item := integration.DriftItem{
+			Resource:   "subscription",
+			ExternalID: sub.StripeSubscriptionID,
+			Kind:       integration.DriftOrphaned,
+			Detail:     "not found in Stripe; local status " + sub.Status,
+		}
+		if heal {
+			now := time.Now()
+			sub.Status = "canceled"
+			sub.CanceledAt = &now
+			if err := s.subs.Upsert(ctx, sub); err != nil {
+				slog.Error("reconcile: failed to cancel orphaned subscription", "stripe_sub_id", sub.StripeSubscriptionID, "error", err)
+			} else {
+				item.Healed = true
+			}
+		}
+		report.Add(item)
+	}
+
+	return nil
+}
+
+// healSubscription upserts a Stripe subscription, syncing its customer first
+// when it is not known locally.
+func (s *StripeSyncService) healSubscription(ctx context.Context, orgID uuid.UUID, sub *stripe.Subscription) error {
+	localCustomer, err := s.customers.GetByExternalID(ctx, orgID, "stripe", sub.Customer.ID)
+	if err != nil {
+		return fmt.Errorf("get customer: %w", err)
+	}
+	if localCustomer == nil {
+		if err := s.upsertCustomer(ctx, orgID, sub.Customer); err != nil {
+			return fmt.Errorf("upsert customer: %w", err)
+		}
+		localCustomer, err = s.customers.GetByExternalID(ctx, orgID, "stripe", sub.Customer.ID)
+		if err != nil {
+			return fmt.Errorf("get customer: %w", err)
+		}
+		if localCustomer == nil {
+			return fmt.Errorf("customer %s not found after upsert", sub.Customer.ID)
+		}
+	}
+
+	return s.subs.Upsert(ctx, buildStripeSubscription(orgID, localCustomer.ID, sub))
+}
+
+// subscriptionDiff describes the fields that differ between a stored
+// subscription and the one Stripe returned, or "" when they match.
+func subscriptionDiff(local, remote *repository.StripeSubscription) string {
+	var diffs []string
+	if local.Status != remote.Status {
+		diffs = append(diffs, fmt.Sprintf("status %s → %s", local.Status, remote.Status))
+	}
+	if local.AmountCents != remote.AmountCents {
+		diffs = append(diffs, fmt.Sprintf("amount %d → %d", local.AmountCents, remote.AmountCents))
+	}
+	if local.Interval != remote.Interval {
+		diffs = append(diffs, fmt.Sprintf("interval %s → %s", local.Interval, remote.Interval))
+	}
+	if local.PlanName != remote.PlanName {
+		diffs = append(diffs, fmt.Sprintf("plan %q → %q", local.PlanName, remote.PlanName))
+	}
+	return strings.Join(diffs, ", ")
+}
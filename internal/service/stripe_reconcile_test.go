@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v81"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+type fakeStripeSubscriptionStore struct {
+	subs     []*repository.StripeSubscription
+	upserted []*repository.StripeSubscription
+}
+
+func (f *fakeStripeSubscriptionStore) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*repository.StripeSubscription, error) {
+	return f.subs, nil
+}
+
+func (f *fakeStripeSubscriptionStore) Upsert(ctx context.Context, sub *repository.StripeSubscription) error {
+	f.upserted = append(f.upserted, sub)
+	return nil
+}
+
+// newTestStripeSync returns a StripeSyncService with an active connection
+// whose Stripe API lists subscriptionsJSON as its only page of subscriptions.
+func newTestStripeSync(t *testing.T, subs *fakeStripeSubscriptionStore, subscriptionsJSON string) *StripeSyncService {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/subscriptions" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","url":"/v1/subscriptions","has_more":false,"data":[` + subscriptionsJSON + `]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	tokens := NewTokenCipher(nil, strings.Repeat("ab", 32))
+	access, err := tokens.Encrypt("sk_test")
+	if err != nil {
+		t.Fatalf("encrypt access token: %v", err)
+	}
+	oauth := &StripeOAuthService{
+		connRepo: &fakeOAuthConnectionStore{conn: &repository.IntegrationConnection{
+			Provider:             "stripe",
+			Status:               "active",
+			AccessTokenEncrypted: access,
+		}},
+		tokens: tokens,
+	}
+	client := &StripeClient{backend: stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		URL:               stripe.String(srv.URL),
+		HTTPClient:        srv.Client(),
+		MaxNetworkRetries: stripe.Int64(0),
+	})}
+
+	return &StripeSyncService{subs: subs, oauthSvc: oauth, client: client}
+}
+
+func TestSubscriptionDiff(t *testing.T) {
+	local := &repository.StripeSubscription{Status: "active", AmountCents: 5000, Interval: "month", PlanName: "Pro", Currency: "usd"}
+
+	tests := []struct {
+		name   string
+		remote repository.StripeSubscription
+		want   string
+	}{
+		{"identical", *local, ""},
+		{"ignores currency", repository.StripeSubscription{Status: "active", AmountCents: 5000, Interval: "month", PlanName: "Pro", Currency: "eur"}, ""},
+		{"status", repository.StripeSubscription{Status: "past_due", AmountCents: 5000, Interval: "month", PlanName: "Pro"}, "status active → past_due"},
+		{"amount", repository.StripeSubscription{Status: "active", AmountCents: 9900, Interval: "month", PlanName: "Pro"}, "amount 5000 → 9900"},
+		{"interval", repository.StripeSubscription{Status: "active", AmountCents: 5000, Interval: "year", PlanName: "Pro"}, "interval month → year"},
+		{"plan", repository.StripeSubscription{Status: "active", AmountCents: 5000, Interval: "month", PlanName: "Team"}, `plan "Pro" → "Team"`},
+		{
+			"several fields in order",
+			repository.StripeSubscription{Status: "canceled", AmountCents: 0, Interval: "month", PlanName: "Team"},
+			`status active → canceled, amount 5000 → 0, plan "Pro" → "Team"`,
+		},
+	}
+	for _, tt := range tests {
+		if got := subscriptionDiff(local, &tt.remote); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+const stripeTestSubscriptions = `
+{"id":"sub_stale","object":"subscription","status":"past_due","customer":{"id":"cus_1","object":"customer"},
+ "items":{"object":"list","data":[{"id":"si_1","object":"subscription_item","quantity":1,
+  "price":{"id":"price_1","object":"price","unit_amount":5000,"currency":"usd","recurring":{"interval":"month"},"product":{"id":"prod_1","object":"product","name":"Pro"}}}]}},
+{"id":"sub_same","object":"subscription","status":"active","customer":{"id":"cus_1","object":"customer"},
+ "items":{"object":"list","data":[{"id":"si_2","object":"subscription_item","quantity":2,
+  "price":{"id":"price_1","object":"price","unit_amount":5000,"currency":"usd","recurring":{"interval":"month"},"product":{"id":"prod_1","object":"product","name":"Pro"}}}]}},
+{"id":"sub_missing","object":"subscription","status":"active","customer":{"id":"cus_2","object":"customer"}},
+{"id":"sub_ended","object":"subscription","status":"canceled","customer":{"id":"cus_2","object":"customer"}},
+{"id":"sub_no_customer","object":"subscription","status":"active"}`
+
+func TestReconcileSubscriptions_Report(t *testing.T) {
+	customerID := uuid.New()
+	subs := &fakeStripeSubscriptionStore{subs: []*repository.StripeSubscription{
+		{StripeSubscriptionID: "sub_stale", CustomerID: customerID, Status: "active", AmountCents: 5000, Interval: "month", PlanName: "Pro", Currency: "usd"},
+		{StripeSubscriptionID: "sub_same", CustomerID: customerID, Status: "active", AmountCents: 10000, Interval: "month", PlanName: "Pro", Currency: "usd"},
+		{StripeSubscriptionID: "sub_orphan", CustomerID: customerID, Status: "active"},
+		{StripeSubscriptionID: "sub_gone", CustomerID: customerID, Status: "canceled"},
+	}}
+	s := newTestStripeSync(t, subs, stripeTestSubscriptions)
+
+	report := &integration.DriftReport{}
+	if err := s.ReconcileSubscriptions(context.Background(), uuid.New(), false, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Checked != 5 || report.Missing != 1 || report.Stale != 1 || report.Orphaned != 1 || report.Healed != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	kinds := map[string]integration.DriftItem{}
+	for _, item := range report.Items {
+		kinds[item.ExternalID] = item
+	}
+	if item := kinds["sub_stale"]; item.Kind != integration.DriftStale || item.Detail != "status active → past_due" {
+		t.Errorf("expected sub_stale to be stale on status, got %+v", item)
+	}
+	if item := kinds["sub_missing"]; item.Kind != integration.DriftMissing {
+		t.Errorf("expected sub_missing to be missing, got %+v", item)
+	}
+	if item := kinds["sub_orphan"]; item.Kind != integration.DriftOrphaned || item.Detail != "not found in Stripe; local status active" {
+		t.Errorf("expected sub_orphan to be orphaned, got %+v", item)
+	}
+	if len(subs.upserted) != 0 {
+		t.Errorf("expected a report without heal to change nothing, got %d upserts", len(subs.upserted))
+	}
+}
+
+func TestReconcileSubscriptions_HealCancelsOrphans(t *testing.T) {
+	orphan := &repository.StripeSubscription{StripeSubscriptionID: "sub_orphan", Status: "past_due"}
+	subs := &fakeStripeSubscriptionStore{subs: []*repository.StripeSubscription{
+		orphan,
+		{StripeSubscriptionID: "sub_gone", Status: "canceled"},
+	}}
+	s := newTestStripeSync(t, subs, "")
+
+	report := &integration.DriftReport{}
+	if err := s.ReconcileSubscriptions(context.Background(), uuid.New(), true, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Orphaned != 1 || report.Healed != 1 || len(report.Items) != 1 || !report.Items[0].Healed {
+		t.Fatalf("expected the orphan to be reported healed, got %+v", report)
+	}
+	if len(subs.upserted) != 1 || subs.upserted[0].StripeSubscriptionID != "sub_orphan" {
+		t.Fatalf("expected only the orphan to be upserted, got %+v", subs.upserted)
+	}
+	if orphan.Status != "canceled" || orphan.CanceledAt == nil {
+		t.Errorf("expected the orphan to be marked canceled, got status %q canceled_at %v", orphan.Status, orphan.CanceledAt)
+	}
+}
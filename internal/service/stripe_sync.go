@@ -8,20 +8,25 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v81"
-	stripecharge "github.com/stripe/stripe-go/v81/charge"
-	stripecustomer "github.com/stripe/stripe-go/v81/customer"
-	stripesub "github.com/stripe/stripe-go/v81/subscription"
 
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
+// stripeSubscriptionStore is the part of StripeSubscriptionRepository the
+// Stripe sync uses.
+type stripeSubscriptionStore interface {
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*repository.StripeSubscription, error)
+	Upsert(ctx context.Context, sub *repository.StripeSubscription) error
+}
+
 // StripeSyncService handles syncing data from Stripe to local database.
 type StripeSyncService struct {
 	customers   *repository.CustomerRepository
-	subs        *repository.StripeSubscriptionRepository
+	subs        stripeSubscriptionStore
 	payments    *repository.StripePaymentRepository
 	events      *repository.CustomerEventRepository
 	oauthSvc    *StripeOAuthService
+	client      *StripeClient
 	paymentDays int
 }
 
@@ -32,6 +37,7 @@ func NewStripeSyncService(
 	payments *repository.StripePaymentRepository,
 	events *repository.CustomerEventRepository,
 	oauthSvc *StripeOAuthService,
+	client *StripeClient,
 	paymentDays int,
 ) *StripeSyncService {
 	return &StripeSyncService{
@@ -40,6 +46,7 @@ func NewStripeSyncService(
 		payments:    payments,
 		events:      events,
 		oauthSvc:    oauthSvc,
+		client:      client,
 		paymentDays: paymentDays,
 	}
 }
@@ -50,6 +57,8 @@ type SyncProgress struct {
 	Total   int    `json:"total"`
 	Current int    `json:"current"`
 	Errors  int    `json:"errors"`
+	// Expected is the record count reported by the provider, when it reports one.
+	Expected int `json:"expected,omitempty"`
 }
 
 type stripePaymentSyncOptions struct {
@@ -90,12 +99,13 @@ func (s *StripeSyncService) syncCustomers(
 
 	progress := &SyncProgress{Step: step}
 
-	client := newStripeCustomerClient(accessToken)
+	client := s.client.customers(accessToken)
 	iter := client.List(params)
 
 	for iter.Next() {
 		c := iter.Customer()
 		progress.Total++
+		reportSyncProgress(ctx, progress)
 
 		if err := s.upsertCustomer(ctx, orgID, c); err != nil {
 			slog.Error("failed to upsert customer", "stripe_id", c.ID, "error", err)
@@ -153,12 +163,13 @@ func (s *StripeSyncService) SyncSubscriptions(ctx context.Context, orgID uuid.UU
 	params := &stripe.SubscriptionListParams{}
 	params.Limit = stripe.Int64(100)
 
-	client := newStripeSubClient(accessToken)
+	client := s.client.subscriptions(accessToken)
 	iter := client.List(params)
 
 	for iter.Next() {
 		sub := iter.Subscription()
 		progress.Total++
+		reportSyncProgress(ctx, progress)
 
 		// Find local customer
 		localCustomer, err := s.customers.GetByExternalID(ctx, orgID, "stripe", sub.Customer.ID)
@@ -180,40 +191,7 @@ func (s *StripeSyncService) SyncSubscriptions(ctx context.Context, orgID uuid.UU
 			continue
 		}
 
-		// Resolve plan name and amount from subscription items
-		planName, amountCents, interval, currency := extractSubscriptionDetails(sub)
-
-		var periodStart, periodEnd *time.Time
-		if sub.CurrentPeriodStart > 0 {
-			t := time.Unix(sub.CurrentPeriodStart, 0)
-			periodStart = &t
-		}
-		if sub.CurrentPeriodEnd > 0 {
-			t := time.Unix(sub.CurrentPeriodEnd, 0)
-			periodEnd = &t
-		}
-
-		var canceledAt *time.Time
-		if sub.CanceledAt > 0 {
-			t := time.Unix(sub.CanceledAt, 0)
-			canceledAt = &t
-		}
-
-		localSub := &repository.StripeSubscription{
-			OrgID:                orgID,
-			CustomerID:           localCustomer.ID,
-			StripeSubscriptionID: sub.ID,
-			Status:               string(sub.Status),
-			PlanName:             planName,
-			AmountCents:          amountCents,
-			Currency:             currency,
-			Interval:             interval,
-			CurrentPeriodStart:   periodStart,
-			CurrentPeriodEnd:     periodEnd,
-			CanceledAt:           canceledAt,
-			Metadata:             stripeMetadataToMap(sub.Metadata),
-		}
-
+		localSub := buildStripeSubscription(orgID, localCustomer.ID, sub)
 		if err := s.subs.Upsert(ctx, localSub); err != nil {
 			slog.Error("failed to upsert subscription", "stripe_sub_id", sub.ID, "error", err)
 			progress.Errors++
@@ -275,12 +253,13 @@ func (s *StripeSyncService) syncPayments(
 
 	progress := &SyncProgress{Step: step}
 
-	client := newStripeChargeClient(accessToken)
+	client := s.client.charges(accessToken)
 	iter := client.List(params)
 
 	for iter.Next() {
 		ch := iter.Charge()
 		progress.Total++
+		reportSyncProgress(ctx, progress)
 
 		synced, err := s.processPaymentCharge(ctx, orgID, ch, options)
 		if err != nil {
@@ -400,6 +379,43 @@ func (s *StripeSyncService) emitFailedPaymentEvent(ctx context.Context, orgID, c
 	}
 }
 
+// buildStripeSubscription maps a Stripe subscription to its local row.
+func buildStripeSubscription(orgID, customerID uuid.UUID, sub *stripe.Subscription) *repository.StripeSubscription {
+	// Resolve plan name and amount from subscription items
+	planName, amountCents, interval, currency := extractSubscriptionDetails(sub)
+
+	var periodStart, periodEnd *time.Time
+	if sub.CurrentPeriodStart > 0 {
+		t := time.Unix(sub.CurrentPeriodStart, 0)
+		periodStart = &t
+	}
+	if sub.CurrentPeriodEnd > 0 {
+		t := time.Unix(sub.CurrentPeriodEnd, 0)
+		periodEnd = &t
+	}
+
+	var canceledAt *time.Time
+	if sub.CanceledAt > 0 {
+		t := time.Unix(sub.CanceledAt, 0)
+		canceledAt = &t
+	}
+
+	return &repository.StripeSubscription{
+		OrgID:                orgID,
+		CustomerID:           customerID,
+		StripeSubscriptionID: sub.ID,
+		Status:               string(sub.Status),
+		PlanName:             planName,
+		AmountCents:          amountCents,
+		Currency:             currency,
+		Interval:             interval,
+		CurrentPeriodStart:   periodStart,
+		CurrentPeriodEnd:     periodEnd,
+		CanceledAt:           canceledAt,
+		Metadata:             stripeMetadataToMap(sub.Metadata),
+	}
+}
+
 // extractSubscriptionDetails extracts plan name, amount, interval, and currency from a subscription.
 func extractSubscriptionDetails(sub *stripe.Subscription) (planName string, amountCents int, interval, currency string) {
 	if sub.Items == nil || len(sub.Items.Data) == 0 {
@@ -431,18 +447,3 @@ func stripeMetadataToMap(metadata map[string]string) map[string]any {
 	}
 	return result
 }
-
-// newStripeCustomerClient creates a Stripe customer client with the given access token.
-func newStripeCustomerClient(accessToken string) stripecustomer.Client {
-	return stripecustomer.Client{B: stripe.GetBackend(stripe.APIBackend), Key: accessToken}
-}
-
-// newStripeSubClient creates a Stripe subscription client with the given access token.
-func newStripeSubClient(accessToken string) stripesub.Client {
-	return stripesub.Client{B: stripe.GetBackend(stripe.APIBackend), Key: accessToken}
-}
-
-// newStripeChargeClient creates a Stripe charge client with the given access token.
-func newStripeChargeClient(accessToken string) stripecharge.Client {
-	return stripecharge.Client{B: stripe.GetBackend(stripe.APIBackend), Key: accessToken}
-}
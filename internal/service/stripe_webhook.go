@@ -15,59 +15,174 @@ import (
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
+// stripeAccountCacheTTL bounds how long a Stripe account to org mapping is
+// trusted before it is looked up again.
+const stripeAccountCacheTTL = 5 * time.Minute
+
+// stripeAccountConnections is the part of IntegrationConnectionRepository the
+// Stripe webhook uses to route events to orgs.
+type stripeAccountConnections interface {
+	GetByOrgAndProvider(ctx context.Context, orgID uuid.UUID, provider string) (*repository.IntegrationConnection, error)
+	GetByProviderAndExternalID(ctx context.Context, provider, externalAccountID string) (*repository.IntegrationConnection, error)
+}
+
+// webhookQuarantineStore is the part of WebhookQuarantineRepository the
+// Stripe webhook uses.
+type webhookQuarantineStore interface {
+	Insert(ctx context.Context, q *repository.QuarantinedWebhook) error
+	ListUnreleased(ctx context.Context, provider, accountID string) ([]*repository.QuarantinedWebhook, error)
+	MarkReleased(ctx context.Context, id uuid.UUID) error
+}
+
+// webhookEnqueuer queues a verified webhook for asynchronous processing.
+type webhookEnqueuer interface {
+	Enqueue(ctx context.Context, provider, eventID, eventType string, orgID *uuid.UUID, payload []byte) error
+}
+
+// customerEventUpserter is the part of CustomerEventRepository the Stripe
+// webhook uses.
+type customerEventUpserter interface {
+	Upsert(ctx context.Context, event *repository.CustomerEvent) error
+}
+
 // StripeWebhookService handles incoming Stripe webhook events.
 type StripeWebhookService struct {
 	webhookSecret string
-	connRepo      *repository.IntegrationConnectionRepository
+	connRepo      stripeAccountConnections
+	quarantine    webhookQuarantineStore
 	customers     *repository.CustomerRepository
 	subs          *repository.StripeSubscriptionRepository
 	payments      *repository.StripePaymentRepository
-	events        *repository.CustomerEventRepository
+	events        customerEventUpserter
 	mrrSvc        *MRRService
 	paymentHealth *PaymentHealthService
+	inbox         webhookEnqueuer
 
-	// processedEvents tracks recently processed event IDs for idempotency
-	processedEvents map[string]time.Time
-	mu              sync.Mutex
+	// Stripe account ID -> org, so routing an event costs no query when hot
+	accountsMu sync.RWMutex
+	accounts   map[string]stripeAccountEntry
+}
+
+type stripeAccountEntry struct {
+	orgID   uuid.UUID
+	expires time.Time
 }
 
 // NewStripeWebhookService creates a new StripeWebhookService.
 func NewStripeWebhookService(
 	webhookSecret string,
 	connRepo *repository.IntegrationConnectionRepository,
+	quarantine *repository.WebhookQuarantineRepository,
 	customers *repository.CustomerRepository,
 	subs *repository.StripeSubscriptionRepository,
 	payments *repository.StripePaymentRepository,
 	events *repository.CustomerEventRepository,
 	mrrSvc *MRRService,
 	paymentHealth *PaymentHealthService,
+	inbox *WebhookInboxService,
 ) *StripeWebhookService {
 	return &StripeWebhookService{
-		webhookSecret:   webhookSecret,
-		connRepo:        connRepo,
-		customers:       customers,
-		subs:            subs,
-		payments:        payments,
-		events:          events,
-		mrrSvc:          mrrSvc,
-		paymentHealth:   paymentHealth,
-		processedEvents: make(map[string]time.Time),
+		webhookSecret: webhookSecret,
+		connRepo:      connRepo,
+		quarantine:    quarantine,
+		customers:     customers,
+		subs:          subs,
+		payments:      payments,
+		events:        events,
+		mrrSvc:        mrrSvc,
+		paymentHealth: paymentHealth,
+		inbox:         inbox,
+		accounts:      make(map[string]stripeAccountEntry),
 	}
 }
 
-// HandleEvent verifies and processes a Stripe webhook event.
+// HandleEvent verifies a Stripe webhook event and stores it in the webhook
+// inbox for the org that connected the sending account. The event is
+// processed asynchronously by ProcessPayload.
+//
+// Platform events (no account) concern PulseScore's own Stripe account, not a
+// customer's, and are acknowledged without processing; subscription billing
+// receives them on its own endpoint. Connected-account events whose account
+// matches no org are quarantined rather than dropped.
 func (s *StripeWebhookService) HandleEvent(ctx context.Context, payload []byte, sigHeader string) error {
 	event, err := webhook.ConstructEvent(payload, sigHeader, s.webhookSecret)
 	if err != nil {
 		return &ValidationError{Field: "signature", Message: "invalid webhook signature"}
 	}
 
-	// Idempotency check
-	if s.isProcessed(event.ID) {
-		slog.Debug("duplicate webhook event skipped", "event_id", event.ID)
+	if event.Account == "" {
+		slog.Info("stripe webhook: ignoring platform event", "event_id", event.ID, "type", event.Type)
 		return nil
 	}
 
+	orgID, found, err := s.lookupAccount(ctx, event.Account)
+	if err != nil {
+		return err
+	}
+	if !found {
+		slog.Warn("stripe webhook: quarantining event for unknown account", "event_id", event.ID, "account", event.Account)
+		return s.quarantine.Insert(ctx, &repository.QuarantinedWebhook{
+			Provider:  "stripe",
+			EventID:   event.ID,
+			EventType: string(event.Type),
+			AccountID: event.Account,
+			Payload:   payload,
+			Reason:    "no org connected to account",
+		})
+	}
+
+	return s.inbox.Enqueue(ctx, "stripe", event.ID, string(event.Type), &orgID, payload)
+}
+
+// ReleaseQuarantined queues the quarantined events of the Stripe account an
+// org has just connected, such as ones delivered before the OAuth callback
+// stored the connection.
+func (s *StripeWebhookService) ReleaseQuarantined(ctx context.Context, orgID uuid.UUID) error {
+	conn, err := s.connRepo.GetByOrgAndProvider(ctx, orgID, "stripe")
+	if err != nil {
+		return fmt.Errorf("get connection: %w", err)
+	}
+	if conn == nil || conn.ExternalAccountID == "" {
+		return nil
+	}
+	s.rememberAccount(conn.ExternalAccountID, orgID)
+
+	events, err := s.quarantine.ListUnreleased(ctx, "stripe", conn.ExternalAccountID)
+	if err != nil {
+		return err
+	}
+	for _, q := range events {
+		if err := s.inbox.Enqueue(ctx, "stripe", q.EventID, q.EventType, &orgID, q.Payload); err != nil {
+			return err
+		}
+		if err := s.quarantine.MarkReleased(ctx, q.ID); err != nil {
+			return err
+		}
+	}
+	if len(events) > 0 {
+		slog.Info("stripe webhook: released quarantined events", "org_id", orgID, "account", conn.ExternalAccountID, "count", len(events))
+	}
+	return nil
+}
+
+// ForgetOrg drops cached account mappings for an org, e.g. after it disconnects.
+func (s *StripeWebhookService) ForgetOrg(orgID uuid.UUID) {
+	s.accountsMu.Lock()
+	defer s.accountsMu.Unlock()
+	for account, entry := range s.accounts {
+		if entry.orgID == orgID {
+			delete(s.accounts, account)
+		}
+	}
+}
+
+// ProcessPayload processes a stored Stripe webhook event.
+func (s *StripeWebhookService) ProcessPayload(ctx context.Context, payload []byte) error {
+	var event stripe.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("decode stripe event: %w", err)
+	}
+
 	slog.Info("processing webhook event",
 		"event_id", event.ID,
 		"type", event.Type,
@@ -78,46 +193,30 @@ func (s *StripeWebhookService) HandleEvent(ctx context.Context, payload []byte,
 		return s.handleCustomerEvent(ctx, event)
 	case "customer.deleted":
 		return s.handleCustomerDeleted(ctx, event)
-	case "customer.subscription.created", "customer.subscription.updated":
+	case "customer.subscription.created", "customer.subscription.updated",
+		"customer.subscription.paused", "customer.subscription.resumed":
 		return s.handleSubscriptionEvent(ctx, event)
 	case "customer.subscription.deleted":
 		return s.handleSubscriptionDeleted(ctx, event)
+	case "customer.subscription.trial_will_end":
+		return s.handleTrialWillEnd(ctx, event)
 	case "invoice.paid":
 		return s.handleInvoicePaid(ctx, event)
 	case "invoice.payment_failed":
 		return s.handleInvoicePaymentFailed(ctx, event)
+	case "invoice.upcoming":
+		return s.handleInvoiceUpcoming(ctx, event)
+	case "charge.refunded":
+		return s.handleChargeRefunded(ctx, event)
+	case "charge.dispute.created", "charge.dispute.closed":
+		return s.handleDisputeEvent(ctx, event)
 	default:
 		slog.Debug("unhandled webhook event type", "type", event.Type)
 	}
 
-	s.markProcessed(event.ID)
 	return nil
 }
 
-func (s *StripeWebhookService) isProcessed(eventID string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, ok := s.processedEvents[eventID]; ok {
-		return true
-	}
-
-	// Clean up old entries (older than 24h)
-	cutoff := time.Now().Add(-24 * time.Hour)
-	for id, t := range s.processedEvents {
-		if t.Before(cutoff) {
-			delete(s.processedEvents, id)
-		}
-	}
-	return false
-}
-
-func (s *StripeWebhookService) markProcessed(eventID string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.processedEvents[eventID] = time.Now()
-}
-
 func (s *StripeWebhookService) handleCustomerEvent(ctx context.Context, event stripe.Event) error {
 	var cust stripe.Customer
 	if err := json.Unmarshal(event.Data.Raw, &cust); err != nil {
@@ -148,7 +247,6 @@ func (s *StripeWebhookService) handleCustomerEvent(ctx context.Context, event st
 		return fmt.Errorf("upsert customer: %w", err)
 	}
 
-	s.markProcessed(event.ID)
 	return nil
 }
 
@@ -167,7 +265,6 @@ func (s *StripeWebhookService) handleCustomerDeleted(ctx context.Context, event
 		return fmt.Errorf("soft delete customer: %w", err)
 	}
 
-	s.markProcessed(event.ID)
 	return nil
 }
 
@@ -230,16 +327,22 @@ func (s *StripeWebhookService) handleSubscriptionEvent(ctx context.Context, even
 		Metadata:             stripeMetadataToMap(sub.Metadata),
 	}
 
+	prevSub, err := s.subs.GetByStripeID(ctx, sub.ID)
+	if err != nil {
+		return fmt.Errorf("get subscription: %w", err)
+	}
+
 	if err := s.subs.Upsert(ctx, localSub); err != nil {
 		return fmt.Errorf("upsert subscription: %w", err)
 	}
 
+	s.recordSubscriptionChanges(ctx, event, prevSub, localSub)
+
 	// Recalculate MRR for the customer
 	if err := s.mrrSvc.CalculateForCustomer(ctx, localCustomer.ID); err != nil {
 		slog.Error("failed to recalculate MRR after subscription webhook", "error", err)
 	}
 
-	s.markProcessed(event.ID)
 	return nil
 }
 
@@ -276,7 +379,6 @@ func (s *StripeWebhookService) handleSubscriptionDeleted(ctx context.Context, ev
 		}
 	}
 
-	s.markProcessed(event.ID)
 	return nil
 }
 
@@ -315,7 +417,6 @@ func (s *StripeWebhookService) handleInvoicePaid(ctx context.Context, event stri
 		return fmt.Errorf("upsert payment: %w", err)
 	}
 
-	s.markProcessed(event.ID)
 	return nil
 }
 
@@ -377,28 +478,49 @@ func (s *StripeWebhookService) handleInvoicePaymentFailed(ctx context.Context, e
 		slog.Error("failed to track failed payment", "error", err)
 	}
 
-	s.markProcessed(event.ID)
 	return nil
 }
 
 // findOrgForStripeAccount finds the org that has the given Stripe account connected.
 func (s *StripeWebhookService) findOrgForStripeAccount(ctx context.Context, stripeAccountID string) (uuid.UUID, error) {
-	conns, err := s.connRepo.ListActiveByProvider(ctx, "stripe")
+	if stripeAccountID == "" {
+		return uuid.Nil, fmt.Errorf("stripe event has no connected account")
+	}
+
+	orgID, found, err := s.lookupAccount(ctx, stripeAccountID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("list connections: %w", err)
+		return uuid.Nil, err
 	}
+	if !found {
+		return uuid.Nil, fmt.Errorf("no org found for Stripe account %s", stripeAccountID)
+	}
+	return orgID, nil
+}
 
-	for _, conn := range conns {
-		if conn.ExternalAccountID == stripeAccountID {
-			return conn.OrgID, nil
-		}
+// lookupAccount resolves a Stripe account to its org through the cache, then
+// the (provider, external_account_id) index.
+func (s *StripeWebhookService) lookupAccount(ctx context.Context, stripeAccountID string) (uuid.UUID, bool, error) {
+	s.accountsMu.RLock()
+	entry, ok := s.accounts[stripeAccountID]
+	s.accountsMu.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.orgID, true, nil
 	}
 
-	// If no account ID match (e.g. direct account, not Connect), 
-	// try to find the single active connection
-	if stripeAccountID == "" && len(conns) == 1 {
-		return conns[0].OrgID, nil
+	conn, err := s.connRepo.GetByProviderAndExternalID(ctx, "stripe", stripeAccountID)
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("lookup stripe account: %w", err)
 	}
+	if conn == nil {
+		return uuid.Nil, false, nil
+	}
+
+	s.rememberAccount(stripeAccountID, conn.OrgID)
+	return conn.OrgID, true, nil
+}
 
-	return uuid.Nil, fmt.Errorf("no org found for Stripe account %s", stripeAccountID)
+func (s *StripeWebhookService) rememberAccount(stripeAccountID string, orgID uuid.UUID) {
+	s.accountsMu.Lock()
+	defer s.accountsMu.Unlock()
+	s.accounts[stripeAccountID] = stripeAccountEntry{orgID: orgID, expires: time.Now().Add(stripeAccountCacheTTL)}
 }
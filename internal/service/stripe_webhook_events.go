@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v81"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// BillingEventTypes are the normalized customer_events types recorded from
+// Stripe billing activity. They can be used by billing_event alert rules.
+var BillingEventTypes = []string{
+	"payment.failed",
+	"payment.refunded",
+	"dispute.opened",
+	"dispute.won",
+	"dispute.lost",
+	"trial.ending",
+	"subscription.past_due",
+	"subscription.paused",
+	"subscription.resumed",
+	"plan.upgraded",
+	"plan.downgraded",
+	"invoice.upcoming",
+}
+
+// IsBillingEventType reports whether eventType is one of BillingEventTypes.
+func IsBillingEventType(eventType string) bool {
+	for _, t := range BillingEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// BillingEventLabel returns a human-readable label for a billing event type.
+func BillingEventLabel(eventType string) string {
+	switch eventType {
+	case "payment.failed":
+		return "Payment failed"
+	case "payment.refunded":
+		return "Payment refunded"
+	case "dispute.opened":
+		return "Dispute opened"
+	case "dispute.won":
+		return "Dispute won"
+	case "dispute.lost":
+		return "Dispute lost"
+	case "trial.ending":
+		return "Trial ending"
+	case "subscription.past_due":
+		return "Subscription past due"
+	case "subscription.paused":
+		return "Subscription paused"
+	case "subscription.resumed":
+		return "Subscription resumed"
+	case "plan.upgraded":
+		return "Plan upgraded"
+	case "plan.downgraded":
+		return "Plan downgraded"
+	case "invoice.upcoming":
+		return "Upcoming invoice"
+	}
+	return eventType
+}
+
+func (s *StripeWebhookService) handleChargeRefunded(ctx context.Context, event stripe.Event) error {
+	var ch stripe.Charge
+	if err := json.Unmarshal(event.Data.Raw, &ch); err != nil {
+		return fmt.Errorf("unmarshal charge: %w", err)
+	}
+
+	orgID, err := s.findOrgForStripeAccount(ctx, event.Account)
+	if err != nil {
+		return err
+	}
+	if ch.Customer == nil {
+		return nil
+	}
+
+	customerID, ok, err := s.localCustomerID(ctx, orgID, ch.Customer.ID)
+	if err != nil || !ok {
+		return err
+	}
+
+	return s.recordBillingEvent(ctx, event, orgID, customerID, "payment.refunded", map[string]any{
+		"charge_id":    ch.ID,
+		"amount_cents": ch.AmountRefunded,
+		"currency":     string(ch.Currency),
+		"full_refund":  ch.Refunded,
+	})
+}
+
+func (s *StripeWebhookService) handleDisputeEvent(ctx context.Context, event stripe.Event) error {
+	var dispute stripe.Dispute
+	if err := json.Unmarshal(event.Data.Raw, &dispute); err != nil {
+		return fmt.Errorf("unmarshal dispute: %w", err)
+	}
+
+	orgID, err := s.findOrgForStripeAccount(ctx, event.Account)
+	if err != nil {
+		return err
+	}
+	if dispute.Charge == nil {
+		return nil
+	}
+
+	var eventType string
+	switch {
+	case event.Type == "charge.dispute.created":
+		eventType = "dispute.opened"
+	case dispute.Status == stripe.DisputeStatusWon:
+		eventType = "dispute.won"
+	case dispute.Status == stripe.DisputeStatusLost:
+		eventType = "dispute.lost"
+	default:
+		// Closed inquiries (warning_closed) carry no outcome
+		return nil
+	}
+
+	// Dispute payloads only reference the charge, so resolve the customer
+	// through the synced payment.
+	payment, err := s.payments.GetByStripeID(ctx, dispute.Charge.ID)
+	if err != nil {
+		return fmt.Errorf("get disputed payment: %w", err)
+	}
+	if payment == nil || payment.OrgID != orgID {
+		slog.Warn("dispute webhook: charge not found locally",
+			"stripe_charge_id", dispute.Charge.ID,
+			"stripe_dispute_id", dispute.ID,
+		)
+		return nil
+	}
+
+	return s.recordBillingEvent(ctx, event, orgID, payment.CustomerID, eventType, map[string]any{
+		"dispute_id":   dispute.ID,
+		"charge_id":    dispute.Charge.ID,
+		"amount_cents": dispute.Amount,
+		"currency":     string(dispute.Currency),
+		"reason":       string(dispute.Reason),
+		"status":       string(dispute.Status),
+	})
+}
+
+func (s *StripeWebhookService) handleTrialWillEnd(ctx context.Context, event stripe.Event) error {
+	var sub stripe.Subscription
+	if err := json.Unmarshal(event.Data.Raw, &sub); err != nil {
+		return fmt.Errorf("unmarshal subscription: %w", err)
+	}
+
+	orgID, err := s.findOrgForStripeAccount(ctx, event.Account)
+	if err != nil {
+		return err
+	}
+	if sub.Customer == nil {
+		return nil
+	}
+
+	customerID, ok, err := s.localCustomerID(ctx, orgID, sub.Customer.ID)
+	if err != nil || !ok {
+		return err
+	}
+
+	data := map[string]any{"subscription_id": sub.ID}
+	if sub.TrialEnd > 0 {
+		data["trial_end"] = time.Unix(sub.TrialEnd, 0).UTC().Format(time.RFC3339)
+	}
+	return s.recordBillingEvent(ctx, event, orgID, customerID, "trial.ending", data)
+}
+
+func (s *StripeWebhookService) handleInvoiceUpcoming(ctx context.Context, event stripe.Event) error {
+	var inv stripe.Invoice
+	if err := json.Unmarshal(event.Data.Raw, &inv); err != nil {
+		return fmt.Errorf("unmarshal invoice: %w", err)
+	}
+
+	orgID, err := s.findOrgForStripeAccount(ctx, event.Account)
+	if err != nil {
+		return err
+	}
+	if inv.Customer == nil {
+		return nil
+	}
+
+	customerID, ok, err := s.localCustomerID(ctx, orgID, inv.Customer.ID)
+	if err != nil || !ok {
+		return err
+	}
+
+	data := map[string]any{
+		"amount_cents": inv.AmountDue,
+		"currency":     string(inv.Currency),
+	}
+	if inv.Subscription != nil {
+		data["subscription_id"] = inv.Subscription.ID
+	}
+	if inv.NextPaymentAttempt > 0 {
+		data["due_at"] = time.Unix(inv.NextPaymentAttempt, 0).UTC().Format(time.RFC3339)
+	}
+	return s.recordBillingEvent(ctx, event, orgID, customerID, "invoice.upcoming", data)
+}
+
+// recordSubscriptionChanges emits billing events for the difference between
+// the stored subscription and the one just received. Comparing against local
+// state keeps the paused/resumed and updated events Stripe sends for the same
+// change from being counted twice.
+func (s *StripeWebhookService) recordSubscriptionChanges(ctx context.Context, event stripe.Event, prev, next *repository.StripeSubscription) {
+	if prev == nil {
+		return
+	}
+
+	var types []string
+	if prev.Status != next.Status {
+		switch {
+		case next.Status == "past_due":
+			types = append(types, "subscription.past_due")
+		case next.Status == "paused":
+			types = append(types, "subscription.paused")
+		case prev.Status == "paused" && next.Status == "active":
+			types = append(types, "subscription.resumed")
+		}
+	}
+	if prev.AmountCents > 0 && next.AmountCents > 0 && next.Status != "canceled" {
+		switch {
+		case next.AmountCents > prev.AmountCents:
+			types = append(types, "plan.upgraded")
+		case next.AmountCents < prev.AmountCents:
+			types = append(types, "plan.downgraded")
+		}
+	}
+
+	for _, eventType := range types {
+		data := map[string]any{
+			"subscription_id": next.StripeSubscriptionID,
+			"previous_status": prev.Status,
+			"status":          next.Status,
+			"previous_plan":   prev.PlanName,
+			"plan":            next.PlanName,
+			"previous_amount": prev.AmountCents,
+			"amount_cents":    next.AmountCents,
+			"currency":        next.Currency,
+			"interval":        next.Interval,
+		}
+		if err := s.recordBillingEvent(ctx, event, next.OrgID, next.CustomerID, eventType, data); err != nil {
+			slog.Error("failed to record subscription change", "event_type", eventType, "error", err)
+		}
+	}
+}
+
+// localCustomerID resolves a Stripe customer to the org's local customer. ok
+// is false when the customer has not been synced yet.
+func (s *StripeWebhookService) localCustomerID(ctx context.Context, orgID uuid.UUID, stripeCustomerID string) (uuid.UUID, bool, error) {
+	localCustomer, err := s.customers.GetByExternalID(ctx, orgID, "stripe", stripeCustomerID)
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("get customer: %w", err)
+	}
+	if localCustomer == nil {
+		slog.Warn("stripe webhook: customer not found locally", "stripe_customer_id", stripeCustomerID)
+		return uuid.Nil, false, nil
+	}
+	return localCustomer.ID, true, nil
+}
+
+// recordBillingEvent stores a normalized customer event for a Stripe webhook.
+// A webhook can yield several event types, so the type is part of the key.
+func (s *StripeWebhookService) recordBillingEvent(
+	ctx context.Context,
+	event stripe.Event,
+	orgID, customerID uuid.UUID,
+	eventType string,
+	data map[string]any,
+) error {
+	occurredAt := time.Now()
+	if event.Created > 0 {
+		occurredAt = time.Unix(event.Created, 0)
+	}
+
+	custEvent := &repository.CustomerEvent{
+		OrgID:           orgID,
+		CustomerID:      customerID,
+		EventType:       eventType,
+		Source:          "stripe",
+		ExternalEventID: "webhook_" + event.ID + "_" + eventType,
+		OccurredAt:      occurredAt,
+		Data:            data,
+	}
+	if err := s.events.Upsert(ctx, custEvent); err != nil {
+		return fmt.Errorf("create %s event: %w", eventType, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v81"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+type fakeCustomerEventStore struct {
+	events []*repository.CustomerEvent
+}
+
+func (f *fakeCustomerEventStore) Upsert(ctx context.Context, event *repository.CustomerEvent) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+func TestRecordSubscriptionChanges(t *testing.T) {
+	sub := func(status string, amountCents int) *repository.StripeSubscription {
+		return &repository.StripeSubscription{StripeSubscriptionID: "sub_1", Status: status, AmountCents: amountCents}
+	}
+
+	tests := []struct {
+		name       string
+		prev, next *repository.StripeSubscription
+		want       []string
+	}{
+		{"new subscription", nil, sub("active", 5000), nil},
+		{"unchanged", sub("active", 5000), sub("active", 5000), nil},
+		{"past due", sub("active", 5000), sub("past_due", 5000), []string{"subscription.past_due"}},
+		{"paused", sub("active", 5000), sub("paused", 5000), []string{"subscription.paused"}},
+		{"resumed", sub("paused", 5000), sub("active", 5000), []string{"subscription.resumed"}},
+		{"recovered from past due is not a resume", sub("past_due", 5000), sub("active", 5000), nil},
+		{"upgrade", sub("active", 5000), sub("active", 9900), []string{"plan.upgraded"}},
+		{"downgrade", sub("active", 9900), sub("active", 5000), []string{"plan.downgraded"}},
+		{"downgrade while past due", sub("active", 9900), sub("past_due", 5000), []string{"subscription.past_due", "plan.downgraded"}},
+		{"canceled is not a downgrade", sub("active", 9900), sub("canceled", 5000), nil},
+		{"from free is not an upgrade", sub("trialing", 0), sub("trialing", 5000), nil},
+		{"to free is not a downgrade", sub("active", 5000), sub("active", 0), nil},
+	}
+	for _, tt := range tests {
+		events := &fakeCustomerEventStore{}
+		s := &StripeWebhookService{events: events}
+		event := stripe.Event{ID: "evt_1", Created: 1767225600}
+		if tt.next != nil {
+			tt.next.OrgID = uuid.New()
+			tt.next.CustomerID = uuid.New()
+		}
+		s.recordSubscriptionChanges(context.Background(), event, tt.prev, tt.next)
+
+		var got []string
+		for _, e := range events.events {
+			got = append(got, e.EventType)
+			if e.OrgID != tt.next.OrgID || e.CustomerID != tt.next.CustomerID {
+				t.Errorf("%s: expected the event on the subscription's customer, got %+v", tt.name, e)
+			}
+			if e.ExternalEventID != "webhook_evt_1_"+e.EventType {
+				t.Errorf("%s: expected the event type in the external ID, got %q", tt.name, e.ExternalEventID)
+			}
+			if e.Data["previous_status"] != tt.prev.Status || e.Data["previous_amount"] != tt.prev.AmountCents {
+				t.Errorf("%s: expected the previous state in the event data, got %v", tt.name, e.Data)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/webhook"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const testStripeWebhookSecret = "whsec_test"
+
+type fakeStripeAccountConnections struct {
+	byAccount map[string]*repository.IntegrationConnection
+	byOrg     *repository.IntegrationConnection
+	lookups   int
+	err       error
+}
+
+func (f *fakeStripeAccountConnections) GetByOrgAndProvider(ctx context.Context, orgID uuid.UUID, provider string) (*repository.IntegrationConnection, error) {
+	return f.byOrg, nil
+}
+
+func (f *fakeStripeAccountConnections) GetByProviderAndExternalID(ctx context.Context, provider, externalAccountID string) (*repository.IntegrationConnection, error) {
+	f.lookups++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.byAccount[externalAccountID], nil
+}
+
+type fakeWebhookQuarantineStore struct {
+	inserted   []*repository.QuarantinedWebhook
+	unreleased []*repository.QuarantinedWebhook
+	released   []uuid.UUID
+}
+
+func (f *fakeWebhookQuarantineStore) Insert(ctx context.Context, q *repository.QuarantinedWebhook) error {
+	f.inserted = append(f.inserted, q)
+	return nil
+}
+
+func (f *fakeWebhookQuarantineStore) ListUnreleased(ctx context.Context, provider, accountID string) ([]*repository.QuarantinedWebhook, error) {
+	return f.unreleased, nil
+}
+
+func (f *fakeWebhookQuarantineStore) MarkReleased(ctx context.Context, id uuid.UUID) error {
+	f.released = append(f.released, id)
+	return nil
+}
+
+type queuedWebhook struct {
+	eventID string
+	orgID   uuid.UUID
+}
+
+type fakeWebhookEnqueuer struct {
+	queued []queuedWebhook
+	err    error
+}
+
+func (f *fakeWebhookEnqueuer) Enqueue(ctx context.Context, provider, eventID, eventType string, orgID *uuid.UUID, payload []byte) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.queued = append(f.queued, queuedWebhook{eventID: eventID, orgID: *orgID})
+	return nil
+}
+
+func newTestStripeWebhook(conns *fakeStripeAccountConnections) (*StripeWebhookService, *fakeWebhookQuarantineStore, *fakeWebhookEnqueuer) {
+	quarantine := &fakeWebhookQuarantineStore{}
+	inbox := &fakeWebhookEnqueuer{}
+	return &StripeWebhookService{
+		webhookSecret: testStripeWebhookSecret,
+		connRepo:      conns,
+		quarantine:    quarantine,
+		inbox:         inbox,
+		accounts:      make(map[string]stripeAccountEntry),
+	}, quarantine, inbox
+}
+
+// signedStripeEvent returns a webhook payload for an event from account and
+// its signature header.
+func signedStripeEvent(eventID, account string) ([]byte, string) {
+	payload := []byte(fmt.Sprintf(
+		`{"id":%q,"object":"event","type":"customer.updated","account":%q,"api_version":%q,"data":{"object":{}}}`,
+		eventID, account, stripe.APIVersion,
+	))
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: testStripeWebhookSecret})
+	return payload, signed.Header
+}
+
+func TestStripeWebhookLookupAccount(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	conns := &fakeStripeAccountConnections{byAccount: map[string]*repository.IntegrationConnection{
+		"acct_1": {OrgID: orgID},
+	}}
+	s, _, _ := newTestStripeWebhook(conns)
+
+	for i := 0; i < 2; i++ {
+		got, found, err := s.lookupAccount(ctx, "acct_1")
+		if err != nil || !found || got != orgID {
+			t.Fatalf("expected acct_1 to resolve to %s, got %s, %v, %v", orgID, got, found, err)
+		}
+	}
+	if conns.lookups != 1 {
+		t.Errorf("expected the second lookup to be served from the cache, got %d queries", conns.lookups)
+	}
+
+	s.accounts["acct_1"] = stripeAccountEntry{orgID: orgID, expires: time.Now().Add(-time.Second)}
+	if _, _, err := s.lookupAccount(ctx, "acct_1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conns.lookups != 2 {
+		t.Errorf("expected an expired entry to be looked up again, got %d queries", conns.lookups)
+	}
+	if entry := s.accounts["acct_1"]; time.Until(entry.expires) <= stripeAccountCacheTTL-time.Minute {
+		t.Errorf("expected the entry to be refreshed for the TTL, expires %s", entry.expires)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, found, err := s.lookupAccount(ctx, "acct_unknown"); err != nil || found {
+			t.Fatalf("expected an unknown account not to resolve, got %v, %v", found, err)
+		}
+	}
+	if conns.lookups != 4 {
+		t.Errorf("expected unknown accounts not to be cached, got %d queries", conns.lookups)
+	}
+
+	conns.err = errors.New("db down")
+	if _, _, err := s.lookupAccount(ctx, "acct_2"); !errors.Is(err, conns.err) {
+		t.Errorf("expected the lookup error, got %v", err)
+	}
+}
+
+func TestStripeWebhookHandleEvent(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	conns := &fakeStripeAccountConnections{byAccount: map[string]*repository.IntegrationConnection{
+		"acct_1": {OrgID: orgID},
+	}}
+
+	t.Run("invalid signature", func(t *testing.T) {
+		s, _, inbox := newTestStripeWebhook(conns)
+		payload, _ := signedStripeEvent("evt_1", "acct_1")
+		var vErr *ValidationError
+		if err := s.HandleEvent(ctx, payload, "t=1,v1=bad"); !errors.As(err, &vErr) {
+			t.Errorf("expected a ValidationError, got %v", err)
+		}
+		if len(inbox.queued) != 0 {
+			t.Errorf("expected nothing to be queued, got %v", inbox.queued)
+		}
+	})
+
+	t.Run("platform event is skipped", func(t *testing.T) {
+		s, quarantine, inbox := newTestStripeWebhook(conns)
+		payload, header := signedStripeEvent("evt_platform", "")
+		if err := s.HandleEvent(ctx, payload, header); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(inbox.queued) != 0 || len(quarantine.inserted) != 0 {
+			t.Errorf("expected a platform event to be acknowledged without processing, got %v queued, %v quarantined", inbox.queued, quarantine.inserted)
+		}
+	})
+
+	t.Run("known account is queued for its org", func(t *testing.T) {
+		s, _, inbox := newTestStripeWebhook(conns)
+		payload, header := signedStripeEvent("evt_known", "acct_1")
+		if err := s.HandleEvent(ctx, payload, header); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(inbox.queued) != 1 || inbox.queued[0] != (queuedWebhook{eventID: "evt_known", orgID: orgID}) {
+			t.Errorf("expected the event to be queued for %s, got %v", orgID, inbox.queued)
+		}
+	})
+
+	t.Run("unknown account is quarantined", func(t *testing.T) {
+		s, quarantine, inbox := newTestStripeWebhook(conns)
+		payload, header := signedStripeEvent("evt_unknown", "acct_unknown")
+		if err := s.HandleEvent(ctx, payload, header); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(inbox.queued) != 0 {
+			t.Errorf("expected nothing to be queued, got %v", inbox.queued)
+		}
+		if len(quarantine.inserted) != 1 {
+			t.Fatalf("expected the event to be quarantined, got %v", quarantine.inserted)
+		}
+		q := quarantine.inserted[0]
+		if q.Provider != "stripe" || q.EventID != "evt_unknown" || q.EventType != "customer.updated" || q.AccountID != "acct_unknown" || string(q.Payload) != string(payload) {
+			t.Errorf("unexpected quarantined event: %+v", q)
+		}
+	})
+}
+
+func TestStripeWebhookReleaseQuarantined(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+
+	s, quarantine, inbox := newTestStripeWebhook(&fakeStripeAccountConnections{})
+	quarantine.unreleased = []*repository.QuarantinedWebhook{{ID: uuid.New(), EventID: "evt_1"}}
+	if err := s.ReleaseQuarantined(ctx, orgID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(inbox.queued) != 0 || len(quarantine.released) != 0 {
+		t.Errorf("expected nothing to be released without a connection, got %v", inbox.queued)
+	}
+
+	conns := &fakeStripeAccountConnections{byOrg: &repository.IntegrationConnection{OrgID: orgID, ExternalAccountID: "acct_1"}}
+	s, quarantine, inbox = newTestStripeWebhook(conns)
+	first, second := uuid.New(), uuid.New()
+	quarantine.unreleased = []*repository.QuarantinedWebhook{
+		{ID: first, EventID: "evt_1", EventType: "customer.updated"},
+		{ID: second, EventID: "evt_2", EventType: "invoice.paid"},
+	}
+	if err := s.ReleaseQuarantined(ctx, orgID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(inbox.queued) != 2 || inbox.queued[0] != (queuedWebhook{eventID: "evt_1", orgID: orgID}) || inbox.queued[1].eventID != "evt_2" {
+		t.Errorf("expected both events to be queued for the org, got %v", inbox.queued)
+	}
+	if len(quarantine.released) != 2 || quarantine.released[0] != first || quarantine.released[1] != second {
+		t.Errorf("expected both events to be marked released, got %v", quarantine.released)
+	}
+	if got, found, _ := s.lookupAccount(ctx, "acct_1"); !found || got != orgID || conns.lookups != 0 {
+		t.Errorf("expected the connected account to be cached, got %s, %v after %d queries", got, found, conns.lookups)
+	}
+
+	s, quarantine, inbox = newTestStripeWebhook(conns)
+	quarantine.unreleased = []*repository.QuarantinedWebhook{{ID: uuid.New(), EventID: "evt_1"}}
+	inbox.err = errors.New("inbox down")
+	if err := s.ReleaseQuarantined(ctx, orgID); !errors.Is(err, inbox.err) {
+		t.Errorf("expected the enqueue error, got %v", err)
+	}
+	if len(quarantine.released) != 0 {
+		t.Errorf("expected an event that failed to queue to stay quarantined, got %v", quarantine.released)
+	}
+}
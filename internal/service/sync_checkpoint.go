@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	// syncChunkReserve is the time left before the sync deadline at which a
+	// paginated sync stops fetching pages and pauses at its checkpoint.
+	syncChunkReserve = time.Minute
+	// syncCheckpointMaxAge bounds how old a cursor may be and still be resumed;
+	// provider cursors are not guaranteed to stay valid indefinitely.
+	syncCheckpointMaxAge = 24 * time.Hour
+	// syncStepDone is the cursor of a checkpoint that records a finished step
+	// of a paused full sync rather than a page to fetch.
+	syncStepDone = "done"
+)
+
+// errSyncPaused is returned by a paginated sync step that stopped early to stay
+// within its time budget. Its checkpoint is kept so the next run resumes there.
+var errSyncPaused = errors.New("sync paused; will resume from checkpoint")
+
+// syncCheckpointStore is the part of SyncCheckpointRepository a
+// syncCheckpointer uses.
+type syncCheckpointStore interface {
+	Get(ctx context.Context, orgID uuid.UUID, provider, objectType string) (*repository.SyncCheckpoint, error)
+	Save(ctx context.Context, orgID uuid.UUID, provider, objectType, cursor string) error
+	Delete(ctx context.Context, orgID uuid.UUID, provider, objectType string) error
+}
+
+// syncCheckpointer persists pagination cursors for one provider. A nil
+// repository or an empty object type disables checkpointing.
+type syncCheckpointer struct {
+	repo     syncCheckpointStore
+	provider string
+}
+
+// newSyncCheckpointer creates a syncCheckpointer for provider; a nil repo
+// disables checkpointing.
+func newSyncCheckpointer(repo *repository.SyncCheckpointRepository, provider string) syncCheckpointer {
+	c := syncCheckpointer{provider: provider}
+	if repo != nil {
+		c.repo = repo
+	}
+	return c
+}
+
+// load returns the cursor to resume from, or "" to start from the first page.
+func (c syncCheckpointer) load(ctx context.Context, orgID uuid.UUID, objectType string) string {
+	if c.repo == nil || objectType == "" {
+		return ""
+	}
+
+	cp, err := c.repo.Get(ctx, orgID, c.provider, objectType)
+	if err != nil {
+		slog.Error("failed to load sync checkpoint", "org_id", orgID, "provider", c.provider, "object", objectType, "error", err)
+		return ""
+	}
+	if cp == nil {
+		return ""
+	}
+	if time.Since(cp.UpdatedAt) > syncCheckpointMaxAge {
+		slog.Info("discarding stale sync checkpoint", "org_id", orgID, "provider", c.provider, "object", objectType)
+		c.clear(ctx, orgID, objectType)
+		return ""
+	}
+
+	slog.Info("resuming sync from checkpoint", "org_id", orgID, "provider", c.provider, "object", objectType)
+	return cp.Cursor
+}
+
+// save records the cursor of the next page to fetch.
+func (c syncCheckpointer) save(ctx context.Context, orgID uuid.UUID, objectType, cursor string) {
+	if c.repo == nil || objectType == "" {
+		return
+	}
+	if err := c.repo.Save(ctx, orgID, c.provider, objectType, cursor); err != nil {
+		slog.Error("failed to save sync checkpoint", "org_id", orgID, "provider", c.provider, "object", objectType, "error", err)
+	}
+}
+
+// clear removes the checkpoint once every page has been synced.
+func (c syncCheckpointer) clear(ctx context.Context, orgID uuid.UUID, objectType string) {
+	if c.repo == nil || objectType == "" {
+		return
+	}
+	if err := c.repo.Delete(ctx, orgID, c.provider, objectType); err != nil {
+		slog.Error("failed to clear sync checkpoint", "org_id", orgID, "provider", c.provider, "object", objectType, "error", err)
+	}
+}
+
+// shouldPause reports whether a sync checkpointing under objectType should
+// stop and pause at its checkpoint to stay within its time budget. Syncs
+// without a checkpoint, such as incremental ones, always run to the end.
+func (c syncCheckpointer) shouldPause(ctx context.Context, objectType string) bool {
+	return objectType != "" && syncBudgetExhausted(ctx)
+}
+
+// runStep runs one paginated step of a full sync unless a full sync that
+// paused has already finished it, in which case it returns nil progress.
+// A step that finishes is recorded so a resumed sync skips it.
+func (c syncCheckpointer) runStep(
+	ctx context.Context,
+	orgID uuid.UUID,
+	step string,
+	fn func(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error),
+) (*SyncProgress, error) {
+	if c.stepDone(ctx, orgID, step) {
+		slog.Info("skipping sync step finished before the sync paused", "org_id", orgID, "provider", c.provider, "step", step)
+		return nil, nil
+	}
+	progress, err := fn(ctx, orgID)
+	if err == nil {
+		c.save(ctx, orgID, syncStepObject(step), syncStepDone)
+	}
+	return progress, err
+}
+
+// stepDone reports whether a paused full sync finished a step.
+func (c syncCheckpointer) stepDone(ctx context.Context, orgID uuid.UUID, step string) bool {
+	if c.repo == nil {
+		return false
+	}
+	cp, err := c.repo.Get(ctx, orgID, c.provider, syncStepObject(step))
+	if err != nil {
+		slog.Error("failed to load sync step", "org_id", orgID, "provider", c.provider, "step", step, "error", err)
+		return false
+	}
+	return cp != nil && time.Since(cp.UpdatedAt) <= syncCheckpointMaxAge
+}
+
+// clearSteps removes the finished-step records once a full sync completes.
+func (c syncCheckpointer) clearSteps(ctx context.Context, orgID uuid.UUID, steps ...string) {
+	for _, step := range steps {
+		c.clear(ctx, orgID, syncStepObject(step))
+	}
+}
+
+// syncStepObject is the checkpoint object type that records a finished step.
+func syncStepObject(step string) string {
+	return "step:" + step
+}
+
+// syncBudgetExhausted reports whether a checkpointed sync should pause rather
+// than fetch another page, so it finishes before its context deadline.
+func syncBudgetExhausted(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < syncChunkReserve
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+type fakeSyncCheckpointStore struct {
+	checkpoints map[string]*repository.SyncCheckpoint
+	getErr      error
+}
+
+func newFakeSyncCheckpointStore() *fakeSyncCheckpointStore {
+	return &fakeSyncCheckpointStore{checkpoints: map[string]*repository.SyncCheckpoint{}}
+}
+
+func (f *fakeSyncCheckpointStore) Get(ctx context.Context, orgID uuid.UUID, provider, objectType string) (*repository.SyncCheckpoint, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	return f.checkpoints[provider+"/"+objectType], nil
+}
+
+func (f *fakeSyncCheckpointStore) Save(ctx context.Context, orgID uuid.UUID, provider, objectType, cursor string) error {
+	f.checkpoints[provider+"/"+objectType] = &repository.SyncCheckpoint{
+		OrgID: orgID, Provider: provider, ObjectType: objectType, Cursor: cursor, UpdatedAt: time.Now(),
+	}
+	return nil
+}
+
+func (f *fakeSyncCheckpointStore) Delete(ctx context.Context, orgID uuid.UUID, provider, objectType string) error {
+	delete(f.checkpoints, provider+"/"+objectType)
+	return nil
+}
+
+// pagedSync simulates a checkpointed paginated sync over pages cursors, the
+// way SyncContacts walks provider pages. It records the pages it fetched.
+func pagedSync(c syncCheckpointer, objectType string, pages int, fetched *[]int) func(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error) {
+	return func(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error) {
+		progress := &SyncProgress{}
+		page := 0
+		if cursor := c.load(ctx, orgID, objectType); cursor != "" {
+			page = int(cursor[0] - '0')
+		}
+		for ; page < pages; page++ {
+			*fetched = append(*fetched, page)
+			progress.Total++
+			c.save(ctx, orgID, objectType, string(rune('0'+page+1)))
+			if c.shouldPause(ctx, objectType) {
+				return progress, errSyncPaused
+			}
+		}
+		c.clear(ctx, orgID, objectType)
+		return progress, nil
+	}
+}
+
+func TestSyncCheckpointer_LoadSaveClear(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	store := newFakeSyncCheckpointStore()
+	c := syncCheckpointer{repo: store, provider: "hubspot"}
+
+	if got := c.load(ctx, orgID, "contacts"); got != "" {
+		t.Errorf("expected no cursor before a save, got %q", got)
+	}
+	c.save(ctx, orgID, "contacts", "after-100")
+	if got := c.load(ctx, orgID, "contacts"); got != "after-100" {
+		t.Errorf("expected the saved cursor, got %q", got)
+	}
+	if got := (syncCheckpointer{repo: store, provider: "intercom"}).load(ctx, orgID, "contacts"); got != "" {
+		t.Errorf("expected checkpoints to be per provider, got %q", got)
+	}
+	c.clear(ctx, orgID, "contacts")
+	if got := c.load(ctx, orgID, "contacts"); got != "" {
+		t.Errorf("expected no cursor after clear, got %q", got)
+	}
+
+	c.save(ctx, orgID, "", "after-100")
+	if len(store.checkpoints) != 0 {
+		t.Errorf("expected an empty object type not to be saved, got %v", store.checkpoints)
+	}
+
+	c.save(ctx, orgID, "deals", "after-5")
+	store.checkpoints["hubspot/deals"].UpdatedAt = time.Now().Add(-syncCheckpointMaxAge - time.Minute)
+	if got := c.load(ctx, orgID, "deals"); got != "" {
+		t.Errorf("expected a stale cursor to be discarded, got %q", got)
+	}
+	if _, ok := store.checkpoints["hubspot/deals"]; ok {
+		t.Error("expected a stale cursor to be deleted")
+	}
+
+	store.getErr = errors.New("db down")
+	if got := c.load(ctx, orgID, "contacts"); got != "" {
+		t.Errorf("expected a failed lookup to start from the first page, got %q", got)
+	}
+
+	nop := newSyncCheckpointer(nil, "hubspot")
+	nop.save(ctx, orgID, "contacts", "after-100")
+	nop.clear(ctx, orgID, "contacts")
+	if got := nop.load(ctx, orgID, "contacts"); got != "" {
+		t.Errorf("expected a nil repository to disable checkpoints, got %q", got)
+	}
+}
+
+func TestSyncCheckpointer_RunStep(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	store := newFakeSyncCheckpointStore()
+	c := syncCheckpointer{repo: store, provider: "hubspot"}
+
+	calls := 0
+	step := func(err error) func(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error) {
+		return func(ctx context.Context, orgID uuid.UUID) (*SyncProgress, error) {
+			calls++
+			return &SyncProgress{Total: 1}, err
+		}
+	}
+
+	if _, err := c.runStep(ctx, orgID, "contacts", step(errSyncPaused)); !errors.Is(err, errSyncPaused) {
+		t.Fatalf("expected errSyncPaused, got %v", err)
+	}
+	if c.stepDone(ctx, orgID, "contacts") {
+		t.Error("expected a paused step not to be recorded as done")
+	}
+	if _, err := c.runStep(ctx, orgID, "contacts", step(errors.New("api down"))); err == nil {
+		t.Fatal("expected the step's error")
+	}
+	if c.stepDone(ctx, orgID, "contacts") {
+		t.Error("expected a failed step not to be recorded as done")
+	}
+
+	progress, err := c.runStep(ctx, orgID, "contacts", step(nil))
+	if err != nil || progress == nil {
+		t.Fatalf("expected progress, got %v, %v", progress, err)
+	}
+	if cp := store.checkpoints["hubspot/step:contacts"]; cp == nil || cp.Cursor != syncStepDone {
+		t.Fatalf("expected step:contacts to be recorded as %q, got %+v", syncStepDone, cp)
+	}
+
+	calls = 0
+	progress, err = c.runStep(ctx, orgID, "contacts", step(nil))
+	if err != nil || progress != nil || calls != 0 {
+		t.Errorf("expected a done step to be skipped, got %v, %v after %d calls", progress, err, calls)
+	}
+
+	store.checkpoints["hubspot/step:contacts"].UpdatedAt = time.Now().Add(-syncCheckpointMaxAge - time.Minute)
+	if _, err := c.runStep(ctx, orgID, "contacts", step(nil)); err != nil || calls != 1 {
+		t.Errorf("expected a stale step record to be ignored, got %v after %d calls", err, calls)
+	}
+
+	c.save(ctx, orgID, syncStepObject("deals"), syncStepDone)
+	c.save(ctx, orgID, "companies", "after-7")
+	c.clearSteps(ctx, orgID, "contacts", "deals")
+	if len(store.checkpoints) != 1 || store.checkpoints["hubspot/companies"] == nil {
+		t.Errorf("expected only the step records to be cleared, got %v", store.checkpoints)
+	}
+}
+
+func TestSyncCheckpointer_PauseAndResume(t *testing.T) {
+	orgID := uuid.New()
+	store := newFakeSyncCheckpointStore()
+	c := syncCheckpointer{repo: store, provider: "intercom"}
+	var fetched []int
+
+	// Inside the reserve every page exhausts the budget, so each run fetches
+	// one page and pauses.
+	short, cancel := context.WithTimeout(context.Background(), syncChunkReserve/2)
+	defer cancel()
+	if _, err := c.runStep(short, orgID, "contacts", pagedSync(c, "contacts", 3, &fetched)); !errors.Is(err, errSyncPaused) {
+		t.Fatalf("expected the sync to pause, got %v", err)
+	}
+	if cp := store.checkpoints["intercom/contacts"]; cp == nil || cp.Cursor != "1" {
+		t.Fatalf("expected the cursor of the next page to be kept, got %+v", cp)
+	}
+
+	progress, err := c.runStep(context.Background(), orgID, "contacts", pagedSync(c, "contacts", 3, &fetched))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if progress.Total != 2 || len(fetched) != 3 || fetched[1] != 1 || fetched[2] != 2 {
+		t.Errorf("expected the resumed sync to fetch the remaining pages, got %v", fetched)
+	}
+	if _, ok := store.checkpoints["intercom/contacts"]; ok {
+		t.Error("expected the cursor to be cleared once every page is synced")
+	}
+	if !c.stepDone(context.Background(), orgID, "contacts") {
+		t.Error("expected the finished step to be recorded")
+	}
+
+	// A sync without a checkpoint, such as an incremental one, never pauses.
+	fetched = nil
+	if _, err := pagedSync(c, "", 3, &fetched)(short, orgID); err != nil || len(fetched) != 3 {
+		t.Errorf("expected an uncheckpointed sync to run to the end, got %v after %v", err, fetched)
+	}
+}
+
+func TestRunFullSyncStep_UnknownStep(t *testing.T) {
+	hubspot := &HubSpotSyncService{}
+	if _, err := hubspot.RunFullSyncStep(context.Background(), uuid.New(), "tickets"); err == nil {
+		t.Error("expected an unknown hubspot step to be rejected")
+	}
+	intercom := &IntercomSyncService{}
+	if _, err := intercom.RunFullSyncStep(context.Background(), uuid.New(), "tickets"); err == nil {
+		t.Error("expected an unknown intercom step to be rejected")
+	}
+}
@@ -8,25 +8,29 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/integration"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
 // SyncOrchestratorService orchestrates the full sync pipeline:
 // customers → subscriptions → payments → MRR calculation.
 type SyncOrchestratorService struct {
-	connRepo  *repository.IntegrationConnectionRepository
-	syncSvc   *StripeSyncService
-	mrrSvc    *MRRService
+	connRepo *repository.IntegrationConnectionRepository
+	runs     *repository.SyncRunRepository
+	syncSvc  *StripeSyncService
+	mrrSvc   *MRRService
 }
 
 // NewSyncOrchestratorService creates a new SyncOrchestratorService.
 func NewSyncOrchestratorService(
 	connRepo *repository.IntegrationConnectionRepository,
+	runs *repository.SyncRunRepository,
 	syncSvc *StripeSyncService,
 	mrrSvc *MRRService,
 ) *SyncOrchestratorService {
 	return &SyncOrchestratorService{
 		connRepo: connRepo,
+		runs:     runs,
 		syncSvc:  syncSvc,
 		mrrSvc:   mrrSvc,
 	}
@@ -42,11 +46,14 @@ type SyncResult struct {
 	Error         string        `json:"error,omitempty"`
 }
 
-// RunFullSync runs the complete sync pipeline for an org.
-func (s *SyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UUID) *SyncResult {
+// RunFullSync runs the complete sync pipeline for an org and records it as a sync run.
+func (s *SyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UUID, trigger string) *SyncResult {
 	start := time.Now()
 	result := &SyncResult{}
 
+	ctx, run := startSyncRun(ctx, s.runs, orgID, "stripe", "full", trigger)
+	defer func() { run.finish(ctx, result.Error) }()
+
 	// Mark sync in progress
 	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "stripe", "syncing", nil); err != nil {
 		slog.Error("failed to update sync status", "error", err)
@@ -55,6 +62,7 @@ func (s *SyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UU
 	// Step 1: Sync customers
 	custProgress, err := s.syncSvc.SyncCustomers(ctx, orgID)
 	result.Customers = custProgress
+	run.record(custProgress)
 	if err != nil {
 		result.Error = fmt.Sprintf("customer sync failed: %v", err)
 		s.markSyncError(ctx, orgID, result.Error)
@@ -65,6 +73,7 @@ func (s *SyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UU
 	// Step 2: Sync subscriptions
 	subProgress, err := s.syncSvc.SyncSubscriptions(ctx, orgID)
 	result.Subscriptions = subProgress
+	run.record(subProgress)
 	if err != nil {
 		result.Error = fmt.Sprintf("subscription sync failed: %v", err)
 		s.markSyncError(ctx, orgID, result.Error)
@@ -75,6 +84,7 @@ func (s *SyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UU
 	// Step 3: Sync payments
 	payProgress, err := s.syncSvc.SyncPayments(ctx, orgID)
 	result.Payments = payProgress
+	run.record(payProgress)
 	if err != nil {
 		result.Error = fmt.Sprintf("payment sync failed: %v", err)
 		s.markSyncError(ctx, orgID, result.Error)
@@ -106,14 +116,18 @@ func (s *SyncOrchestratorService) RunFullSync(ctx context.Context, orgID uuid.UU
 	return result
 }
 
-// RunIncrementalSync runs an incremental sync since the last sync time.
-func (s *SyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time) *SyncResult {
+// RunIncrementalSync runs an incremental sync since the last sync time and records it as a sync run.
+func (s *SyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID uuid.UUID, since time.Time, trigger string) *SyncResult {
 	start := time.Now()
 	result := &SyncResult{}
 
+	ctx, run := startSyncRun(ctx, s.runs, orgID, "stripe", "incremental", trigger)
+	defer func() { run.finish(ctx, result.Error) }()
+
 	// Step 1: Incremental customer sync
 	custProgress, err := s.syncSvc.SyncCustomersSince(ctx, orgID, since)
 	result.Customers = custProgress
+	run.record(custProgress)
 	if err != nil {
 		result.Error = fmt.Sprintf("incremental customer sync failed: %v", err)
 		s.markSyncError(ctx, orgID, result.Error)
@@ -124,6 +138,7 @@ func (s *SyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID
 	// Step 2: Full subscription sync (Stripe API doesn't support created filter well for subs)
 	subProgress, err := s.syncSvc.SyncSubscriptions(ctx, orgID)
 	result.Subscriptions = subProgress
+	run.record(subProgress)
 	if err != nil {
 		result.Error = fmt.Sprintf("subscription sync failed: %v", err)
 		s.markSyncError(ctx, orgID, result.Error)
@@ -134,6 +149,7 @@ func (s *SyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID
 	// Step 3: Incremental payment sync
 	payProgress, err := s.syncSvc.SyncPaymentsSince(ctx, orgID, since)
 	result.Payments = payProgress
+	run.record(payProgress)
 	if err != nil {
 		result.Error = fmt.Sprintf("incremental payment sync failed: %v", err)
 		s.markSyncError(ctx, orgID, result.Error)
@@ -155,6 +171,23 @@ func (s *SyncOrchestratorService) RunIncrementalSync(ctx context.Context, orgID
 	return result
 }
 
+// Reconcile compares local Stripe subscriptions and customer MRR with
+// Stripe. MRR is checked after subscriptions so healed subscriptions are
+// reflected in the expected value.
+func (s *SyncOrchestratorService) Reconcile(ctx context.Context, orgID uuid.UUID, heal bool) (*integration.DriftReport, error) {
+	report := &integration.DriftReport{}
+
+	if err := s.syncSvc.ReconcileSubscriptions(ctx, orgID, heal, report); err != nil {
+		return report, fmt.Errorf("reconcile subscriptions: %w", err)
+	}
+
+	if err := s.mrrSvc.Reconcile(ctx, orgID, heal, report); err != nil {
+		return report, fmt.Errorf("reconcile mrr: %w", err)
+	}
+
+	return report, nil
+}
+
 func (s *SyncOrchestratorService) markSyncError(ctx context.Context, orgID uuid.UUID, errMsg string) {
 	if err := s.connRepo.UpdateSyncStatus(ctx, orgID, "stripe", "error", nil); err != nil {
 		slog.Error("failed to update sync error status", "error", err)
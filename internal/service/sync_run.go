@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// Sync run triggers.
+const (
+	SyncTriggerScheduler = "scheduler"
+	SyncTriggerManual    = "manual"
+)
+
+const (
+	// syncRunFlushInterval is how often live progress of a running sync is
+	// persisted. It doubles as a heartbeat for detecting interrupted runs.
+	syncRunFlushInterval = 5 * time.Second
+	// syncRunStaleAfter is how long a running sync may go without a heartbeat
+	// before it is considered interrupted.
+	syncRunStaleAfter = 5 * time.Minute
+)
+
+type syncRunKey struct{}
+
+// syncRunTracker records one sync run and its per-step progress.
+type syncRunTracker struct {
+	runs  *repository.SyncRunRepository
+	runID uuid.UUID
+	start time.Time
+
+	mu     sync.Mutex
+	steps  []repository.SyncRunStep
+	paused bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startSyncRun records a running sync and returns a context that carries its
+// tracker, so sync steps can report live progress with reportSyncProgress.
+// A nil repository disables recording.
+func startSyncRun(ctx context.Context, runs *repository.SyncRunRepository, orgID uuid.UUID, provider, mode, trigger string) (context.Context, *syncRunTracker) {
+	t := &syncRunTracker{
+		runs:  runs,
+		start: time.Now(),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	if runs != nil {
+		run := &repository.SyncRun{OrgID: orgID, Provider: provider, Mode: mode, Trigger: trigger}
+		if err := runs.Create(ctx, run); err != nil {
+			slog.Error("failed to record sync run", "org_id", orgID, "provider", provider, "error", err)
+		} else {
+			t.runID = run.ID
+		}
+	}
+
+	if t.runID == uuid.Nil {
+		close(t.done)
+		return context.WithValue(ctx, syncRunKey{}, t), t
+	}
+
+	go t.flushLoop(context.WithoutCancel(ctx))
+	return context.WithValue(ctx, syncRunKey{}, t), t
+}
+
+// reportSyncProgress updates the live progress of the sync run in ctx, if any.
+func reportSyncProgress(ctx context.Context, p *SyncProgress) {
+	t, ok := ctx.Value(syncRunKey{}).(*syncRunTracker)
+	if !ok || p == nil {
+		return
+	}
+	t.record(p)
+}
+
+func (t *syncRunTracker) record(p *SyncProgress) {
+	if p == nil {
+		return
+	}
+	step := repository.SyncRunStep{
+		Step:     p.Step,
+		Total:    p.Total,
+		Current:  p.Current,
+		Errors:   p.Errors,
+		Expected: p.Expected,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.steps {
+		if t.steps[i].Step == step.Step {
+			t.steps[i] = step
+			return
+		}
+	}
+	t.steps = append(t.steps, step)
+}
+
+func (t *syncRunTracker) snapshot() []repository.SyncRunStep {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	steps := make([]repository.SyncRunStep, len(t.steps))
+	copy(steps, t.steps)
+	return steps
+}
+
+func (t *syncRunTracker) flushLoop(ctx context.Context) {
+	defer close(t.done)
+
+	ticker := time.NewTicker(syncRunFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			if err := t.runs.UpdateSteps(ctx, t.runID, t.snapshot()); err != nil {
+				slog.Error("failed to update sync run progress", "run_id", t.runID, "error", err)
+			}
+		}
+	}
+}
+
+// pause marks the run as stopped early at a checkpoint, to be resumed by a later run.
+func (t *syncRunTracker) pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = true
+}
+
+// finish records the outcome of the run. An empty errMsg marks it succeeded,
+// or paused if pause was called.
+func (t *syncRunTracker) finish(ctx context.Context, errMsg string) {
+	if t.runID == uuid.Nil {
+		return
+	}
+	close(t.stop)
+	<-t.done
+
+	t.mu.Lock()
+	status := "succeeded"
+	if t.paused {
+		status = "paused"
+	}
+	t.mu.Unlock()
+	if errMsg != "" {
+		status = "failed"
+	}
+	elapsed := time.Since(t.start)
+
+	// The sync context may already be cancelled by its timeout; the outcome must still be saved.
+	ctx = context.WithoutCancel(ctx)
+	if err := t.runs.Finish(ctx, t.runID, status, t.snapshot(), errMsg, time.Now(), elapsed.Milliseconds()); err != nil {
+		slog.Error("failed to finish sync run", "run_id", t.runID, "error", err)
+	}
+}
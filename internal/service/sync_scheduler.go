@@ -8,16 +8,17 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/onnwee/pulse-score/internal/integration"
 	"github.com/onnwee/pulse-score/internal/repository"
 )
 
 // SyncSchedulerService runs periodic incremental syncs for all active connections.
 type SyncSchedulerService struct {
-	connRepo              *repository.IntegrationConnectionRepository
-	orchestrator          *SyncOrchestratorService
-	hubspotOrchestrator   *HubSpotSyncOrchestratorService
-	intercomOrchestrator  *IntercomSyncOrchestratorService
-	interval              time.Duration
+	connRepo    *repository.IntegrationConnectionRepository
+	runs        *repository.SyncRunRepository
+	checkpoints *repository.SyncCheckpointRepository
+	providers   *integration.Registry
+	interval    time.Duration
 
 	// Per-connection lock to prevent overlapping syncs
 	locks map[uuid.UUID]*sync.Mutex
@@ -27,18 +28,18 @@ type SyncSchedulerService struct {
 // NewSyncSchedulerService creates a new SyncSchedulerService.
 func NewSyncSchedulerService(
 	connRepo *repository.IntegrationConnectionRepository,
-	orchestrator *SyncOrchestratorService,
-	hubspotOrchestrator *HubSpotSyncOrchestratorService,
-	intercomOrchestrator *IntercomSyncOrchestratorService,
+	runs *repository.SyncRunRepository,
+	checkpoints *repository.SyncCheckpointRepository,
+	providers *integration.Registry,
 	intervalMinutes int,
 ) *SyncSchedulerService {
 	return &SyncSchedulerService{
-		connRepo:             connRepo,
-		orchestrator:         orchestrator,
-		hubspotOrchestrator:  hubspotOrchestrator,
-		intercomOrchestrator: intercomOrchestrator,
-		interval:             time.Duration(intervalMinutes) * time.Minute,
-		locks:                make(map[uuid.UUID]*sync.Mutex),
+		connRepo:    connRepo,
+		runs:        runs,
+		checkpoints: checkpoints,
+		providers:   providers,
+		interval:    time.Duration(intervalMinutes) * time.Minute,
+		locks:       make(map[uuid.UUID]*sync.Mutex),
 	}
 }
 
@@ -61,97 +62,64 @@ func (s *SyncSchedulerService) Start(ctx context.Context) {
 }
 
 func (s *SyncSchedulerService) runCycle(ctx context.Context) {
-	// Stripe connections
-	conns, err := s.connRepo.ListActiveByProvider(ctx, "stripe")
-	if err != nil {
-		slog.Error("scheduler: failed to list stripe connections", "error", err)
-	} else {
-		for _, conn := range conns {
-			lock := s.getLock(conn.OrgID)
-			if !lock.TryLock() {
-				slog.Debug("scheduler: skipping org (sync in progress)", "org_id", conn.OrgID)
-				continue
-			}
+	// Runs left running by a process that exited never finish on their own
+	if failed, err := s.runs.FailStale(ctx, time.Now().Add(-syncRunStaleAfter)); err != nil {
+		slog.Error("scheduler: failed to close stale sync runs", "error", err)
+	} else if failed > 0 {
+		slog.Warn("scheduler: marked interrupted sync runs as failed", "count", failed)
+	}
 
-			go func(orgID uuid.UUID, lastSync *time.Time) {
-				defer lock.Unlock()
+	for _, p := range s.providers.All() {
+		s.scheduleProvider(ctx, p)
+	}
+}
 
-				syncCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-				defer cancel()
+func (s *SyncSchedulerService) scheduleProvider(ctx context.Context, p integration.Provider) {
+	provider := p.Name()
+	conns, err := s.connRepo.ListActiveByProvider(ctx, provider)
+	if err != nil {
+		slog.Error("scheduler: failed to list connections", "provider", provider, "error", err)
+		return
+	}
 
-				if lastSync != nil {
-					s.orchestrator.RunIncrementalSync(syncCtx, orgID, *lastSync)
-				} else {
-					s.orchestrator.RunFullSync(syncCtx, orgID)
-				}
-			}(conn.OrgID, conn.LastSyncAt)
+	for _, conn := range conns {
+		lock := s.getLock(conn.ID)
+		if !lock.TryLock() {
+			slog.Debug("scheduler: skipping connection (sync in progress)", "org_id", conn.OrgID, "provider", provider)
+			continue
 		}
-	}
 
-	// HubSpot connections
-	if s.hubspotOrchestrator != nil {
-		hsConns, err := s.connRepo.ListActiveByProvider(ctx, "hubspot")
-		if err != nil {
-			slog.Error("scheduler: failed to list hubspot connections", "error", err)
-		} else {
-			for _, conn := range hsConns {
-				lock := s.getLock(conn.OrgID)
-				if !lock.TryLock() {
-					slog.Debug("scheduler: skipping hubspot org (sync in progress)", "org_id", conn.OrgID)
-					continue
-				}
-
-				go func(orgID uuid.UUID, lastSync *time.Time) {
-					defer lock.Unlock()
-
-					syncCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-					defer cancel()
-
-					if lastSync != nil {
-						s.hubspotOrchestrator.RunIncrementalSync(syncCtx, orgID, *lastSync)
-					} else {
-						s.hubspotOrchestrator.RunFullSync(syncCtx, orgID)
-					}
-				}(conn.OrgID, conn.LastSyncAt)
+		go func(orgID uuid.UUID, lastSync *time.Time) {
+			defer lock.Unlock()
+
+			syncCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+			defer cancel()
+
+			// A full sync that paused or was interrupted resumes from its
+			// checkpoints, which include the steps it already finished
+			if lastSync != nil && !s.hasCheckpoint(syncCtx, orgID, provider) {
+				p.RunIncrementalSync(syncCtx, orgID, *lastSync, SyncTriggerScheduler)
+			} else {
+				p.RunFullSync(syncCtx, orgID, SyncTriggerScheduler)
 			}
-		}
+		}(conn.OrgID, conn.LastSyncAt)
 	}
+}
 
-	// Intercom connections
-	if s.intercomOrchestrator != nil {
-		icConns, err := s.connRepo.ListActiveByProvider(ctx, "intercom")
-		if err != nil {
-			slog.Error("scheduler: failed to list intercom connections", "error", err)
-		} else {
-			for _, conn := range icConns {
-				lock := s.getLock(conn.OrgID)
-				if !lock.TryLock() {
-					slog.Debug("scheduler: skipping intercom org (sync in progress)", "org_id", conn.OrgID)
-					continue
-				}
-
-				go func(orgID uuid.UUID, lastSync *time.Time) {
-					defer lock.Unlock()
-
-					syncCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-					defer cancel()
-
-					if lastSync != nil {
-						s.intercomOrchestrator.RunIncrementalSync(syncCtx, orgID, *lastSync)
-					} else {
-						s.intercomOrchestrator.RunFullSync(syncCtx, orgID)
-					}
-				}(conn.OrgID, conn.LastSyncAt)
-			}
-		}
+func (s *SyncSchedulerService) hasCheckpoint(ctx context.Context, orgID uuid.UUID, provider string) bool {
+	exists, err := s.checkpoints.ExistsForProvider(ctx, orgID, provider)
+	if err != nil {
+		slog.Error("scheduler: failed to check sync checkpoints", "org_id", orgID, "provider", provider, "error", err)
+		return false
 	}
+	return exists
 }
 
-func (s *SyncSchedulerService) getLock(orgID uuid.UUID) *sync.Mutex {
+func (s *SyncSchedulerService) getLock(connID uuid.UUID) *sync.Mutex {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.locks[orgID]; !ok {
-		s.locks[orgID] = &sync.Mutex{}
+	if _, ok := s.locks[connID]; !ok {
+		s.locks[connID] = &sync.Mutex{}
 	}
-	return s.locks[orgID]
+	return s.locks[connID]
 }
@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	maxTaskTitleLength = 255
+	// defaultAlertTaskDueDays is when automated tasks are due if their rule or
+	// playbook step does not say.
+	defaultAlertTaskDueDays = 3
+	// overdueReminderBatch caps how many overdue tasks are reminded per run.
+	overdueReminderBatch = 200
+)
+
+var validTaskStatuses = map[string]bool{
+	repository.TaskStatusOpen:       true,
+	repository.TaskStatusInProgress: true,
+	repository.TaskStatusDone:       true,
+	repository.TaskStatusCancelled:  true,
+}
+
+// TaskService handles follow-up tasks for org members, created by hand or
+// raised by alert rules, and reminds assignees of overdue tasks.
+type TaskService struct {
+	tasks        *repository.TaskRepository
+	customers    *repository.CustomerRepository
+	alertHistory *repository.AlertHistoryRepository
+	orgRepo      *repository.OrganizationRepository
+	owners       *repository.CustomerOwnerRepository
+	notifSvc     *NotificationService
+	interval     time.Duration
+	repeatAfter  time.Duration
+}
+
+// NewTaskService creates a new TaskService. Overdue reminders are checked
+// every reminderIntervalMin minutes and repeated every reminderRepeatHr hours
+// while a task stays overdue.
+func NewTaskService(
+	tasks *repository.TaskRepository,
+	customers *repository.CustomerRepository,
+	alertHistory *repository.AlertHistoryRepository,
+	orgRepo *repository.OrganizationRepository,
+	owners *repository.CustomerOwnerRepository,
+	notifSvc *NotificationService,
+	reminderIntervalMin, reminderRepeatHr int,
+) *TaskService {
+	return &TaskService{
+		tasks:        tasks,
+		customers:    customers,
+		alertHistory: alertHistory,
+		orgRepo:      orgRepo,
+		owners:       owners,
+		notifSvc:     notifSvc,
+		interval:     time.Duration(reminderIntervalMin) * time.Minute,
+		repeatAfter:  time.Duration(reminderRepeatHr) * time.Hour,
+	}
+}
+
+// CreateTaskRequest holds input for creating a task. A task linked to an
+// alert without a customer takes the alert's customer.
+type CreateTaskRequest struct {
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	DueAt          *time.Time `json:"due_at"`
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	CustomerID     *uuid.UUID `json:"customer_id"`
+	AlertHistoryID *uuid.UUID `json:"alert_history_id"`
+}
+
+// UpdateTaskRequest holds input for updating a task. ClearDueAt removes the
+// due date and Unassign removes the assignee.
+type UpdateTaskRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Status      *string    `json:"status"`
+	DueAt       *time.Time `json:"due_at"`
+	ClearDueAt  bool       `json:"clear_due_at"`
+	AssigneeID  *uuid.UUID `json:"assignee_id"`
+	Unassign    bool       `json:"unassign"`
+}
+
+// TaskListResponse is the JSON response for the task list.
+type TaskListResponse struct {
+	Tasks      []*repository.Task `json:"tasks"`
+	Pagination PaginationMeta     `json:"pagination"`
+}
+
+// List returns a filtered page of an org's tasks.
+func (s *TaskService) List(ctx context.Context, params repository.TaskListParams) (*TaskListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PerPage < 1 {
+		params.PerPage = 25
+	}
+	if params.PerPage > 100 {
+		params.PerPage = 100
+	}
+
+	for _, status := range params.Statuses {
+		if !validTaskStatuses[status] {
+			return nil, &ValidationError{Field: "status", Message: "status must be open, in_progress, done or cancelled"}
+		}
+	}
+	if params.Sort != "" && params.Sort != "due" && params.Sort != "created" && params.Sort != "updated" && params.Sort != "title" {
+		return nil, &ValidationError{Field: "sort", Message: "sort must be due, created, updated or title"}
+	}
+	if params.Order != "" && params.Order != "asc" && params.Order != "desc" {
+		return nil, &ValidationError{Field: "order", Message: "order must be asc or desc"}
+	}
+
+	result, err := s.tasks.List(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+
+	tasks := result.Tasks
+	if tasks == nil {
+		tasks = []*repository.Task{}
+	}
+
+	return &TaskListResponse{
+		Tasks: tasks,
+		Pagination: PaginationMeta{
+			Page:       result.Page,
+			PerPage:    result.PerPage,
+			Total:      result.Total,
+			TotalPages: result.TotalPages,
+		},
+	}, nil
+}
+
+// Get returns a task.
+func (s *TaskService) Get(ctx context.Context, id, orgID uuid.UUID) (*repository.Task, error) {
+	task, err := s.tasks.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, &NotFoundError{Resource: "task", Message: "task not found"}
+	}
+	return task, nil
+}
+
+// Create adds a task and notifies its assignee.
+func (s *TaskService) Create(ctx context.Context, orgID, userID uuid.UUID, req CreateTaskRequest) (*repository.Task, error) {
+	title, err := validateTaskTitle(req.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	customerID := req.CustomerID
+	if req.AlertHistoryID != nil {
+		history, err := s.alertHistory.GetByID(ctx, *req.AlertHistoryID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		if history == nil {
+			return nil, &ValidationError{Field: "alert_history_id", Message: "alert not found"}
+		}
+		if customerID == nil {
+			customerID = history.CustomerID
+		}
+	}
+	if customerID != nil {
+		customer, err := s.customers.GetByIDAndOrg(ctx, *customerID, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("get customer: %w", err)
+		}
+		if customer == nil {
+			return nil, &ValidationError{Field: "customer_id", Message: "customer not found"}
+		}
+	}
+	if req.AssigneeID != nil {
+		if err := s.validateAssignee(ctx, orgID, *req.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	task := &repository.Task{
+		OrgID:          orgID,
+		Title:          title,
+		Description:    strings.TrimSpace(req.Description),
+		Status:         repository.TaskStatusOpen,
+		DueAt:          req.DueAt,
+		AssigneeID:     req.AssigneeID,
+		CustomerID:     customerID,
+		AlertHistoryID: req.AlertHistoryID,
+		CreatedBy:      &userID,
+	}
+	created, err := s.create(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	if created.AssigneeID != nil && *created.AssigneeID != userID {
+		s.notify(ctx, created, NotificationTaskAssigned)
+	}
+	return created, nil
+}
+
+// Update edits a task. Completing a task records when it was done; moving it
+// to another member notifies the new assignee.
+func (s *TaskService) Update(ctx context.Context, id, orgID, userID uuid.UUID, req UpdateTaskRequest) (*repository.Task, error) {
+	task, err := s.Get(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	previousAssignee := task.AssigneeID
+
+	if req.Title != nil {
+		if task.Title, err = validateTaskTitle(*req.Title); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		task.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Status != nil && *req.Status != task.Status {
+		if !validTaskStatuses[*req.Status] {
+			return nil, &ValidationError{Field: "status", Message: "status must be open, in_progress, done or cancelled"}
+		}
+		task.Status = *req.Status
+		task.CompletedAt = nil
+		if task.Status == repository.TaskStatusDone {
+			now := time.Now()
+			task.CompletedAt = &now
+		}
+	}
+	switch {
+	case req.ClearDueAt:
+		task.DueAt = nil
+	case req.DueAt != nil:
+		task.DueAt = req.DueAt
+	}
+	switch {
+	case req.Unassign:
+		task.AssigneeID = nil
+	case req.AssigneeID != nil:
+		if err := s.validateAssignee(ctx, orgID, *req.AssigneeID); err != nil {
+			return nil, err
+		}
+		task.AssigneeID = req.AssigneeID
+	}
+
+	if err := s.tasks.Update(ctx, task); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.Get(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated.AssigneeID != nil && *updated.AssigneeID != userID &&
+		(previousAssignee == nil || *previousAssignee != *updated.AssigneeID) {
+		s.notify(ctx, updated, NotificationTaskAssigned)
+	}
+	return updated, nil
+}
+
+// Delete removes a task. Creators may delete their own tasks; admins and
+// owners may delete any task.
+func (s *TaskService) Delete(ctx context.Context, id, orgID, userID uuid.UUID) error {
+	task, err := s.Get(ctx, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	if task.CreatedBy == nil || *task.CreatedBy != userID {
+		role, err := s.orgRepo.GetMemberRole(ctx, orgID, userID)
+		if err != nil {
+			return fmt.Errorf("get member role: %w", err)
+		}
+		if role != "admin" && role != "owner" {
+			return &ForbiddenError{Message: "only the creator or an admin can delete a task"}
+		}
+	}
+
+	return s.tasks.Delete(ctx, id, orgID)
+}
+
+// CreateForAlert creates the follow-up task configured on the rule of an
+// alert match, linked to the alert and its customer. Customer alerts go to
+// the customer's first owner when the rule asks for it.
+func (s *TaskService) CreateForAlert(ctx context.Context, match AlertMatch, alertHistoryID uuid.UUID) (*repository.Task, error) {
+	cfg := match.Rule.Task
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	title := strings.TrimSpace(cfg.Title)
+	if title == "" {
+		title = fmt.Sprintf("Follow up: %s", match.Rule.Name)
+	}
+
+	task := AutomatedTask{
+		OrgID:          match.Rule.OrgID,
+		Title:          fmt.Sprintf("%s (%s)", title, match.subjectName()),
+		Description:    s.notifSvc.buildMessage(match),
+		DueInDays:      cfg.DueInDays,
+		AssigneeID:     cfg.AssigneeID,
+		AssignToOwner:  cfg.AssignToOwner,
+		AlertHistoryID: &alertHistoryID,
+		CreatedBy:      match.Rule.CreatedBy,
+	}
+	if match.Customer != nil {
+		task.CustomerID = &match.Customer.ID
+	}
+	return s.CreateAutomated(ctx, task)
+}
+
+// AutomatedTask describes a task raised by an alert rule or a playbook.
+type AutomatedTask struct {
+	OrgID          uuid.UUID
+	Title          string
+	Description    string
+	DueInDays      int
+	AssigneeID     *uuid.UUID
+	AssignToOwner  bool
+	CustomerID     *uuid.UUID
+	AlertHistoryID *uuid.UUID
+	CreatedBy      *uuid.UUID
+}
+
+// CreateAutomated creates a task on behalf of an automation and notifies its
+// assignee. With AssignToOwner the customer's first owner is assigned, falling
+// back to AssigneeID; an assignee who has left the org is dropped.
+func (s *TaskService) CreateAutomated(ctx context.Context, t AutomatedTask) (*repository.Task, error) {
+	title := strings.TrimSpace(t.Title)
+	if utf8.RuneCountInString(title) > maxTaskTitleLength {
+		title = string([]rune(title)[:maxTaskTitleLength])
+	}
+
+	dueDays := t.DueInDays
+	if dueDays <= 0 {
+		dueDays = defaultAlertTaskDueDays
+	}
+	due := time.Now().AddDate(0, 0, dueDays)
+
+	task := &repository.Task{
+		OrgID:          t.OrgID,
+		Title:          title,
+		Description:    t.Description,
+		Status:         repository.TaskStatusOpen,
+		DueAt:          &due,
+		AssigneeID:     t.AssigneeID,
+		CustomerID:     t.CustomerID,
+		AlertHistoryID: t.AlertHistoryID,
+		CreatedBy:      t.CreatedBy,
+	}
+	if t.CustomerID != nil && t.AssignToOwner && s.owners != nil {
+		owners, err := s.owners.ListByCustomer(ctx, *t.CustomerID, t.OrgID)
+		if err != nil {
+			slog.Error("task: resolve customer owner", "customer_id", *t.CustomerID, "error", err)
+		} else if len(owners) > 0 {
+			task.AssigneeID = &owners[0].UserID
+		}
+	}
+	if task.AssigneeID != nil && s.validateAssignee(ctx, task.OrgID, *task.AssigneeID) != nil {
+		task.AssigneeID = nil
+	}
+
+	created, err := s.create(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, created, NotificationTaskAssigned)
+	return created, nil
+}
+
+// Start begins the periodic overdue reminder loop. Cancel the context to stop.
+func (s *TaskService) Start(ctx context.Context) {
+	slog.Info("task reminders started", "interval", s.interval, "repeat", s.repeatAfter)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("task reminders stopped")
+			return
+		case <-ticker.C:
+			s.RemindOverdue(ctx)
+		}
+	}
+}
+
+// RemindOverdue notifies the assignees of overdue tasks that have not been
+// reminded within the repeat window.
+func (s *TaskService) RemindOverdue(ctx context.Context) {
+	for ctx.Err() == nil {
+		tasks, err := s.tasks.ClaimOverdue(ctx, s.repeatAfter, overdueReminderBatch)
+		if err != nil {
+			slog.Error("task reminders: claim overdue", "error", err)
+			return
+		}
+
+		for _, task := range tasks {
+			s.notify(ctx, task, NotificationTaskOverdue)
+		}
+		if len(tasks) > 0 {
+			slog.Info("task reminders: reminded overdue tasks", "count", len(tasks))
+		}
+		if len(tasks) < overdueReminderBatch {
+			return
+		}
+	}
+}
+
+func (s *TaskService) create(ctx context.Context, task *repository.Task) (*repository.Task, error) {
+	if err := s.tasks.Create(ctx, task); err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+
+	created, err := s.tasks.GetByID(ctx, task.ID, task.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, fmt.Errorf("created task not found")
+	}
+	return created, nil
+}
+
+func (s *TaskService) validateAssignee(ctx context.Context, orgID, userID uuid.UUID) error {
+	ok, err := s.orgRepo.IsMember(ctx, userID, orgID)
+	if err != nil {
+		return fmt.Errorf("check membership: %w", err)
+	}
+	if !ok {
+		return &ValidationError{Field: "assignee_id", Message: "assignee is not a member of this organization"}
+	}
+	return nil
+}
+
+func (s *TaskService) notify(ctx context.Context, task *repository.Task, notifType string) {
+	if s.notifSvc != nil {
+		s.notifSvc.CreateForTask(ctx, task, notifType)
+	}
+}
+
+func validateTaskTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", &ValidationError{Field: "title", Message: "title is required"}
+	}
+	if utf8.RuneCountInString(title) > maxTaskTitleLength {
+		return "", &ValidationError{Field: "title", Message: fmt.Sprintf("title must be at most %d characters", maxTaskTitleLength)}
+	}
+	return title, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/onnwee/pulse-score/internal/keyring"
+)
+
+// TokenCipher encrypts integration tokens at rest. With a keyring, tokens are
+// envelope-encrypted under the keyring's primary key and carry its key ID.
+// Tokens written before the keyring existed are still read with the
+// provider's legacy key until they are rotated.
+type TokenCipher struct {
+	keys      *keyring.Keyring
+	legacyKey string
+}
+
+// NewTokenCipher creates a TokenCipher. keys may be nil, in which case tokens
+// are written in the legacy single-key format.
+func NewTokenCipher(keys *keyring.Keyring, legacyKeyHex string) *TokenCipher {
+	return &TokenCipher{keys: keys, legacyKey: legacyKeyHex}
+}
+
+// LoadTokenKeyring parses the configured token keyring. It returns nil when no
+// keys are configured, which keeps tokens in the legacy format.
+func LoadTokenKeyring(keys []string, primary string) (*keyring.Keyring, error) {
+	if len(keys) == 0 {
+		if primary != "" {
+			return nil, fmt.Errorf("primary token key %q set without any keys", primary)
+		}
+		return nil, nil
+	}
+	return keyring.Parse(keys, primary)
+}
+
+// Encrypt encrypts a token for storage.
+func (c *TokenCipher) Encrypt(plaintext string) ([]byte, error) {
+	if c.keys == nil {
+		return encryptToken(plaintext, c.legacyKey)
+	}
+	return c.keys.Encrypt([]byte(plaintext))
+}
+
+// Decrypt decrypts a stored token in either format.
+func (c *TokenCipher) Decrypt(ciphertext []byte) (string, error) {
+	if keyring.IsEnvelope(ciphertext) {
+		if c.keys == nil {
+			return "", fmt.Errorf("token is envelope-encrypted but no keyring is configured")
+		}
+		plaintext, err := c.keys.Decrypt(ciphertext)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	}
+	return decryptToken(ciphertext, c.legacyKey)
+}
+
+// Rotate returns ciphertext re-encrypted under the primary key and whether it
+// changed. Envelopes only have their data key re-wrapped; legacy tokens are
+// decrypted and sealed in a new envelope.
+func (c *TokenCipher) Rotate(ciphertext []byte) ([]byte, bool, error) {
+	if c.keys == nil || len(ciphertext) == 0 {
+		return ciphertext, false, nil
+	}
+
+	if keyring.IsEnvelope(ciphertext) {
+		if id, _ := keyring.KeyID(ciphertext); id == c.keys.Primary() {
+			return ciphertext, false, nil
+		}
+		out, err := c.keys.Rewrap(ciphertext)
+		if err != nil {
+			return nil, false, err
+		}
+		return out, true, nil
+	}
+
+	plaintext, err := decryptToken(ciphertext, c.legacyKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("decrypt legacy token: %w", err)
+	}
+	out, err := c.keys.Encrypt([]byte(plaintext))
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// encryptToken encrypts a token string using AES-GCM with a single static key.
+// It is the format used before the keyring; new tokens only use it when no
+// keyring is configured.
+func encryptToken(plaintext, keyHex string) ([]byte, error) {
+	if keyHex == "" {
+		// In dev mode, just store plaintext as bytes (not for production)
+		return []byte(plaintext), nil
+	}
+
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+// decryptToken decrypts a token written by encryptToken.
+func decryptToken(ciphertext []byte, keyHex string) (string, error) {
+	if keyHex == "" {
+		// In dev mode, just return plaintext
+		return string(ciphertext), nil
+	}
+
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return "", fmt.Errorf("decode encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("create gcm: %w", err)
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, encrypted := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, encrypted, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// tokenRefreshLeadTime is how long before expiry an access token is renewed.
+// It is wider than the refresh-on-use window in GetAccessToken so syncs rarely
+// have to refresh inline.
+const tokenRefreshLeadTime = 15 * time.Minute
+
+type expiringTokenStore interface {
+	ListExpiringTokens(ctx context.Context, provider string, before time.Time) ([]*repository.IntegrationConnection, error)
+	MarkNeedsReauth(ctx context.Context, orgID uuid.UUID, provider, reason string) (bool, error)
+}
+
+type orgMemberReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*repository.Organization, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]repository.OrgMember, error)
+}
+
+type notificationCreator interface {
+	Create(ctx context.Context, n *repository.Notification) error
+}
+
+type notificationPreferenceReader interface {
+	Get(ctx context.Context, userID, orgID uuid.UUID) (*repository.NotificationPreference, error)
+}
+
+// TokenLifecycleService renews OAuth access tokens before they expire and,
+// when a provider revokes a grant, parks the connection in needs_reauth and
+// asks the org's admins to reconnect.
+type TokenLifecycleService struct {
+	connRepo     expiringTokenStore
+	providers    *integration.Registry
+	orgs         orgMemberReader
+	notifRepo    notificationCreator
+	prefs        notificationPreferenceReader
+	emailService EmailService
+	templates    *EmailTemplateService
+	interval     time.Duration
+	frontendURL  string
+}
+
+// TokenLifecycleDeps holds constructor dependencies for TokenLifecycleService.
+type TokenLifecycleDeps struct {
+	Connections   *repository.IntegrationConnectionRepository
+	Providers     *integration.Registry
+	Orgs          *repository.OrganizationRepository
+	Notifications *repository.NotificationRepository
+	Prefs         *NotificationPreferenceService
+	EmailService  EmailService
+	Templates     *EmailTemplateService
+}
+
+// NewTokenLifecycleService creates a new TokenLifecycleService.
+func NewTokenLifecycleService(deps TokenLifecycleDeps, intervalMinutes int, frontendURL string) *TokenLifecycleService {
+	return &TokenLifecycleService{
+		connRepo:     deps.Connections,
+		providers:    deps.Providers,
+		orgs:         deps.Orgs,
+		notifRepo:    deps.Notifications,
+		prefs:        deps.Prefs,
+		emailService: deps.EmailService,
+		templates:    deps.Templates,
+		interval:     time.Duration(intervalMinutes) * time.Minute,
+		frontendURL:  frontendURL,
+	}
+}
+
+// Start begins the periodic token refresh loop. Cancel the context to stop.
+func (s *TokenLifecycleService) Start(ctx context.Context) {
+	slog.Info("token lifecycle started", "interval", s.interval, "lead_time", tokenRefreshLeadTime)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("token lifecycle stopped")
+			return
+		case <-ticker.C:
+			s.refreshExpiring(ctx)
+		}
+	}
+}
+
+func (s *TokenLifecycleService) refreshExpiring(ctx context.Context) {
+	before := time.Now().Add(tokenRefreshLeadTime)
+
+	for _, p := range s.providers.All() {
+		conns, err := s.connRepo.ListExpiringTokens(ctx, p.Name(), before)
+		if err != nil {
+			slog.Error("token lifecycle: failed to list expiring tokens", "provider", p.Name(), "error", err)
+			continue
+		}
+
+		for _, conn := range conns {
+			err := p.RefreshToken(ctx, conn.OrgID)
+			if errors.Is(err, integration.ErrReauthRequired) {
+				if err := s.RequireReauth(ctx, p, conn.OrgID, err); err != nil {
+					slog.Error("token lifecycle: failed to flag connection", "provider", p.Name(), "org_id", conn.OrgID, "error", err)
+				}
+				continue
+			}
+			if err != nil {
+				// Transient failures are retried on the next tick; the token is
+				// still refreshed on use if it runs out in between.
+				slog.Warn("token lifecycle: refresh failed",
+					"provider", p.Name(),
+					"org_id", conn.OrgID,
+					"expires_at", conn.TokenExpiresAt,
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// RequireReauth moves an org's connection to needs_reauth and notifies the
+// org's owners and admins with a reconnect link. Admins are only notified the
+// first time; a connection already waiting for reauthorization is left alone.
+func (s *TokenLifecycleService) RequireReauth(ctx context.Context, p integration.Provider, orgID uuid.UUID, cause error) error {
+	reason := cause.Error()
+	marked, err := s.connRepo.MarkNeedsReauth(ctx, orgID, p.Name(), reason)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return nil
+	}
+
+	slog.Warn("integration requires reauthorization", "provider", p.Name(), "org_id", orgID, "reason", reason)
+
+	reconnectURL, err := p.ConnectURL(orgID)
+	if err != nil {
+		reconnectURL = fmt.Sprintf("%s/settings/integrations", s.frontendURL)
+	}
+
+	members, err := s.orgs.ListMembers(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("list org members: %w", err)
+	}
+
+	orgName := ""
+	if org, err := s.orgs.GetByID(ctx, orgID); err == nil && org != nil {
+		orgName = org.Name
+	}
+
+	for _, m := range members {
+		if m.Role != "owner" && m.Role != "admin" {
+			continue
+		}
+		s.notifyMember(ctx, m, orgID, orgName, p, reason, reconnectURL)
+	}
+	return nil
+}
+
+func (s *TokenLifecycleService) notifyMember(
+	ctx context.Context,
+	m repository.OrgMember,
+	orgID uuid.UUID,
+	orgName string,
+	p integration.Provider,
+	reason, reconnectURL string,
+) {
+	pref, prefErr := s.prefs.Get(ctx, m.UserID, orgID)
+
+	if prefErr != nil || pref.InAppEnabled {
+		notif := &repository.Notification{
+			UserID:  m.UserID,
+			OrgID:   orgID,
+			Type:    "integration_reauth",
+			Title:   fmt.Sprintf("Reconnect %s", p.DisplayName()),
+			Message: fmt.Sprintf("%s revoked access. Syncing is paused until the integration is reconnected.", p.DisplayName()),
+			Data: map[string]any{
+				"provider":      p.Name(),
+				"reconnect_url": reconnectURL,
+			},
+		}
+		if err := s.notifRepo.Create(ctx, notif); err != nil {
+			slog.Error("token lifecycle: create notification failed", "user_id", m.UserID, "error", err)
+		}
+	}
+
+	if prefErr == nil && !pref.EmailEnabled {
+		return
+	}
+
+	htmlBody, textBody, err := s.templates.RenderIntegrationReauth(IntegrationReauthEmailData{
+		OrgName:        orgName,
+		ProviderName:   p.DisplayName(),
+		Reason:         reason,
+		ReconnectURL:   reconnectURL,
+		UnsubscribeURL: fmt.Sprintf("%s/settings?tab=notifications", s.frontendURL),
+	})
+	if err != nil {
+		slog.Error("token lifecycle: render email failed", "provider", p.Name(), "error", err)
+		return
+	}
+
+	if _, err := s.emailService.SendEmail(ctx, SendEmailParams{
+		To:       m.Email,
+		Subject:  fmt.Sprintf("Action required: reconnect %s to PulseScore", p.DisplayName()),
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	}); err != nil {
+		slog.Error("token lifecycle: send email failed", "recipient", m.Email, "error", err)
+	}
+}
+
+// reauthOnUnauthorized marks a provider API 401 as integration.ErrReauthRequired.
+func reauthOnUnauthorized(err error) error {
+	var apiErr *ProviderAPIError
+	if errors.As(err, &apiErr) && apiErr.Unauthorized() {
+		return fmt.Errorf("%w: %v", integration.ErrReauthRequired, err)
+	}
+	return err
+}
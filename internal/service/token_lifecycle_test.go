@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/integration"
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+type fakeExpiringTokenStore struct {
+	needsReauth map[string]bool
+}
+
+func (f *fakeExpiringTokenStore) ListExpiringTokens(ctx context.Context, provider string, before time.Time) ([]*repository.IntegrationConnection, error) {
+	return nil, nil
+}
+
+func (f *fakeExpiringTokenStore) MarkNeedsReauth(ctx context.Context, orgID uuid.UUID, provider, reason string) (bool, error) {
+	if f.needsReauth[provider] {
+		return false, nil
+	}
+	f.needsReauth[provider] = true
+	return true, nil
+}
+
+type fakeOrgMemberReader struct {
+	members []repository.OrgMember
+}
+
+func (f *fakeOrgMemberReader) GetByID(ctx context.Context, id uuid.UUID) (*repository.Organization, error) {
+	return &repository.Organization{ID: id, Name: "Acme"}, nil
+}
+
+func (f *fakeOrgMemberReader) ListMembers(ctx context.Context, orgID uuid.UUID) ([]repository.OrgMember, error) {
+	return f.members, nil
+}
+
+type fakeNotificationCreator struct {
+	created []*repository.Notification
+}
+
+func (f *fakeNotificationCreator) Create(ctx context.Context, n *repository.Notification) error {
+	f.created = append(f.created, n)
+	return nil
+}
+
+type fakeNotificationPreferenceReader struct{}
+
+func (fakeNotificationPreferenceReader) Get(ctx context.Context, userID, orgID uuid.UUID) (*repository.NotificationPreference, error) {
+	return &repository.NotificationPreference{UserID: userID, OrgID: orgID, EmailEnabled: true, InAppEnabled: true}, nil
+}
+
+type fakeEmailService struct {
+	sent []SendEmailParams
+	err  error
+}
+
+func (f *fakeEmailService) SendInvitation(ctx context.Context, params SendInvitationParams) error {
+	return nil
+}
+
+func (f *fakeEmailService) SendPasswordReset(ctx context.Context, params SendPasswordResetParams) error {
+	return nil
+}
+
+func (f *fakeEmailService) SendEmail(ctx context.Context, params SendEmailParams) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.sent = append(f.sent, params)
+	return "msg", nil
+}
+
+// fakeReauthProvider implements the parts of integration.Provider that
+// RequireReauth uses.
+type fakeReauthProvider struct {
+	integration.Provider
+}
+
+func (fakeReauthProvider) Name() string        { return "hubspot" }
+func (fakeReauthProvider) DisplayName() string { return "HubSpot" }
+
+func (fakeReauthProvider) ConnectURL(orgID uuid.UUID) (string, error) {
+	return "https://app.hubspot.com/oauth/authorize?state=" + orgID.String(), nil
+}
+
+func TestRequireReauth_NotifiesAdminsOnce(t *testing.T) {
+	templates, err := NewEmailTemplateService()
+	if err != nil {
+		t.Fatalf("load email templates: %v", err)
+	}
+	notifs := &fakeNotificationCreator{}
+	emails := &fakeEmailService{}
+	svc := &TokenLifecycleService{
+		connRepo: &fakeExpiringTokenStore{needsReauth: map[string]bool{}},
+		orgs: &fakeOrgMemberReader{members: []repository.OrgMember{
+			{UserID: uuid.New(), Email: "owner@acme.com", Role: "owner"},
+			{UserID: uuid.New(), Email: "admin@acme.com", Role: "admin"},
+			{UserID: uuid.New(), Email: "member@acme.com", Role: "member"},
+		}},
+		notifRepo:    notifs,
+		prefs:        fakeNotificationPreferenceReader{},
+		emailService: emails,
+		templates:    templates,
+	}
+
+	orgID := uuid.New()
+	cause := errors.New("integration requires reauthorization: refresh token revoked")
+	for range 3 {
+		if err := svc.RequireReauth(context.Background(), fakeReauthProvider{}, orgID, cause); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(notifs.created) != 2 || len(emails.sent) != 2 {
+		t.Fatalf("expected the owner and admin to be notified once, got %d notifications and %d emails", len(notifs.created), len(emails.sent))
+	}
+	for _, e := range emails.sent {
+		if e.To == "member@acme.com" {
+			t.Error("expected members not to be emailed")
+		}
+	}
+	if url, _ := notifs.created[0].Data["reconnect_url"].(string); url == "" {
+		t.Error("expected the notification to carry a reconnect link")
+	}
+}
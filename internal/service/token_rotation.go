@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+// TokenRotationService re-encrypts stored integration tokens under the
+// keyring's primary key. It walks integration_connections in batches and
+// swaps each row's tokens only if they are unchanged, so it can run while the
+// API is serving traffic.
+type TokenRotationService struct {
+	connRepo *repository.IntegrationConnectionRepository
+	ciphers  map[string]*TokenCipher // keyed by provider
+}
+
+// TokenRotationResult summarises a rotation run.
+type TokenRotationResult struct {
+	Scanned  int // connections with stored tokens
+	Rotated  int // connections re-encrypted under the primary key
+	Current  int // connections already on the primary key
+	Conflict int // connections whose tokens changed mid-rotation; already current
+	Failed   int // connections that could not be decrypted or updated
+}
+
+// NewTokenRotationService creates a new TokenRotationService.
+func NewTokenRotationService(connRepo *repository.IntegrationConnectionRepository, ciphers map[string]*TokenCipher) *TokenRotationService {
+	return &TokenRotationService{connRepo: connRepo, ciphers: ciphers}
+}
+
+// Rotate re-encrypts every connection's tokens in batches of batchSize,
+// sleeping pause between batches. With dryRun set it only counts the rows
+// that would change.
+func (s *TokenRotationService) Rotate(ctx context.Context, batchSize int, pause time.Duration, dryRun bool) (*TokenRotationResult, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	result := &TokenRotationResult{}
+	after := uuid.Nil
+
+	for {
+		conns, err := s.connRepo.ListTokensAfter(ctx, after, batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(conns) == 0 {
+			return result, nil
+		}
+
+		for _, conn := range conns {
+			result.Scanned++
+			s.rotateConnection(ctx, conn, dryRun, result)
+		}
+		after = conns[len(conns)-1].ID
+
+		slog.Info("token rotation: batch done",
+			"scanned", result.Scanned,
+			"rotated", result.Rotated,
+			"failed", result.Failed,
+		)
+
+		if len(conns) < batchSize {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(pause):
+		}
+	}
+}
+
+func (s *TokenRotationService) rotateConnection(ctx context.Context, conn *repository.IntegrationConnection, dryRun bool, result *TokenRotationResult) {
+	cipher, ok := s.ciphers[conn.Provider]
+	if !ok {
+		slog.Error("token rotation: no cipher for provider", "provider", conn.Provider, "connection_id", conn.ID)
+		result.Failed++
+		return
+	}
+
+	newAccess, accessChanged, err := cipher.Rotate(conn.AccessTokenEncrypted)
+	if err != nil {
+		slog.Error("token rotation: access token", "connection_id", conn.ID, "provider", conn.Provider, "error", err)
+		result.Failed++
+		return
+	}
+	newRefresh, refreshChanged, err := cipher.Rotate(conn.RefreshTokenEncrypted)
+	if err != nil {
+		slog.Error("token rotation: refresh token", "connection_id", conn.ID, "provider", conn.Provider, "error", err)
+		result.Failed++
+		return
+	}
+
+	if !accessChanged && !refreshChanged {
+		result.Current++
+		return
+	}
+	if dryRun {
+		result.Rotated++
+		return
+	}
+
+	swapped, err := s.connRepo.SwapTokens(ctx, conn.ID,
+		conn.AccessTokenEncrypted, conn.RefreshTokenEncrypted,
+		newAccess, newRefresh,
+	)
+	if err != nil {
+		slog.Error("token rotation: update", "connection_id", conn.ID, "provider", conn.Provider, "error", err)
+		result.Failed++
+		return
+	}
+	if !swapped {
+		// The tokens were refreshed or the connection removed since the batch
+		// was read; new tokens are written under the primary key already.
+		result.Conflict++
+		return
+	}
+	result.Rotated++
+}
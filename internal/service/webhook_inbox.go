@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/onnwee/pulse-score/internal/repository"
+)
+
+const (
+	webhookInboxPollInterval = 5 * time.Second
+	webhookInboxBatchSize    = 50
+	// webhookInboxStaleAfter is how long an event may stay in processing before
+	// another worker assumes its owner crashed and reclaims it.
+	webhookInboxStaleAfter = 10 * time.Minute
+	// webhookInboxMaxAttempts is how many times an event is tried before it is dead-lettered.
+	webhookInboxMaxAttempts = 8
+	webhookInboxBaseBackoff = 30 * time.Second
+	webhookInboxMaxBackoff  = time.Hour
+	webhookInboxRetention   = 7 * 24 * time.Hour
+)
+
+// WebhookProcessorFunc processes the stored payload of one inbound webhook event.
+type WebhookProcessorFunc func(ctx context.Context, payload []byte) error
+
+// WebhookInboxService persists verified inbound webhooks and processes them
+// asynchronously with retries. Events that keep failing are dead-lettered and
+// can be replayed by an admin.
+type WebhookInboxService struct {
+	inbox *repository.WebhookInboxRepository
+
+	mu         sync.RWMutex
+	processors map[string]WebhookProcessorFunc
+}
+
+// NewWebhookInboxService creates a new WebhookInboxService.
+func NewWebhookInboxService(inbox *repository.WebhookInboxRepository) *WebhookInboxService {
+	return &WebhookInboxService{
+		inbox:      inbox,
+		processors: make(map[string]WebhookProcessorFunc),
+	}
+}
+
+// RegisterProcessor sets the function that processes events from provider.
+func (s *WebhookInboxService) RegisterProcessor(provider string, fn WebhookProcessorFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processors[provider] = fn
+}
+
+// Enqueue stores a verified event for processing. Events the provider already
+// delivered are ignored. When the provider has no event ID, the payload hash is used.
+func (s *WebhookInboxService) Enqueue(ctx context.Context, provider, eventID, eventType string, orgID *uuid.UUID, payload []byte) error {
+	if eventID == "" {
+		sum := sha256.Sum256(payload)
+		eventID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	inserted, err := s.inbox.Insert(ctx, &repository.WebhookInboxEvent{
+		Provider:  provider,
+		EventID:   eventID,
+		EventType: eventType,
+		OrgID:     orgID,
+		Payload:   payload,
+	})
+	if err != nil {
+		return fmt.Errorf("enqueue %s webhook: %w", provider, err)
+	}
+	if !inserted {
+		slog.Debug("duplicate webhook event skipped", "provider", provider, "event_id", eventID)
+	}
+	return nil
+}
+
+// Start processes due events until the context is cancelled.
+func (s *WebhookInboxService) Start(ctx context.Context) {
+	slog.Info("webhook inbox worker started")
+
+	ticker := time.NewTicker(webhookInboxPollInterval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(time.Hour)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("webhook inbox worker stopped")
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		case <-pruneTicker.C:
+			s.prune(ctx)
+		}
+	}
+}
+
+// RunOnce claims and processes due events until none are left.
+func (s *WebhookInboxService) RunOnce(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := s.inbox.ClaimDue(ctx, time.Now(), webhookInboxStaleAfter, webhookInboxBatchSize)
+		if err != nil {
+			slog.Error("webhook inbox: claim events", "error", err)
+			return
+		}
+		for _, event := range events {
+			s.process(ctx, event)
+		}
+		if len(events) < webhookInboxBatchSize {
+			return
+		}
+	}
+}
+
+func (s *WebhookInboxService) process(ctx context.Context, event *repository.WebhookInboxEvent) {
+	s.mu.RLock()
+	fn, ok := s.processors[event.Provider]
+	s.mu.RUnlock()
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no processor registered for provider %q", event.Provider)
+	} else {
+		err = fn(ctx, event.Payload)
+	}
+
+	if err == nil {
+		if err := s.inbox.MarkProcessed(ctx, event.ID); err != nil {
+			slog.Error("webhook inbox: mark processed", "id", event.ID, "error", err)
+		}
+		return
+	}
+
+	status := "failed"
+	if event.Attempts >= webhookInboxMaxAttempts {
+		status = "dead"
+	}
+	slog.Error("webhook inbox: process event",
+		"provider", event.Provider,
+		"event_id", event.EventID,
+		"attempt", event.Attempts,
+		"status", status,
+		"error", err,
+	)
+	next := time.Now().Add(webhookInboxBackoff(event.Attempts))
+	if err := s.inbox.MarkFailed(ctx, event.ID, status, err.Error(), next); err != nil {
+		slog.Error("webhook inbox: mark failed", "id", event.ID, "error", err)
+	}
+}
+
+// webhookInboxBackoff returns the delay before retrying after the given attempt.
+func webhookInboxBackoff(attempt int) time.Duration {
+	d := webhookInboxBaseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= webhookInboxMaxBackoff {
+			return webhookInboxMaxBackoff
+		}
+	}
+	return d
+}
+
+func (s *WebhookInboxService) prune(ctx context.Context) {
+	deleted, err := s.inbox.DeleteProcessedBefore(ctx, time.Now().Add(-webhookInboxRetention))
+	if err != nil {
+		slog.Error("webhook inbox: prune events", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("webhook inbox: pruned processed events", "deleted", deleted)
+	}
+}
+
+// ListFailed returns an org's failed and dead-lettered events. status narrows
+// the result to "failed" or "dead"; empty returns both.
+func (s *WebhookInboxService) ListFailed(ctx context.Context, orgID uuid.UUID, status string, limit, offset int) ([]*repository.WebhookInboxEvent, int, error) {
+	statuses := []string{"failed", "dead"}
+	switch status {
+	case "":
+	case "failed", "dead":
+		statuses = []string{status}
+	default:
+		return nil, 0, &ValidationError{Field: "status", Message: "status must be failed or dead"}
+	}
+
+	events, total, err := s.inbox.ListByOrgAndStatus(ctx, orgID, statuses, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list webhook events: %w", err)
+	}
+	return events, total, nil
+}
+
+// Replay queues a failed or dead-lettered event for immediate reprocessing.
+func (s *WebhookInboxService) Replay(ctx context.Context, id, orgID uuid.UUID) (*repository.WebhookInboxEvent, error) {
+	event, err := s.inbox.Requeue(ctx, id, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("replay webhook event: %w", err)
+	}
+	if event == nil {
+		return nil, &NotFoundError{Resource: "webhook_event", Message: "failed webhook event not found"}
+	}
+	return event, nil
+}
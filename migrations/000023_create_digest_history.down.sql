@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS digest_history;
+
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,22 @@
+-- Per-user timezone used to schedule digests in local time
+ALTER TABLE notification_preferences
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Digest delivery history (one row per user, org, cadence and period)
+CREATE TABLE digest_history (
+    id                  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id             UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    org_id              UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    frequency           VARCHAR(20) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    period_start        TIMESTAMPTZ NOT NULL,
+    period_end          TIMESTAMPTZ NOT NULL,
+    status              VARCHAR(20) NOT NULL CHECK (status IN ('sent', 'failed', 'pending')),
+    sendgrid_message_id VARCHAR(255),
+    error_message       TEXT,
+    sent_at             TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (user_id, org_id, frequency, period_start)
+);
+
+CREATE INDEX idx_digest_history_org_created ON digest_history (org_id, created_at DESC);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS queued_alert_emails;
+
+ALTER TABLE alert_rules
+    DROP COLUMN IF EXISTS severity;
+
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS quiet_hours_enabled,
+    DROP COLUMN IF EXISTS quiet_hours_start,
+    DROP COLUMN IF EXISTS quiet_hours_end,
+    DROP COLUMN IF EXISTS business_days_only,
+    DROP COLUMN IF EXISTS urgent_bypass_quiet_hours;
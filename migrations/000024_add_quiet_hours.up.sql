@@ -0,0 +1,32 @@
+-- Quiet hours and business-day delivery windows per user
+ALTER TABLE notification_preferences
+    ADD COLUMN quiet_hours_enabled       BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN quiet_hours_start         VARCHAR(5) NOT NULL DEFAULT '22:00',
+    ADD COLUMN quiet_hours_end           VARCHAR(5) NOT NULL DEFAULT '08:00',
+    ADD COLUMN business_days_only        BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN urgent_bypass_quiet_hours BOOLEAN NOT NULL DEFAULT true;
+
+-- Alert severity (critical alerts may bypass quiet hours)
+ALTER TABLE alert_rules
+    ADD COLUMN severity VARCHAR(20) NOT NULL DEFAULT 'warning'
+        CHECK (severity IN ('info', 'warning', 'critical'));
+
+-- Alert emails held back until the recipient's delivery window opens
+CREATE TABLE queued_alert_emails (
+    id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id           UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    alert_history_id UUID NOT NULL REFERENCES alert_history (id) ON DELETE CASCADE,
+    user_id          UUID REFERENCES users (id) ON DELETE CASCADE,
+    recipient        VARCHAR(255) NOT NULL,
+    subject          TEXT NOT NULL,
+    html_body        TEXT NOT NULL,
+    text_body        TEXT NOT NULL,
+    deliver_after    TIMESTAMPTZ NOT NULL,
+    status           VARCHAR(20) NOT NULL DEFAULT 'queued'
+                     CHECK (status IN ('queued', 'sending', 'sent', 'failed')),
+    error_message    TEXT,
+    sent_at          TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_queued_alert_emails_due ON queued_alert_emails (deliver_after) WHERE status = 'queued';
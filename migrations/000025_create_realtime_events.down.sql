@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS integration_connections_publish_realtime ON integration_connections;
+DROP TRIGGER IF EXISTS health_scores_publish_realtime_update ON health_scores;
+DROP TRIGGER IF EXISTS health_scores_publish_realtime_insert ON health_scores;
+DROP TRIGGER IF EXISTS notifications_publish_realtime ON notifications;
+DROP FUNCTION IF EXISTS publish_sync_event();
+DROP FUNCTION IF EXISTS publish_score_event();
+DROP FUNCTION IF EXISTS publish_notification_event();
+DROP TABLE IF EXISTS realtime_events;
+DROP FUNCTION IF EXISTS notify_realtime_event();
@@ -0,0 +1,114 @@
+-- Realtime event log backing the SSE notification stream. Rows are kept for a
+-- short window so reconnecting clients can replay from Last-Event-ID.
+CREATE TABLE realtime_events (
+    id         BIGSERIAL PRIMARY KEY,
+    org_id     UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id    UUID REFERENCES users (id) ON DELETE CASCADE, -- NULL: every member of the org
+    event_type VARCHAR(50) NOT NULL,
+    payload    JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_realtime_events_org_id ON realtime_events (org_id, id);
+CREATE INDEX idx_realtime_events_created_at ON realtime_events (created_at);
+
+-- Wake listening API instances. The payload only carries routing keys; listeners
+-- load the row itself, which keeps NOTIFY well under its 8000 byte limit.
+CREATE OR REPLACE FUNCTION notify_realtime_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('realtime_events', json_build_object(
+        'id', NEW.id,
+        'org_id', NEW.org_id,
+        'user_id', NEW.user_id
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER realtime_events_notify
+    AFTER INSERT ON realtime_events
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_realtime_event();
+
+-- New in-app notifications go to their recipient
+CREATE OR REPLACE FUNCTION publish_notification_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO realtime_events (org_id, user_id, event_type, payload)
+    VALUES (NEW.org_id, NEW.user_id, 'notification.created', jsonb_build_object(
+        'id', NEW.id,
+        'type', NEW.type,
+        'title', NEW.title,
+        'message', NEW.message,
+        'data', NEW.data,
+        'created_at', NEW.created_at
+    ));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notifications_publish_realtime
+    AFTER INSERT ON notifications
+    FOR EACH ROW
+    EXECUTE FUNCTION publish_notification_event();
+
+-- Health score changes go to the whole org
+CREATE OR REPLACE FUNCTION publish_score_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    previous_score INTEGER;
+    previous_level VARCHAR(20);
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        previous_score := OLD.overall_score;
+        previous_level := OLD.risk_level;
+    END IF;
+
+    INSERT INTO realtime_events (org_id, event_type, payload)
+    VALUES (NEW.org_id, 'score.changed', jsonb_build_object(
+        'customer_id', NEW.customer_id,
+        'overall_score', NEW.overall_score,
+        'risk_level', NEW.risk_level,
+        'previous_score', previous_score,
+        'previous_risk_level', previous_level,
+        'calculated_at', NEW.calculated_at
+    ));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER health_scores_publish_realtime_insert
+    AFTER INSERT ON health_scores
+    FOR EACH ROW
+    EXECUTE FUNCTION publish_score_event();
+
+CREATE TRIGGER health_scores_publish_realtime_update
+    AFTER UPDATE ON health_scores
+    FOR EACH ROW
+    WHEN (OLD.overall_score IS DISTINCT FROM NEW.overall_score
+          OR OLD.risk_level IS DISTINCT FROM NEW.risk_level)
+    EXECUTE FUNCTION publish_score_event();
+
+-- Integration sync status changes go to the whole org
+CREATE OR REPLACE FUNCTION publish_sync_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO realtime_events (org_id, event_type, payload)
+    VALUES (NEW.org_id, 'sync.status', jsonb_build_object(
+        'provider', NEW.provider,
+        'status', NEW.status,
+        'last_sync_at', NEW.last_sync_at,
+        'last_sync_error', NEW.last_sync_error
+    ));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER integration_connections_publish_realtime
+    AFTER UPDATE ON integration_connections
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status
+          OR OLD.last_sync_at IS DISTINCT FROM NEW.last_sync_at
+          OR OLD.last_sync_error IS DISTINCT FROM NEW.last_sync_error)
+    EXECUTE FUNCTION publish_sync_event();
@@ -0,0 +1,24 @@
+-- Org-editable alert email templates. A template applies either to one rule or
+-- to every rule of a trigger type; rule templates win over trigger-type ones.
+CREATE TABLE alert_templates (
+    id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id           UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    alert_rule_id    UUID REFERENCES alert_rules (id) ON DELETE CASCADE,
+    trigger_type     VARCHAR(50),
+    subject_template TEXT NOT NULL,
+    body_template    TEXT NOT NULL,
+    text_template    TEXT NOT NULL DEFAULT '',
+    created_by       UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK ((alert_rule_id IS NULL) <> (trigger_type IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_alert_templates_org_rule ON alert_templates (org_id, alert_rule_id) WHERE alert_rule_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_alert_templates_org_trigger ON alert_templates (org_id, trigger_type) WHERE trigger_type IS NOT NULL;
+
+CREATE TRIGGER set_alert_templates_updated_at
+    BEFORE UPDATE ON alert_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
@@ -0,0 +1,29 @@
+-- Durable inbox for verified inbound integration webhooks. Events are stored
+-- before processing so they survive restarts, are deduplicated across replicas
+-- by (provider, event_id), and can be retried or replayed.
+CREATE TABLE webhook_inbox (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider        VARCHAR(50) NOT NULL,
+    event_id        VARCHAR(255) NOT NULL,
+    event_type      VARCHAR(120) NOT NULL DEFAULT '',
+    org_id          UUID REFERENCES organizations (id) ON DELETE CASCADE,
+    payload         JSONB NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending'
+                    CHECK (status IN ('pending', 'processing', 'processed', 'failed', 'dead')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    received_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at    TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (provider, event_id)
+);
+
+CREATE INDEX idx_webhook_inbox_due ON webhook_inbox (next_attempt_at) WHERE status IN ('pending', 'failed');
+CREATE INDEX idx_webhook_inbox_org_status ON webhook_inbox (org_id, status, received_at DESC);
+
+CREATE TRIGGER set_webhook_inbox_updated_at
+    BEFORE UPDATE ON webhook_inbox
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
@@ -0,0 +1,23 @@
+CREATE TABLE sync_runs (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    provider    VARCHAR(50) NOT NULL,
+    mode        VARCHAR(20) NOT NULL CHECK (mode IN ('full', 'incremental')),
+    trigger     VARCHAR(20) NOT NULL CHECK (trigger IN ('scheduler', 'manual', 'webhook')),
+    status      VARCHAR(20) NOT NULL DEFAULT 'running'
+                CHECK (status IN ('running', 'succeeded', 'failed')),
+    steps       JSONB NOT NULL DEFAULT '[]',
+    error       TEXT,
+    started_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    duration_ms BIGINT,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sync_runs_org_provider ON sync_runs (org_id, provider, started_at DESC);
+CREATE INDEX idx_sync_runs_running ON sync_runs (updated_at) WHERE status = 'running';
+
+CREATE TRIGGER set_sync_runs_updated_at
+    BEFORE UPDATE ON sync_runs
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
@@ -0,0 +1,6 @@
+UPDATE sync_runs SET status = 'succeeded' WHERE status = 'paused';
+ALTER TABLE sync_runs DROP CONSTRAINT sync_runs_status_check;
+ALTER TABLE sync_runs ADD CONSTRAINT sync_runs_status_check
+    CHECK (status IN ('running', 'succeeded', 'failed'));
+
+DROP TABLE IF EXISTS sync_checkpoints;
@@ -0,0 +1,24 @@
+-- Pagination checkpoints let an interrupted or time-boxed full sync resume
+-- from the last completed page instead of starting over. They are removed with
+-- the connection, so a reconnected account never resumes a stale cursor.
+CREATE TABLE sync_checkpoints (
+    org_id      UUID NOT NULL,
+    provider    VARCHAR(50) NOT NULL,
+    object_type VARCHAR(50) NOT NULL,
+    cursor      TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (org_id, provider, object_type),
+    FOREIGN KEY (org_id, provider) REFERENCES integration_connections (org_id, provider) ON DELETE CASCADE
+);
+
+CREATE TRIGGER set_sync_checkpoints_updated_at
+    BEFORE UPDATE ON sync_checkpoints
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- A run that stops early to stay within its time budget is paused, not failed.
+ALTER TABLE sync_runs DROP CONSTRAINT sync_runs_status_check;
+ALTER TABLE sync_runs ADD CONSTRAINT sync_runs_status_check
+    CHECK (status IN ('running', 'succeeded', 'paused', 'failed'));
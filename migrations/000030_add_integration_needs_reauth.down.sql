@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_integration_connections_token_expires_at;
+
+UPDATE integration_connections SET status = 'error' WHERE status = 'needs_reauth';
+ALTER TABLE integration_connections DROP CONSTRAINT integration_connections_status_check;
+ALTER TABLE integration_connections ADD CONSTRAINT integration_connections_status_check
+    CHECK (status IN ('pending', 'active', 'error', 'disconnected'));
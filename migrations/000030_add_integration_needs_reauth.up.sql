@@ -0,0 +1,8 @@
+-- A connection whose grant was revoked by the provider waits in needs_reauth
+-- until an admin connects it again.
+ALTER TABLE integration_connections DROP CONSTRAINT integration_connections_status_check;
+ALTER TABLE integration_connections ADD CONSTRAINT integration_connections_status_check
+    CHECK (status IN ('pending', 'active', 'error', 'disconnected', 'needs_reauth'));
+
+CREATE INDEX idx_integration_connections_token_expires_at ON integration_connections (token_expires_at)
+    WHERE status = 'active' AND refresh_token_encrypted IS NOT NULL;
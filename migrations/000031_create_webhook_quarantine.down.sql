@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS webhook_quarantine;
+DROP INDEX IF EXISTS idx_integration_connections_provider_external_account;
@@ -0,0 +1,24 @@
+-- Webhooks are routed to an org by the provider account that sent them.
+CREATE INDEX idx_integration_connections_provider_external_account
+    ON integration_connections (provider, external_account_id);
+
+-- Verified webhook events whose account matches no connected org. They are
+-- kept instead of dropped and released into the inbox if the account is
+-- connected later, e.g. when Stripe delivers events before the OAuth callback
+-- has stored the connection.
+CREATE TABLE webhook_quarantine (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider    VARCHAR(50) NOT NULL,
+    event_id    VARCHAR(255) NOT NULL,
+    event_type  VARCHAR(120) NOT NULL DEFAULT '',
+    account_id  VARCHAR(255) NOT NULL,
+    payload     JSONB NOT NULL,
+    reason      TEXT NOT NULL DEFAULT '',
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    released_at TIMESTAMPTZ,
+
+    UNIQUE (provider, event_id)
+);
+
+CREATE INDEX idx_webhook_quarantine_account ON webhook_quarantine (provider, account_id, received_at)
+    WHERE released_at IS NULL;
@@ -0,0 +1,19 @@
+CREATE TABLE reconciliation_reports (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    provider    VARCHAR(50) NOT NULL,
+    trigger     VARCHAR(20) NOT NULL CHECK (trigger IN ('scheduler', 'manual')),
+    heal        BOOLEAN NOT NULL DEFAULT FALSE,
+    status      VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    checked     INTEGER NOT NULL DEFAULT 0,
+    missing     INTEGER NOT NULL DEFAULT 0,
+    stale       INTEGER NOT NULL DEFAULT 0,
+    orphaned    INTEGER NOT NULL DEFAULT 0,
+    healed      INTEGER NOT NULL DEFAULT 0,
+    items       JSONB NOT NULL DEFAULT '[]',
+    error       TEXT,
+    started_at  TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reconciliation_reports_org_provider ON reconciliation_reports (org_id, provider, started_at DESC);
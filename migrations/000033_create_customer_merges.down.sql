@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS customer_merges;
+
+ALTER TABLE customers DROP COLUMN IF EXISTS merged_into_id;
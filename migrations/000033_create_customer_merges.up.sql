@@ -0,0 +1,23 @@
+-- A customer merged into another is retired and points at the record it was merged into
+ALTER TABLE customers ADD COLUMN merged_into_id UUID REFERENCES customers (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_customers_merged_into ON customers (merged_into_id) WHERE merged_into_id IS NOT NULL;
+
+CREATE TABLE customer_merges (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    primary_id  UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    merged_id   UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    reason      VARCHAR(20) NOT NULL CHECK (reason IN ('duplicate_email', 'manual')),
+    snapshot    JSONB NOT NULL,
+    merged_by   UUID REFERENCES users (id) ON DELETE SET NULL,
+    merged_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    unmerged_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    unmerged_at TIMESTAMPTZ,
+
+    CHECK (primary_id <> merged_id)
+);
+
+CREATE INDEX idx_customer_merges_org ON customer_merges (org_id, merged_at DESC);
+CREATE INDEX idx_customer_merges_primary ON customer_merges (primary_id);
+CREATE UNIQUE INDEX idx_customer_merges_active ON customer_merges (merged_id) WHERE unmerged_at IS NULL;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS identity_matches;
+
+DROP INDEX IF EXISTS idx_customers_org_lower_email;
+
+UPDATE customer_merges SET reason = 'manual' WHERE reason = 'identity_match';
+ALTER TABLE customer_merges DROP CONSTRAINT customer_merges_reason_check;
+ALTER TABLE customer_merges ADD CONSTRAINT customer_merges_reason_check
+    CHECK (reason IN ('duplicate_email', 'manual'));
@@ -0,0 +1,28 @@
+ALTER TABLE customer_merges DROP CONSTRAINT customer_merges_reason_check;
+ALTER TABLE customer_merges ADD CONSTRAINT customer_merges_reason_check
+    CHECK (reason IN ('duplicate_email', 'identity_match', 'manual'));
+
+CREATE INDEX idx_customers_org_lower_email ON customers (org_id, LOWER(email)) WHERE deleted_at IS NULL;
+
+-- Candidate duplicate pairs scored below the auto-merge threshold, awaiting an
+-- admin decision. customer_id is always the lower of the two IDs so a pair has
+-- one row; rejected pairs are kept so they are not queued again.
+CREATE TABLE identity_matches (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id       UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    customer_id  UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    candidate_id UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    score        INT NOT NULL CHECK (score BETWEEN 0 AND 100),
+    signals      JSONB NOT NULL DEFAULT '[]',
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    merge_id     UUID REFERENCES customer_merges (id) ON DELETE SET NULL,
+    decided_by   UUID REFERENCES users (id) ON DELETE SET NULL,
+    decided_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (customer_id < candidate_id),
+    UNIQUE (org_id, customer_id, candidate_id)
+);
+
+CREATE INDEX idx_identity_matches_org_status ON identity_matches (org_id, status, score DESC);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_alert_history_rule_account;
+ALTER TABLE alert_history DROP COLUMN IF EXISTS account_id;
+
+ALTER TABLE scoring_configs DROP COLUMN IF EXISTS account_aggregation;
+
+DROP TABLE IF EXISTS account_health_scores;
+
+DROP INDEX IF EXISTS idx_customers_account;
+ALTER TABLE customers DROP COLUMN IF EXISTS account_id;
+
+DROP TABLE IF EXISTS accounts;
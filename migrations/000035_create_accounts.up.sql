@@ -0,0 +1,62 @@
+-- Companies customers belong to. An account comes from a HubSpot company, a
+-- corporate email domain or, failing both, a Stripe billing customer;
+-- external_id is the company ID, the domain or the Stripe customer ID.
+CREATE TABLE accounts (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    source      VARCHAR(20) NOT NULL CHECK (source IN ('hubspot', 'domain', 'stripe')),
+    external_id VARCHAR(255) NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    domain      VARCHAR(255),
+    mrr_cents   INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (org_id, source, external_id)
+);
+
+CREATE INDEX idx_accounts_org_name ON accounts (org_id, name);
+
+CREATE TRIGGER set_accounts_updated_at
+    BEFORE UPDATE ON accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+ALTER TABLE customers ADD COLUMN account_id UUID REFERENCES accounts (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_customers_account ON customers (account_id) WHERE account_id IS NOT NULL;
+
+-- Current rolled-up score per account. previous_risk_level and risk_changed_at
+-- record the last risk level transition for account_risk_change alerts.
+CREATE TABLE account_health_scores (
+    id                  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id              UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    account_id          UUID NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    overall_score       INTEGER NOT NULL CHECK (overall_score >= 0 AND overall_score <= 100),
+    risk_level          VARCHAR(20) NOT NULL CHECK (risk_level IN ('green', 'yellow', 'red')),
+    aggregation         VARCHAR(20) NOT NULL,
+    contact_count       INTEGER NOT NULL DEFAULT 0,
+    factors             JSONB NOT NULL,
+    previous_risk_level VARCHAR(20),
+    risk_changed_at     TIMESTAMPTZ,
+    calculated_at       TIMESTAMPTZ NOT NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (account_id)
+);
+
+CREATE INDEX idx_account_health_scores_org_risk ON account_health_scores (org_id, risk_level);
+
+CREATE TRIGGER set_account_health_scores_updated_at
+    BEFORE UPDATE ON account_health_scores
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+ALTER TABLE scoring_configs ADD COLUMN account_aggregation VARCHAR(20) NOT NULL DEFAULT 'mrr_weighted'
+    CHECK (account_aggregation IN ('mrr_weighted', 'min', 'mean'));
+
+ALTER TABLE alert_history ADD COLUMN account_id UUID REFERENCES accounts (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_alert_history_rule_account ON alert_history (alert_rule_id, account_id, created_at DESC)
+    WHERE account_id IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS customer_note_revisions;
+
+DROP TABLE IF EXISTS customer_notes;
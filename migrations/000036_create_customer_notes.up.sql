@@ -0,0 +1,31 @@
+CREATE TABLE customer_notes (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    author_id   UUID REFERENCES users (id) ON DELETE SET NULL,
+    body        TEXT NOT NULL,
+    mentions    UUID[] NOT NULL DEFAULT '{}',
+    pinned      BOOLEAN NOT NULL DEFAULT FALSE,
+    edited_at   TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_customer_notes_customer ON customer_notes (customer_id, created_at DESC);
+CREATE INDEX idx_customer_notes_org ON customer_notes (org_id);
+
+CREATE TRIGGER set_customer_notes_updated_at
+    BEFORE UPDATE ON customer_notes
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Each edit keeps the body it replaced
+CREATE TABLE customer_note_revisions (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    note_id    UUID NOT NULL REFERENCES customer_notes (id) ON DELETE CASCADE,
+    body       TEXT NOT NULL,
+    edited_by  UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_customer_note_revisions_note ON customer_note_revisions (note_id, created_at DESC);
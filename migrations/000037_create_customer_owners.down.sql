@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS customer_owners;
+
+DROP TABLE IF EXISTS customer_assignment_rules;
@@ -0,0 +1,35 @@
+-- Rules that auto-assign owners to customers without one, lowest priority first
+CREATE TABLE customer_assignment_rules (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    name        VARCHAR(255) NOT NULL,
+    priority    INTEGER NOT NULL DEFAULT 0,
+    conditions  JSONB NOT NULL DEFAULT '{}',
+    strategy    VARCHAR(20) NOT NULL DEFAULT 'round_robin' CHECK (strategy IN ('round_robin', 'all')),
+    user_ids    UUID[] NOT NULL,
+    next_index  INTEGER NOT NULL DEFAULT 0,
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by  UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_customer_assignment_rules_org ON customer_assignment_rules (org_id, priority);
+
+CREATE TRIGGER set_customer_assignment_rules_updated_at
+    BEFORE UPDATE ON customer_assignment_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+CREATE TABLE customer_owners (
+    customer_id        UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    user_id            UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    org_id             UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    assigned_by        UUID REFERENCES users (id) ON DELETE SET NULL,
+    assignment_rule_id UUID REFERENCES customer_assignment_rules (id) ON DELETE SET NULL,
+    assigned_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (customer_id, user_id)
+);
+
+CREATE INDEX idx_customer_owners_org_user ON customer_owners (org_id, user_id);
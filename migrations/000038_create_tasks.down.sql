@@ -0,0 +1,3 @@
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS task_config;
+
+DROP TABLE IF EXISTS tasks;
@@ -0,0 +1,30 @@
+-- Follow-up work items, optionally linked to a customer and/or the alert that raised them
+CREATE TABLE tasks (
+    id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id           UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    title            VARCHAR(255) NOT NULL,
+    description      TEXT NOT NULL DEFAULT '',
+    status           VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'in_progress', 'done', 'cancelled')),
+    due_at           TIMESTAMPTZ,
+    assignee_id      UUID REFERENCES users (id) ON DELETE SET NULL,
+    customer_id      UUID REFERENCES customers (id) ON DELETE CASCADE,
+    alert_history_id UUID REFERENCES alert_history (id) ON DELETE SET NULL,
+    created_by       UUID REFERENCES users (id) ON DELETE SET NULL,
+    completed_at     TIMESTAMPTZ,
+    reminded_at      TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_tasks_org_status_due ON tasks (org_id, status, due_at);
+CREATE INDEX idx_tasks_assignee_status ON tasks (assignee_id, status);
+CREATE INDEX idx_tasks_customer ON tasks (customer_id);
+CREATE INDEX idx_tasks_alert_history ON tasks (alert_history_id);
+
+CREATE TRIGGER set_tasks_updated_at
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Optional task template applied when an alert rule fires
+ALTER TABLE alert_rules ADD COLUMN task_config JSONB;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS playbook_run_steps;
+
+DROP TABLE IF EXISTS playbook_runs;
+
+DROP TABLE IF EXISTS playbooks;
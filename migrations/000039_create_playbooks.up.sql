@@ -0,0 +1,70 @@
+-- Automated customer success workflows: a trigger enrolls customers in a sequence of steps
+CREATE TABLE playbooks (
+    id                 UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id             UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    name               VARCHAR(255) NOT NULL,
+    description        TEXT NOT NULL DEFAULT '',
+    trigger            JSONB NOT NULL,
+    steps              JSONB NOT NULL DEFAULT '[]',
+    is_active          BOOLEAN NOT NULL DEFAULT TRUE,
+    trigger_checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by         UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_playbooks_org_active ON playbooks (org_id, is_active);
+
+CREATE TRIGGER set_playbooks_updated_at
+    BEFORE UPDATE ON playbooks
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- One customer's progress through a playbook. Steps are copied from the
+-- playbook when the run starts so later edits do not affect it.
+CREATE TABLE playbook_runs (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id       UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    playbook_id  UUID NOT NULL REFERENCES playbooks (id) ON DELETE CASCADE,
+    customer_id  UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    trigger_key  VARCHAR(255) NOT NULL DEFAULT '',
+    trigger_data JSONB NOT NULL DEFAULT '{}',
+    steps        JSONB NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'waiting', 'completed', 'failed', 'cancelled')),
+    current_step INTEGER NOT NULL DEFAULT 0,
+    next_run_at  TIMESTAMPTZ,
+    error        TEXT NOT NULL DEFAULT '',
+    started_by   UUID REFERENCES users (id) ON DELETE SET NULL,
+    started_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A customer is in at most one active run of a playbook, and each trigger
+-- occurrence enrolls them once.
+CREATE UNIQUE INDEX idx_playbook_runs_active ON playbook_runs (playbook_id, customer_id)
+    WHERE status IN ('running', 'waiting');
+CREATE UNIQUE INDEX idx_playbook_runs_trigger_key ON playbook_runs (playbook_id, customer_id, trigger_key)
+    WHERE trigger_key <> '';
+CREATE INDEX idx_playbook_runs_due ON playbook_runs (next_run_at) WHERE status IN ('running', 'waiting');
+CREATE INDEX idx_playbook_runs_customer ON playbook_runs (customer_id, started_at DESC);
+CREATE INDEX idx_playbook_runs_org_playbook ON playbook_runs (org_id, playbook_id, started_at DESC);
+
+CREATE TRIGGER set_playbook_runs_updated_at
+    BEFORE UPDATE ON playbook_runs
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- What each executed step did
+CREATE TABLE playbook_run_steps (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    run_id      UUID NOT NULL REFERENCES playbook_runs (id) ON DELETE CASCADE,
+    step_index  INTEGER NOT NULL,
+    step_type   VARCHAR(20) NOT NULL,
+    status      VARCHAR(20) NOT NULL CHECK (status IN ('completed', 'failed')),
+    detail      JSONB NOT NULL DEFAULT '{}',
+    error       TEXT NOT NULL DEFAULT '',
+    executed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_playbook_run_steps_run ON playbook_run_steps (run_id, executed_at);
@@ -0,0 +1,7 @@
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS segment_id;
+
+DROP TABLE IF EXISTS segment_counts;
+
+DROP TABLE IF EXISTS segments;
+
+DROP TABLE IF EXISTS customer_tags;
@@ -0,0 +1,43 @@
+-- Free-form labels on customers
+CREATE TABLE customer_tags (
+    customer_id UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    tag         VARCHAR(50) NOT NULL,
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    created_by  UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (customer_id, tag)
+);
+
+CREATE INDEX idx_customer_tags_org_tag ON customer_tags (org_id, tag);
+
+-- Saved customer segments defined by a filter expression
+CREATE TABLE segments (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    filter      JSONB NOT NULL,
+    created_by  UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, name)
+);
+
+CREATE TRIGGER set_segments_updated_at
+    BEFORE UPDATE ON segments
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Daily segment membership, refreshed through the day
+CREATE TABLE segment_counts (
+    segment_id   UUID NOT NULL REFERENCES segments (id) ON DELETE CASCADE,
+    recorded_on  DATE NOT NULL,
+    member_count INTEGER NOT NULL,
+    mrr_cents    BIGINT NOT NULL DEFAULT 0,
+    recorded_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (segment_id, recorded_on)
+);
+
+-- Alert rules can be limited to a segment's customers. A segment in use by
+-- a rule cannot be deleted, so the rule never silently widens to everyone.
+ALTER TABLE alert_rules ADD COLUMN segment_id UUID REFERENCES segments (id) ON DELETE RESTRICT;
@@ -0,0 +1,43 @@
+-- Typed custom fields an org defines for its customers, optionally filled
+-- from a key in the Stripe, HubSpot or Intercom metadata synced onto them
+CREATE TABLE custom_fields (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id      UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    key         VARCHAR(64) NOT NULL CHECK (key ~ '^[a-z][a-z0-9_]{0,62}$'),
+    label       VARCHAR(255) NOT NULL,
+    field_type  VARCHAR(20) NOT NULL CHECK (field_type IN ('string', 'number', 'date', 'enum', 'boolean')),
+    options     TEXT[] NOT NULL DEFAULT '{}',
+    source      VARCHAR(20) CHECK (source IN ('stripe', 'hubspot', 'intercom')),
+    source_key  VARCHAR(255),
+    scoring     JSONB,
+    created_by  UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, key),
+    CHECK ((source IS NULL) = (source_key IS NULL))
+);
+
+CREATE TRIGGER set_custom_fields_updated_at
+    BEFORE UPDATE ON custom_fields
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- A customer's value for a custom field. value_text holds every value in
+-- canonical form; number and date values are also kept typed so they can be
+-- compared and sorted.
+CREATE TABLE customer_field_values (
+    customer_id  UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    field_id     UUID NOT NULL REFERENCES custom_fields (id) ON DELETE CASCADE,
+    org_id       UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    value_text   TEXT NOT NULL,
+    value_number NUMERIC,
+    value_date   DATE,
+    source       VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'sync')),
+    updated_by   UUID REFERENCES users (id) ON DELETE SET NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (customer_id, field_id)
+);
+
+CREATE INDEX idx_customer_field_values_text ON customer_field_values (field_id, value_text);
+CREATE INDEX idx_customer_field_values_number ON customer_field_values (field_id, value_number) WHERE value_number IS NOT NULL;
+CREATE INDEX idx_customer_field_values_date ON customer_field_values (field_id, value_date) WHERE value_date IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_hubspot_deals_org_pipeline_close;
+DROP TABLE IF EXISTS renewal_settings;
+DROP TABLE IF EXISTS customer_renewals;
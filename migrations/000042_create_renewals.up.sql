@@ -0,0 +1,37 @@
+-- Manual renewal overrides. A customer's renewal otherwise comes from an open
+-- HubSpot renewal deal or, failing that, its Stripe subscriptions.
+CREATE TABLE customer_renewals (
+    customer_id   UUID PRIMARY KEY REFERENCES customers (id) ON DELETE CASCADE,
+    org_id        UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    renewal_date  DATE NOT NULL,
+    amount_cents  BIGINT CHECK (amount_cents >= 0),
+    currency      VARCHAR(3),
+    notes         TEXT NOT NULL DEFAULT '',
+    updated_by    UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_customer_renewals_org_date ON customer_renewals (org_id, renewal_date);
+
+CREATE TRIGGER set_customer_renewals_updated_at
+    BEFORE UPDATE ON customer_renewals
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Which HubSpot deals are renewals: open deals in hubspot_pipeline whose
+-- stage is not one of closed_stages
+CREATE TABLE renewal_settings (
+    org_id            UUID PRIMARY KEY REFERENCES organizations (id) ON DELETE CASCADE,
+    hubspot_pipeline  VARCHAR(255),
+    closed_stages     TEXT[] NOT NULL DEFAULT '{closedwon,closedlost}',
+    updated_by        UUID REFERENCES users (id) ON DELETE SET NULL,
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER set_renewal_settings_updated_at
+    BEFORE UPDATE ON renewal_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_updated_at();
+
+CREATE INDEX idx_hubspot_deals_org_pipeline_close ON hubspot_deals (org_id, pipeline, close_date);
@@ -0,0 +1 @@
+ALTER TABLE playbooks DROP COLUMN IF EXISTS webhook_secret;
@@ -0,0 +1,5 @@
+-- Secret that signs a playbook's webhook requests. Two v4 UUIDs give 244
+-- random bits from the server's strong random source.
+ALTER TABLE playbooks
+    ADD COLUMN webhook_secret VARCHAR(64) NOT NULL
+        DEFAULT replace(uuid_generate_v4()::text || uuid_generate_v4()::text, '-', '');
@@ -0,0 +1 @@
+ALTER TABLE queued_alert_emails DROP COLUMN IF EXISTS claimed_at;
@@ -0,0 +1,6 @@
+-- When a queued alert email was claimed for sending, so emails left in
+-- sending by a crashed worker can be claimed again
+ALTER TABLE queued_alert_emails
+    ADD COLUMN claimed_at TIMESTAMPTZ;
+
+UPDATE queued_alert_emails SET claimed_at = NOW() WHERE status = 'sending';
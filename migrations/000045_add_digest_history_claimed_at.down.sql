@@ -0,0 +1 @@
+ALTER TABLE digest_history DROP COLUMN IF EXISTS claimed_at;
@@ -0,0 +1,6 @@
+-- When a digest was last claimed, so digests left pending by a crashed worker
+-- can be claimed again
+ALTER TABLE digest_history
+    ADD COLUMN claimed_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE digest_history SET claimed_at = created_at;
@@ -4,7 +4,7 @@ import { relativeTime } from "@/lib/format";
 
 interface IntegrationCardProps {
   provider: string;
-  status: "connected" | "syncing" | "error" | "disconnected";
+  status: "connected" | "syncing" | "error" | "needs_reauth" | "disconnected";
   lastSyncAt?: string;
   customerCount?: number;
   onSync?: () => void;
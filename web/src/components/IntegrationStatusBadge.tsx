@@ -1,5 +1,5 @@
 interface IntegrationStatusBadgeProps {
-  status: "connected" | "syncing" | "error" | "disconnected";
+  status: "connected" | "syncing" | "error" | "needs_reauth" | "disconnected";
 }
 
 const statusStyles = {
@@ -18,6 +18,11 @@ const statusStyles = {
     text: "text-[var(--galdr-danger)]",
     label: "Error",
   },
+  needs_reauth: {
+    dot: "bg-[var(--galdr-danger)]",
+    text: "text-[var(--galdr-danger)]",
+    label: "Reconnect required",
+  },
   disconnected: {
     dot: "bg-[var(--galdr-fg-muted)]",
     text: "text-[var(--galdr-fg-muted)]",
@@ -22,6 +22,22 @@ const TRIGGER_TYPES = [
   { value: "score_drop", label: "Score Drop" },
   { value: "risk_change", label: "Risk Level Change" },
   { value: "payment_failed", label: "Payment Failed" },
+  { value: "billing_event", label: "Billing Event" },
+];
+
+const BILLING_EVENT_TYPES = [
+  { value: "payment.refunded", label: "Payment refunded" },
+  { value: "dispute.opened", label: "Dispute opened" },
+  { value: "dispute.lost", label: "Dispute lost" },
+  { value: "dispute.won", label: "Dispute won" },
+  { value: "trial.ending", label: "Trial ending" },
+  { value: "subscription.past_due", label: "Subscription past due" },
+  { value: "subscription.paused", label: "Subscription paused" },
+  { value: "subscription.resumed", label: "Subscription resumed" },
+  { value: "plan.downgraded", label: "Plan downgraded" },
+  { value: "plan.upgraded", label: "Plan upgraded" },
+  { value: "invoice.upcoming", label: "Upcoming invoice" },
+  { value: "payment.failed", label: "Payment failed" },
 ];
 
 function triggerLabel(type: string): string {
@@ -69,6 +85,9 @@ function RuleForm({ onSave, onCancel, initial, saving }: RuleFormProps) {
   const [days, setDays] = useState(
     String((initial?.conditions?.days as number) ?? 7),
   );
+  const [billingEventType, setBillingEventType] = useState(
+    (initial?.conditions?.event_type as string) ?? "dispute.opened",
+  );
 
   function buildConditions(): Record<string, unknown> {
     switch (triggerType) {
@@ -80,6 +99,8 @@ function RuleForm({ onSave, onCancel, initial, saving }: RuleFormProps) {
         return {};
       case "payment_failed":
         return {};
+      case "billing_event":
+        return { event_type: billingEventType };
       default:
         return {};
     }
@@ -182,6 +203,26 @@ function RuleForm({ onSave, onCancel, initial, saving }: RuleFormProps) {
         </div>
       )}
 
+      {triggerType === "billing_event" && (
+        <div>
+          <label className={labelCls}>Billing Event</label>
+          <select
+            className={inputCls}
+            value={billingEventType}
+            onChange={(e) => setBillingEventType(e.target.value)}
+          >
+            {BILLING_EVENT_TYPES.map((t) => (
+              <option key={t.value} value={t.value}>
+                {t.label}
+              </option>
+            ))}
+          </select>
+          <p className="mt-1 text-xs text-[var(--galdr-fg-muted)]">
+            Alert when Stripe reports this event for a customer
+          </p>
+        </div>
+      )}
+
       <div>
         <label className={labelCls}>Recipients (comma-separated emails)</label>
         <input
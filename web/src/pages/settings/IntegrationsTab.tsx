@@ -91,6 +91,7 @@ export default function IntegrationsTab() {
                     | "connected"
                     | "syncing"
                     | "error"
+                    | "needs_reauth"
                     | "disconnected"
                 }
                 lastSyncAt={integration.last_sync_at}